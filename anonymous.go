@@ -0,0 +1,168 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrResolverNotConfigured is returned when an anonymous entity needs to be resolved,
+// but the parser that produced the expression has no Resolver (see WithResolver).
+var ErrResolverNotConfigured = errors.New("anonymous entity resolver is not configured")
+
+// EntityInstance is an instance of CTI type that is resolved from the anonymous entity UUID.
+type EntityInstance struct {
+	// Cti is a complete CTI of the instance.
+	// For anonymous entity, it ends with the entity UUID (e.g. cti.a.p.am.alert.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6).
+	Cti string
+
+	// Values are the values of the instance.
+	Values map[string]any
+
+	// RawValues are the values of the instance encoded to JSON, if available.
+	// ApplyPatch keeps them in sync with Values.
+	RawValues json.RawMessage
+}
+
+// Resolver resolves anonymous entities by their UUIDs.
+type Resolver interface {
+	// ResolveAnonymous returns the instance identified by the anonymous entity UUID.
+	ResolveAnonymous(id uuid.UUID) (*EntityInstance, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as Resolver.
+type ResolverFunc func(id uuid.UUID) (*EntityInstance, error)
+
+// ResolveAnonymous calls f(id).
+func (f ResolverFunc) ResolveAnonymous(id uuid.UUID) (*EntityInstance, error) {
+	return f(id)
+}
+
+// ResolveAnonymous resolves the anonymous entity of the Expression using the Resolver of the parser.
+func (e *Expression) ResolveAnonymous() (*EntityInstance, error) {
+	if !e.AnonymousEntityUUID.Valid {
+		return nil, fmt.Errorf("expression has no anonymous entity")
+	}
+	if e.parser == nil || e.parser.resolver == nil {
+		return nil, ErrResolverNotConfigured
+	}
+	instance, err := e.parser.resolver.ResolveAnonymous(e.AnonymousEntityUUID.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve anonymous entity %s: %w", e.AnonymousEntityUUID.UUID, err)
+	}
+	return instance, nil
+}
+
+// SelectAttribute returns the value of the attribute that is selected by the Expression.
+// The Expression must have both anonymous entity UUID and attribute selector,
+// e.g. cti.a.p.am.alert.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6@category.
+// The attribute name may select nested values and array items (see AttributeName.WalkJSON).
+func (e *Expression) SelectAttribute() (any, error) {
+	if e.AttributeSelector == "" {
+		return nil, fmt.Errorf("expression has no attribute selector")
+	}
+	instance, err := e.ResolveAnonymous()
+	if err != nil {
+		return nil, err
+	}
+	val, ok := e.LookupAttribute(instance.Values)
+	if !ok {
+		return nil, fmt.Errorf("attribute %q not found in %s", e.AttributeSelector, instance.Cti)
+	}
+	return val, nil
+}
+
+// LookupAttribute returns the value of the attribute that is selected by the Expression from the values.
+// The attribute name may select nested values and array items (see AttributeName.WalkJSON).
+// If the attribute selector has the wildcard array index, e.g. items[*].id, all selected values are returned as a slice.
+func (e *Expression) LookupAttribute(values map[string]any) (any, bool) {
+	if e.AttributeSelector == "" {
+		return nil, false
+	}
+	return lookupAttribute(values, e.AttributeSelector)
+}
+
+// MatchAttributes reports whether the values satisfy all query attributes of the Expression.
+// The attribute names may select nested values and array items (see AttributeName.WalkJSON).
+// If an attribute name selects several values, it is enough for one of them to match. An attribute value that is a CTI expression
+// matches string values that are matched by the expression, other attribute values are compared as strings.
+func (e *Expression) MatchAttributes(values map[string]any) (bool, error) {
+	p := e.parser
+	if p == nil {
+		p = NewParser()
+	}
+	for i := range e.QueryAttributes {
+		queryAttr := &e.QueryAttributes[i]
+		matched := false
+		for _, val := range queryAttr.Name.WalkJSON(values) {
+			var matchErr error
+			if matched, matchErr = queryAttr.Value.matchValue(p, val); matchErr != nil {
+				return false, fmt.Errorf("match query attribute %q: %w", queryAttr.Name, matchErr)
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchAnonymous reports whether the anonymous entity of the second expression satisfies the query of the Expression.
+// The second expression is resolved using the Resolver of the parser of the Expression.
+func (e *Expression) matchAnonymous(secondExpression Expression, ignoreQuery bool) (bool, error) {
+	if e.parser == nil || e.parser.resolver == nil {
+		return false, nil
+	}
+	if ignoreQuery || !e.HasQueryAttributes() {
+		return true, nil
+	}
+	secondExpression.parser = e.parser
+	instance, err := secondExpression.ResolveAnonymous()
+	if err != nil {
+		return false, err
+	}
+	return e.MatchAttributes(instance.Values)
+}
+
+// matchValue reports whether the value of the resolved instance attribute matches with the query attribute value.
+func (v QueryAttributeValue) matchValue(p *Parser, val any) (bool, error) {
+	if !v.IsExpression() {
+		return fmt.Sprint(val) == v.Raw, nil
+	}
+	s, ok := val.(string)
+	if !ok {
+		return false, nil
+	}
+	valExpr, err := p.Parse(s)
+	if err != nil {
+		if errors.Is(err, ErrNotExpression) {
+			return false, nil
+		}
+		return false, err
+	}
+	return v.Expression.Match(valExpr)
+}
+
+// lookupAttribute returns the value selected by the attribute name.
+// If the name has the wildcard array index, all selected values are returned as a slice.
+func lookupAttribute(values map[string]any, name AttributeName) (any, bool) {
+	vals := name.WalkJSON(values)
+	if len(vals) == 0 {
+		return nil, false
+	}
+	if name.HasWildcard() {
+		return vals, true
+	}
+	return vals[0], true
+}
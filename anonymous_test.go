@@ -0,0 +1,203 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testAnonymousUUID = "ba3c448e-55e3-4f7f-ae54-4e87eb8635f6"
+
+func newTestResolver() Resolver {
+	instances := map[uuid.UUID]*EntityInstance{
+		uuid.MustParse(testAnonymousUUID): {
+			Cti: "cti.a.p.am.alert.v1.0~" + testAnonymousUUID,
+			Values: map[string]any{
+				"severity": "critical",
+				"category": "cti.a.p.am.category.v1.0~a.p.backup.v1.0",
+				"count":    float64(3),
+				"origin":   map[string]any{"host": "srv1"},
+			},
+		},
+	}
+	return ResolverFunc(func(id uuid.UUID) (*EntityInstance, error) {
+		instance, ok := instances[id]
+		if !ok {
+			return nil, fmt.Errorf("entity not found")
+		}
+		return instance, nil
+	})
+}
+
+func TestExpression_ResolveAnonymous(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true), WithResolver(newTestResolver()))
+
+	expr := p.MustParse("cti.a.p.am.alert.v1.0~" + testAnonymousUUID)
+	instance, err := expr.ResolveAnonymous()
+	require.NoError(t, err)
+	require.Equal(t, "critical", instance.Values["severity"])
+
+	expr = p.MustParse("cti.a.p.am.alert.v1.0~00000000-0000-0000-0000-000000000000")
+	_, err = expr.ResolveAnonymous()
+	require.EqualError(t, err, "resolve anonymous entity 00000000-0000-0000-0000-000000000000: entity not found")
+
+	expr = p.MustParse("cti.a.p.am.alert.v1.0")
+	_, err = expr.ResolveAnonymous()
+	require.EqualError(t, err, "expression has no anonymous entity")
+
+	expr = MustParse("cti.a.p.am.alert.v1.0~"+testAnonymousUUID, WithAllowAnonymousEntity(true))
+	_, err = expr.ResolveAnonymous()
+	require.ErrorIs(t, err, ErrResolverNotConfigured)
+}
+
+func TestExpression_SelectAttribute(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true), WithResolver(newTestResolver()))
+
+	tests := []struct {
+		name       string
+		input      string
+		wantValue  any
+		wantErrMsg string
+	}{
+		{
+			name:      "ok, top-level attribute",
+			input:     "cti.a.p.am.alert.v1.0~" + testAnonymousUUID + "@severity",
+			wantValue: "critical",
+		},
+		{
+			name:      "ok, nested attribute",
+			input:     "cti.a.p.am.alert.v1.0~" + testAnonymousUUID + "@origin.host",
+			wantValue: "srv1",
+		},
+		{
+			name:       "error, unknown attribute",
+			input:      "cti.a.p.am.alert.v1.0~" + testAnonymousUUID + "@origin.port",
+			wantErrMsg: `attribute "origin.port" not found in cti.a.p.am.alert.v1.0~` + testAnonymousUUID,
+		},
+		{
+			name:       "error, no attribute selector",
+			input:      "cti.a.p.am.alert.v1.0~" + testAnonymousUUID,
+			wantErrMsg: "expression has no attribute selector",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := p.MustParse(tt.input)
+			val, err := expr.SelectAttribute()
+			if tt.wantErrMsg != "" {
+				require.EqualError(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantValue, val)
+		})
+	}
+}
+
+func TestExpression_MatchAnonymous(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true), WithResolver(newTestResolver()))
+	anonymous := p.MustParse("cti.a.p.am.alert.v1.0~" + testAnonymousUUID)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantMatch bool
+	}{
+		{
+			name:      "type matches its anonymous entity",
+			query:     "cti.a.p.am.alert.v1.0",
+			wantMatch: true,
+		},
+		{
+			name:      "query matches raw value",
+			query:     `cti.a.p.am.alert.v1.0[severity="critical"]`,
+			wantMatch: true,
+		},
+		{
+			name:      "query matches non-string value",
+			query:     `cti.a.p.am.alert.v1.0[count="3"]`,
+			wantMatch: true,
+		},
+		{
+			name:      "query matches nested value",
+			query:     `cti.a.p.am.alert.v1.0[origin.host="srv1"]`,
+			wantMatch: true,
+		},
+		{
+			name:      "query matches expression value",
+			query:     `cti.a.p.am.alert.v1.0[category="cti.a.p.am.category.v1.0~a.p.*"]`,
+			wantMatch: true,
+		},
+		{
+			name:  "query does not match value",
+			query: `cti.a.p.am.alert.v1.0[severity="low"]`,
+		},
+		{
+			name:  "query attribute is missing",
+			query: `cti.a.p.am.alert.v1.0[source="agent"]`,
+		},
+		{
+			name:  "other type",
+			query: "cti.a.p.am.incident.v1.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := p.MustParse(tt.query)
+			matched, err := expr.Match(anonymous)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMatch, matched)
+		})
+	}
+
+	t.Run("without resolver anonymous entity is not matched", func(t *testing.T) {
+		expr := MustParse("cti.a.p.am.alert.v1.0")
+		matched, err := expr.Match(anonymous)
+		require.NoError(t, err)
+		require.False(t, matched)
+	})
+}
+
+func TestExpression_MatchAttributes(t *testing.T) {
+	values := map[string]any{
+		"severity": "critical",
+		"category": "cti.a.p.am.category.v1.0~a.p.backup.v1.0",
+		"origin":   map[string]any{"host": "srv1"},
+	}
+
+	for _, tt := range []struct {
+		input string
+		want  bool
+	}{
+		{input: `cti.a.p.am.alert.v1.0[severity="critical"]`, want: true},
+		{input: `cti.a.p.am.alert.v1.0[severity="critical",origin.host="srv1"]`, want: true},
+		{input: `cti.a.p.am.alert.v1.0[category="cti.a.p.am.category.v1.0~a.p.*"]`, want: true},
+		{input: `cti.a.p.am.alert.v1.0[severity="low"]`, want: false},
+		{input: `cti.a.p.am.alert.v1.0[origin.port="80"]`, want: false},
+		{input: `cti.a.p.am.alert.v1.0`, want: true},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			expr := MustParse(tt.input)
+			ok, err := expr.MatchAttributes(values)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ok)
+		})
+	}
+
+	expr := MustParse("cti.a.p.am.alert.v1.0~a.p.alert.v1.0@origin.host")
+	val, ok := expr.LookupAttribute(values)
+	require.True(t, ok)
+	require.Equal(t, "srv1", val)
+
+	expr = MustParse("cti.a.p.am.alert.v1.0")
+	_, ok = expr.LookupAttribute(values)
+	require.False(t, ok)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AttributeNamePart is a part of the attribute name that selects either a key of an object or an item of an array.
+type AttributeNamePart struct {
+	// Key is a key of the object. It is empty if the part is an array index.
+	Key string
+	// Index is an index of the array item if IsIndex is true. It is -1 for the wildcard ("[*]") that selects all items.
+	Index   int
+	IsIndex bool
+}
+
+// IsWildcard reports whether the part selects all items of the array.
+func (p AttributeNamePart) IsWildcard() bool {
+	return p.IsIndex && p.Index == -1
+}
+
+// Parts splits the attribute name into keys and array indexes, e.g. items[0].name is split into
+// "items", [0] and "name". Escaped dots and backslashes ("\." and "\\") are unescaped in keys.
+// The attribute name is expected to be valid (see Parser.ParseAttributeSelector).
+func (n AttributeName) Parts() []AttributeNamePart {
+	var res []AttributeNamePart
+	s := string(n)
+	var key strings.Builder
+	inKey := false
+	flushKey := func() {
+		if inKey {
+			res = append(res, AttributeNamePart{Key: key.String()})
+			key.Reset()
+			inKey = false
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+			key.WriteByte(s[i])
+			inKey = true
+		case '.':
+			flushKey()
+		case '[':
+			flushKey()
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				end = len(s) - i
+			}
+			part := AttributeNamePart{Index: -1, IsIndex: true}
+			if idx := s[i+1 : i+end]; idx != "*" {
+				part.Index, _ = strconv.Atoi(idx)
+			}
+			res = append(res, part)
+			i += end
+		default:
+			key.WriteByte(c)
+			inKey = true
+		}
+	}
+	flushKey()
+	return res
+}
+
+// HasWildcard reports whether the attribute name has the wildcard array index ("[*]"),
+// so it may select several values.
+func (n AttributeName) HasWildcard() bool {
+	return strings.Contains(string(n), "[*]")
+}
+
+// WalkJSON returns values selected by the attribute name from the unmarshaled JSON value.
+// Keys select values of objects (map[string]any), indexes select items of arrays ([]any)
+// and the wildcard index selects all items. Values that are not found are skipped,
+// so the result is empty if nothing is selected.
+func (n AttributeName) WalkJSON(value any) []any {
+	cur := []any{value}
+	for _, part := range n.Parts() {
+		var next []any
+		for _, v := range cur {
+			switch {
+			case !part.IsIndex:
+				if m, ok := v.(map[string]any); ok {
+					if child, ok := m[part.Key]; ok {
+						next = append(next, child)
+					}
+				}
+			case part.IsWildcard():
+				if arr, ok := v.([]any); ok {
+					next = append(next, arr...)
+				}
+			default:
+				if arr, ok := v.([]any); ok && part.Index < len(arr) {
+					next = append(next, arr[part.Index])
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+// WalkJSONSchema returns schemas of values selected by the attribute name from the unmarshaled JSON schema.
+// Keys select "properties" of object schemas, indexes and the wildcard select "items" of array schemas.
+// Members of "anyOf" are walked separately, so several schemas may be returned for unions.
+// The schema must not have references (e.g. merged schemas of CTI types).
+func (n AttributeName) WalkJSONSchema(schema map[string]any) []map[string]any {
+	cur := expandAnyOf(nil, schema)
+	for _, part := range n.Parts() {
+		var next []map[string]any
+		for _, s := range cur {
+			if !part.IsIndex {
+				properties, _ := s["properties"].(map[string]any)
+				if child, ok := properties[part.Key].(map[string]any); ok {
+					next = expandAnyOf(next, child)
+				}
+				continue
+			}
+			if items, ok := s["items"].(map[string]any); ok {
+				next = expandAnyOf(next, items)
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+func expandAnyOf(res []map[string]any, schema map[string]any) []map[string]any {
+	members, ok := schema["anyOf"].([]any)
+	if !ok {
+		return append(res, schema)
+	}
+	for _, member := range members {
+		if m, ok := member.(map[string]any); ok {
+			res = expandAnyOf(res, m)
+		}
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeName_Parts(t *testing.T) {
+	require.Equal(t, []AttributeNamePart{
+		{Key: "items"},
+		{Index: 0, IsIndex: true},
+		{Key: "tags"},
+		{Index: -1, IsIndex: true},
+	}, AttributeName("items[0].tags[*]").Parts())
+	require.Equal(t, []AttributeNamePart{
+		{Key: "labels"},
+		{Key: "app.kubernetes.io"},
+	}, AttributeName(`labels.app\.kubernetes\.io`).Parts())
+	require.Equal(t, []AttributeNamePart{{Key: `a\b`}}, AttributeName(`a\\b`).Parts())
+}
+
+func TestAttributeName_WalkJSON(t *testing.T) {
+	values := map[string]any{
+		"items": []any{
+			map[string]any{"id": "a", "tags": []any{"x", "y"}},
+			map[string]any{"id": "b"},
+		},
+		"labels": map[string]any{"app.kubernetes.io": "cti"},
+	}
+
+	for _, tt := range []struct {
+		name AttributeName
+		want []any
+	}{
+		{name: "items[0].id", want: []any{"a"}},
+		{name: "items[*].id", want: []any{"a", "b"}},
+		{name: "items[*].tags[1]", want: []any{"y"}},
+		{name: "items[2].id"},
+		{name: "items.id"},
+		{name: `labels.app\.kubernetes\.io`, want: []any{"cti"}},
+	} {
+		t.Run(string(tt.name), func(t *testing.T) {
+			require.Equal(t, tt.want, tt.name.WalkJSON(values))
+		})
+	}
+
+	expr := MustParse("cti.a.p.am.alert.v1.0~a.p.alert.v1.0@items[*].id")
+	val, ok := expr.LookupAttribute(values)
+	require.True(t, ok)
+	require.Equal(t, []any{"a", "b"}, val)
+
+	expr = MustParse(`cti.a.p.am.alert.v1.0[items[*].id="b"]`)
+	matched, err := expr.MatchAttributes(values)
+	require.NoError(t, err)
+	require.True(t, matched)
+}
+
+func TestAttributeName_WalkJSONSchema(t *testing.T) {
+	id := map[string]any{"type": "string"}
+	count := map[string]any{"type": "integer"}
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"items": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"anyOf": []any{
+						map[string]any{"type": "object", "properties": map[string]any{"id": id}},
+						map[string]any{"type": "object", "properties": map[string]any{"id": count}},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, []map[string]any{id, count}, AttributeName("items[*].id").WalkJSONSchema(schema))
+	require.Equal(t, []map[string]any{id, count}, AttributeName("items[0].id").WalkJSONSchema(schema))
+	require.Nil(t, AttributeName("items.id").WalkJSONSchema(schema))
+}
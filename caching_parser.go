@@ -0,0 +1,134 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"container/list"
+	"sync"
+)
+
+type parseMethod uint8
+
+const (
+	parseMethodParse parseMethod = iota
+	parseMethodQuery
+	parseMethodAttributeSelector
+	parseMethodIdentifier
+	parseMethodReference
+)
+
+type cacheKey struct {
+	method parseMethod
+	input  string
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	expr Expression
+}
+
+// CachingParser is a Parser that keeps up to the specified number of recently parsed expressions,
+// so services that parse the same CTI strings repeatedly get the parsed expression without parsing.
+// Expressions are cached separately for each parsing method. Errors are not cached.
+//
+// Cached expressions are shared between callers, so they must not be modified.
+// CachingParser is safe for concurrent use.
+type CachingParser struct {
+	parser *Parser
+	size   int
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+}
+
+// NewCachingParser creates a new CachingParser that caches up to size expressions.
+// The least recently used expressions are evicted when the cache is full. If size is not positive, nothing is cached.
+// Options are the same as for NewParser.
+func NewCachingParser(size int, opts ...ParserOption) *CachingParser {
+	return &CachingParser{
+		parser:  NewParser(opts...),
+		size:    size,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Parse parses input string as a CTI expression. See Parser.Parse for more details.
+func (p *CachingParser) Parse(input string) (Expression, error) {
+	return p.parse(parseMethodParse, input, p.parser.Parse)
+}
+
+// ParseQuery parses input string as a CTI expression. See Parser.ParseQuery for more details.
+func (p *CachingParser) ParseQuery(input string) (Expression, error) {
+	return p.parse(parseMethodQuery, input, p.parser.ParseQuery)
+}
+
+// ParseAttributeSelector parses input string as a CTI expression. See Parser.ParseAttributeSelector for more details.
+func (p *CachingParser) ParseAttributeSelector(input string) (Expression, error) {
+	return p.parse(parseMethodAttributeSelector, input, p.parser.ParseAttributeSelector)
+}
+
+// ParseIdentifier parses input string as a CTI expression. See Parser.ParseIdentifier for more details.
+func (p *CachingParser) ParseIdentifier(input string) (Expression, error) {
+	return p.parse(parseMethodIdentifier, input, p.parser.ParseIdentifier)
+}
+
+// ParseReference parses input string as a CTI expression. See Parser.ParseReference for more details.
+func (p *CachingParser) ParseReference(input string) (Expression, error) {
+	return p.parse(parseMethodReference, input, p.parser.ParseReference)
+}
+
+// MustParse parses input string as a CTI expression and panics on error. See Parser.Parse for more details.
+func (p *CachingParser) MustParse(input string) Expression {
+	expr, err := p.Parse(input)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+// Len returns the number of cached expressions.
+func (p *CachingParser) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lru.Len()
+}
+
+func (p *CachingParser) parse(method parseMethod, input string, parse func(string) (Expression, error)) (Expression, error) {
+	key := cacheKey{method: method, input: input}
+
+	p.mu.Lock()
+	if el, ok := p.entries[key]; ok {
+		p.lru.MoveToFront(el)
+		expr := el.Value.(*cacheEntry).expr
+		p.mu.Unlock()
+		return expr, nil
+	}
+	p.mu.Unlock()
+
+	// Parsing is done without the lock, so concurrent callers may parse the same input.
+	// The expressions are equal, so the first one is kept.
+	expr, err := parse(input)
+	if err != nil || p.size <= 0 {
+		return expr, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if el, ok := p.entries[key]; ok {
+		p.lru.MoveToFront(el)
+		return el.Value.(*cacheEntry).expr, nil
+	}
+	p.entries[key] = p.lru.PushFront(&cacheEntry{key: key, expr: expr})
+	if p.lru.Len() > p.size {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return expr, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachingParser(t *testing.T) {
+	p := NewCachingParser(2)
+
+	expr, err := p.Parse("cti.a.p.event.v1.0")
+	require.NoError(t, err)
+	cached, err := p.Parse("cti.a.p.event.v1.0")
+	require.NoError(t, err)
+	require.Same(t, expr.Head, cached.Head)
+	require.Equal(t, 1, p.Len())
+
+	// Expressions are cached separately for each method, since methods accept different inputs.
+	_, err = p.ParseIdentifier("cti.a.p.event.v1")
+	require.ErrorIs(t, err, ErrMinorVersionMissing)
+	ref, err := p.ParseReference("cti.a.p.event.v1")
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.event.v1", ref.String())
+	require.Equal(t, 2, p.Len(), "errors are not cached")
+
+	// The least recently used expression is evicted.
+	_, err = p.Parse("cti.a.p.event.v1.0")
+	require.NoError(t, err)
+	_, err = p.ParseQuery(`cti.a.p.event.v1.0[severity="critical"]`)
+	require.NoError(t, err)
+	require.Equal(t, 2, p.Len())
+	again, err := p.Parse("cti.a.p.event.v1.0")
+	require.NoError(t, err)
+	require.Same(t, expr.Head, again.Head)
+	again, err = p.ParseReference("cti.a.p.event.v1")
+	require.NoError(t, err)
+	require.NotSame(t, ref.Head, again.Head)
+
+	_, err = p.ParseAttributeSelector("cti.a.p.event.v1.0")
+	require.ErrorIs(t, err, ErrAttributeSelectorMissing)
+	require.Panics(t, func() { p.MustParse("invalid") })
+
+	uncached := NewCachingParser(0)
+	_, err = uncached.Parse("cti.a.p.event.v1.0")
+	require.NoError(t, err)
+	require.Equal(t, 0, uncached.Len())
+}
+
+func TestCachingParser_Concurrent(t *testing.T) {
+	p := NewCachingParser(8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				input := fmt.Sprintf("cti.a.p.event.v1.%d", (i+j)%16)
+				expr, err := p.Parse(input)
+				require.NoError(t, err)
+				require.Equal(t, input, expr.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, 8, p.Len())
+}
+
+// ---------------------- Benchmarks ----------------------
+
+func BenchmarkCachingParser_Parse_Identifier(b *testing.B) {
+	p := NewCachingParser(len(benchParseExprIdentifiers))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := p.Parse(benchParseExprIdentifiers[i%len(benchParseExprIdentifiers)])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCachingParser_Parse_Query(b *testing.B) {
+	rawExp := `cti.a.p.am.alert.v1.0[type="cti.a.p.am.alert.v1.0~sophos.endpoint_protection.*"]`
+	p := NewCachingParser(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := p.Parse(rawExp)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
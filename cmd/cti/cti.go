@@ -10,18 +10,34 @@ import (
 	"strings"
 
 	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/codegencmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/compatcmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/deploycmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/deprecationscmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/diffcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/docscmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/envcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/examplecmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/exportcmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/fmtcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/generatecmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/infocmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/initcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/legacycheckcmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/lintcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/lspcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/ownerscmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/packcmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/pkgcmd"
-	"github.com/acronis/go-cti/cmd/cti/internal/commands/restcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/querycmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/renamecmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/servecmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/statscmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/synccmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/testcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/treecmd"
 	"github.com/acronis/go-cti/cmd/cti/internal/commands/validatecmd"
+	"github.com/acronis/go-cti/metadata/validator"
 	"github.com/acronis/go-stacktrace"
 	slogex "github.com/acronis/go-stacktrace/slogex"
 	"github.com/mattn/go-isatty"
@@ -31,7 +47,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func initLogging(verbose bool) {
+func initLogging(verbose bool, format string) {
 	logLvl := func() slog.Level {
 		if verbose {
 			return slog.LevelDebug
@@ -40,6 +56,11 @@ func initLogging(verbose bool) {
 	}()
 	w := os.Stderr
 
+	if format == command.LogFormatJSON {
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: logLvl})))
+		return
+	}
+
 	logger := slog.New(
 		slogformatter.NewFormatterHandler(
 			slogformatter.HTTPRequestFormatter(false),
@@ -88,23 +109,43 @@ func mainFn() int {
 					os.Exit(1)
 				}
 
-				initLogging(verbose)
-			},
-			CompletionOptions: cobra.CompletionOptions{
-				DisableDefaultCmd: true,
+				format, err := command.GetLogFormat(cmd)
+				if err != nil {
+					fmt.Printf("Failed to get log format: %v\n", err)
+					os.Exit(1)
+				}
+
+				initLogging(verbose, format)
 			},
 		}
 
 		command.AddWorkDirFlag(cmd)
+		command.AddLogFormatFlag(cmd)
 
 		cmd.PersistentFlags().BoolP(verboseFlag, "v", false, "verbose output")
 		cmd.Flags().BoolVarP(&ensureDuplicates, "ensure-duplicates", "d", false, "ensure that there are no duplicates in tracebacks")
 
 		cmd.AddCommand(
+			codegencmd.New(ctx),
+			compatcmd.New(ctx),
+			deprecationscmd.New(ctx),
+			diffcmd.New(ctx),
+			docscmd.New(ctx),
+			examplecmd.New(ctx),
+			exportcmd.New(ctx),
+			generatecmd.New(ctx),
 			initcmd.New(ctx),
+			legacycheckcmd.New(ctx),
+			lspcmd.New(ctx),
+			ownerscmd.New(ctx),
 			packcmd.New(ctx),
 			pkgcmd.New(ctx),
+			querycmd.New(ctx),
+			renamecmd.New(ctx),
+			servecmd.New(ctx),
+			statscmd.New(ctx),
 			synccmd.New(ctx),
+			treecmd.New(ctx),
 			validatecmd.New(ctx),
 			// TODO implement
 			deploycmd.New(ctx),
@@ -112,14 +153,13 @@ func mainFn() int {
 			fmtcmd.New(ctx),
 			infocmd.New(ctx),
 			lintcmd.New(ctx),
-			restcmd.New(ctx),
 			testcmd.New(ctx),
 			&cobra.Command{
 				Use:   "version",
 				Short: "print a version of tool",
 				Args:  cobra.MinimumNArgs(0),
-				RunE: func(_ *cobra.Command, args []string) error {
-					// TODO: implement in-place solution
+				RunE: func(cmd *cobra.Command, args []string) error {
+					fmt.Fprintln(cmd.OutOrStdout(), command.ToolVersion())
 					return nil
 				},
 			},
@@ -137,7 +177,13 @@ func mainFn() int {
 				return []stacktrace.TracesOpt{}
 			}()
 
-			slog.Error("Command failed", slogex.ErrToSlogAttr(cmdErr.Inner, stOpts...))
+			inner := cmdErr.Inner
+			// Errors of the validation are aggregated, so they are logged as a stack trace to keep their locations.
+			var aggErr *validator.AggregateError
+			if errors.As(inner, &aggErr) {
+				inner = aggErr.StackTrace()
+			}
+			slog.Error("Command failed", slogex.ErrToSlogAttr(inner, stOpts...))
 		} else {
 			_ = rootCmd.Usage()
 		}
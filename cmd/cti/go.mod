@@ -3,6 +3,7 @@ module github.com/acronis/go-cti/cmd/cti
 go 1.22.6
 
 require (
+	github.com/acronis/go-cti v1.1.0
 	github.com/acronis/go-cti/metadata v0.32.0
 	github.com/acronis/go-stacktrace v0.4.0
 	github.com/acronis/go-stacktrace/slogex v0.3.0
@@ -13,18 +14,17 @@ require (
 )
 
 require (
-	github.com/acronis/go-cti v1.0.0 // indirect
-	github.com/acronis/go-cti/metadata/ramlx v1.3.0 // indirect
-	github.com/acronis/go-raml v0.19.0 // indirect
+	github.com/acronis/go-cti/metadata/ramlx v1.4.0 // indirect
+	github.com/acronis/go-raml v1.20.0 // indirect
 	github.com/antlr4-go/antlr/v4 v4.13.0 // indirect
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/blang/semver/v4 v4.0.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.5 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/otiai10/copy v1.14.0 // indirect
-	github.com/rogpeppe/go-internal v1.13.1 // indirect
 	github.com/samber/lo v1.47.0 // indirect
 	github.com/samber/slog-multi v1.2.4 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
@@ -39,7 +39,7 @@ require (
 	golang.org/x/exp v0.0.0-20230515195305-f3d0a9c9a5cc // indirect
 	golang.org/x/mod v0.21.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
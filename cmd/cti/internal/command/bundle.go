@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/spf13/cobra"
+)
+
+// StdinPath is a path that refers to the standard input.
+const StdinPath = "-"
+
+// ReadBundle reads a packed package from the file or from the standard input of the command if the path is "-".
+func ReadBundle(cmd *cobra.Command, path string) (*ctipackage.Bundle, error) {
+	var r io.Reader
+	if path == StdinPath {
+		r = cmd.InOrStdin()
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open bundle: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := ctipackage.ReadBundle(r)
+	if err != nil {
+		return nil, fmt.Errorf("read bundle: %w", err)
+	}
+	return b, nil
+}
+
+// ReadRegistry returns the global registry of the package in the directory, i.e. entities of the package
+// and its dependencies. If the bundle path is set, the registry is read from the packed package instead (see ReadBundle),
+// which must hold entities of the dependencies of the package.
+func ReadRegistry(cmd *cobra.Command, baseDir string, bundle string) (*collector.MetadataRegistry, error) {
+	if bundle != "" {
+		b, err := ReadBundle(cmd, bundle)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.CheckDependencies(); err != nil {
+			return nil, fmt.Errorf("check bundle: %w", err)
+		}
+		return b.Registry, nil
+	}
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return nil, fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return nil, fmt.Errorf("parse package: %w", err)
+	}
+	return pkg.GlobalRegistry, nil
+}
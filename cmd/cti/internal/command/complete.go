@@ -0,0 +1,31 @@
+package command
+
+import (
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/spf13/cobra"
+)
+
+// maxCompletions limits the number of CTIs offered by CompleteCti, so shells stay responsive on huge registries.
+const maxCompletions = 1000
+
+// CompleteCti offers CTIs of the package in the working directory and of its dependencies starting with toComplete.
+// CTIs are looked up in the cache files written by parsing the package, so nothing is offered before the first parse.
+// It is meant to be used in ValidArgsFunction of commands.
+func CompleteCti(cmd *cobra.Command, toComplete string) ([]string, cobra.ShellCompDirective) {
+	baseDir, err := GetWorkingDir(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if err := pkg.Read(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	r, err := pkg.ReadCache()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return r.CompleteCti(toComplete, maxCompletions), cobra.ShellCompDirectiveNoFileComp
+}
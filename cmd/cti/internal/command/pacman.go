@@ -1,6 +1,8 @@
 package command
 
 import (
+	"log/slog"
+
 	"github.com/acronis/go-cti/metadata/pacman"
 	"github.com/acronis/go-cti/metadata/storage/gitstorage"
 	"github.com/spf13/cobra"
@@ -9,5 +11,23 @@ import (
 func InitializePackageManager(_ *cobra.Command) (pacman.PackageManager, error) { // get option from command
 	return pacman.New(
 		pacman.WithStorage(gitstorage.New()),
+		pacman.WithDownloadProgress(logDownloadProgress),
 	)
 }
+
+func logDownloadProgress(p pacman.DownloadProgress) {
+	switch p.Status {
+	case pacman.DownloadRetrying:
+		slog.Warn("Retry download",
+			slog.String("package", p.Source),
+			slog.String("version", p.Version),
+			slog.Int("attempt", p.Attempt),
+			slog.String("error", p.Err.Error()))
+	case pacman.DownloadSucceeded:
+		slog.Info("Downloaded dependency",
+			slog.String("package", p.Source),
+			slog.String("version", p.Version),
+			slog.Int("done", p.Done),
+			slog.Int("total", p.Total))
+	}
+}
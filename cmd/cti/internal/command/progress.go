@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+const (
+	LogFormatFlag = "log-format"
+
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+
+	progressBarWidth = 30
+)
+
+func AddLogFormatFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(LogFormatFlag, LogFormatText, "log format: text or json")
+}
+
+func GetLogFormat(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Flags().GetString(LogFormatFlag)
+	if err != nil {
+		return "", fmt.Errorf("get log-format flag: %w", err)
+	}
+	switch format {
+	case LogFormatText, LogFormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported log format %s", format)
+	}
+}
+
+// NewProgressReporter makes a reporter of package operations. A progress bar is rendered if the standard error
+// is a terminal and logs are in text format. Otherwise, e.g. in CI, progress is logged.
+// In both cases the timing of each phase is logged when the phase finishes.
+func NewProgressReporter(cmd *cobra.Command) ctipackage.ProgressReporter {
+	format, _ := cmd.Flags().GetString(LogFormatFlag)
+	if f, ok := cmd.ErrOrStderr().(*os.File); ok && format != LogFormatJSON && isatty.IsTerminal(f.Fd()) {
+		return &progressBar{w: f}
+	}
+	return &progressLogger{}
+}
+
+// progressLogger logs progress of package operations.
+type progressLogger struct{}
+
+func (p *progressLogger) PhaseStarted(phase ctipackage.Phase, total int) {
+	slog.Info("Phase started", slog.String("phase", string(phase)), slog.Int("total", total))
+}
+
+func (p *progressLogger) ItemDone(phase ctipackage.Phase, done int, item string) {
+	slog.Debug("Item done", slog.String("phase", string(phase)), slog.Int("done", done), slog.String("item", item))
+}
+
+func (p *progressLogger) PhaseFinished(stats ctipackage.PhaseStats) {
+	logPhaseFinished(stats)
+}
+
+// progressBar renders progress of package operations in the terminal.
+type progressBar struct {
+	w     io.Writer
+	total int
+}
+
+func (p *progressBar) PhaseStarted(phase ctipackage.Phase, total int) {
+	p.total = total
+	p.render(phase, 0)
+}
+
+func (p *progressBar) ItemDone(phase ctipackage.Phase, done int, _ string) {
+	p.render(phase, done)
+}
+
+func (p *progressBar) PhaseFinished(stats ctipackage.PhaseStats) {
+	// Clear the line before the bar is replaced by the log record.
+	fmt.Fprintf(p.w, "\r%s\r", strings.Repeat(" ", progressBarWidth+40))
+	logPhaseFinished(stats)
+}
+
+func (p *progressBar) render(phase ctipackage.Phase, done int) {
+	filled := progressBarWidth
+	if p.total > 0 && done < p.total {
+		filled = progressBarWidth * done / p.total
+	}
+	fmt.Fprintf(p.w, "\r%-8s [%s%s] %d/%d",
+		phase, strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), done, p.total)
+}
+
+func logPhaseFinished(stats ctipackage.PhaseStats) {
+	attrs := []any{
+		slog.String("phase", string(stats.Phase)),
+		slog.Int("items", stats.Items),
+		slog.Duration("duration", stats.Duration),
+	}
+	if stats.Err != nil {
+		slog.Warn("Phase failed", attrs...)
+		return
+	}
+	slog.Info("Phase finished", attrs...)
+}
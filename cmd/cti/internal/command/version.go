@@ -0,0 +1,20 @@
+package command
+
+import (
+	"runtime/debug"
+)
+
+// Version may be set at build time with -ldflags "-X github.com/acronis/go-cti/cmd/cti/internal/command.Version=...".
+var Version = ""
+
+// ToolVersion returns the version of the tool.
+// If Version is not set at build time, the module version from the build information is used.
+func ToolVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,21 @@
+package codegencmd
+
+import (
+	"context"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/codegencmd/graphqlcmd"
+	"github.com/acronis/go-cti/cmd/cti/internal/commands/codegencmd/protobufcmd"
+	"github.com/spf13/cobra"
+)
+
+func New(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "codegen",
+		Short: "generate code from cti types",
+	}
+	cmd.AddCommand(
+		graphqlcmd.New(ctx),
+		protobufcmd.New(ctx),
+	)
+	return cmd
+}
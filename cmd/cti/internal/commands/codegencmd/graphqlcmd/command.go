@@ -0,0 +1,108 @@
+package graphqlcmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/graphql"
+
+	"github.com/spf13/cobra"
+)
+
+type GraphQLOptions struct {
+	Scalars      []string
+	Output       string
+	Traits       bool
+	TraitKeys    []string
+	OnlyReleased bool
+}
+
+func New(ctx context.Context) *cobra.Command {
+	graphqlOpts := GraphQLOptions{}
+	cmd := &cobra.Command{
+		Use:   "graphql [cti expression]",
+		Short: "generate GraphQL SDL type definitions of cti types",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			var filter string
+			if len(args) > 0 {
+				filter = args[0]
+			}
+
+			return command.WrapError(execute(ctx, baseDir, filter, graphqlOpts))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&graphqlOpts.Scalars, "scalar", nil,
+		"Mapping of JSON schema type or format to GraphQL scalar in form key=Scalar.")
+	cmd.Flags().BoolVar(&graphqlOpts.Traits, "traits", false, "Include traits of types merged with traits of their ancestors.")
+	cmd.Flags().StringSliceVar(&graphqlOpts.TraitKeys, "trait", nil, "Include only the specified top-level traits. Implies --traits.")
+	cmd.Flags().StringVarP(&graphqlOpts.Output, "output", "o", "", "Output file. Standard output is used by default.")
+	cmd.Flags().BoolVar(&graphqlOpts.OnlyReleased, "only-released", false, "Exclude draft and retired entities and their descendants.")
+
+	return cmd
+}
+
+func execute(_ context.Context, baseDir string, filter string, opts GraphQLOptions) error {
+	genOpts := []graphql.Option{graphql.WithFilter(filter)}
+	if opts.Traits || len(opts.TraitKeys) != 0 {
+		genOpts = append(genOpts, graphql.WithTraits(opts.TraitKeys...))
+	}
+	for _, item := range opts.Scalars {
+		key, scalar, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("invalid scalar mapping %s", item)
+		}
+		genOpts = append(genOpts, graphql.WithScalar(key, scalar))
+	}
+
+	slog.Info("Generating GraphQL schema", slog.String("path", baseDir))
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.Output != "" {
+		f, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	registry := pkg.GlobalRegistry
+	if opts.OnlyReleased {
+		registry = registry.ReleasedView()
+	}
+	if err := graphql.Generate(w, registry, genOpts...); err != nil {
+		return fmt.Errorf("generate graphql schema: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
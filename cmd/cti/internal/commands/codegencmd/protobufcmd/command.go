@@ -0,0 +1,99 @@
+package protobufcmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/protobuf"
+
+	"github.com/spf13/cobra"
+)
+
+type ProtobufOptions struct {
+	Package      string
+	Output       string
+	Traits       bool
+	TraitKeys    []string
+	OnlyReleased bool
+}
+
+func New(ctx context.Context) *cobra.Command {
+	protobufOpts := ProtobufOptions{}
+	cmd := &cobra.Command{
+		Use:   "protobuf [cti expression]",
+		Short: "generate protobuf message definitions of cti types",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			var filter string
+			if len(args) > 0 {
+				filter = args[0]
+			}
+
+			return command.WrapError(execute(ctx, baseDir, filter, protobufOpts))
+		},
+	}
+
+	cmd.Flags().StringVar(&protobufOpts.Package, "package", protobuf.DefaultPackage, "Name of the protobuf package.")
+	cmd.Flags().BoolVar(&protobufOpts.Traits, "traits", false, "Include traits of types merged with traits of their ancestors.")
+	cmd.Flags().StringSliceVar(&protobufOpts.TraitKeys, "trait", nil, "Include only the specified top-level traits. Implies --traits.")
+	cmd.Flags().StringVarP(&protobufOpts.Output, "output", "o", "", "Output file. Standard output is used by default.")
+	cmd.Flags().BoolVar(&protobufOpts.OnlyReleased, "only-released", false, "Exclude draft and retired entities and their descendants.")
+
+	return cmd
+}
+
+func execute(_ context.Context, baseDir string, filter string, opts ProtobufOptions) error {
+	genOpts := []protobuf.Option{protobuf.WithFilter(filter), protobuf.WithPackage(opts.Package)}
+	if opts.Traits || len(opts.TraitKeys) != 0 {
+		genOpts = append(genOpts, protobuf.WithTraits(opts.TraitKeys...))
+	}
+
+	slog.Info("Generating protobuf schema", slog.String("path", baseDir))
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.Output != "" {
+		f, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	registry := pkg.GlobalRegistry
+	if opts.OnlyReleased {
+		registry = registry.ReleasedView()
+	}
+	if err := protobuf.Generate(w, registry, genOpts...); err != nil {
+		return fmt.Errorf("generate protobuf schema: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
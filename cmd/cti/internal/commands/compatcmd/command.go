@@ -0,0 +1,97 @@
+package compatcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type CompatOptions struct {
+	Baseline string
+	Exempt   []string
+	Format   string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	compatOpts := CompatOptions{}
+	cmd := &cobra.Command{
+		Use:   "compat",
+		Short: "check the package for breaking changes against the published baseline",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd.OutOrStdout(), baseDir, compatOpts))
+		},
+	}
+
+	cmd.Flags().StringVar(&compatOpts.Baseline, "baseline", "", "Directory or bundle of the published version of the package.")
+	cmd.Flags().StringSliceVar(&compatOpts.Exempt, "exempt", nil, "CTI expressions of types that are not checked.")
+	cmd.Flags().StringVarP(&compatOpts.Format, "format", "f", FormatText, "Output format: text or json.")
+	_ = cmd.MarkFlagRequired("baseline")
+
+	return cmd
+}
+
+func execute(_ context.Context, w io.Writer, baseDir string, opts CompatOptions) error {
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	baseline, err := ctipackage.LoadBaseline(opts.Baseline)
+	if err != nil {
+		return fmt.Errorf("load baseline: %w", err)
+	}
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	changes, err := collector.CheckCompatibility(baseline, pkg.GlobalRegistry, collector.WithCompatExemptions(opts.Exempt...))
+	if err != nil {
+		return fmt.Errorf("check compatibility: %w", err)
+	}
+
+	if opts.Format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if changes == nil {
+			changes = []collector.BreakingChange{}
+		}
+		if err := enc.Encode(changes); err != nil {
+			return fmt.Errorf("encode changes: %w", err)
+		}
+	} else {
+		for _, change := range changes {
+			fmt.Fprintln(w, change)
+		}
+	}
+
+	if len(changes) != 0 {
+		return fmt.Errorf("found %d breaking changes", len(changes))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package deprecationscmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+
+	"github.com/spf13/cobra"
+)
+
+func New(ctx context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "deprecations",
+		Short: "print deprecated cti types and entities that depend on them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd.OutOrStdout(), baseDir))
+		},
+	}
+}
+
+func execute(_ context.Context, w io.Writer, baseDir string) error {
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	r := pkg.GlobalRegistry
+	var ids []string
+	for id, typ := range r.Types {
+		if typ.Deprecated {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		typ := r.Types[id]
+		line := id
+		if typ.DeprecationMessage != "" {
+			line += ": " + typ.DeprecationMessage
+		}
+		if typ.ReplacedBy != "" {
+			line += fmt.Sprintf(" (replaced by %s)", typ.ReplacedBy)
+		}
+		fmt.Fprintln(w, line)
+
+		dependents, err := r.GetDependents(id)
+		if err != nil {
+			return fmt.Errorf("get dependents of %s: %w", id, err)
+		}
+		for _, dependent := range dependents {
+			marker := ""
+			if r.Index[dependent].Deprecated {
+				marker = " (deprecated)"
+			}
+			fmt.Fprintf(w, "  %s%s\n", dependent, marker)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package diffcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type DiffOptions struct {
+	Format string
+}
+
+type result struct {
+	Traits *collector.TraitsDiff `json:"traits"`
+}
+
+func New(ctx context.Context) *cobra.Command {
+	diffOpts := DiffOptions{}
+	cmd := &cobra.Command{
+		Use:   "diff <old cti> <new cti>",
+		Short: "print differences between two versions of the cti entity",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) >= 2 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return command.CompleteCti(cmd, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd.OutOrStdout(), baseDir, args[0], args[1], diffOpts))
+		},
+	}
+
+	cmd.Flags().StringVarP(&diffOpts.Format, "format", "f", FormatText, "Output format: text or json.")
+
+	return cmd
+}
+
+func execute(_ context.Context, w io.Writer, baseDir string, oldCti, newCti string, opts DiffOptions) error {
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	traits, err := pkg.GlobalRegistry.DiffTraits(oldCti, newCti)
+	if err != nil {
+		return fmt.Errorf("diff traits: %w", err)
+	}
+
+	if opts.Format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result{Traits: traits}); err != nil {
+			return fmt.Errorf("encode diff: %w", err)
+		}
+		return nil
+	}
+	return writeText(w, result{Traits: traits})
+}
+
+func writeText(w io.Writer, res result) error {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", res.Traits.Old, res.Traits.New)
+	if res.Traits.IsEmpty() {
+		fmt.Fprintln(w, "No changes")
+		return nil
+	}
+	if err := writeChanges(w, "Traits", res.Traits.Traits); err != nil {
+		return err
+	}
+	if err := writeChanges(w, "Traits schema", res.Traits.TraitsSchema); err != nil {
+		return err
+	}
+	return writeAnnotationChanges(w, "Traits annotations", res.Traits.TraitsAnnotations)
+}
+
+func writeChanges(w io.Writer, title string, changes []collector.TraitChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, change := range changes {
+		oldVal, err := json.Marshal(change.Old)
+		if err != nil {
+			return fmt.Errorf("encode value of %s: %w", change.Path, err)
+		}
+		newVal, err := json.Marshal(change.New)
+		if err != nil {
+			return fmt.Errorf("encode value of %s: %w", change.Path, err)
+		}
+		switch change.Kind {
+		case collector.ChangeAdded:
+			fmt.Fprintf(w, "  + %s: %s\n", change.Path, newVal)
+		case collector.ChangeRemoved:
+			fmt.Fprintf(w, "  - %s: %s\n", change.Path, oldVal)
+		case collector.ChangeChanged:
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", change.Path, oldVal, newVal)
+		}
+	}
+	return nil
+}
+
+func writeAnnotationChanges(w io.Writer, title string, changes []collector.AnnotationChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, change := range changes {
+		oldVal, err := json.Marshal(change.Old)
+		if err != nil {
+			return fmt.Errorf("encode %s of %s: %w", change.Name, change.Path, err)
+		}
+		newVal, err := json.Marshal(change.New)
+		if err != nil {
+			return fmt.Errorf("encode %s of %s: %w", change.Name, change.Path, err)
+		}
+		switch {
+		case change.Old == nil:
+			fmt.Fprintf(w, "  + %s %s: %s\n", change.Path, change.Name, newVal)
+		case change.New == nil:
+			fmt.Fprintf(w, "  - %s %s: %s\n", change.Path, change.Name, oldVal)
+		default:
+			fmt.Fprintf(w, "  ~ %s %s: %s -> %s\n", change.Path, change.Name, oldVal, newVal)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package docscmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/docgen"
+
+	"github.com/spf13/cobra"
+)
+
+type DocsOptions struct {
+	Output       string
+	OnlyReleased bool
+	Tags         []string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	docsOpts := DocsOptions{}
+	cmd := &cobra.Command{
+		Use:   "docs [cti expression]",
+		Short: "generate markdown documentation of cti entities",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			var filter string
+			if len(args) > 0 {
+				filter = args[0]
+			}
+
+			return command.WrapError(execute(ctx, baseDir, filter, docsOpts))
+		},
+	}
+
+	cmd.Flags().StringVarP(&docsOpts.Output, "output", "o", "docs", "Output directory.")
+	cmd.Flags().BoolVar(&docsOpts.OnlyReleased, "only-released", false, "Exclude draft and retired entities and their descendants.")
+	cmd.Flags().StringSliceVarP(&docsOpts.Tags, "tag", "t", nil, "Document only entities with any of the specified tags and their ancestors.")
+
+	return cmd
+}
+
+func execute(_ context.Context, baseDir string, filter string, opts DocsOptions) error {
+	slog.Info("Generating documentation", slog.String("path", baseDir), slog.String("output", opts.Output))
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	registry := pkg.GlobalRegistry
+	if opts.OnlyReleased {
+		registry = registry.ReleasedView()
+	}
+	if len(opts.Tags) != 0 {
+		registry = registry.TaggedView(opts.Tags...)
+	}
+	if err := docgen.WriteDir(opts.Output, registry, filter, docgen.WithAssetStore(pkg.AssetStore())); err != nil {
+		return fmt.Errorf("write documentation: %w", err)
+	}
+	return nil
+}
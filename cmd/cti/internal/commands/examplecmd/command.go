@@ -0,0 +1,55 @@
+package examplecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/datagen"
+
+	"github.com/spf13/cobra"
+)
+
+func New(ctx context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "example <cti>",
+		Short: "print an example instance of cti type",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd, baseDir, args[0]))
+		},
+	}
+}
+
+func execute(_ context.Context, cmd *cobra.Command, baseDir string, typeCti string) error {
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	example, err := datagen.Example(pkg.GlobalRegistry, typeCti)
+	if err != nil {
+		return fmt.Errorf("generate example: %w", err)
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(example); err != nil {
+		return fmt.Errorf("write example: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package exportcmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/merger"
+	"github.com/acronis/go-cti/metadata/tabular"
+
+	"github.com/spf13/cobra"
+)
+
+// FormatJSONSchema exports the merged schema of the type instead of its instances (see merger.ExportCtiSchema).
+const FormatJSONSchema = "jsonschema"
+
+type ExportOptions struct {
+	Type   string
+	Format string
+	Output string
+	// Extensions keeps vendor extensions (x-custom) in exported schemas.
+	Extensions   bool
+	OnlyReleased bool
+	Tags         []string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	exportOpts := ExportOptions{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export values of instances of cti type as a table",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			return command.WrapError(execute(ctx, baseDir, exportOpts))
+		},
+	}
+
+	cmd.Flags().StringVar(&exportOpts.Type, "type", "", "CTI of the type which instances are exported.")
+	cmd.Flags().StringVarP(&exportOpts.Format, "format", "f", tabular.FormatCSV, "Output format: csv, xlsx or jsonschema.")
+	cmd.Flags().StringVarP(&exportOpts.Output, "output", "o", "", "Output file. Standard output is used by default.")
+	cmd.Flags().BoolVar(&exportOpts.Extensions, "extensions", true, "Keep vendor extensions (x-custom) in the exported schema. Used with jsonschema format.")
+	cmd.Flags().BoolVar(&exportOpts.OnlyReleased, "only-released", false, "Exclude draft and retired entities and their descendants.")
+	cmd.Flags().StringSliceVarP(&exportOpts.Tags, "tag", "t", nil, "Export only instances with any of the specified tags.")
+	_ = cmd.MarkFlagRequired("type")
+
+	return cmd
+}
+
+func execute(_ context.Context, baseDir string, opts ExportOptions) error {
+	slog.Info("Exporting instances", slog.String("path", baseDir), slog.String("type", opts.Type))
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.Output != "" {
+		f, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	registry := pkg.GlobalRegistry
+	if opts.OnlyReleased {
+		registry = registry.ReleasedView()
+	}
+	if len(opts.Tags) != 0 {
+		registry = registry.TaggedView(opts.Tags...)
+	}
+	if opts.Format == FormatJSONSchema {
+		if err := exportSchema(w, registry, opts); err != nil {
+			return err
+		}
+	} else if err := tabular.Export(w, registry, opts.Type, tabular.WithFormat(opts.Format)); err != nil {
+		return fmt.Errorf("export instances: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
+
+func exportSchema(w io.Writer, registry *collector.MetadataRegistry, opts ExportOptions) error {
+	var exportOpts []merger.ExportOption
+	if !opts.Extensions {
+		exportOpts = append(exportOpts, merger.WithoutExtensions())
+	}
+	schema, err := merger.ExportCtiSchema(opts.Type, registry, exportOpts...)
+	if err != nil {
+		return fmt.Errorf("export schema: %w", err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(schema); err != nil {
+		return fmt.Errorf("encode schema: %w", err)
+	}
+	return nil
+}
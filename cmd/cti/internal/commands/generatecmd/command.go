@@ -0,0 +1,153 @@
+package generatecmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/datagen"
+
+	"github.com/spf13/cobra"
+)
+
+type GenerateOptions struct {
+	Count        int
+	Seed         int64
+	StringLength string
+	ArraySize    string
+	EnumWeights  []string
+	Output       string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	generateOpts := GenerateOptions{}
+	cmd := &cobra.Command{
+		Use:   "generate <cti>",
+		Short: "generate instances of cti type as JSON lines",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			return command.WrapError(execute(ctx, baseDir, args[0], generateOpts))
+		},
+	}
+
+	cmd.Flags().IntVarP(&generateOpts.Count, "count", "n", 1, "Number of instances to generate.")
+	cmd.Flags().Int64Var(&generateOpts.Seed, "seed", 1, "Seed of the pseudo-random generator.")
+	cmd.Flags().StringVar(&generateOpts.StringLength, "string-length", "", "Range of string lengths in form min:max.")
+	cmd.Flags().StringVar(&generateOpts.ArraySize, "array-size", "", "Range of array sizes in form min:max.")
+	cmd.Flags().StringSliceVar(&generateOpts.EnumWeights, "enum-weight", nil, "Weight of enum value in form value=weight.")
+	cmd.Flags().StringVarP(&generateOpts.Output, "output", "o", "", "Output file. Standard output is used by default.")
+
+	return cmd
+}
+
+func execute(_ context.Context, baseDir string, typeCti string, opts GenerateOptions) error {
+	genOpts, err := opts.generatorOptions()
+	if err != nil {
+		return err
+	}
+	gen, err := datagen.New(genOpts...)
+	if err != nil {
+		return fmt.Errorf("new generator: %w", err)
+	}
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.Output != "" {
+		f, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	slog.Info("Generating instances", slog.String("type", typeCti), slog.Int("count", opts.Count))
+	if err := gen.WriteJSONL(w, pkg.GlobalRegistry, typeCti, opts.Count); err != nil {
+		return fmt.Errorf("generate instances: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
+
+func (opts GenerateOptions) generatorOptions() ([]datagen.Option, error) {
+	genOpts := []datagen.Option{datagen.WithSeed(opts.Seed)}
+	if opts.StringLength != "" {
+		lo, hi, err := parseRange(opts.StringLength)
+		if err != nil {
+			return nil, fmt.Errorf("parse string length: %w", err)
+		}
+		genOpts = append(genOpts, datagen.WithStringLength(lo, hi))
+	}
+	if opts.ArraySize != "" {
+		lo, hi, err := parseRange(opts.ArraySize)
+		if err != nil {
+			return nil, fmt.Errorf("parse array size: %w", err)
+		}
+		genOpts = append(genOpts, datagen.WithArraySize(lo, hi))
+	}
+	if len(opts.EnumWeights) != 0 {
+		weights := make(map[string]float64, len(opts.EnumWeights))
+		for _, item := range opts.EnumWeights {
+			value, weight, ok := strings.Cut(item, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid enum weight %s", item)
+			}
+			w, err := strconv.ParseFloat(weight, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse enum weight %s: %w", item, err)
+			}
+			// Plain strings are accepted without quotes.
+			if !json.Valid([]byte(value)) {
+				data, _ := json.Marshal(value)
+				value = string(data)
+			}
+			weights[value] = w
+		}
+		genOpts = append(genOpts, datagen.WithEnumWeights(weights))
+	}
+	return genOpts, nil
+}
+
+func parseRange(s string) (int, int, error) {
+	minStr, maxStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %s", s)
+	}
+	lo, err := strconv.Atoi(minStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse minimum: %w", err)
+	}
+	hi, err := strconv.Atoi(maxStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse maximum: %w", err)
+	}
+	return lo, hi, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/acronis/go-cti/cmd/cti/internal/command"
 	"github.com/acronis/go-cti/metadata/ctipackage"
@@ -11,8 +12,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type InitOptions struct {
+	ID       string
+	Template string
+}
+
 func New(ctx context.Context) *cobra.Command {
-	return &cobra.Command{
+	initOpts := InitOptions{}
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "generate cti project with default dependencies",
 		Args:  cobra.MinimumNArgs(0),
@@ -22,15 +29,29 @@ func New(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("get working directory: %w", err)
 			}
 
-			return command.WrapError(execute(ctx, baseDir))
+			return command.WrapError(execute(ctx, baseDir, initOpts))
 		},
 	}
+
+	templates := make([]string, 0, len(ctipackage.Templates()))
+	for _, t := range ctipackage.Templates() {
+		templates = append(templates, string(t))
+	}
+	cmd.Flags().StringVar(&initOpts.ID, "id", "", "Package ID in <vendor>.<package> format.")
+	cmd.Flags().StringVar(&initOpts.Template, "template", "",
+		fmt.Sprintf("Project template to scaffold: %s. Requires --id.", strings.Join(templates, ", ")))
+
+	return cmd
 }
 
-func execute(_ context.Context, baseDir string) error {
+func execute(_ context.Context, baseDir string, opts InitOptions) error {
 	slog.Info("Initialize package", slog.String("path", baseDir))
 
-	pkg, err := ctipackage.New(baseDir)
+	var pkgOpts []ctipackage.InitializeOption
+	if opts.ID != "" {
+		pkgOpts = append(pkgOpts, ctipackage.WithID(opts.ID))
+	}
+	pkg, err := ctipackage.New(baseDir, pkgOpts...)
 	if err != nil {
 		return fmt.Errorf("new package: %w", err)
 	}
@@ -39,6 +60,13 @@ func execute(_ context.Context, baseDir string) error {
 		return nil
 	}
 
+	if opts.Template != "" {
+		if err := pkg.Scaffold(ctipackage.Template(opts.Template)); err != nil {
+			return fmt.Errorf("scaffold the package: %w", err)
+		}
+		slog.Info("Package was scaffolded", slog.String("template", opts.Template))
+	}
+
 	if err := pkg.Initialize(); err != nil {
 		return fmt.Errorf("initialize the package: %w", err)
 	}
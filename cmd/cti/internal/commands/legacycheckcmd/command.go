@@ -0,0 +1,73 @@
+package legacycheckcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+
+	"github.com/spf13/cobra"
+)
+
+func New(ctx context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "legacy-check [file]",
+		Short: "check identifiers accepted by legacy regexp-based validation against the parser",
+		Long: "Reads CTI identifiers (one per line) from the file or standard input and reports " +
+			"identifiers that are rejected by the parser and whether the legacy compatibility mode accepts them.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return command.WrapError(execute(ctx, cmd.InOrStdin(), cmd.OutOrStdout()))
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return command.WrapError(fmt.Errorf("open file: %w", err))
+			}
+			defer f.Close()
+
+			return command.WrapError(execute(ctx, f, cmd.OutOrStdout()))
+		},
+	}
+}
+
+func execute(_ context.Context, r io.Reader, w io.Writer) error {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read identifiers: %w", err)
+	}
+
+	results := cti.CheckLegacyIdentifiers(ids)
+	for _, res := range results {
+		line := fmt.Sprintf("%s: %v", res.Identifier, res.Err)
+		switch {
+		case errors.Is(res.Err, cti.ErrNotLegacyIdentifier):
+		case res.AcceptedInLegacyCompat:
+			line += " (accepted in legacy compatibility mode)"
+		default:
+			line += " (rejected in legacy compatibility mode)"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+	}
+
+	slog.Info("Checked identifiers", slog.Int("total", len(ids)), slog.Int("rejected", len(results)))
+	if len(results) != 0 {
+		return fmt.Errorf("%d of %d identifiers are rejected by the parser", len(results), len(ids))
+	}
+	return nil
+}
@@ -2,18 +2,83 @@ package lintcmd
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/linter"
+	"github.com/acronis/go-cti/metadata/validator"
 
 	"github.com/spf13/cobra"
 )
 
-func New(_ context.Context) *cobra.Command {
-	return &cobra.Command{
+type LintOptions struct {
+	Fix bool
+}
+
+func New(ctx context.Context) *cobra.Command {
+	lintOpts := LintOptions{}
+	cmd := &cobra.Command{
 		Use:   "lint",
 		Short: "lint cti package",
 		Args:  cobra.MinimumNArgs(0),
-		RunE: func(_ *cobra.Command, args []string) error {
-			return errors.New("not implemented")
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, baseDir, lintOpts))
 		},
 	}
+
+	cmd.Flags().BoolVar(&lintOpts.Fix, "fix", false, "Apply suggested fixes to the package sources.")
+
+	return cmd
+}
+
+func execute(_ context.Context, baseDir string, opts LintOptions) error {
+	slog.Info("Linting package", slog.String("path", baseDir))
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	issues, err := pkg.Lint()
+	if err != nil {
+		return fmt.Errorf("lint package: %w", err)
+	}
+
+	remaining := issues
+	if opts.Fix {
+		fixes := linter.Fixes(issues)
+		if err := validator.ApplyFixes(pkg.BaseDir, fixes); err != nil {
+			return fmt.Errorf("apply fixes: %w", err)
+		}
+		for _, fix := range fixes {
+			slog.Info("Applied fix",
+				slog.String("file", fix.File),
+				slog.Int("line", fix.Range.Start.Line+1),
+				slog.String("replacement", fix.Replacement))
+		}
+		remaining = nil
+		for _, issue := range issues {
+			if len(issue.Fixes) == 0 {
+				remaining = append(remaining, issue)
+			}
+		}
+	}
+
+	for _, issue := range remaining {
+		slog.Warn(issue.Message, slog.String("cti", issue.Cti), slog.String("rule", issue.Rule))
+	}
+	if len(remaining) != 0 {
+		return fmt.Errorf("found %d lint issues", len(remaining))
+	}
+	slog.Info("No lint issues found")
+	return nil
 }
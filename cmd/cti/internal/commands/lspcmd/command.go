@@ -0,0 +1,33 @@
+package lspcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/lsp"
+
+	"github.com/spf13/cobra"
+)
+
+func New(_ context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "run language server for the package over standard input and output",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(cmd, baseDir))
+		},
+	}
+}
+
+func execute(cmd *cobra.Command, baseDir string) error {
+	if err := lsp.NewServer(baseDir).Serve(cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+		return fmt.Errorf("serve language server: %w", err)
+	}
+	return nil
+}
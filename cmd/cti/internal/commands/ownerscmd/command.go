@@ -0,0 +1,103 @@
+package ownerscmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+
+	"github.com/spf13/cobra"
+)
+
+const unowned = "(unowned)"
+
+type OwnersOptions struct {
+	Codeowners string
+	Prefix     string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	ownersOpts := OwnersOptions{}
+	cmd := &cobra.Command{
+		Use:   "owners [owner]",
+		Short: "print owners of cti entities or export them to CODEOWNERS file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			var owner string
+			if len(args) > 0 {
+				owner = args[0]
+			}
+
+			return command.WrapError(execute(ctx, cmd.OutOrStdout(), baseDir, owner, ownersOpts))
+		},
+	}
+
+	cmd.Flags().StringVar(&ownersOpts.Codeowners, "codeowners", "", "Write CODEOWNERS file to the specified path.")
+	cmd.Flags().StringVar(&ownersOpts.Prefix, "prefix", "", "Prefix of paths in CODEOWNERS file, e.g. path to the package in the repository.")
+
+	return cmd
+}
+
+func execute(_ context.Context, w io.Writer, baseDir string, owner string, opts OwnersOptions) error {
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	if opts.Codeowners != "" {
+		return writeCodeowners(pkg, opts)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if owner != "" {
+		for _, entity := range pkg.LocalRegistry.FindByOwner(owner) {
+			fmt.Fprintf(tw, "%s\t%s\n", entity.Cti, entity.SourceMap.OriginalPath)
+		}
+		return tw.Flush()
+	}
+	fmt.Fprintln(tw, "OWNER\tTYPES\tINSTANCES")
+	for _, s := range pkg.LocalRegistry.GetOwnerStats() {
+		name := s.Owner
+		if name == "" {
+			name = unowned
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", name, s.Types, s.Instances)
+	}
+	return tw.Flush()
+}
+
+func writeCodeowners(pkg *ctipackage.Package, opts OwnersOptions) error {
+	if err := os.MkdirAll(filepath.Dir(opts.Codeowners), 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	f, err := os.Create(opts.Codeowners)
+	if err != nil {
+		return fmt.Errorf("create codeowners file: %w", err)
+	}
+	defer f.Close()
+
+	if err := pkg.WriteCodeowners(f, opts.Prefix); err != nil {
+		return err
+	}
+	slog.Info("CODEOWNERS file was written", slog.String("path", opts.Codeowners))
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/acronis/go-cti/cmd/cti/internal/command"
 	"github.com/acronis/go-cti/metadata/archiver/tgzwriter"
@@ -15,11 +17,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// defaultFileName is the name of the output file without extension. The extension depends on the format.
+const defaultFileName = "package"
+
 type PackOptions struct {
 	FileName      string
 	Prefix        string
 	IncludeSource bool
 	Format        PackFormat
+	Revision      string
 }
 
 func New(ctx context.Context) *cobra.Command {
@@ -33,23 +39,32 @@ func New(ctx context.Context) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("get working directory: %w", err)
 			}
+			if !cmd.Flags().Changed("output") && packOpts.Format == PackFormatCtib {
+				packOpts.FileName = defaultFileName + ctipackage.PackedExtension
+			}
 
-			return command.WrapError(execute(ctx, baseDir, packOpts))
+			return command.WrapError(execute(ctx, baseDir, command.NewProgressReporter(cmd), packOpts))
 		},
 	}
 
-	cmd.Flags().StringVarP(&packOpts.FileName, "output", "o", "package."+packer.ArchiveExtension, "Output file name with path.")
+	cmd.Flags().StringVarP(&packOpts.FileName, "output", "o", defaultFileName+packer.ArchiveExtension,
+		"Output file name with path. Defaults to "+defaultFileName+ctipackage.PackedExtension+" for ctib format.")
 	cmd.Flags().StringVarP(&packOpts.Prefix, "prefix", "p", "", "Output prefix.")
 	cmd.Flags().BoolVarP(&packOpts.IncludeSource, "include-source", "s", false, "Include source files in the resulting package.")
 	cmd.Flags().Var(&packOpts.Format, "format", `Archive format. allowed: `+strings.Join(ListPackFormats, ","))
+	cmd.Flags().StringVar(&packOpts.Revision, "revision", "", "VCS revision of the package sources recorded into the provenance.")
 
 	return cmd
 }
 
-func execute(_ context.Context, baseDir string, opts PackOptions) error {
+func execute(_ context.Context, baseDir string, progress ctipackage.ProgressReporter, opts PackOptions) error {
 	slog.Info("Packing package", slog.String("path", baseDir))
 
-	prkOpts := []packer.Option{}
+	if opts.Format == PackFormatCtib {
+		return packSingleFile(baseDir, progress, opts)
+	}
+
+	prkOpts := []packer.Option{packer.WithToolVersion(command.ToolVersion()), packer.WithRevision(opts.Revision)}
 
 	switch opts.Format {
 	case PackFormatZip:
@@ -68,7 +83,7 @@ func execute(_ context.Context, baseDir string, opts PackOptions) error {
 		return fmt.Errorf("new packer: %w", err)
 	}
 
-	pkg, err := ctipackage.New(baseDir)
+	pkg, err := ctipackage.New(baseDir, ctipackage.WithProgress(progress))
 	if err != nil {
 		return fmt.Errorf("new package: %w", err)
 	}
@@ -82,3 +97,36 @@ func execute(_ context.Context, baseDir string, opts PackOptions) error {
 	slog.Info("Packing has been completed", "path", fullPath)
 	return nil
 }
+
+func packSingleFile(baseDir string, progress ctipackage.ProgressReporter, opts PackOptions) error {
+	pkg, err := ctipackage.New(baseDir, ctipackage.WithProgress(progress))
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+	provenance, err := pkg.ComputeProvenance(command.ToolVersion(), opts.Revision, time.Now())
+	if err != nil {
+		return fmt.Errorf("compute provenance: %w", err)
+	}
+
+	fullPath := filepath.Join(opts.Prefix, opts.FileName)
+	f, err := os.Create(fullPath)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", fullPath, err)
+	}
+	defer f.Close()
+	if err := pkg.Pack(f, ctipackage.WithPackProvenance(provenance)); err != nil {
+		return fmt.Errorf("pack the package: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", fullPath, err)
+	}
+
+	slog.Info("Packing has been completed", "path", fullPath)
+	return nil
+}
@@ -0,0 +1,80 @@
+package packcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+)
+
+const testIndex = `{"package_id": "mock.pkg", "ramlx_version": "v0.1.0", "entities": ["entities.raml"]}`
+
+const testEntities = `#%RAML 1.0 Library
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Foo:
+    (cti.cti): cti.mock.pkg.foo.v1.0
+    properties:
+      name: string
+`
+
+func runPack(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "index.json"), []byte(testIndex), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "entities.raml"), []byte(testEntities), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := t.TempDir()
+
+	cmd := New(context.Background())
+	command.AddWorkDirFlag(cmd)
+	cmd.SetArgs(append([]string{"-w", baseDir, "-p", outDir}, args...))
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return outDir, cmd.Execute()
+}
+
+func Test_PackCtib(t *testing.T) {
+	outDir, err := runPack(t, "--format", "ctib", "--revision", "0123abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(outDir, "package"+ctipackage.PackedExtension))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	packed, err := ctipackage.Unpack(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packed.Index.PackageID != "mock.pkg" || len(packed.Registry.Types) != 1 {
+		t.Fatalf("unexpected package id %q", packed.Index.PackageID)
+	}
+	if packed.Provenance == nil || packed.Provenance.Revision != "0123abc" {
+		t.Fatalf("unexpected provenance %+v", packed.Provenance)
+	}
+}
+
+func Test_PackFormat(t *testing.T) {
+	for _, format := range ListPackFormats {
+		var f PackFormat
+		if err := f.Set(format); err != nil {
+			t.Fatalf("set %s: %v", format, err)
+		}
+	}
+
+	_, err := runPack(t, "--format", "foo")
+	if err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -10,9 +10,11 @@ type PackFormat string
 const (
 	PackFormatTgz PackFormat = "tgz"
 	PackFormatZip PackFormat = "zip"
+	// PackFormatCtib is a single-file bundle of the parsed package without sources (see ctipackage.Package.Pack).
+	PackFormatCtib PackFormat = "ctib"
 )
 
-var ListPackFormats = []string{string(PackFormatTgz), string(PackFormatZip)}
+var ListPackFormats = []string{string(PackFormatTgz), string(PackFormatZip), string(PackFormatCtib)}
 
 // String is used both by fmt.Print and by Cobra in help text
 func (e *PackFormat) String() string {
@@ -21,13 +23,13 @@ func (e *PackFormat) String() string {
 
 // Set must have pointer receiver so it doesn't change the value of a copy
 func (e *PackFormat) Set(v string) error {
-	switch v {
-	case "foo", "bar", "moo":
-		*e = PackFormat(v)
-		return nil
-	default:
-		return errors.New(`must be one of ` + strings.Join(ListPackFormats, ","))
+	for _, format := range ListPackFormats {
+		if v == format {
+			*e = PackFormat(v)
+			return nil
+		}
 	}
+	return errors.New(`must be one of ` + strings.Join(ListPackFormats, ","))
 }
 
 // Type is only used in help text
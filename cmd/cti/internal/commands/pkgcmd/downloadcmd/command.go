@@ -41,12 +41,12 @@ func New(ctx context.Context) *cobra.Command {
 	}
 }
 
-func downloadPackages(_ context.Context, pm pacman.PackageManager, packages map[string]string) error {
+func downloadPackages(ctx context.Context, pm pacman.PackageManager, packages map[string]string) error {
 	slog.Info("Download",
 		slog.Any("packages", packages),
 	)
 
-	if _, err := pm.Download(packages); err != nil {
+	if _, err := pm.DownloadContext(ctx, packages); err != nil {
 		return fmt.Errorf("download packages: %w", err)
 	}
 
@@ -55,7 +55,7 @@ func downloadPackages(_ context.Context, pm pacman.PackageManager, packages map[
 	return nil
 }
 
-func downloadPackageDependencies(_ context.Context, pm pacman.PackageManager, baseDir string) error {
+func downloadPackageDependencies(ctx context.Context, pm pacman.PackageManager, baseDir string) error {
 	slog.Info("Download package dependencies",
 		slog.String("path", baseDir),
 	)
@@ -68,7 +68,7 @@ func downloadPackageDependencies(_ context.Context, pm pacman.PackageManager, ba
 		return fmt.Errorf("read package: %w", err)
 	}
 
-	if _, err := pm.Download(pkg.Index.Depends); err != nil {
+	if _, err := pm.DownloadContext(ctx, pkg.Index.Depends); err != nil {
 		return fmt.Errorf("download packages: %w", err)
 	}
 
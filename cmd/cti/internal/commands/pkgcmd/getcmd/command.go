@@ -42,7 +42,7 @@ func New(ctx context.Context) *cobra.Command {
 	}
 }
 
-func addPackages(_ context.Context, baseDir string, pm pacman.PackageManager, packages map[string]string) error {
+func addPackages(ctx context.Context, baseDir string, pm pacman.PackageManager, packages map[string]string) error {
 	slog.Info("Add package dependencies",
 		slog.String("path", baseDir),
 		slog.Any("packages", packages),
@@ -56,14 +56,14 @@ func addPackages(_ context.Context, baseDir string, pm pacman.PackageManager, pa
 		return fmt.Errorf("read package: %w", err)
 	}
 
-	if err := pm.Add(pkg, packages); err != nil {
+	if err := pm.AddContext(ctx, pkg, packages); err != nil {
 		return fmt.Errorf("install dependencies: %w", err)
 	}
 
 	return nil
 }
 
-func installAll(_ context.Context, baseDir string, pm pacman.PackageManager) error {
+func installAll(ctx context.Context, baseDir string, pm pacman.PackageManager) error {
 	slog.Info("Install all packages",
 		slog.String("path", baseDir),
 	)
@@ -76,7 +76,7 @@ func installAll(_ context.Context, baseDir string, pm pacman.PackageManager) err
 		return fmt.Errorf("read package: %w", err)
 	}
 
-	if err := pm.Install(pkg); err != nil {
+	if err := pm.InstallContext(ctx, pkg); err != nil {
 		return fmt.Errorf("install dependencies: %w", err)
 	}
 
@@ -0,0 +1,113 @@
+package querycmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	kindType     = "type"
+	kindInstance = "instance"
+)
+
+type QueryOptions struct {
+	Format string
+	Bundle string
+	Tags   []string
+}
+
+type result struct {
+	Cti   string `json:"cti"`
+	Kind  string `json:"kind"`
+	Value any    `json:"value,omitempty"`
+}
+
+func New(ctx context.Context) *cobra.Command {
+	queryOpts := QueryOptions{}
+	cmd := &cobra.Command{
+		Use:   "query <cti expression>",
+		Short: "print cti entities matching the expression",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return command.CompleteCti(cmd, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd, baseDir, args[0], queryOpts))
+		},
+	}
+
+	cmd.Flags().StringVarP(&queryOpts.Format, "format", "f", FormatText, "Output format: text or json.")
+	cmd.Flags().StringSliceVarP(&queryOpts.Tags, "tag", "t", nil, "Print only entities with any of the specified tags.")
+	cmd.Flags().StringVar(&queryOpts.Bundle, "bundle", "", "Read the packed package from the file or from the standard input if set to '-'.")
+
+	return cmd
+}
+
+func execute(_ context.Context, cmd *cobra.Command, baseDir string, query string, opts QueryOptions) error {
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	r, err := command.ReadRegistry(cmd, baseDir, opts.Bundle)
+	if err != nil {
+		return err
+	}
+
+	matches, err := r.Query(query)
+	if err != nil {
+		return fmt.Errorf("query registry: %w", err)
+	}
+
+	results := make([]result, 0, len(matches))
+	for _, match := range matches {
+		if len(opts.Tags) != 0 && !r.HasAnyTag(match.Entity.Cti, opts.Tags...) {
+			continue
+		}
+		kind := kindType
+		if _, ok := r.Instances[match.Entity.Cti]; ok {
+			kind = kindInstance
+		}
+		results = append(results, result{Cti: match.Entity.Cti, Kind: kind, Value: match.Value})
+	}
+
+	if opts.Format == FormatJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return fmt.Errorf("encode results: %w", err)
+		}
+		return nil
+	}
+	return writeText(cmd.OutOrStdout(), results)
+}
+
+func writeText(w io.Writer, results []result) error {
+	for _, res := range results {
+		if res.Value == nil {
+			fmt.Fprintln(w, res.Cti)
+			continue
+		}
+		value, err := json.Marshal(res.Value)
+		if err != nil {
+			return fmt.Errorf("encode value of %s: %w", res.Cti, err)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", res.Cti, value)
+	}
+	return nil
+}
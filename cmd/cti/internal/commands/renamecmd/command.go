@@ -0,0 +1,80 @@
+package renamecmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/rewrite"
+
+	"github.com/spf13/cobra"
+)
+
+type RenameOptions struct {
+	DryRun bool
+}
+
+func New(ctx context.Context) *cobra.Command {
+	renameOpts := RenameOptions{}
+	cmd := &cobra.Command{
+		Use:   "rename <old-cti> <new-cti>",
+		Short: "rename cti entity across the package sources",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return command.CompleteCti(cmd, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd.OutOrStdout(), baseDir, args[0], args[1], renameOpts))
+		},
+	}
+
+	cmd.Flags().BoolVar(&renameOpts.DryRun, "dry-run", false, "Print the unified diff of the sources instead of modifying them.")
+
+	return cmd
+}
+
+func execute(_ context.Context, w io.Writer, baseDir string, oldCti, newCti string, opts RenameOptions) error {
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if opts.DryRun {
+		fixes, err := pkg.PlanRename(oldCti, newCti)
+		if err != nil {
+			return fmt.Errorf("plan rename: %w", err)
+		}
+		diff, err := rewrite.Diff(pkg.BaseDir, fixes)
+		if err != nil {
+			return fmt.Errorf("make diff: %w", err)
+		}
+		_, err = io.WriteString(w, diff)
+		return err
+	}
+
+	fixes, err := pkg.Rename(oldCti, newCti)
+	if err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	for _, fix := range fixes {
+		slog.Info("Applied edit",
+			slog.String("file", fix.File),
+			slog.Int("line", fix.Range.Start.Line+1),
+			slog.String("replacement", fix.Replacement))
+	}
+	slog.Info("Renamed entity", slog.String("from", oldCti), slog.String("to", newCti), slog.Int("edits", len(fixes)))
+	return nil
+}
@@ -1,19 +0,0 @@
-package restcmd
-
-import (
-	"context"
-	"errors"
-
-	"github.com/spf13/cobra"
-)
-
-func New(_ context.Context) *cobra.Command {
-	return &cobra.Command{
-		Use:   "rest",
-		Short: "run http server to expose restful api",
-		Args:  cobra.MinimumNArgs(0),
-		RunE: func(_ *cobra.Command, args []string) error {
-			return errors.New("not implemented")
-		},
-	}
-}
@@ -0,0 +1,78 @@
+package servecmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/server"
+
+	"github.com/spf13/cobra"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+type ServeOptions struct {
+	Addr   string
+	Bundle string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	serveOpts := ServeOptions{}
+	cmd := &cobra.Command{
+		Use:     "serve",
+		Aliases: []string{"rest"},
+		Short:   "run http server to expose read-only restful api of the registry",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd, baseDir, serveOpts))
+		},
+	}
+
+	cmd.Flags().StringVar(&serveOpts.Addr, "addr", "localhost:8080", "Address to listen on.")
+	cmd.Flags().StringVar(&serveOpts.Bundle, "bundle", "", "Serve the packed package from the file or from the standard input if set to '-'.")
+
+	return cmd
+}
+
+func execute(ctx context.Context, cmd *cobra.Command, baseDir string, opts ServeOptions) error {
+	r, err := command.ReadRegistry(cmd, baseDir, opts.Bundle)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           server.NewHandler(r),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("Serving registry", slog.String("addr", opts.Addr), slog.Int("entities", len(r.Index)))
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
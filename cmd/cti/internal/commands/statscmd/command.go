@@ -0,0 +1,104 @@
+package statscmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type StatsOptions struct {
+	Format string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	statsOpts := StatsOptions{}
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "print usage statistics of cti types and instances",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			return command.WrapError(execute(ctx, cmd.OutOrStdout(), baseDir, statsOpts))
+		},
+	}
+
+	cmd.Flags().StringVarP(&statsOpts.Format, "format", "f", FormatText, "Output format: text or json.")
+
+	return cmd
+}
+
+func execute(_ context.Context, w io.Writer, baseDir string, opts StatsOptions) error {
+	if opts.Format != FormatText && opts.Format != FormatJSON {
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	pkg, err := ctipackage.New(baseDir)
+	if err != nil {
+		return fmt.Errorf("new package: %w", err)
+	}
+
+	if err := pkg.Read(); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	if err := pkg.Parse(); err != nil {
+		return fmt.Errorf("parse package: %w", err)
+	}
+
+	stats, err := pkg.GlobalRegistry.Stats()
+	if err != nil {
+		return fmt.Errorf("collect stats: %w", err)
+	}
+
+	if opts.Format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			return fmt.Errorf("encode stats: %w", err)
+		}
+		return nil
+	}
+	return writeText(w, stats)
+}
+
+func writeText(w io.Writer, stats *collector.RegistryStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Types:\t%d\nInstances:\t%d\n", stats.Types, stats.Instances)
+
+	fmt.Fprintln(tw, "\nVENDOR\tPACKAGE\tTYPES\tINSTANCES")
+	for _, s := range stats.Packages {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", s.Vendor, s.Package, s.Types, s.Instances)
+	}
+
+	fmt.Fprintln(tw, "\nDEPTH\tTYPES")
+	for _, s := range stats.Depths {
+		fmt.Fprintf(tw, "%d\t%d\n", s.Depth, s.Types)
+	}
+
+	fmt.Fprintln(tw, "\nMOST REFERENCED\tREFERENCES")
+	for _, s := range stats.MostReferenced {
+		fmt.Fprintf(tw, "%s\t%d\n", s.Cti, s.References)
+	}
+
+	fmt.Fprintln(tw, "\nUNUSED")
+	for _, id := range stats.Unused {
+		fmt.Fprintln(tw, id)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,94 @@
+package treecmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/acronis/go-cti/cmd/cti/internal/command"
+	"github.com/acronis/go-cti/metadata/tree"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	FormatText = "text"
+	FormatDOT  = "dot"
+)
+
+type TreeOptions struct {
+	Tags   []string
+	Format string
+	Bundle string
+}
+
+func New(ctx context.Context) *cobra.Command {
+	treeOpts := TreeOptions{}
+	cmd := &cobra.Command{
+		Use:   "tree [cti expression or package id]",
+		Short: "print inheritance tree of cti types",
+		Args:  cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return command.CompleteCti(cmd, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseDir, err := command.GetWorkingDir(cmd)
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			var target string
+			if len(args) > 0 {
+				target = args[0]
+			}
+
+			return command.WrapError(execute(ctx, cmd, baseDir, target, treeOpts))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&treeOpts.Tags, "tag", "t", nil, "Show only types with any of the specified tags.")
+	cmd.Flags().StringVarP(&treeOpts.Format, "format", "f", FormatText, "Output format: text or dot (Graphviz).")
+	cmd.Flags().StringVar(&treeOpts.Bundle, "bundle", "", "Read the packed package from the file or from the standard input if set to '-'.")
+
+	return cmd
+}
+
+func execute(_ context.Context, cmd *cobra.Command, baseDir string, target string, opts TreeOptions) error {
+	render := tree.Render
+	switch opts.Format {
+	case FormatText:
+	case FormatDOT:
+		render = tree.RenderDOT
+	default:
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+
+	buildOpts := []tree.Option{tree.WithTags(opts.Tags...)}
+	if strings.HasPrefix(target, "cti.") {
+		buildOpts = append(buildOpts, tree.WithFilter(target))
+	} else if target != "" {
+		buildOpts = append(buildOpts, tree.WithPackage(target))
+	}
+
+	if opts.Bundle == "" {
+		slog.Info("Building inheritance tree", slog.String("path", baseDir))
+	}
+	r, err := command.ReadRegistry(cmd, baseDir, opts.Bundle)
+	if err != nil {
+		return err
+	}
+
+	roots, err := tree.Build(r, buildOpts...)
+	if err != nil {
+		return fmt.Errorf("build tree: %w", err)
+	}
+
+	if err := render(cmd.OutOrStdout(), roots); err != nil {
+		return fmt.Errorf("render tree: %w", err)
+	}
+	return nil
+}
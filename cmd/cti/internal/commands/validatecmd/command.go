@@ -7,12 +7,24 @@ import (
 
 	"github.com/acronis/go-cti/cmd/cti/internal/command"
 	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/validator"
 
 	"github.com/spf13/cobra"
 )
 
+type ValidateOptions struct {
+	Fix               bool
+	Bundle            string
+	StrictInheritance bool
+	Dereference       bool
+	Descriptions      bool
+	MaxIssues         int
+	Baseline          string
+}
+
 func New(ctx context.Context) *cobra.Command {
-	return &cobra.Command{
+	validateOpts := ValidateOptions{}
+	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "validate cti",
 		Args:  cobra.MinimumNArgs(0),
@@ -22,15 +34,33 @@ func New(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("get working directory: %w", err)
 			}
 
-			return command.WrapError(execute(ctx, baseDir))
+			if validateOpts.Bundle != "" {
+				return command.WrapError(executeBundle(cmd, validateOpts))
+			}
+			return command.WrapError(execute(ctx, baseDir, command.NewProgressReporter(cmd), validateOpts))
 		},
 	}
+
+	cmd.Flags().BoolVar(&validateOpts.Fix, "fix", false, "Apply suggested fixes to the package sources before validation.")
+	cmd.Flags().StringVar(&validateOpts.Bundle, "bundle", "", "Validate the packed package from the file or from the standard input if set to '-'.")
+	cmd.Flags().BoolVar(&validateOpts.StrictInheritance, "strict-inheritance", false,
+		"Reject types that widen constraints inherited from their parents.")
+	cmd.Flags().BoolVar(&validateOpts.Dereference, "dereference", false,
+		"Check that entities referenced by instances exist and are accessible.")
+	cmd.Flags().BoolVar(&validateOpts.Descriptions, "require-descriptions", false,
+		"Reject public types with properties that do not have descriptions.")
+	cmd.Flags().IntVar(&validateOpts.MaxIssues, "max-issues", 0, "Stop validation after the number of errors. Zero means no limit.")
+	cmd.Flags().StringVar(&validateOpts.Baseline, "baseline", "",
+		"Directory or bundle of the published version of the package. Released types must not change in a breaking way.")
+	cmd.MarkFlagsMutuallyExclusive("fix", "bundle")
+
+	return cmd
 }
 
-func execute(ctx context.Context, baseDir string) error {
+func execute(ctx context.Context, baseDir string, progress ctipackage.ProgressReporter, opts ValidateOptions) error {
 	slog.Info("Validating package", slog.String("path", baseDir))
 
-	pkg, err := ctipackage.New(baseDir)
+	pkg, err := ctipackage.New(baseDir, ctipackage.WithProgress(progress))
 	if err != nil {
 		return fmt.Errorf("new package: %w", err)
 	}
@@ -39,10 +69,69 @@ func execute(ctx context.Context, baseDir string) error {
 		return fmt.Errorf("read package: %w", err)
 	}
 
+	if opts.Fix {
+		fixes, err := pkg.FixContext(ctx)
+		if err != nil {
+			return fmt.Errorf("fix package: %w", err)
+		}
+		for _, fix := range fixes {
+			slog.Info("Applied fix",
+				slog.String("file", fix.File),
+				slog.Int("line", fix.Range.Start.Line+1),
+				slog.String("replacement", fix.Replacement))
+		}
+	}
+
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
 	// TODO: Validation for usage of indirect dependencies
-	if err := pkg.Validate(); err != nil {
+	if err := pkg.ValidateContext(ctx, validatorOpts...); err != nil {
 		return fmt.Errorf("validate package: %w", err)
 	}
 	slog.Info("No errors found")
 	return nil
 }
+
+func executeBundle(cmd *cobra.Command, opts ValidateOptions) error {
+	b, err := command.ReadBundle(cmd, opts.Bundle)
+	if err != nil {
+		return err
+	}
+	slog.Info("Validating bundle", slog.String("id", b.Index.PackageID))
+
+	validatorOpts, err := validatorOptions(opts)
+	if err != nil {
+		return err
+	}
+	if err := b.Validate(validatorOpts...); err != nil {
+		return fmt.Errorf("validate bundle: %w", err)
+	}
+	slog.Info("No errors found")
+	return nil
+}
+
+func validatorOptions(opts ValidateOptions) ([]validator.Option, error) {
+	var res []validator.Option
+	if opts.StrictInheritance {
+		res = append(res, validator.WithStrictInheritance())
+	}
+	if opts.Dereference {
+		res = append(res, validator.WithDereferencedReferences())
+	}
+	if opts.Descriptions {
+		res = append(res, validator.WithRequiredPropertyDescriptions())
+	}
+	if opts.MaxIssues != 0 {
+		res = append(res, validator.WithMaxIssues(opts.MaxIssues))
+	}
+	if opts.Baseline != "" {
+		baseline, err := ctipackage.LoadBaseline(opts.Baseline)
+		if err != nil {
+			return nil, fmt.Errorf("load baseline: %w", err)
+		}
+		res = append(res, validator.WithReleasedBaseline(baseline))
+	}
+	return res, nil
+}
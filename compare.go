@@ -0,0 +1,86 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Compare compares two expressions and returns -1, 0 or +1. Nodes of the inheritance chains are compared
+// one by one by vendor, package, entity name and version, so a parent precedes its children
+// and chains of the same depth are ordered by their first differing node.
+// Expressions with equal chains are ordered by their string representations (e.g. by anonymous entity UUIDs),
+// so Compare returns 0 only for expressions with the same string representation.
+func Compare(a, b Expression) int {
+	n1, n2 := a.Head, b.Head
+	for ; n1 != nil && n2 != nil; n1, n2 = n1.Child, n2.Child {
+		if c := compareNodes(n1, n2); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case n1 != nil:
+		return 1
+	case n2 != nil:
+		return -1
+	}
+	return strings.Compare(a.String(), b.String())
+}
+
+// compareNodes compares a single node of inheritance chains without its children.
+func compareNodes(a, b *Node) int {
+	if c := strings.Compare(string(a.Vendor), string(b.Vendor)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(string(a.Package), string(b.Package)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(string(a.EntityName), string(b.EntityName)); c != 0 {
+		return c
+	}
+	if c := a.Version.Compare(b.Version); c != 0 {
+		return c
+	}
+	// Wildcards and dynamic parameters are not taken into account above.
+	return strings.Compare(a.String(), b.String())
+}
+
+// Sort sorts the expressions in the order defined by Compare.
+func Sort(exprs []Expression) {
+	sort.SliceStable(exprs, func(i, j int) bool {
+		return Compare(exprs[i], exprs[j]) < 0
+	})
+}
+
+// SortStrings sorts CTI expressions in the order defined by Compare, e.g. identifiers of registry entities.
+// Expressions are parsed with the specified options. The slice is left unchanged if any of them cannot be parsed.
+func SortStrings(inputs []string, opts ...ParserOption) error {
+	p := NewParser(opts...)
+	exprs := make([]Expression, len(inputs))
+	for i, input := range inputs {
+		expr, err := p.Parse(input)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", input, err)
+		}
+		exprs[i] = expr
+	}
+	idx := make([]int, len(inputs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return Compare(exprs[idx[i]], exprs[idx[j]]) < 0
+	})
+	sorted := make([]string, len(inputs))
+	for i, k := range idx {
+		sorted[i] = inputs[k]
+	}
+	copy(inputs, sorted)
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true))
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"cti.a.p.event.v1.0", "cti.a.p.event.v1.0", 0},
+		{"cti.a.p.event.v1.0", "cti.b.p.event.v1.0", -1},
+		{"cti.a.q.event.v1.0", "cti.a.p.event.v1.0", 1},
+		{"cti.a.p.alert.v1.0", "cti.a.p.event.v1.0", -1},
+		{"cti.a.p.event.v1.2", "cti.a.p.event.v1.10", -1},
+		{"cti.a.p.event.v2.0", "cti.a.p.event.v1.10", 1},
+		{"cti.a.p.event.v1.0", "cti.a.p.event.v1.0~a.p.created.v1.0", -1},
+		{"cti.a.p.event.v1.0~b.p.created.v1.0", "cti.a.p.event.v2.0", -1},
+		{"cti.a.p.event.v1.0~a.p.created.v1.0", "cti.a.p.event.v1.0~a.p.deleted.v1.0", -1},
+		{"cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6", "cti.a.p.event.v1.0~0a3c448e-55e3-4f7f-ae54-4e87eb8635f6", 1},
+		{"cti.a.p.event.v1.*", "cti.a.p.event.v1.0", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			require.Equal(t, tt.want, Compare(p.MustParse(tt.a), p.MustParse(tt.b)))
+			require.Equal(t, -tt.want, Compare(p.MustParse(tt.b), p.MustParse(tt.a)))
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	exprs := []Expression{
+		MustParse("cti.b.p.event.v1.0"),
+		MustParse("cti.a.p.event.v1.10"),
+		MustParse("cti.a.p.event.v1.2~a.p.created.v1.0"),
+		MustParse("cti.a.p.event.v1.2"),
+	}
+	Sort(exprs)
+	var got []string
+	for _, e := range exprs {
+		got = append(got, e.String())
+	}
+	require.Equal(t, []string{
+		"cti.a.p.event.v1.2",
+		"cti.a.p.event.v1.2~a.p.created.v1.0",
+		"cti.a.p.event.v1.10",
+		"cti.b.p.event.v1.0",
+	}, got)
+}
+
+func TestSortStrings(t *testing.T) {
+	ids := []string{"cti.a.p.event.v1.10", "cti.a.p.event.v1.2~a.p.created.v1.0", "cti.a.p.event.v1.2"}
+	require.NoError(t, SortStrings(ids))
+	require.Equal(t, []string{"cti.a.p.event.v1.2", "cti.a.p.event.v1.2~a.p.created.v1.0", "cti.a.p.event.v1.10"}, ids)
+
+	invalid := []string{"cti.a.p.event.v1.10", "invalid"}
+	require.Error(t, SortStrings(invalid))
+	require.Equal(t, []string{"cti.a.p.event.v1.10", "invalid"}, invalid)
+}
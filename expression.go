@@ -73,6 +73,31 @@ func (v Version) HasWildcard() bool {
 	return v.HasMajorWildcard || v.HasMinorWildcard
 }
 
+// Compare compares the version with another version and returns -1, 0 or +1.
+// Major versions are compared first, then minor versions. An absent part precedes any present one.
+// Wildcards are not taken into account.
+func (v Version) Compare(other Version) int {
+	if c := compareNullVersions(v.Major, other.Major); c != 0 {
+		return c
+	}
+	return compareNullVersions(v.Minor, other.Minor)
+}
+
+func compareNullVersions(a, b NullVersion) int {
+	switch {
+	case a.Valid != b.Valid:
+		if a.Valid {
+			return 1
+		}
+		return -1
+	case a.Value < b.Value:
+		return -1
+	case a.Value > b.Value:
+		return 1
+	}
+	return 0
+}
+
 // String returns string representation of the Version.
 func (v Version) String() string {
 	var b strings.Builder
@@ -432,6 +457,9 @@ func (e *Expression) match(secondExpression Expression, ignoreQuery bool) (bool,
 
 	switch {
 	case curNode1 == nil && curNode2 == nil:
+		if !e.AnonymousEntityUUID.Valid && secondExpression.AnonymousEntityUUID.Valid {
+			return e.matchAnonymous(secondExpression, ignoreQuery)
+		}
 		if e.AnonymousEntityUUID != secondExpression.AnonymousEntityUUID {
 			return false, nil
 		}
@@ -453,6 +481,83 @@ func (e *Expression) match(secondExpression Expression, ignoreQuery bool) (bool,
 	return true, nil
 }
 
+// SatisfiedBy reports whether the candidate identifier is a compatible version of the entity referenced by the Expression.
+// The candidate must identify the same entity with the same major versions. Minor versions of the candidate
+// must be equal to or newer than minor versions of the Expression, so cti.a.p.event.v1.2 is satisfied by
+// cti.a.p.event.v1.2 and cti.a.p.event.v1.3, but not by cti.a.p.event.v1.1 or cti.a.p.event.v2.0.
+// Wildcards and absent versions of the Expression are satisfied by any version, wildcards in names
+// are matched the same way as by Match. Query attributes of the Expression are ignored.
+func (e *Expression) SatisfiedBy(candidate Expression) (bool, error) {
+	if e.AttributeSelector != "" || candidate.AttributeSelector != "" {
+		return false, fmt.Errorf("version resolution of CTI with attribute selector is not supported")
+	}
+	if candidate.HasWildcard() {
+		return false, fmt.Errorf("version resolution against CTI with wildcard is not supported")
+	}
+
+	curNode1 := e.Head
+	curNode2 := candidate.Head
+	for ; curNode1 != nil && curNode2 != nil; curNode1, curNode2 = curNode1.Child, curNode2.Child {
+		if curNode1.Vendor.IsWildCard() {
+			return true, nil
+		}
+		if curNode1.Vendor != curNode2.Vendor {
+			return false, nil
+		}
+		if curNode1.Package.IsWildCard() {
+			return true, nil
+		}
+		if curNode1.Package != curNode2.Package {
+			return false, nil
+		}
+		if curNode1.EntityName.EndsWithWildcard() {
+			entityName1Prefix := string(curNode1.EntityName)
+			entityName1Prefix = entityName1Prefix[:len(entityName1Prefix)-1]
+			return strings.HasPrefix(string(curNode2.EntityName)+".", entityName1Prefix), nil
+		}
+		if curNode1.EntityName != curNode2.EntityName {
+			return false, nil
+		}
+
+		v1, v2 := curNode1.Version, curNode2.Version
+		if v1.HasMajorWildcard || !v1.Major.Valid {
+			continue
+		}
+		if v1.Major != v2.Major {
+			return false, nil
+		}
+		if v1.HasMinorWildcard || !v1.Minor.Valid {
+			continue
+		}
+		if compareNullVersions(v1.Minor, v2.Minor) > 0 {
+			return false, nil
+		}
+	}
+	if curNode1 != nil || curNode2 != nil {
+		return false, nil
+	}
+	return e.AnonymousEntityUUID == candidate.AnonymousEntityUUID, nil
+}
+
+// CompareVersions compares versions of the Expression with versions of another expression node by node
+// and returns -1, 0 or +1. It is used to select the newest of the expressions that identify the same entity.
+func (e *Expression) CompareVersions(other Expression) int {
+	curNode1 := e.Head
+	curNode2 := other.Head
+	for ; curNode1 != nil && curNode2 != nil; curNode1, curNode2 = curNode1.Child, curNode2.Child {
+		if c := curNode1.Version.Compare(curNode2.Version); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case curNode1 != nil:
+		return 1
+	case curNode2 != nil:
+		return -1
+	}
+	return 0
+}
+
 // DynamicParameterValues is a container (map) of dynamic parameter values that can be interpolated into the Expression.
 type DynamicParameterValues map[string]string
 
@@ -527,6 +527,91 @@ func TestVersion_String(t *testing.T) {
 	}
 }
 
+func TestVersion_Compare(t *testing.T) {
+	testCases := []struct {
+		name     string
+		v1, v2   Version
+		expected int
+	}{
+		{name: "equal", v1: NewVersion(1, 2), v2: NewVersion(1, 2), expected: 0},
+		{name: "older major", v1: NewVersion(1, 9), v2: NewVersion(2, 0), expected: -1},
+		{name: "newer minor", v1: NewVersion(1, 3), v2: NewVersion(1, 2), expected: 1},
+		{name: "absent minor", v1: NewPartialVersion(1), v2: NewVersion(1, 0), expected: -1},
+		{name: "absent version", v1: Version{}, v2: NewPartialVersion(0), expected: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.v1.Compare(tc.v2))
+		})
+	}
+}
+
+func TestExpression_SatisfiedBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		reference  string
+		candidate  string
+		wantErrMsg string
+		want       bool
+	}{
+		{name: "same version", reference: "cti.a.p.event.v1.2", candidate: "cti.a.p.event.v1.2", want: true},
+		{name: "newer minor version", reference: "cti.a.p.event.v1.2", candidate: "cti.a.p.event.v1.3", want: true},
+		{name: "older minor version", reference: "cti.a.p.event.v1.2", candidate: "cti.a.p.event.v1.1", want: false},
+		{name: "other major version", reference: "cti.a.p.event.v1.2", candidate: "cti.a.p.event.v2.2", want: false},
+		{name: "partial version", reference: "cti.a.p.event.v1", candidate: "cti.a.p.event.v1.7", want: true},
+		{name: "minor wildcard", reference: "cti.a.p.event.v1.*", candidate: "cti.a.p.event.v1.0", want: true},
+		{name: "major wildcard", reference: "cti.a.p.event.v*", candidate: "cti.a.p.event.v3.0", want: true},
+		{
+			name:      "chain",
+			reference: "cti.a.p.event.v1.0~a.p.created.v1.1",
+			candidate: "cti.a.p.event.v1.4~a.p.created.v1.2",
+			want:      true,
+		},
+		{
+			name:      "chain with older child version",
+			reference: "cti.a.p.event.v1.0~a.p.created.v1.1",
+			candidate: "cti.a.p.event.v1.4~a.p.created.v1.0",
+			want:      false,
+		},
+		{name: "descendant", reference: "cti.a.p.event.v1.0", candidate: "cti.a.p.event.v1.0~a.p.created.v1.0", want: false},
+		{name: "other entity", reference: "cti.a.p.event.v1.0", candidate: "cti.a.p.topic.v1.0", want: false},
+		{name: "entity name wildcard", reference: "cti.a.p.*", candidate: "cti.a.p.event.v1.0", want: true},
+		{
+			name:       "wildcard in candidate",
+			reference:  "cti.a.p.event.v1.0",
+			candidate:  "cti.a.p.event.v1.*",
+			wantErrMsg: "version resolution against CTI with wildcard is not supported",
+		},
+	}
+	p := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := p.ParseReference(tt.reference)
+			require.NoError(t, err)
+			candidate, err := p.Parse(tt.candidate)
+			require.NoError(t, err)
+
+			ok, err := ref.SatisfiedBy(candidate)
+			if tt.wantErrMsg != "" {
+				require.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestExpression_CompareVersions(t *testing.T) {
+	p := NewParser()
+	e1 := p.MustParse("cti.a.p.event.v1.4~a.p.created.v1.0")
+	e2 := p.MustParse("cti.a.p.event.v1.3~a.p.created.v1.9")
+	require.Equal(t, 1, e1.CompareVersions(e2))
+	require.Equal(t, -1, e2.CompareVersions(e1))
+	require.Equal(t, 0, e1.CompareVersions(e1))
+}
+
 // ---------------------- Benchmarks ----------------------
 
 func BenchmarkExpression_InterpolateDynamicParameterValues(b *testing.B) {
@@ -0,0 +1,159 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"strings"
+)
+
+// FindAll extracts CTI expressions embedded in a composite string, e.g. in a URL path or query
+// (/types/cti.a.p.event.v1.0%7Ea.p.created.v1.0?parent=cti.a.p.event.v1.0) or in a JSON pointer
+// (/definitions/cti.a.p.event.v1.0~0a.p.created.v1.0). Expressions are parsed with the specified options
+// and returned in the order of occurrence. See Parser.FindAll for details.
+func FindAll(s string, opts ...ParserOption) []Expression {
+	return NewParser(opts...).FindAll(s)
+}
+
+// FindAll extracts CTI expressions embedded in a composite string.
+// Percent-encoded characters are decoded first, so identifiers may be received in URLs as is.
+// An expression starts with the "cti." prefix that does not follow a character of an identifier
+// and is the longest part of the string that is parsed by Parse and is not followed by a character of an identifier.
+// If it cannot be parsed, but it can be parsed after unescaping of JSON pointer sequences (~0 and ~1),
+// the unescaped expression is returned.
+// Parts of the string that look like expressions but cannot be parsed are skipped.
+func (p *Parser) FindAll(s string) []Expression {
+	s = unescapePercent(s)
+
+	var exprs []Expression
+	for i := 0; i < len(s); {
+		start := strings.Index(s[i:], "cti.")
+		if start < 0 {
+			break
+		}
+		start += i
+		if start > 0 && isIdentifierChar(s[start-1]) {
+			i = start + len("cti.")
+			continue
+		}
+		expr, n := p.parseLongestPrefix(s[start:findCandidateEnd(s, start, p.maxLength)])
+		if n == 0 {
+			i = start + len("cti.")
+			continue
+		}
+		exprs = append(exprs, expr)
+		i = start + n
+	}
+	return exprs
+}
+
+// parseLongestPrefix parses the longest prefix of the candidate and returns the expression and the length of the prefix.
+// The length is 0 if no prefix can be parsed.
+func (p *Parser) parseLongestPrefix(candidate string) (Expression, int) {
+	for n := len(candidate); n > len("cti."); n-- {
+		if !isBoundary(candidate, n) {
+			continue
+		}
+		prefix := candidate[:n]
+		if expr, err := p.Parse(prefix); err == nil {
+			return expr, n
+		}
+		if strings.Contains(prefix, "~0") || strings.Contains(prefix, "~1") {
+			if expr, err := p.Parse(unescapeJSONPointer(prefix)); err == nil {
+				return expr, n
+			}
+		}
+	}
+	return emptyExpression, 0
+}
+
+// findCandidateEnd returns the end of the part of the string that may hold the expression starting at the position.
+// The part ends at whitespaces, quotes and delimiters of URLs and JSON pointers that are not within query attributes.
+// It is limited by the maximum length of the parser, if any.
+func findCandidateEnd(s string, start int, maxLength int) int {
+	end := len(s)
+	if maxLength > 0 && start+maxLength < end {
+		end = start + maxLength
+	}
+	inQuery, inQuotes := false, false
+	for i := start; i < end; i++ {
+		c := s[i]
+		switch {
+		case inQuotes:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inQuotes = false
+			}
+		case inQuery:
+			switch c {
+			case '"':
+				inQuotes = true
+			case ']':
+				inQuery = false
+			}
+		case c == '[':
+			inQuery = true
+		case c <= ' ' || strings.IndexByte("\"'`/?&#;<>()|", c) >= 0:
+			return i
+		}
+	}
+	return end
+}
+
+// isBoundary returns true if the expression may end at the position of the string, so a prefix of an identifier
+// (e.g. an identifier of the parent type) is not extracted. A trailing dot is allowed, e.g. at the end of a sentence.
+func isBoundary(s string, pos int) bool {
+	switch {
+	case pos == len(s) || !isIdentifierChar(s[pos]):
+		return true
+	case s[pos] == '.':
+		return pos+1 == len(s) || !isIdentifierChar(s[pos+1])
+	}
+	return false
+}
+
+func isIdentifierChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-' || c == InheritanceSeparator
+}
+
+// unescapePercent decodes valid percent-encoded sequences and keeps invalid ones as is,
+// unlike url.PathUnescape that fails on the whole string.
+func unescapePercent(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func unescapeJSONPointer(s string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "url path and query",
+			input: "https://example.com/api/types/cti.a.p.event.v1.0%7Ea.p.created.v1.0?parent=cti.a.p.event.v1.0&limit=10",
+			want:  []string{"cti.a.p.event.v1.0~a.p.created.v1.0", "cti.a.p.event.v1.0"},
+		},
+		{
+			name:  "json pointer",
+			input: "#/definitions/cti.a.p.event.v1.0~0a.p.created.v1.0/properties/id",
+			want:  []string{"cti.a.p.event.v1.0~a.p.created.v1.0"},
+		},
+		{
+			name:  "query with quoted attribute",
+			input: `filter=cti.a.p.alert.v1.0[severity="very high", category="cti.a.p.category.v1.0"]&sort=asc`,
+			want:  []string{`cti.a.p.alert.v1.0[severity="very high",category="cti.a.p.category.v1.0"]`},
+		},
+		{
+			name:  "json document",
+			input: `{"type":"cti.a.p.event.v1.0","items":["cti.b.q.user.v2.1"]}`,
+			want:  []string{"cti.a.p.event.v1.0", "cti.b.q.user.v2.1"},
+		},
+		{
+			name:  "sentence",
+			input: "Use cti.a.p.event.v1.0. Not xcti.a.p.event.v1.0 or cti.invalid.",
+			want:  []string{"cti.a.p.event.v1.0"},
+		},
+		{
+			name:  "invalid percent encoding",
+			input: "/types/cti.a.p.event.v1.0?q=100%",
+			want:  []string{"cti.a.p.event.v1.0"},
+		},
+		{
+			name:  "none",
+			input: "/types",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range FindAll(tt.input) {
+				got = append(got, e.String())
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParser_FindAll_Anonymous(t *testing.T) {
+	input := "/instances/cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6"
+	require.Empty(t, FindAll(input))
+
+	exprs := NewParser(WithAllowAnonymousEntity(true)).FindAll(input)
+	require.Len(t, exprs, 1)
+	require.True(t, exprs[0].HasAnonymousEntity())
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"math"
+	"strings"
+)
+
+// IsValidIdentifier reports whether the string is a valid CTI identifier.
+// It accepts the same strings as ParseIdentifier of the parser with default options
+// (i.e. without anonymous entities, dynamic parameters and legacy compatibility) except
+// the empty expression "cti.", but neither builds the expression nor allocates memory, so it may be used on hot paths.
+func IsValidIdentifier(s string) bool {
+	if !strings.HasPrefix(s, "cti.") {
+		return false
+	}
+	s = s[4:]
+	for {
+		chunk, tail, found := strings.Cut(s, string(InheritanceSeparator))
+		if !isValidIdentifierNode(chunk) {
+			return false
+		}
+		if !found {
+			return true
+		}
+		s = tail
+	}
+}
+
+// isValidIdentifierNode checks a node of CTI identifier in the form <vendor>.<package>.<entity name>.v<major>.<minor>.
+func isValidIdentifierNode(s string) bool {
+	vendor, s, _ := strings.Cut(s, ".")
+	if !isValidVendorOrPackage(vendor) {
+		return false
+	}
+	pkg, s, _ := strings.Cut(s, ".")
+	if !isValidVendorOrPackage(pkg) {
+		return false
+	}
+
+	i := strings.LastIndexByte(s, '.')
+	if i == -1 {
+		return false
+	}
+	minor, ok := parseVersionNumber(s[i+1:])
+	if !ok {
+		return false
+	}
+	s = s[:i]
+	i = strings.LastIndex(s, ".v")
+	if i == -1 {
+		return false
+	}
+	major, ok := parseVersionNumber(s[i+2:])
+	if !ok || (major == 0 && minor == 0) {
+		return false
+	}
+	return isValidEntityName(s[:i])
+}
+
+func isValidVendorOrPackage(s string) bool {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if (s[i] < 'a' || s[i] > 'z') && !checkByteIsDigit(s[i]) && s[i] != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func isValidEntityName(s string) bool {
+	if s == "" || s[len(s)-1] == '.' || (s[0] != '_' && (s[0] < 'a' || s[0] > 'z')) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '.' || s[i] == '_':
+			if s[i-1] == s[i] {
+				return false
+			}
+		case s[i] >= 'a' && s[i] <= 'z', checkByteIsDigit(s[i]):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersionNumber parses a major or minor part of version that cannot have leading zeros.
+func parseVersionNumber(s string) (int, bool) {
+	if s == "" || (s[0] == '0' && len(s) > 1) {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if !checkByteIsDigit(s[i]) {
+			return 0, false
+		}
+		d := int(s[i] - '0')
+		if n > (math.MaxInt-d)/10 {
+			return 0, false
+		}
+		n = n*10 + d
+	}
+	return n, true
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var isValidIdentifierInputs = []string{
+	"cti.a.p.message.v1.0",
+	"cti.a.p.message.v1.0~a.p.created.v2.10",
+	"cti.a.p.wm.workload.v1.0~a.p.wm.aspect.v1.0~a.p.machine.v1.0",
+	"cti.a.p._internal.v1.0",
+	"cti.a.p.message.v1.0.v2.3",
+	"cti.a_1.p_2.message_3.v0.1",
+	"cti.a.p.message.v1.0~",
+	"cti.a.p.message.v1",
+	"cti.a.p.message.v0.0",
+	"cti.a.p.message.v01.0",
+	"cti.a.p.message.v1.01",
+	"cti.a.p.message.v1.0x",
+	"cti.a.p.message.v99999999999999999999.0",
+	"cti.a.p.message..v1.0",
+	"cti.a.p.mess..age.v1.0",
+	"cti.a.p.mess__age.v1.0",
+	"cti.a.p.1message.v1.0",
+	"cti.a.p..v1.0",
+	"cti.a.p.v1.0",
+	"cti.a.p.Message.v1.0",
+	"cti.a.p.message.vv1.0",
+	"cti.a.p.message_v1.0",
+	"cti._a.p.message.v1.0",
+	"cti.1a.p.message.v1.0",
+	"cti.a.p.message.v1.*",
+	"cti.a.p.message.v1.0[a=b]",
+	"cti.a.p.message.v1.0@a",
+	"cti.a.p.message.v1.0~a.p.${param}",
+	"cti.a.p.message.v1.0~f7a9ba04-2fa4-4e2c-9d0a-5e3c6f6d0c7a",
+	"cti.a.p",
+	"cti.",
+	"a.p.message.v1.0",
+	"",
+}
+
+// isParsedIdentifier reports whether the parser accepts the input as a non-empty identifier.
+func isParsedIdentifier(input string) bool {
+	expr, err := ParseIdentifier(input)
+	return err == nil && expr.Head != nil
+}
+
+func TestIsValidIdentifier(t *testing.T) {
+	for _, input := range isValidIdentifierInputs {
+		require.Equal(t, isParsedIdentifier(input), IsValidIdentifier(input), input)
+	}
+}
+
+func TestIsValidIdentifier_Allocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, input := range benchParseExprIdentifiers {
+			if !IsValidIdentifier(input) {
+				t.Fatalf("%q is not valid", input)
+			}
+		}
+	})
+	require.Zero(t, allocs)
+}
+
+func FuzzIsValidIdentifier(f *testing.F) {
+	for _, input := range isValidIdentifierInputs {
+		f.Add(input)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		if valid := IsValidIdentifier(input); valid != isParsedIdentifier(input) {
+			t.Fatalf("IsValidIdentifier(%q) = %v", input, valid)
+		}
+	})
+}
+
+func BenchmarkIsValidIdentifier(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !IsValidIdentifier(benchParseExprIdentifiers[i%len(benchParseExprIdentifiers)]) {
+			b.Fatal("identifier is not valid")
+		}
+	}
+}
+
+func BenchmarkParser_ParseIdentifier(b *testing.B) {
+	p := NewParser()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.ParseIdentifier(benchParseExprIdentifiers[i%len(benchParseExprIdentifiers)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"errors"
+	"regexp"
+)
+
+// LegacyIdentifierPattern is a regular expression that was used to validate CTI identifiers
+// in RAML specification before the parser was introduced.
+//
+//nolint:lll // regular expression is copied as is
+const LegacyIdentifierPattern = `^cti\.([a-z][a-z0-9_]*\.[a-z][a-z0-9_]*\.[a-z_][a-z0-9_.]*\.v[\d]+\.[\d]+)(~([a-z][a-z0-9_]*\.[a-z][a-z0-9_]*\.[a-z_][a-z0-9_.]*\.v[\d]+\.[\d]+))*(~[0-9a-f]{8}\b-[0-9a-f]{4}\b-[0-9a-f]{4}\b-[0-9a-f]{4}\b-[0-9a-f]{12})?$`
+
+var legacyIdentifierRegexp = regexp.MustCompile(LegacyIdentifierPattern)
+
+// ErrNotLegacyIdentifier is returned when an input is not accepted by LegacyIdentifierPattern.
+var ErrNotLegacyIdentifier = errors.New("not accepted by legacy identifier pattern")
+
+// LegacyCheckResult describes an identifier that was accepted by the legacy regexp-based validation
+// but is rejected by the parser.
+type LegacyCheckResult struct {
+	// Identifier is a checked identifier.
+	Identifier string
+
+	// Err is an error returned by the parser or ErrNotLegacyIdentifier.
+	Err error
+
+	// AcceptedInLegacyCompat is true if the identifier is accepted by the parser in the legacy compatibility mode.
+	AcceptedInLegacyCompat bool
+}
+
+// IsLegacyIdentifier returns true if the input is accepted by LegacyIdentifierPattern.
+func IsLegacyIdentifier(input string) bool {
+	return legacyIdentifierRegexp.MatchString(input)
+}
+
+// CheckLegacyIdentifiers checks a corpus of identifiers that were accepted by the legacy regexp-based validation
+// and returns results only for identifiers that are rejected by the parser, in the order of input.
+// Identifiers are parsed as by ParseIdentifier with the specified options.
+func CheckLegacyIdentifiers(inputs []string, opts ...ParserOption) []LegacyCheckResult {
+	p := NewParser(opts...)
+	legacyParser := NewParser(append(append([]ParserOption{}, opts...), WithLegacyCompat(true))...)
+
+	var results []LegacyCheckResult
+	for _, input := range inputs {
+		if !IsLegacyIdentifier(input) {
+			results = append(results, LegacyCheckResult{Identifier: input, Err: ErrNotLegacyIdentifier})
+			continue
+		}
+		if _, err := p.ParseIdentifier(input); err != nil {
+			_, legacyErr := legacyParser.ParseIdentifier(input)
+			results = append(results, LegacyCheckResult{
+				Identifier:             input,
+				Err:                    err,
+				AcceptedInLegacyCompat: legacyErr == nil,
+			})
+		}
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLegacyIdentifiers(t *testing.T) {
+	tests := []struct {
+		name                 string
+		input                string
+		wantErrMsg           string
+		wantAcceptedInLegacy bool
+	}{
+		{
+			name:  "valid identifier",
+			input: "cti.a.p.gr.namespace.v1.0",
+		},
+		{
+			name:  "valid identifier with inheritance",
+			input: "cti.a.p.wm.workload.v1.0~a.p.wm.aspect.v1.0~a.p.machine.v1.0",
+		},
+		{
+			name:                 "anonymous entity",
+			input:                "cti.a.p.xx.v1.0~6e4f419f-4bf7-494f-a4b5-3e95eb464fc5",
+			wantErrMsg:           `parse vendor: can be "*" or start only with letter`,
+			wantAcceptedInLegacy: true,
+		},
+		{
+			name:                 "double dots in entity name",
+			input:                "cti.a.p.gr..namespace.v1.0",
+			wantErrMsg:           `parse entity name and version: entity name cannot have double dots ("..")`,
+			wantAcceptedInLegacy: true,
+		},
+		{
+			name:                 "double underscores in entity name",
+			input:                "cti.a.p.gr__namespace.v1.0",
+			wantErrMsg:           `parse entity name and version: entity name cannot have double underscores ("__")`,
+			wantAcceptedInLegacy: true,
+		},
+		{
+			name:                 "leading zero in version",
+			input:                "cti.a.p.gr.namespace.v01.0",
+			wantErrMsg:           "parse entity name and version: major part of version cannot contain leading zero",
+			wantAcceptedInLegacy: true,
+		},
+		{
+			name:                 "zero version",
+			input:                "cti.a.p.gr.namespace.v0.0",
+			wantErrMsg:           "parse entity name and version: version must be higher than 0.0",
+			wantAcceptedInLegacy: true,
+		},
+		{
+			name:       "not legacy identifier",
+			input:      "cti.a.p.gr.namespace.v1",
+			wantErrMsg: ErrNotLegacyIdentifier.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := CheckLegacyIdentifiers([]string{tt.input})
+			if tt.wantErrMsg == "" {
+				require.Empty(t, results)
+				return
+			}
+			require.Len(t, results, 1)
+			require.Equal(t, tt.input, results[0].Identifier)
+			require.EqualError(t, results[0].Err, tt.wantErrMsg)
+			require.Equal(t, tt.wantAcceptedInLegacy, results[0].AcceptedInLegacyCompat)
+		})
+	}
+}
+
+func TestParseIdentifierWithLegacyCompat(t *testing.T) {
+	expr, err := ParseIdentifier("cti.a.p.gr__namespace.v01.0~6e4f419f-4bf7-494f-a4b5-3e95eb464fc5", WithLegacyCompat(true))
+	require.NoError(t, err)
+	require.Equal(t, "gr__namespace", string(expr.Head.EntityName))
+	require.Equal(t, NewVersion(1, 0), expr.Head.Version)
+	require.True(t, expr.AnonymousEntityUUID.Valid)
+}
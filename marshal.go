@@ -0,0 +1,198 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Expression, Node and Version are marshaled to their canonical string form (see String methods),
+// so they may be embedded into configuration structures that are stored in JSON or YAML.
+// Zero values are marshaled to an empty string and vice versa.
+
+// MarshalText implements encoding.TextMarshaler.
+func (e Expression) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// The text is parsed by the parser with default options and may be any kind of CTI expression (see Parser.Parse).
+func (e *Expression) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*e = Expression{}
+		return nil
+	}
+	expr, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*e = expr
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e Expression) MarshalJSON() ([]byte, error) {
+	return marshalJSONString(e.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *Expression) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONString(data, e.UnmarshalText)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (e Expression) MarshalYAML() (interface{}, error) {
+	return e.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler in the form that does not depend on a particular version of the YAML package.
+func (e *Expression) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLString(unmarshal, e.UnmarshalText)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (n Node) MarshalText() ([]byte, error) {
+	return []byte(n.text()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// The text is a single node of CTI expression without the "cti." prefix (e.g. a.p.event.v1.0).
+// Wildcards and partial versions are allowed (see Parser.ParseReference).
+func (n *Node) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*n = Node{}
+		return nil
+	}
+	if strings.IndexByte(string(text), InheritanceSeparator) != -1 {
+		return fmt.Errorf("node %q cannot contain %q", text, InheritanceSeparator)
+	}
+	expr, err := ParseReference("cti." + string(text))
+	if err != nil {
+		return err
+	}
+	if expr.Head == nil {
+		return fmt.Errorf("invalid node %q", text)
+	}
+	*n = *expr.Head
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n Node) MarshalJSON() ([]byte, error) {
+	return marshalJSONString(n.text())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONString(data, n.UnmarshalText)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (n Node) MarshalYAML() (interface{}, error) {
+	return n.text(), nil
+}
+
+func (n Node) text() string {
+	if n == (Node{}) {
+		return ""
+	}
+	return n.String()
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler in the form that does not depend on a particular version of the YAML package.
+func (n *Node) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLString(unmarshal, n.UnmarshalText)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// The text is a version without the "v" prefix that may be partial or contain wildcards (e.g. 1.0, 1, 1.*, *).
+func (v *Version) UnmarshalText(text []byte) error {
+	ver, err := parseVersionString(string(text))
+	if err != nil {
+		return err
+	}
+	*v = ver
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Version) MarshalJSON() ([]byte, error) {
+	return marshalJSONString(v.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Version) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONString(data, v.UnmarshalText)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (v Version) MarshalYAML() (interface{}, error) {
+	return v.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler in the form that does not depend on a particular version of the YAML package.
+func (v *Version) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLString(unmarshal, v.UnmarshalText)
+}
+
+func parseVersionString(s string) (Version, error) {
+	if s == "" {
+		return Version{}, nil
+	}
+	if s == string(Wildcard) {
+		return Version{HasMajorWildcard: true}, nil
+	}
+	majorStr, minorStr, hasMinor := strings.Cut(s, ".")
+	major, ok := parseVersionNumber(majorStr)
+	if !ok {
+		return Version{}, fmt.Errorf("invalid major part of version %q", s)
+	}
+	if !hasMinor {
+		return NewPartialVersion(uint(major)), nil
+	}
+	if minorStr == string(Wildcard) {
+		return Version{Major: NullVersion{uint(major), true}, HasMinorWildcard: true}, nil
+	}
+	minor, ok := parseVersionNumber(minorStr)
+	if !ok {
+		return Version{}, fmt.Errorf("invalid minor part of version %q", s)
+	}
+	if major == 0 && minor == 0 {
+		return Version{}, fmt.Errorf("version must be higher than 0.0")
+	}
+	return NewVersion(uint(major), uint(minor)), nil
+}
+
+func marshalJSONString(s string) ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func unmarshalJSONString(data []byte, unmarshalText func([]byte) error) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return unmarshalText([]byte(s))
+}
+
+func unmarshalYAMLString(unmarshal func(interface{}) error, unmarshalText func([]byte) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return unmarshalText([]byte(s))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type marshalConfig struct {
+	Expression Expression  `json:"expression" yaml:"expression"`
+	Node       Node        `json:"node" yaml:"node"`
+	Version    Version     `json:"version" yaml:"version"`
+	Optional   *Expression `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	cfg := marshalConfig{
+		Expression: MustParse(`cti.a.p.am.alert.v1.0~a.p.activity.canceled.v1.0[severity="critical"]`),
+		Node:       *MustParse("cti.a.p.event.v1.*").Head,
+		Version:    NewVersion(2, 1),
+	}
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"expression": "cti.a.p.am.alert.v1.0~a.p.activity.canceled.v1.0[severity=\"critical\"]",
+		"node": "a.p.event.v1.*",
+		"version": "2.1"
+	}`, string(data))
+	var fromJSON marshalConfig
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	require.Equal(t, cfg.Expression.String(), fromJSON.Expression.String())
+	require.Equal(t, cfg.Node, fromJSON.Node)
+	require.Equal(t, cfg.Version, fromJSON.Version)
+	require.Nil(t, fromJSON.Optional)
+
+	data, err = yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, `expression: cti.a.p.am.alert.v1.0~a.p.activity.canceled.v1.0[severity="critical"]
+node: a.p.event.v1.*
+version: "2.1"
+`, string(data))
+	var fromYAML marshalConfig
+	require.NoError(t, yaml.Unmarshal(data, &fromYAML))
+	require.Equal(t, cfg.Expression.String(), fromYAML.Expression.String())
+	require.Equal(t, cfg.Node, fromYAML.Node)
+	require.Equal(t, cfg.Version, fromYAML.Version)
+
+	// Zero values round-trip to empty strings.
+	data, err = json.Marshal(marshalConfig{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"expression": "", "node": "", "version": ""}`, string(data))
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	require.Nil(t, fromJSON.Expression.Head)
+	require.Equal(t, Node{}, fromJSON.Node)
+	require.Equal(t, Version{}, fromJSON.Version)
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	var cfg marshalConfig
+	require.Error(t, json.Unmarshal([]byte(`{"expression": "a.p.event.v1.0"}`), &cfg))
+	require.Error(t, json.Unmarshal([]byte(`{"expression": 1}`), &cfg))
+	require.Error(t, json.Unmarshal([]byte(`{"node": "a.p.event.v1.0~a.p.created.v1.0"}`), &cfg))
+	require.Error(t, yaml.Unmarshal([]byte(`node: A.p.event.v1.0`), &cfg))
+
+	for _, input := range []string{"v1.0", "01", "1.", "0.0", "1.0.0", "*.1"} {
+		var v Version
+		require.Error(t, v.UnmarshalText([]byte(input)), input)
+	}
+	for input, want := range map[string]Version{
+		"1":   NewPartialVersion(1),
+		"1.*": {Major: NullVersion{1, true}, HasMinorWildcard: true},
+		"*":   {HasMajorWildcard: true},
+	} {
+		var v Version
+		require.NoError(t, v.UnmarshalText([]byte(input)))
+		require.Equal(t, want, v)
+	}
+}
@@ -0,0 +1,151 @@
+package metadata
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// extraField is the name of the field of Annotations with annotations of third-party namespaces.
+const extraField = "Extra"
+
+// AnnotationDiff is a difference of a single annotation between two Annotations.
+// Old or New is nil if the annotation is not set on the respective side.
+type AnnotationDiff struct {
+	// Name is a name of the annotation, e.g. cti.reference.
+	Name string `json:"name"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// Equal reports whether both Annotations set the same annotations to the same values.
+// A single string and a one-element list of strings are considered equal,
+// since cti.cti, cti.reference and cti.schema accept both forms.
+func (a Annotations) Equal(other Annotations) bool {
+	return len(a.Diff(other)) == 0
+}
+
+// Diff returns differences between the Annotations and the other Annotations
+// in the order of the Annotations fields followed by extra annotations sorted by name.
+// Values are compared as in Equal.
+func (a Annotations) Diff(other Annotations) []AnnotationDiff {
+	var diffs []AnnotationDiff
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(other)
+	for i := 0; i < av.NumField(); i++ {
+		if av.Type().Field(i).Name == extraField {
+			continue
+		}
+		oldVal, newVal := annotationValue(av.Field(i)), annotationValue(bv.Field(i))
+		if reflect.DeepEqual(normalizeAnnotationValue(oldVal), normalizeAnnotationValue(newVal)) {
+			continue
+		}
+		diffs = append(diffs, AnnotationDiff{
+			Name: annotationName(av.Type().Field(i)),
+			Old:  oldVal,
+			New:  newVal,
+		})
+	}
+
+	names := make([]string, 0, len(a.Extra)+len(other.Extra))
+	for name := range a.Extra {
+		names = append(names, name)
+	}
+	for name := range other.Extra {
+		if _, ok := a.Extra[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldVal, newVal := a.Extra[name], other.Extra[name]
+		if !reflect.DeepEqual(normalizeAnnotationValue(oldVal), normalizeAnnotationValue(newVal)) {
+			diffs = append(diffs, AnnotationDiff{Name: name, Old: oldVal, New: newVal})
+		}
+	}
+	return diffs
+}
+
+// Merge returns Annotations where annotations of the override take precedence over the Annotations.
+// The Annotations are expected to be inherited from a parent and the override to be defined by a child:
+//   - an annotation that is set in the override replaces the inherited one entirely;
+//   - an annotation that is not set in the override is inherited as is;
+//   - cti.propertyNames and extra annotations are merged by key into new maps, with keys of the override taking precedence.
+func (a Annotations) Merge(override Annotations) Annotations {
+	res := a
+	rv, ov := reflect.ValueOf(&res).Elem(), reflect.ValueOf(override)
+	for i := 0; i < rv.NumField(); i++ {
+		if rv.Field(i).Kind() == reflect.Map {
+			continue
+		}
+		if annotationValue(ov.Field(i)) != nil {
+			rv.Field(i).Set(ov.Field(i))
+		}
+	}
+	res.PropertyNames = mergeAnnotationMaps(a.PropertyNames, override.PropertyNames)
+	res.Extra = mergeAnnotationMaps(a.Extra, override.Extra)
+	return res
+}
+
+// mergeAnnotationMaps returns a new map with keys of both maps, where keys of the override take precedence.
+// Nil is returned if both maps are nil.
+func mergeAnnotationMaps(m, override map[string]interface{}) map[string]interface{} {
+	if m == nil && override == nil {
+		return nil
+	}
+	res := make(map[string]interface{}, len(m)+len(override))
+	for k, v := range m {
+		res[k] = v
+	}
+	for k, v := range override {
+		res[k] = v
+	}
+	return res
+}
+
+// annotationValue returns the value of the annotation field or nil if the annotation is not set.
+func annotationValue(f reflect.Value) any {
+	switch f.Kind() {
+	case reflect.Ptr:
+		if f.IsNil() {
+			return nil
+		}
+		return f.Elem().Interface()
+	case reflect.Interface, reflect.Map:
+		if f.IsNil() {
+			return nil
+		}
+		return f.Interface()
+	case reflect.String:
+		if f.String() == "" {
+			return nil
+		}
+		return f.String()
+	default:
+		return f.Interface()
+	}
+}
+
+func annotationName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// normalizeAnnotationValue converts lists of strings to []any and unwraps one-element lists.
+func normalizeAnnotationValue(v any) any {
+	var list []any
+	switch v := v.(type) {
+	case []string:
+		list = make([]any, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+	case []any:
+		list = v
+	default:
+		return v
+	}
+	if len(list) == 1 {
+		return list[0]
+	}
+	return list
+}
@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/ramlx"
+)
+
+func Test_AnnotationsEqual(t *testing.T) {
+	yes, no := true, false
+
+	require.True(t, Annotations{}.Equal(Annotations{}))
+	require.True(t, Annotations{Final: &yes}.Equal(Annotations{Final: &yes}))
+	require.False(t, Annotations{Final: &yes}.Equal(Annotations{Final: &no}))
+	require.False(t, Annotations{Final: &no}.Equal(Annotations{}))
+	require.True(t, Annotations{Cti: "cti.a.p.x.v1.0"}.Equal(Annotations{Cti: []string{"cti.a.p.x.v1.0"}}))
+	require.True(t, Annotations{Cti: []string{"a", "b"}}.Equal(Annotations{Cti: []any{"a", "b"}}))
+	require.False(t, Annotations{Cti: []string{"a", "b"}}.Equal(Annotations{Cti: []string{"b", "a"}}))
+	require.False(t, Annotations{Reference: true}.Equal(Annotations{Reference: "cti.a.p.x.v1.0"}))
+}
+
+func Test_AnnotationsDiff(t *testing.T) {
+	yes := true
+
+	diffs := Annotations{
+		Reference: true,
+		Final:     &yes,
+		Meta:      "cti.a.p.meta.v1.0",
+		Extra:     map[string]interface{}{"acme.owner": "billing", "acme.sensitive": true},
+	}.Diff(Annotations{
+		Reference: "cti.a.p.x.v1.0",
+		Final:     &yes,
+		L10N:      &yes,
+		Extra:     map[string]interface{}{"acme.sensitive": true, "acme.retention": "P30D"},
+	})
+	require.Equal(t, []AnnotationDiff{
+		{Name: "cti.reference", Old: true, New: "cti.a.p.x.v1.0"},
+		{Name: "cti.l10n", New: true},
+		{Name: "cti.meta", Old: "cti.a.p.meta.v1.0"},
+		{Name: "acme.owner", Old: "billing"},
+		{Name: "acme.retention", New: "P30D"},
+	}, diffs)
+}
+
+func Test_AnnotationsMerge(t *testing.T) {
+	yes, no := true, false
+
+	parent := Annotations{
+		Reference:     "cti.a.p.x.v1.0",
+		Overridable:   &yes,
+		Final:         &no,
+		PropertyNames: map[string]interface{}{"a": 1, "b": 2},
+		Extra:         map[string]interface{}{"acme.owner": "billing", "acme.sensitive": false},
+	}
+	child := Annotations{
+		Reference:     "cti.a.p.x.v1.0~a.p.y.v1.0",
+		Final:         &yes,
+		PropertyNames: map[string]interface{}{"b": 3},
+		Extra:         map[string]interface{}{"acme.sensitive": true},
+	}
+
+	merged := parent.Merge(child)
+	require.Equal(t, "cti.a.p.x.v1.0~a.p.y.v1.0", merged.Reference)
+	require.Equal(t, &yes, merged.Overridable)
+	require.Equal(t, &yes, merged.Final)
+	require.Equal(t, map[string]interface{}{"a": 1, "b": 3}, merged.PropertyNames)
+	require.Equal(t, map[string]interface{}{"acme.owner": "billing", "acme.sensitive": true}, merged.Extra)
+
+	// Merge does not modify the operands.
+	require.Equal(t, map[string]interface{}{"a": 1, "b": 2}, parent.PropertyNames)
+	require.Equal(t, &no, parent.Final)
+
+	// Extra annotations are merged by key even if only one side sets them, and the result is a new map.
+	merged = Annotations{}.Merge(child)
+	merged.Extra["acme.owner"] = "audit"
+	require.Equal(t, map[string]interface{}{"acme.sensitive": true}, child.Extra)
+	merged = parent.Merge(Annotations{Final: &yes})
+	require.Equal(t, parent.Extra, merged.Extra)
+	merged.Extra["acme.owner"] = "audit"
+	require.Equal(t, "billing", parent.Extra["acme.owner"])
+
+	require.True(t, parent.Merge(Annotations{}).Equal(parent))
+	require.True(t, Annotations{}.Merge(child).Equal(child))
+}
+
+func Test_AnnotationsMatchRamlxCatalog(t *testing.T) {
+	// Annotations that are not declared by the cti.raml library.
+	undeclared := map[string]bool{"cti.meta": true, "cti.propertyNames": true}
+
+	typ := reflect.TypeOf(Annotations{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := annotationName(typ.Field(i))
+		if undeclared[name] || typ.Field(i).Name == extraField {
+			continue
+		}
+		_, ok := ramlx.LookupAnnotation(name)
+		require.True(t, ok, "annotation %s is not declared by ramlx", name)
+	}
+
+	reference, ok := ramlx.LookupAnnotation("cti.reference")
+	require.True(t, ok)
+	require.Equal(t, []string{"CTIWildcard", "CTIWildcard[]", "boolean"}, reference.Types)
+	require.Equal(t, []string{"TypeDeclaration"}, reference.AllowedTargets)
+
+	id, ok := ramlx.LookupAnnotation("cti.id")
+	require.True(t, ok)
+	require.True(t, id.Deprecated)
+
+	final, ok := ramlx.LookupAnnotation("cti.final")
+	require.True(t, ok)
+	require.Equal(t, true, final.Default)
+}
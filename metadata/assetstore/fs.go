@@ -0,0 +1,90 @@
+package assetstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+type fsStore struct {
+	fsys fs.FS
+}
+
+// NewFS makes a storage of assets in the file system, e.g. embed.FS of a service that ships its packages.
+func NewFS(fsys fs.FS) Store {
+	return &fsStore{fsys: fsys}
+}
+
+// NewDir makes a storage of assets in the directory, e.g. the directory of the package.
+func NewDir(dir string) Store {
+	return NewFS(os.DirFS(dir))
+}
+
+// List returns all files of the file system except hidden ones (e.g. the .dep directory of dependencies).
+func (s *fsStore) List(_ context.Context) ([]Info, error) {
+	var res []Info
+	err := fs.WalkDir(s.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		res = append(res, Info{Name: name, Size: fi.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk assets: %w", err)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+func (s *fsStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := s.fsys.Open(cleanName(name))
+	if err != nil {
+		return nil, wrapFSError(name, err)
+	}
+	return f, nil
+}
+
+func (s *fsStore) Stat(_ context.Context, name string) (Info, error) {
+	fi, err := fs.Stat(s.fsys, cleanName(name))
+	if err != nil {
+		return Info{}, wrapFSError(name, err)
+	}
+	if fi.IsDir() {
+		return Info{}, fmt.Errorf("asset %s is a directory", name)
+	}
+	return Info{Name: name, Size: fi.Size()}, nil
+}
+
+// cleanName converts the asset name to the name accepted by fs.FS, e.g. ./assets/logo.svg to assets/logo.svg.
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func wrapFSError(name string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return fmt.Errorf("open asset %s: %w", name, err)
+}
@@ -0,0 +1,141 @@
+package assetstore
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type s3Store struct {
+	endpoint string
+	bucket   string
+	prefix   string
+	client   *http.Client
+}
+
+type S3Option func(*s3Store)
+
+// WithHTTPClient sets the client of the S3 storage. Private buckets require a client
+// whose transport signs requests.
+func WithHTTPClient(client *http.Client) S3Option {
+	return func(s *s3Store) {
+		s.client = client
+	}
+}
+
+// NewS3 makes a storage of assets in the S3 bucket, e.g. NewS3("https://s3.eu-west-1.amazonaws.com", "packages", "x.y/").
+// Assets are objects with keys made of the prefix and the asset name. The storage uses path-style requests of
+// the S3 REST API, so it also works with S3-compatible storages (e.g. MinIO).
+func NewS3(endpoint string, bucket string, prefix string, opts ...S3Option) Store {
+	s := &s3Store{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		bucket:   bucket,
+		prefix:   prefix,
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *s3Store) List(ctx context.Context) ([]Info, error) {
+	var res []Info
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, s.endpoint+"/"+url.PathEscape(s.bucket)+"?"+q.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("list objects: %w", err)
+		}
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if strings.HasSuffix(obj.Key, "/") {
+				continue
+			}
+			res = append(res, Info{Name: strings.TrimPrefix(obj.Key, s.prefix), Size: obj.Size})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+func (s *s3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(name))
+	if err != nil {
+		return nil, fmt.Errorf("get asset %s: %w", name, err)
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Store) Stat(ctx context.Context, name string) (Info, error) {
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(name))
+	if err != nil {
+		return Info{}, fmt.Errorf("head asset %s: %w", name, err)
+	}
+	resp.Body.Close()
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return Info{}, fmt.Errorf("parse size of asset %s: %w", name, err)
+	}
+	return Info{Name: name, Size: size}, nil
+}
+
+func (s *s3Store) objectURL(name string) string {
+	key := s.prefix + cleanName(name)
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.endpoint + "/" + url.PathEscape(s.bucket) + "/" + strings.Join(segments, "/")
+}
+
+// do sends the request and returns the response if it succeeded.
+func (s *s3Store) do(ctx context.Context, method string, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("make request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
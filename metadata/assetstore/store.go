@@ -0,0 +1,30 @@
+// Package assetstore provides storages of package assets, i.e. binary files referenced by the cti.asset
+// annotation and listed in the package index. Assets are named by slash-separated paths relative to the root
+// of the storage, e.g. assets/logo.svg.
+package assetstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when the asset does not exist in the storage.
+var ErrNotFound = errors.New("asset not found")
+
+// Info describes an asset in the storage.
+type Info struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Store is a storage of package assets, e.g. the package directory (NewDir), an embedded file system (NewFS)
+// or an S3 bucket (NewS3).
+type Store interface {
+	// List returns assets of the storage sorted by name.
+	List(ctx context.Context) ([]Info, error)
+	// Open opens the asset for reading. It returns an error wrapping ErrNotFound if the asset does not exist.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Stat returns the description of the asset. It returns an error wrapping ErrNotFound if the asset does not exist.
+	Stat(ctx context.Context, name string) (Info, error)
+}
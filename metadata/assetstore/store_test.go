@@ -0,0 +1,103 @@
+package assetstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FSStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewFS(fstest.MapFS{
+		"assets/logo.svg":  {Data: []byte("<svg/>")},
+		"assets/readme.md": {Data: []byte("# Assets")},
+		".dep/x.y/a.txt":   {Data: []byte("dependency")},
+	})
+
+	list, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Info{{Name: "assets/logo.svg", Size: 6}, {Name: "assets/readme.md", Size: 8}}, list)
+
+	info, err := s.Stat(ctx, "./assets/logo.svg")
+	require.NoError(t, err)
+	require.Equal(t, int64(6), info.Size)
+
+	f, err := s.Open(ctx, "assets/logo.svg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, "<svg/>", string(data))
+
+	_, err = s.Stat(ctx, "assets/missing.svg")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Open(ctx, "../assets/missing.svg")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Stat(ctx, "assets")
+	require.ErrorContains(t, err, "is a directory")
+}
+
+func Test_S3Store(t *testing.T) {
+	objects := map[string]string{
+		"x.y/assets/logo.svg":  "<svg/>",
+		"x.y/assets/readme.md": "# Assets",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bucket" {
+			require.Equal(t, "2", r.URL.Query().Get("list-type"))
+			prefix := r.URL.Query().Get("prefix")
+			// The first page is truncated to check pagination.
+			keys := []string{"x.y/assets/readme.md"}
+			truncated := r.URL.Query().Get("continuation-token") == ""
+			if !truncated {
+				keys = []string{"x.y/assets/", "x.y/assets/logo.svg"}
+			}
+			fmt.Fprint(w, `<ListBucketResult>`)
+			for _, key := range keys {
+				require.True(t, strings.HasPrefix(key, prefix))
+				fmt.Fprintf(w, `<Contents><Key>%s</Key><Size>%d</Size></Contents>`, key, len(objects[key]))
+			}
+			fmt.Fprintf(w, `<IsTruncated>%t</IsTruncated><NextContinuationToken>next</NextContinuationToken></ListBucketResult>`, truncated)
+			return
+		}
+		data, ok := objects[strings.TrimPrefix(r.URL.Path, "/bucket/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, data)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := NewS3(srv.URL+"/", "bucket", "x.y/", WithHTTPClient(srv.Client()))
+
+	list, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Info{{Name: "assets/logo.svg", Size: 6}, {Name: "assets/readme.md", Size: 8}}, list)
+
+	info, err := s.Stat(ctx, "assets/readme.md")
+	require.NoError(t, err)
+	require.Equal(t, Info{Name: "assets/readme.md", Size: 8}, info)
+
+	f, err := s.Open(ctx, "assets/logo.svg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, "<svg/>", string(data))
+
+	_, err = s.Stat(ctx, "assets/missing.svg")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,127 @@
+package metadata
+
+import (
+	"encoding/json"
+)
+
+// Clone returns a deep copy of the entity, so the copy may be modified in place
+// without affecting the entity, e.g. by copy-on-write updates of registries.
+func (e *Entity) Clone() *Entity {
+	c := *e
+	c.Dictionaries = cloneValue(e.Dictionaries).(map[string]interface{})
+	c.Values = cloneRaw(e.Values)
+	c.Schema = cloneRaw(e.Schema)
+	c.TraitsSchema = cloneRaw(e.TraitsSchema)
+	c.Traits = cloneRaw(e.Traits)
+	c.TraitsAnnotations = cloneAnnotations(e.TraitsAnnotations)
+	c.Annotations = cloneAnnotations(e.Annotations)
+	c.Tags = cloneStrings(e.Tags)
+	c.Owners = cloneStrings(e.Owners)
+	if e.SourceMap.AnnotationType != nil {
+		annotationType := *e.SourceMap.AnnotationType
+		c.SourceMap.AnnotationType = &annotationType
+	}
+	if e.ValuesSourceLocation != nil {
+		location := *e.ValuesSourceLocation
+		c.ValuesSourceLocation = &location
+	}
+	if e.SchemaSourceMap != nil {
+		c.SchemaSourceMap = make(map[GJsonPath]SourceLocation, len(e.SchemaSourceMap))
+		for k, v := range e.SchemaSourceMap {
+			c.SchemaSourceMap[k] = v
+		}
+	}
+	if e.AnnotationsSourceMap != nil {
+		c.AnnotationsSourceMap = make(map[GJsonPath]map[string]SourceLocation, len(e.AnnotationsSourceMap))
+		for k, locations := range e.AnnotationsSourceMap {
+			m := make(map[string]SourceLocation, len(locations))
+			for name, location := range locations {
+				m[name] = location
+			}
+			c.AnnotationsSourceMap[k] = m
+		}
+	}
+	return &c
+}
+
+// Clone returns a deep copy of the annotations.
+func (a Annotations) Clone() Annotations {
+	c := a
+	c.Cti = cloneValue(a.Cti)
+	c.ID = cloneBool(a.ID)
+	c.DisplayName = cloneBool(a.DisplayName)
+	c.Description = cloneBool(a.Description)
+	c.Reference = cloneValue(a.Reference)
+	c.Overridable = cloneBool(a.Overridable)
+	c.Final = cloneBool(a.Final)
+	c.Deprecated = cloneBool(a.Deprecated)
+	c.Asset = cloneBool(a.Asset)
+	c.Dictionary = cloneBool(a.Dictionary)
+	c.Sensitive = cloneBool(a.Sensitive)
+	c.L10N = cloneBool(a.L10N)
+	c.Schema = cloneValue(a.Schema)
+	c.PropertyNames = cloneValue(a.PropertyNames).(map[string]interface{})
+	c.Extra = cloneValue(a.Extra).(map[string]interface{})
+	return c
+}
+
+func cloneAnnotations(m map[GJsonPath]Annotations) map[GJsonPath]Annotations {
+	if m == nil {
+		return nil
+	}
+	res := make(map[GJsonPath]Annotations, len(m))
+	for k, v := range m {
+		res[k] = v.Clone()
+	}
+	return res
+}
+
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(s)), s...)
+}
+
+func cloneRaw(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	return append(make(json.RawMessage, 0, len(raw)), raw...)
+}
+
+// cloneValue deeply copies maps and slices of decoded JSON values. Typed nil maps are kept typed.
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[k] = cloneValue(item)
+		}
+		return res
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = cloneValue(item)
+		}
+		return res
+	case []string:
+		return cloneStrings(v)
+	default:
+		return v
+	}
+}
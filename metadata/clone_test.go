@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EntityClone(t *testing.T) {
+	yes := true
+	e := &Entity{
+		Cti:    "cti.a.p.event.v1.0",
+		Schema: []byte(`{"type":"object"}`),
+		Annotations: map[GJsonPath]Annotations{
+			".id": {ID: &yes, Extra: map[string]interface{}{"x.tags": []interface{}{"a"}}},
+		},
+		Tags:                 []string{"a"},
+		SourceMap:            SourceMap{InstanceAnnotationReference: InstanceAnnotationReference{AnnotationType: &AnnotationType{Name: "A"}}},
+		SchemaSourceMap:      map[GJsonPath]SourceLocation{".": {Path: "a.raml"}},
+		ValuesSourceLocation: &SourceLocation{Path: "a.raml"},
+	}
+	c := e.Clone()
+	require.Equal(t, e, c)
+
+	*c.Annotations[".id"].ID = false
+	c.Annotations[".id"].Extra["x.tags"].([]interface{})[0] = "b"
+	c.Schema[0] = '['
+	c.Tags[0] = "b"
+	c.SourceMap.AnnotationType.Name = "B"
+	c.SchemaSourceMap["."] = SourceLocation{Path: "b.raml"}
+	c.ValuesSourceLocation.Path = "b.raml"
+
+	require.True(t, *e.Annotations[".id"].ID)
+	require.Equal(t, []interface{}{"a"}, e.Annotations[".id"].Extra["x.tags"])
+	require.Equal(t, `{"type":"object"}`, string(e.Schema))
+	require.Equal(t, []string{"a"}, e.Tags)
+	require.Equal(t, "A", e.SourceMap.AnnotationType.Name)
+	require.Equal(t, "a.raml", e.SchemaSourceMap["."].Path)
+	require.Equal(t, "a.raml", e.ValuesSourceLocation.Path)
+}
@@ -80,7 +80,7 @@ func (c *AnnotationsCollector) collectAnnotations(ctx string, s *raml.BaseShape)
 	filtered := make([]*raml.DomainExtension, 0)
 	for pair := s.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
 		annotation := pair.Value
-		if strings.HasPrefix(annotation.Name, MetadataPrefix) {
+		if strings.HasPrefix(annotation.Name, MetadataPrefix) || metadata.HasAnnotationNamespace(annotation.Name) {
 			filtered = append(filtered, annotation)
 		}
 	}
@@ -104,9 +104,22 @@ func (c *AnnotationsCollector) collectAnnotations(ctx string, s *raml.BaseShape)
 		case metadata.Asset:
 			v := annotation.Extension.Value.(bool)
 			item.Asset = &v
+		case metadata.Dictionary:
+			v := annotation.Extension.Value.(bool)
+			item.Dictionary = &v
+		case metadata.Sensitive:
+			v := annotation.Extension.Value.(bool)
+			item.Sensitive = &v
 		case metadata.Overridable:
 			v := annotation.Extension.Value.(bool)
 			item.Overridable = &v
+		case metadata.Deprecated:
+			v := annotation.Extension.Value.(bool)
+			item.Deprecated = &v
+		case metadata.DeprecationMessage:
+			item.DeprecationMessage = annotation.Extension.Value.(string)
+		case metadata.ReplacedBy:
+			item.ReplacedBy = annotation.Extension.Value.(string)
 		case metadata.Reference:
 			item.Reference = annotation.Extension.Value
 		case metadata.Schema:
@@ -121,6 +134,14 @@ func (c *AnnotationsCollector) collectAnnotations(ctx string, s *raml.BaseShape)
 			item.Description = &v
 		case metadata.PropertyNames:
 			item.PropertyNames = annotation.Extension.Value.(map[string]interface{})
+		default:
+			if strings.HasPrefix(annotation.Name, MetadataPrefix) {
+				continue
+			}
+			if item.Extra == nil {
+				item.Extra = make(map[string]interface{})
+			}
+			item.Extra[annotation.Name] = annotation.Extension.Value
 		}
 	}
 	c.annotations[metadata.GJsonPath(ctx)] = item
@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// GetAssetReferences returns names of the assets referenced by values of the instance, i.e. values of properties
+// annotated with cti.asset in the types of the inheritance chain. Names are sorted and deduplicated.
+func (r *MetadataRegistry) GetAssetReferences(cti string) []string {
+	entity, ok := r.Index[cti]
+	if !ok || entity.Values == nil {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var res []string
+	for id := metadata.GetParentCti(cti); ; id = metadata.GetParentCti(id) {
+		if typ, ok := r.Index[id]; ok {
+			for key, annotation := range typ.Annotations {
+				if annotation.Asset == nil || !*annotation.Asset {
+					continue
+				}
+				for _, val := range key.GetValue(entity.Values).Array() {
+					if _, ok := seen[val.Str]; ok || val.Str == "" {
+						continue
+					}
+					seen[val.Str] = struct{}{}
+					res = append(res, val.Str)
+				}
+			}
+		}
+		if metadata.GetParentCti(id) == id {
+			break
+		}
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_RegistryGetAssetReferences(t *testing.T) {
+	asset := true
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti:    "cti.x.y.app.v1.0",
+			Schema: []byte(`{"type": "object"}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".icon": {Asset: &asset},
+			},
+		},
+		{
+			Cti:    "cti.x.y.app.v1.0~x.y.plugin.v1.0",
+			Schema: []byte(`{"type": "object"}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".screenshots": {Asset: &asset},
+			},
+		},
+		{
+			Cti:    "cti.x.y.app.v1.0~x.y.plugin.v1.0~x.y.backup.v1.0",
+			Values: []byte(`{"icon": "assets/icon.svg", "screenshots": ["assets/b.png", "assets/a.png", "assets/icon.svg"]}`),
+		},
+		{Cti: "cti.x.y.app.v1.0~x.y.plugin.v1.0~x.y.empty.v1.0", Values: []byte(`{"icon": ""}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	require.Equal(t, []string{"assets/a.png", "assets/b.png", "assets/icon.svg"},
+		r.GetAssetReferences("cti.x.y.app.v1.0~x.y.plugin.v1.0~x.y.backup.v1.0"))
+	require.Empty(t, r.GetAssetReferences("cti.x.y.app.v1.0~x.y.plugin.v1.0~x.y.empty.v1.0"))
+	require.Empty(t, r.GetAssetReferences("cti.x.y.app.v1.0"))
+	require.Empty(t, r.GetAssetReferences("cti.x.y.unknown.v1.0"))
+}
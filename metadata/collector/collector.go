@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/acronis/go-cti"
 	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/ramlx"
 	"github.com/acronis/go-raml"
 )
 
@@ -18,6 +20,10 @@ type Collector struct {
 	raml                 *raml.RAML
 	jsonSchemaConverter  *raml.JSONSchemaConverter
 	annotationsCollector *AnnotationsCollector
+	// sourceRanges are shared by locations collectors, so source files are parsed once.
+	sourceRanges *sourceRanges
+	// conversionHooks customize schemas of CTI types, see AddConversionHook.
+	conversionHooks []ConversionHook
 
 	ctiParser *cti.Parser
 
@@ -36,6 +42,7 @@ func New() *Collector {
 	return &Collector{
 		jsonSchemaConverter:  raml.NewJSONSchemaConverter(raml.WithOmitRefs(true)),
 		annotationsCollector: NewAnnotationsCollector(),
+		sourceRanges:         newSourceRanges(),
 		ctiParser:            cti.NewParser(),
 		LocalRegistry:        NewMetadataRegistry(),
 		GlobalRegistry:       NewMetadataRegistry(),
@@ -45,13 +52,25 @@ func New() *Collector {
 	}
 }
 
+// SetFragmentCache makes the collector decode source files with the cache, e.g. shared by collectors
+// of several packages. Source files that are already decoded are not affected.
+func (c *Collector) SetFragmentCache(cache *ramlx.FragmentCache) {
+	c.sourceRanges.fragments = cache
+}
+
 func (c *Collector) SetRaml(r *raml.RAML) {
 	c.raml = r
-	c.baseDir = r.GetLocation()
+	// Location points to the RAML file, source paths are relative to its directory.
+	c.baseDir = filepath.Dir(r.GetLocation())
 	c.localRamlCtiTypes = make(map[string]*raml.BaseShape)
 }
 
 func (c *Collector) Collect(isLocal bool) error {
+	return c.CollectContext(context.Background(), isLocal)
+}
+
+// CollectContext is like Collect, but stops with the error of the context when the context is done.
+func (c *Collector) CollectContext(ctx context.Context, isLocal bool) error {
 	if c.raml == nil {
 		return fmt.Errorf("raml is not set")
 	}
@@ -60,6 +79,9 @@ func (c *Collector) Collect(isLocal bool) error {
 		return fmt.Errorf("entry point is not a library")
 	}
 	for pair := idx.Uses.Oldest(); pair != nil; pair = pair.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		ref := pair.Value
 		for pair := ref.Link.Types.Oldest(); pair != nil; pair = pair.Next() {
 			shape := pair.Value
@@ -78,6 +100,9 @@ func (c *Collector) Collect(isLocal bool) error {
 	// NOTE: This is a custom pipeline for RAML-CTI types processing.
 	// Unwrap implemented in go-raml cannot be used since CTI types require special handling.
 	for k, shape := range c.localRamlCtiTypes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// Create a copy of CTI type and unwrap it using special rules.
 		//
 		// NOTE: Copy is required since CTI types may share some RAML types.
@@ -131,6 +156,33 @@ func (c *Collector) MakeMetadataTypeFromShape(id string, shape *raml.BaseShape)
 	if val, ok := shape.CustomDomainProperties.Get(metadata.Final); ok {
 		final = val.Extension.Value.(bool)
 	}
+	var access string
+	if val, ok := shape.CustomDomainProperties.Get(metadata.Access); ok {
+		access = val.Extension.Value.(string)
+	}
+	var status string
+	if val, ok := shape.CustomDomainProperties.Get(metadata.Status); ok {
+		status = val.Extension.Value.(string)
+	}
+	deprecated := status == metadata.StatusDeprecated
+	if val, ok := shape.CustomDomainProperties.Get(metadata.Deprecated); ok {
+		deprecated = val.Extension.Value.(bool)
+	}
+	var deprecationMessage, replacedBy string
+	if val, ok := shape.CustomDomainProperties.Get(metadata.DeprecationMessage); ok {
+		deprecationMessage = val.Extension.Value.(string)
+	}
+	if val, ok := shape.CustomDomainProperties.Get(metadata.ReplacedBy); ok {
+		replacedBy = val.Extension.Value.(string)
+	}
+	tags, err := readStringsAnnotation(shape, metadata.Tags)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := readStringsAnnotation(shape, metadata.Owners)
+	if err != nil {
+		return nil, err
+	}
 	var traitsBytes []byte
 	if shape.CustomShapeFacets != nil {
 		if t, ok := shape.CustomShapeFacets.Get(metadata.Traits); ok {
@@ -152,7 +204,13 @@ func (c *Collector) MakeMetadataTypeFromShape(id string, shape *raml.BaseShape)
 		return nil, fmt.Errorf("convert schema: %w", err)
 	}
 	schemaBytes, _ := json.Marshal(schema)
+	schemaBytes, err = c.applyConversionHooks(id, shape, schemaBytes)
+	if err != nil {
+		return nil, fmt.Errorf("apply conversion hooks: %w", err)
+	}
 	annotations := c.annotationsCollector.Collect(shape.Shape)
+	locationsCollector := newLocationsCollector(c.baseDir, c.sourceRanges)
+	schemaSourceMap := locationsCollector.Collect(shape.Shape)
 
 	originalPath, _ := filepath.Rel(c.baseDir, shape.Location)
 	// FIXME: sourcePath points to itself or to next parent, if present.
@@ -163,14 +221,19 @@ func (c *Collector) MakeMetadataTypeFromShape(id string, shape *raml.BaseShape)
 	}
 
 	entity := &metadata.Entity{
-		Cti:               id,
-		Final:             final,
-		DisplayName:       displayName,
-		Description:       description,
-		Schema:            schemaBytes,
-		Traits:            traitsBytes,
-		TraitsSchema:      traitsSchemaBytes,
-		TraitsAnnotations: traitsAnnotations,
+		Cti:                id,
+		Final:              final,
+		Access:             access,
+		Status:             status,
+		Deprecated:         deprecated,
+		DeprecationMessage: deprecationMessage,
+		ReplacedBy:         replacedBy,
+		DisplayName:        displayName,
+		Description:        description,
+		Schema:             schemaBytes,
+		Traits:             traitsBytes,
+		TraitsSchema:       traitsSchemaBytes,
+		TraitsAnnotations:  traitsAnnotations,
 		SourceMap: metadata.SourceMap{
 			TypeAnnotationReference: metadata.TypeAnnotationReference{
 				Name: shape.Name,
@@ -178,7 +241,11 @@ func (c *Collector) MakeMetadataTypeFromShape(id string, shape *raml.BaseShape)
 			OriginalPath: filepath.ToSlash(originalPath),
 			SourcePath:   filepath.ToSlash(sourcePath),
 		},
-		Annotations: annotations,
+		Annotations:          annotations,
+		Tags:                 tags,
+		Owners:               owners,
+		SchemaSourceMap:      schemaSourceMap,
+		AnnotationsSourceMap: locationsCollector.AnnotationLocations(),
 	}
 
 	return entity, nil
@@ -446,6 +513,27 @@ func (c *Collector) readMetadataCti(base *raml.BaseShape) ([]string, error) {
 	return nil, fmt.Errorf("cti.cti must be string or array of strings")
 }
 
+// readStringsAnnotation reads the value of the annotation that is declared as an array of strings, e.g. cti.tags.
+func readStringsAnnotation(base *raml.BaseShape, name string) ([]string, error) {
+	val, ok := base.CustomDomainProperties.Get(name)
+	if !ok {
+		return nil, nil
+	}
+	items, ok := val.Extension.Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be array of strings", name)
+	}
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be array of strings", name)
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
 func (c *Collector) readCtiType(base *raml.BaseShape) error {
 	ctis, err := c.readMetadataCti(base)
 	if err != nil {
@@ -518,7 +606,7 @@ func (c *Collector) readAndMakeCtiInstances(annotation *raml.DomainExtension, is
 	}
 	idKey := idProp.Name
 
-	for _, item := range annotation.Extension.Value.([]interface{}) {
+	for i, item := range annotation.Extension.Value.([]interface{}) {
 		obj := item.(map[string]interface{})
 		id := obj[idKey].(string)
 
@@ -531,6 +619,7 @@ func (c *Collector) readAndMakeCtiInstances(annotation *raml.DomainExtension, is
 		}
 
 		entity := c.MakeMetadataInstanceFromExtension(id, s, obj, annotation.Extension.Location)
+		entity.ValuesSourceLocation = c.instanceLocation(annotation, i, entity.SourceMap.OriginalPath)
 		err = c.GlobalRegistry.Add(entity.SourceMap.OriginalPath, entity)
 		if err != nil {
 			return fmt.Errorf("add cti entity: %w", err)
@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// mapEntryOverhead is an approximate number of bytes used by a map entry in addition to its key and value.
+const mapEntryOverhead = 16
+
+var (
+	entitySize      = int64(reflect.TypeOf(metadata.Entity{}).Size())
+	annotationsSize = int64(reflect.TypeOf(metadata.Annotations{}).Size())
+	locationSize    = int64(reflect.TypeOf(metadata.SourceLocation{}).Size())
+	pointerSize     = int64(reflect.TypeOf(&metadata.Entity{}).Size())
+)
+
+// AddCompactHook registers a function that is called by Compact and Restore.
+// Hooks are used to drop data derived from the registry that can be recomputed on demand, e.g. cached merged schemas.
+func (r *MetadataRegistry) AddCompactHook(hook func()) {
+	r.compactHooks = append(r.compactHooks, hook)
+}
+
+// Compact releases memory that is not required to look up entities of the registry.
+// Raw JSON is the only representation of schemas, traits and values of the entities, so it is kept:
+// only insignificant whitespace and unused capacity of its buffers are released.
+// Indexes are rebuilt to release space of removed entries, and the completion index and compact hooks
+// drop derived data, e.g. merged schemas, that is recomputed lazily.
+// Compact must not be called concurrently with other methods of the registry.
+func (r *MetadataRegistry) Compact() {
+	for _, entity := range r.Index {
+		entity.Schema = compactRaw(entity.Schema)
+		entity.TraitsSchema = compactRaw(entity.TraitsSchema)
+		entity.Traits = compactRaw(entity.Traits)
+		entity.Values = compactRaw(entity.Values)
+	}
+
+	r.Types = cloneEntitiesMap(r.Types)
+	r.Instances = cloneEntitiesMap(r.Instances)
+	r.Index = cloneEntitiesMap(r.Index)
+	r.Tags = cloneIndex(r.Tags)
+	r.Owners = cloneIndex(r.Owners)
+	fragments := make(map[string]metadata.Entities, len(r.FragmentEntities))
+	for path, entities := range r.FragmentEntities {
+		if len(entities) != 0 {
+			fragments[path] = append(make(metadata.Entities, 0, len(entities)), entities...)
+		}
+	}
+	r.FragmentEntities = fragments
+	r.dropDerived()
+}
+
+// dropDerived drops data derived from the registry, i.e. the completion index and data of compact hooks.
+func (r *MetadataRegistry) dropDerived() {
+	r.sortedIDs = nil
+	for _, hook := range r.compactHooks {
+		hook()
+	}
+}
+
+// MemoryFootprint returns an estimated number of bytes used by the entities and indexes of the registry.
+// Data derived from the registry, e.g. cached merged schemas, is not included.
+func (r *MetadataRegistry) MemoryFootprint() int64 {
+	var size int64
+	for id, entity := range r.Index {
+		size += entitySize + int64(len(id))
+		size += int64(len(entity.DisplayName) + len(entity.Description))
+		size += int64(cap(entity.Schema) + cap(entity.TraitsSchema) + cap(entity.Traits) + cap(entity.Values))
+		size += int64(len(entity.Annotations)+len(entity.TraitsAnnotations)) * (annotationsSize + mapEntryOverhead)
+		for path := range entity.Annotations {
+			size += int64(len(path))
+		}
+		for path := range entity.SchemaSourceMap {
+			size += int64(len(path)) + locationSize + mapEntryOverhead
+		}
+		if entity.ValuesSourceLocation != nil {
+			size += locationSize
+		}
+		for _, s := range entity.Tags {
+			size += int64(len(s))
+		}
+		for _, s := range entity.Owners {
+			size += int64(len(s))
+		}
+		size += int64(len(entity.SourceMap.OriginalPath) + len(entity.SourceMap.SourcePath))
+	}
+
+	// Indexes keep pointers to the entities and share keys with them.
+	entries := len(r.Index) + len(r.Types) + len(r.Instances)
+	for _, entities := range r.FragmentEntities {
+		entries += len(entities)
+	}
+	for _, entities := range r.Tags {
+		entries += len(entities)
+	}
+	for _, entities := range r.Owners {
+		entries += len(entities)
+	}
+	size += int64(entries) * (pointerSize + mapEntryOverhead)
+	return size
+}
+
+// compactRaw returns JSON without insignificant whitespace in a buffer of the exact size.
+func compactRaw(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	if buf.Len() == len(raw) && len(raw) == cap(raw) {
+		return raw
+	}
+	return append(make(json.RawMessage, 0, buf.Len()), buf.Bytes()...)
+}
+
+func cloneEntitiesMap(m metadata.EntitiesMap) metadata.EntitiesMap {
+	res := make(metadata.EntitiesMap, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+func cloneIndex(index map[string]metadata.EntitiesMap) map[string]metadata.EntitiesMap {
+	res := make(map[string]metadata.EntitiesMap, len(index))
+	for k, entities := range index {
+		if len(entities) != 0 {
+			res[k] = cloneEntitiesMap(entities)
+		}
+	}
+	return res
+}
@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegistryCompact(t *testing.T) {
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:    "cti.a.p.event.v1.0",
+		Schema: []byte("{\n  \"type\": \"object\"\n}"),
+		Tags:   []string{"audit"},
+		SourceMap: metadata.SourceMap{
+			OriginalPath: "types.raml",
+		},
+	}))
+	require.NoError(t, r.Add("instances.raml", &metadata.Entity{
+		Cti:    "cti.a.p.event.v1.0~a.p.created.v1.0",
+		Values: []byte(`{ "name": "created" }`),
+	}))
+	r.unindex(r.Index["cti.a.p.event.v1.0"])
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:    "cti.a.p.event.v1.0",
+		Schema: []byte("{\n  \"type\": \"object\"\n}"),
+	}))
+
+	compacted := 0
+	r.AddCompactHook(func() { compacted++ })
+
+	before := r.MemoryFootprint()
+	r.Compact()
+	require.Less(t, r.MemoryFootprint(), before)
+	require.Equal(t, 1, compacted)
+
+	require.Equal(t, `{"type":"object"}`, string(r.Index["cti.a.p.event.v1.0"].Schema))
+	require.Equal(t, `{"name":"created"}`, string(r.Instances["cti.a.p.event.v1.0~a.p.created.v1.0"].Values))
+	require.Same(t, r.Index["cti.a.p.event.v1.0"], r.Types["cti.a.p.event.v1.0"])
+	require.Empty(t, r.Tags)
+	require.Len(t, r.FragmentEntities["types.raml"], 1)
+}
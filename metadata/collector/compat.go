@@ -0,0 +1,267 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// InternalTag marks types that are not a part of the public API of the package,
+// so their changes are not checked by CheckCompatibility.
+const InternalTag = "internal"
+
+const (
+	SchemaKindSchema       = "schema"
+	SchemaKindTraitsSchema = "traits_schema"
+)
+
+// BreakingChange is a change of a public type that breaks consumers of the baseline version of the type.
+type BreakingChange struct {
+	Cti string `json:"cti"`
+	// Kind is the changed schema of the type: SchemaKindSchema or SchemaKindTraitsSchema.
+	// It is empty if the type itself is removed.
+	Kind    string             `json:"kind,omitempty"`
+	Path    metadata.GJsonPath `json:"path,omitempty"`
+	Message string             `json:"message"`
+}
+
+func (c BreakingChange) String() string {
+	if c.Kind == "" {
+		return fmt.Sprintf("%s: %s", c.Cti, c.Message)
+	}
+	return fmt.Sprintf("%s: %s %s: %s", c.Cti, c.Kind, c.Path, c.Message)
+}
+
+type compatConfig struct {
+	exempt []string
+}
+
+type CompatOption func(*compatConfig)
+
+// WithCompatExemptions excludes types matching the CTI expressions from the check,
+// e.g. cti.a.p.experimental.* for types that are known to be unstable.
+func WithCompatExemptions(exprs ...string) CompatOption {
+	return func(c *compatConfig) {
+		c.exempt = append(c.exempt, exprs...)
+	}
+}
+
+// CheckCompatibility reports breaking changes of public types of the baseline registry, e.g. of the previously
+// published version of the package, in the current registry. Public types are types with cti.final set to false
+// that are not tagged with InternalTag. Removal of the type, removal of properties, changes of property types, new required properties,
+// narrowing of constraints (see jsonschema.CompareConstraints) and added or changed cti.reference and cti.schema
+// annotations of properties (see metadata.Annotations.Diff) are breaking.
+// Changes are sorted by CTI, kind and path.
+func CheckCompatibility(baseline, current *MetadataRegistry, opts ...CompatOption) ([]BreakingChange, error) {
+	var cfg compatConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	p := cti.NewParser()
+	exempt := make([]cti.Expression, 0, len(cfg.exempt))
+	for _, raw := range cfg.exempt {
+		expr, err := p.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse exemption %s: %w", raw, err)
+		}
+		exempt = append(exempt, expr)
+	}
+
+	var res []BreakingChange
+	for id, old := range baseline.Types {
+		if old.Final || old.HasTag(InternalTag) {
+			continue
+		}
+		expr, err := p.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", id, err)
+		}
+		isExempt := false
+		for _, e := range exempt {
+			if ok, err := e.Match(expr); err == nil && ok {
+				isExempt = true
+				break
+			}
+		}
+		if isExempt {
+			continue
+		}
+
+		cur, ok := current.Types[id]
+		if !ok {
+			res = append(res, BreakingChange{Cti: id, Message: "type is removed"})
+			continue
+		}
+		changes, err := CheckTypeCompatibility(old, cur)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, changes...)
+	}
+	sortBreakingChanges(res)
+	return res, nil
+}
+
+// CheckTypeCompatibility reports breaking changes of schemas of the baseline version of the type
+// in its current version, see CheckCompatibility. Changes are sorted by kind and path.
+func CheckTypeCompatibility(baseline, current *metadata.Entity) ([]BreakingChange, error) {
+	var res []BreakingChange
+	for _, s := range []struct {
+		kind                           string
+		old, cur                       json.RawMessage
+		oldAnnotations, curAnnotations map[metadata.GJsonPath]metadata.Annotations
+	}{
+		{
+			kind: SchemaKindSchema, old: baseline.Schema, cur: current.Schema,
+			oldAnnotations: baseline.Annotations, curAnnotations: current.Annotations,
+		},
+		{
+			kind: SchemaKindTraitsSchema, old: baseline.TraitsSchema, cur: current.TraitsSchema,
+			oldAnnotations: baseline.TraitsAnnotations, curAnnotations: current.TraitsAnnotations,
+		},
+	} {
+		if s.old == nil {
+			continue
+		}
+		changes, err := compareSchemas(s.old, s.cur)
+		if err != nil {
+			return nil, fmt.Errorf("compare %s of %s: %w", s.kind, baseline.Cti, err)
+		}
+		changes = append(changes, compareAnnotations(s.oldAnnotations, s.curAnnotations)...)
+		for _, change := range changes {
+			change.Cti, change.Kind = baseline.Cti, s.kind
+			res = append(res, change)
+		}
+	}
+	sortBreakingChanges(res)
+	return res, nil
+}
+
+func sortBreakingChanges(res []BreakingChange) {
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Cti != res[j].Cti {
+			return res[i].Cti < res[j].Cti
+		}
+		if res[i].Kind != res[j].Kind {
+			return res[i].Kind < res[j].Kind
+		}
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Message < res[j].Message
+	})
+}
+
+// compareAnnotations reports added and changed annotations that restrict values of properties to other CTI types.
+// Removal of such annotations accepts more values, so it is not breaking.
+func compareAnnotations(oldVal, curVal map[metadata.GJsonPath]metadata.Annotations) []BreakingChange {
+	var res []BreakingChange
+	for _, change := range diffAnnotations(oldVal, curVal) {
+		if (change.Name != metadata.Reference && change.Name != metadata.Schema) || change.New == nil {
+			continue
+		}
+		msg := fmt.Sprintf("annotation %s %v is added", change.Name, change.New)
+		if change.Old != nil {
+			msg = fmt.Sprintf("annotation %s is changed from %v to %v", change.Name, change.Old, change.New)
+		}
+		res = append(res, BreakingChange{Path: change.Path, Message: msg})
+	}
+	return res
+}
+
+// compareSchemas compares nodes of the schemas by paths. The root node has the "." path.
+func compareSchemas(oldRaw, curRaw json.RawMessage) ([]BreakingChange, error) {
+	oldNodes, err := schemaNodes(oldRaw)
+	if err != nil {
+		return nil, err
+	}
+	curNodes, err := schemaNodes(curRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []BreakingChange
+	for path, o := range oldNodes {
+		oldNode, _ := o.(map[string]any)
+		c, ok := curNodes[path]
+		if !ok {
+			if path == "." {
+				res = append(res, BreakingChange{Path: metadata.GJsonPath(path), Message: "schema is removed"})
+			} else {
+				res = append(res, BreakingChange{Path: metadata.GJsonPath(path), Message: "property is removed"})
+			}
+			continue
+		}
+		curNode, _ := c.(map[string]any)
+		for _, msg := range compareNodes(oldNode, curNode) {
+			res = append(res, BreakingChange{Path: metadata.GJsonPath(path), Message: msg})
+		}
+	}
+	return res, nil
+}
+
+// schemaNodes flattens the schema by paths of its properties. Nodes do not include nested properties and array items.
+func schemaNodes(raw json.RawMessage) (map[string]any, error) {
+	res := make(map[string]any)
+	if raw == nil {
+		return res, nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal schema: %w", err)
+	}
+	definitions, _ := schema["definitions"].(map[string]any)
+	root := schema
+	if ref, ok := schema["$ref"].(string); ok {
+		if def, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any); ok {
+			root = def
+		}
+	}
+	res["."] = shallowSchema(root)
+	flattenSchemaProperties(".", schema, definitions, res, make(map[string]struct{}))
+	return res, nil
+}
+
+// compareNodes returns descriptions of breaking changes between the nodes of the schemas.
+func compareNodes(oldNode, curNode map[string]any) []string {
+	var res []string
+	if o, ok := oldNode["type"]; ok && !reflect.DeepEqual(o, curNode["type"]) {
+		res = append(res, fmt.Sprintf("type is changed from %v to %v", o, curNode["type"]))
+	}
+
+	oldRequired := stringSet(oldNode["required"])
+	var required []string
+	for name := range stringSet(curNode["required"]) {
+		if _, ok := oldRequired[name]; !ok {
+			required = append(required, name)
+		}
+	}
+	if len(required) != 0 {
+		sort.Strings(required)
+		res = append(res, fmt.Sprintf("properties are required: %s", strings.Join(required, ", ")))
+	}
+
+	for _, change := range jsonschema.CompareConstraints(oldNode, curNode) {
+		if change.Narrowed {
+			res = append(res, change.Message)
+		}
+	}
+	return res
+}
+
+func stringSet(val any) map[string]struct{} {
+	res := make(map[string]struct{})
+	items, _ := val.([]any)
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			res[s] = struct{}{}
+		}
+	}
+	return res
+}
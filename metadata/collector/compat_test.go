@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_CheckCompatibility(t *testing.T) {
+	makeRegistry := func(entities ...*metadata.Entity) *MetadataRegistry {
+		r := NewMetadataRegistry()
+		for _, e := range entities {
+			require.NoError(t, r.Add("entities.raml", e))
+		}
+		return r
+	}
+
+	baseline := makeRegistry(
+		&metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{
+			"$ref": "#/definitions/Event",
+			"definitions": {"Event": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"severity": {"type": "string", "enum": ["low", "high"]},
+					"name": {"type": "string", "maxLength": 100},
+					"count": {"type": "integer"},
+					"note": {"type": "string"}
+				},
+				"required": ["id"]
+			}}
+		}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".id":       {Reference: true},
+			".severity": {Reference: "cti.a.p.severity.v1.0"},
+		}},
+		&metadata.Entity{Cti: "cti.a.p.removed.v1.0", Schema: []byte(`{}`)},
+		&metadata.Entity{Cti: "cti.a.p.final.v1.0", Final: true, Schema: []byte(`{}`)},
+		&metadata.Entity{Cti: "cti.a.p.hidden.v1.0", Tags: []string{InternalTag}, Schema: []byte(`{}`)},
+		&metadata.Entity{Cti: "cti.a.p.experimental.v1.0", Schema: []byte(`{}`)},
+	)
+	current := makeRegistry(
+		&metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{
+			"$ref": "#/definitions/Event",
+			"definitions": {"Event": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"severity": {"type": "string", "enum": ["low", "medium"]},
+					"name": {"type": "string", "maxLength": 50},
+					"count": {"type": "string"},
+					"extra": {"type": "string"}
+				},
+				"required": ["id", "name"]
+			}}
+		}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".id":   {Reference: "cti.a.p.id.v1.0"},
+			".name": {Schema: "cti.a.p.name.v1.0", Final: new(bool)},
+		}},
+	)
+
+	changes, err := CheckCompatibility(baseline, current, WithCompatExemptions("cti.a.p.experimental.*"))
+	require.NoError(t, err)
+	messages := make([]string, len(changes))
+	for i, change := range changes {
+		messages[i] = change.String()
+	}
+	require.Equal(t, []string{
+		"cti.a.p.event.v1.0: schema .: properties are required: name",
+		"cti.a.p.event.v1.0: schema .count: type is changed from integer to string",
+		"cti.a.p.event.v1.0: schema .id: annotation cti.reference is changed from true to cti.a.p.id.v1.0",
+		"cti.a.p.event.v1.0: schema .name: annotation cti.schema cti.a.p.name.v1.0 is added",
+		"cti.a.p.event.v1.0: schema .name: maxLength is lowered from 100 to 50",
+		"cti.a.p.event.v1.0: schema .note: property is removed",
+		"cti.a.p.event.v1.0: schema .severity: enum values are removed: high",
+		"cti.a.p.removed.v1.0: type is removed",
+	}, messages)
+
+	changes, err = CheckCompatibility(baseline, baseline)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = CheckCompatibility(baseline, current, WithCompatExemptions("not a cti"))
+	require.ErrorContains(t, err, "parse exemption not a cti")
+}
@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+)
+
+// CompleteCti returns up to limit CTIs of the registry starting with the prefix sorted lexicographically,
+// e.g. to complete identifiers in shell or editors. Zero limit means no limit.
+// The sorted list of CTIs is built on the first call and dropped by every method that changes the registry,
+// so subsequent lookups take logarithmic time.
+func (r *MetadataRegistry) CompleteCti(prefix string, limit int) []string {
+	r.sortedMu.Lock()
+	defer r.sortedMu.Unlock()
+
+	if r.sortedIDs == nil {
+		r.sortedIDs = make([]string, 0, len(r.Index))
+		for id := range r.Index {
+			r.sortedIDs = append(r.sortedIDs, id)
+		}
+		sort.Strings(r.sortedIDs)
+	}
+
+	var res []string
+	for i := sort.SearchStrings(r.sortedIDs, prefix); i < len(r.sortedIDs); i++ {
+		if !strings.HasPrefix(r.sortedIDs[i], prefix) || (limit > 0 && len(res) == limit) {
+			break
+		}
+		res = append(res, r.sortedIDs[i])
+	}
+	return res
+}
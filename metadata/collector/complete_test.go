@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_CompleteCti(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, id := range []string{"cti.a.p.event.v1.0", "cti.a.p.alert.v1.0", "cti.a.p.alert.v1.1", "cti.b.p.event.v1.0"} {
+		require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: id, Schema: []byte(`{"type":"object"}`)}))
+	}
+
+	require.Equal(t, []string{"cti.a.p.alert.v1.0", "cti.a.p.alert.v1.1", "cti.a.p.event.v1.0"}, r.CompleteCti("cti.a.", 0))
+	require.Equal(t, []string{"cti.a.p.alert.v1.0"}, r.CompleteCti("cti.a.p.alert", 1))
+	require.Empty(t, r.CompleteCti("cti.c.", 0))
+
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.alert.v2.0", Schema: []byte(`{"type":"object"}`)}))
+	require.Equal(t, []string{"cti.a.p.alert.v1.0", "cti.a.p.alert.v1.1", "cti.a.p.alert.v2.0"}, r.CompleteCti("cti.a.p.alert", 0))
+
+	// Restoring a snapshot of the same size drops the list as well.
+	snapshot := r.Snapshot()
+	r.unindex(r.Index["cti.b.p.event.v1.0"])
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.z.v1.0", Schema: []byte(`{"type":"object"}`)}))
+	require.Equal(t, []string{"cti.a.p.z.v1.0"}, r.CompleteCti("cti.a.p.z", 0))
+	r.Restore(snapshot)
+	require.Empty(t, r.CompleteCti("cti.a.p.z", 0))
+	require.Equal(t, []string{"cti.b.p.event.v1.0"}, r.CompleteCti("cti.b.", 0))
+}
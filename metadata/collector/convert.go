@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/acronis/go-raml"
+
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// ConvertLibrary converts CTI types declared in a single RAML library to JSON schemas keyed by CTI.
+// Unlike package parsing, it does not require a package index. The library must be able to resolve
+// the libraries it uses by itself, including the CTI specification.
+// Schemas of derived types are not merged with parent schemas, use merger for that.
+func ConvertLibrary(path string) (map[string]jsonschema.JSONSchemaCTI, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path: %w", err)
+	}
+	baseDir, fileName := filepath.Split(absPath)
+
+	// Collector expects an entry point that uses libraries with CTI entities.
+	index := fmt.Sprintf("#%%RAML 1.0 Library\nuses:\n  lib: %s", fileName)
+	r, err := raml.ParseFromString(index, "index.raml", baseDir, raml.OptWithValidate())
+	if err != nil {
+		return nil, fmt.Errorf("parse library %s: %w", path, err)
+	}
+
+	c := New()
+	c.SetRaml(r)
+	if err := c.Collect(true); err != nil {
+		return nil, fmt.Errorf("collect from library %s: %w", path, err)
+	}
+
+	schemas := make(map[string]jsonschema.JSONSchemaCTI, len(c.LocalRegistry.Types))
+	for id, entity := range c.LocalRegistry.Types {
+		schema, err := jsonschema.FromBytes(entity.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("decode schema of %s: %w", id, err)
+		}
+		schemas[id] = schema
+	}
+	return schemas, nil
+}
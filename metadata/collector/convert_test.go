@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/filesys"
+	"github.com/acronis/go-cti/metadata/ramlx"
+)
+
+func Test_ConvertLibrary(t *testing.T) {
+	baseDir := t.TempDir()
+	require.NoError(t, filesys.CopyFS(ramlx.RamlFiles, filepath.Join(baseDir, "spec"), filesys.WithRoot("spec_v1")))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "library.raml"), []byte(`#%RAML 1.0 Library
+
+uses:
+  cti: spec/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    (cti.final): false
+    properties:
+      name: string
+
+  Created:
+    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
+    type: Event
+    properties:
+      time: datetime
+`), 0600))
+
+	schemas, err := ConvertLibrary(filepath.Join(baseDir, "library.raml"))
+	require.NoError(t, err)
+	require.Len(t, schemas, 2)
+
+	schema := schemas["cti.x.y.event.v1.0~x.y.created.v1.0"]
+	require.Equal(t, "#/definitions/Created", schema["$ref"])
+	definition := schema["definitions"].(map[string]any)["Created"].(map[string]any)
+	require.Contains(t, definition["properties"], "time")
+	require.NotContains(t, definition["properties"], "name")
+
+	_, err = ConvertLibrary(filepath.Join(baseDir, "missing.raml"))
+	require.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// DependencyKind is a kind of the dependency between registry entities.
+type DependencyKind string
+
+const (
+	// DependencyInheritance is a dependency of the entity on its parent type.
+	DependencyInheritance DependencyKind = "inheritance"
+	// DependencyReference is a dependency on an entity referenced by cti.reference annotations of the schemas
+	// or by the values and traits that are annotated with cti.reference.
+	DependencyReference DependencyKind = "reference"
+	// DependencySchema is a dependency of the type on a type referenced by cti.schema annotations of its schemas.
+	DependencySchema DependencyKind = "schema"
+)
+
+// Dependency is a dependency on the registry entity with the CTI.
+type Dependency struct {
+	Cti  string
+	Kind DependencyKind
+}
+
+// GetDependencies returns CTIs of the registry entities that the entity depends on sorted by CTI.
+// Dependencies are the parent type, types referenced by cti.reference and cti.schema annotations
+// of the schema and the traits schema, entities referenced by instance values and traits.
+// Only references to the exact CTIs of the registry entities are taken into account.
+func (r *MetadataRegistry) GetDependencies(cti string) ([]string, error) {
+	deps, err := r.GetDependencyKinds(cti)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		if len(res) == 0 || res[len(res)-1] != dep.Cti {
+			res = append(res, dep.Cti)
+		}
+	}
+	return res, nil
+}
+
+// GetDependencyKinds returns dependencies of the entity (see GetDependencies) with their kinds sorted by CTI and kind.
+// The entity depends on the same entity once per kind.
+func (r *MetadataRegistry) GetDependencyKinds(cti string) ([]Dependency, error) {
+	entity, ok := r.Index[cti]
+	if !ok {
+		return nil, fmt.Errorf("failed to find cti %s", cti)
+	}
+
+	deps := make(map[Dependency]struct{})
+	add := func(id string, kind DependencyKind) {
+		if _, ok := r.Index[id]; ok && id != cti {
+			deps[Dependency{Cti: id, Kind: kind}] = struct{}{}
+		}
+	}
+
+	parentCti := metadata.GetParentCti(cti)
+	add(parentCti, DependencyInheritance)
+	for _, annotations := range []map[metadata.GJsonPath]metadata.Annotations{entity.Annotations, entity.TraitsAnnotations} {
+		for _, annotation := range annotations {
+			for _, id := range annotationCtis(annotation.Reference) {
+				add(id, DependencyReference)
+			}
+			for _, id := range annotationCtis(annotation.Schema) {
+				add(id, DependencySchema)
+			}
+		}
+	}
+	if parent, ok := r.Index[parentCti]; ok && entity.Values != nil {
+		for key, annotation := range parent.Annotations {
+			if annotation.Reference == nil {
+				continue
+			}
+			for _, val := range key.GetValue(entity.Values).Array() {
+				add(val.Str, DependencyReference)
+			}
+		}
+	}
+	refs, err := r.GetTraitReferences(cti)
+	if err != nil {
+		return nil, fmt.Errorf("get trait references: %w", err)
+	}
+	for _, ref := range refs {
+		add(ref.Cti, DependencyReference)
+	}
+
+	res := make([]Dependency, 0, len(deps))
+	for dep := range deps {
+		res = append(res, dep)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Cti != res[j].Cti {
+			return res[i].Cti < res[j].Cti
+		}
+		return res[i].Kind < res[j].Kind
+	})
+	return res, nil
+}
+
+// GetDependents returns CTIs of the registry entities that depend on the entity (see GetDependencies) sorted by CTI.
+func (r *MetadataRegistry) GetDependents(cti string) ([]string, error) {
+	var res []string
+	for id := range r.Index {
+		deps, err := r.GetDependencies(id)
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.SearchStrings(deps, cti)
+		if idx < len(deps) && deps[idx] == cti {
+			res = append(res, id)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// annotationCtis returns CTIs of the annotation value that is a string or an array of strings.
+func annotationCtis(val any) []string {
+	switch v := val.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
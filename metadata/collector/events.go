@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// RegistryEvents are callbacks that notify long-running services about incremental changes of registries,
+// e.g. to rebuild routing tables without comparing whole registries. Callbacks that are not set are skipped.
+type RegistryEvents struct {
+	// OnTypeAdded is called for a type that is added to the registry.
+	OnTypeAdded func(entity *metadata.Entity)
+	// OnTypeReplaced is called for a type that replaces the previous version of the entity with the same CTI.
+	OnTypeReplaced func(old, entity *metadata.Entity)
+	// OnInstanceAdded is called for an instance that is added to the registry.
+	OnInstanceAdded func(entity *metadata.Entity)
+	// OnInstanceReplaced is called for an instance that replaces the previous version of the entity with the same CTI.
+	OnInstanceReplaced func(old, entity *metadata.Entity)
+}
+
+func (e *RegistryEvents) added(entity *metadata.Entity) {
+	switch {
+	case entity.Values != nil && e.OnInstanceAdded != nil:
+		e.OnInstanceAdded(entity)
+	case entity.Values == nil && e.OnTypeAdded != nil:
+		e.OnTypeAdded(entity)
+	}
+}
+
+func (e *RegistryEvents) replaced(old, entity *metadata.Entity) {
+	switch {
+	case entity.Values != nil && e.OnInstanceReplaced != nil:
+		e.OnInstanceReplaced(old, entity)
+	case entity.Values == nil && e.OnTypeReplaced != nil:
+		e.OnTypeReplaced(old, entity)
+	}
+}
+
+// Subscribe makes the registry call the callbacks for entities added with Add and replaced with Replace.
+// Callbacks are called synchronously in the order of subscription. The returned function cancels the subscription.
+func (r *MetadataRegistry) Subscribe(events RegistryEvents) (unsubscribe func()) {
+	sub := &events
+	r.subscribers = append(r.subscribers, sub)
+	return func() {
+		r.subscribers = removeSubscriber(r.subscribers, sub)
+	}
+}
+
+func (r *MetadataRegistry) notifyAdded(entity *metadata.Entity) {
+	for _, sub := range r.subscribers {
+		sub.added(entity)
+	}
+}
+
+func (r *MetadataRegistry) notifyReplaced(old, entity *metadata.Entity) {
+	for _, sub := range r.subscribers {
+		sub.replaced(old, entity)
+	}
+}
+
+// Subscribe makes the holder call the callbacks after a registry is published by Swap or Update.
+// Entities of the published registry are compared with entities of the previous one, so callbacks are called
+// for added entities and for entities that differ from their previous versions in the order of CTIs.
+// Callbacks are called synchronously while the holder is locked, so they must not call Swap or Update.
+// The returned function cancels the subscription.
+func (s *SyncRegistry) Subscribe(events RegistryEvents) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub := &events
+	s.subscribers = append(s.subscribers, sub)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.subscribers = removeSubscriber(s.subscribers, sub)
+	}
+}
+
+// notify calls the subscribers for entities of the published registry that are added or changed
+// since the previous registry. The holder must be locked.
+func (s *SyncRegistry) notify(prev, cur *MetadataRegistry) {
+	if len(s.subscribers) == 0 || prev == cur {
+		return
+	}
+	ids := make([]string, 0, len(cur.Index))
+	for id := range cur.Index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		entity := cur.Index[id]
+		old, ok := prev.Index[id]
+		for _, sub := range s.subscribers {
+			switch {
+			case !ok:
+				sub.added(entity)
+			case old != entity && !reflect.DeepEqual(old, entity):
+				sub.replaced(old, entity)
+			}
+		}
+	}
+}
+
+func removeSubscriber(subs []*RegistryEvents, sub *RegistryEvents) []*RegistryEvents {
+	for i, s := range subs {
+		if s == sub {
+			return append(subs[:i:i], subs[i+1:]...)
+		}
+	}
+	return subs
+}
@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+type recordedEvents []string
+
+func (e *recordedEvents) subscription() RegistryEvents {
+	return RegistryEvents{
+		OnTypeAdded:     func(entity *metadata.Entity) { *e = append(*e, "type added "+entity.Cti) },
+		OnTypeReplaced:  func(old, entity *metadata.Entity) { *e = append(*e, "type replaced "+entity.Cti) },
+		OnInstanceAdded: func(entity *metadata.Entity) { *e = append(*e, "instance added "+entity.Cti) },
+	}
+}
+
+func Test_RegistrySubscribe(t *testing.T) {
+	r := NewMetadataRegistry()
+	var events recordedEvents
+	unsubscribe := r.Subscribe(events.subscription())
+
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{}`), Tags: []string{"old"}}))
+	require.NoError(t, r.Add("instances.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0", Values: []byte(`{}`)}))
+	require.NoError(t, r.Replace("events.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{"type":"object"}`)}))
+	// Instances are replaced silently, since there is no callback.
+	require.NoError(t, r.Replace("instances.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0", Values: []byte(`{"a":1}`)}))
+	require.Error(t, r.Replace("types.raml", &metadata.Entity{Cti: "cti.a.p.unknown.v1.0", Schema: []byte(`{}`)}))
+	require.Equal(t, recordedEvents{
+		"type added cti.a.p.event.v1.0",
+		"instance added cti.a.p.event.v1.0~a.p.created.v1.0",
+		"type replaced cti.a.p.event.v1.0",
+	}, events)
+
+	// Indexes point to the replacement.
+	require.JSONEq(t, `{"type":"object"}`, string(r.Types["cti.a.p.event.v1.0"].Schema))
+	require.NotContains(t, r.FragmentEntities, "types.raml")
+	require.Len(t, r.FragmentEntities["events.raml"], 1)
+	require.NotContains(t, r.Tags, "old")
+
+	unsubscribe()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.1", Schema: []byte(`{}`)}))
+	require.Len(t, events, 3)
+}
+
+func Test_SyncRegistrySubscribe(t *testing.T) {
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{}`)}))
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.alert.v1.0", Schema: []byte(`{}`)}))
+	s := NewSyncRegistry(r)
+	var events recordedEvents
+	unsubscribe := s.Subscribe(events.subscription())
+
+	require.NoError(t, s.Update(func(r *MetadataRegistry) error {
+		r.Index["cti.a.p.event.v1.0"].Description = "changed"
+		return r.Add("instances.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0", Values: []byte(`{}`)})
+	}))
+	require.Equal(t, recordedEvents{
+		"type replaced cti.a.p.event.v1.0",
+		"instance added cti.a.p.event.v1.0~a.p.created.v1.0",
+	}, events)
+
+	refreshed := NewMetadataRegistry()
+	require.NoError(t, refreshed.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.alert.v1.0", Schema: []byte(`{}`)}))
+	require.NoError(t, refreshed.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.alert.v1.1", Schema: []byte(`{}`)}))
+	events = nil
+	s.Swap(refreshed)
+	// Entities that are equal to their previous versions are not reported.
+	require.Equal(t, recordedEvents{"type added cti.a.p.alert.v1.1"}, events)
+
+	unsubscribe()
+	s.Swap(r)
+	require.Len(t, events, 1)
+}
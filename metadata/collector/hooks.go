@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/acronis/go-raml"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// ConversionHook customizes JSON schemas of CTI types converted from RAML, e.g. to attach vendor x- keywords
+// like database column hints. Hooks receive schemas as decoded JSON and may modify them in place,
+// including replacing or removing keywords. Returned errors fail the collection.
+type ConversionHook interface {
+	// OnType is called for the schema of the CTI type after all its properties are handled.
+	OnType(cti string, shape *raml.BaseShape, schema jsonschema.JSONSchemaCTI) error
+	// OnProperty is called for the schema of each property of object types, including nested ones.
+	// Properties are handled after their own properties, so nested schemas are already customized.
+	OnProperty(cti string, prop ConversionProperty, schema jsonschema.JSONSchemaCTI) error
+}
+
+// ConversionProperty describes the RAML property which schema is passed to ConversionHook.OnProperty.
+type ConversionProperty struct {
+	// Path is the path of the property in values of the type, e.g. .owner.name or .items.#.id.
+	Path     metadata.GJsonPath
+	Name     string
+	Required bool
+	Shape    *raml.BaseShape
+}
+
+// ConversionHookFuncs implements ConversionHook with optional functions, so embedders may handle only
+// types or only properties.
+type ConversionHookFuncs struct {
+	Type     func(cti string, shape *raml.BaseShape, schema jsonschema.JSONSchemaCTI) error
+	Property func(cti string, prop ConversionProperty, schema jsonschema.JSONSchemaCTI) error
+}
+
+func (h ConversionHookFuncs) OnType(cti string, shape *raml.BaseShape, schema jsonschema.JSONSchemaCTI) error {
+	if h.Type == nil {
+		return nil
+	}
+	return h.Type(cti, shape, schema)
+}
+
+func (h ConversionHookFuncs) OnProperty(cti string, prop ConversionProperty, schema jsonschema.JSONSchemaCTI) error {
+	if h.Property == nil {
+		return nil
+	}
+	return h.Property(cti, prop, schema)
+}
+
+// AddConversionHook makes the collector call the hook for schemas of CTI types collected afterwards.
+// Hooks are called in the order they are added.
+func (c *Collector) AddConversionHook(h ConversionHook) {
+	c.conversionHooks = append(c.conversionHooks, h)
+}
+
+// applyConversionHooks returns the schema of the CTI type customized by the conversion hooks.
+// The schema is returned as is if there are no hooks, otherwise it is re-encoded, so keywords are sorted.
+func (c *Collector) applyConversionHooks(id string, shape *raml.BaseShape, schemaBytes []byte) ([]byte, error) {
+	if len(c.conversionHooks) == 0 {
+		return schemaBytes, nil
+	}
+	schema, err := jsonschema.FromBytes(schemaBytes)
+	if err != nil {
+		return nil, err
+	}
+	// Converted schema references the definition of the entry point shape.
+	definitions, _ := schema["definitions"].(map[string]any)
+	definition, ok := definitions[shape.Name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("definition %s is not found", shape.Name)
+	}
+	for _, h := range c.conversionHooks {
+		if err := walkConversionHook(h, id, ".", shape.Shape, definition); err != nil {
+			return nil, err
+		}
+		if err := h.OnType(id, shape, definition); err != nil {
+			return nil, fmt.Errorf("hook for type %s: %w", id, err)
+		}
+	}
+	return json.Marshal(schema)
+}
+
+// walkConversionHook calls the hook for properties of the shape which schema is at the path.
+// Schemas of recursive shapes are references to definitions, so they are not descended into.
+func walkConversionHook(h ConversionHook, id string, path string, s raml.Shape, schema map[string]any) error {
+	switch s := s.(type) {
+	case *raml.ObjectShape:
+		if s.Properties == nil {
+			return nil
+		}
+		props, _ := schema["properties"].(map[string]any)
+		prefix := path
+		if prefix != "." {
+			prefix += "."
+		}
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			propSchema, ok := props[pair.Key].(map[string]any)
+			if !ok {
+				continue
+			}
+			propPath := prefix + prop.Name
+			if err := walkConversionHook(h, id, propPath, prop.Base.Shape, propSchema); err != nil {
+				return err
+			}
+			p := ConversionProperty{
+				Path:     metadata.GJsonPath(propPath),
+				Name:     prop.Name,
+				Required: prop.Required,
+				Shape:    prop.Base,
+			}
+			if err := h.OnProperty(id, p, propSchema); err != nil {
+				return fmt.Errorf("hook for property %s of %s: %w", propPath, id, err)
+			}
+		}
+	case *raml.ArrayShape:
+		items, ok := schema["items"].(map[string]any)
+		if !ok || s.Items == nil {
+			return nil
+		}
+		itemsPath := path + ".#"
+		if path == "." {
+			itemsPath = ".#"
+		}
+		return walkConversionHook(h, id, itemsPath, s.Items.Shape, items)
+	case *raml.UnionShape:
+		anyOf, _ := schema["anyOf"].([]any)
+		for i, member := range s.AnyOf {
+			if i >= len(anyOf) {
+				break
+			}
+			memberSchema, ok := anyOf[i].(map[string]any)
+			if !ok {
+				continue
+			}
+			if err := walkConversionHook(h, id, path, member.Shape, memberSchema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
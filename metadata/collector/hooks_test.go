@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/acronis/go-raml"
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/filesys"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+	"github.com/acronis/go-cti/metadata/ramlx"
+)
+
+func collectWithHooks(t *testing.T, hooks ...ConversionHook) (*Collector, error) {
+	baseDir := t.TempDir()
+	require.NoError(t, filesys.CopyFS(ramlx.RamlFiles, filepath.Join(baseDir, "spec"), filesys.WithRoot("spec_v1")))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "library.raml"), []byte(`#%RAML 1.0 Library
+
+uses:
+  cti: spec/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    properties:
+      name: string
+      owner:
+        type: object
+        properties:
+          id: integer
+      tags:
+        type: array
+        items:
+          type: object
+          properties:
+            value?: string
+`), 0600))
+	r, err := raml.ParseFromString("#%RAML 1.0 Library\nuses:\n  lib: library.raml", "index.raml", baseDir, raml.OptWithValidate())
+	require.NoError(t, err)
+
+	c := New()
+	for _, h := range hooks {
+		c.AddConversionHook(h)
+	}
+	c.SetRaml(r)
+	return c, c.Collect(true)
+}
+
+func Test_ConversionHooks(t *testing.T) {
+	var paths []metadata.GJsonPath
+	c, err := collectWithHooks(t, ConversionHookFuncs{
+		Property: func(cti string, prop ConversionProperty, schema jsonschema.JSONSchemaCTI) error {
+			paths = append(paths, prop.Path)
+			if schema["type"] == "integer" {
+				schema["x-db-column"] = map[string]any{"type": "bigint", "required": prop.Required}
+			}
+			return nil
+		},
+	}, ConversionHookFuncs{
+		Type: func(cti string, shape *raml.BaseShape, schema jsonschema.JSONSchemaCTI) error {
+			schema["x-db-table"] = shape.Name
+			delete(schema, "additionalProperties")
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	// Nested properties are handled before the properties that contain them.
+	require.Equal(t, []metadata.GJsonPath{".name", ".owner.id", ".owner", ".tags.#.value", ".tags"}, paths)
+
+	entity := c.LocalRegistry.Types["cti.x.y.event.v1.0"]
+	require.NotNil(t, entity)
+	schema, err := jsonschema.FromBytes(entity.Schema)
+	require.NoError(t, err)
+	definition := schema["definitions"].(map[string]any)["Event"].(map[string]any)
+	require.Equal(t, "Event", definition["x-db-table"])
+	require.NotContains(t, definition, "additionalProperties")
+	owner := definition["properties"].(map[string]any)["owner"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "bigint", "required": true}, owner["properties"].(map[string]any)["id"].(map[string]any)["x-db-column"])
+
+	_, err = collectWithHooks(t, ConversionHookFuncs{
+		Property: func(cti string, prop ConversionProperty, schema jsonschema.JSONSchemaCTI) error {
+			if prop.Name == "owner" {
+				return errors.New("unsupported")
+			}
+			return nil
+		},
+	})
+	require.ErrorContains(t, err, "hook for property .owner of cti.x.y.event.v1.0: unsupported")
+}
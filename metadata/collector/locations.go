@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-raml"
+	"github.com/acronis/go-stacktrace"
+)
+
+// LocationsCollector collects source locations of shapes and their annotations keyed by the same paths as annotations.
+type LocationsCollector struct {
+	baseDir     string
+	ranges      *sourceRanges
+	locations   map[metadata.GJsonPath]metadata.SourceLocation
+	annotations map[metadata.GJsonPath]map[string]metadata.SourceLocation
+}
+
+func NewLocationsCollector(baseDir string) *LocationsCollector {
+	return newLocationsCollector(baseDir, newSourceRanges())
+}
+
+func newLocationsCollector(baseDir string, ranges *sourceRanges) *LocationsCollector {
+	return &LocationsCollector{baseDir: baseDir, ranges: ranges}
+}
+
+func (c *LocationsCollector) Collect(s raml.Shape) map[metadata.GJsonPath]metadata.SourceLocation {
+	c.locations = make(map[metadata.GJsonPath]metadata.SourceLocation)
+	c.annotations = make(map[metadata.GJsonPath]map[string]metadata.SourceLocation)
+	c.Visit(".", s)
+	return c.locations
+}
+
+// AnnotationLocations returns locations of the annotations collected by the last Collect
+// keyed by paths of the shapes and names of the annotations. Only annotations that are collected
+// into metadata.Annotations are located.
+func (c *LocationsCollector) AnnotationLocations() map[metadata.GJsonPath]map[string]metadata.SourceLocation {
+	if len(c.annotations) == 0 {
+		return nil
+	}
+	return c.annotations
+}
+
+func (c *LocationsCollector) Visit(ctx string, s raml.Shape) {
+	base := s.Base()
+	key := metadata.GJsonPath(ctx)
+	if _, ok := c.locations[key]; ok {
+		// Keep the first member of the union.
+		return
+	}
+	if base.Location != "" {
+		c.locations[key] = c.location(base.Location, base.Position, false)
+	}
+	for pair := base.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
+		annotation := pair.Value
+		if annotation.Location == "" ||
+			!strings.HasPrefix(annotation.Name, MetadataPrefix) && !metadata.HasAnnotationNamespace(annotation.Name) {
+			continue
+		}
+		if c.annotations[key] == nil {
+			c.annotations[key] = make(map[string]metadata.SourceLocation)
+		}
+		c.annotations[key][annotation.Name] = c.location(annotation.Location, annotation.Position, true)
+	}
+
+	switch s := s.(type) {
+	case *raml.ObjectShape:
+		c.VisitObjectShape(ctx, s)
+	case *raml.ArrayShape:
+		c.VisitArrayShape(ctx, s)
+	case *raml.UnionShape:
+		c.VisitUnionShape(ctx, s)
+	}
+}
+
+// location returns the location of the node at the position in the file.
+// Annotations are located by their keys and span their values.
+func (c *LocationsCollector) location(file string, pos stacktrace.Position, pair bool) metadata.SourceLocation {
+	path, err := filepath.Rel(c.baseDir, file)
+	if err != nil {
+		path = file
+	}
+	res := metadata.SourceLocation{
+		Path:   filepath.ToSlash(path),
+		Line:   pos.Line,
+		Column: pos.Column,
+	}
+	resolve := c.ranges.node
+	if pair {
+		resolve = c.ranges.pair
+	}
+	if f, s, ok := resolve(file, pos.Line, pos.Column); ok {
+		res.Offset, res.EndOffset = s.start, s.end
+		res.EndLine, res.EndColumn = f.position(s.end)
+	}
+	return res
+}
+
+// instanceLocation returns the location of the item of the annotation that defines an instance or nil
+// if the item cannot be located, e.g. if the values are included from another file.
+func (c *Collector) instanceLocation(annotation *raml.DomainExtension, index int, path string) *metadata.SourceLocation {
+	if annotation.Extension.Location != annotation.Location {
+		return nil
+	}
+	f, s, ok := c.sourceRanges.item(annotation.Location, annotation.Line, annotation.Column, index)
+	if !ok {
+		return nil
+	}
+	res := f.location(s)
+	res.Path = path
+	return &res
+}
+
+func (c *LocationsCollector) VisitObjectShape(ctx string, s *raml.ObjectShape) any {
+	if ctx != "." {
+		ctx += "."
+	}
+
+	if s.Properties != nil {
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			v := pair.Value
+			c.Visit(ctx+v.Name, v.Base.Shape)
+		}
+	}
+	if s.PatternProperties != nil {
+		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			k, v := pair.Key, pair.Value
+			c.Visit(ctx+k, v.Base.Shape)
+		}
+	}
+	return nil
+}
+
+func (c *LocationsCollector) VisitArrayShape(ctx string, s *raml.ArrayShape) any {
+	if ctx == "." {
+		ctx += "#"
+	} else {
+		ctx += ".#"
+	}
+
+	if s.Items != nil {
+		c.Visit(ctx, s.Items.Shape)
+	}
+	return nil
+}
+
+func (c *LocationsCollector) VisitUnionShape(ctx string, s *raml.UnionShape) any {
+	// Members share the path of the union, so the location of the union itself is kept.
+	for _, item := range s.AnyOf {
+		c.visitMembers(ctx, item.Shape)
+	}
+	return nil
+}
+
+func (c *LocationsCollector) visitMembers(ctx string, s raml.Shape) {
+	switch s := s.(type) {
+	case *raml.ObjectShape:
+		c.VisitObjectShape(ctx, s)
+	case *raml.ArrayShape:
+		c.VisitArrayShape(ctx, s)
+	}
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+)
+
+// InheritedAnnotations returns annotations of the type that are effective for its instances.
+// Annotations are merged along the inheritance chain from the root type down to the type,
+// so the annotations of descendants take precedence (see metadata.Annotations.Merge).
+func (r *MetadataRegistry) InheritedAnnotations(typeCti string) map[metadata.GJsonPath]metadata.Annotations {
+	var chain []*metadata.Entity
+	for id := typeCti; ; {
+		entity, ok := r.Index[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, entity)
+		parentCti := metadata.GetParentCti(id)
+		if parentCti == id {
+			break
+		}
+		id = parentCti
+	}
+	res := make(map[metadata.GJsonPath]metadata.Annotations)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for key, annotation := range chain[i].Annotations {
+			res[key] = res[key].Merge(annotation)
+		}
+	}
+	return res
+}
+
+// MaskSensitive returns a copy of the instance where values of properties annotated with cti.sensitive
+// in the parent type or its ancestors are replaced with metadata.MaskedValue (see metadata.MaskSensitive).
+func (r *MetadataRegistry) MaskSensitive(instance *cti.EntityInstance) (*cti.EntityInstance, error) {
+	raw := []byte(instance.RawValues)
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(instance.Values); err != nil {
+			return nil, fmt.Errorf("marshal values of %s: %w", instance.Cti, err)
+		}
+	}
+	masked, err := metadata.MaskSensitive(raw, r.InheritedAnnotations(metadata.GetParentCti(instance.Cti)))
+	if err != nil {
+		return nil, fmt.Errorf("mask values of %s: %w", instance.Cti, err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(masked, &values); err != nil {
+		return nil, fmt.Errorf("unmarshal values of %s: %w", instance.Cti, err)
+	}
+	return &cti.EntityInstance{Cti: instance.Cti, Values: values, RawValues: masked}, nil
+}
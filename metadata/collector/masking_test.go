@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InheritedAnnotations(t *testing.T) {
+	yes := true
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.event.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".topic": {Reference: true, Overridable: &yes},
+		}},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0~x.y.user.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".topic": {Reference: "cti.x.y.topic.v1.0"},
+		}},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	annotations := r.InheritedAnnotations("cti.x.y.event.v1.0~x.y.created.v1.0~x.y.user.v1.0")
+	require.True(t, annotations[".topic"].Equal(metadata.Annotations{Reference: "cti.x.y.topic.v1.0", Overridable: &yes}))
+
+	annotations = r.InheritedAnnotations("cti.x.y.event.v1.0~x.y.created.v1.0")
+	require.True(t, annotations[".topic"].Equal(metadata.Annotations{Reference: true, Overridable: &yes}))
+	require.NotContains(t, annotations, metadata.GJsonPath(".other"))
+}
+
+func Test_RegistryMaskSensitive(t *testing.T) {
+	yes, no := true, false
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:    "cti.a.p.credential.v1.0",
+		Schema: []byte(`{"type":"object"}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".password": {Sensitive: &yes},
+			".login":    {Sensitive: &yes},
+		},
+	}))
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:    "cti.a.p.credential.v1.0~a.p.ssh.v1.0",
+		Schema: []byte(`{"type":"object"}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".login": {Sensitive: &no},
+			".key":   {Sensitive: &yes},
+		},
+	}))
+
+	annotations := r.InheritedAnnotations("cti.a.p.credential.v1.0~a.p.ssh.v1.0")
+	require.Equal(t, map[metadata.GJsonPath]metadata.Annotations{
+		".password": {Sensitive: &yes},
+		".login":    {Sensitive: &no},
+		".key":      {Sensitive: &yes},
+	}, annotations)
+
+	instance := &cti.EntityInstance{
+		Cti:    "cti.a.p.credential.v1.0~a.p.ssh.v1.0~a.p.backup.v1.0",
+		Values: map[string]any{"login": "admin", "password": "secret", "key": "ssh-rsa"},
+	}
+	masked, err := r.MaskSensitive(instance)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"login": "admin", "password": "******", "key": "******"}, masked.Values)
+	require.JSONEq(t, `{"login": "admin", "password": "******", "key": "******"}`, string(masked.RawValues))
+	require.Equal(t, "secret", instance.Values["password"])
+}
@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti"
+)
+
+// ConflictPolicy defines how MergeWith resolves entities with the same CTI in both registries.
+type ConflictPolicy string
+
+const (
+	// ConflictFailOnDuplicate fails the merge if any entity is defined in both registries.
+	ConflictFailOnDuplicate ConflictPolicy = "fail-on-duplicate"
+	// ConflictPreferLocal keeps entities of the registry being merged into.
+	ConflictPreferLocal ConflictPolicy = "prefer-local"
+	// ConflictPreferNewerVersion keeps the entity of the registry that defines the newer minor version
+	// of the entity, i.e. the registry that was collected from the newer release of the package.
+	// If both registries define the same minor versions, the local entity is kept.
+	ConflictPreferNewerVersion ConflictPolicy = "prefer-newer-version"
+)
+
+// ConflictPolicies returns the supported conflict policies.
+func ConflictPolicies() []ConflictPolicy {
+	return []ConflictPolicy{ConflictFailOnDuplicate, ConflictPreferLocal, ConflictPreferNewerVersion}
+}
+
+// MergeWith adds entities of the other registry to the registry.
+// Entities with the same CTI in both registries are resolved according to the policy.
+// Entities replaced according to the policy are reported to subscribers as replaced (see Subscribe).
+// With ConflictFailOnDuplicate, the registry is not modified if the merge fails.
+func (r *MetadataRegistry) MergeWith(other *MetadataRegistry, policy ConflictPolicy) error {
+	switch policy {
+	case ConflictFailOnDuplicate, ConflictPreferLocal, ConflictPreferNewerVersion:
+	default:
+		return fmt.Errorf("unknown conflict policy %q", policy)
+	}
+
+	ids := make([]string, 0, len(other.Index))
+	var duplicates []string
+	for id := range other.Index {
+		ids = append(ids, id)
+		if _, ok := r.Index[id]; ok {
+			duplicates = append(duplicates, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(duplicates) != 0 && policy == ConflictFailOnDuplicate {
+		sort.Strings(duplicates)
+		return fmt.Errorf("duplicate cti entities: %s", strings.Join(duplicates, ", "))
+	}
+
+	var localMinors, otherMinors map[string]uint
+	if policy == ConflictPreferNewerVersion && len(duplicates) != 0 {
+		localMinors, otherMinors = r.latestMinorVersions(), other.latestMinorVersions()
+	}
+
+	r.sortedIDs = nil
+	for _, id := range ids {
+		entity := other.Index[id]
+		if _, ok := r.Index[id]; ok {
+			if policy != ConflictPreferNewerVersion {
+				continue
+			}
+			family, _, ok := splitMinorVersion(id)
+			if !ok || otherMinors[family] <= localMinors[family] {
+				continue
+			}
+			if err := r.Replace(entity.SourceMap.OriginalPath, entity); err != nil {
+				return fmt.Errorf("replace cti entity: %w", err)
+			}
+			continue
+		}
+		if err := r.Add(entity.SourceMap.OriginalPath, entity); err != nil {
+			return fmt.Errorf("add cti entity: %w", err)
+		}
+	}
+	return nil
+}
+
+// latestMinorVersions returns the latest minor version of every entity family in the registry.
+func (r *MetadataRegistry) latestMinorVersions() map[string]uint {
+	res := make(map[string]uint)
+	for id := range r.Index {
+		family, minor, ok := splitMinorVersion(id)
+		if !ok {
+			continue
+		}
+		if latest, ok := res[family]; !ok || minor > latest {
+			res[family] = minor
+		}
+	}
+	return res
+}
+
+// splitMinorVersion splits CTI into the part without minor version of the last node and its minor version.
+func splitMinorVersion(id string) (string, uint, bool) {
+	expr, err := cti.ParseIdentifier(id)
+	if err != nil {
+		return "", 0, false
+	}
+	tail := expr.Tail()
+	if tail == nil || !tail.Version.Minor.Valid {
+		return "", 0, false
+	}
+	idx := strings.LastIndex(id, ".")
+	if idx == -1 {
+		return "", 0, false
+	}
+	return id[:idx], tail.Version.Minor.Value, true
+}
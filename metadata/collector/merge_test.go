@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegistryMergeWith(t *testing.T) {
+	newRegistry := func(t *testing.T, path string, ids ...string) *MetadataRegistry {
+		r := NewMetadataRegistry()
+		for _, id := range ids {
+			require.NoError(t, r.Add(path, &metadata.Entity{
+				Cti:    id,
+				Schema: []byte(`{"type":"object"}`),
+				Tags:   []string{path},
+				SourceMap: metadata.SourceMap{
+					OriginalPath: path,
+				},
+			}))
+		}
+		return r
+	}
+
+	t.Run("fail on duplicate", func(t *testing.T) {
+		local := newRegistry(t, "local.raml", "cti.a.p.event.v1.0", "cti.a.p.topic.v1.0")
+		other := newRegistry(t, "other.raml", "cti.a.p.topic.v1.0", "cti.b.p.event.v1.0")
+
+		err := local.MergeWith(other, ConflictFailOnDuplicate)
+		require.EqualError(t, err, "duplicate cti entities: cti.a.p.topic.v1.0")
+		require.Len(t, local.Index, 2)
+
+		require.NoError(t, local.MergeWith(newRegistry(t, "other.raml", "cti.b.p.event.v1.0"), ConflictFailOnDuplicate))
+		require.Len(t, local.Index, 3)
+		require.Len(t, local.Types, 3)
+	})
+
+	t.Run("prefer local", func(t *testing.T) {
+		local := newRegistry(t, "local.raml", "cti.a.p.topic.v1.0")
+		other := newRegistry(t, "other.raml", "cti.a.p.topic.v1.0", "cti.b.p.event.v1.0")
+
+		require.NoError(t, local.MergeWith(other, ConflictPreferLocal))
+		require.Len(t, local.Index, 2)
+		require.Equal(t, "local.raml", local.Index["cti.a.p.topic.v1.0"].Tags[0])
+		require.Len(t, local.FindByTag("other.raml"), 1)
+	})
+
+	t.Run("prefer newer version", func(t *testing.T) {
+		local := newRegistry(t, "local.raml", "cti.a.p.topic.v1.0", "cti.a.p.event.v1.0", "cti.a.p.event.v1.1")
+		other := newRegistry(t, "other.raml", "cti.a.p.topic.v1.0", "cti.a.p.topic.v1.1", "cti.a.p.event.v1.0")
+
+		require.NoError(t, local.MergeWith(other, ConflictPreferNewerVersion))
+		require.Len(t, local.Index, 4)
+		require.Equal(t, "other.raml", local.Index["cti.a.p.topic.v1.0"].Tags[0])
+		require.Equal(t, "local.raml", local.Index["cti.a.p.event.v1.0"].Tags[0])
+		require.Len(t, local.FragmentEntities["local.raml"], 2)
+		require.Len(t, local.FragmentEntities["other.raml"], 2)
+		require.Len(t, local.FindByTag("local.raml"), 2)
+	})
+
+	t.Run("prefer newer version replaces entities", func(t *testing.T) {
+		local := newRegistry(t, "local.raml", "cti.a.p.topic.v1.0")
+		require.NoError(t, local.Add("local.raml", &metadata.Entity{
+			Cti:    "cti.a.p.event.v1.0",
+			Schema: []byte(`{"type":"object"}`),
+			Owners: []string{"local"},
+			SourceMap: metadata.SourceMap{
+				OriginalPath: "local.raml",
+			},
+		}))
+		other := newRegistry(t, "other.raml", "cti.a.p.event.v1.0", "cti.a.p.event.v1.1")
+
+		var events recordedEvents
+		local.Subscribe(events.subscription())
+		require.NoError(t, local.MergeWith(other, ConflictPreferNewerVersion))
+		require.Equal(t, recordedEvents{
+			"type replaced cti.a.p.event.v1.0",
+			"type added cti.a.p.event.v1.1",
+		}, events)
+
+		require.Same(t, other.Index["cti.a.p.event.v1.0"], local.Types["cti.a.p.event.v1.0"])
+		require.Empty(t, local.Owners)
+		require.Len(t, local.Tags, 2)
+		require.Len(t, local.Tags["local.raml"], 1)
+		require.Len(t, local.Tags["other.raml"], 2)
+		require.Len(t, local.FragmentEntities["local.raml"], 1)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		err := NewMetadataRegistry().MergeWith(NewMetadataRegistry(), "unknown")
+		require.EqualError(t, err, `unknown conflict policy "unknown"`)
+	})
+}
@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+)
+
+// QueryResult is a registry entity that matches the query.
+type QueryResult struct {
+	Entity *metadata.Entity
+	// Value is a value of the attribute selected by the query. It is nil if the query has no attribute selector.
+	Value any
+}
+
+// Query returns registry entities that match the query sorted by CTI.
+// The query is a CTI expression that may have wildcards and partial versions (see cti.Parser.ParseReference)
+// followed by the query attributes and the attribute selector (see cti.Parser.ParseQueryAndSelector),
+// e.g. cti.a.p.topic.v1.0~a.p.*[status="active"]@name.
+// Query attributes and the attribute selector are applied to values of instances and traits of types.
+// Entities that do not have the selected attribute are not returned.
+func (r *MetadataRegistry) Query(query string) ([]QueryResult, error) {
+	pattern, suffix := query, ""
+	if idx := strings.IndexAny(query, "[@"); idx != -1 {
+		pattern, suffix = query[:idx], query[idx:]
+	}
+	p := cti.NewParser()
+	patternExpr, err := p.ParseReference(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", pattern, err)
+	}
+	attrs, selector, err := p.ParseQueryAndSelector(suffix)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", suffix, err)
+	}
+	queryExpr := cti.Expression{QueryAttributes: attrs, AttributeSelector: selector}
+
+	ids := make([]string, 0, len(r.Index))
+	for id := range r.Index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var res []QueryResult
+	for _, id := range ids {
+		expr, err := p.ParseIdentifier(id)
+		if err != nil {
+			continue
+		}
+		ok, err := patternExpr.Match(expr)
+		if err != nil {
+			return nil, fmt.Errorf("match %s: %w", id, err)
+		}
+		if !ok {
+			continue
+		}
+		result := QueryResult{Entity: r.Index[id]}
+		if len(attrs) == 0 && selector == "" {
+			res = append(res, result)
+			continue
+		}
+
+		values, err := queryValues(result.Entity)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := queryExpr.MatchAttributes(values); err != nil {
+			return nil, fmt.Errorf("match %s: %w", id, err)
+		} else if !ok {
+			continue
+		}
+		if selector != "" {
+			if result.Value, ok = queryExpr.LookupAttribute(values); !ok {
+				continue
+			}
+		}
+		res = append(res, result)
+	}
+	return res, nil
+}
+
+func queryValues(entity *metadata.Entity) (map[string]any, error) {
+	data := entity.Values
+	if data == nil {
+		data = entity.Traits
+	}
+	if data == nil {
+		return nil, nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("unmarshal values of %s: %w", entity.Cti, err)
+	}
+	return values, nil
+}
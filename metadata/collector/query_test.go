@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_Query(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.a.p.topic.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.topic.v1.0~a.p.users.v1.0", Values: []byte(`{"name": "Users", "status": "active"}`)},
+		{Cti: "cti.a.p.topic.v1.0~a.p.groups.v1.0", Values: []byte(`{"name": "Groups", "status": "retired"}`)},
+		{Cti: "cti.a.p.topic.v1.0~b.q.tenants.v1.0", Values: []byte(`{"status": "active"}`)},
+		{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"topic": {"name": "Events"}}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	ctis := func(res []QueryResult) []string {
+		var ids []string
+		for _, item := range res {
+			ids = append(ids, item.Entity.Cti)
+		}
+		return ids
+	}
+
+	res, err := r.Query("cti.a.p.topic.v1~a.p.*")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cti.a.p.topic.v1.0~a.p.groups.v1.0", "cti.a.p.topic.v1.0~a.p.users.v1.0"}, ctis(res))
+
+	res, err = r.Query(`cti.a.p.topic.v1.0~*[status="active"]`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cti.a.p.topic.v1.0~a.p.users.v1.0", "cti.a.p.topic.v1.0~b.q.tenants.v1.0"}, ctis(res))
+
+	res, err = r.Query(`cti.a.p.topic.v1.0~*[status="active"]@name`)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "cti.a.p.topic.v1.0~a.p.users.v1.0", res[0].Entity.Cti)
+	require.Equal(t, "Users", res[0].Value)
+
+	res, err = r.Query("cti.a.p.*@topic.name")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, "Events", res[0].Value)
+
+	_, err = r.Query("cti.a.p.topic.v1.0~*@")
+	require.Error(t, err)
+}
@@ -0,0 +1,370 @@
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/ramlx"
+)
+
+// position is a one-based line and column of the first character of a YAML node as reported by the RAML parser.
+type position struct {
+	line, column int
+}
+
+// span is a range of byte offsets of a YAML node in the source file. The end is exclusive.
+type span struct {
+	start, end int
+}
+
+// sourceRanges resolves ranges of YAML nodes of RAML source files by positions of their first characters.
+// Source files are read and parsed once, so a single instance is meant to be shared by all entities of a package.
+// Contents of the files are decoded with the fragment cache, so identical files of several packages are decoded once.
+type sourceRanges struct {
+	files     map[string]*SourceFile
+	fragments *ramlx.FragmentCache
+}
+
+// SourceFile is a parsed RAML source file that locates its YAML nodes by byte ranges. It is the single locator
+// of source text: source maps of entities, rewrites of CTIs and fixes of issues are built with it.
+type SourceFile struct {
+	content     []byte
+	lineOffsets []int
+	root        *yaml.Node
+	// spans are ranges of all nodes of the file.
+	spans map[*yaml.Node]span
+	// nodes are the outermost nodes starting at the position.
+	nodes map[position]*yaml.Node
+	// pairs are ranges of key-value pairs of mappings keyed by positions of their keys.
+	pairs map[position]span
+	// values are values of key-value pairs of mappings keyed by positions of their keys.
+	values map[position]*yaml.Node
+}
+
+func newSourceRanges() *sourceRanges {
+	return &sourceRanges{files: make(map[string]*SourceFile), fragments: ramlx.NewFragmentCache()}
+}
+
+// node returns the range of the outermost YAML node starting at the position, e.g. of a shape.
+func (r *sourceRanges) node(path string, line, column int) (*SourceFile, span, bool) {
+	f := r.file(path)
+	if f == nil {
+		return nil, span{}, false
+	}
+	n, ok := f.nodes[position{line: line, column: column}]
+	return f, f.spans[n], ok
+}
+
+// pair returns the range of the key-value pair which key starts at the position, e.g. of an annotation.
+func (r *sourceRanges) pair(path string, line, column int) (*SourceFile, span, bool) {
+	f := r.file(path)
+	if f == nil {
+		return nil, span{}, false
+	}
+	s, ok := f.pairs[position{line: line, column: column}]
+	return f, s, ok
+}
+
+// item returns the range of the item of the sequence that is the value of the key-value pair which key starts
+// at the position, e.g. of the instance defined by an annotation.
+func (r *sourceRanges) item(path string, line, column, index int) (*SourceFile, span, bool) {
+	f := r.file(path)
+	if f == nil {
+		return nil, span{}, false
+	}
+	n, ok := f.values[position{line: line, column: column}]
+	if !ok || n.Kind != yaml.SequenceNode || index >= len(n.Content) {
+		return nil, span{}, false
+	}
+	return f, f.spans[n.Content[index]], true
+}
+
+// file returns the parsed source file or nil if it cannot be read or parsed.
+func (r *sourceRanges) file(path string) *SourceFile {
+	if f, ok := r.files[path]; ok {
+		return f
+	}
+	var f *SourceFile
+	if content, root, err := r.fragments.ReadFile(path); err == nil {
+		f = newSourceFile(content, root)
+	}
+	r.files[path] = f
+	return f
+}
+
+// ParseSourceFile parses the content of the RAML source file.
+func ParseSourceFile(content []byte) (*SourceFile, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+	return newSourceFile(content, &root), nil
+}
+
+// newSourceFile makes the source file of the content and its decoded YAML document.
+// The document is not modified, so it may be shared, e.g. by ramlx.FragmentCache.
+func newSourceFile(content []byte, root *yaml.Node) *SourceFile {
+	f := &SourceFile{
+		content:     content,
+		lineOffsets: []int{0},
+		root:        root,
+		spans:       make(map[*yaml.Node]span),
+		nodes:       make(map[position]*yaml.Node),
+		pairs:       make(map[position]span),
+		values:      make(map[position]*yaml.Node),
+	}
+	for i, c := range content {
+		if c == '\n' {
+			f.lineOffsets = append(f.lineOffsets, i+1)
+		}
+	}
+
+	// Nodes are listed in the document order, so a node ends before the first node that follows its subtree.
+	var order []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind != yaml.DocumentNode {
+			order = append(order, n)
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(f.root)
+	index := make(map[*yaml.Node]int, len(order))
+	for i, n := range order {
+		index[n] = i
+	}
+	// lastDescendant returns the last node of the subtree and the number of flow collections enclosing it.
+	lastDescendant := func(n *yaml.Node) (*yaml.Node, int) {
+		flows := 0
+		for len(n.Content) != 0 {
+			if n.Style&yaml.FlowStyle != 0 {
+				flows++
+			}
+			n = n.Content[len(n.Content)-1]
+		}
+		return n, flows
+	}
+	spanOf := func(first, last *yaml.Node) span {
+		start := f.offset(first.Line, first.Column)
+		last, flows := lastDescendant(last)
+		limit := len(content)
+		if i := index[last] + 1; i < len(order) {
+			limit = f.offset(order[i].Line, order[i].Column)
+		}
+		return span{start: start, end: f.trimEnd(last, flows, start, limit)}
+	}
+
+	for _, n := range order {
+		f.spans[n] = spanOf(n, n)
+		pos := position{line: n.Line, column: n.Column}
+		if _, ok := f.nodes[pos]; !ok {
+			f.nodes[pos] = n
+		}
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i]
+				f.pairs[position{line: key.Line, column: key.Column}] = spanOf(key, n.Content[i+1])
+				f.values[position{line: key.Line, column: key.Column}] = n.Content[i+1]
+			}
+		}
+	}
+	return f
+}
+
+// Content returns the content of the file.
+func (f *SourceFile) Content() []byte {
+	return f.content
+}
+
+// SourceScalar is a scalar node of a source file.
+type SourceScalar struct {
+	Value string
+	// Location is the location of the source text of the value. Quotes of quoted scalars are excluded.
+	Location metadata.SourceLocation
+}
+
+// Scalars returns scalar nodes of the file in the document order, both keys and values of mappings.
+// Scalars which source text differs from the value, e.g. multi-line, block or escaped ones, are skipped,
+// so the located text may be replaced without changing the rest of the value.
+func (f *SourceFile) Scalars() []SourceScalar {
+	var res []SourceScalar
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode {
+			if s, ok := f.scalarText(n); ok {
+				res = append(res, SourceScalar{Value: n.Value, Location: f.location(s)})
+			}
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(f.root)
+	return res
+}
+
+// ValueLocation returns the location of the value node at the path under the node at the location,
+// e.g. of a property of the instance which values are located by Entity.ValuesSourceLocation.
+// The location of a scalar is the location of its source text as for Scalars.
+func (f *SourceFile) ValueLocation(at metadata.SourceLocation, path metadata.ValuePath) (metadata.SourceLocation, bool) {
+	n, ok := f.nodes[position{line: at.Line, column: at.Column}]
+	if !ok || f.spans[n].start != at.Offset {
+		return metadata.SourceLocation{}, false
+	}
+	for _, segment := range path {
+		n = childNode(n, segment)
+		if n == nil {
+			return metadata.SourceLocation{}, false
+		}
+	}
+	s, ok := f.spans[n], true
+	if n.Kind == yaml.ScalarNode {
+		s, ok = f.scalarText(n)
+	}
+	if !ok {
+		return metadata.SourceLocation{}, false
+	}
+	res := f.location(s)
+	res.Path = at.Path
+	return res, true
+}
+
+// childNode returns the value of the property of the mapping or the item of the sequence by the index.
+func childNode(n *yaml.Node, segment string) *yaml.Node {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == segment {
+				return n.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(n.Content) {
+			return n.Content[i]
+		}
+	}
+	return nil
+}
+
+// scalarText returns the range of the source text of the scalar value excluding quotes,
+// if the text is equal to the value.
+func (f *SourceFile) scalarText(n *yaml.Node) (span, bool) {
+	s, ok := f.spans[n]
+	if !ok || n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return span{}, false
+	}
+	if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		s.start++
+		s.end--
+	}
+	if s.end < s.start || string(f.content[s.start:s.end]) != n.Value {
+		return span{}, false
+	}
+	return s, true
+}
+
+// location returns the location of the range. The path of the location is not set.
+func (f *SourceFile) location(s span) metadata.SourceLocation {
+	res := metadata.SourceLocation{Offset: s.start, EndOffset: s.end}
+	res.Line, res.Column = f.position(s.start)
+	res.EndLine, res.EndColumn = f.position(s.end)
+	return res
+}
+
+// offset converts the one-based line and column counted in characters to the byte offset.
+func (f *SourceFile) offset(line, column int) int {
+	if line < 1 || line > len(f.lineOffsets) {
+		return len(f.content)
+	}
+	res := f.lineOffsets[line-1]
+	for col := 1; col < column && res < len(f.content) && f.content[res] != '\n'; col++ {
+		_, size := utf8.DecodeRune(f.content[res:])
+		res += size
+	}
+	return res
+}
+
+// position converts the byte offset to the one-based line and column counted in characters.
+func (f *SourceFile) position(offset int) (int, int) {
+	line := 1
+	for line < len(f.lineOffsets) && f.lineOffsets[line] <= offset {
+		line++
+	}
+	return line, utf8.RuneCount(f.content[f.lineOffsets[line-1]:offset]) + 1
+}
+
+// trimEnd returns the end of the node which subtree ends with the last node enclosed in the number of flow collections.
+// Single-line scalars end right after their source text followed by closing brackets of the flow collections,
+// other nodes end before the limit excluding trailing whitespace and comments.
+func (f *SourceFile) trimEnd(last *yaml.Node, flows int, start, limit int) int {
+	if last.Kind == yaml.ScalarNode && last.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		if end, ok := f.scalarEnd(last); ok && end >= start && end <= limit {
+			for i := end; i < limit && flows > 0; i++ {
+				switch f.content[i] {
+				case ']', '}':
+					flows--
+					end = i + 1
+				case ' ', '\t', '\r', '\n', ',':
+				default:
+					flows = 0
+				}
+			}
+			return end
+		}
+	}
+	end := limit
+	for end > start {
+		trimmed := bytes.TrimRight(f.content[start:end], " \t\r\n")
+		end = start + len(trimmed)
+		lineStart := bytes.LastIndexByte(trimmed, '\n') + 1
+		if !bytes.HasPrefix(bytes.TrimLeft(trimmed[lineStart:], " \t"), []byte("#")) || lineStart == 0 {
+			break
+		}
+		end = start + lineStart
+	}
+	return end
+}
+
+// scalarEnd returns the end of the single-line plain or quoted scalar.
+func (f *SourceFile) scalarEnd(n *yaml.Node) (int, bool) {
+	start := f.offset(n.Line, n.Column)
+	lineEnd := len(f.content)
+	if i := bytes.IndexByte(f.content[start:], '\n'); i >= 0 {
+		lineEnd = start + i
+	}
+	text := f.content[start:lineEnd]
+	switch {
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		for i := 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return start + i + 1, true
+			}
+		}
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		for i := 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return start + i + 1, true
+		}
+	default:
+		if bytes.HasPrefix(text, []byte(n.Value)) {
+			return start + len(n.Value), true
+		}
+	}
+	return 0, false
+}
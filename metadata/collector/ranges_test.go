@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_SourceFileRanges(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Event:
+    (cti.cti): "cti.x.y.event.v1.0" # Base event.
+    properties:
+      name:
+        type: string
+        # Comment is not a part of the shape.
+
+      tags: [ a, b ] # Inline comment.
+    description: |
+      Multi-line.
+`
+	f, err := ParseSourceFile([]byte(content))
+	require.NoError(t, err)
+
+	text := func(s span, ok bool) string {
+		require.True(t, ok)
+		return content[s.start:s.end]
+	}
+
+	// The mapping of the Event shape starts at its first key.
+	n, ok := f.nodes[position{line: 4, column: 5}]
+	s := f.spans[n]
+	require.Equal(t, `(cti.cti): "cti.x.y.event.v1.0" # Base event.
+    properties:
+      name:
+        type: string
+        # Comment is not a part of the shape.
+
+      tags: [ a, b ] # Inline comment.
+    description: |
+      Multi-line.`, text(s, ok))
+
+	s, ok = f.pairs[position{line: 4, column: 5}]
+	require.Equal(t, `(cti.cti): "cti.x.y.event.v1.0"`, text(s, ok))
+
+	n, ok = f.nodes[position{line: 7, column: 9}]
+	s = f.spans[n]
+	require.Equal(t, `type: string`, text(s, ok))
+
+	s, ok = f.pairs[position{line: 10, column: 7}]
+	require.Equal(t, `tags: [ a, b ]`, text(s, ok))
+
+	line, column := f.position(s.end)
+	require.Equal(t, 10, line)
+	require.Equal(t, 21, column)
+	require.Equal(t, s.start, f.offset(10, 7))
+
+	_, err = ParseSourceFile([]byte("key: ["))
+	require.Error(t, err)
+}
+
+func Test_SourceFileScalars(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Event:
+    (cti.cti): "cti.x.y.event.v1.0"
+    description: |
+      cti.x.y.event.v1.0
+    (cti.reference): [ 'cti.x.y.topic.v1.0', "escaped\u0020" ]
+`
+	f, err := ParseSourceFile([]byte(content))
+	require.NoError(t, err)
+
+	var values []string
+	for _, scalar := range f.Scalars() {
+		values = append(values, scalar.Value)
+		require.Equal(t, scalar.Value, content[scalar.Location.Offset:scalar.Location.EndOffset])
+	}
+	require.Equal(t, []string{"types", "Event", "(cti.cti)", "cti.x.y.event.v1.0", "description", "(cti.reference)", "cti.x.y.topic.v1.0"}, values)
+
+	scalars := f.Scalars()
+	require.Equal(t, 4, scalars[3].Location.Line)
+	require.Equal(t, 17, scalars[3].Location.Column)
+	require.Equal(t, 35, scalars[3].Location.EndColumn)
+}
+
+func Test_SourceFileValueLocation(t *testing.T) {
+	content := `#%RAML 1.0 Library
+(Subscriptions):
+- id: cti.x.y.subscription.v1.0~x.y.first.v1.0
+  topics: [ cti.x.y.topic.v1, "cti.x.y.topic.v2" ]
+  filter:
+    name.with.dots: value
+`
+	ranges := newSourceRanges()
+	f, err := ParseSourceFile([]byte(content))
+	require.NoError(t, err)
+	ranges.files["entities.raml"] = f
+
+	_, s, ok := ranges.item("entities.raml", 2, 1, 0)
+	require.True(t, ok)
+	at := f.location(s)
+	at.Path = "entities.raml"
+	require.Equal(t, 3, at.Line)
+	require.Equal(t, 3, at.Column)
+
+	text := func(path ...string) string {
+		loc, ok := f.ValueLocation(at, metadata.ValuePath(path))
+		require.True(t, ok, path)
+		require.Equal(t, "entities.raml", loc.Path)
+		return content[loc.Offset:loc.EndOffset]
+	}
+	require.Equal(t, "cti.x.y.topic.v1", text("topics", "0"))
+	require.Equal(t, "cti.x.y.topic.v2", text("topics", "1"))
+	require.Equal(t, "value", text("filter", "name.with.dots"))
+	require.Equal(t, "name.with.dots: value", text("filter"))
+
+	_, ok = f.ValueLocation(at, metadata.ValuePath{"topics", "2"})
+	require.False(t, ok)
+	_, _, ok = ranges.item("entities.raml", 2, 1, 1)
+	require.False(t, ok)
+}
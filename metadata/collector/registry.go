@@ -1,8 +1,15 @@
 package collector
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/google/uuid"
+
+	"github.com/acronis/go-cti"
 	"github.com/acronis/go-cti/metadata"
 )
 
@@ -12,13 +19,55 @@ type MetadataRegistry struct {
 	Instances        metadata.EntitiesMap
 	FragmentEntities map[string]metadata.Entities
 	Index            metadata.EntitiesMap
+	Tags             map[string]metadata.EntitiesMap
+	Owners           map[string]metadata.EntitiesMap
+
+	changeHooks  []func(cti string)
+	compactHooks []func()
+	subscribers  []*RegistryEvents
+	// sortedIDs is a lazily built sorted list of CTIs of the index used by CompleteCti.
+	// It is guarded by sortedMu, since CompleteCti builds it on reads that may be concurrent.
+	sortedIDs []string
+	sortedMu  sync.Mutex
+	// derived holds data derived from the registry that is shared by its users, see Derived.
+	derived   map[any]any
+	derivedMu sync.Mutex
 }
 
 func (r *MetadataRegistry) Add(originalPath string, entity *metadata.Entity) error {
 	if _, ok := r.Index[entity.Cti]; ok {
 		return fmt.Errorf("duplicate cti entity %s", entity.Cti)
 	}
+	if err := r.index(originalPath, entity); err != nil {
+		return err
+	}
+	r.sortedIDs = nil
+	r.NotifyChange(entity.Cti)
+	r.notifyAdded(entity)
+	return nil
+}
+
+// Replace replaces the registered entity that has the same CTI, e.g. after its source fragment is parsed again,
+// and notifies subscribers of the registry (see Subscribe).
+func (r *MetadataRegistry) Replace(originalPath string, entity *metadata.Entity) error {
+	old, ok := r.Index[entity.Cti]
+	if !ok {
+		return fmt.Errorf("cti entity %s not found", entity.Cti)
+	}
+	if entity.Values == nil && entity.Schema == nil {
+		return fmt.Errorf("invalid entity: %s", entity.Cti)
+	}
+	r.unindex(old)
+	if err := r.index(originalPath, entity); err != nil {
+		return err
+	}
+	r.sortedIDs = nil
+	r.NotifyChange(entity.Cti)
+	r.notifyReplaced(old, entity)
+	return nil
+}
 
+func (r *MetadataRegistry) index(originalPath string, entity *metadata.Entity) error {
 	switch {
 	case entity.Values != nil:
 		r.Instances[entity.Cti] = entity
@@ -30,12 +79,276 @@ func (r *MetadataRegistry) Add(originalPath string, entity *metadata.Entity) err
 
 	r.FragmentEntities[originalPath] = append(r.FragmentEntities[originalPath], entity)
 	r.Index[entity.Cti] = entity
+	r.indexTags(entity, entity.Tags)
+	r.indexOwners(entity, entity.Owners)
 	return nil
 }
 
+// unindex removes the entity from all indexes of the registry.
+func (r *MetadataRegistry) unindex(entity *metadata.Entity) {
+	delete(r.Index, entity.Cti)
+	delete(r.Types, entity.Cti)
+	delete(r.Instances, entity.Cti)
+	for path, entities := range r.FragmentEntities {
+		for i, e := range entities {
+			if e != entity {
+				continue
+			}
+			entities = append(entities[:i:i], entities[i+1:]...)
+			if len(entities) == 0 {
+				delete(r.FragmentEntities, path)
+			} else {
+				r.FragmentEntities[path] = entities
+			}
+			break
+		}
+	}
+	for _, index := range []map[string]metadata.EntitiesMap{r.Tags, r.Owners} {
+		for key, m := range index {
+			if m[entity.Cti] != entity {
+				continue
+			}
+			delete(m, entity.Cti)
+			if len(m) == 0 {
+				delete(index, key)
+			}
+		}
+	}
+}
+
+// AddChangeHook registers a function that is called with the CTI of every entity added to the registry.
+// Hooks are used to invalidate data derived from the registry, e.g. cached merged schemas.
+func (r *MetadataRegistry) AddChangeHook(hook func(cti string)) {
+	r.changeHooks = append(r.changeHooks, hook)
+}
+
+// NotifyChange calls the change hooks for the entity. It must be called for entities modified in place.
+func (r *MetadataRegistry) NotifyChange(cti string) {
+	for _, hook := range r.changeHooks {
+		hook(cti)
+	}
+}
+
+// AddTags assigns tags to the registered entity and indexes them.
+func (r *MetadataRegistry) AddTags(id string, tags ...string) error {
+	entity, ok := r.Index[id]
+	if !ok {
+		return fmt.Errorf("cti entity %s not found", id)
+	}
+	for _, tag := range tags {
+		if !entity.HasTag(tag) {
+			entity.Tags = append(entity.Tags, tag)
+		}
+	}
+	r.indexTags(entity, tags)
+	return nil
+}
+
+// FindByTag returns entities with the specified tag sorted by CTI.
+func (r *MetadataRegistry) FindByTag(tag string) metadata.Entities {
+	tagged := r.Tags[tag]
+	entities := make(metadata.Entities, 0, len(tagged))
+	for _, entity := range tagged {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Cti < entities[j].Cti
+	})
+	return entities
+}
+
+// AddOwners assigns owners to the registered entity and indexes them.
+func (r *MetadataRegistry) AddOwners(id string, owners ...string) error {
+	entity, ok := r.Index[id]
+	if !ok {
+		return fmt.Errorf("cti entity %s not found", id)
+	}
+	for _, owner := range owners {
+		if !entity.HasOwner(owner) {
+			entity.Owners = append(entity.Owners, owner)
+		}
+	}
+	r.indexOwners(entity, owners)
+	return nil
+}
+
+// FindByOwner returns entities owned by the specified owner sorted by CTI.
+func (r *MetadataRegistry) FindByOwner(owner string) metadata.Entities {
+	owned := r.Owners[owner]
+	entities := make(metadata.Entities, 0, len(owned))
+	for _, entity := range owned {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Cti < entities[j].Cti
+	})
+	return entities
+}
+
+// OwnerStats is a number of entities owned by the owner.
+type OwnerStats struct {
+	Owner     string
+	Types     int
+	Instances int
+}
+
+// GetOwnerStats returns numbers of types and instances per owner sorted by owner.
+// Entities without owners are counted under the empty owner.
+func (r *MetadataRegistry) GetOwnerStats() []OwnerStats {
+	stats := make(map[string]*OwnerStats)
+	count := func(owner string, entity *metadata.Entity) {
+		s, ok := stats[owner]
+		if !ok {
+			s = &OwnerStats{Owner: owner}
+			stats[owner] = s
+		}
+		if entity.Values != nil {
+			s.Instances++
+		} else {
+			s.Types++
+		}
+	}
+	for _, entity := range r.Index {
+		if len(entity.Owners) == 0 {
+			count("", entity)
+		}
+		for _, owner := range entity.Owners {
+			count(owner, entity)
+		}
+	}
+	res := make([]OwnerStats, 0, len(stats))
+	for _, s := range stats {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Owner < res[j].Owner
+	})
+	return res
+}
+
+// FindCompatible returns entities that satisfy the reference (see cti.Expression.SatisfiedBy) ordered from the oldest
+// to the newest version, e.g. all cti.a.p.alert.v1.x types for the reference cti.a.p.alert.v1.
+// Entities with equal versions are ordered by CTI.
+func (r *MetadataRegistry) FindCompatible(ref string) (metadata.Entities, error) {
+	p := cti.NewParser()
+	refExpr, err := p.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ref, err)
+	}
+	var (
+		res   metadata.Entities
+		exprs = make(map[*metadata.Entity]cti.Expression)
+	)
+	for id, entity := range r.Index {
+		expr, err := p.ParseIdentifier(id)
+		if err != nil {
+			continue
+		}
+		ok, err := refExpr.SatisfiedBy(expr)
+		if err != nil {
+			return nil, fmt.Errorf("match %s: %w", id, err)
+		}
+		if ok {
+			res = append(res, entity)
+			exprs[entity] = expr
+		}
+	}
+	sort.Slice(res, func(a, b int) bool {
+		exprA := exprs[res[a]]
+		if c := exprA.CompareVersions(exprs[res[b]]); c != 0 {
+			return c < 0
+		}
+		return res[a].Cti < res[b].Cti
+	})
+	return res, nil
+}
+
+// LatestVersion returns the entity with the highest version that satisfies the reference (see FindCompatible),
+// e.g. cti.a.p.event.v1.3 for the reference cti.a.p.event.v1.0 if the registry contains both of them.
+func (r *MetadataRegistry) LatestVersion(ref string) (*metadata.Entity, error) {
+	entities, err := r.FindCompatible(ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("failed to find cti satisfying %s", ref)
+	}
+	return entities[len(entities)-1], nil
+}
+
+// ResolveAnonymous implements cti.Resolver. It looks up the instance whose CTI ends with the anonymous entity UUID.
+func (r *MetadataRegistry) ResolveAnonymous(id uuid.UUID) (*cti.EntityInstance, error) {
+	suffix := string(cti.InheritanceSeparator) + id.String()
+	for key, entity := range r.Instances {
+		if !strings.HasSuffix(key, suffix) {
+			continue
+		}
+		var values map[string]any
+		if err := json.Unmarshal(entity.Values, &values); err != nil {
+			return nil, fmt.Errorf("unmarshal values of %s: %w", entity.Cti, err)
+		}
+		return &cti.EntityInstance{Cti: entity.Cti, Values: values, RawValues: entity.Values}, nil
+	}
+	return nil, fmt.Errorf("anonymous entity %s not found", id)
+}
+
+func (r *MetadataRegistry) indexTags(entity *metadata.Entity, tags []string) {
+	for _, tag := range tags {
+		if r.Tags[tag] == nil {
+			r.Tags[tag] = make(metadata.EntitiesMap)
+		}
+		r.Tags[tag][entity.Cti] = entity
+	}
+}
+
+func (r *MetadataRegistry) indexOwners(entity *metadata.Entity, owners []string) {
+	for _, owner := range owners {
+		if r.Owners[owner] == nil {
+			r.Owners[owner] = make(metadata.EntitiesMap)
+		}
+		r.Owners[owner][entity.Cti] = entity
+	}
+}
+
+// Derived returns the data derived from the registry under the key, e.g. a cache of merged schemas,
+// and makes it with init on the first call, so all users of the registry share the same data instead of
+// registering hooks of their own. The data lives as long as the registry, and init should register
+// change and compact hooks to keep it up to date. Keys should be values of unexported types of the caller package.
+func (r *MetadataRegistry) Derived(key any, init func() any) any {
+	r.derivedMu.Lock()
+	defer r.derivedMu.Unlock()
+
+	if v, ok := r.derived[key]; ok {
+		return v
+	}
+	if r.derived == nil {
+		r.derived = make(map[any]any)
+	}
+	v := init()
+	r.derived[key] = v
+	return v
+}
+
+// GetEntity returns the entity by CTI. It implements metadata.EntityResolver.
+func (r *MetadataRegistry) GetEntity(cti string) (*metadata.Entity, bool) {
+	entity, ok := r.Index[cti]
+	return entity, ok
+}
+
+// Clone returns a shallow copy of the registry that shares indexes and entities with the registry.
+// Lookups of the clone that use derived data (e.g. CompleteCti) do not observe later changes made through the registry.
 func (r *MetadataRegistry) Clone() *MetadataRegistry {
-	c := *r
-	return &c
+	return &MetadataRegistry{
+		Types:            r.Types,
+		Instances:        r.Instances,
+		FragmentEntities: r.FragmentEntities,
+		Index:            r.Index,
+		Tags:             r.Tags,
+		Owners:           r.Owners,
+		changeHooks:      r.changeHooks,
+		compactHooks:     r.compactHooks,
+		subscribers:      r.subscribers,
+	}
 }
 
 func NewMetadataRegistry() *MetadataRegistry {
@@ -44,5 +357,7 @@ func NewMetadataRegistry() *MetadataRegistry {
 		Instances:        make(metadata.EntitiesMap),
 		Index:            make(metadata.EntitiesMap),
 		FragmentEntities: make(map[string]metadata.Entities),
+		Tags:             make(map[string]metadata.EntitiesMap),
+		Owners:           make(map[string]metadata.EntitiesMap),
 	}
 }
@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegistryResolveAnonymous(t *testing.T) {
+	const id = "ba3c448e-55e3-4f7f-ae54-4e87eb8635f6"
+
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:    "cti.a.p.alert.v1.0",
+		Schema: []byte(`{"type":"object"}`),
+	}))
+	require.NoError(t, r.Add("instances.raml", &metadata.Entity{
+		Cti:    "cti.a.p.alert.v1.0~" + id,
+		Values: []byte(`{"severity":"critical"}`),
+	}))
+
+	instance, err := r.ResolveAnonymous(uuid.MustParse(id))
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.alert.v1.0~"+id, instance.Cti)
+
+	_, err = r.ResolveAnonymous(uuid.Nil)
+	require.EqualError(t, err, "anonymous entity 00000000-0000-0000-0000-000000000000 not found")
+
+	p := cti.NewParser(cti.WithAllowAnonymousEntity(true), cti.WithResolver(r))
+	query := p.MustParse(`cti.a.p.alert.v1.0[severity="critical"]`)
+	matched, err := query.Match(p.MustParse("cti.a.p.alert.v1.0~" + id))
+	require.NoError(t, err)
+	require.True(t, matched)
+}
+
+func Test_RegistryLatestVersion(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, id := range []string{
+		"cti.a.p.event.v1.0",
+		"cti.a.p.event.v1.3",
+		"cti.a.p.event.v2.0",
+		"cti.a.p.event.v1.3~a.p.created.v1.0",
+	} {
+		require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: id, Schema: []byte(`{"type":"object"}`)}))
+	}
+
+	for ref, expected := range map[string]string{
+		"cti.a.p.event.v1.0":                  "cti.a.p.event.v1.3",
+		"cti.a.p.event.v1":                    "cti.a.p.event.v1.3",
+		"cti.a.p.event.v*":                    "cti.a.p.event.v2.0",
+		"cti.a.p.event.v1.0~a.p.created.v1.0": "cti.a.p.event.v1.3~a.p.created.v1.0",
+	} {
+		entity, err := r.LatestVersion(ref)
+		require.NoError(t, err, ref)
+		require.Equal(t, expected, entity.Cti, ref)
+	}
+
+	_, err := r.LatestVersion("cti.a.p.event.v1.4")
+	require.EqualError(t, err, "failed to find cti satisfying cti.a.p.event.v1.4")
+}
+
+func Test_RegistryFindCompatible(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, id := range []string{
+		"cti.a.p.alert.v1.10",
+		"cti.a.p.alert.v1.2",
+		"cti.a.p.alert.v1.0",
+		"cti.a.p.alert.v2.0",
+		"cti.a.p.alerts.v1.0",
+	} {
+		require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: id, Schema: []byte(`{"type":"object"}`)}))
+	}
+
+	for ref, expected := range map[string][]string{
+		"cti.a.p.alert.v1":   {"cti.a.p.alert.v1.0", "cti.a.p.alert.v1.2", "cti.a.p.alert.v1.10"},
+		"cti.a.p.alert.v1.2": {"cti.a.p.alert.v1.2", "cti.a.p.alert.v1.10"},
+		"cti.a.p.alert.v2":   {"cti.a.p.alert.v2.0"},
+		"cti.a.p.alert.v3":   nil,
+	} {
+		entities, err := r.FindCompatible(ref)
+		require.NoError(t, err, ref)
+		var ids []string
+		for _, entity := range entities {
+			ids = append(ids, entity.Cti)
+		}
+		require.Equal(t, expected, ids, ref)
+	}
+
+	_, err := r.FindCompatible("invalid")
+	require.Error(t, err)
+}
+
+func Test_RegistryDerived(t *testing.T) {
+	type key struct{}
+	r := NewMetadataRegistry()
+	calls := 0
+	init := func() any {
+		calls++
+		return &calls
+	}
+	v := r.Derived(key{}, init)
+	require.Same(t, v, r.Derived(key{}, init))
+	require.Equal(t, 1, calls)
+
+	r.Derived(struct{ key }{}, init)
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"reflect"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// Snapshot is a state of the registry captured by MetadataRegistry.Snapshot.
+type Snapshot struct {
+	types     metadata.EntitiesMap
+	instances metadata.EntitiesMap
+	index     metadata.EntitiesMap
+	fragments map[string]metadata.Entities
+	tags      map[string]metadata.EntitiesMap
+	owners    map[string]metadata.EntitiesMap
+	// entities are deep copies of the registered entities, so changes of entities made in place are rolled back as well.
+	entities map[*metadata.Entity]*metadata.Entity
+}
+
+// Snapshot captures the state of the registry, so interactive tools (e.g. a language server or a REPL)
+// can apply edits speculatively and roll them back with Restore without parsing the package again.
+// Entities are copied deeply, so changes of the entities made in place (e.g. entries of annotation maps)
+// are restored as well.
+func (r *MetadataRegistry) Snapshot() *Snapshot {
+	s := &Snapshot{
+		types:     cloneEntitiesMap(r.Types),
+		instances: cloneEntitiesMap(r.Instances),
+		index:     cloneEntitiesMap(r.Index),
+		fragments: cloneFragments(r.FragmentEntities),
+		tags:      cloneIndex(r.Tags),
+		owners:    cloneIndex(r.Owners),
+		entities:  make(map[*metadata.Entity]*metadata.Entity, len(r.Index)),
+	}
+	for _, entity := range r.Index {
+		s.entities[entity] = entity.Clone()
+	}
+	return s
+}
+
+// Restore brings the registry back to the state captured by the snapshot. The snapshot may be restored several times.
+// Change hooks are called for entities that were added, removed, replaced or changed since the snapshot,
+// and data derived from the registry is dropped the same way as by Compact, e.g. cached merged schemas
+// of entities that no longer exist.
+func (r *MetadataRegistry) Restore(s *Snapshot) {
+	changed := make(map[string]struct{})
+	for id, entity := range r.Index {
+		if orig, ok := s.index[id]; !ok || orig != entity {
+			changed[id] = struct{}{}
+		}
+	}
+	for id, entity := range s.index {
+		current, ok := r.Index[id]
+		if !ok || current != entity || !reflect.DeepEqual(entity, s.entities[entity]) {
+			changed[id] = struct{}{}
+		}
+	}
+
+	for entity, orig := range s.entities {
+		*entity = *orig.Clone()
+	}
+	r.Types = cloneEntitiesMap(s.types)
+	r.Instances = cloneEntitiesMap(s.instances)
+	r.Index = cloneEntitiesMap(s.index)
+	r.FragmentEntities = cloneFragments(s.fragments)
+	r.Tags = cloneIndex(s.tags)
+	r.Owners = cloneIndex(s.owners)
+
+	for id := range changed {
+		r.NotifyChange(id)
+	}
+	r.dropDerived()
+}
+
+func cloneFragments(fragments map[string]metadata.Entities) map[string]metadata.Entities {
+	res := make(map[string]metadata.Entities, len(fragments))
+	for path, entities := range fragments {
+		res[path] = append(make(metadata.Entities, 0, len(entities)), entities...)
+	}
+	return res
+}
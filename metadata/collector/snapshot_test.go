@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_RegistrySnapshot(t *testing.T) {
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:    "cti.a.p.event.v1.0",
+		Schema: []byte(`{"type": "object"}`),
+		Tags:   []string{"audit"},
+	}))
+	require.NoError(t, r.Add("instances.raml", &metadata.Entity{
+		Cti:    "cti.a.p.event.v1.0~a.p.created.v1.0",
+		Values: []byte(`{"name": "created"}`),
+	}))
+
+	var changed []string
+	r.AddChangeHook(func(cti string) { changed = append(changed, cti) })
+
+	s := r.Snapshot()
+	event := r.Index["cti.a.p.event.v1.0"]
+
+	require.NoError(t, r.Add("instances.raml", &metadata.Entity{
+		Cti:    "cti.a.p.event.v1.0~a.p.deleted.v1.0",
+		Values: []byte(`{"name": "deleted"}`),
+	}))
+	require.NoError(t, r.AddTags("cti.a.p.event.v1.0", "security"))
+	event.Description = "Changed"
+	r.unindex(r.Index["cti.a.p.event.v1.0~a.p.created.v1.0"])
+
+	for i := 0; i < 2; i++ {
+		changed = nil
+		r.Restore(s)
+
+		require.Len(t, r.Index, 2)
+		require.Len(t, r.Instances, 1)
+		require.Len(t, r.FragmentEntities["instances.raml"], 1)
+		require.Same(t, event, r.Index["cti.a.p.event.v1.0"])
+		require.Empty(t, event.Description)
+		require.Equal(t, []string{"audit"}, event.Tags)
+		require.Empty(t, r.FindByTag("security"))
+		require.Len(t, r.FindByTag("audit"), 1)
+
+		if i == 0 {
+			sort.Strings(changed)
+			require.Equal(t, []string{
+				"cti.a.p.event.v1.0",
+				"cti.a.p.event.v1.0~a.p.created.v1.0",
+				"cti.a.p.event.v1.0~a.p.deleted.v1.0",
+			}, changed)
+			// Changes made after the restore are rolled back by the next restore.
+			require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.topic.v1.0", Schema: []byte(`{}`)}))
+		} else {
+			require.Equal(t, []string{"cti.a.p.topic.v1.0"}, changed)
+		}
+	}
+}
+
+func Test_RegistryRestoreDerived(t *testing.T) {
+	yes := true
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{
+		Cti:         "cti.a.p.event.v1.0",
+		Schema:      []byte(`{"type": "object"}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{".id": {}},
+	}))
+	resets := 0
+	r.AddCompactHook(func() { resets++ })
+
+	s := r.Snapshot()
+	r.Index["cti.a.p.event.v1.0"].Annotations[".id"] = metadata.Annotations{ID: &yes}
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.topic.v1.0", Schema: []byte(`{}`)}))
+
+	r.Restore(s)
+	require.Nil(t, r.Index["cti.a.p.event.v1.0"].Annotations[".id"].ID)
+	require.Equal(t, 1, resets)
+	require.Equal(t, []string{"cti.a.p.event.v1.0"}, r.CompleteCti("cti.", 0))
+}
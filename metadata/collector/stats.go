@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxMostReferenced is the number of the most referenced types reported by Stats.
+const maxMostReferenced = 10
+
+// RegistryStats is a summary of the registry usage.
+type RegistryStats struct {
+	Types     int `json:"types"`
+	Instances int `json:"instances"`
+	// Packages are numbers of entities per package sorted by vendor and package.
+	Packages []PackageStats `json:"packages"`
+	// Depths is the distribution of types by the inheritance depth sorted by depth.
+	// Depth of root types is zero.
+	Depths []DepthStats `json:"depths"`
+	// MostReferenced are the most referenced types sorted by the number of references in descending order.
+	MostReferenced []ReferenceStats `json:"most_referenced"`
+	// Unused are CTIs of types that have neither instances nor references, sorted by CTI.
+	Unused []string `json:"unused"`
+}
+
+// PackageStats is a number of entities declared by the package.
+type PackageStats struct {
+	Vendor    string `json:"vendor"`
+	Package   string `json:"package"`
+	Types     int    `json:"types"`
+	Instances int    `json:"instances"`
+}
+
+// DepthStats is a number of types with the inheritance depth.
+type DepthStats struct {
+	Depth int `json:"depth"`
+	Types int `json:"types"`
+}
+
+// ReferenceStats is a number of registry entities that depend on the type (see GetDependents).
+type ReferenceStats struct {
+	Cti        string `json:"cti"`
+	References int    `json:"references"`
+}
+
+// Stats returns usage statistics of the registry. Entities are attributed to the package
+// of the last segment of their CTI, e.g. cti.a.p.event.v1.0~b.q.created.v1.0 is declared by b.q.
+// References are dependencies between registry entities (see GetDependencies), including derived types and instances.
+func (r *MetadataRegistry) Stats() (*RegistryStats, error) {
+	res := &RegistryStats{Types: len(r.Types), Instances: len(r.Instances)}
+
+	packages := make(map[[2]string]*PackageStats)
+	depths := make(map[int]int)
+	for id, entity := range r.Index {
+		vendor, pkg := entityPackage(id)
+		s, ok := packages[[2]string{vendor, pkg}]
+		if !ok {
+			s = &PackageStats{Vendor: vendor, Package: pkg}
+			packages[[2]string{vendor, pkg}] = s
+		}
+		if entity.Values != nil {
+			s.Instances++
+			continue
+		}
+		s.Types++
+		depths[strings.Count(id, "~")]++
+	}
+	res.Packages = make([]PackageStats, 0, len(packages))
+	for _, s := range packages {
+		res.Packages = append(res.Packages, *s)
+	}
+	sort.Slice(res.Packages, func(i, j int) bool {
+		if res.Packages[i].Vendor != res.Packages[j].Vendor {
+			return res.Packages[i].Vendor < res.Packages[j].Vendor
+		}
+		return res.Packages[i].Package < res.Packages[j].Package
+	})
+	res.Depths = make([]DepthStats, 0, len(depths))
+	for depth, n := range depths {
+		res.Depths = append(res.Depths, DepthStats{Depth: depth, Types: n})
+	}
+	sort.Slice(res.Depths, func(i, j int) bool {
+		return res.Depths[i].Depth < res.Depths[j].Depth
+	})
+
+	references := make(map[string]int)
+	for id := range r.Index {
+		deps, err := r.GetDependencies(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deps {
+			references[dep]++
+		}
+	}
+	res.MostReferenced = []ReferenceStats{}
+	res.Unused = []string{}
+	for id := range r.Types {
+		n := references[id]
+		if n == 0 {
+			res.Unused = append(res.Unused, id)
+			continue
+		}
+		res.MostReferenced = append(res.MostReferenced, ReferenceStats{Cti: id, References: n})
+	}
+	sort.Strings(res.Unused)
+	sort.Slice(res.MostReferenced, func(i, j int) bool {
+		if res.MostReferenced[i].References != res.MostReferenced[j].References {
+			return res.MostReferenced[i].References > res.MostReferenced[j].References
+		}
+		return res.MostReferenced[i].Cti < res.MostReferenced[j].Cti
+	})
+	if len(res.MostReferenced) > maxMostReferenced {
+		res.MostReferenced = res.MostReferenced[:maxMostReferenced]
+	}
+	return res, nil
+}
+
+// entityPackage returns the vendor and the package of the last segment of the CTI.
+// Anonymous instances are attributed to the package of their type.
+func entityPackage(id string) (string, string) {
+	segments := strings.Split(strings.TrimPrefix(id, "cti."), "~")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if parts := strings.SplitN(segments[i], ".", 3); len(parts) == 3 {
+			return parts[0], parts[1]
+		}
+	}
+	return "", ""
+}
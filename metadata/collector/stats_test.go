@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_Stats(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.a.p.severity.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.severity.v1.0~a.p.low.v1.0", Values: []byte(`{}`)},
+		{
+			Cti:         "cti.a.p.event.v1.0",
+			Schema:      []byte(`{}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".severity": {Reference: "cti.a.p.severity.v1.0"}},
+		},
+		{Cti: "cti.a.p.event.v1.0~b.q.created.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.event.v1.0~b.q.created.v1.0~c.r.user.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6", Values: []byte(`{}`)},
+		{Cti: "cti.b.q.unused.v1.0", Schema: []byte(`{}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	stats, err := r.Stats()
+	require.NoError(t, err)
+	require.Equal(t, &RegistryStats{
+		Types:     5,
+		Instances: 2,
+		Packages: []PackageStats{
+			{Vendor: "a", Package: "p", Types: 2, Instances: 2},
+			{Vendor: "b", Package: "q", Types: 2},
+			{Vendor: "c", Package: "r", Types: 1},
+		},
+		Depths: []DepthStats{{Depth: 0, Types: 3}, {Depth: 1, Types: 1}, {Depth: 2, Types: 1}},
+		MostReferenced: []ReferenceStats{
+			{Cti: "cti.a.p.event.v1.0", References: 2},
+			{Cti: "cti.a.p.severity.v1.0", References: 2},
+			{Cti: "cti.a.p.event.v1.0~b.q.created.v1.0", References: 1},
+		},
+		Unused: []string{"cti.a.p.event.v1.0~b.q.created.v1.0~c.r.user.v1.0", "cti.b.q.unused.v1.0"},
+	}, stats)
+}
@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// SyncRegistry holds the registry that is shared between goroutines of long-running services:
+// lookups are served from the current registry while a background refresh builds the new one.
+// Registries are never modified after they are published: Swap replaces the registry atomically
+// and Update applies changes to a copy (copy-on-write), so readers never observe partial updates.
+type SyncRegistry struct {
+	current atomic.Pointer[MetadataRegistry]
+	// mu serializes writers, so concurrent updates are not lost.
+	mu sync.Mutex
+	// subscribers are notified about changes of published registries, see Subscribe.
+	subscribers []*RegistryEvents
+}
+
+// NewSyncRegistry makes a concurrent-safe holder of the registry. The registry must not be modified afterwards.
+func NewSyncRegistry(r *MetadataRegistry) *SyncRegistry {
+	s := &SyncRegistry{}
+	s.current.Store(r)
+	return s
+}
+
+// Load returns the current registry. The registry is safe for concurrent reads and must not be modified.
+// Callers that make several lookups should use the same registry to observe a consistent state.
+// Data derived from the registry (e.g. merger.SchemaCache) should be bound to the returned registry
+// rather than to the holder, since the registry is replaced on refresh.
+func (s *SyncRegistry) Load() *MetadataRegistry {
+	return s.current.Load()
+}
+
+// Swap atomically replaces the registry with the refreshed one and returns the previous registry.
+func (s *SyncRegistry) Swap(r *MetadataRegistry) *MetadataRegistry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.current.Swap(r)
+	s.notify(prev, r)
+	return prev
+}
+
+// Update applies the changes to a copy of the current registry and publishes the copy if fn succeeds.
+// Entities are copied as well, so fn may modify them in place. Change hooks and subscriptions are not copied.
+func (s *SyncRegistry) Update(fn func(r *MetadataRegistry) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.current.Load()
+	r := copyRegistry(prev)
+	if err := fn(r); err != nil {
+		return err
+	}
+	s.current.Store(r)
+	s.notify(prev, r)
+	return nil
+}
+
+// Get returns the entity of the current registry.
+func (s *SyncRegistry) Get(cti string) (*metadata.Entity, bool) {
+	entity, ok := s.Load().Index[cti]
+	return entity, ok
+}
+
+// LatestVersion returns the latest version of the entity of the current registry (see MetadataRegistry.LatestVersion).
+func (s *SyncRegistry) LatestVersion(ref string) (*metadata.Entity, error) {
+	return s.Load().LatestVersion(ref)
+}
+
+// Query returns entities of the current registry matching the query (see MetadataRegistry.Query).
+func (s *SyncRegistry) Query(query string) ([]QueryResult, error) {
+	return s.Load().Query(query)
+}
+
+// copyRegistry copies indexes and entities of the registry. Entities are copied deeply,
+// so changes of their annotations, tags and other fields do not leak into the published registry.
+func copyRegistry(r *MetadataRegistry) *MetadataRegistry {
+	entities := make(map[*metadata.Entity]*metadata.Entity, len(r.Index))
+	copyEntity := func(entity *metadata.Entity) *metadata.Entity {
+		if c, ok := entities[entity]; ok {
+			return c
+		}
+		c := entity.Clone()
+		entities[entity] = c
+		return c
+	}
+	copyMap := func(m metadata.EntitiesMap) metadata.EntitiesMap {
+		res := make(metadata.EntitiesMap, len(m))
+		for k, entity := range m {
+			res[k] = copyEntity(entity)
+		}
+		return res
+	}
+	copyIndex := func(index map[string]metadata.EntitiesMap) map[string]metadata.EntitiesMap {
+		res := make(map[string]metadata.EntitiesMap, len(index))
+		for k, m := range index {
+			res[k] = copyMap(m)
+		}
+		return res
+	}
+
+	res := &MetadataRegistry{
+		Types:            copyMap(r.Types),
+		Instances:        copyMap(r.Instances),
+		Index:            copyMap(r.Index),
+		FragmentEntities: make(map[string]metadata.Entities, len(r.FragmentEntities)),
+		Tags:             copyIndex(r.Tags),
+		Owners:           copyIndex(r.Owners),
+	}
+	for path, fragment := range r.FragmentEntities {
+		c := make(metadata.Entities, len(fragment))
+		for i, entity := range fragment {
+			c[i] = copyEntity(entity)
+		}
+		res.FragmentEntities[path] = c
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SyncRegistryUpdate(t *testing.T) {
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{"type":"object"}`)}))
+	s := NewSyncRegistry(r)
+
+	require.NoError(t, s.Update(func(r *MetadataRegistry) error {
+		r.Index["cti.a.p.event.v1.0"].Description = "changed"
+		return r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.1", Schema: []byte(`{"type":"object"}`)})
+	}))
+
+	// The published registry is not modified.
+	require.Empty(t, r.Index["cti.a.p.event.v1.0"].Description)
+	require.NotContains(t, r.Index, "cti.a.p.event.v1.1")
+
+	entity, ok := s.Get("cti.a.p.event.v1.0")
+	require.True(t, ok)
+	require.Equal(t, "changed", entity.Description)
+	require.Same(t, entity, s.Load().Types["cti.a.p.event.v1.0"])
+	require.Same(t, entity, s.Load().FragmentEntities["types.raml"][0])
+
+	latest, err := s.LatestVersion("cti.a.p.event.v1")
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.event.v1.1", latest.Cti)
+
+	errFailed := errors.New("failed")
+	current := s.Load()
+	require.ErrorIs(t, s.Update(func(r *MetadataRegistry) error {
+		delete(r.Index, "cti.a.p.event.v1.0")
+		return errFailed
+	}), errFailed)
+	require.Same(t, current, s.Load())
+
+	require.Same(t, current, s.Swap(r))
+	require.Same(t, r, s.Load())
+}
+
+func Test_SyncRegistryUpdateAnnotations(t *testing.T) {
+	yes := true
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{"type":"object"}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{".id": {}}, Tags: []string{"a"}}))
+	s := NewSyncRegistry(r)
+	var replaced []string
+	s.Subscribe(RegistryEvents{OnTypeReplaced: func(_, entity *metadata.Entity) {
+		replaced = append(replaced, entity.Cti)
+	}})
+
+	require.NoError(t, s.Update(func(r *MetadataRegistry) error {
+		e := r.Index["cti.a.p.event.v1.0"]
+		e.Annotations[".id"] = metadata.Annotations{ID: &yes}
+		e.Tags[0] = "b"
+		return nil
+	}))
+
+	// Entities are copied deeply, so the published registry is not modified.
+	require.Nil(t, r.Index["cti.a.p.event.v1.0"].Annotations[".id"].ID)
+	require.Equal(t, []string{"a"}, r.Index["cti.a.p.event.v1.0"].Tags)
+	require.Equal(t, []string{"cti.a.p.event.v1.0"}, replaced)
+}
+
+func Test_SyncRegistryConcurrent(t *testing.T) {
+	r := NewMetadataRegistry()
+	require.NoError(t, r.Add("types.raml", &metadata.Entity{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{"type":"object"}`)}))
+	s := NewSyncRegistry(r)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, ok := s.Get("cti.a.p.event.v1.0")
+				require.True(t, ok)
+				_, err := s.Query("cti.a.p.event.v1.0")
+				require.NoError(t, err)
+				require.Len(t, s.Load().CompleteCti("cti.a.p.", 0), 1)
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		require.NoError(t, s.Update(func(r *MetadataRegistry) error {
+			r.Index["cti.a.p.event.v1.0"].Description = "changed"
+			return nil
+		}))
+	}
+	wg.Wait()
+	require.Len(t, s.Load().Index, 1)
+}
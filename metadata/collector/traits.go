@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// TraitReference is a trait value of the entity that references a CTI entity, e.g. a member of a dictionary type.
+type TraitReference struct {
+	// Path is a path of the trait property annotated with cti.reference.
+	Path metadata.GJsonPath
+	// Reference is a value of cti.reference annotation that the trait value must match.
+	Reference string
+	// Cti is the trait value.
+	Cti string
+	// Entity is the referenced entity or nil if it is not found in the registry.
+	Entity *metadata.Entity
+	// DisplayName is a display name of the referenced entity or its CTI if the entity has no display name.
+	DisplayName string
+}
+
+// FindTraitsSchemaInChain returns the nearest ancestor of the entity that defines the traits schema.
+func (r *MetadataRegistry) FindTraitsSchemaInChain(cti string) (*metadata.Entity, bool) {
+	for {
+		parentCti := metadata.GetParentCti(cti)
+		if parentCti == cti {
+			return nil, false
+		}
+		parent, ok := r.Index[parentCti]
+		if !ok {
+			return nil, false
+		}
+		if parent.TraitsSchema != nil {
+			return parent, true
+		}
+		cti = parentCti
+	}
+}
+
+// GetMergedTraits returns traits of the entity merged with traits of its ancestors.
+// Traits of descendants take precedence, nested objects are merged by key.
+// Returns nil if neither the entity nor its ancestors have traits.
+func (r *MetadataRegistry) GetMergedTraits(cti string) (map[string]any, error) {
+	var chain []*metadata.Entity
+	for id := cti; ; {
+		entity, ok := r.Index[id]
+		if !ok {
+			return nil, fmt.Errorf("failed to find cti %s", id)
+		}
+		if entity.Traits != nil {
+			chain = append(chain, entity)
+		}
+		parentCti := metadata.GetParentCti(id)
+		if parentCti == id {
+			break
+		}
+		id = parentCti
+	}
+
+	var merged map[string]any
+	for i := len(chain) - 1; i >= 0; i-- {
+		var traits map[string]any
+		if err := json.Unmarshal(chain[i].Traits, &traits); err != nil {
+			return nil, fmt.Errorf("unmarshal traits of %s: %w", chain[i].Cti, err)
+		}
+		merged = mergeTraits(merged, traits)
+	}
+	return merged, nil
+}
+
+func mergeTraits(parent, child map[string]any) map[string]any {
+	if parent == nil {
+		return child
+	}
+	res := make(map[string]any, len(parent)+len(child))
+	for k, v := range parent {
+		res[k] = v
+	}
+	for k, v := range child {
+		parentObj, ok1 := res[k].(map[string]any)
+		childObj, ok2 := v.(map[string]any)
+		if ok1 && ok2 {
+			res[k] = mergeTraits(parentObj, childObj)
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// GetTraitReferences returns trait values of the entity that reference CTI entities according to
+// cti.reference annotations of the traits schema. References are sorted by path and value.
+func (r *MetadataRegistry) GetTraitReferences(cti string) ([]TraitReference, error) {
+	entity, ok := r.Index[cti]
+	if !ok || entity.Traits == nil {
+		return nil, nil
+	}
+	owner, ok := r.FindTraitsSchemaInChain(cti)
+	if !ok {
+		return nil, nil
+	}
+
+	var refs []TraitReference
+	for key, annotation := range owner.TraitsAnnotations {
+		if annotation.Reference == nil {
+			continue
+		}
+		reference := annotation.ReadReference()
+		for _, val := range key.GetValue(entity.Traits).Array() {
+			ref := TraitReference{
+				Path:        key,
+				Reference:   reference,
+				Cti:         val.Str,
+				Entity:      r.Index[val.Str],
+				DisplayName: val.Str,
+			}
+			if ref.Entity != nil {
+				if name := r.GetDisplayName(ref.Entity); name != "" {
+					ref.DisplayName = name
+				}
+			}
+			refs = append(refs, ref)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Path != refs[j].Path {
+			return refs[i].Path < refs[j].Path
+		}
+		return refs[i].Cti < refs[j].Cti
+	})
+	return refs, nil
+}
+
+// GetDisplayName returns the display name of the entity.
+// For instances without display name, the value of the property annotated with cti.display_name
+// in the nearest type of the inheritance chain is used.
+func (r *MetadataRegistry) GetDisplayName(entity *metadata.Entity) string {
+	if entity.DisplayName != "" || entity.Values == nil {
+		return entity.DisplayName
+	}
+	for id := metadata.GetParentCti(entity.Cti); ; {
+		typ, ok := r.Index[id]
+		if !ok {
+			return ""
+		}
+		for key, annotation := range typ.Annotations {
+			if annotation.DisplayName != nil && *annotation.DisplayName {
+				return key.GetValue(entity.Values).String()
+			}
+		}
+		parentCti := metadata.GetParentCti(id)
+		if parentCti == id {
+			return ""
+		}
+		id = parentCti
+	}
+}
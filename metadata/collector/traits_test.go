@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func makeTraitsRegistry(t *testing.T, severity string) *MetadataRegistry {
+	t.Helper()
+
+	yes := true
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.severity.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".name": {DisplayName: &yes},
+		}},
+		{Cti: "cti.x.y.severity.v1.0~x.y.low.v1.0", Values: []byte(`{"name": "Low"}`)},
+		{Cti: "cti.x.y.severity.v1.0~x.y.high.v1.0", DisplayName: "High", Values: []byte(`{"name": "high"}`)},
+		{Cti: "cti.x.y.alert.v1.0", Schema: []byte(`{}`), TraitsSchema: []byte(`{}`),
+			TraitsAnnotations: map[metadata.GJsonPath]metadata.Annotations{
+				".severity": {Reference: "cti.x.y.severity.v1.0"},
+			}},
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"severity": "` + severity + `"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	return r
+}
+
+func Test_FindTraitsSchemaInChain(t *testing.T) {
+	r := makeTraitsRegistry(t, "cti.x.y.severity.v1.0~x.y.low.v1.0")
+
+	owner, ok := r.FindTraitsSchemaInChain("cti.x.y.alert.v1.0~x.y.disk.v1.0")
+	require.True(t, ok)
+	require.Equal(t, "cti.x.y.alert.v1.0", owner.Cti)
+
+	_, ok = r.FindTraitsSchemaInChain("cti.x.y.alert.v1.0")
+	require.False(t, ok)
+}
+
+func Test_GetTraitReferences(t *testing.T) {
+	for _, tc := range []struct {
+		severity    string
+		displayName string
+		found       bool
+	}{
+		{severity: "cti.x.y.severity.v1.0~x.y.low.v1.0", displayName: "Low", found: true},
+		{severity: "cti.x.y.severity.v1.0~x.y.high.v1.0", displayName: "High", found: true},
+		{severity: "cti.x.y.severity.v1.0~x.y.mid.v1.0", displayName: "cti.x.y.severity.v1.0~x.y.mid.v1.0"},
+	} {
+		t.Run(tc.severity, func(t *testing.T) {
+			r := makeTraitsRegistry(t, tc.severity)
+			refs, err := r.GetTraitReferences("cti.x.y.alert.v1.0~x.y.disk.v1.0")
+			require.NoError(t, err)
+			require.Len(t, refs, 1)
+			require.Equal(t, metadata.GJsonPath(".severity"), refs[0].Path)
+			require.Equal(t, "cti.x.y.severity.v1.0", refs[0].Reference)
+			require.Equal(t, tc.severity, refs[0].Cti)
+			require.Equal(t, tc.found, refs[0].Entity != nil)
+			require.Equal(t, tc.displayName, refs[0].DisplayName)
+		})
+	}
+}
+
+func Test_GetMergedTraits(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.alert.v1.0", Schema: []byte(`{}`), TraitsSchema: []byte(`{}`)},
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0", Schema: []byte(`{}`),
+			Traits: []byte(`{"severity": "low", "limits": {"max": 10, "min": 1}}`)},
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.full.v1.0", Schema: []byte(`{}`),
+			Traits: []byte(`{"severity": "high", "limits": {"max": 20}}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	traits, err := r.GetMergedTraits("cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.full.v1.0")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"severity": "high",
+		"limits":   map[string]any{"max": float64(20), "min": float64(1)},
+	}, traits)
+
+	traits, err = r.GetMergedTraits("cti.x.y.alert.v1.0")
+	require.NoError(t, err)
+	require.Nil(t, traits)
+
+	_, err = r.GetMergedTraits("cti.x.y.alert.v1.0~x.y.unknown.v1.0")
+	require.EqualError(t, err, "failed to find cti cti.x.y.alert.v1.0~x.y.unknown.v1.0")
+}
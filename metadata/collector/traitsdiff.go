@@ -0,0 +1,242 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// ChangeKind is a kind of a change between two versions of the entity.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// TraitChange is a change of a trait value or of a property of the traits schema.
+// Old or New is nil if the value is absent in the respective version.
+type TraitChange struct {
+	Path metadata.GJsonPath `json:"path"`
+	Kind ChangeKind         `json:"kind"`
+	Old  any                `json:"old,omitempty"`
+	New  any                `json:"new,omitempty"`
+}
+
+// AnnotationChange is a change of an annotation of the property at the path, see metadata.Annotations.Diff.
+type AnnotationChange struct {
+	Path metadata.GJsonPath `json:"path"`
+	metadata.AnnotationDiff
+}
+
+// TraitsDiff is a difference of traits and traits schemas between two versions of the entity.
+type TraitsDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Traits are changes of trait values merged with traits of ancestors (see GetMergedTraits),
+	// since these are the values observed by consumers at runtime.
+	Traits []TraitChange `json:"traits,omitempty"`
+	// TraitsSchema are changes of properties of the traits schema defined by the type.
+	// Changes of nested properties are reported separately from changes of the enclosing property.
+	TraitsSchema []TraitChange `json:"traits_schema,omitempty"`
+	// TraitsAnnotations are changes of CTI annotations of properties of the traits schema defined by the type.
+	TraitsAnnotations []AnnotationChange `json:"traits_annotations,omitempty"`
+}
+
+// IsEmpty reports whether neither traits nor traits schema differ.
+func (d *TraitsDiff) IsEmpty() bool {
+	return len(d.Traits) == 0 && len(d.TraitsSchema) == 0 && len(d.TraitsAnnotations) == 0
+}
+
+// DiffTraits compares traits and traits schemas of two versions of the entity, e.g. cti.a.p.event.v1.0 and
+// cti.a.p.event.v1.1. Changes are sorted by path.
+func (r *MetadataRegistry) DiffTraits(oldCti, newCti string) (*TraitsDiff, error) {
+	oldEntity, ok := r.Index[oldCti]
+	if !ok {
+		return nil, fmt.Errorf("failed to find cti %s", oldCti)
+	}
+	newEntity, ok := r.Index[newCti]
+	if !ok {
+		return nil, fmt.Errorf("failed to find cti %s", newCti)
+	}
+
+	d := &TraitsDiff{Old: oldCti, New: newCti}
+
+	oldTraits, err := r.GetMergedTraits(oldCti)
+	if err != nil {
+		return nil, err
+	}
+	newTraits, err := r.GetMergedTraits(newCti)
+	if err != nil {
+		return nil, err
+	}
+	d.Traits = diffTraitValues(".", oldTraits, newTraits, nil)
+
+	oldProps, err := traitsSchemaProperties(oldEntity)
+	if err != nil {
+		return nil, err
+	}
+	newProps, err := traitsSchemaProperties(newEntity)
+	if err != nil {
+		return nil, err
+	}
+	d.TraitsSchema = diffValues(oldProps, newProps)
+	d.TraitsAnnotations = diffAnnotations(oldEntity.TraitsAnnotations, newEntity.TraitsAnnotations)
+
+	return d, nil
+}
+
+// diffTraitValues compares trait objects recursively and reports changes of leaf values.
+func diffTraitValues(path string, oldVal, newVal map[string]any, res []TraitChange) []TraitChange {
+	keys := make(map[string]struct{}, len(oldVal)+len(newVal))
+	for k := range oldVal {
+		keys[k] = struct{}{}
+	}
+	for k := range newVal {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		childPath := joinPath(path, k)
+		o, oldOk := oldVal[k]
+		n, newOk := newVal[k]
+		oldObj, ok1 := o.(map[string]any)
+		newObj, ok2 := n.(map[string]any)
+		switch {
+		case ok1 && ok2:
+			res = diffTraitValues(childPath, oldObj, newObj, res)
+		case !oldOk:
+			res = append(res, TraitChange{Path: metadata.GJsonPath(childPath), Kind: ChangeAdded, New: n})
+		case !newOk:
+			res = append(res, TraitChange{Path: metadata.GJsonPath(childPath), Kind: ChangeRemoved, Old: o})
+		case !reflect.DeepEqual(o, n):
+			res = append(res, TraitChange{Path: metadata.GJsonPath(childPath), Kind: ChangeChanged, Old: o, New: n})
+		}
+	}
+	return res
+}
+
+// diffValues compares flattened values by path.
+func diffValues(oldVal, newVal map[string]any) []TraitChange {
+	var res []TraitChange
+	for path, o := range oldVal {
+		n, ok := newVal[path]
+		switch {
+		case !ok:
+			res = append(res, TraitChange{Path: metadata.GJsonPath(path), Kind: ChangeRemoved, Old: o})
+		case !reflect.DeepEqual(o, n):
+			res = append(res, TraitChange{Path: metadata.GJsonPath(path), Kind: ChangeChanged, Old: o, New: n})
+		}
+	}
+	for path, n := range newVal {
+		if _, ok := oldVal[path]; !ok {
+			res = append(res, TraitChange{Path: metadata.GJsonPath(path), Kind: ChangeAdded, New: n})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+// diffAnnotations compares annotations by path with metadata.Annotations.Diff.
+// Changes are sorted by path and then in the order of metadata.Annotations.Diff.
+func diffAnnotations(oldVal, newVal map[metadata.GJsonPath]metadata.Annotations) []AnnotationChange {
+	paths := make([]metadata.GJsonPath, 0, len(oldVal)+len(newVal))
+	for path := range oldVal {
+		paths = append(paths, path)
+	}
+	for path := range newVal {
+		if _, ok := oldVal[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i] < paths[j]
+	})
+
+	var res []AnnotationChange
+	for _, path := range paths {
+		for _, diff := range oldVal[path].Diff(newVal[path]) {
+			res = append(res, AnnotationChange{Path: path, AnnotationDiff: diff})
+		}
+	}
+	return res
+}
+
+// traitsSchemaProperties flattens properties of the traits schema of the entity by their paths.
+// Nested properties and array items are excluded from the schema of the enclosing property.
+func traitsSchemaProperties(entity *metadata.Entity) (map[string]any, error) {
+	res := make(map[string]any)
+	if entity.TraitsSchema == nil {
+		return res, nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(entity.TraitsSchema, &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal traits schema of %s: %w", entity.Cti, err)
+	}
+	definitions, _ := schema["definitions"].(map[string]any)
+	flattenSchemaProperties(".", schema, definitions, res, make(map[string]struct{}))
+	return res, nil
+}
+
+func flattenSchemaProperties(
+	path string, node map[string]any, definitions map[string]any, res map[string]any, visiting map[string]struct{},
+) {
+	if ref, ok := node["$ref"].(string); ok {
+		if _, ok := visiting[ref]; ok {
+			return
+		}
+		def, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		if !ok {
+			return
+		}
+		visiting[ref] = struct{}{}
+		defer delete(visiting, ref)
+		node = def
+	}
+
+	if properties, ok := node["properties"].(map[string]any); ok {
+		for name, property := range properties {
+			child, ok := property.(map[string]any)
+			if !ok {
+				continue
+			}
+			childPath := joinPath(path, name)
+			res[childPath] = shallowSchema(child)
+			flattenSchemaProperties(childPath, child, definitions, res, visiting)
+		}
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		flattenSchemaProperties(joinPath(path, "#"), items, definitions, res, visiting)
+	}
+}
+
+// shallowSchema returns the schema without nested properties and array items.
+func shallowSchema(schema map[string]any) map[string]any {
+	res := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if k == "properties" || k == "items" {
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
+
+func joinPath(path, key string) string {
+	if path == "." {
+		return path + key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_DiffTraits(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti: "cti.x.y.alert.v1.0", Schema: []byte(`{}`),
+			TraitsSchema: []byte(`{"$ref": "#/definitions/Traits", "definitions": {"Traits": {"type": "object", "properties": {
+				"retry": {"type": "object", "properties": {"count": {"type": "integer"}}},
+				"ttl": {"type": "string"}
+			}}}}`),
+			Traits: []byte(`{"category": "system"}`),
+			TraitsAnnotations: map[metadata.GJsonPath]metadata.Annotations{
+				".ttl": {Reference: true},
+			},
+		},
+		{
+			Cti: "cti.x.y.alert.v1.1", Schema: []byte(`{}`),
+			TraitsSchema: []byte(`{"$ref": "#/definitions/Traits", "definitions": {"Traits": {"type": "object", "properties": {
+				"retry": {"type": "object", "properties": {"count": {"type": "integer", "maximum": 5}}},
+				"severity": {"type": "string"}
+			}}}}`),
+			Traits: []byte(`{"category": "system"}`),
+			TraitsAnnotations: map[metadata.GJsonPath]metadata.Annotations{
+				".severity": {Reference: "cti.x.y.severity.v1.0"},
+			},
+		},
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"retry": {"count": 3}, "ttl": "1h"}`)},
+		{Cti: "cti.x.y.alert.v1.1~x.y.disk.v1.1", Schema: []byte(`{}`), Traits: []byte(`{"retry": {"count": 5}, "severity": "high"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	d, err := r.DiffTraits("cti.x.y.alert.v1.0~x.y.disk.v1.0", "cti.x.y.alert.v1.1~x.y.disk.v1.1")
+	require.NoError(t, err)
+	require.Equal(t, []TraitChange{
+		{Path: ".retry.count", Kind: ChangeChanged, Old: float64(3), New: float64(5)},
+		{Path: ".severity", Kind: ChangeAdded, New: "high"},
+		{Path: ".ttl", Kind: ChangeRemoved, Old: "1h"},
+	}, d.Traits)
+	require.Empty(t, d.TraitsSchema)
+
+	d, err = r.DiffTraits("cti.x.y.alert.v1.0", "cti.x.y.alert.v1.1")
+	require.NoError(t, err)
+	require.Empty(t, d.Traits)
+	require.Equal(t, []TraitChange{
+		{
+			Path: ".retry.count", Kind: ChangeChanged,
+			Old: map[string]any{"type": "integer"}, New: map[string]any{"type": "integer", "maximum": float64(5)},
+		},
+		{Path: ".severity", Kind: ChangeAdded, New: map[string]any{"type": "string"}},
+		{Path: ".ttl", Kind: ChangeRemoved, Old: map[string]any{"type": "string"}},
+	}, d.TraitsSchema)
+	require.Equal(t, []AnnotationChange{
+		{Path: ".severity", AnnotationDiff: metadata.AnnotationDiff{Name: metadata.Reference, New: "cti.x.y.severity.v1.0"}},
+		{Path: ".ttl", AnnotationDiff: metadata.AnnotationDiff{Name: metadata.Reference, Old: true}},
+	}, d.TraitsAnnotations)
+	require.False(t, d.IsEmpty())
+
+	d, err = r.DiffTraits("cti.x.y.alert.v1.0", "cti.x.y.alert.v1.0")
+	require.NoError(t, err)
+	require.True(t, d.IsEmpty())
+
+	_, err = r.DiffTraits("cti.x.y.alert.v1.0", "cti.x.y.alert.v2.0")
+	require.EqualError(t, err, "failed to find cti cti.x.y.alert.v2.0")
+}
@@ -77,6 +77,17 @@ func (c *Collector) unwrapMetadataType(base *raml.BaseShape) (*raml.BaseShape, e
 			objShape.Properties.Set(pair.Key, prop)
 		}
 	}
+	if objShape.PatternProperties != nil {
+		for pair := objShape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			us, err := c.raml.UnwrapShape(prop.Base)
+			if err != nil {
+				return nil, fmt.Errorf("object pattern property unwrap: %w", err)
+			}
+			prop.Base = us
+			objShape.PatternProperties.Set(pair.Key, prop)
+		}
+	}
 
 	for pair := base.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
 		prop := pair.Value
@@ -0,0 +1,121 @@
+package collector
+
+import "github.com/acronis/go-cti/metadata"
+
+// ViewFor returns a registry of the entities that are visible to the package of the vendor according to
+// their access modifiers: public entities are visible to anyone, protected entities to packages of the same vendor
+// and private entities only to the same package. The vendor and the package of an entity are taken
+// from the last named segment of its CTI, so anonymous instances share them with their type.
+// Entities are shared with the registry, so the view must be treated as read-only.
+func (r *MetadataRegistry) ViewFor(vendor, pkg string) *MetadataRegistry {
+	view := NewMetadataRegistry()
+	for path, entities := range r.FragmentEntities {
+		for _, entity := range entities {
+			if !isVisibleTo(entity, vendor, pkg) {
+				continue
+			}
+			// Entities are unique in the source registry, so Add cannot fail.
+			_ = view.Add(path, entity)
+		}
+	}
+	return view
+}
+
+func isVisibleTo(entity *metadata.Entity, vendor, pkg string) bool {
+	entityVendor, entityPkg := entityPackage(entity.Cti)
+	switch entity.Access {
+	case metadata.AccessProtected:
+		return entityVendor == vendor
+	case metadata.AccessPrivate:
+		return entityVendor == vendor && entityPkg == pkg
+	default:
+		return true
+	}
+}
+
+// IsAccessibleFrom reports whether the entity is visible to the package of the entity with the CTI
+// according to the access modifier of the entity, see ViewFor.
+func IsAccessibleFrom(entity *metadata.Entity, fromCti string) bool {
+	vendor, pkg := entityPackage(fromCti)
+	return isVisibleTo(entity, vendor, pkg)
+}
+
+// ReleasedView returns a registry of the released entities, i.e. entities that are neither drafts nor retired
+// (see metadata.Entity.IsReleased), e.g. for exports of the package. Entities derived from entities
+// that are not released are excluded as well, so instances of draft types are not exported.
+// Entities are shared with the registry, so the view must be treated as read-only.
+func (r *MetadataRegistry) ReleasedView() *MetadataRegistry {
+	view := NewMetadataRegistry()
+	for path, entities := range r.FragmentEntities {
+		for _, entity := range entities {
+			if !r.isReleased(entity) {
+				continue
+			}
+			// Entities are unique in the source registry, so Add cannot fail.
+			_ = view.Add(path, entity)
+		}
+	}
+	return view
+}
+
+// isReleased reports whether the entity and its ancestors found in the registry are released.
+func (r *MetadataRegistry) isReleased(entity *metadata.Entity) bool {
+	for entity != nil {
+		if !entity.IsReleased() {
+			return false
+		}
+		parentCti := metadata.GetParentCti(entity.Cti)
+		if parentCti == entity.Cti {
+			return true
+		}
+		entity = r.Index[parentCti]
+	}
+	return true
+}
+
+// TaggedView returns a registry of the entities that have any of the tags and their ancestors, so schemas of
+// the tagged entities can be merged in the view. Tags are looked up in the tag index of the registry (see FindByTag).
+// Entities are shared with the registry, so the view must be treated as read-only.
+func (r *MetadataRegistry) TaggedView(tags ...string) *MetadataRegistry {
+	keep := make(map[string]struct{})
+	for _, tag := range tags {
+		for id := range r.Tags[tag] {
+			for {
+				if _, ok := keep[id]; ok {
+					break
+				}
+				keep[id] = struct{}{}
+				parentCti := metadata.GetParentCti(id)
+				if parentCti == id {
+					break
+				}
+				if _, ok := r.Index[parentCti]; !ok {
+					break
+				}
+				id = parentCti
+			}
+		}
+	}
+
+	view := NewMetadataRegistry()
+	for path, entities := range r.FragmentEntities {
+		for _, entity := range entities {
+			if _, ok := keep[entity.Cti]; !ok {
+				continue
+			}
+			// Entities are unique in the source registry, so Add cannot fail.
+			_ = view.Add(path, entity)
+		}
+	}
+	return view
+}
+
+// HasAnyTag reports whether the entity with the CTI has any of the tags according to the tag index of the registry.
+func (r *MetadataRegistry) HasAnyTag(id string, tags ...string) bool {
+	for _, tag := range tags {
+		if _, ok := r.Tags[tag][id]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_ViewFor(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, entity := range []*metadata.Entity{
+		{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.internal.v1.0", Schema: []byte(`{}`), Access: metadata.AccessPrivate},
+		{Cti: "cti.a.p.shared.v1.0", Schema: []byte(`{}`), Access: metadata.AccessProtected},
+		{Cti: "cti.a.p.internal.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6", Values: []byte(`{}`), Access: metadata.AccessPrivate},
+		{Cti: "cti.a.p.event.v1.0~b.q.created.v1.0", Schema: []byte(`{}`), Access: metadata.AccessPrivate},
+	} {
+		require.NoError(t, r.Add("entities.raml", entity))
+	}
+
+	ids := func(view *MetadataRegistry) []string {
+		var res []string
+		for id := range view.Index {
+			res = append(res, id)
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	require.Equal(t, []string{
+		"cti.a.p.event.v1.0",
+		"cti.a.p.internal.v1.0",
+		"cti.a.p.internal.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6",
+		"cti.a.p.shared.v1.0",
+	}, ids(r.ViewFor("a", "p")))
+	require.Equal(t, []string{"cti.a.p.event.v1.0", "cti.a.p.shared.v1.0"}, ids(r.ViewFor("a", "ui")))
+	require.Equal(t, []string{"cti.a.p.event.v1.0", "cti.a.p.event.v1.0~b.q.created.v1.0"}, ids(r.ViewFor("b", "q")))
+	require.Len(t, r.ViewFor("c", "x").Types, 1)
+}
+
+func Test_ReleasedView(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, entity := range []*metadata.Entity{
+		{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0", Schema: []byte(`{}`), Status: metadata.StatusDeprecated},
+		{Cti: "cti.a.p.event.v1.0~a.p.updated.v1.0", Schema: []byte(`{}`), Status: metadata.StatusDraft},
+		{Cti: "cti.a.p.event.v1.0~a.p.updated.v1.0~a.p.partial.v1.0", Schema: []byte(`{}`), Status: metadata.StatusReleased},
+		{Cti: "cti.a.p.event.v1.0~a.p.removed.v1.0", Schema: []byte(`{}`), Status: metadata.StatusRetired},
+		{Cti: "cti.a.p.event.v1.0~a.p.updated.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6", Values: []byte(`{}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", entity))
+	}
+
+	view := r.ReleasedView()
+	var ids []string
+	for id := range view.Index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	require.Equal(t, []string{"cti.a.p.event.v1.0", "cti.a.p.event.v1.0~a.p.created.v1.0"}, ids)
+}
+
+func Test_TaggedView(t *testing.T) {
+	r := NewMetadataRegistry()
+	for _, entity := range []*metadata.Entity{
+		{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0", Schema: []byte(`{}`), Tags: []string{"billing"}},
+		{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6", Values: []byte(`{}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.updated.v1.0", Schema: []byte(`{}`), Tags: []string{"audit"}},
+		{Cti: "cti.a.p.topic.v1.0", Schema: []byte(`{}`), Tags: []string{"internal"}},
+	} {
+		require.NoError(t, r.Add("entities.raml", entity))
+	}
+	require.NoError(t, r.AddTags("cti.a.p.topic.v1.0", "billing"))
+
+	ids := func(view *MetadataRegistry) []string {
+		var res []string
+		for id := range view.Index {
+			res = append(res, id)
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	require.Equal(t, []string{
+		"cti.a.p.event.v1.0",
+		"cti.a.p.event.v1.0~a.p.created.v1.0",
+		"cti.a.p.topic.v1.0",
+	}, ids(r.TaggedView("billing")))
+	require.Equal(t, []string{
+		"cti.a.p.event.v1.0",
+		"cti.a.p.event.v1.0~a.p.created.v1.0",
+		"cti.a.p.event.v1.0~a.p.updated.v1.0",
+		"cti.a.p.topic.v1.0",
+	}, ids(r.TaggedView("billing", "audit")))
+	require.Empty(t, r.TaggedView("unknown").Index)
+
+	require.True(t, r.HasAnyTag("cti.a.p.topic.v1.0", "audit", "billing"))
+	require.False(t, r.HasAnyTag("cti.a.p.event.v1.0", "billing"))
+	require.False(t, r.HasAnyTag("cti.a.p.unknown.v1.0", "billing"))
+}
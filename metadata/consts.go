@@ -1,20 +1,44 @@
 package metadata
 
 const (
-	Cti           = "cti.cti"
-	Final         = "cti.final"
-	ID            = "cti.id"
-	L10n          = "cti.l10n"
-	DisplayName   = "cti.display_name"
-	Description   = "cti.description"
-	Asset         = "cti.asset"
-	Overridable   = "cti.overridable"
-	Reference     = "cti.reference"
-	Schema        = "cti.schema"
-	Meta          = "cti.meta"
-	PropertyNames = "cti.propertyNames"
+	Cti                = "cti.cti"
+	Final              = "cti.final"
+	Access             = "cti.access"
+	Status             = "cti.status"
+	Deprecated         = "cti.deprecated"
+	DeprecationMessage = "cti.deprecation_message"
+	ReplacedBy         = "cti.replaced_by"
+	ID                 = "cti.id"
+	L10n               = "cti.l10n"
+	DisplayName        = "cti.display_name"
+	Description        = "cti.description"
+	Asset              = "cti.asset"
+	Dictionary         = "cti.dictionary"
+	Sensitive          = "cti.sensitive"
+	Overridable        = "cti.overridable"
+	Reference          = "cti.reference"
+	Schema             = "cti.schema"
+	Meta               = "cti.meta"
+	PropertyNames      = "cti.propertyNames"
+	Tags               = "cti.tags"
+	Owners             = "cti.owners"
 )
 
 const (
 	Traits = "cti-traits"
 )
+
+// Access modifiers of entities, see Entity.Access.
+const (
+	AccessPublic    = "public"
+	AccessProtected = "protected"
+	AccessPrivate   = "private"
+)
+
+// Lifecycle statuses of entities, see Entity.Status.
+const (
+	StatusDraft      = "draft"
+	StatusReleased   = "released"
+	StatusDeprecated = "deprecated"
+	StatusRetired    = "retired"
+)
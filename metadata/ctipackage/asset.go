@@ -1,6 +1,76 @@
 package ctipackage
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata/assetstore"
+)
+
 type Asset struct {
 	Name  string `json:"name"`
 	Value []byte `json:"value"`
 }
+
+// WithAssetStore makes the package resolve its assets from the storage instead of the package directory.
+func WithAssetStore(store assetstore.Store) InitializeOption {
+	return func(pkg *Package) error {
+		pkg.assets = store
+		return nil
+	}
+}
+
+// WithMaxAssetSize limits the size of assets of the package checked by ValidateAssets.
+func WithMaxAssetSize(size int64) InitializeOption {
+	return func(pkg *Package) error {
+		pkg.maxAssetSize = size
+		return nil
+	}
+}
+
+// AssetStore returns the storage of assets of the package. By default, assets are resolved from the package directory.
+func (pkg *Package) AssetStore() assetstore.Store {
+	if pkg.assets == nil {
+		return assetstore.NewDir(pkg.BaseDir)
+	}
+	return pkg.assets
+}
+
+// ValidateAssets checks that assets listed in the index and assets referenced by cti.asset annotations
+// of the parsed entities of the package exist in the storage, are not empty and do not exceed
+// the size limit (see WithMaxAssetSize).
+func (pkg *Package) ValidateAssets(ctx context.Context) error {
+	names := make(map[string]struct{})
+	for _, name := range pkg.Index.Assets {
+		names[name] = struct{}{}
+	}
+	if pkg.LocalRegistry != nil {
+		for id := range pkg.LocalRegistry.Instances {
+			for _, name := range pkg.GlobalRegistry.GetAssetReferences(id) {
+				names[name] = struct{}{}
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	store := pkg.AssetStore()
+	var errs []error
+	for _, name := range sorted {
+		info, err := store.Stat(ctx, name)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case info.Size == 0:
+			errs = append(errs, fmt.Errorf("asset %s is empty", name))
+		case pkg.maxAssetSize > 0 && info.Size > pkg.maxAssetSize:
+			errs = append(errs, fmt.Errorf("asset %s is too large: %d bytes, at most %d allowed", name, info.Size, pkg.maxAssetSize))
+		}
+	}
+	return errors.Join(errs...)
+}
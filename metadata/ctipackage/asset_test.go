@@ -0,0 +1,33 @@
+package ctipackage
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/assetstore"
+)
+
+func Test_ValidateAssets(t *testing.T) {
+	store := assetstore.NewFS(fstest.MapFS{
+		"assets/logo.svg":  {Data: []byte("<svg/>")},
+		"assets/empty.txt": {Data: []byte{}},
+	})
+	pkg, err := New(t.TempDir(), WithAssetStore(store), WithMaxAssetSize(4))
+	require.NoError(t, err)
+	require.Same(t, store, pkg.AssetStore())
+
+	pkg.Index.Assets = []string{"assets/logo.svg"}
+	require.EqualError(t, pkg.ValidateAssets(context.Background()),
+		"asset assets/logo.svg is too large: 6 bytes, at most 4 allowed")
+
+	pkg.maxAssetSize = 0
+	require.NoError(t, pkg.ValidateAssets(context.Background()))
+
+	pkg.Index.Assets = []string{"assets/missing.png", "assets/empty.txt", "assets/logo.svg"}
+	err = pkg.ValidateAssets(context.Background())
+	require.ErrorIs(t, err, assetstore.ErrNotFound)
+	require.EqualError(t, err, "asset assets/empty.txt is empty\nasset not found: assets/missing.png")
+}
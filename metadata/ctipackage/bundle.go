@@ -0,0 +1,211 @@
+package ctipackage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	// maxBundleFileSize limits the size of a single file read from the bundle.
+	maxBundleFileSize = 100 << 20 // 100 MB
+	// maxBundleSize limits the total uncompressed size of the files read from the bundle.
+	maxBundleSize = 1 << 30 // 1 GB
+)
+
+// ErrBundleDependencies is returned for bundles of packages with dependencies that do not hold entities
+// of the dependencies, e.g. bundles made by older versions of the packer.
+var ErrBundleDependencies = errors.New("bundle does not hold entities of its dependencies")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// Bundle is a packed package that is read into memory from an archive stream.
+type Bundle struct {
+	Index *Index
+	// Registry holds entities serialized into the bundle by the packer: entities of the package
+	// and of its dependencies (see DependenciesCacheFile).
+	Registry *collector.MetadataRegistry
+	// Files are contents of all files of the bundle by their paths.
+	Files map[string][]byte
+}
+
+// ReadBundle reads a packed package from the stream without touching the file system.
+// The stream is a tar archive, optionally compressed with gzip, or a zip archive.
+// Zip archives are buffered in memory since they cannot be read sequentially.
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read archive header: %w", err)
+	}
+
+	var files map[string][]byte
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("create gzip reader: %w", err)
+		}
+		defer gzr.Close()
+		files, err = readTarFiles(gzr, maxBundleSize)
+		if err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(magic, zipMagic):
+		files, err = readZipFiles(br, maxBundleSize)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		files, err = readTarFiles(br, maxBundleSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewBundle(files)
+}
+
+// NewBundle makes a bundle from the files of a packed package.
+func NewBundle(files map[string][]byte) (*Bundle, error) {
+	data, ok := files[IndexFileName]
+	if !ok {
+		return nil, fmt.Errorf("failed to find %s in archive", IndexFileName)
+	}
+	idx, err := DecodeIndex(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode index: %w", err)
+	}
+	if err := idx.Check(); err != nil {
+		return nil, fmt.Errorf("check index: %w", err)
+	}
+
+	b := &Bundle{
+		Index:    idx,
+		Registry: collector.NewMetadataRegistry(),
+		Files:    files,
+	}
+	for _, name := range idx.Serialized {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("failed to find serialized metadata %s in archive", name)
+		}
+		var entities []*metadata.Entity
+		if err := json.Unmarshal(data, &entities); err != nil {
+			return nil, fmt.Errorf("decode serialized metadata %s: %w", name, err)
+		}
+		for _, entity := range entities {
+			if err := b.Registry.Add(entity.SourceMap.OriginalPath, entity); err != nil {
+				return nil, fmt.Errorf("add cti entity: %w", err)
+			}
+		}
+	}
+	return b, nil
+}
+
+// CheckDependencies returns ErrBundleDependencies if the package has dependencies, but the bundle
+// does not hold their entities, so references to the dependencies cannot be resolved.
+func (b *Bundle) CheckDependencies() error {
+	if len(b.Index.Depends) == 0 {
+		return nil
+	}
+	for _, name := range b.Index.Serialized {
+		if name == DependenciesCacheFile {
+			return nil
+		}
+	}
+	return ErrBundleDependencies
+}
+
+// readTarFiles reads regular files of the tar archive. The total size of the files is limited by maxSize.
+func readTarFiles(r io.Reader, maxSize int64) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	var total int64
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read tar header: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxBundleFileSize {
+			return nil, fmt.Errorf("file too large: %s", header.Name)
+		}
+		if total += header.Size; total > maxSize {
+			return nil, fmt.Errorf("archive too large: more than %d bytes", maxSize)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+		files[cleanBundlePath(header.Name)] = data
+	}
+	return files, nil
+}
+
+// readZipFiles reads files of the zip archive. The size of the compressed archive and the total size
+// of the files are limited by maxSize.
+func readZipFiles(r io.Reader, maxSize int64) (map[string][]byte, error) {
+	// The central directory is at the end of the archive, so it is read into memory before the files.
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read zip archive: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("archive too large: more than %d bytes", maxSize)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open zip archive: %w", err)
+	}
+	files := make(map[string][]byte)
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.UncompressedSize64 > maxBundleFileSize {
+			return nil, fmt.Errorf("file too large: %s", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		// Sizes in zip headers are not trusted, the data read is limited as well.
+		data, err := io.ReadAll(io.LimitReader(rc, maxBundleFileSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f.Name, err)
+		}
+		if len(data) > maxBundleFileSize {
+			return nil, fmt.Errorf("file too large: %s", f.Name)
+		}
+		if total += int64(len(data)); total > maxSize {
+			return nil, fmt.Errorf("archive too large: more than %d bytes", maxSize)
+		}
+		files[cleanBundlePath(f.Name)] = data
+	}
+	return files, nil
+}
+
+func cleanBundlePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
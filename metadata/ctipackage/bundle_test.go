@@ -0,0 +1,123 @@
+package ctipackage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReadBundle(t *testing.T) {
+	files := map[string]string{
+		IndexFileName:     `{"package_id": "x.y", "entities": ["entities.raml"], "serialized": [".cache.json"]}`,
+		MetadataCacheFile: `[{"cti": "cti.x.y.event.v1.0", "final": false, "schema": {"type": "object"}, "source_map": {"$originalPath": "entities.raml"}}, {"cti": "cti.x.y.event.v1.0~x.y.created.v1.0", "final": true, "values": {}}]`,
+		"entities.raml":   "#%RAML 1.0 Library\n",
+	}
+	names := []string{IndexFileName, MetadataCacheFile, "entities.raml"}
+
+	writeTar := func(w io.Writer) {
+		tw := tar.NewWriter(w)
+		for _, name := range names {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}))
+			_, err := tw.Write([]byte(files[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+	}
+
+	var plain, tgz, zipped bytes.Buffer
+	writeTar(&plain)
+
+	gw := gzip.NewWriter(&tgz)
+	writeTar(gw)
+	require.NoError(t, gw.Close())
+
+	zw := zip.NewWriter(&zipped)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	for format, archive := range map[string]*bytes.Buffer{"tar": &plain, "tgz": &tgz, "zip": &zipped} {
+		t.Run(format, func(t *testing.T) {
+			b, err := ReadBundle(archive)
+			require.NoError(t, err)
+			require.Equal(t, "x.y", b.Index.PackageID)
+			require.Len(t, b.Registry.Types, 1)
+			require.Len(t, b.Registry.Instances, 1)
+			require.Len(t, b.Registry.FragmentEntities["entities.raml"], 1)
+			require.Equal(t, files["entities.raml"], string(b.Files["entities.raml"]))
+		})
+	}
+
+	_, err := ReadBundle(bytes.NewReader(nil))
+	require.EqualError(t, err, "failed to find index.json in archive")
+}
+
+func Test_BundleDependencies(t *testing.T) {
+	local := `[{"cti": "cti.a.b.event.v1.0~x.y.created.v1.0", "final": true, "values": {}, "source_map": {"$originalPath": "entities.raml"}}]`
+	dependencies := `[{"cti": "cti.a.b.event.v1.0", "final": false, "schema": {"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object"}}}, "annotations": {".": {"cti.cti": "cti.a.b.event.v1.0"}}}]`
+
+	b, err := NewBundle(map[string][]byte{
+		IndexFileName:         []byte(`{"package_id": "x.y", "depends": {"github.com/a/b": "v1.0.0"}, "serialized": [".cache.json", ".dep.cache.json"]}`),
+		MetadataCacheFile:     []byte(local),
+		DependenciesCacheFile: []byte(dependencies),
+	})
+	require.NoError(t, err)
+	require.Contains(t, b.Registry.Types, "cti.a.b.event.v1.0")
+	require.NoError(t, b.CheckDependencies())
+	require.NoError(t, b.Validate())
+
+	// Bundles made before dependencies were packed cannot be validated.
+	b, err = NewBundle(map[string][]byte{
+		IndexFileName:     []byte(`{"package_id": "x.y", "depends": {"github.com/a/b": "v1.0.0"}, "serialized": [".cache.json"]}`),
+		MetadataCacheFile: []byte(local),
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, b.Validate(), ErrBundleDependencies)
+}
+
+func Test_ReadBundleSizeLimit(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 6, Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte("012345"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	_, err := readTarFiles(bytes.NewReader(archive.Bytes()), 12)
+	require.NoError(t, err)
+	_, err = readTarFiles(bytes.NewReader(archive.Bytes()), 10)
+	require.EqualError(t, err, "archive too large: more than 10 bytes")
+
+	// Files are compressed, so the archive is smaller than its files.
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for _, name := range []string{"a", "b"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(bytes.Repeat([]byte("0"), 1000))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	size := int64(zipped.Len())
+	require.Less(t, size, int64(1000))
+
+	_, err = readZipFiles(bytes.NewReader(zipped.Bytes()), 2000)
+	require.NoError(t, err)
+	_, err = readZipFiles(bytes.NewReader(zipped.Bytes()), size)
+	require.EqualError(t, err, fmt.Sprintf("archive too large: more than %d bytes", size))
+	// The compressed archive is limited before its files are read.
+	_, err = readZipFiles(bytes.NewReader(zipped.Bytes()), size-1)
+	require.EqualError(t, err, fmt.Sprintf("archive too large: more than %d bytes", size-1))
+}
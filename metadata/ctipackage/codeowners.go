@@ -0,0 +1,76 @@
+package ctipackage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CodeownersEntry maps a source file of the package to owners of the entities declared in it.
+type CodeownersEntry struct {
+	// Path is a path of the source file relative to the package directory.
+	Path   string
+	Owners []string
+}
+
+// CodeownersEntries returns owners of the package source files sorted by path.
+// Owners of a file are the owners of all entities declared in it. Files outside of the package
+// directory and files which entities have no owners are skipped.
+func (pkg *Package) CodeownersEntries() []CodeownersEntry {
+	if pkg.LocalRegistry == nil {
+		return nil
+	}
+	files := make(map[string]map[string]struct{})
+	for _, entity := range pkg.LocalRegistry.Index {
+		f := entity.SourceMap.OriginalPath
+		if f == "" || strings.HasPrefix(f, "../") || len(entity.Owners) == 0 {
+			continue
+		}
+		if files[f] == nil {
+			files[f] = make(map[string]struct{})
+		}
+		for _, owner := range entity.Owners {
+			files[f][owner] = struct{}{}
+		}
+	}
+
+	entries := make([]CodeownersEntry, 0, len(files))
+	for f, owners := range files {
+		entry := CodeownersEntry{Path: f, Owners: make([]string, 0, len(owners))}
+		for owner := range owners {
+			entry.Owners = append(entry.Owners, owner)
+		}
+		sort.Strings(entry.Owners)
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries
+}
+
+// WriteCodeowners writes a CODEOWNERS file that maps the package source files to their owners.
+// Paths are prefixed with the prefix, e.g. a path of the package directory in the repository.
+// Owners of the package index are assigned to all files of the package by default.
+// The package must be parsed.
+func (pkg *Package) WriteCodeowners(w io.Writer, prefix string) error {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix != "/" {
+		prefix += "/"
+	}
+
+	lines := []string{fmt.Sprintf("# Generated from CTI package %s. DO NOT EDIT.", pkg.Index.PackageID)}
+	if len(pkg.Index.Owners) != 0 {
+		lines = append(lines, fmt.Sprintf("%s** %s", prefix, strings.Join(pkg.Index.Owners, " ")))
+	}
+	for _, entry := range pkg.CodeownersEntries() {
+		lines = append(lines, fmt.Sprintf("%s%s %s", prefix, entry.Path, strings.Join(entry.Owners, " ")))
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("write codeowners: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package ctipackage
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// LoadBaseline loads entities of the previously published version of the package, e.g. to check compatibility
+// of the current version with collector.CheckCompatibility. The path is either the package directory
+// or the bundle produced by the packer (see ReadBundle). The cache of the package directory is not written.
+func LoadBaseline(path string) (*collector.MetadataRegistry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat baseline: %w", err)
+	}
+
+	if !info.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open baseline bundle: %w", err)
+		}
+		defer f.Close()
+
+		b, err := ReadBundle(f)
+		if err != nil {
+			return nil, fmt.Errorf("read baseline bundle: %w", err)
+		}
+		return b.Registry, nil
+	}
+
+	pkg, err := New(path)
+	if err != nil {
+		return nil, fmt.Errorf("new baseline package: %w", err)
+	}
+	if err := pkg.Read(); err != nil {
+		return nil, fmt.Errorf("read baseline package: %w", err)
+	}
+	entities, err := pkg.EntityFiles()
+	if err != nil {
+		return nil, fmt.Errorf("resolve entity files: %w", err)
+	}
+	if err := pkg.parseEntities(context.Background(), entities, false); err != nil {
+		return nil, fmt.Errorf("parse baseline package: %w", err)
+	}
+	return pkg.LocalRegistry, nil
+}
@@ -0,0 +1,66 @@
+package ctipackage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_LoadBaseline(t *testing.T) {
+	testsupp.InitLog(t)
+
+	entities := func(maxLength int) string {
+		return strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    (cti.final): false
+    type: object
+    properties:
+      name:
+        type: string
+        maxLength: `+strings.Repeat("9", maxLength)+`
+`) + "\n"
+	}
+	makePackage := func(name string, maxLength int) *Package {
+		tc := parserTestCase{
+			name:     name,
+			pkgId:    "x.y",
+			entities: []string{"entities.raml"},
+			files:    map[string]string{"entities.raml": entities(maxLength)},
+		}
+		pkg, err := New(initParseTest(t, tc), WithRamlxVersion("1.0"), WithID(tc.pkgId), WithEntities(tc.entities))
+		require.NoError(t, err)
+		require.NoError(t, pkg.Initialize())
+		return pkg
+	}
+
+	baselinePkg := makePackage("baseline", 3)
+	baseline, err := LoadBaseline(baselinePkg.BaseDir)
+	require.NoError(t, err)
+	require.Contains(t, baseline.Types, "cti.x.y.event.v1.0")
+	_, err = os.Stat(filepath.Join(baselinePkg.BaseDir, MetadataCacheFile))
+	require.True(t, os.IsNotExist(err))
+
+	pkg := makePackage("current", 2)
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+	changes, err := collector.CheckCompatibility(baseline, pkg.GlobalRegistry)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "cti.x.y.event.v1.0: schema .name: maxLength is lowered from 999 to 99", changes[0].String())
+
+	_, err = LoadBaseline(filepath.Join(baselinePkg.BaseDir, "missing"))
+	require.ErrorContains(t, err, "stat baseline")
+}
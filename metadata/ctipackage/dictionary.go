@@ -1,13 +1,126 @@
 package ctipackage
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata/filesys"
+)
+
+// DefaultLangCode is the locale that display names of dictionary entries fall back to.
+const DefaultLangCode LangCode = "en"
+
 type LangCode string
 
 type Field string
 
 type Entry map[Field]string
 
+// Dictionary maps a locale to display names of the dictionary entries by their keys.
+// Each dictionary file of the package index holds one locale, e.g. dictionaries/en.json.
 type Dictionary map[LangCode]Entry
 
 type Dictionaries struct {
 	Dictionaries Dictionary `json:"dictionaries"`
 }
+
+// DictionaryEntry is an entry of the dictionary with its display names in all locales.
+type DictionaryEntry struct {
+	Key          Field
+	DisplayNames map[LangCode]string
+}
+
+// Locales returns locales of the dictionary sorted by code.
+func (d Dictionary) Locales() []LangCode {
+	res := make([]LangCode, 0, len(d))
+	for lang := range d {
+		res = append(res, lang)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// Keys returns keys of the entries of all locales sorted and deduplicated.
+func (d Dictionary) Keys() []Field {
+	seen := make(map[Field]struct{})
+	var res []Field
+	for _, entry := range d {
+		for key := range entry {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			res = append(res, key)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// Entries returns entries of the dictionary sorted by key.
+func (d Dictionary) Entries() []DictionaryEntry {
+	keys := d.Keys()
+	res := make([]DictionaryEntry, 0, len(keys))
+	for _, key := range keys {
+		entry := DictionaryEntry{Key: key, DisplayNames: make(map[LangCode]string)}
+		for lang, names := range d {
+			if name, ok := names[key]; ok {
+				entry.DisplayNames[lang] = name
+			}
+		}
+		res = append(res, entry)
+	}
+	return res
+}
+
+// Has reports whether the key is an entry of the dictionary in any locale.
+func (d Dictionary) Has(key Field) bool {
+	for _, entry := range d {
+		if _, ok := entry[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayName returns the display name of the entry in the locale. If the entry is not translated to the locale,
+// the display name in DefaultLangCode is returned. The second value is false if the dictionary has no such entry
+// in either locale.
+func (d Dictionary) DisplayName(key Field, lang LangCode) (string, bool) {
+	if name, ok := d[lang][key]; ok {
+		return name, true
+	}
+	name, ok := d[DefaultLangCode][key]
+	return name, ok
+}
+
+// GetDictionary returns the dictionary of the bundle read from its dictionary files.
+func (b *Bundle) GetDictionary() (Dictionary, error) {
+	return readDictionary(b.Index, func(name string) (io.ReadCloser, error) {
+		data, ok := b.Files[name]
+		if !ok {
+			return nil, fmt.Errorf("failed to find %s in bundle", name)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+}
+
+// readDictionary reads dictionary files of the index. The locale of the file is its base name.
+func readDictionary(idx *Index, open func(name string) (io.ReadCloser, error)) (Dictionary, error) {
+	dictionary := make(Dictionary)
+	for _, name := range idx.Dictionaries {
+		file, err := open(name)
+		if err != nil {
+			return nil, fmt.Errorf("open dictionary file: %w", err)
+		}
+		entry, err := validateDictionary(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("validate dictionary %s: %w", name, err)
+		}
+		dictionary[LangCode(filesys.GetBaseName(name))] = entry
+	}
+	return dictionary, nil
+}
@@ -0,0 +1,98 @@
+package ctipackage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_Dictionary(t *testing.T) {
+	d := Dictionary{
+		"en": {"active": "Active", "archived": "Archived"},
+		"de": {"active": "Aktiv"},
+	}
+	require.Equal(t, []LangCode{"de", "en"}, d.Locales())
+	require.Equal(t, []Field{"active", "archived"}, d.Keys())
+	require.Equal(t, []DictionaryEntry{
+		{Key: "active", DisplayNames: map[LangCode]string{"en": "Active", "de": "Aktiv"}},
+		{Key: "archived", DisplayNames: map[LangCode]string{"en": "Archived"}},
+	}, d.Entries())
+	require.True(t, d.Has("archived"))
+	require.False(t, d.Has("deleted"))
+
+	name, ok := d.DisplayName("active", "de")
+	require.True(t, ok)
+	require.Equal(t, "Aktiv", name)
+	name, ok = d.DisplayName("archived", "de")
+	require.True(t, ok)
+	require.Equal(t, "Archived", name)
+	_, ok = d.DisplayName("deleted", "en")
+	require.False(t, ok)
+}
+
+func Test_ValidateDictionaryValues(t *testing.T) {
+	testsupp.InitLog(t)
+
+	entities := func(status string) string {
+		return strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+annotationTypes:
+  Projects: Project[]
+
+(Projects):
+- id: cti.x.y.project.v1.0~x.y.alpha.v1.0
+  status: `+status+`
+
+types:
+  Project:
+    (cti.cti): cti.x.y.project.v1.0
+    (cti.final): false
+    properties:
+      id:
+        type: cti.CTI
+        (cti.id): true
+      status:
+        type: string
+        (cti.dictionary): true
+`) + "\n"
+	}
+
+	for _, tc := range []struct {
+		status  string
+		wantErr string
+	}{
+		{status: "active"},
+		{status: "deleted", wantErr: `.status: value "deleted" is not an entry of the package dictionary`},
+	} {
+		t.Run(tc.status, func(t *testing.T) {
+			ptc := parserTestCase{
+				name:     "dictionary " + tc.status,
+				pkgId:    "x.y",
+				entities: []string{"entities.raml"},
+				files: map[string]string{
+					"entities.raml":        entities(tc.status),
+					"dictionaries/en.json": `{"active": "Active", "archived": "Archived"}`,
+				},
+			}
+			pkg, err := New(initParseTest(t, ptc), WithRamlxVersion("1.0"), WithID(ptc.pkgId), WithEntities(ptc.entities))
+			require.NoError(t, err)
+			require.NoError(t, pkg.Initialize())
+			require.NoError(t, pkg.Read())
+			pkg.Index.Dictionaries = []string{"dictionaries/en.json"}
+
+			err = pkg.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
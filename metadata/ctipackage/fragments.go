@@ -0,0 +1,41 @@
+package ctipackage
+
+import (
+	"path/filepath"
+
+	"github.com/acronis/go-raml"
+)
+
+// WithRamlFragments makes the package retain the RAML document it is parsed from,
+// so RamlFragments is available after Parse without parsing RAML again.
+func WithRamlFragments() InitializeOption {
+	return func(pkg *Package) error {
+		pkg.retainRaml = true
+		return nil
+	}
+}
+
+// RamlFragments returns RAML fragments (libraries, data types and named examples) of the last Parse or ParseOnly
+// keyed by slash-separated paths relative to the package directory, e.g. for custom analyzers of the sources.
+// Fragments of dependencies that are used by the package are included with paths under the dependency directory.
+// Returns nil if the package is not parsed or is created without WithRamlFragments.
+//
+// Fragments are shared with the collected entities and with RamlCache, so they must not be modified.
+func (pkg *Package) RamlFragments() map[string]raml.Fragment {
+	if pkg.ramlDoc == nil {
+		return nil
+	}
+	fragments := make(map[string]raml.Fragment)
+	for _, path := range fragmentPaths(pkg.ramlDoc) {
+		frag := pkg.ramlDoc.GetFragment(path)
+		if frag == nil {
+			continue
+		}
+		rel, err := filepath.Rel(pkg.BaseDir, path)
+		if err != nil {
+			rel = path
+		}
+		fragments[filepath.ToSlash(rel)] = frag
+	}
+	return fragments
+}
@@ -0,0 +1,56 @@
+package ctipackage
+
+import (
+	"testing"
+
+	"github.com/acronis/go-raml"
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_RamlFragments(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "raml fragments",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": `#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+`},
+	}
+	baseDir := initParseTest(t, tc)
+	pkg, err := New(baseDir,
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+
+	parse := func(opts ...InitializeOption) *Package {
+		pkg, err := New(baseDir, opts...)
+		require.NoError(t, err)
+		require.NoError(t, pkg.Read())
+		require.Nil(t, pkg.RamlFragments())
+		require.NoError(t, pkg.Parse())
+		return pkg
+	}
+
+	require.Nil(t, parse().RamlFragments(), "fragments are retained only on demand")
+
+	fragments := parse(WithRamlFragments()).RamlFragments()
+	require.Contains(t, fragments, ".ramlx/cti.raml")
+	lib, ok := fragments["entities.raml"].(*raml.Library)
+	require.True(t, ok)
+	event, ok := lib.Types.Get("Event")
+	require.True(t, ok)
+	require.Equal(t, "Event", event.Shape.Base().Name)
+}
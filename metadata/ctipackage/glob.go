@@ -0,0 +1,123 @@
+package ctipackage
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsPattern reports whether the path of the index is a glob pattern, e.g. types/**/*.raml.
+// Patterns use the syntax of path.Match for each path element, and ** matches any number of directories.
+func IsPattern(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// checkPattern returns an error if the pattern is malformed.
+func checkPattern(pattern string) error {
+	for _, elem := range strings.Split(pattern, "/") {
+		if elem == "**" {
+			continue
+		}
+		if _, err := path.Match(elem, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchPattern reports whether the slash-separated path matches the pattern.
+func matchPattern(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) != 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// EntityFiles returns entity files of the package with patterns of the index expanded
+// into matching files of the package directory sorted by path. Hidden directories, e.g. dependencies, are not matched.
+// Files that are listed explicitly are returned as is even if they do not exist.
+func (pkg *Package) EntityFiles() ([]string, error) {
+	var files []string
+	seen := make(map[string]struct{})
+	add := func(file string) {
+		if _, ok := seen[file]; ok {
+			return
+		}
+		seen[file] = struct{}{}
+		files = append(files, file)
+	}
+
+	var all []string
+	for _, entity := range pkg.Index.Entities {
+		if !IsPattern(entity) {
+			add(entity)
+			continue
+		}
+		if all == nil {
+			var err error
+			if all, err = listPackageFiles(pkg.BaseDir); err != nil {
+				return nil, err
+			}
+		}
+		matched := false
+		for _, file := range all {
+			if matchPattern(entity, file) {
+				add(file)
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("entities pattern %s matches no files", entity)
+		}
+	}
+	return files, nil
+}
+
+// listPackageFiles returns slash-separated paths of files of the package directory relative to it sorted by path.
+// Hidden directories are skipped.
+func listPackageFiles(baseDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != baseDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", baseDir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
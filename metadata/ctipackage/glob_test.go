@@ -0,0 +1,82 @@
+package ctipackage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_MatchPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{pattern: "types/**/*.raml", name: "types/a.raml", match: true},
+		{pattern: "types/**/*.raml", name: "types/x/y/a.raml", match: true},
+		{pattern: "types/*.raml", name: "types/x/a.raml"},
+		{pattern: "**/*.raml", name: "a.raml", match: true},
+		{pattern: "types/a.raml", name: "types/a.raml", match: true},
+		{pattern: "types/**/*.raml", name: "instances/a.raml"},
+	} {
+		require.Equal(t, tc.match, matchPattern(tc.pattern, tc.name), "%s %s", tc.pattern, tc.name)
+	}
+}
+
+func Test_ParseOnly(t *testing.T) {
+	testsupp.InitLog(t)
+
+	entity := func(name string) string {
+		return strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: ../../.ramlx/cti.raml
+
+types:
+  Entity:
+    (cti.cti): cti.x.y.`+name+`.v1.0
+    type: object
+`) + "\n"
+	}
+	tc := parserTestCase{
+		name:     "glob",
+		pkgId:    "x.y",
+		entities: []string{"types/**/*.raml"},
+		files: map[string]string{
+			"types/alerts/alert.raml": entity("alert"),
+			"types/events/event.raml": entity("event"),
+			"types/events/notes.txt":  "not an entity",
+			"types/.hidden/skip.raml": entity("skip"),
+		},
+	}
+
+	baseDir := initParseTest(t, tc)
+	pkg, err := New(baseDir, WithRamlxVersion("1.0"), WithID(tc.pkgId), WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+
+	files, err := pkg.EntityFiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{"types/alerts/alert.raml", "types/events/event.raml"}, files)
+
+	require.NoError(t, pkg.ParseOnly("types/events/*.raml"))
+	require.Len(t, pkg.LocalRegistry.Index, 1)
+	require.Contains(t, pkg.LocalRegistry.Index, "cti.x.y.event.v1.0")
+	_, err = os.Stat(filepath.Join(baseDir, MetadataCacheFile))
+	require.True(t, os.IsNotExist(err))
+
+	require.ErrorContains(t, pkg.ParseOnly("apis/*.raml"), "no entity files match apis/*.raml")
+
+	require.NoError(t, pkg.Parse())
+	require.Len(t, pkg.LocalRegistry.Index, 2)
+
+	pkg.Index.Entities = []string{"apis/**/*.raml"}
+	require.ErrorContains(t, pkg.Parse(), "entities pattern apis/**/*.raml matches no files")
+}
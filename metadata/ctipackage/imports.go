@@ -0,0 +1,303 @@
+package ctipackage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-cti/metadata/ramlx"
+)
+
+const (
+	ImportKindEntities = "entities"
+	ImportKindUses     = "uses"
+	ImportKindInclude  = "include"
+
+	maxImportCandidates = 3
+)
+
+// ErrUnresolvedImport is matched by errors of packages whose fragments reference missing files, see ImportError.
+var ErrUnresolvedImport = errors.New("unresolved import")
+
+// ImportIssue is a reference to a file that does not exist: the library of `uses`, the file of `!include`
+// or the entity file of the index.
+type ImportIssue struct {
+	// File is the path of the referencing file relative to the package directory.
+	File string
+	// Line is the line of the reference in the file. It is zero for entity files of the index.
+	Line   int
+	Kind   string
+	Target string
+	// Candidates are existing files with the nearest paths to the target, written as they would be referenced
+	// from the file.
+	Candidates []string
+}
+
+func (i ImportIssue) Error() string {
+	var sb strings.Builder
+	sb.WriteString(i.File)
+	if i.Line != 0 {
+		fmt.Fprintf(&sb, ":%d", i.Line)
+	}
+	fmt.Fprintf(&sb, ": %s target %s not found", i.Kind, i.Target)
+	if len(i.Candidates) != 0 {
+		fmt.Fprintf(&sb, ", did you mean %s?", strings.Join(i.Candidates, " or "))
+	}
+	return sb.String()
+}
+
+// ImportError is returned when fragments of the package reference missing files.
+type ImportError struct {
+	Issues []ImportIssue
+}
+
+func (e *ImportError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		msgs[i] = issue.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether the target is ErrUnresolvedImport.
+func (e *ImportError) Is(target error) bool {
+	return target == ErrUnresolvedImport
+}
+
+// CheckImports resolves `uses` and `!include` of the entity files of the package and of the fragments they reference
+// without parsing the RAML. Parse runs the check before parsing, since the RAML parser reports missing files
+// without the referencing location. Remote references are not checked.
+func (pkg *Package) CheckImports() ([]ImportIssue, error) {
+	entities, err := pkg.EntityFiles()
+	if err != nil {
+		return nil, err
+	}
+	return pkg.checkImports(entities)
+}
+
+func (pkg *Package) checkImports(entities []string) ([]ImportIssue, error) {
+	var issues []ImportIssue
+	var candidates []string
+	suggest := func(from, target string) []string {
+		if candidates == nil {
+			var err error
+			if candidates, err = listFragmentFiles(pkg.BaseDir); err != nil {
+				return nil
+			}
+		}
+		return nearestPaths(pkg.BaseDir, from, target, candidates)
+	}
+
+	visited := make(map[string]struct{})
+	var queue []string
+	for _, entity := range entities {
+		path := filepath.Join(pkg.BaseDir, entity)
+		if !fileExists(path) {
+			issues = append(issues, ImportIssue{
+				File:       IndexFileName,
+				Kind:       ImportKindEntities,
+				Target:     entity,
+				Candidates: suggest(pkg.BaseDir, path),
+			})
+			continue
+		}
+		queue = append(queue, path)
+	}
+
+	for len(queue) != 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[path]; ok {
+			continue
+		}
+		visited[path] = struct{}{}
+
+		refs, err := readFragmentRefs(pkg.fragmentCache(), path)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(pkg.BaseDir, path)
+		for _, ref := range refs {
+			if strings.Contains(ref.target, "://") {
+				continue
+			}
+			target := filepath.Join(filepath.Dir(path), ref.target)
+			if !fileExists(target) {
+				issues = append(issues, ImportIssue{
+					File:       filepath.ToSlash(rel),
+					Line:       ref.line,
+					Kind:       ref.kind,
+					Target:     ref.target,
+					Candidates: suggest(filepath.Dir(path), target),
+				})
+				continue
+			}
+			if filepath.Ext(target) == ".raml" {
+				queue = append(queue, target)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// resolveImports returns ImportError if the entity files or fragments they reference are missing.
+func (pkg *Package) resolveImports(entities []string) error {
+	issues, err := pkg.checkImports(entities)
+	if err != nil {
+		return fmt.Errorf("check imports: %w", err)
+	}
+	if len(issues) != 0 {
+		return &ImportError{Issues: issues}
+	}
+	return nil
+}
+
+type fragmentRef struct {
+	kind   string
+	target string
+	line   int
+}
+
+// readFragmentRefs returns libraries of `uses` and files of `!include` of the RAML fragment.
+// Fragments that are not valid YAML are skipped, so the RAML parser reports the syntax error.
+func readFragmentRefs(fragments *ramlx.FragmentCache, path string) ([]fragmentRef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	doc, err := fragments.Decode(data)
+	if err != nil || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	var refs []fragmentRef
+	root := doc.Content[0]
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "uses" || root.Content[i+1].Kind != yaml.MappingNode {
+				continue
+			}
+			uses := root.Content[i+1]
+			for j := 1; j < len(uses.Content); j += 2 {
+				lib := uses.Content[j]
+				if lib.Kind == yaml.ScalarNode && lib.Tag != "!include" {
+					refs = append(refs, fragmentRef{kind: ImportKindUses, target: lib.Value, line: lib.Line})
+				}
+			}
+		}
+	}
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.ScalarNode && node.Tag == "!include" {
+			refs = append(refs, fragmentRef{kind: ImportKindInclude, target: node.Value, line: node.Line})
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+	return refs, nil
+}
+
+// listFragmentFiles returns files of the package directory that may be referenced by fragments.
+// Hidden directories are skipped except for RAMLx specification, so dependencies are not suggested.
+func listFragmentFiles(baseDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != baseDir && strings.HasPrefix(d.Name(), ".") && d.Name() != RamlxDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", baseDir, err)
+	}
+	return files, nil
+}
+
+// nearestPaths returns up to maxImportCandidates files with the same extension as the target
+// and the nearest paths by edit distance. Paths are relative to the directory of the referencing file.
+func nearestPaths(baseDir, fromDir, target string, files []string) []string {
+	type candidate struct {
+		path     string
+		distance int
+	}
+	relTarget, _ := filepath.Rel(baseDir, target)
+	threshold := len(relTarget) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	var found []candidate
+	for _, file := range files {
+		if filepath.Ext(file) != filepath.Ext(target) {
+			continue
+		}
+		relFile, _ := filepath.Rel(baseDir, file)
+		if d := editDistance(relTarget, relFile); d <= threshold {
+			found = append(found, candidate{path: file, distance: d})
+		}
+	}
+	sort.Slice(found, func(a, b int) bool {
+		if found[a].distance != found[b].distance {
+			return found[a].distance < found[b].distance
+		}
+		return found[a].path < found[b].path
+	})
+
+	var res []string
+	for i := 0; i < len(found) && i < maxImportCandidates; i++ {
+		rel, err := filepath.Rel(fromDir, found[i].path)
+		if err != nil {
+			continue
+		}
+		res = append(res, filepath.ToSlash(rel))
+	}
+	return res
+}
+
+// editDistance returns Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
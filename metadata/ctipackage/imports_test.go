@@ -0,0 +1,66 @@
+package ctipackage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_CheckImports(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "imports",
+		pkgId:    "x.y",
+		entities: []string{"entities/events.raml", "entities/topic.raml"},
+		files: map[string]string{
+			"entities/events.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: ../.ramlx/cti.raml
+  common: ../lib/comon.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+    properties:
+      payload: !include ../schemas/payload.json
+`) + "\n",
+			"lib/common.raml":      "#%RAML 1.0 Library\n",
+			"schemas/payload.json": `{"type": "object"}`,
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc), WithRamlxVersion("1.0"), WithID(tc.pkgId), WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+
+	err = pkg.Parse()
+	var importErr *ImportError
+	require.True(t, errors.As(err, &importErr))
+	require.ErrorIs(t, err, ErrUnresolvedImport)
+	require.Equal(t, []ImportIssue{
+		{
+			File:       IndexFileName,
+			Kind:       ImportKindEntities,
+			Target:     "entities/topic.raml",
+			Candidates: []string{"entities/events.raml"},
+		},
+		{
+			File:       "entities/events.raml",
+			Line:       5,
+			Kind:       ImportKindUses,
+			Target:     "../lib/comon.raml",
+			Candidates: []string{"../lib/common.raml"},
+		},
+	}, importErr.Issues)
+	require.Equal(t, "entities/events.raml:5: uses target ../lib/comon.raml not found, did you mean ../lib/common.raml?",
+		importErr.Issues[1].Error())
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/acronis/go-cti/metadata/filesys"
+	"github.com/acronis/go-cti/metadata/validator"
 )
 
 const (
@@ -28,6 +29,17 @@ type Index struct {
 	Examples             []string          `json:"examples,omitempty"`
 	AdditionalProperties interface{}       `json:"additional_properties,omitempty"`
 	Serialized           []string          `json:"serialized,omitempty"`
+	// Tags maps a tag to CTI expressions of the package entities that are tagged with it.
+	Tags map[string][]string `json:"tags,omitempty"`
+	// Owners are teams or emails that own the package entities which do not declare cti.owners.
+	Owners []string `json:"owners,omitempty"`
+	// Coexistence configures checks of CTI types that exist in several major versions.
+	Coexistence *validator.CoexistencePolicy `json:"coexistence,omitempty"`
+	// IDUniqueness is the scope where values of properties annotated with cti.id must be unique.
+	// Defaults to the package.
+	IDUniqueness validator.UniquenessScope `json:"id_uniqueness,omitempty"`
+	// Provenance is recorded by the packer into the index of the bundle.
+	Provenance *Provenance `json:"provenance,omitempty"`
 }
 
 func ReadIndex(dirPath string) (*Index, error) {
@@ -78,6 +90,9 @@ func (idx *Index) Check() error {
 		if ext := filepath.Ext(p); ext != RAMLExt {
 			return fmt.Errorf("$.entities[%d]: invalid entity extension: %s", i, ext)
 		}
+		if err := checkPattern(p); err != nil {
+			return fmt.Errorf("$.entities[%d]: invalid entity pattern: %w", i, err)
+		}
 	}
 	for i, p := range idx.Examples {
 		if p == "" {
@@ -87,6 +102,27 @@ func (idx *Index) Check() error {
 			return fmt.Errorf("$.examples[%d]: invalid example extension: %s", i, ext)
 		}
 	}
+	for tag, exprs := range idx.Tags {
+		if tag == "" {
+			return fmt.Errorf("$.tags: tag cannot be empty")
+		}
+		for i, expr := range exprs {
+			if expr == "" {
+				return fmt.Errorf("$.tags.%s[%d]: cti expression cannot be empty", tag, i)
+			}
+		}
+	}
+	for i, owner := range idx.Owners {
+		if !strings.Contains(owner, "@") || strings.ContainsAny(owner, " \t") {
+			return fmt.Errorf("$.owners[%d]: owner must be a team (@org/team), a user (@user) or an email: %q", i, owner)
+		}
+	}
+	switch idx.IDUniqueness {
+	case "", validator.UniquenessScopePackage, validator.UniquenessScopeRegistry:
+	default:
+		return fmt.Errorf("$.id_uniqueness: invalid scope %q, expect %s or %s",
+			idx.IDUniqueness, validator.UniquenessScopePackage, validator.UniquenessScopeRegistry)
+	}
 	if idx.PackageID == "" {
 		return fmt.Errorf("package id is missing")
 	}
@@ -110,6 +146,13 @@ func (idx *Index) GenerateIndexRaml(includeExamples bool) string {
 	return sb.String()
 }
 
+// IsMetadataOnly returns true if the package does not define entities.
+// Such packages only aggregate dependencies or publish assets and dictionaries.
+// They are read, parsed and validated as regular packages and produce empty registries.
+func (idx *Index) IsMetadataOnly() bool {
+	return len(idx.Entities) == 0
+}
+
 func (idx *Index) Clone() *Index {
 	c := *idx
 	return &c
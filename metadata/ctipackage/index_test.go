@@ -141,6 +141,38 @@ func Test_IndexCheck(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "EmptyTag",
+			index: Index{
+				PackageID: "test.pkg",
+				Tags:      map[string][]string{"": {"cti.x.y.*"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "EmptyTagExpression",
+			index: Index{
+				PackageID: "test.pkg",
+				Tags:      map[string][]string{"billing": {""}},
+			},
+			expectError: true,
+		},
+		{
+			name: "InvalidOwner",
+			index: Index{
+				PackageID: "test.pkg",
+				Owners:    []string{"billing team"},
+			},
+			expectError: true,
+		},
+		{
+			name: "InvalidIDUniqueness",
+			index: Index{
+				PackageID:    "test.pkg",
+				IDUniqueness: "global",
+			},
+			expectError: true,
+		},
 		{
 			name: "MissingPackageID",
 			index: Index{
@@ -36,6 +36,9 @@ type Info struct {
 	Source          string            `json:"source"`
 	SourceIntegrity string            `json:"source_integrity"`
 	Depends         map[string]string `json:"depends"`
+	// Checksum is the SHA-256 hash of the package content in the "h1:" format of go.sum files.
+	// Downloads of the same version must match it.
+	Checksum string `json:"checksum,omitempty"`
 }
 
 func ReadIndexLock(pkgDir string) (*IndexLock, error) {
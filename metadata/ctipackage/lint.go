@@ -0,0 +1,26 @@
+package ctipackage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/linter"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+// Lint checks style of entities of the package with the rules, linter.DefaultRules if none are specified.
+// Entities of dependencies are not checked.
+func (pkg *Package) Lint(rules ...validator.Rule) ([]validator.Issue, error) {
+	if err := pkg.Parse(); err != nil {
+		return nil, fmt.Errorf("parse package: %w", err)
+	}
+	if len(rules) == 0 {
+		rules = linter.DefaultRules(pkg.BaseDir)
+	}
+	entities := make([]*metadata.Entity, 0, len(pkg.LocalRegistry.Index))
+	for _, entity := range pkg.LocalRegistry.Index {
+		entities = append(entities, entity)
+	}
+	return linter.Lint(context.Background(), pkg.GlobalRegistry, entities, rules...), nil
+}
@@ -8,8 +8,10 @@ import (
 	"path"
 	"path/filepath"
 
+	"github.com/acronis/go-cti/metadata/assetstore"
 	"github.com/acronis/go-cti/metadata/collector"
-	"github.com/acronis/go-cti/metadata/filesys"
+	"github.com/acronis/go-cti/metadata/ramlx"
+	"github.com/acronis/go-raml"
 )
 
 const (
@@ -24,6 +26,17 @@ type Package struct {
 	GlobalRegistry *collector.MetadataRegistry
 
 	BaseDir string
+
+	progress     ProgressReporter
+	ramlCache    *RamlCache
+	fragments    *ramlx.FragmentCache
+	assets       assetstore.Store
+	maxAssetSize int64
+	// conversionHooks customize schemas of CTI types of the package and its dependencies.
+	conversionHooks []collector.ConversionHook
+	// retainRaml makes the package keep ramlDoc, the RAML document of the last parse, see RamlFragments.
+	retainRaml bool
+	ramlDoc    *raml.RAML
 }
 
 // New creates a new package from the specified path.
@@ -80,6 +93,15 @@ func WithEntities(entities []string) InitializeOption {
 	}
 }
 
+// WithConversionHooks makes the package customize JSON schemas of CTI types converted from RAML with the hooks,
+// e.g. to attach vendor x- keywords. Types of dependencies are customized as well.
+func WithConversionHooks(hooks ...collector.ConversionHook) InitializeOption {
+	return func(pkg *Package) error {
+		pkg.conversionHooks = append(pkg.conversionHooks, hooks...)
+		return nil
+	}
+}
+
 func (pkg *Package) Read() error {
 	idx, err := ReadIndex(pkg.BaseDir)
 	if err != nil {
@@ -110,26 +132,13 @@ func (pkg *Package) SaveIndex() error {
 }
 
 func (pkg *Package) GetDictionaries() (Dictionaries, error) {
-	dictionaries := Dictionaries{
-		Dictionaries: make(map[LangCode]Entry),
-	}
-
-	for _, dict := range pkg.Index.Dictionaries {
-		file, err := os.Open(path.Join(pkg.BaseDir, dict))
-		if err != nil {
-			return Dictionaries{}, fmt.Errorf("open dictionary file: %w", err)
-		}
-		defer file.Close()
-
-		entry, err := validateDictionary(file)
-		if err != nil {
-			return Dictionaries{}, fmt.Errorf("validate dictionary: %w", err)
-		}
-		lang := filesys.GetBaseName(file.Name())
-		dictionaries.Dictionaries[LangCode(lang)] = entry
+	dictionary, err := readDictionary(pkg.Index, func(name string) (io.ReadCloser, error) {
+		return os.Open(path.Join(pkg.BaseDir, name))
+	})
+	if err != nil {
+		return Dictionaries{}, err
 	}
-
-	return dictionaries, nil
+	return Dictionaries{Dictionaries: dictionary}, nil
 }
 
 func validateDictionary(input io.Reader) (Entry, error) {
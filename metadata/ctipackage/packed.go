@@ -0,0 +1,190 @@
+package ctipackage
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/assetstore"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+const (
+	// PackedExtension is the file extension of single-file bundles made by Package.Pack.
+	PackedExtension = ".ctib"
+	// PackedVersion is the version of the format of single-file bundles.
+	PackedVersion = 1
+
+	// maxPackedSize limits the size of the decompressed single-file bundle.
+	maxPackedSize = 1 << 30 // 1 GB
+)
+
+// AssetInfo describes an asset of the package in the manifest of the single-file bundle.
+type AssetInfo struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// Digest is a SHA-256 checksum of the asset content in the form of sha256:<hex>.
+	Digest string `json:"digest"`
+}
+
+// Packed is a parsed package loaded from a single-file bundle by Unpack.
+type Packed struct {
+	Index *Index
+	// Registry holds entities of the package and its dependencies.
+	Registry *collector.MetadataRegistry
+	// Schemas are merged schemas of the types by their CTIs.
+	Schemas map[string]map[string]any
+	// Assets is a manifest of the assets of the package.
+	Assets []AssetInfo
+	// Provenance describes how the bundle was built. It is nil if the bundle was packed without it.
+	Provenance *Provenance
+}
+
+// packed is the serialized form of the single-file bundle.
+type packed struct {
+	Version  int                       `json:"version"`
+	Index    *Index                    `json:"index"`
+	Entities []*metadata.Entity        `json:"entities"`
+	Schemas  map[string]map[string]any `json:"schemas"`
+	Assets   []AssetInfo               `json:"assets"`
+	// Provenance is kept next to the entities rather than in the index, so it describes the packed registry.
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+type packConfig struct {
+	provenance *Provenance
+}
+
+type PackOption func(*packConfig)
+
+// WithPackProvenance records the provenance (see ComputeProvenance) into the single-file bundle.
+func WithPackProvenance(p *Provenance) PackOption {
+	return func(c *packConfig) {
+		c.provenance = p
+	}
+}
+
+// Pack writes the parsed package as a single-file bundle (see PackedExtension): a gzip-compressed JSON document
+// with the index, entities of the package and its dependencies, merged schemas of the types and the manifest of
+// the assets. The bundle is loaded back by Unpack without the RAML sources, so it may be distributed to runtime
+// services. The output is deterministic for the same package and provenance.
+func (pkg *Package) Pack(w io.Writer, opts ...PackOption) error {
+	var cfg packConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if pkg.GlobalRegistry == nil {
+		return fmt.Errorf("package is not parsed")
+	}
+	r := pkg.GlobalRegistry
+
+	p := packed{
+		Version:    PackedVersion,
+		Index:      pkg.Index,
+		Entities:   make([]*metadata.Entity, 0, len(r.Index)),
+		Schemas:    make(map[string]map[string]any, len(r.Types)),
+		Assets:     make([]AssetInfo, 0, len(pkg.Index.Assets)),
+		Provenance: cfg.provenance,
+	}
+	for _, entity := range r.Index {
+		p.Entities = append(p.Entities, entity)
+	}
+	sort.Slice(p.Entities, func(a, b int) bool {
+		return p.Entities[a].Cti < p.Entities[b].Cti
+	})
+
+	schemas := merger.NewSchemaCache(r)
+	for id := range r.Types {
+		schema, err := schemas.GetMergedCtiSchema(id)
+		if err != nil {
+			return fmt.Errorf("get merged schema of %s: %w", id, err)
+		}
+		p.Schemas[id] = schema
+	}
+
+	store := pkg.AssetStore()
+	for _, name := range pkg.Index.Assets {
+		asset, err := readAssetInfo(store, name)
+		if err != nil {
+			return err
+		}
+		p.Assets = append(p.Assets, asset)
+	}
+
+	gzw := gzip.NewWriter(w)
+	if err := json.NewEncoder(gzw).Encode(p); err != nil {
+		gzw.Close()
+		return fmt.Errorf("encode bundle: %w", err)
+	}
+	if err := gzw.Close(); err != nil {
+		return fmt.Errorf("compress bundle: %w", err)
+	}
+	return nil
+}
+
+func readAssetInfo(store assetstore.Store, name string) (AssetInfo, error) {
+	f, err := store.Open(context.Background(), name)
+	if err != nil {
+		return AssetInfo{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return AssetInfo{}, fmt.Errorf("read asset %s: %w", name, err)
+	}
+	return AssetInfo{Name: name, Size: size, Digest: "sha256:" + hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Unpack loads the single-file bundle written by Package.Pack.
+func Unpack(r io.Reader) (*Packed, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("create gzip reader: %w", err)
+	}
+	defer gzr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(gzr, maxPackedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress bundle: %w", err)
+	}
+	if len(data) > maxPackedSize {
+		return nil, fmt.Errorf("bundle too large")
+	}
+
+	var p packed
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+	if p.Version != PackedVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, expected %d", p.Version, PackedVersion)
+	}
+	if p.Index == nil {
+		return nil, fmt.Errorf("bundle has no index")
+	}
+	if err := p.Index.Check(); err != nil {
+		return nil, fmt.Errorf("check index: %w", err)
+	}
+
+	res := &Packed{
+		Index:      p.Index,
+		Registry:   collector.NewMetadataRegistry(),
+		Schemas:    p.Schemas,
+		Assets:     p.Assets,
+		Provenance: p.Provenance,
+	}
+	for _, entity := range p.Entities {
+		if err := res.Registry.Add(entity.SourceMap.OriginalPath, entity); err != nil {
+			return nil, fmt.Errorf("add cti entity: %w", err)
+		}
+	}
+	return res, nil
+}
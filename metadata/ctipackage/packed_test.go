@@ -0,0 +1,90 @@
+package ctipackage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_PackUnpack(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "packed",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{
+			"entities.raml": `#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+annotationTypes:
+  Events:
+    type: Event[]
+
+(Events):
+  - id: cti.x.y.event.v1.0~x.y.created.v1.0
+    name: created
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+    properties:
+      id:
+        type: cti.CTI
+        (cti.id): true
+      name: string
+`,
+			"assets/logo.svg": "<svg/>",
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	pkg.Index.Assets = []string{"assets/logo.svg"}
+
+	require.ErrorContains(t, pkg.Pack(&bytes.Buffer{}), "package is not parsed")
+	require.NoError(t, pkg.Parse())
+
+	var buf, again bytes.Buffer
+	require.NoError(t, pkg.Pack(&buf))
+	require.NoError(t, pkg.Pack(&again))
+	require.Equal(t, buf.Bytes(), again.Bytes(), "bundle is deterministic")
+
+	p, err := Unpack(&buf)
+	require.NoError(t, err)
+	require.Nil(t, p.Provenance)
+	require.Equal(t, "x.y", p.Index.PackageID)
+	require.Len(t, p.Registry.Types, 1)
+	require.Len(t, p.Registry.Instances, 1)
+	require.Contains(t, p.Registry.Index, "cti.x.y.event.v1.0~x.y.created.v1.0")
+	require.Len(t, p.Registry.FragmentEntities["entities.raml"], 2)
+	require.Contains(t, p.Schemas, "cti.x.y.event.v1.0")
+	require.Equal(t, []AssetInfo{{
+		Name:   "assets/logo.svg",
+		Size:   6,
+		Digest: "sha256:d4dc56669143034f31aa309635d4113d9ad76a02b1739da22c965ed2049be9e6",
+	}}, p.Assets)
+
+	var unsupported bytes.Buffer
+	gzw := gzip.NewWriter(&unsupported)
+	_, err = gzw.Write([]byte(`{"version": 100}`))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+	_, err = Unpack(&unsupported)
+	require.ErrorContains(t, err, "unsupported bundle version 100")
+
+	_, err = Unpack(bytes.NewReader([]byte("not a bundle")))
+	require.Error(t, err)
+}
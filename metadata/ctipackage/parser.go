@@ -1,52 +1,121 @@
 package ctipackage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/acronis/go-cti"
 	"github.com/acronis/go-cti/metadata"
 	"github.com/acronis/go-cti/metadata/collector"
-	"github.com/acronis/go-raml"
 )
 
 const (
 	MetadataCacheFile = ".cache.json"
+	// DependenciesCacheFile holds entities of the dependencies of the package in bundles made by the packer,
+	// so bundles may be validated and queried without installing the dependencies.
+	DependenciesCacheFile = ".dep.cache.json"
 )
 
 func (pkg *Package) Parse() error {
+	return pkg.ParseContext(context.Background())
+}
+
+// ParseContext is like Parse, but stops with the error of the context when the context is done,
+// e.g. to abort parsing of huge packages by servers or CI jobs with timeouts.
+func (pkg *Package) ParseContext(ctx context.Context) error {
+	entities, err := pkg.EntityFiles()
+	if err != nil {
+		return fmt.Errorf("resolve entity files: %w", err)
+	}
+	return pkg.parseEntities(ctx, entities, true)
+}
+
+// ParseOnly parses dependencies of the package and only the entity files of the package that match the patterns,
+// e.g. to iterate faster on a subset of a huge package. Patterns have the same syntax as entities of the index
+// and are relative to the package directory.
+// Registries of the package are partial, so the cache of the package is not written.
+func (pkg *Package) ParseOnly(patterns ...string) error {
+	entities, err := pkg.EntityFiles()
+	if err != nil {
+		return fmt.Errorf("resolve entity files: %w", err)
+	}
+	var selected []string
+	for _, entity := range entities {
+		for _, pattern := range patterns {
+			if matchPattern(filepath.ToSlash(filepath.Clean(pattern)), entity) {
+				selected = append(selected, entity)
+				break
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no entity files match %s", strings.Join(patterns, ", "))
+	}
+	return pkg.parseEntities(context.Background(), selected, false)
+}
+
+func (pkg *Package) parseEntities(ctx context.Context, entities []string, dumpCache bool) (err error) {
+	tracker := pkg.startPhase(PhaseParse, len(pkg.IndexLock.SourceInfo)+1)
+	defer func() { tracker.finish(err) }()
+
 	c := collector.New()
+	// Dependencies share the fragment cache, so fragments they have in common are decoded once.
+	c.SetFragmentCache(pkg.fragmentCache())
+	for _, h := range pkg.conversionHooks {
+		c.AddConversionHook(h)
+	}
 	// TODO: This will work only for top-level packages. Need to handle nested dependencies.
 	for _, dep := range pkg.IndexLock.SourceInfo {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		depIndexFile := filepath.Join(pkg.BaseDir, DependencyDirName, dep.PackageID)
 		// FIXME: Need a proper detection of the package type.
 		if strings.Contains(pkg.BaseDir, "/.dep/") {
 			depIndexFile = filepath.Join(pkg.BaseDir, "..", dep.PackageID)
 		}
-		depPkg, err := New(depIndexFile)
+		depPkg, err := New(depIndexFile, WithRamlCache(pkg.ramlCache), WithFragmentCache(pkg.fragmentCache()))
 		if err != nil {
 			return fmt.Errorf("new package: %w", err)
 		}
 		if err = depPkg.Read(); err != nil {
 			return fmt.Errorf("read package: %w", err)
 		}
-		err = depPkg.parse(c, false)
+		depEntities, err := depPkg.EntityFiles()
+		if err != nil {
+			return fmt.Errorf("resolve entity files of dependent package: %w", err)
+		}
+		err = depPkg.parse(ctx, c, depEntities, false)
 		if err != nil {
 			return fmt.Errorf("parse dependent package: %w", err)
 		}
+		tracker.itemDone(dep.PackageID)
 	}
 
-	err := pkg.parse(c, true)
-	if err != nil {
+	if err := pkg.parse(ctx, c, entities, true); err != nil {
 		return fmt.Errorf("parse dependent package: %w", err)
 	}
+	tracker.itemDone(pkg.Index.PackageID)
 	pkg.LocalRegistry = c.LocalRegistry
 	pkg.GlobalRegistry = c.GlobalRegistry
 
-	// TODO: Maybe need an option to parse without dumping cache?
+	if err := pkg.applyIndexTags(); err != nil {
+		return fmt.Errorf("apply index tags: %w", err)
+	}
+
+	if err := pkg.applyIndexOwners(); err != nil {
+		return fmt.Errorf("apply index owners: %w", err)
+	}
+
+	if !dumpCache {
+		return nil
+	}
 	if err := pkg.DumpCache(); err != nil {
 		return fmt.Errorf("dump cache: %w", err)
 	}
@@ -54,26 +123,115 @@ func (pkg *Package) Parse() error {
 	return nil
 }
 
-func (pkg *Package) parse(c *collector.Collector, isLocal bool) error {
+func (pkg *Package) parse(ctx context.Context, c *collector.Collector, entities []string, isLocal bool) error {
 	// NOTE: Sync is mandatory before parse. Otherwise, parse may fail due to missing ramlx folder.
 	if err := pkg.Sync(); err != nil {
 		return fmt.Errorf("sync package: %w", err)
 	}
 
-	r, err := raml.ParseFromString(pkg.Index.GenerateIndexRaml(false), "index.raml", pkg.BaseDir, raml.OptWithValidate())
+	if len(entities) == 0 {
+		// Nothing to collect, the package contributes only its dependencies and assets.
+		slog.Debug("Package has no entities", slog.String("id", pkg.Index.PackageID))
+		return nil
+	}
+
+	if err := pkg.resolveImports(entities); err != nil {
+		return err
+	}
+
+	idx := pkg.Index.Clone()
+	idx.Entities = entities
+	r, err := pkg.parseRaml(idx.GenerateIndexRaml(false))
 	if err != nil {
 		return fmt.Errorf("parse index.raml: %w", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	c.SetRaml(r)
-	if err := c.Collect(isLocal); err != nil {
+	if err := c.CollectContext(ctx, isLocal); err != nil {
 		return fmt.Errorf("collect from package: %w", err)
 	}
+	if pkg.retainRaml {
+		pkg.ramlDoc = r
+	}
+	return nil
+}
+
+// applyIndexTags assigns tags from the index to the matching entities declared by the package.
+func (pkg *Package) applyIndexTags() error {
+	if len(pkg.Index.Tags) == 0 {
+		return nil
+	}
+	p := cti.NewParser()
+	for tag, exprs := range pkg.Index.Tags {
+		for _, raw := range exprs {
+			expr, err := p.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("parse %s of tag %s: %w", raw, tag, err)
+			}
+			for id := range pkg.LocalRegistry.Index {
+				entityExpr, err := p.Parse(id)
+				if err != nil {
+					return fmt.Errorf("parse %s: %w", id, err)
+				}
+				ok, err := expr.Match(entityExpr)
+				if err != nil {
+					return fmt.Errorf("match %s of tag %s: %w", raw, tag, err)
+				}
+				if !ok {
+					continue
+				}
+				if err := pkg.LocalRegistry.AddTags(id, tag); err != nil {
+					return err
+				}
+				if err := pkg.GlobalRegistry.AddTags(id, tag); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyIndexOwners assigns owners from the index to the entities declared by the package that have no owners.
+func (pkg *Package) applyIndexOwners() error {
+	if len(pkg.Index.Owners) == 0 {
+		return nil
+	}
+	for id, entity := range pkg.LocalRegistry.Index {
+		if len(entity.Owners) != 0 {
+			continue
+		}
+		if err := pkg.LocalRegistry.AddOwners(id, pkg.Index.Owners...); err != nil {
+			return err
+		}
+		if err := pkg.GlobalRegistry.AddOwners(id, pkg.Index.Owners...); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// MarshalDependencies returns serialized entities of the dependencies of the parsed package sorted by CTI,
+// i.e. entities of the global registry that are not defined by the package itself.
+func (pkg *Package) MarshalDependencies() ([]byte, error) {
+	items := make([]*metadata.Entity, 0, len(pkg.GlobalRegistry.Index)-len(pkg.LocalRegistry.Index))
+	for id, entity := range pkg.GlobalRegistry.Index {
+		if _, ok := pkg.LocalRegistry.Index[id]; !ok {
+			items = append(items, entity)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool {
+		return items[a].Cti < items[b].Cti
+	})
+	return json.Marshal(items)
+}
+
 func (pkg *Package) DumpCache() error {
-	var items []*metadata.Entity
+	// Metadata-only packages produce an empty list rather than null.
+	items := make([]*metadata.Entity, 0, len(pkg.LocalRegistry.Index))
 	for _, v := range pkg.LocalRegistry.Index {
 		items = append(items, v)
 	}
@@ -89,6 +247,37 @@ func (pkg *Package) DumpCache() error {
 	return os.WriteFile(filepath.Join(pkg.BaseDir, MetadataCacheFile), bytes, 0600)
 }
 
+// ReadCache makes a registry of the entities of the package and of its dependencies from their cache files
+// written by Parse, without parsing RAML. Dependencies that have no cache file are skipped.
+// The registry is meant for fast lookups, e.g. completion of identifiers, and may be outdated.
+func (pkg *Package) ReadCache() (*collector.MetadataRegistry, error) {
+	files := []string{filepath.Join(pkg.BaseDir, MetadataCacheFile)}
+	for _, dep := range pkg.IndexLock.SourceInfo {
+		file := filepath.Join(pkg.BaseDir, DependencyDirName, dep.PackageID, MetadataCacheFile)
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+
+	r := collector.NewMetadataRegistry()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read cache file %s: %w", file, err)
+		}
+		var entities metadata.Entities
+		if err := json.Unmarshal(data, &entities); err != nil {
+			return nil, fmt.Errorf("decode cache file %s: %w", file, err)
+		}
+		for _, entity := range entities {
+			if err := r.Add(file, entity); err != nil {
+				return nil, fmt.Errorf("add entity %s: %w", entity.Cti, err)
+			}
+		}
+	}
+	return r, nil
+}
+
 // FIXME: Fix caching.
 // Currently it may not work in cases when extraneous cti.schema is used by the package
 // func (pkg *Package) ParseWithCache() (*collector.MetadataRegistry, error) {
@@ -1,6 +1,9 @@
 package ctipackage
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -9,7 +12,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
 	"github.com/acronis/go-cti/metadata/testsupp"
+	"github.com/acronis/go-cti/metadata/validator"
 	"github.com/acronis/go-stacktrace"
 	slogex "github.com/acronis/go-stacktrace/slogex"
 )
@@ -35,6 +42,77 @@ func Test_EmptyPackage(t *testing.T) {
 
 	require.NotNil(t, pkg.LocalRegistry)
 	require.Empty(t, pkg.LocalRegistry.Index)
+	require.True(t, pkg.Index.IsMetadataOnly())
+	require.NoError(t, pkg.Validate())
+
+	cache, err := os.ReadFile(filepath.Join(testPath, MetadataCacheFile))
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(cache))
+}
+
+func Test_MetadataOnlyPackage(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:  "metadata only",
+		pkgId: "x.aggregate",
+		files: map[string]string{
+			"index.json": `{"package_id": "x.aggregate", "assets": ["logo.txt"], "depends": {"example.com/x.y": "v1.0.0"}}`,
+			"index-lock.json": `{
+				"version": "v1",
+				"depends": {"x.y": "example.com/x.y"},
+				"dependsInfo": {"example.com/x.y": {"package_id": "x.y", "version": "v1.0.0", "source": "example.com/x.y"}}
+			}`,
+			"logo.txt":            "logo",
+			".dep/x.y/index.json": `{"package_id": "x.y", "ramlx_version": "1.0", "entities": ["entities.raml"]}`,
+			".dep/x.y/entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+`) + "\n",
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Read())
+	require.True(t, pkg.Index.IsMetadataOnly())
+	require.NoError(t, pkg.Parse())
+	require.NoError(t, pkg.Validate())
+
+	require.Empty(t, pkg.LocalRegistry.Index)
+	require.Contains(t, pkg.GlobalRegistry.Types, "cti.x.y.event.v1.0")
+}
+
+func Test_ReadCache(t *testing.T) {
+	tc := parserTestCase{
+		name:  "read cache",
+		pkgId: "x.z",
+		files: map[string]string{
+			"index.json": `{"package_id": "x.z", "entities": ["entities.raml"], "depends": {"example.com/x.y": "v1.0.0"}}`,
+			"index-lock.json": `{
+				"version": "v1",
+				"depends": {"x.y": "example.com/x.y"},
+				"dependsInfo": {"example.com/x.y": {"package_id": "x.y", "version": "v1.0.0", "source": "example.com/x.y"}}
+			}`,
+			MetadataCacheFile:               `[{"cti": "cti.x.z.alert.v1.0", "final": true, "schema": {"type": "object"}}]`,
+			".dep/x.y/" + MetadataCacheFile: `[{"cti": "cti.x.y.event.v1.0", "final": false, "schema": {"type": "object"}}]`,
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Read())
+
+	r, err := pkg.ReadCache()
+	require.NoError(t, err)
+	require.Equal(t, []string{"cti.x.y.event.v1.0", "cti.x.z.alert.v1.0"}, r.CompleteCti("cti.x.", 0))
 }
 
 func Test_EmptyIndex(t *testing.T) {
@@ -163,7 +241,7 @@ types:
 				pkgId:    "x.y",
 				entities: []string{"non_existent_file.raml"},
 			},
-			expectedError: "non_existent_file.raml: The system cannot find the file specified.",
+			expectedError: "index.json: entities target non_existent_file.raml not found",
 		},
 	}
 
@@ -189,3 +267,614 @@ types:
 		})
 	}
 }
+
+func Test_ParseTags(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "tags",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  BillingEntity:
+    (cti.cti): cti.x.y.billing_entity.v1.0
+    (cti.tags): [billing]
+    type: object
+
+  AuditEntity:
+    (cti.cti): cti.x.y.audit_entity.v1.0
+    type: object
+`) + "\n"},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+
+	pkg.Index.Tags = map[string][]string{
+		"audit":   {"cti.x.y.audit_entity.v1.0"},
+		"billing": {"cti.x.y.*"},
+	}
+	require.NoError(t, pkg.Parse())
+
+	ids := func(entities metadata.Entities) []string {
+		var res []string
+		for _, e := range entities {
+			res = append(res, e.Cti)
+		}
+		return res
+	}
+	require.Equal(t, []string{"cti.x.y.audit_entity.v1.0"}, ids(pkg.LocalRegistry.FindByTag("audit")))
+	require.Equal(t, []string{"cti.x.y.audit_entity.v1.0", "cti.x.y.billing_entity.v1.0"},
+		ids(pkg.LocalRegistry.FindByTag("billing")))
+	require.Equal(t, []string{"billing"}, pkg.LocalRegistry.Index["cti.x.y.billing_entity.v1.0"].Tags)
+	require.Empty(t, pkg.GlobalRegistry.FindByTag("unknown"))
+}
+
+func Test_ParseOwners(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "owners",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml", "audit.raml"},
+		files: map[string]string{
+			"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  BillingEntity:
+    (cti.cti): cti.x.y.billing_entity.v1.0
+    (cti.owners): ["@acme/billing", billing@acme.com]
+    type: object
+`) + "\n",
+			"audit.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  AuditEntity:
+    (cti.cti): cti.x.y.audit_entity.v1.0
+    type: object
+`) + "\n",
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+
+	pkg.Index.Owners = []string{"@acme/platform"}
+	require.NoError(t, pkg.Parse())
+
+	require.Equal(t, []string{"@acme/billing", "billing@acme.com"},
+		pkg.LocalRegistry.Index["cti.x.y.billing_entity.v1.0"].Owners)
+	require.Equal(t, []string{"@acme/platform"}, pkg.LocalRegistry.Index["cti.x.y.audit_entity.v1.0"].Owners)
+	require.Len(t, pkg.GlobalRegistry.FindByOwner("@acme/billing"), 1)
+	require.Equal(t, []collector.OwnerStats{
+		{Owner: "@acme/billing", Types: 1},
+		{Owner: "@acme/platform", Types: 1},
+		{Owner: "billing@acme.com", Types: 1},
+	}, pkg.LocalRegistry.GetOwnerStats())
+
+	var buf bytes.Buffer
+	require.NoError(t, pkg.WriteCodeowners(&buf, "cti/x.y/"))
+	require.Equal(t, `# Generated from CTI package x.y. DO NOT EDIT.
+/cti/x.y/** @acme/platform
+/cti/x.y/audit.raml @acme/platform
+/cti/x.y/entities.raml @acme/billing billing@acme.com
+`, buf.String())
+}
+
+func Test_ParseAccess(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "access",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{
+			"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  PublicEntity:
+    (cti.cti): cti.x.y.public_entity.v1.0
+    type: object
+  PrivateEntity:
+    (cti.cti): cti.x.y.private_entity.v1.0
+    (cti.access): private
+    type: object
+`) + "\n",
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	require.Empty(t, pkg.LocalRegistry.Index["cti.x.y.public_entity.v1.0"].Access)
+	require.Equal(t, metadata.AccessPrivate, pkg.LocalRegistry.Index["cti.x.y.private_entity.v1.0"].Access)
+	require.Len(t, pkg.GlobalRegistry.ViewFor("x", "z").Index, 1)
+}
+
+func Test_ParseAnnotationNamespace(t *testing.T) {
+	testsupp.InitLog(t)
+
+	require.NoError(t, metadata.RegisterAnnotationNamespace("acme", map[string]json.RawMessage{
+		"sensitive": json.RawMessage(`{"type": "boolean"}`),
+	}))
+	t.Cleanup(func() { metadata.UnregisterAnnotationNamespace("acme") })
+
+	tc := parserTestCase{
+		name:     "annotation_namespace",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{
+			"acme.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+annotationTypes:
+  sensitive: boolean
+  ignored: string
+`) + "\n",
+			"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+  acme: acme.raml
+
+types:
+  User:
+    (cti.cti): cti.x.y.user.v1.0
+    type: object
+    properties:
+      email:
+        type: string
+        (acme.sensitive): true
+`) + "\n",
+		},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	entity := pkg.LocalRegistry.Index["cti.x.y.user.v1.0"]
+	require.NotNil(t, entity)
+	require.Equal(t, map[string]interface{}{"acme.sensitive": true}, entity.Annotations[".email"].Extra)
+	require.NoError(t, pkg.Validate())
+}
+
+func Test_Fix(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "fix",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+annotationTypes:
+  Subscriptions: Subscription[]
+
+# Subscriptions to cti.x.y.topic.v1 topics.
+(Subscriptions):
+- id: cti.x.y.subscription.v1.0~x.y.first.v1.0
+  topic: cti.x.y.topic.v1
+- { id: cti.x.y.subscription.v1.0~x.y.second.v1.0, topic: "cti.x.y.topic.v1" }
+
+types:
+  Topic:
+    (cti.cti): cti.x.y.topic.v1.0
+    type: object
+
+  TopicNext:
+    (cti.cti): cti.x.y.topic.v1.2
+    type: object
+
+  Subscription:
+    (cti.cti): cti.x.y.subscription.v1.0
+    (cti.final): false
+    properties:
+      id:
+        type: cti.CTI
+        (cti.id): true
+      topic:
+        type: string
+        (cti.reference): cti.x.y.topic.v1.*
+`) + "\n"},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.ErrorContains(t, pkg.Validate(), "minor part of version is missing")
+
+	fixes, err := pkg.Fix()
+	require.NoError(t, err)
+	require.Len(t, fixes, 2)
+	for _, fix := range fixes {
+		require.Equal(t, "entities.raml", fix.File)
+		require.Equal(t, "cti.x.y.topic.v1.2", fix.Replacement)
+	}
+
+	require.NoError(t, pkg.Validate())
+
+	// Only the values of the references are fixed.
+	data, err := os.ReadFile(filepath.Join(pkg.BaseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `# Subscriptions to cti.x.y.topic.v1 topics.
+(Subscriptions):
+- id: cti.x.y.subscription.v1.0~x.y.first.v1.0
+  topic: cti.x.y.topic.v1.2
+- { id: cti.x.y.subscription.v1.0~x.y.second.v1.0, topic: "cti.x.y.topic.v1.2" }`)
+}
+
+func Test_FixAnnotations(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "fix annotations",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    (cti.access): protected
+    (cti.final): false
+    type: object
+
+  Created:
+    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
+    type: Event
+
+  Updated:
+    (cti.cti): cti.x.y.event.v1.0~x.y.updated.v1.0
+    (cti.access): public
+    type: Event
+
+  Topic:
+    (cti.cti): cti.x.y.topic.v1.0
+    (cti.deprecated): false
+    type: object
+
+  TopicNext:
+    (cti.cti): cti.x.y.topic.v2.0
+    type: object
+`) + "\n"},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	pkg.Index.Coexistence = &validator.CoexistencePolicy{RequireDeprecation: true}
+	require.ErrorContains(t, pkg.Validate(), "must be deprecated since newer major version cti.x.y.topic.v2.0 exists")
+
+	fixes, err := pkg.Fix()
+	require.NoError(t, err)
+	require.Len(t, fixes, 3)
+
+	require.NoError(t, pkg.Validate())
+
+	// Missing annotations are inserted before cti.cti, existing ones are replaced.
+	data, err := os.ReadFile(filepath.Join(pkg.BaseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `
+  Created:
+    (cti.access): protected
+    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
+    type: Event
+
+  Updated:
+    (cti.cti): cti.x.y.event.v1.0~x.y.updated.v1.0
+    (cti.access): protected
+    type: Event
+
+  Topic:
+    (cti.cti): cti.x.y.topic.v1.0
+    (cti.deprecated): true
+    type: object
+
+  TopicNext:
+    (cti.cti): cti.x.y.topic.v2.0
+    type: object
+`)
+	for _, entity := range pkg.LocalRegistry.Index {
+		if strings.HasPrefix(entity.Cti, "cti.x.y.event.v1.0~") {
+			require.Equal(t, metadata.AccessProtected, entity.Access, entity.Cti)
+		}
+	}
+	require.True(t, pkg.LocalRegistry.Index["cti.x.y.topic.v1.0"].Deprecated)
+}
+
+func Test_TraceSchemaPath(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "trace",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    (cti.final): false
+    properties:
+      name:
+        type: string
+        maxLength: 64
+
+  Created:
+    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
+    type: Event
+    properties:
+      name:
+        type: string
+        maxLength: 16
+`) + "\n"},
+	}
+
+	baseDir := initParseTest(t, tc)
+	pkg, err := New(baseDir,
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	sources, err := merger.TracePath("cti.x.y.event.v1.0~x.y.created.v1.0", pkg.GlobalRegistry, "/properties/name")
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	require.Equal(t, "cti.x.y.event.v1.0~x.y.created.v1.0", sources[0].Cti)
+	require.Equal(t, metadata.SourceLocation{Path: "entities.raml", Line: 20, Column: 9,
+		Offset: 309, EndLine: 21, EndColumn: 22, EndOffset: 343}, sources[0].Location)
+	require.Equal(t, "cti.x.y.event.v1.0", sources[1].Cti)
+	require.Equal(t, metadata.SourceLocation{Path: "entities.raml", Line: 12, Column: 9,
+		Offset: 159, EndLine: 13, EndColumn: 22, EndOffset: 193}, sources[1].Location)
+	content, err := os.ReadFile(filepath.Join(baseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Equal(t, "type: string\n        maxLength: 16", string(content[309:343]))
+
+	final := pkg.LocalRegistry.Index["cti.x.y.event.v1.0"].AnnotationsSourceMap["."][metadata.Final]
+	require.Equal(t, 9, final.Line)
+	require.Equal(t, "(cti.final): false", string(content[final.Offset:final.EndOffset]))
+	require.Equal(t, []string{"maxLength", "type"}, sources[1].Keywords)
+
+	sources, err = merger.TracePath("cti.x.y.event.v1.0~x.y.created.v1.0", pkg.GlobalRegistry, "/properties/name/maxLength")
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	require.Equal(t, []string{"maxLength"}, sources[0].Keywords)
+
+	sources, err = merger.TracePath("cti.x.y.event.v1.0~x.y.created.v1.0", pkg.GlobalRegistry, "/properties/unknown")
+	require.NoError(t, err)
+	require.Empty(t, sources)
+
+	_, err = merger.TracePath("cti.x.y.event.v1.0~x.y.created.v1.0", pkg.GlobalRegistry, "properties")
+	require.ErrorContains(t, err, "invalid JSON pointer")
+}
+
+func Test_ParseContextCanceled(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "context canceled",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  AuditEntity:
+    (cti.cti): cti.x.y.audit_entity.v1.0
+    type: object
+`) + "\n"},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, pkg.ParseContext(ctx), context.Canceled)
+	require.Nil(t, pkg.LocalRegistry)
+	require.ErrorIs(t, pkg.ValidateContext(ctx), context.Canceled)
+
+	require.NoError(t, pkg.ValidateContext(context.Background()))
+	require.Contains(t, pkg.LocalRegistry.Index, "cti.x.y.audit_entity.v1.0")
+}
+
+func Test_ParseSensitive(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "sensitive",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+annotationTypes:
+  Credentials: Credential[]
+
+(Credentials):
+- id: cti.x.y.credential.v1.0~x.y.backup.v1.0
+  login: admin
+  password: secret
+  secrets:
+    db: hunter2
+
+types:
+  Credential:
+    (cti.cti): cti.x.y.credential.v1.0
+    properties:
+      id:
+        type: cti.CTI
+        (cti.id): true
+      login: string
+      password:
+        type: string
+        (cti.sensitive): true
+      secrets?:
+        properties:
+          /.*/:
+            type: string
+            (cti.sensitive): true
+`) + "\n"},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	sensitive := pkg.GlobalRegistry.Index["cti.x.y.credential.v1.0"].Annotations[".password"].Sensitive
+	require.NotNil(t, sensitive)
+	require.True(t, *sensitive)
+
+	instance := pkg.GlobalRegistry.Index["cti.x.y.credential.v1.0~x.y.backup.v1.0"]
+	masked, err := instance.MaskSensitive(pkg.GlobalRegistry.InheritedAnnotations("cti.x.y.credential.v1.0"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": "cti.x.y.credential.v1.0~x.y.backup.v1.0", "login": "admin", "password": "******",
+		"secrets": {"db": "******"}}`, string(masked.Values))
+	require.Contains(t, string(instance.Values), "secret")
+}
+
+func Test_ParseDescriptions(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "descriptions",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Label:
+    type: string
+    displayName: Label
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    properties:
+      value:
+        type: Label | number
+        description: Value.
+      tags:
+        type: array
+        description: Tags.
+        items:
+          properties:
+            name:
+              type: string
+              description: Name.
+      /^x-/:
+        type: string
+        displayName: Extension
+        description: Extension property.
+  Measured:
+    type: Event
+    (cti.cti): cti.x.y.event.v1.0~x.y.measured.v1.0
+    properties:
+      value:
+        type: number
+        minimum: 0
+`) + "\n"},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	schema, err := merger.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.measured.v1.0", pkg.GlobalRegistry)
+	require.NoError(t, err)
+	properties := schema["properties"].(map[string]any)
+	require.Equal(t, "Value.", properties["value"].(map[string]any)["description"])
+	require.Equal(t, "Tags.", properties["tags"].(map[string]any)["description"])
+	items := properties["tags"].(map[string]any)["items"].(map[string]any)
+	require.Equal(t, "Name.", items["properties"].(map[string]any)["name"].(map[string]any)["description"])
+	extension := schema["patternProperties"].(map[string]any)["^x-"].(map[string]any)
+	require.Equal(t, "Extension", extension["title"])
+	require.Equal(t, "Extension property.", extension["description"])
+}
@@ -0,0 +1,74 @@
+package ctipackage
+
+import (
+	"time"
+)
+
+// Phase is a stage of a long-running package operation.
+type Phase string
+
+const (
+	// PhaseParse is parsing of the package and its dependencies. Items are package IDs.
+	PhaseParse Phase = "parse"
+	// PhaseValidate is validation of the parsed entities. Items are CTIs.
+	PhaseValidate Phase = "validate"
+)
+
+// PhaseStats holds timing statistics of the finished phase.
+type PhaseStats struct {
+	Phase    Phase
+	Items    int
+	Duration time.Duration
+	Err      error
+}
+
+// ProgressReporter receives progress of package operations, e.g. to render a progress bar.
+// Methods are called from the goroutine that runs the operation.
+type ProgressReporter interface {
+	// PhaseStarted is called when the phase starts. Total is the number of items to process.
+	PhaseStarted(phase Phase, total int)
+	// ItemDone is called when the item of the phase is processed. Done is the number of processed items.
+	ItemDone(phase Phase, done int, item string)
+	// PhaseFinished is called when the phase finishes, either successfully or not.
+	PhaseFinished(stats PhaseStats)
+}
+
+// WithProgress makes the package report progress of its operations.
+func WithProgress(reporter ProgressReporter) InitializeOption {
+	return func(pkg *Package) error {
+		pkg.progress = reporter
+		return nil
+	}
+}
+
+type nopProgressReporter struct{}
+
+func (nopProgressReporter) PhaseStarted(Phase, int)     {}
+func (nopProgressReporter) ItemDone(Phase, int, string) {}
+func (nopProgressReporter) PhaseFinished(PhaseStats)    {}
+
+// phaseTracker reports progress of a single phase.
+type phaseTracker struct {
+	reporter ProgressReporter
+	phase    Phase
+	started  time.Time
+	done     int
+}
+
+func (pkg *Package) startPhase(phase Phase, total int) *phaseTracker {
+	reporter := pkg.progress
+	if reporter == nil {
+		reporter = nopProgressReporter{}
+	}
+	reporter.PhaseStarted(phase, total)
+	return &phaseTracker{reporter: reporter, phase: phase, started: time.Now()}
+}
+
+func (t *phaseTracker) itemDone(item string) {
+	t.done++
+	t.reporter.ItemDone(t.phase, t.done, item)
+}
+
+func (t *phaseTracker) finish(err error) {
+	t.reporter.PhaseFinished(PhaseStats{Phase: t.phase, Items: t.done, Duration: time.Since(t.started), Err: err})
+}
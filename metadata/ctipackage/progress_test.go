@@ -0,0 +1,71 @@
+package ctipackage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+type recordingReporter struct {
+	started map[Phase]int
+	items   map[Phase][]string
+	stats   []PhaseStats
+}
+
+func (r *recordingReporter) PhaseStarted(phase Phase, total int) {
+	r.started[phase] = total
+}
+
+func (r *recordingReporter) ItemDone(phase Phase, _ int, item string) {
+	r.items[phase] = append(r.items[phase], item)
+}
+
+func (r *recordingReporter) PhaseFinished(stats PhaseStats) {
+	r.stats = append(r.stats, stats)
+}
+
+func Test_Progress(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "progress",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{
+			"entities.raml": `#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+`,
+		},
+	}
+
+	reporter := &recordingReporter{started: make(map[Phase]int), items: make(map[Phase][]string)}
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities),
+		WithProgress(reporter))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Validate())
+
+	require.Equal(t, map[Phase]int{PhaseParse: 1, PhaseValidate: 1}, reporter.started)
+	require.Equal(t, []string{"x.y"}, reporter.items[PhaseParse])
+	require.Equal(t, []string{"cti.x.y.event.v1.0"}, reporter.items[PhaseValidate])
+	require.Len(t, reporter.stats, 2)
+	require.Equal(t, PhaseParse, reporter.stats[0].Phase)
+	require.Equal(t, PhaseValidate, reporter.stats[1].Phase)
+	for _, stats := range reporter.stats {
+		require.Equal(t, 1, stats.Items)
+		require.NoError(t, stats.Err)
+	}
+}
@@ -0,0 +1,58 @@
+package ctipackage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/acronis/go-cti/metadata/filesys"
+)
+
+// Provenance describes how the package bundle was built.
+type Provenance struct {
+	ToolVersion string `json:"tool_version,omitempty"`
+	// Revision is the VCS revision of the package sources, e.g. a commit hash.
+	Revision  string    `json:"revision,omitempty"`
+	BuildTime time.Time `json:"build_time"`
+	// Inputs maps a package relative file path to its checksum.
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// ComputeProvenance computes provenance of the package.
+// Inputs include index files, entity, API and example files and the serialized registry,
+// so the package must be parsed before. The revision is provided by the caller (e.g. from the CI environment)
+// rather than detected, since the package directory is not necessarily a working tree of a VCS.
+func (pkg *Package) ComputeProvenance(toolVersion, revision string, buildTime time.Time) (*Provenance, error) {
+	entities, err := pkg.EntityFiles()
+	if err != nil {
+		return nil, err
+	}
+	files := []string{IndexFileName, IndexLockFileName, MetadataCacheFile}
+	files = append(files, entities...)
+	files = append(files, pkg.Index.Apis...)
+	files = append(files, pkg.Index.Examples...)
+
+	inputs := make(map[string]string, len(files))
+	for _, f := range files {
+		fPath := filepath.Join(pkg.BaseDir, f)
+		if _, err := os.Stat(fPath); err != nil {
+			if os.IsNotExist(err) && f == IndexLockFileName {
+				continue
+			}
+			return nil, fmt.Errorf("stat input %s: %w", f, err)
+		}
+		checksum, err := filesys.ComputeFileChecksum(fPath)
+		if err != nil {
+			return nil, fmt.Errorf("compute checksum of input %s: %w", f, err)
+		}
+		inputs[filepath.ToSlash(f)] = checksum
+	}
+
+	return &Provenance{
+		ToolVersion: toolVersion,
+		Revision:    revision,
+		BuildTime:   buildTime.UTC(),
+		Inputs:      inputs,
+	}, nil
+}
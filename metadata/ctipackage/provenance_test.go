@@ -0,0 +1,70 @@
+package ctipackage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_ComputeProvenance(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "provenance",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": `#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  SampleEntity:
+    (cti.cti): cti.x.y.sample_entity.v1.0
+    type: object
+`},
+	}
+
+	pkg, err := New(initParseTest(t, tc),
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	buildTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("UTC+1", 3600))
+	p, err := pkg.ComputeProvenance("v1.2.3", "0123abc", buildTime)
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.3", p.ToolVersion)
+	require.Equal(t, "0123abc", p.Revision)
+	require.Equal(t, time.UTC, p.BuildTime.Location())
+	require.True(t, buildTime.Equal(p.BuildTime))
+	require.Len(t, p.Inputs, 4)
+	for _, f := range []string{IndexFileName, IndexLockFileName, MetadataCacheFile, "entities.raml"} {
+		require.Contains(t, p.Inputs, f)
+		require.Contains(t, p.Inputs[f], "xxh3:")
+	}
+
+	// Changed input must change its checksum.
+	prevChecksum := p.Inputs["entities.raml"]
+	require.NoError(t, os.WriteFile(filepath.Join(pkg.BaseDir, "entities.raml"),
+		[]byte(tc.files["entities.raml"]+"    description: changed\n"), os.ModePerm))
+	p, err = pkg.ComputeProvenance("v1.2.3", "0123abc", buildTime)
+	require.NoError(t, err)
+	require.NotEqual(t, prevChecksum, p.Inputs["entities.raml"])
+
+	// Provenance is recorded into the serialized registry of the single-file bundle.
+	var buf bytes.Buffer
+	require.NoError(t, pkg.Pack(&buf, WithPackProvenance(p)))
+	packed, err := Unpack(&buf)
+	require.NoError(t, err)
+	require.Equal(t, p, packed.Provenance)
+}
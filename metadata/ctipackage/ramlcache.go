@@ -0,0 +1,168 @@
+package ctipackage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/acronis/go-cti/metadata/ramlx"
+	"github.com/acronis/go-raml"
+)
+
+// RamlCache caches parsed RAML documents of packages in memory, so packages with unchanged sources are not parsed
+// again, e.g. when a long-running server reloads the package after an edit of another file or the same dependency
+// is parsed by several packages. Entries are keyed by the package directory and the generated index RAML,
+// and an entry is reused only if SHA-256 digests of all RAML fragments the document was parsed from match the files
+// on disk.
+//
+// The cache works on whole documents rather than fragments: go-raml resolves shapes of all fragments of a document
+// in place and records their absolute locations, so a parsed fragment cannot be shared between documents.
+// Decoded YAML of fragments, which is read by the package apart from go-raml (imports, source maps and fixes),
+// is cached by content with ramlx.FragmentCache instead, see WithFragmentCache. Documents are shared between
+// packages, so packages that use the same cache must not be parsed concurrently.
+type RamlCache struct {
+	mu      sync.Mutex
+	entries map[string]*ramlCacheEntry
+}
+
+type ramlCacheEntry struct {
+	doc *raml.RAML
+	// digests are digests of the fragments by their absolute paths.
+	digests map[string]string
+}
+
+func NewRamlCache() *RamlCache {
+	return &RamlCache{entries: make(map[string]*ramlCacheEntry)}
+}
+
+// WithRamlCache makes the package and its dependencies reuse parsed RAML documents from the cache.
+func WithRamlCache(cache *RamlCache) InitializeOption {
+	return func(pkg *Package) error {
+		pkg.ramlCache = cache
+		return nil
+	}
+}
+
+// WithFragmentCache makes the package and its dependencies decode RAML fragments with the cache,
+// e.g. shared by several packages or stored on disk with ramlx.WithFragmentCacheDir.
+// Without the option, the package and its dependencies share a cache of the package.
+func WithFragmentCache(cache *ramlx.FragmentCache) InitializeOption {
+	return func(pkg *Package) error {
+		pkg.fragments = cache
+		return nil
+	}
+}
+
+func (pkg *Package) fragmentCache() *ramlx.FragmentCache {
+	if pkg.fragments == nil {
+		pkg.fragments = ramlx.NewFragmentCache()
+	}
+	return pkg.fragments
+}
+
+// parseRaml parses the index RAML of the package, reusing the cached document if its fragments did not change.
+func (pkg *Package) parseRaml(index string) (*raml.RAML, error) {
+	if pkg.ramlCache == nil {
+		return raml.ParseFromString(index, "index.raml", pkg.BaseDir, raml.OptWithValidate())
+	}
+	return pkg.ramlCache.parse(index, pkg.BaseDir)
+}
+
+func (c *RamlCache) parse(index string, baseDir string) (*raml.RAML, error) {
+	// Locations of the parsed shapes are absolute, so the directory is a part of the key.
+	h := sha256.New()
+	h.Write([]byte(baseDir))
+	h.Write([]byte{0})
+	h.Write([]byte(index))
+	key := hex.EncodeToString(h.Sum(nil))
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.isValid() {
+		return entry.doc, nil
+	}
+
+	doc, err := raml.ParseFromString(index, "index.raml", baseDir, raml.OptWithValidate())
+	if err != nil {
+		return nil, err
+	}
+	digests := make(map[string]string)
+	for _, path := range fragmentPaths(doc) {
+		digest, err := fileDigest(path)
+		if err != nil {
+			// The fragment cannot be tracked, so the document is not cached.
+			return doc, nil
+		}
+		digests[path] = digest
+	}
+
+	c.mu.Lock()
+	c.entries[key] = &ramlCacheEntry{doc: doc, digests: digests}
+	c.mu.Unlock()
+	return doc, nil
+}
+
+func (e *ramlCacheEntry) isValid() bool {
+	for path, digest := range e.digests {
+		actual, err := fileDigest(path)
+		if err != nil || actual != digest {
+			return false
+		}
+	}
+	return true
+}
+
+// fragmentPaths returns paths of the fragments the document was parsed from: libraries reachable from
+// the entry point and fragments that declare shapes, e.g. included data types.
+// The entry point is generated from the index, so it is not included.
+func fragmentPaths(doc *raml.RAML) []string {
+	seen := map[string]struct{}{doc.GetLocation(): {}}
+	var paths []string
+	add := func(path string) {
+		if _, ok := seen[path]; ok || path == "" {
+			return
+		}
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+
+	var walk func(lib *raml.Library)
+	visited := make(map[*raml.Library]struct{})
+	walk = func(lib *raml.Library) {
+		if _, ok := visited[lib]; ok {
+			return
+		}
+		visited[lib] = struct{}{}
+		add(lib.Location)
+		for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Link != nil {
+				walk(pair.Value.Link)
+			}
+		}
+	}
+	if lib, ok := doc.EntryPoint().(*raml.Library); ok {
+		walk(lib)
+	}
+	for _, shape := range doc.GetShapes() {
+		add(shape.Location)
+	}
+	return paths
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,132 @@
+package ctipackage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/ramlx"
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_RamlCache(t *testing.T) {
+	testsupp.InitLog(t)
+
+	const entities = `#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+`
+	tc := parserTestCase{
+		name:     "raml cache",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files:    map[string]string{"entities.raml": entities},
+	}
+	baseDir := initParseTest(t, tc)
+	cache := NewRamlCache()
+
+	parse := func() *Package {
+		pkg, err := New(baseDir, WithRamlCache(cache))
+		require.NoError(t, err)
+		require.NoError(t, pkg.Read())
+		require.NoError(t, pkg.Parse())
+		return pkg
+	}
+
+	pkg, err := New(baseDir,
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+
+	first := parse()
+	require.Len(t, cache.entries, 1)
+	var entry *ramlCacheEntry
+	for _, e := range cache.entries {
+		entry = e
+	}
+	require.Contains(t, entry.digests, filepath.ToSlash(filepath.Join(first.BaseDir, "entities.raml")))
+
+	second := parse()
+	require.Len(t, cache.entries, 1)
+	require.Same(t, entry, cache.entries[firstKey(cache)], "unchanged sources are not parsed again")
+	require.Equal(t, first.LocalRegistry.Index["cti.x.y.event.v1.0"].Schema, second.LocalRegistry.Index["cti.x.y.event.v1.0"].Schema)
+
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "entities.raml"),
+		[]byte(entities+"    description: changed\n"), os.ModePerm))
+	third := parse()
+	require.NotSame(t, entry, cache.entries[firstKey(cache)], "changed sources are parsed again")
+	require.Equal(t, "changed", third.LocalRegistry.Index["cti.x.y.event.v1.0"].Description)
+}
+
+func firstKey(c *RamlCache) string {
+	for k := range c.entries {
+		return k
+	}
+	return ""
+}
+
+func Test_FragmentCache(t *testing.T) {
+	testsupp.InitLog(t)
+
+	const entities = `#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+`
+	cacheDir := t.TempDir()
+	parse := func(name string, cache *ramlx.FragmentCache) *Package {
+		tc := parserTestCase{
+			name:     name,
+			pkgId:    "x.y",
+			entities: []string{"entities.raml"},
+			files:    map[string]string{"entities.raml": entities},
+		}
+		pkg, err := New(initParseTest(t, tc),
+			WithRamlxVersion("1.0"),
+			WithID(tc.pkgId),
+			WithEntities(tc.entities),
+			WithFragmentCache(cache))
+		require.NoError(t, err)
+		require.NoError(t, pkg.Initialize())
+		require.NoError(t, pkg.Read())
+		require.NoError(t, pkg.Parse())
+		return pkg
+	}
+	stored := func() []string {
+		files, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		require.NoError(t, err)
+		return files
+	}
+
+	cache := ramlx.NewFragmentCache(ramlx.WithFragmentCacheDir(cacheDir))
+	first := parse("fragment cache", cache)
+	files := stored()
+	require.NotEmpty(t, files)
+
+	// Fragments of another package have the same contents, so they are decoded once.
+	second := parse("fragment cache copy", cache)
+	require.Equal(t, files, stored())
+	require.Equal(t, first.LocalRegistry.Index["cti.x.y.event.v1.0"].SchemaSourceMap,
+		second.LocalRegistry.Index["cti.x.y.event.v1.0"].SchemaSourceMap)
+
+	// Another process reuses the documents stored on disk.
+	third := parse("fragment cache", ramlx.NewFragmentCache(ramlx.WithFragmentCacheDir(cacheDir)))
+	require.Equal(t, files, stored())
+	require.Equal(t, first.LocalRegistry.Index["cti.x.y.event.v1.0"].AnnotationsSourceMap,
+		third.LocalRegistry.Index["cti.x.y.event.v1.0"].AnnotationsSourceMap)
+}
@@ -0,0 +1,45 @@
+package ctipackage
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata/rewrite"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+// PlanRename returns edits of the package sources that rename the entity declared by the package.
+// Occurrences of the CTI in annotations, references, schema usages and instance values are replaced,
+// as well as CTIs of descendants and instances of the entity. Sources are not modified.
+func (pkg *Package) PlanRename(oldCti, newCti string) ([]validator.Fix, error) {
+	if _, err := cti.NewParser().ParseIdentifier(newCti); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", newCti, err)
+	}
+	if err := pkg.Parse(); err != nil {
+		return nil, fmt.Errorf("parse package: %w", err)
+	}
+	if _, ok := pkg.LocalRegistry.Index[oldCti]; !ok {
+		return nil, fmt.Errorf("entity %s is not declared by the package", oldCti)
+	}
+	if _, ok := pkg.GlobalRegistry.Index[newCti]; ok {
+		return nil, fmt.Errorf("entity %s already exists", newCti)
+	}
+	fixes, err := rewrite.Plan(pkg.BaseDir, rewrite.SourceFiles(pkg.LocalRegistry), rewrite.Mapping{oldCti: newCti})
+	if err != nil {
+		return nil, fmt.Errorf("plan rename: %w", err)
+	}
+	return fixes, nil
+}
+
+// Rename renames the entity declared by the package in the package sources (see PlanRename)
+// and returns the applied edits.
+func (pkg *Package) Rename(oldCti, newCti string) ([]validator.Fix, error) {
+	fixes, err := pkg.PlanRename(oldCti, newCti)
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.ApplyFixes(pkg.BaseDir, fixes); err != nil {
+		return nil, fmt.Errorf("apply edits: %w", err)
+	}
+	return fixes, nil
+}
@@ -0,0 +1,81 @@
+package ctipackage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/testsupp"
+)
+
+func Test_Rename(t *testing.T) {
+	testsupp.InitLog(t)
+
+	tc := parserTestCase{
+		name:     "rename",
+		pkgId:    "x.y",
+		entities: []string{"entities.raml"},
+		files: map[string]string{"entities.raml": strings.TrimSpace(`
+#%RAML 1.0 Library
+
+uses:
+  cti: .ramlx/cti.raml
+
+annotationTypes:
+  Subscriptions: Subscription[]
+
+(Subscriptions):
+- id: cti.x.y.subscription.v1.0~x.y.first.v1.0
+  topic: cti.x.y.topic.v1.0
+
+types:
+  Topic:
+    (cti.cti): cti.x.y.topic.v1.0
+    type: object
+
+  Subscription:
+    (cti.cti): cti.x.y.subscription.v1.0
+    (cti.final): false
+    properties:
+      id:
+        type: cti.CTI
+        (cti.id): true
+      topic:
+        type: string
+        (cti.reference): cti.x.y.topic.v1.0
+`) + "\n"},
+	}
+
+	baseDir := initParseTest(t, tc)
+	pkg, err := New(baseDir,
+		WithRamlxVersion("1.0"),
+		WithID(tc.pkgId),
+		WithEntities(tc.entities))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Initialize())
+	require.NoError(t, pkg.Read())
+
+	_, err = pkg.PlanRename("cti.x.y.unknown.v1.0", "cti.x.y.channel.v1.0")
+	require.ErrorContains(t, err, "entity cti.x.y.unknown.v1.0 is not declared by the package")
+	_, err = pkg.PlanRename("cti.x.y.topic.v1.0", "cti.x.y.subscription.v1.0")
+	require.ErrorContains(t, err, "entity cti.x.y.subscription.v1.0 already exists")
+	_, err = pkg.PlanRename("cti.x.y.topic.v1.0", "cti.x.y.channel")
+	require.Error(t, err)
+
+	fixes, err := pkg.PlanRename("cti.x.y.topic.v1.0", "cti.x.y.channel.v1.0")
+	require.NoError(t, err)
+	require.Len(t, fixes, 3)
+
+	fixes, err = pkg.Rename("cti.x.y.topic.v1.0", "cti.x.y.channel.v1.0")
+	require.NoError(t, err)
+	require.Len(t, fixes, 3)
+	data, err := os.ReadFile(filepath.Join(baseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "cti.x.y.topic.v1.0")
+
+	require.NoError(t, pkg.Validate())
+	require.Contains(t, pkg.LocalRegistry.Index, "cti.x.y.channel.v1.0")
+}
@@ -0,0 +1,112 @@
+package ctipackage
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Template is a name of the project template used to scaffold a new package.
+type Template string
+
+const (
+	// TemplateService scaffolds a package of a service that emits events.
+	TemplateService Template = "service"
+	// TemplateDomain scaffolds a package of domain entities with a dictionary type.
+	TemplateDomain Template = "domain"
+	// TemplateTraitsOnly scaffolds a package of types that are described by traits.
+	TemplateTraitsOnly Template = "traits-only"
+
+	templatesDir = "templates"
+	templateExt  = ".tmpl"
+	examplesDir  = "examples"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Templates returns names of the available project templates.
+func Templates() []Template {
+	return []Template{TemplateService, TemplateDomain, TemplateTraitsOnly}
+}
+
+// TemplateFiles renders files of the project template for the package.
+// Returned map is keyed by file paths relative to the package directory.
+// Files in the examples directory are test fixtures that are listed in the examples section of the index.
+func TemplateFiles(t Template, packageID string) (map[string][]byte, error) {
+	if err := ValidateID(packageID); err != nil {
+		return nil, fmt.Errorf("validate id: %w", err)
+	}
+	root := path.Join(templatesDir, string(t))
+	if _, err := fs.Stat(templateFiles, root); err != nil {
+		return nil, fmt.Errorf("unknown template %q", t)
+	}
+
+	data := struct{ PackageID string }{PackageID: packageID}
+	files := make(map[string][]byte)
+	err := fs.WalkDir(templateFiles, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		tmpl, err := template.ParseFS(templateFiles, p)
+		if err != nil {
+			return fmt.Errorf("parse template %s: %w", p, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("execute template %s: %w", p, err)
+		}
+		files[strings.TrimSuffix(strings.TrimPrefix(p, root+"/"), templateExt)] = buf.Bytes()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Scaffold writes files of the project template to the package directory
+// and adds them to the entities and examples sections of the index.
+// Existing files are never overwritten. The index is saved by Initialize.
+func (pkg *Package) Scaffold(t Template) error {
+	if pkg.Index.PackageID == "" {
+		return errors.New("package id is required to scaffold the package")
+	}
+	files, err := TemplateFiles(t, pkg.Index.PackageID)
+	if err != nil {
+		return fmt.Errorf("render template: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(pkg.BaseDir, name)); err == nil {
+			return fmt.Errorf("file %s already exists", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fPath := filepath.Join(pkg.BaseDir, name)
+		if err := os.MkdirAll(filepath.Dir(fPath), 0755); err != nil {
+			return fmt.Errorf("create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(fPath, files[name], 0644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		if strings.HasPrefix(name, examplesDir+"/") {
+			pkg.Index.Examples = append(pkg.Index.Examples, name)
+		} else {
+			pkg.Index.Entities = append(pkg.Index.Entities, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package ctipackage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TemplateFiles(t *testing.T) {
+	for _, tmpl := range Templates() {
+		t.Run(string(tmpl), func(t *testing.T) {
+			files, err := TemplateFiles(tmpl, "ab.cd")
+			require.NoError(t, err)
+			require.Contains(t, files, "entities.raml")
+			for name, content := range files {
+				require.Equal(t, RAMLExt, filepath.Ext(name))
+				require.NotContains(t, string(content), "{{")
+				require.Contains(t, string(content), "cti.ab.cd.")
+			}
+		})
+	}
+
+	_, err := TemplateFiles("unknown", "ab.cd")
+	require.EqualError(t, err, `unknown template "unknown"`)
+
+	_, err = TemplateFiles(TemplateService, "invalid")
+	require.Error(t, err)
+}
+
+func Test_Scaffold(t *testing.T) {
+	baseDir := t.TempDir()
+
+	pkg, err := New(baseDir, WithID("ab.cd"))
+	require.NoError(t, err)
+	require.NoError(t, pkg.Scaffold(TemplateDomain))
+	require.NoError(t, pkg.Initialize())
+
+	require.Equal(t, []string{"entities.raml"}, pkg.Index.Entities)
+	require.Equal(t, []string{"examples/statuses.raml"}, pkg.Index.Examples)
+	require.FileExists(t, filepath.Join(baseDir, "examples", "statuses.raml"))
+
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+	require.NoError(t, pkg.Validate())
+	require.Contains(t, pkg.GlobalRegistry.Types, "cti.ab.cd.entity.v1.0~ab.cd.document.v1.0")
+
+	// Existing files are not overwritten.
+	pkg, err = New(baseDir, WithID("ab.cd"))
+	require.NoError(t, err)
+	require.EqualError(t, pkg.Scaffold(TemplateService), "file entities.raml already exists")
+
+	pkg, err = New(t.TempDir())
+	require.NoError(t, err)
+	require.EqualError(t, pkg.Scaffold(TemplateService), "package id is required to scaffold the package")
+}
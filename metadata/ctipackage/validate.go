@@ -1,22 +1,160 @@
 package ctipackage
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/acronis/go-cti/metadata/validator"
 )
 
-func (pkg *Package) Validate() error {
+func (pkg *Package) Validate(opts ...validator.Option) error {
+	return pkg.ValidateContext(context.Background(), opts...)
+}
+
+// ValidateContext is like Validate, but the context is passed to the rules, the policies and the asset store,
+// and parsing and validation stop with the error of the context when the context is done.
+func (pkg *Package) ValidateContext(ctx context.Context, opts ...validator.Option) (err error) {
 	// TODO: Validate must use cache.
-	err := pkg.Parse()
+	err = pkg.ParseContext(ctx)
 	if err != nil {
 		return fmt.Errorf("parse with cache: %w", err)
 	}
-	validator := validator.MakeMetadataValidator(pkg.GlobalRegistry)
+	tracker := pkg.startPhase(PhaseValidate, len(pkg.GlobalRegistry.Index))
+	defer func() { tracker.finish(err) }()
 
-	if err := validator.ValidateAll(); err != nil {
+	opts = append([]validator.Option{
+		validator.WithPackage(pkg.Index.PackageID, pkg.BaseDir),
+		validator.WithProgress(tracker.itemDone),
+	}, opts...)
+	v, err := validator.MakeMetadataValidator(pkg.GlobalRegistry, opts...)
+	if err != nil {
+		return fmt.Errorf("make validator: %w", err)
+	}
+	dictionaries, err := pkg.GetDictionaries()
+	if err != nil {
+		return fmt.Errorf("get dictionaries: %w", err)
+	}
+	if err = registerIndexRules(v, pkg.Index, dictionaries.Dictionaries); err != nil {
+		return err
+	}
+	if err = v.RegisterRule(validator.NewDependencyReferenceRule(pkg.Index.PackageID, pkg.pinnedDependencies())); err != nil {
+		return fmt.Errorf("register dependency reference rule: %w", err)
+	}
+
+	if err = v.ValidateAllContext(ctx); err != nil {
 		return fmt.Errorf("validate all: %w", err)
 	}
 
+	if err = pkg.ValidateAssets(ctx); err != nil {
+		return fmt.Errorf("validate assets: %w", err)
+	}
+
+	return nil
+}
+
+// Validate validates entities of the bundle against entities of its dependencies held by the bundle.
+// Bundles of packages with dependencies that do not hold their entities are rejected with ErrBundleDependencies.
+func (b *Bundle) Validate(opts ...validator.Option) error {
+	if err := b.CheckDependencies(); err != nil {
+		return err
+	}
+	opts = append([]validator.Option{validator.WithPackage(b.Index.PackageID, "")}, opts...)
+	v, err := validator.MakeMetadataValidator(b.Registry, opts...)
+	if err != nil {
+		return fmt.Errorf("make validator: %w", err)
+	}
+	dictionary, err := b.GetDictionary()
+	if err != nil {
+		return fmt.Errorf("get dictionary: %w", err)
+	}
+	if err := registerIndexRules(v, b.Index, dictionary); err != nil {
+		return err
+	}
+
+	if err := v.ValidateAll(); err != nil {
+		return fmt.Errorf("validate all: %w", err)
+	}
+
+	return nil
+}
+
+// pinnedDependencies returns versions of the dependencies pinned in the lockfile by their package IDs.
+func (pkg *Package) pinnedDependencies() map[string]string {
+	deps := make(map[string]string, len(pkg.IndexLock.SourceInfo))
+	for _, info := range pkg.IndexLock.SourceInfo {
+		deps[info.PackageID] = info.Version
+	}
+	return deps
+}
+
+// registerIndexRules registers the built-in rules and the rules configured by the index and the dictionary.
+func registerIndexRules(v *validator.MetadataValidator, idx *Index, dictionary Dictionary) error {
+	if err := v.RegisterRule(validator.NewDeprecatedReferenceRule()); err != nil {
+		return fmt.Errorf("register deprecated reference rule: %w", err)
+	}
+	if err := v.RegisterRule(validator.NewMetaReferenceRule()); err != nil {
+		return fmt.Errorf("register meta reference rule: %w", err)
+	}
+	keys := dictionary.Keys()
+	dictionaryKeys := make([]string, len(keys))
+	for i, key := range keys {
+		dictionaryKeys[i] = string(key)
+	}
+	if err := v.RegisterRule(validator.NewDictionaryRule(idx.PackageID, dictionaryKeys)); err != nil {
+		return fmt.Errorf("register dictionary rule: %w", err)
+	}
+	scope := idx.IDUniqueness
+	if scope == "" {
+		scope = validator.UniquenessScopePackage
+	}
+	if err := v.RegisterRule(validator.NewUniqueIDRule(idx.PackageID, scope)); err != nil {
+		return fmt.Errorf("register unique id rule: %w", err)
+	}
+	if idx.Coexistence != nil {
+		if err := v.RegisterRule(validator.NewCoexistenceRule(*idx.Coexistence)); err != nil {
+			return fmt.Errorf("register coexistence rule: %w", err)
+		}
+	}
 	return nil
 }
+
+// Fix applies machine-applicable fixes suggested by the validation rules to the package sources.
+// Built-in fix rules are always executed in addition to the rules from the options.
+// Returns the list of applied fixes.
+func (pkg *Package) Fix(opts ...validator.Option) ([]validator.Fix, error) {
+	return pkg.FixContext(context.Background(), opts...)
+}
+
+// FixContext is like Fix, but the context is passed to the parser and the rules.
+func (pkg *Package) FixContext(ctx context.Context, opts ...validator.Option) ([]validator.Fix, error) {
+	if err := pkg.ParseContext(ctx); err != nil {
+		return nil, fmt.Errorf("parse package: %w", err)
+	}
+	v, err := validator.MakeMetadataValidator(pkg.GlobalRegistry, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("make validator: %w", err)
+	}
+	rules := []validator.Rule{
+		validator.NewReferenceMinorVersionRule(pkg.BaseDir),
+		validator.NewAccessModifierRule(pkg.BaseDir),
+	}
+	if pkg.Index.Coexistence != nil && pkg.Index.Coexistence.RequireDeprecation {
+		rules = append(rules, validator.NewRequiredDeprecationRule(pkg.BaseDir))
+	}
+	for _, rule := range rules {
+		if err := v.RegisterRule(rule); err != nil {
+			return nil, fmt.Errorf("register fix rule: %w", err)
+		}
+	}
+
+	var fixes []validator.Fix
+	for _, entity := range pkg.LocalRegistry.Index {
+		for _, issue := range v.ValidateRules(ctx, entity) {
+			fixes = append(fixes, issue.Fixes...)
+		}
+	}
+	if err := validator.ApplyFixes(pkg.BaseDir, fixes); err != nil {
+		return nil, fmt.Errorf("apply fixes: %w", err)
+	}
+	return fixes, nil
+}
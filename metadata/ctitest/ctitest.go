@@ -0,0 +1,387 @@
+// Package ctitest provides utilities for building CTI packages in tests of downstream repositories.
+//
+// A package is declared with Go structs and rendered into RAML files, index.json and the RAMLx specification
+// that are ready to be parsed with ctipackage. Rendering is deterministic: the same declaration always
+// produces byte-identical files. Packages are written to separate directories, so tests that build
+// packages may run in parallel.
+package ctitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/ramlx"
+)
+
+const (
+	// EntitiesFileName is a name of the RAML file with the declared types and instances.
+	EntitiesFileName = "entities.raml"
+
+	ramlxSpecDir = "spec_v1"
+)
+
+// Package declares a CTI package.
+type Package struct {
+	// ID is a package identifier in the form of <vendor>.<package>.
+	ID string
+	// Types are CTI types of the package.
+	Types []Type
+	// Instances are CTI instances of the package.
+	Instances []Instance
+}
+
+// Type declares a CTI type.
+type Type struct {
+	// Name is a name of the RAML type. If empty, it is derived from the CTI, e.g. EventV1_0.
+	Name string
+	// Cti is an identifier of the type.
+	Cti string
+	// Base is a name of the RAML type the type inherits from.
+	// If empty, it is the type of the package that is identified by the parent CTI.
+	Base string
+	// Description is a description of the type.
+	Description string
+	// Annotations are annotations of the type by names without parentheses, e.g. cti.final.
+	Annotations map[string]any
+	// Properties are properties of the type in the order of declaration.
+	Properties []Property
+}
+
+// Property declares a property of the CTI type.
+type Property struct {
+	// Name is a name of the property.
+	Name string
+	// Type is a RAML type of the property. Defaults to string.
+	Type string
+	// Optional marks the property as not required.
+	Optional bool
+	// Description is a description of the property.
+	Description string
+	// Annotations are annotations of the property by names without parentheses, e.g. cti.id.
+	Annotations map[string]any
+}
+
+// Instance declares a CTI instance.
+type Instance struct {
+	// Type is a CTI of the package type the instance belongs to.
+	Type string
+	// Values are values of the instance including its identifier.
+	Values map[string]any
+}
+
+// Files renders the package. Returned map is keyed by file paths relative to the package directory.
+func (p *Package) Files() (map[string][]byte, error) {
+	if err := ctipackage.ValidateID(p.ID); err != nil {
+		return nil, fmt.Errorf("validate id: %w", err)
+	}
+	entities, err := p.renderEntities()
+	if err != nil {
+		return nil, fmt.Errorf("render entities: %w", err)
+	}
+
+	idx := &ctipackage.Index{
+		PackageID: p.ID,
+		Entities:  []string{EntitiesFileName},
+	}
+	idxData, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode index: %w", err)
+	}
+	idxLock := &ctipackage.IndexLock{
+		Version:           ctipackage.IndexLockVersion,
+		DependentPackages: make(map[string]string),
+		SourceInfo:        make(map[string]ctipackage.Info),
+	}
+	idxLockData, err := json.MarshalIndent(idxLock, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode index lock: %w", err)
+	}
+
+	files := map[string][]byte{
+		EntitiesFileName:             entities,
+		ctipackage.IndexFileName:     idxData,
+		ctipackage.IndexLockFileName: idxLockData,
+	}
+	err = fs.WalkDir(ramlx.RamlFiles, ramlxSpecDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := ramlx.RamlFiles.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", p, err)
+		}
+		files[path.Join(ctipackage.RamlxDirName, strings.TrimPrefix(p, ramlxSpecDir+"/"))] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read RAMLx specification: %w", err)
+	}
+	return files, nil
+}
+
+// WriteDir renders the package and writes it to the directory.
+// Files of the package that already exist in the directory are overwritten.
+func (p *Package) WriteDir(dir string) error {
+	files, err := p.Files()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fPath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fPath), 0755); err != nil {
+			return fmt.Errorf("create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(fPath, files[name], 0644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// FS renders the package into an in-memory file system.
+func (p *Package) FS() (fs.FS, error) {
+	files, err := p.Files()
+	if err != nil {
+		return nil, err
+	}
+	fsys := make(fstest.MapFS, len(files))
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: data, Mode: 0644}
+	}
+	return fsys, nil
+}
+
+// TempDir writes the package to a new temporary directory of the test and returns the directory.
+// The test fails immediately if the package cannot be rendered.
+func (p *Package) TempDir(t testing.TB) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := p.WriteDir(dir); err != nil {
+		t.Fatalf("write package %s: %v", p.ID, err)
+	}
+	return dir
+}
+
+func (p *Package) renderEntities() ([]byte, error) {
+	names := make(map[string]string, len(p.Types))
+	byCti := make(map[string]*Type, len(p.Types))
+	for i := range p.Types {
+		typ := &p.Types[i]
+		if typ.Cti == "" {
+			return nil, fmt.Errorf("cti of type #%d is required", i)
+		}
+		if _, ok := byCti[typ.Cti]; ok {
+			return nil, fmt.Errorf("duplicate type %s", typ.Cti)
+		}
+		name := typ.Name
+		if name == "" {
+			name = typeName(typ.Cti)
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("types %s and %s have the same name %s", other, typ.Cti, name)
+		}
+		names[name] = typ.Cti
+		byCti[typ.Cti] = typ
+	}
+	nameOf := make(map[string]string, len(names))
+	for name, cti := range names {
+		nameOf[cti] = name
+	}
+
+	ctis := make([]string, 0, len(byCti))
+	for cti := range byCti {
+		ctis = append(ctis, cti)
+	}
+	sort.Strings(ctis)
+
+	types := mappingNode()
+	for _, cti := range ctis {
+		typ := byCti[cti]
+		base := typ.Base
+		if base == "" {
+			if idx := strings.LastIndex(cti, "~"); idx != -1 {
+				parent, ok := nameOf[cti[:idx]]
+				if !ok {
+					return nil, fmt.Errorf("base of type %s is required since its parent is not declared", cti)
+				}
+				base = parent
+			}
+		}
+		node, err := typ.node(base)
+		if err != nil {
+			return nil, fmt.Errorf("render type %s: %w", cti, err)
+		}
+		appendPair(types, nameOf[cti], node)
+	}
+
+	// Instances are grouped by types and declared with an annotation type per type.
+	groups := make(map[string][]Instance)
+	for i, inst := range p.Instances {
+		name, ok := nameOf[inst.Type]
+		if !ok {
+			return nil, fmt.Errorf("type %s of instance #%d is not declared", inst.Type, i)
+		}
+		groups[name] = append(groups[name], inst)
+	}
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	doc := mappingNode()
+	appendPair(doc, "uses", mappingNode("cti", scalarNode(path.Join(ctipackage.RamlxDirName, "cti.raml"))))
+	if len(groupNames) != 0 {
+		annotationTypes := mappingNode()
+		for _, name := range groupNames {
+			appendPair(annotationTypes, name+"Instances", scalarNode(name+"[]"))
+		}
+		appendPair(doc, "annotationTypes", annotationTypes)
+		for _, name := range groupNames {
+			values := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, inst := range groups[name] {
+				node, err := valueNode(inst.Values)
+				if err != nil {
+					return nil, fmt.Errorf("render instance of %s: %w", inst.Type, err)
+				}
+				values.Content = append(values.Content, node)
+			}
+			appendPair(doc, "("+name+"Instances)", values)
+		}
+	}
+	if len(ctis) != 0 {
+		appendPair(doc, "types", types)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("#%RAML 1.0 Library\n\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode raml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode raml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (typ *Type) node(base string) (*yaml.Node, error) {
+	node := mappingNode("(cti.cti)", scalarNode(typ.Cti))
+	if err := appendAnnotations(node, typ.Annotations); err != nil {
+		return nil, err
+	}
+	if base != "" {
+		appendPair(node, "type", scalarNode(base))
+	}
+	if typ.Description != "" {
+		appendPair(node, "description", scalarNode(typ.Description))
+	}
+	if len(typ.Properties) == 0 {
+		return node, nil
+	}
+
+	props := mappingNode()
+	for _, prop := range typ.Properties {
+		name := prop.Name
+		if prop.Optional {
+			name += "?"
+		}
+		propType := prop.Type
+		if propType == "" {
+			propType = "string"
+		}
+		propNode := mappingNode("type", scalarNode(propType))
+		if err := appendAnnotations(propNode, prop.Annotations); err != nil {
+			return nil, fmt.Errorf("property %s: %w", prop.Name, err)
+		}
+		if prop.Description != "" {
+			appendPair(propNode, "description", scalarNode(prop.Description))
+		}
+		appendPair(props, name, propNode)
+	}
+	appendPair(node, "properties", props)
+	return node, nil
+}
+
+func appendAnnotations(node *yaml.Node, annotations map[string]any) error {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val, err := valueNode(annotations[key])
+		if err != nil {
+			return fmt.Errorf("annotation %s: %w", key, err)
+		}
+		appendPair(node, "("+key+")", val)
+	}
+	return nil
+}
+
+// typeName derives a name of the RAML type from the entity name and the version of the last CTI node,
+// e.g. cti.a.p.event.v1.0~a.p.user_created.v1.2 becomes UserCreatedV1_2.
+func typeName(cti string) string {
+	node := cti[strings.LastIndex(cti, "~")+1:]
+	parts := strings.Split(strings.TrimPrefix(node, "cti."), ".")
+	if len(parts) < 4 {
+		return strings.NewReplacer(".", "_", "~", "_").Replace(cti)
+	}
+	var sb strings.Builder
+	for _, part := range parts[2 : len(parts)-2] {
+		for _, word := range strings.Split(part, "_") {
+			if word != "" {
+				sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+			}
+		}
+	}
+	sb.WriteString(strings.ToUpper(parts[len(parts)-2]) + "_" + parts[len(parts)-1])
+	return sb.String()
+}
+
+func mappingNode(pairs ...any) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		appendPair(node, pairs[i].(string), pairs[i+1].(*yaml.Node))
+	}
+	return node
+}
+
+func scalarNode(val string) *yaml.Node {
+	node := &yaml.Node{}
+	// Encoding a string never fails and quotes values that would be resolved to other types.
+	_ = node.Encode(val)
+	return node
+}
+
+func valueNode(val any) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(val); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func appendPair(node *yaml.Node, key string, val *yaml.Node) {
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, val)
+}
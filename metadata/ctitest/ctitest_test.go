@@ -0,0 +1,91 @@
+package ctitest
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/ctipackage"
+)
+
+func samplePackage(id string) *Package {
+	return &Package{
+		ID: id,
+		Types: []Type{
+			{
+				Cti:         "cti." + id + ".event.v1.0",
+				Annotations: map[string]any{"cti.final": false},
+				Properties: []Property{
+					{Name: "id", Type: "string"},
+					{Name: "topic", Type: "cti.CTI", Annotations: map[string]any{"cti.reference": "cti." + id + ".topic.v1.0"}},
+					{Name: "data", Type: "object", Optional: true},
+				},
+			},
+			{
+				Cti:         "cti." + id + ".event.v1.0~" + id + ".user_created.v1.0",
+				Description: "Emitted when a user is created.",
+			},
+			{
+				Cti:         "cti." + id + ".topic.v1.0",
+				Annotations: map[string]any{"cti.final": false},
+				Properties: []Property{
+					{Name: "id", Type: "cti.CTI", Annotations: map[string]any{"cti.id": true}},
+					{Name: "name", Annotations: map[string]any{"cti.display_name": true}},
+				},
+			},
+		},
+		Instances: []Instance{
+			{
+				Type:   "cti." + id + ".topic.v1.0",
+				Values: map[string]any{"id": "cti." + id + ".topic.v1.0~" + id + ".users.v1.0", "name": "Users"},
+			},
+		},
+	}
+}
+
+func Test_Package(t *testing.T) {
+	for _, id := range []string{"a.p", "b.q", "c.r"} {
+		id := id
+		t.Run(id, func(t *testing.T) {
+			t.Parallel()
+
+			pkg, err := ctipackage.New(samplePackage(id).TempDir(t))
+			require.NoError(t, err)
+			require.NoError(t, pkg.Read())
+			require.NoError(t, pkg.Parse())
+			require.NoError(t, pkg.Validate())
+
+			require.Contains(t, pkg.LocalRegistry.Types, "cti."+id+".event.v1.0~"+id+".user_created.v1.0")
+			require.Contains(t, pkg.LocalRegistry.Instances, "cti."+id+".topic.v1.0~"+id+".users.v1.0")
+		})
+	}
+}
+
+func Test_Files(t *testing.T) {
+	files, err := samplePackage("a.p").Files()
+	require.NoError(t, err)
+	again, err := samplePackage("a.p").Files()
+	require.NoError(t, err)
+	require.Equal(t, files, again)
+
+	require.Contains(t, files, ".ramlx/cti.raml")
+	require.Contains(t, string(files[EntitiesFileName]), "UserCreatedV1_0:\n")
+	require.Contains(t, string(files[EntitiesFileName]), "type: EventV1_0\n")
+	require.Contains(t, string(files[EntitiesFileName]), "(TopicV1_0Instances):\n")
+
+	fsys, err := samplePackage("a.p").FS()
+	require.NoError(t, err)
+	data, err := fs.ReadFile(fsys, EntitiesFileName)
+	require.NoError(t, err)
+	require.Equal(t, files[EntitiesFileName], data)
+
+	_, err = (&Package{ID: "invalid"}).Files()
+	require.Error(t, err)
+
+	_, err = (&Package{ID: "a.p", Types: []Type{{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0"}}}).Files()
+	require.EqualError(t, err, "render entities: base of type cti.a.p.event.v1.0~a.p.created.v1.0 is required since its parent is not declared")
+
+	_, err = (&Package{ID: "a.p", Instances: []Instance{{Type: "cti.a.p.topic.v1.0"}}}).Files()
+	require.EqualError(t, err, "render entities: type cti.a.p.topic.v1.0 of instance #0 is not declared")
+}
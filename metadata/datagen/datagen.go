@@ -0,0 +1,440 @@
+package datagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+const (
+	ctiIDKey   = "x-domainExt-cti.id"
+	maxDepth   = 16
+	letters    = "abcdefghijklmnopqrstuvwxyz"
+	defaultMax = 100
+)
+
+// Generator produces pseudo-random JSON values that satisfy JSON schemas of CTI types.
+// Values are deterministic for the same seed and options.
+type Generator struct {
+	rnd         *rand.Rand
+	seed        int64
+	minStrLen   int
+	maxStrLen   int
+	minItems    int
+	maxItems    int
+	enumWeights map[string]float64
+	optional    float64
+	examples    bool
+}
+
+type Option func(*Generator) error
+
+// WithSeed sets the seed of the pseudo-random generator. Default seed is 1.
+func WithSeed(seed int64) Option {
+	return func(g *Generator) error {
+		g.seed = seed
+		return nil
+	}
+}
+
+// WithStringLength sets the range of lengths of generated strings.
+// Range is narrowed by minLength and maxLength of the schema.
+func WithStringLength(minLen, maxLen int) Option {
+	return func(g *Generator) error {
+		if minLen < 0 || maxLen < minLen {
+			return fmt.Errorf("invalid string length range %d..%d", minLen, maxLen)
+		}
+		g.minStrLen, g.maxStrLen = minLen, maxLen
+		return nil
+	}
+}
+
+// WithArraySize sets the range of sizes of generated arrays.
+// Range is narrowed by minItems and maxItems of the schema.
+func WithArraySize(minSize, maxSize int) Option {
+	return func(g *Generator) error {
+		if minSize < 0 || maxSize < minSize {
+			return fmt.Errorf("invalid array size range %d..%d", minSize, maxSize)
+		}
+		g.minItems, g.maxItems = minSize, maxSize
+		return nil
+	}
+}
+
+// WithEnumWeights sets relative weights of enum values keyed by their JSON representation
+// (e.g. `"critical"` for a string or `3` for a number). Values without weight have weight 1.
+func WithEnumWeights(weights map[string]float64) Option {
+	return func(g *Generator) error {
+		for value, weight := range weights {
+			if weight < 0 {
+				return fmt.Errorf("negative weight of enum value %s", value)
+			}
+			g.enumWeights[value] = weight
+		}
+		return nil
+	}
+}
+
+// WithOptionalProbability sets the probability of generating a value for a property that is not required.
+func WithOptionalProbability(p float64) Option {
+	return func(g *Generator) error {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("invalid probability %v", p)
+		}
+		g.optional = p
+		return nil
+	}
+}
+
+// WithExamples makes the generator prefer values of examples and default keywords of the schema,
+// the first enum value and the first non-null anyOf member, so generated values look like hand-written examples.
+func WithExamples() Option {
+	return func(g *Generator) error {
+		g.examples = true
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Generator, error) {
+	g := &Generator{
+		seed:        1,
+		minStrLen:   1,
+		maxStrLen:   16,
+		minItems:    0,
+		maxItems:    4,
+		enumWeights: make(map[string]float64),
+		optional:    0.5,
+	}
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	//nolint:gosec // generated data is not used for security purposes
+	g.rnd = rand.New(rand.NewSource(g.seed))
+	return g, nil
+}
+
+// Generate generates a value for the schema. The instanceID is used for properties annotated with cti.id.
+func (g *Generator) Generate(schema map[string]any, instanceID string) (any, error) {
+	st := &state{root: schema, instanceID: instanceID}
+	return g.generate(st, schema, 0)
+}
+
+// WriteJSONL generates n instances of the CTI type and writes them to w as JSON lines.
+func (g *Generator) WriteJSONL(w io.Writer, r *collector.MetadataRegistry, typeCti string, n int) error {
+	expr, schema, err := typeSchema(r, typeCti)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for i := 0; i < n; i++ {
+		instanceID := fmt.Sprintf("%s~%s.%s.gen_%d.v1.0", typeCti, expr.Head.Vendor, expr.Head.Package, i+1)
+		v, err := g.Generate(schema, instanceID)
+		if err != nil {
+			return fmt.Errorf("generate instance %d: %w", i+1, err)
+		}
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("write instance %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Example returns a sample value of the CTI type that satisfies its merged schema, e.g. for documentation and tests.
+// All properties are filled, arrays have a single item (unless the schema requires more), and values of examples,
+// default and enum keywords are preferred (see WithExamples). The value is the same for the same schema.
+// Properties annotated with cti.id receive an identifier of the form <type cti>~<vendor>.<package>.example.v1.0.
+func Example(r *collector.MetadataRegistry, typeCti string) (any, error) {
+	expr, schema, err := typeSchema(r, typeCti)
+	if err != nil {
+		return nil, err
+	}
+	g, err := New(WithExamples(), WithOptionalProbability(1), WithArraySize(1, 1))
+	if err != nil {
+		return nil, err
+	}
+	return g.Generate(schema, fmt.Sprintf("%s~%s.%s.example.v1.0", typeCti, expr.Head.Vendor, expr.Head.Package))
+}
+
+func typeSchema(r *collector.MetadataRegistry, typeCti string) (cti.Expression, map[string]any, error) {
+	if _, ok := r.Types[typeCti]; !ok {
+		return cti.Expression{}, nil, fmt.Errorf("type %s not found", typeCti)
+	}
+	expr, err := cti.ParseIdentifier(typeCti)
+	if err != nil {
+		return cti.Expression{}, nil, fmt.Errorf("parse %s: %w", typeCti, err)
+	}
+	schema, err := merger.GetMergedCtiSchema(typeCti, r)
+	if err != nil {
+		return cti.Expression{}, nil, fmt.Errorf("get merged schema of %s: %w", typeCti, err)
+	}
+	return expr, schema, nil
+}
+
+type state struct {
+	root       map[string]any
+	instanceID string
+}
+
+//nolint:gocyclo // dispatch by schema type
+func (g *Generator) generate(st *state, schema map[string]any, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, ok := jsonschema.LookupRef(st.root, ref)
+		if !ok {
+			return nil, fmt.Errorf("definition of $ref %s not found", ref)
+		}
+		return g.generate(st, resolved, depth+1)
+	}
+	if isCtiID(schema) && st.instanceID != "" {
+		return st.instanceID, nil
+	}
+	if v, ok := schema["const"]; ok {
+		return v, nil
+	}
+	if g.examples {
+		if examples, ok := schema["examples"].([]any); ok && len(examples) != 0 {
+			return examples[0], nil
+		}
+		if v, ok := schema["default"]; ok {
+			return v, nil
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) != 0 {
+		if g.examples {
+			return enum[0], nil
+		}
+		return g.pickEnum(enum), nil
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && len(anyOf) != 0 {
+		member, ok := g.pickAnyOf(anyOf).(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid anyOf member")
+		}
+		return g.generate(st, member, depth+1)
+	}
+
+	switch typ := jsonschema.SchemaType(schema); typ {
+	case "object":
+		return g.generateObject(st, schema, depth)
+	case "array":
+		return g.generateArray(st, schema, depth)
+	case "string":
+		return g.generateString(schema), nil
+	case "integer":
+		lo, hi := numberRange(schema)
+		return int64(lo) + g.rnd.Int63n(int64(hi-lo)+1), nil
+	case "number":
+		lo, hi := numberRange(schema)
+		return lo + g.rnd.Float64()*(hi-lo), nil
+	case "boolean":
+		return g.rnd.Intn(2) == 1, nil
+	case "null":
+		return nil, nil
+	case "":
+		// Any value is allowed.
+		return g.randomString(g.minStrLen, g.maxStrLen), nil
+	default:
+		return nil, fmt.Errorf("unsupported schema type %s", typ)
+	}
+}
+
+func (g *Generator) generateObject(st *state, schema map[string]any, depth int) (any, error) {
+	obj := make(map[string]any)
+	properties, _ := schema["properties"].(map[string]any)
+	required := make(map[string]struct{})
+	switch items := schema["required"].(type) {
+	case []string:
+		for _, item := range items {
+			required[item] = struct{}{}
+		}
+	case []any:
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				required[s] = struct{}{}
+			}
+		}
+	}
+
+	// Sort property names to keep generated values deterministic.
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, isRequired := required[name]
+		if !isRequired && g.rnd.Float64() >= g.optional {
+			continue
+		}
+		propSchema, ok := properties[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid schema of property %s", name)
+		}
+		v, err := g.generate(st, propSchema, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("generate property %s: %w", name, err)
+		}
+		obj[name] = v
+	}
+	return obj, nil
+}
+
+func (g *Generator) generateArray(st *state, schema map[string]any, depth int) (any, error) {
+	lo, hi := g.minItems, g.maxItems
+	if v, ok := schema["minItems"].(float64); ok {
+		lo = max(lo, int(v))
+		hi = max(hi, lo)
+	}
+	if v, ok := schema["maxItems"].(float64); ok {
+		hi = min(hi, int(v))
+		lo = min(lo, hi)
+	}
+	size := lo + g.rnd.Intn(hi-lo+1)
+
+	items, _ := schema["items"].(map[string]any)
+	arr := make([]any, 0, size)
+	for i := 0; i < size; i++ {
+		if items == nil {
+			arr = append(arr, g.randomString(g.minStrLen, g.maxStrLen))
+			continue
+		}
+		// Only the top-level instance could have the identifier.
+		v, err := g.generate(&state{root: st.root}, items, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("generate item %d: %w", i, err)
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (g *Generator) generateString(schema map[string]any) string {
+	switch schema["format"] {
+	case "date-time":
+		return g.randomTime().Format(time.RFC3339)
+	case "date":
+		return g.randomTime().Format(time.DateOnly)
+	case "time":
+		return g.randomTime().Format(time.TimeOnly)
+	case "uuid":
+		var b [16]byte
+		_, _ = g.rnd.Read(b[:])
+		return uuid.Must(uuid.FromBytes(b[:])).String()
+	case "email":
+		return g.randomString(1, 8) + "@example.com"
+	case "uri":
+		return "https://example.com/" + g.randomString(1, 8)
+	}
+
+	lo, hi := g.minStrLen, g.maxStrLen
+	if v, ok := schema["minLength"].(float64); ok {
+		lo = max(lo, int(v))
+		hi = max(hi, lo)
+	}
+	if v, ok := schema["maxLength"].(float64); ok {
+		hi = min(hi, int(v))
+		lo = min(lo, hi)
+	}
+	return g.randomString(lo, hi)
+}
+
+func (g *Generator) randomString(lo, hi int) string {
+	n := lo + g.rnd.Intn(hi-lo+1)
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteByte(letters[g.rnd.Intn(len(letters))])
+	}
+	return sb.String()
+}
+
+func (g *Generator) randomTime() time.Time {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(g.rnd.Int63n(int64(5 * 365 * 24 * time.Hour))))
+}
+
+func (g *Generator) pickAnyOf(anyOf []any) any {
+	if g.examples {
+		for _, member := range anyOf {
+			if m, ok := member.(map[string]any); ok && jsonschema.SchemaType(m) != "null" {
+				return member
+			}
+		}
+		return anyOf[0]
+	}
+	return anyOf[g.rnd.Intn(len(anyOf))]
+}
+
+func (g *Generator) pickEnum(enum []any) any {
+	weights := make([]float64, len(enum))
+	total := 0.0
+	for i, v := range enum {
+		weights[i] = 1
+		if key, err := json.Marshal(v); err == nil {
+			if w, ok := g.enumWeights[string(key)]; ok {
+				weights[i] = w
+			}
+		}
+		total += weights[i]
+	}
+	if total == 0 {
+		return enum[g.rnd.Intn(len(enum))]
+	}
+	x := g.rnd.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return enum[i]
+		}
+		x -= w
+	}
+	return enum[len(enum)-1]
+}
+
+func numberRange(schema map[string]any) (float64, float64) {
+	lo, hi := 0.0, float64(defaultMax)
+	if v, ok := schema["minimum"].(float64); ok {
+		lo = v
+		if _, ok := schema["maximum"]; !ok {
+			hi = v + defaultMax
+		}
+	}
+	if v, ok := schema["maximum"].(float64); ok {
+		hi = v
+		if _, ok := schema["minimum"]; !ok {
+			lo = v - defaultMax
+		}
+	}
+	if jsonschema.SchemaType(schema) == "integer" {
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+func isCtiID(schema map[string]any) bool {
+	custom, ok := schema[jsonschema.CustomKeyword].(map[string]any)
+	if !ok {
+		return false
+	}
+	v, ok := custom[ctiIDKey].(bool)
+	return ok && v
+}
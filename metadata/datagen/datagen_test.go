@@ -0,0 +1,159 @@
+package datagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+const sampleCti = "cti.x.y.sample_entity.v1.0"
+
+func newSampleRegistry(t *testing.T) *collector.MetadataRegistry {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: sampleCti,
+		Schema: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema",
+			"$ref": "#/definitions/SampleEntity",
+			"definitions": {
+				"SampleEntity": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "string", "x-custom": {"x-domainExt-cti.id": true}},
+						"name": {"type": "string", "minLength": 2, "maxLength": 4},
+						"severity": {"type": "string", "enum": ["low", "high", "critical"]},
+						"count": {"type": "integer", "minimum": 1, "maximum": 3},
+						"ratio": {"type": "number"},
+						"enabled": {"type": "boolean"},
+						"created_at": {"type": "string", "format": "date-time"},
+						"labels": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+						"nested": {
+							"type": "object",
+							"properties": {"value": {"type": "integer"}},
+							"required": ["value"]
+						}
+					},
+					"required": ["id", "name", "severity", "count", "labels", "nested"]
+				}
+			}
+		}`),
+	}))
+	return r
+}
+
+func Test_WriteJSONL(t *testing.T) {
+	r := newSampleRegistry(t)
+
+	generate := func(opts ...Option) string {
+		g, err := New(opts...)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, g.WriteJSONL(&buf, r, sampleCti, 20))
+		return buf.String()
+	}
+
+	out := generate(WithSeed(42), WithStringLength(1, 10), WithArraySize(1, 5))
+	require.Equal(t, out, generate(WithSeed(42), WithStringLength(1, 10), WithArraySize(1, 5)))
+	require.NotEqual(t, out, generate(WithSeed(43), WithStringLength(1, 10), WithArraySize(1, 5)))
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 20)
+	for i, line := range lines {
+		var v map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &v))
+		require.Equal(t, fmt.Sprintf("%s~x.y.gen_%d.v1.0", sampleCti, i+1), v["id"])
+		require.LessOrEqual(t, len(v["labels"].([]any)), 2)
+		require.GreaterOrEqual(t, len(v["labels"].([]any)), 1)
+		require.GreaterOrEqual(t, len(v["name"].(string)), 2)
+		require.LessOrEqual(t, len(v["name"].(string)), 4)
+	}
+
+	v, err := validator.MakeMetadataValidator(r)
+	require.NoError(t, err)
+	lineErrs, err := v.ValidateStream(sampleCti, strings.NewReader(out))
+	require.NoError(t, err)
+	require.Empty(t, lineErrs)
+
+	err = (&Generator{}).WriteJSONL(&bytes.Buffer{}, r, "cti.x.y.unknown.v1.0", 1)
+	require.ErrorContains(t, err, "type cti.x.y.unknown.v1.0 not found")
+}
+
+func Test_EnumWeights(t *testing.T) {
+	schema := map[string]any{
+		"type": "string",
+		"enum": []any{"low", "high", "critical"},
+	}
+
+	g, err := New(WithEnumWeights(map[string]float64{`"low"`: 0, `"high"`: 0}))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		v, err := g.Generate(schema, "")
+		require.NoError(t, err)
+		require.Equal(t, "critical", v)
+	}
+
+	_, err = New(WithEnumWeights(map[string]float64{`"low"`: -1}))
+	require.ErrorContains(t, err, "negative weight")
+	_, err = New(WithStringLength(5, 1))
+	require.ErrorContains(t, err, "invalid string length range")
+	_, err = New(WithArraySize(-1, 1))
+	require.ErrorContains(t, err, "invalid array size range")
+}
+
+func Test_Example(t *testing.T) {
+	r := newSampleRegistry(t)
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.sample_entity.v1.0~x.y.child.v1.0",
+		Schema: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema",
+			"$ref": "#/definitions/Child",
+			"definitions": {
+				"Child": {
+					"type": "object",
+					"properties": {
+						"mode": {"type": "string", "default": "auto"},
+						"owner": {"anyOf": [{"type": "null"}, {"type": "string", "format": "email"}]},
+						"hint": {"type": "string", "examples": ["use defaults"]}
+					}
+				}
+			}
+		}`),
+	}))
+	childCti := "cti.x.y.sample_entity.v1.0~x.y.child.v1.0"
+
+	v, err := Example(r, childCti)
+	require.NoError(t, err)
+	example, ok := v.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, childCti+"~x.y.example.v1.0", example["id"])
+	require.Equal(t, "low", example["severity"])
+	require.Equal(t, "auto", example["mode"])
+	require.Equal(t, "use defaults", example["hint"])
+	require.Contains(t, example["owner"], "@example.com")
+	require.Len(t, example["labels"], 1)
+	// All properties are filled.
+	require.Len(t, example, 12)
+
+	again, err := Example(r, childCti)
+	require.NoError(t, err)
+	require.Equal(t, v, again)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	mv, err := validator.MakeMetadataValidator(r)
+	require.NoError(t, err)
+	lineErrs, err := mv.ValidateStream(childCti, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Empty(t, lineErrs)
+
+	_, err = Example(r, "cti.x.y.unknown.v1.0")
+	require.EqualError(t, err, "type cti.x.y.unknown.v1.0 not found")
+}
@@ -0,0 +1,558 @@
+// Package docgen renders Markdown documentation of CTI entities, one document per entity,
+// so the documentation may be published to wikis and static sites.
+package docgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/assetstore"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+const (
+	// IndexFileName is a name of the document with the list of documented entities.
+	IndexFileName = "README.md"
+	// AssetsDirName is a name of the directory with assets copied by WriteDir.
+	AssetsDirName = "assets"
+)
+
+// constraintKeywords are JSON schema keywords that are listed in the constraints column in this order.
+var constraintKeywords = []string{
+	"const", "enum", "default", "format", "pattern",
+	"minLength", "maxLength", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minItems", "maxItems", "uniqueItems", "minProperties", "maxProperties",
+}
+
+// FileName returns a name of the Markdown document of the entity. Documents link to each other by these names.
+func FileName(id string) string {
+	return id + ".md"
+}
+
+type options struct {
+	assets assetstore.Store
+}
+
+type Option func(*options)
+
+// WithAssetStore makes WriteDir copy assets referenced by documented instances from the storage
+// to the AssetsDirName directory, so documents link to them.
+func WithAssetStore(store assetstore.Store) Option {
+	return func(o *options) {
+		o.assets = store
+	}
+}
+
+// WriteDir writes documents of entities of the registry that match the CTI expression (all entities if it is empty)
+// and the index document to the directory.
+func WriteDir(dir string, r *collector.MetadataRegistry, filter string, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var expr *cti.Expression
+	if filter != "" {
+		e, err := cti.ParseReference(filter)
+		if err != nil {
+			return fmt.Errorf("parse filter: %w", err)
+		}
+		expr = &e
+	}
+
+	var ids []string
+	for id := range r.Index {
+		if expr != nil {
+			idExpr, err := cti.ParseIdentifier(id)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", id, err)
+			}
+			if ok, err := expr.Match(idExpr); err != nil {
+				return fmt.Errorf("match %s: %w", id, err)
+			} else if !ok {
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	for _, id := range ids {
+		var copied map[string]struct{}
+		if o.assets != nil {
+			var err error
+			if copied, err = copyAssets(dir, o.assets, r.GetAssetReferences(id)); err != nil {
+				return fmt.Errorf("copy assets of %s: %w", id, err)
+			}
+		}
+		if err := writeFile(filepath.Join(dir, FileName(id)), func(w io.Writer) error {
+			return writeEntity(w, r, id, copied)
+		}); err != nil {
+			return fmt.Errorf("write document of %s: %w", id, err)
+		}
+	}
+	return writeFile(filepath.Join(dir, IndexFileName), func(w io.Writer) error {
+		return writeIndex(w, r, ids)
+	})
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyAssets copies the assets from the storage to the AssetsDirName directory and returns names of the copied ones.
+// Assets that are not found in the storage (e.g. assets of dependencies) are skipped.
+func copyAssets(dir string, store assetstore.Store, names []string) (map[string]struct{}, error) {
+	copied := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		src, err := store.Open(context.Background(), name)
+		if err != nil {
+			if errors.Is(err, assetstore.ErrNotFound) {
+				slog.Warn("Asset not found", slog.String("name", name))
+				continue
+			}
+			return nil, err
+		}
+		dst := filepath.Join(dir, AssetsDirName, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(dst), 0o755)
+		if err == nil {
+			err = writeFile(dst, func(w io.Writer) error {
+				_, err := io.Copy(w, src)
+				return err
+			})
+		}
+		src.Close()
+		if err != nil {
+			return nil, fmt.Errorf("write asset %s: %w", name, err)
+		}
+		copied[name] = struct{}{}
+	}
+	return copied, nil
+}
+
+func writeIndex(w io.Writer, r *collector.MetadataRegistry, ids []string) error {
+	d := &document{r: r}
+	d.printf("# CTI entities\n\n")
+	d.printf("| Entity | Kind | Description |\n|---|---|---|\n")
+	for _, id := range ids {
+		entity := r.Index[id]
+		d.printf("| %s | %s | %s |\n", d.link(id), kind(r, id), cell(entity.Description))
+	}
+	_, err := io.WriteString(w, d.sb.String())
+	return err
+}
+
+// WriteEntity writes the Markdown document of the entity.
+// The document of a type describes every property of its merged schema (including nested ones) in a table:
+// the type, constraints, description, CTI annotations and the type in the inheritance chain that declares the property.
+// Parents, referenced types, derived entities and the type of instances are linked by FileName.
+// Assets referenced by instances are listed by their names.
+func WriteEntity(w io.Writer, r *collector.MetadataRegistry, id string) error {
+	return writeEntity(w, r, id, nil)
+}
+
+func writeEntity(w io.Writer, r *collector.MetadataRegistry, id string, copiedAssets map[string]struct{}) error {
+	entity, ok := r.Index[id]
+	if !ok {
+		return fmt.Errorf("cti entity %s not found", id)
+	}
+	d := &document{r: r, id: id, refs: make(map[string]struct{}), copiedAssets: copiedAssets}
+
+	title := entity.DisplayName
+	if title == "" {
+		title = id
+	}
+	d.printf("# %s\n\n`%s`\n\n", title, id)
+	if entity.Deprecated {
+		d.printf("> **Deprecated.**")
+		if msg := oneLine(entity.DeprecationMessage); msg != "" {
+			if !strings.HasSuffix(msg, ".") {
+				msg += "."
+			}
+			d.printf(" %s", msg)
+		}
+		if entity.ReplacedBy != "" {
+			d.printf(" Replaced by %s.", d.link(entity.ReplacedBy))
+		}
+		d.printf("\n\n")
+	}
+	if entity.Description != "" {
+		d.printf("%s\n\n", entity.Description)
+	}
+
+	d.printf("| | |\n|---|---|\n")
+	d.printf("| Kind | %s |\n", kind(r, id))
+	if parent := metadata.GetParentCti(id); parent != id {
+		d.printf("| Parent | %s |\n", d.link(parent))
+	}
+	if _, ok := r.Types[id]; ok {
+		d.printf("| Final | %t |\n", entity.Final)
+	}
+	if len(entity.Tags) != 0 {
+		d.printf("| Tags | %s |\n", cell(strings.Join(entity.Tags, ", ")))
+	}
+	if len(entity.Owners) != 0 {
+		d.printf("| Owners | %s |\n", cell(strings.Join(entity.Owners, ", ")))
+	}
+	d.printf("\n")
+
+	if _, ok := r.Types[id]; ok {
+		if err := d.writeProperties(); err != nil {
+			return err
+		}
+	}
+	if entity.Traits != nil {
+		d.writeJSON("Traits", entity.Traits)
+	}
+	if entity.Values != nil {
+		d.writeJSON("Values", entity.Values)
+	}
+	d.writeAssets()
+	d.writeReferences()
+	d.writeDerived()
+
+	_, err := io.WriteString(w, d.sb.String())
+	return err
+}
+
+type document struct {
+	r  *collector.MetadataRegistry
+	id string
+	sb strings.Builder
+	// refs are CTI types that are referenced by annotations of the entity.
+	refs map[string]struct{}
+	// copiedAssets are names of the assets copied to AssetsDirName, so they may be linked.
+	copiedAssets map[string]struct{}
+}
+
+func (d *document) printf(format string, args ...any) {
+	fmt.Fprintf(&d.sb, format, args...)
+}
+
+// link returns a link to the document of the entity if it is in the registry or the code span with its CTI otherwise.
+func (d *document) link(id string) string {
+	if _, ok := d.r.Index[id]; !ok {
+		return "`" + id + "`"
+	}
+	return fmt.Sprintf("[%s](%s)", id, FileName(id))
+}
+
+type property struct {
+	name     string
+	pointer  string
+	gjson    string
+	schema   map[string]any
+	required bool
+}
+
+func (d *document) writeProperties() error {
+	schema, err := merger.GetMergedCtiSchema(d.id, d.r)
+	if err != nil {
+		return fmt.Errorf("get merged schema: %w", err)
+	}
+	var props []property
+	collectProperties(schema, "", "", ".", &props)
+	if len(props) == 0 {
+		return nil
+	}
+
+	d.printf("## Properties\n\n")
+	d.printf("| Property | Type | Required | Constraints | Description | Annotations | Declared in |\n")
+	d.printf("|---|---|---|---|---|---|---|\n")
+	for _, prop := range props {
+		sources, err := merger.TracePath(d.id, d.r, prop.pointer)
+		if err != nil {
+			return fmt.Errorf("trace property %s: %w", prop.name, err)
+		}
+		declaredIn := ""
+		if len(sources) != 0 {
+			// Sources are ordered from the type to the root parent.
+			declaredIn = d.link(sources[len(sources)-1].Cti)
+		}
+		required := ""
+		if prop.required {
+			required = "yes"
+		}
+		description, _ := prop.schema["description"].(string)
+		d.printf("| `%s` | %s | %s | %s | %s | %s | %s |\n",
+			prop.name, cell(typeName(prop.schema)), required, cell(constraints(prop.schema)),
+			cell(description), d.annotations(prop.gjson), declaredIn)
+	}
+	d.printf("\n")
+	return nil
+}
+
+// collectProperties collects properties of the object schema and its nested objects and items of arrays.
+func collectProperties(schema map[string]any, prefix, pointer, gjson string, props *[]property) {
+	if items, ok := schema["items"].(map[string]any); ok {
+		collectProperties(items, prefix+"[]", pointer+"/items", joinGJsonPath(gjson, "#"), props)
+		return
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	required := make(map[string]struct{})
+	switch items := schema["required"].(type) {
+	case []string:
+		for _, item := range items {
+			required[item] = struct{}{}
+		}
+	case []any:
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				required[s] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propSchema, ok := properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		_, isRequired := required[name]
+		prop := property{
+			name:     name,
+			pointer:  pointer + "/properties/" + escapePointer(name),
+			gjson:    joinGJsonPath(gjson, name),
+			schema:   propSchema,
+			required: isRequired,
+		}
+		if prefix != "" {
+			prop.name = prefix + "." + name
+		}
+		*props = append(*props, prop)
+		if _, ok := propSchema["$ref"]; !ok {
+			// Recursive schemas refer to the enclosing schema and are not expanded.
+			collectProperties(propSchema, prop.name, prop.pointer, prop.gjson, props)
+		}
+	}
+}
+
+// annotations returns CTI annotations of the property declared by the entity and its parents.
+func (d *document) annotations(path string) string {
+	values := make(map[string]any)
+	for id := d.id; ; {
+		entity, ok := d.r.Index[id]
+		if !ok {
+			break
+		}
+		if annotations, ok := entity.Annotations[metadata.GJsonPath(path)]; ok {
+			var m map[string]any
+			if data, err := json.Marshal(annotations); err == nil && json.Unmarshal(data, &m) == nil {
+				for key, val := range m {
+					// Annotations of the entity override annotations of its parents.
+					if _, ok := values[key]; !ok {
+						values[key] = val
+					}
+				}
+			}
+		}
+		parent := metadata.GetParentCti(id)
+		if parent == id {
+			break
+		}
+		id = parent
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, fmt.Sprintf("`%s`: %s", key, d.annotationValue(key, values[key])))
+	}
+	return strings.Join(items, "<br>")
+}
+
+func (d *document) annotationValue(key string, val any) string {
+	switch v := val.(type) {
+	case string:
+		if key == "cti.reference" || key == "cti.schema" || key == "cti.cti" || key == "cti.replaced_by" {
+			d.refs[v] = struct{}{}
+			return d.link(v)
+		}
+		return cell(v)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, d.annotationValue(key, item))
+		}
+		return strings.Join(items, ", ")
+	default:
+		data, _ := json.Marshal(v)
+		return cell(string(data))
+	}
+}
+
+func (d *document) writeJSON(title string, data json.RawMessage) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return
+	}
+	formatted, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	d.printf("## %s\n\n```json\n%s\n```\n\n", title, formatted)
+}
+
+func (d *document) writeAssets() {
+	names := d.r.GetAssetReferences(d.id)
+	if len(names) == 0 {
+		return
+	}
+	d.printf("## Assets\n\n")
+	for _, name := range names {
+		if _, ok := d.copiedAssets[name]; ok {
+			d.printf("* [%s](%s/%s)\n", name, AssetsDirName, name)
+		} else {
+			d.printf("* `%s`\n", name)
+		}
+	}
+	d.printf("\n")
+}
+
+func (d *document) writeReferences() {
+	delete(d.refs, d.id)
+	if len(d.refs) == 0 {
+		return
+	}
+	refs := make([]string, 0, len(d.refs))
+	for ref := range d.refs {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	d.printf("## Referenced types\n\n")
+	for _, ref := range refs {
+		d.printf("* %s\n", d.link(ref))
+	}
+	d.printf("\n")
+}
+
+func (d *document) writeDerived() {
+	var derived []string
+	for id := range d.r.Index {
+		if id != d.id && metadata.GetParentCti(id) == d.id {
+			derived = append(derived, id)
+		}
+	}
+	if len(derived) == 0 {
+		return
+	}
+	sort.Strings(derived)
+
+	d.printf("## Derived entities\n\n")
+	for _, id := range derived {
+		d.printf("* %s\n", d.link(id))
+	}
+	d.printf("\n")
+}
+
+func kind(r *collector.MetadataRegistry, id string) string {
+	if _, ok := r.Instances[id]; ok {
+		return "instance"
+	}
+	return "type"
+}
+
+// typeName returns a short description of the type of values of the schema, e.g. "array of string" or "string | null".
+func typeName(schema map[string]any) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return "see " + ref
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if members, ok := schema[keyword].([]any); ok && len(members) != 0 {
+			names := make([]string, 0, len(members))
+			for _, member := range members {
+				if m, ok := member.(map[string]any); ok {
+					names = append(names, typeName(m))
+				}
+			}
+			return strings.Join(names, " | ")
+		}
+	}
+	var name string
+	switch t := schema["type"].(type) {
+	case string:
+		name = t
+	case []any:
+		names := make([]string, 0, len(t))
+		for _, item := range t {
+			names = append(names, fmt.Sprint(item))
+		}
+		name = strings.Join(names, " | ")
+	default:
+		return "any"
+	}
+	if items, ok := schema["items"].(map[string]any); ok && name == "array" {
+		return "array of " + typeName(items)
+	}
+	return name
+}
+
+func constraints(schema map[string]any) string {
+	var items []string
+	for _, keyword := range constraintKeywords {
+		val, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(val)
+		if err != nil {
+			continue
+		}
+		items = append(items, keyword+": "+string(data))
+	}
+	return strings.Join(items, ", ")
+}
+
+// cell makes the text suitable for a cell of Markdown table.
+func cell(s string) string {
+	return strings.ReplaceAll(oneLine(s), "|", `\|`)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func joinGJsonPath(path string, key string) string {
+	if path == "." {
+		return path + key
+	}
+	return path + "." + key
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,139 @@
+package docgen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/assetstore"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/ctitest"
+)
+
+func newTestRegistry(t *testing.T) *collector.MetadataRegistry {
+	t.Helper()
+
+	dir := (&ctitest.Package{
+		ID: "a.p",
+		Types: []ctitest.Type{
+			{
+				Cti:         "cti.a.p.topic.v1.0",
+				Description: "Topic of events.",
+				Properties: []ctitest.Property{
+					{Name: "id", Type: "cti.CTI", Annotations: map[string]any{"cti.id": true}},
+				},
+			},
+			{
+				Cti:         "cti.a.p.event.v1.0",
+				Description: "Base event.",
+				Annotations: map[string]any{"cti.final": false},
+				Properties: []ctitest.Property{
+					{Name: "id", Type: "cti.CTI", Annotations: map[string]any{"cti.id": true}},
+					{Name: "topic", Type: "cti.CTI", Description: "Topic of | the event.", Annotations: map[string]any{"cti.reference": "cti.a.p.topic.v1.0"}},
+				},
+			},
+			{
+				Cti: "cti.a.p.event.v1.0~a.p.created.v1.0",
+				Properties: []ctitest.Property{
+					{Name: "name", Optional: true},
+				},
+			},
+		},
+		Instances: []ctitest.Instance{
+			{Type: "cti.a.p.topic.v1.0", Values: map[string]any{"id": "cti.a.p.topic.v1.0~a.p.users.v1.0"}},
+		},
+	}).TempDir(t)
+
+	pkg, err := ctipackage.New(dir)
+	require.NoError(t, err)
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+	return pkg.GlobalRegistry
+}
+
+func Test_WriteEntity(t *testing.T) {
+	r := newTestRegistry(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEntity(&buf, r, "cti.a.p.event.v1.0~a.p.created.v1.0"))
+	doc := buf.String()
+	require.Contains(t, doc, "# CreatedV1_0\n\n`cti.a.p.event.v1.0~a.p.created.v1.0`\n")
+	require.Contains(t, doc, "| Parent | [cti.a.p.event.v1.0](cti.a.p.event.v1.0.md) |\n")
+	require.Contains(t, doc, "| `name` | string |  |  |  |  | [cti.a.p.event.v1.0~a.p.created.v1.0](cti.a.p.event.v1.0~a.p.created.v1.0.md) |\n")
+	require.Contains(t, doc, "| `topic` | string | yes |")
+	require.Contains(t, doc, "Topic of \\| the event. | `cti.reference`: [cti.a.p.topic.v1.0](cti.a.p.topic.v1.0.md) | [cti.a.p.event.v1.0](cti.a.p.event.v1.0.md) |\n")
+	require.Contains(t, doc, "## Referenced types\n\n* [cti.a.p.topic.v1.0](cti.a.p.topic.v1.0.md)\n")
+
+	buf.Reset()
+	require.NoError(t, WriteEntity(&buf, r, "cti.a.p.topic.v1.0"))
+	require.Contains(t, buf.String(), "Topic of events.\n")
+	require.Contains(t, buf.String(), "## Derived entities\n\n* [cti.a.p.topic.v1.0~a.p.users.v1.0](cti.a.p.topic.v1.0~a.p.users.v1.0.md)\n")
+
+	buf.Reset()
+	require.NoError(t, WriteEntity(&buf, r, "cti.a.p.topic.v1.0~a.p.users.v1.0"))
+	require.Contains(t, buf.String(), "| Kind | instance |\n")
+	require.Contains(t, buf.String(), "## Values\n\n```json\n{\n  \"id\": \"cti.a.p.topic.v1.0~a.p.users.v1.0\"\n}\n```\n")
+
+	require.EqualError(t, WriteEntity(&buf, r, "cti.a.p.unknown.v1.0"), "cti entity cti.a.p.unknown.v1.0 not found")
+}
+
+func Test_WriteDir(t *testing.T) {
+	r := newTestRegistry(t)
+	dir := t.TempDir()
+
+	require.NoError(t, WriteDir(dir, r, "cti.a.p.event.v1.0"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Equal(t, []string{IndexFileName, "cti.a.p.event.v1.0.md", "cti.a.p.event.v1.0~a.p.created.v1.0.md"}, names)
+
+	index, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(index), "| [cti.a.p.event.v1.0](cti.a.p.event.v1.0.md) | type | Base event. |\n")
+}
+
+func Test_WriteDirAssets(t *testing.T) {
+	asset := true
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.a.p.app.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/App", "definitions": {"App": {"type": "object", "properties": {"icon": {"type": "string"}}}}}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".icon": {Asset: &asset},
+		},
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.a.p.app.v1.0~a.p.backup.v1.0",
+		Values: []byte(`{"icon": "assets/backup.svg"}`),
+	}))
+	store := assetstore.NewFS(fstest.MapFS{"assets/backup.svg": {Data: []byte("<svg/>")}})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEntity(&buf, r, "cti.a.p.app.v1.0~a.p.backup.v1.0"))
+	require.Contains(t, buf.String(), "## Assets\n\n* `assets/backup.svg`\n")
+
+	dir := t.TempDir()
+	require.NoError(t, WriteDir(dir, r, "", WithAssetStore(store)))
+	doc, err := os.ReadFile(filepath.Join(dir, FileName("cti.a.p.app.v1.0~a.p.backup.v1.0")))
+	require.NoError(t, err)
+	require.Contains(t, string(doc), "## Assets\n\n* [assets/backup.svg](assets/assets/backup.svg)\n")
+	data, err := os.ReadFile(filepath.Join(dir, AssetsDirName, "assets", "backup.svg"))
+	require.NoError(t, err)
+	require.Equal(t, "<svg/>", string(data))
+
+	// Missing assets are listed, but not linked.
+	dir = t.TempDir()
+	require.NoError(t, WriteDir(dir, r, "", WithAssetStore(assetstore.NewFS(fstest.MapFS{}))))
+	doc, err = os.ReadFile(filepath.Join(dir, FileName("cti.a.p.app.v1.0~a.p.backup.v1.0")))
+	require.NoError(t, err)
+	require.Contains(t, string(doc), "## Assets\n\n* `assets/backup.svg`\n")
+}
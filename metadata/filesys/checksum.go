@@ -45,3 +45,8 @@ func ComputeFileChecksum(filePath string) (string, error) {
 func ComputeDirectoryHash(dir string) (string, error) {
 	return dirhash.HashDir(dir, "", hashXXH3)
 }
+
+// ComputeDirectorySHA256 returns the SHA-256 hash of the directory content in the "h1:" format of go.sum files.
+func ComputeDirectorySHA256(dir string) (string, error) {
+	return dirhash.HashDir(dir, "", dirhash.Hash1)
+}
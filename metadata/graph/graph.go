@@ -0,0 +1,240 @@
+// Package graph provides a graph of CTI entities connected by inheritance and references.
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// EdgeKind is a kind of the dependency between entities.
+type EdgeKind = collector.DependencyKind
+
+const (
+	// EdgeInheritance connects an entity with its parent type.
+	EdgeInheritance = collector.DependencyInheritance
+	// EdgeReference connects an entity with an entity referenced by cti.reference annotations of its schemas
+	// or by its values and traits that are annotated with cti.reference.
+	EdgeReference = collector.DependencyReference
+	// EdgeSchema connects a type with a type referenced by cti.schema annotations of its schemas.
+	EdgeSchema = collector.DependencySchema
+)
+
+// Edge is a dependency of the entity From on the entity To.
+type Edge struct {
+	From string
+	To   string
+	Kind EdgeKind
+}
+
+// Graph is a directed graph of registry entities where edges point from entities to their dependencies.
+// Only references to the exact CTIs of the registry entities are taken into account.
+// References of the entity to itself are allowed recursion and are not included in the graph.
+type Graph struct {
+	nodes []string
+	out   map[string][]Edge
+	in    map[string][]Edge
+}
+
+// New builds the graph of the registry entities.
+func New(r *collector.MetadataRegistry) (*Graph, error) {
+	g := &Graph{
+		nodes: make([]string, 0, len(r.Index)),
+		out:   make(map[string][]Edge, len(r.Index)),
+		in:    make(map[string][]Edge, len(r.Index)),
+	}
+	for id := range r.Index {
+		g.nodes = append(g.nodes, id)
+	}
+	sort.Strings(g.nodes)
+
+	for _, id := range g.nodes {
+		deps, err := r.GetDependencyKinds(id)
+		if err != nil {
+			return nil, fmt.Errorf("collect edges of %s: %w", id, err)
+		}
+		for _, dep := range deps {
+			edge := Edge{From: id, To: dep.Cti, Kind: dep.Kind}
+			g.out[id] = append(g.out[id], edge)
+			g.in[dep.Cti] = append(g.in[dep.Cti], edge)
+		}
+	}
+	for _, edges := range g.in {
+		sortEdges(edges)
+	}
+	return g, nil
+}
+
+// Nodes returns CTIs of all entities of the graph sorted by CTI.
+func (g *Graph) Nodes() []string {
+	return append([]string(nil), g.nodes...)
+}
+
+// Edges returns direct dependencies of the entity sorted by CTI and kind.
+func (g *Graph) Edges(cti string) []Edge {
+	return append([]Edge(nil), g.out[cti]...)
+}
+
+// InEdges returns direct dependents of the entity sorted by CTI and kind.
+func (g *Graph) InEdges(cti string) []Edge {
+	return append([]Edge(nil), g.in[cti]...)
+}
+
+// Dependencies returns CTIs of entities that the entity depends on directly or transitively sorted by CTI.
+func (g *Graph) Dependencies(cti string) []string {
+	return g.reachable(cti, func(e Edge) string { return e.To }, g.out)
+}
+
+// Dependents returns CTIs of entities that depend on the entity directly or transitively sorted by CTI.
+// This is the set of entities that are affected by changes of the entity.
+func (g *Graph) Dependents(cti string) []string {
+	return g.reachable(cti, func(e Edge) string { return e.From }, g.in)
+}
+
+func (g *Graph) reachable(cti string, next func(Edge) string, edges map[string][]Edge) []string {
+	visited := map[string]struct{}{cti: {}}
+	queue := []string{cti}
+	var res []string
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range edges[id] {
+			to := next(edge)
+			if _, ok := visited[to]; ok {
+				continue
+			}
+			visited[to] = struct{}{}
+			res = append(res, to)
+			queue = append(queue, to)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Cycles returns cycles of the graph. Each cycle is a strongly connected component of several entities
+// sorted by CTI. Cycles are sorted by their first CTI.
+func (g *Graph) Cycles() [][]string {
+	t := &tarjan{
+		g:       g,
+		index:   make(map[string]int, len(g.nodes)),
+		lowlink: make(map[string]int, len(g.nodes)),
+		onStack: make(map[string]bool, len(g.nodes)),
+	}
+	for _, id := range g.nodes {
+		if _, ok := t.index[id]; !ok {
+			t.connect(id)
+		}
+	}
+	sort.Slice(t.cycles, func(i, j int) bool {
+		return t.cycles[i][0] < t.cycles[j][0]
+	})
+	return t.cycles
+}
+
+// TopologicalOrder returns CTIs of all entities where dependencies precede their dependents.
+// Entities that do not depend on each other are ordered by CTI. Fails if the graph has cycles.
+func (g *Graph) TopologicalOrder() ([]string, error) {
+	if cycles := g.Cycles(); len(cycles) != 0 {
+		descs := make([]string, 0, len(cycles))
+		for _, cycle := range cycles {
+			descs = append(descs, "["+strings.Join(cycle, ", ")+"]")
+		}
+		return nil, fmt.Errorf("dependency cycles: %s", strings.Join(descs, ", "))
+	}
+
+	pending := make(map[string]int, len(g.nodes))
+	var ready []string
+	for _, id := range g.nodes {
+		pending[id] = len(g.out[id])
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	res := make([]string, 0, len(g.nodes))
+	for len(ready) != 0 {
+		id := ready[0]
+		ready = ready[1:]
+		res = append(res, id)
+		var next []string
+		for _, edge := range g.in[id] {
+			pending[edge.From]--
+			if pending[edge.From] == 0 {
+				next = append(next, edge.From)
+			}
+		}
+		ready = mergeSorted(ready, next)
+	}
+	return res, nil
+}
+
+type tarjan struct {
+	g       *Graph
+	counter int
+	index   map[string]int
+	lowlink map[string]int
+	onStack map[string]bool
+	stack   []string
+	cycles  [][]string
+}
+
+func (t *tarjan) connect(id string) {
+	t.index[id] = t.counter
+	t.lowlink[id] = t.counter
+	t.counter++
+	t.stack = append(t.stack, id)
+	t.onStack[id] = true
+
+	for _, edge := range t.g.out[id] {
+		if _, ok := t.index[edge.To]; !ok {
+			t.connect(edge.To)
+			if t.lowlink[edge.To] < t.lowlink[id] {
+				t.lowlink[id] = t.lowlink[edge.To]
+			}
+		} else if t.onStack[edge.To] && t.index[edge.To] < t.lowlink[id] {
+			t.lowlink[id] = t.index[edge.To]
+		}
+	}
+
+	if t.lowlink[id] != t.index[id] {
+		return
+	}
+	var component []string
+	for {
+		top := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[top] = false
+		component = append(component, top)
+		if top == id {
+			break
+		}
+	}
+	if len(component) > 1 {
+		sort.Strings(component)
+		t.cycles = append(t.cycles, component)
+	}
+}
+
+// mergeSorted merges the sorted queue with new items keeping the result sorted.
+func mergeSorted(queue, items []string) []string {
+	if len(items) == 0 {
+		return queue
+	}
+	res := append(queue, items...)
+	sort.Strings(res)
+	return res
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Kind < edges[j].Kind
+	})
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func makeRegistry(t *testing.T, entities ...*metadata.Entity) *collector.MetadataRegistry {
+	t.Helper()
+
+	r := collector.NewMetadataRegistry()
+	for _, e := range entities {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	return r
+}
+
+func Test_Graph(t *testing.T) {
+	r := makeRegistry(t,
+		&metadata.Entity{Cti: "cti.x.y.topic.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".parent": {Reference: "cti.x.y.topic.v1.0"},
+		}},
+		&metadata.Entity{Cti: "cti.x.y.topic.v1.0~x.y.users.v1.0", Values: []byte(`{}`)},
+		&metadata.Entity{Cti: "cti.x.y.payload.v1.0", Schema: []byte(`{}`)},
+		&metadata.Entity{Cti: "cti.x.y.event.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".topic": {Reference: "cti.x.y.topic.v1.0"},
+			".data":  {Schema: []any{"cti.x.y.payload.v1.0", "cti.x.y.unknown.v1.0"}},
+		}},
+		&metadata.Entity{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Schema: []byte(`{}`)},
+	)
+	g, err := New(r)
+	require.NoError(t, err)
+
+	require.Equal(t, []Edge{
+		{From: "cti.x.y.event.v1.0", To: "cti.x.y.payload.v1.0", Kind: EdgeSchema},
+		{From: "cti.x.y.event.v1.0", To: "cti.x.y.topic.v1.0", Kind: EdgeReference},
+	}, g.Edges("cti.x.y.event.v1.0"))
+	require.Equal(t, []Edge{
+		{From: "cti.x.y.event.v1.0", To: "cti.x.y.topic.v1.0", Kind: EdgeReference},
+		{From: "cti.x.y.topic.v1.0~x.y.users.v1.0", To: "cti.x.y.topic.v1.0", Kind: EdgeInheritance},
+	}, g.InEdges("cti.x.y.topic.v1.0"))
+
+	require.Equal(t, []string{"cti.x.y.event.v1.0", "cti.x.y.payload.v1.0", "cti.x.y.topic.v1.0"},
+		g.Dependencies("cti.x.y.event.v1.0~x.y.created.v1.0"))
+	require.Equal(t, []string{"cti.x.y.event.v1.0", "cti.x.y.event.v1.0~x.y.created.v1.0", "cti.x.y.topic.v1.0~x.y.users.v1.0"},
+		g.Dependents("cti.x.y.topic.v1.0"))
+
+	// Self references are allowed recursion.
+	require.Empty(t, g.Cycles())
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"cti.x.y.payload.v1.0",
+		"cti.x.y.topic.v1.0",
+		"cti.x.y.event.v1.0",
+		"cti.x.y.event.v1.0~x.y.created.v1.0",
+		"cti.x.y.topic.v1.0~x.y.users.v1.0",
+	}, order)
+}
+
+func Test_Graph_Cycles(t *testing.T) {
+	r := makeRegistry(t,
+		&metadata.Entity{Cti: "cti.x.y.a.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".b": {Reference: "cti.x.y.b.v1.0"},
+		}},
+		&metadata.Entity{Cti: "cti.x.y.b.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".c": {Schema: "cti.x.y.c.v1.0"},
+		}},
+		&metadata.Entity{Cti: "cti.x.y.c.v1.0", Schema: []byte(`{}`), Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".a": {Reference: "cti.x.y.a.v1.0"},
+		}},
+		&metadata.Entity{Cti: "cti.x.y.c.v1.0~x.y.d.v1.0", Schema: []byte(`{}`)},
+	)
+	g, err := New(r)
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{{"cti.x.y.a.v1.0", "cti.x.y.b.v1.0", "cti.x.y.c.v1.0"}}, g.Cycles())
+	_, err = g.TopologicalOrder()
+	require.EqualError(t, err, "dependency cycles: [cti.x.y.a.v1.0, cti.x.y.b.v1.0, cti.x.y.c.v1.0]")
+	require.Equal(t, []string{"cti.x.y.b.v1.0", "cti.x.y.c.v1.0", "cti.x.y.c.v1.0~x.y.d.v1.0"},
+		g.Dependents("cti.x.y.a.v1.0"))
+}
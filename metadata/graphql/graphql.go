@@ -0,0 +1,554 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+const (
+	// AnyScalarKey is a key of the scalar mapping used for values that cannot be expressed with GraphQL types:
+	// untyped values, objects without properties, heterogeneous unions and non-string enums.
+	AnyScalarKey = "any"
+
+	// TraitsDirective is a name of the directive that holds traits of the type as a JSON string, see WithTraits.
+	TraitsDirective = "traits"
+)
+
+var builtinScalars = map[string]struct{}{
+	"String":  {},
+	"Int":     {},
+	"Float":   {},
+	"Boolean": {},
+	"ID":      {},
+}
+
+type options struct {
+	scalars map[string]string
+	filter  *cti.Expression
+
+	withTraits bool
+	traitKeys  []string
+}
+
+type Option func(*options) error
+
+// WithScalar maps a JSON schema type (string, integer, number, boolean), a string format (e.g. date-time)
+// or AnyScalarKey to a GraphQL scalar. Formats take precedence over types.
+// Scalars that are not built into GraphQL are declared in the generated SDL.
+func WithScalar(key string, scalar string) Option {
+	return func(o *options) error {
+		if !isName(scalar) {
+			return fmt.Errorf("invalid scalar name %s", scalar)
+		}
+		o.scalars[key] = scalar
+		return nil
+	}
+}
+
+// WithFilter keeps only types that match the CTI expression.
+func WithFilter(expr string) Option {
+	return func(o *options) error {
+		if expr == "" {
+			return nil
+		}
+		e, err := cti.Parse(expr)
+		if err != nil {
+			return fmt.Errorf("parse filter: %w", err)
+		}
+		o.filter = &e
+		return nil
+	}
+}
+
+// WithTraits annotates object types with the @traits directive that holds traits of the type merged with traits
+// of its ancestors as a JSON string, e.g. @traits(values: "{\"severity\":\"high\"}").
+// Only the listed top-level traits are included if keys are specified. Types without traits are not annotated.
+func WithTraits(keys ...string) Option {
+	return func(o *options) error {
+		o.withTraits = true
+		o.traitKeys = append(o.traitKeys, keys...)
+		return nil
+	}
+}
+
+// Generate writes GraphQL SDL type definitions of CTI types of the registry.
+// Every CTI type is flattened into a single object type using its merged schema,
+// so inherited properties are included. Nested objects, enums and unions from anyOf
+// are emitted as separate definitions named after the enclosing type and property.
+func Generate(w io.Writer, r *collector.MetadataRegistry, opts ...Option) error {
+	o := options{
+		scalars: map[string]string{
+			"string":     "String",
+			"integer":    "Int",
+			"number":     "Float",
+			"boolean":    "Boolean",
+			AnyScalarKey: "JSON",
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return err
+		}
+	}
+
+	// Minor versions are backward compatible, so only the latest minor version of the type is emitted.
+	latest := make(map[string]string)
+	for id := range r.Types {
+		if o.filter != nil {
+			expr, err := cti.Parse(id)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", id, err)
+			}
+			ok, err := o.filter.Match(expr)
+			if err != nil {
+				return fmt.Errorf("match %s: %w", id, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		name, err := TypeName(id)
+		if err != nil {
+			return err
+		}
+		if other, ok := latest[name]; ok {
+			newer, err := isNewerMinor(id, other)
+			if err != nil {
+				return err
+			}
+			if !newer {
+				continue
+			}
+		}
+		latest[name] = id
+	}
+
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g := &generator{
+		options:     o,
+		names:       make(map[string]struct{}),
+		usedScalars: make(map[string]struct{}),
+	}
+	for _, name := range names {
+		g.names[name] = struct{}{}
+	}
+	for _, name := range names {
+		id := latest[name]
+		if err := g.addType(r, id, name); err != nil {
+			return fmt.Errorf("generate type of %s: %w", id, err)
+		}
+	}
+
+	return g.write(w)
+}
+
+type generator struct {
+	options
+
+	defs        []string
+	names       map[string]struct{}
+	usedScalars map[string]struct{}
+	usedTraits  bool
+
+	// definitions are JSON schema definitions of the current CTI type and its parents.
+	definitions map[string]any
+}
+
+func (g *generator) addType(r *collector.MetadataRegistry, id string, name string) error {
+	schema, err := merger.GetMergedCtiSchema(id, r)
+	if err != nil {
+		return fmt.Errorf("get merged schema: %w", err)
+	}
+	g.definitions, err = collectDefinitions(r, id)
+	if err != nil {
+		return err
+	}
+	if r.Types[id].Description != "" {
+		if _, ok := schema["description"]; !ok {
+			schema["description"] = r.Types[id].Description
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]any); !ok || len(properties) == 0 {
+		// GraphQL object types must have fields, so a type without properties is declared as a scalar.
+		g.usedScalars[name] = struct{}{}
+		return nil
+	}
+	directive, err := g.traitsDirective(r, id)
+	if err != nil {
+		return err
+	}
+	return g.addObject(name, directive, schema)
+}
+
+// traitsDirective returns the @traits directive of the type prefixed with space
+// or an empty string if traits are not included or the type has no traits.
+func (g *generator) traitsDirective(r *collector.MetadataRegistry, id string) (string, error) {
+	if !g.withTraits {
+		return "", nil
+	}
+	traits, err := r.GetMergedTraits(id)
+	if err != nil {
+		return "", fmt.Errorf("get merged traits: %w", err)
+	}
+	if len(g.traitKeys) != 0 {
+		selected := make(map[string]any, len(g.traitKeys))
+		for _, key := range g.traitKeys {
+			if val, ok := traits[key]; ok {
+				selected[key] = val
+			}
+		}
+		traits = selected
+	}
+	if len(traits) == 0 {
+		return "", nil
+	}
+	// Keys of maps are sorted by encoding/json, so the output is deterministic.
+	values, err := json.Marshal(traits)
+	if err != nil {
+		return "", fmt.Errorf("marshal traits: %w", err)
+	}
+	g.usedTraits = true
+	return fmt.Sprintf(" @%s(values: %s)", TraitsDirective, strconv.Quote(string(values))), nil
+}
+
+func (g *generator) addObject(name string, directive string, schema map[string]any) error {
+	properties, _ := schema["properties"].(map[string]any)
+	required := jsonschema.RequiredSet(schema)
+
+	props := make([]string, 0, len(properties))
+	for prop := range properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	// Reserve a slot so the object precedes definitions of its properties.
+	idx := len(g.defs)
+	g.defs = append(g.defs, "")
+
+	var sb strings.Builder
+	writeDescription(&sb, "", schema)
+	fmt.Fprintf(&sb, "type %s%s {\n", name, directive)
+	for _, prop := range props {
+		propSchema, ok := properties[prop].(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid schema of property %s", prop)
+		}
+		typ, err := g.typeRef(propSchema, name+pascalCase(prop))
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+		if _, ok := required[prop]; ok {
+			typ += "!"
+		}
+		writeDescription(&sb, "  ", propSchema)
+		fmt.Fprintf(&sb, "  %s: %s\n", fieldName(prop), typ)
+	}
+	sb.WriteString("}\n")
+	g.defs[idx] = sb.String()
+	return nil
+}
+
+//nolint:gocyclo // dispatch by schema type
+func (g *generator) typeRef(schema map[string]any, hint string) (string, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		defName := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := g.definitions[defName].(map[string]any)
+		if !ok {
+			return g.scalar(AnyScalarKey), nil
+		}
+		return g.typeRef(def, pascalCase(defName))
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) != 0 {
+		return g.enumRef(enum, hint, schema)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && len(anyOf) != 0 {
+		return g.unionRef(anyOf, hint)
+	}
+
+	switch typ := jsonschema.SchemaType(schema); typ {
+	case "object":
+		if properties, ok := schema["properties"].(map[string]any); !ok || len(properties) == 0 {
+			return g.scalar(AnyScalarKey), nil
+		}
+		if _, ok := g.names[hint]; ok {
+			// Named definition is already emitted or is being emitted (recursive reference).
+			return hint, nil
+		}
+		g.names[hint] = struct{}{}
+		if err := g.addObject(hint, "", schema); err != nil {
+			return "", err
+		}
+		return hint, nil
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return "[" + g.scalar(AnyScalarKey) + "]", nil
+		}
+		item, err := g.typeRef(items, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[" + item + "]", nil
+	case "string", "integer", "number", "boolean":
+		if format, ok := schema["format"].(string); ok {
+			if _, ok := g.scalars[format]; ok {
+				return g.scalar(format), nil
+			}
+		}
+		return g.scalar(typ), nil
+	default:
+		return g.scalar(AnyScalarKey), nil
+	}
+}
+
+func (g *generator) enumRef(enum []any, hint string, schema map[string]any) (string, error) {
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		s, ok := v.(string)
+		if !ok || !isName(s) || s == "true" || s == "false" || s == "null" {
+			// GraphQL enum values must be names, fall back to the scalar of the type.
+			if typ := jsonschema.SchemaType(schema); typ != "" {
+				return g.scalar(typ), nil
+			}
+			return g.scalar(AnyScalarKey), nil
+		}
+		values = append(values, s)
+	}
+	if _, ok := g.names[hint]; ok {
+		return hint, nil
+	}
+	g.names[hint] = struct{}{}
+
+	var sb strings.Builder
+	writeDescription(&sb, "", schema)
+	fmt.Fprintf(&sb, "enum %s {\n", hint)
+	for _, v := range values {
+		fmt.Fprintf(&sb, "  %s\n", v)
+	}
+	sb.WriteString("}\n")
+	g.defs = append(g.defs, sb.String())
+	return hint, nil
+}
+
+func (g *generator) unionRef(anyOf []any, hint string) (string, error) {
+	var members []map[string]any
+	for _, item := range anyOf {
+		member, ok := item.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("invalid anyOf member")
+		}
+		if jsonschema.SchemaType(member) == "null" {
+			continue
+		}
+		members = append(members, member)
+	}
+	if len(members) == 1 {
+		return g.typeRef(members[0], hint)
+	}
+	for _, member := range members {
+		if !g.isObject(member) {
+			// GraphQL unions may contain only object types.
+			return g.scalar(AnyScalarKey), nil
+		}
+	}
+	if _, ok := g.names[hint]; ok {
+		return hint, nil
+	}
+	g.names[hint] = struct{}{}
+	idx := len(g.defs)
+	g.defs = append(g.defs, "")
+
+	types := make([]string, 0, len(members))
+	for i, member := range members {
+		typ, err := g.typeRef(member, hint+"Option"+strconv.Itoa(i+1))
+		if err != nil {
+			return "", err
+		}
+		types = append(types, typ)
+	}
+	g.defs[idx] = fmt.Sprintf("union %s = %s\n", hint, strings.Join(types, " | "))
+	return hint, nil
+}
+
+func (g *generator) isObject(schema map[string]any) bool {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := g.definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		return ok && g.isObject(def)
+	}
+	properties, ok := schema["properties"].(map[string]any)
+	return jsonschema.SchemaType(schema) == "object" && ok && len(properties) != 0
+}
+
+func (g *generator) scalar(key string) string {
+	scalar, ok := g.scalars[key]
+	if !ok {
+		scalar = g.scalars[AnyScalarKey]
+	}
+	if _, ok := builtinScalars[scalar]; !ok {
+		g.usedScalars[scalar] = struct{}{}
+	}
+	return scalar
+}
+
+func (g *generator) write(w io.Writer) error {
+	scalars := make([]string, 0, len(g.usedScalars))
+	for scalar := range g.usedScalars {
+		scalars = append(scalars, scalar)
+	}
+	sort.Strings(scalars)
+
+	var sb strings.Builder
+	if g.usedTraits {
+		fmt.Fprintf(&sb, "directive @%s(values: String!) on OBJECT\n\n", TraitsDirective)
+	}
+	for _, scalar := range scalars {
+		fmt.Fprintf(&sb, "scalar %s\n", scalar)
+	}
+	for i, def := range g.defs {
+		if i != 0 || len(scalars) != 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(def)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// TypeName returns the GraphQL type name of the CTI type.
+// The name is made of vendor, package, entity name and major version of every node of the CTI,
+// so minor versions of the same type share the name,
+// for example, cti.a.p.event.v1.0 becomes APEventV1.
+func TypeName(id string) (string, error) {
+	expr, err := cti.ParseIdentifier(id)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", id, err)
+	}
+	var sb strings.Builder
+	for node := expr.Head; node != nil; node = node.Child {
+		sb.WriteString(pascalCase(string(node.Vendor)))
+		sb.WriteString(pascalCase(string(node.Package)))
+		sb.WriteString(pascalCase(string(node.EntityName)))
+		if node.Version.Major.Valid {
+			fmt.Fprintf(&sb, "V%d", node.Version.Major.Value)
+		}
+	}
+	return sb.String(), nil
+}
+
+// isNewerMinor reports whether the CTI a has greater minor versions than the CTI b with the same type name.
+// Minor versions are compared node by node from the head, CTIs are compared if all minor versions are equal.
+func isNewerMinor(a, b string) (bool, error) {
+	exprA, err := cti.ParseIdentifier(a)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", a, err)
+	}
+	exprB, err := cti.ParseIdentifier(b)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", b, err)
+	}
+	for nodeA, nodeB := exprA.Head, exprB.Head; nodeA != nil && nodeB != nil; nodeA, nodeB = nodeA.Child, nodeB.Child {
+		if nodeA.Version.Minor.Value != nodeB.Version.Minor.Value {
+			return nodeA.Version.Minor.Value > nodeB.Version.Minor.Value, nil
+		}
+	}
+	return a > b, nil
+}
+
+func collectDefinitions(r *collector.MetadataRegistry, id string) (map[string]any, error) {
+	definitions := make(map[string]any)
+	for cur := id; ; {
+		entity, ok := r.Index[cur]
+		if !ok {
+			return nil, fmt.Errorf("failed to find cti %s", cur)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+			return nil, fmt.Errorf("unmarshal schema of %s: %w", cur, err)
+		}
+		if defs, ok := schema["definitions"].(map[string]any); ok {
+			for name, def := range defs {
+				// Definitions of the child take precedence.
+				if _, ok := definitions[name]; !ok {
+					definitions[name] = def
+				}
+			}
+		}
+		parent := metadata.GetParentCti(cur)
+		if parent == cur {
+			return definitions, nil
+		}
+		cur = parent
+	}
+}
+
+func writeDescription(sb *strings.Builder, indent string, schema map[string]any) {
+	description, ok := schema["description"].(string)
+	if !ok || description == "" {
+		return
+	}
+	fmt.Fprintf(sb, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(strings.ReplaceAll(description, `"""`, `\"""`), "\n") {
+		fmt.Fprintf(sb, "%s%s\n", indent, line)
+	}
+	fmt.Fprintf(sb, "%s\"\"\"\n", indent)
+}
+
+// fieldName replaces characters that are not allowed in GraphQL names with underscores.
+func fieldName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !isNameChar(c) || (i == 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func pascalCase(s string) string {
+	var sb strings.Builder
+	upper := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isNameChar(c) || c == '_' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func isName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
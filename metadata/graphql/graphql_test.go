@@ -0,0 +1,182 @@
+package graphql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_Generate(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:         "cti.x.y.event.v1.0",
+		Description: "Base event.",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Event",
+			"definitions": {
+				"Event": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "string", "format": "uuid"},
+						"severity": {"type": "string", "enum": ["low", "high"]},
+						"created_at": {"type": "string", "format": "date-time"}
+					},
+					"required": ["id"]
+				}
+			}
+		}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.event.v1.0~x.y.created.v1.0",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Created",
+			"definitions": {
+				"Created": {
+					"type": "object",
+					"properties": {
+						"payload": {
+							"anyOf": [
+								{"type": "object", "properties": {"name": {"type": "string"}}},
+								{"type": "object", "properties": {"size": {"type": "integer"}}}
+							]
+						},
+						"labels": {"type": "array", "items": {"type": "string"}},
+						"extra": {"type": "object"},
+						"owner": {"$ref": "#/definitions/Owner"}
+					},
+					"required": ["labels"]
+				},
+				"Owner": {
+					"type": "object",
+					"properties": {"name": {"type": "string"}},
+					"required": ["name"]
+				}
+			}
+		}`),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, r, WithScalar("date-time", "DateTime"), WithScalar("uuid", "ID")))
+	require.Equal(t, `scalar DateTime
+scalar JSON
+
+"""
+Base event.
+"""
+type XYEventV1 {
+  created_at: DateTime
+  id: ID!
+  severity: XYEventV1Severity
+}
+
+enum XYEventV1Severity {
+  low
+  high
+}
+
+type XYEventV1XYCreatedV1 {
+  created_at: DateTime
+  extra: JSON
+  id: ID!
+  labels: [String]!
+  owner: Owner
+  payload: XYEventV1XYCreatedV1Payload
+  severity: XYEventV1XYCreatedV1Severity
+}
+
+type Owner {
+  name: String!
+}
+
+union XYEventV1XYCreatedV1Payload = XYEventV1XYCreatedV1PayloadOption1 | XYEventV1XYCreatedV1PayloadOption2
+
+type XYEventV1XYCreatedV1PayloadOption1 {
+  name: String
+}
+
+type XYEventV1XYCreatedV1PayloadOption2 {
+  size: Int
+}
+
+enum XYEventV1XYCreatedV1Severity {
+  low
+  high
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, Generate(&buf, r, WithFilter("cti.x.y.event.v1.0~x.y.created.v1.0")))
+	require.Contains(t, buf.String(), "type XYEventV1XYCreatedV1 {")
+	require.NotContains(t, buf.String(), "type XYEventV1 {")
+
+	require.ErrorContains(t, Generate(&buf, r, WithScalar("string", "not-a-name")), "invalid scalar name")
+}
+
+func Test_GenerateLatestMinor(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, id := range []string{"cti.x.y.topic.v1.0", "cti.x.y.topic.v1.2", "cti.x.y.topic.v1.1"} {
+		require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+			Cti: id,
+			Schema: []byte(`{
+				"$ref": "#/definitions/Topic",
+				"definitions": {
+					"Topic": {
+						"type": "object",
+						"properties": {"name": {"type": "string", "description": "` + id + `"}}
+					}
+				}
+			}`),
+		}))
+	}
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.empty.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Empty", "definitions": {"Empty": {"type": "object"}}}`),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, r))
+	require.Equal(t, `scalar XYEmptyV1
+
+type XYTopicV1 {
+  """
+  cti.x.y.topic.v1.2
+  """
+  name: String
+}
+`, buf.String())
+}
+
+func Test_GenerateTraits(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:          "cti.x.y.alert.v1.0",
+		Schema:       []byte(`{"$ref": "#/definitions/Alert", "definitions": {"Alert": {"type": "object", "properties": {"id": {"type": "string"}}}}}`),
+		TraitsSchema: []byte(`{"type": "object"}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.alert.v1.0~x.y.disk.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Disk", "definitions": {"Disk": {"type": "object", "properties": {"id": {"type": "string"}}}}}`),
+		Traits: []byte(`{"severity": "high", "category": "storage"}`),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, r, WithTraits()))
+	require.Equal(t, `directive @traits(values: String!) on OBJECT
+
+type XYAlertV1 {
+  id: String
+}
+
+type XYAlertV1XYDiskV1 @traits(values: "{\"category\":\"storage\",\"severity\":\"high\"}") {
+  id: String
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, Generate(&buf, r, WithTraits("unknown")))
+	require.NotContains(t, buf.String(), "@traits")
+}
@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// rxTraitPlaceholder matches placeholders of trait values that reference attributes of instances,
+// e.g. ${@display_name} or ${@owner.name}.
+var rxTraitPlaceholder = regexp.MustCompile(`\$\{@([A-Za-z0-9_]+(?:\.[A-Za-z0-9_]+)*)\}`)
+
+// TraitPlaceholders returns attributes referenced by placeholders of the trait values, e.g. display_name
+// for ${@display_name}. Nested attributes are separated by dots. Attributes are sorted and unique.
+func TraitPlaceholders(traits json.RawMessage) ([]string, error) {
+	if traits == nil {
+		return nil, nil
+	}
+	var val any
+	if err := json.Unmarshal(traits, &val); err != nil {
+		return nil, fmt.Errorf("unmarshal traits: %w", err)
+	}
+	set := make(map[string]struct{})
+	walkTraitStrings(val, func(s string) {
+		for _, m := range rxTraitPlaceholder.FindAllStringSubmatch(s, -1) {
+			set[m[1]] = struct{}{}
+		}
+	})
+	res := make([]string, 0, len(set))
+	for attr := range set {
+		res = append(res, attr)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// ResolveTraits returns traits of the type with placeholders resolved against values of the instance of the type,
+// e.g. ${@display_name} is replaced by the value of the display_name attribute of the instance.
+// A string that consists of a single placeholder is replaced by the attribute value as is, so it keeps its JSON type.
+// Placeholders embedded into strings are replaced by string values as is and by JSON of other values.
+// Attributes that the instance does not have are resolved to null and to an empty string respectively.
+func (e *Entity) ResolveTraits(instance *Entity) (map[string]any, error) {
+	if !strings.HasPrefix(instance.Cti, e.Cti+"~") {
+		return nil, fmt.Errorf("%s is not derived from %s", instance.Cti, e.Cti)
+	}
+	if e.Traits == nil {
+		return nil, nil
+	}
+	var traits map[string]any
+	if err := json.Unmarshal(e.Traits, &traits); err != nil {
+		return nil, fmt.Errorf("unmarshal traits of %s: %w", e.Cti, err)
+	}
+	return resolveTraitValue(traits, instance.Values).(map[string]any), nil
+}
+
+func resolveTraitValue(val any, values json.RawMessage) any {
+	switch v := val.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, item := range v {
+			res[k] = resolveTraitValue(item, values)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = resolveTraitValue(item, values)
+		}
+		return res
+	case string:
+		if m := rxTraitPlaceholder.FindStringSubmatch(v); m != nil && m[0] == v {
+			return gjson.GetBytes(values, m[1]).Value()
+		}
+		return rxTraitPlaceholder.ReplaceAllStringFunc(v, func(placeholder string) string {
+			attr := rxTraitPlaceholder.FindStringSubmatch(placeholder)[1]
+			res := gjson.GetBytes(values, attr)
+			if res.Type == gjson.String {
+				return res.Str
+			}
+			return res.Raw
+		})
+	default:
+		return v
+	}
+}
+
+func walkTraitStrings(val any, fn func(string)) {
+	switch v := val.(type) {
+	case map[string]any:
+		for _, item := range v {
+			walkTraitStrings(item, fn)
+		}
+	case []any:
+		for _, item := range v {
+			walkTraitStrings(item, fn)
+		}
+	case string:
+		fn(v)
+	}
+}
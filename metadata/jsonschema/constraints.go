@@ -0,0 +1,140 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+var (
+	lowerBoundKeywords = [...]string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBoundKeywords = [...]string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+// ConstraintChange is a change of a validation keyword between the old and the new version of a schema node.
+// Narrowed reports that the new version rejects some values accepted by the old one, Widened reports that
+// it accepts some values rejected by the old one. Replaced constraints (e.g. a different pattern) are both.
+// Old and New are values of the keyword, nil if the keyword is absent.
+type ConstraintChange struct {
+	Keyword  string
+	Old, New any
+	Narrowed bool
+	Widened  bool
+	Message  string
+}
+
+// CompareConstraints compares validation keywords of the old and the new version of the schema node: bounds,
+// multipleOf, enum, pattern, format, const, additionalProperties and uniqueItems. Nested schemas are not compared.
+// Changes are returned in this order of keywords.
+func CompareConstraints(old, cur map[string]any) []ConstraintChange {
+	var res []ConstraintChange
+	add := func(key string, narrowed, widened bool, format string, args ...any) {
+		res = append(res, ConstraintChange{
+			Keyword:  key,
+			Old:      old[key],
+			New:      cur[key],
+			Narrowed: narrowed,
+			Widened:  widened,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for i, keys := range [...][5]string{lowerBoundKeywords, upperBoundKeywords} {
+		lower := i == 0
+		for _, key := range keys {
+			o, ook := old[key].(float64)
+			c, cok := cur[key].(float64)
+			switch {
+			case ook && cok && c > o:
+				add(key, lower, !lower, "%s is raised from %v to %v", key, o, c)
+			case ook && cok && c < o:
+				add(key, !lower, lower, "%s is lowered from %v to %v", key, o, c)
+			case cok && !ook:
+				add(key, true, false, "%s %v is added", key, c)
+			case ook && !cok:
+				add(key, false, true, "%s %v is removed", key, o)
+			}
+		}
+	}
+
+	o, ook := old["multipleOf"].(float64)
+	c, cok := cur["multipleOf"].(float64)
+	switch {
+	case ook && cok && o != c:
+		narrowed := o != 0 && math.Mod(c, o) == 0
+		widened := c != 0 && math.Mod(o, c) == 0
+		if !narrowed && !widened {
+			narrowed, widened = true, true
+		}
+		add("multipleOf", narrowed, widened, "multipleOf is changed from %v to %v", o, c)
+	case cok && !ook:
+		add("multipleOf", true, false, "multipleOf %v is added", c)
+	case ook && !cok:
+		add("multipleOf", false, true, "multipleOf %v is removed", o)
+	}
+
+	oldEnum, ook := old["enum"].([]any)
+	curEnum, cok := cur["enum"].([]any)
+	switch {
+	case ook && cok:
+		if removed := missingValues(oldEnum, curEnum); len(removed) != 0 {
+			add("enum", true, false, "enum values are removed: %s", strings.Join(removed, ", "))
+		}
+		if added := missingValues(curEnum, oldEnum); len(added) != 0 {
+			add("enum", false, true, "enum values are added: %s", strings.Join(added, ", "))
+		}
+	case cok:
+		add("enum", true, false, "enum is added")
+	case ook:
+		add("enum", false, true, "enum is removed")
+	}
+
+	for _, key := range [...]string{"pattern", "format", "const"} {
+		o, ook := old[key]
+		c, cok := cur[key]
+		switch {
+		case ook && cok && !reflect.DeepEqual(o, c):
+			add(key, true, true, "%s is changed from %v to %v", key, o, c)
+		case cok && !ook:
+			add(key, true, false, "%s %v is added", key, c)
+		case ook && !cok:
+			add(key, false, true, "%s %v is removed", key, o)
+		}
+	}
+
+	// Absent keywords default to allowed additional properties and not unique items.
+	oldAdditional, ook := old["additionalProperties"].(bool)
+	curAdditional, cok := cur["additionalProperties"].(bool)
+	if cok && !curAdditional && (!ook || oldAdditional) {
+		add("additionalProperties", true, false, "additional properties are forbidden")
+	} else if ook && !oldAdditional && (!cok || curAdditional) {
+		add("additionalProperties", false, true, "additional properties are allowed")
+	}
+	oldUnique, _ := old["uniqueItems"].(bool)
+	curUnique, _ := cur["uniqueItems"].(bool)
+	if curUnique && !oldUnique {
+		add("uniqueItems", true, false, "unique items are required")
+	} else if oldUnique && !curUnique {
+		add("uniqueItems", false, true, "unique items are not required")
+	}
+	return res
+}
+
+// missingValues returns values that are not in the other values formatted with fmt.Sprint.
+func missingValues(values, other []any) []string {
+	var res []string
+	for _, v := range values {
+		found := false
+		for _, o := range other {
+			if reflect.DeepEqual(v, o) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, fmt.Sprint(v))
+		}
+	}
+	return res
+}
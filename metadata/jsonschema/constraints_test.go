@@ -0,0 +1,76 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CompareConstraints(t *testing.T) {
+	type direction struct {
+		narrowed, widened bool
+	}
+	testCases := []struct {
+		name     string
+		old, cur string
+		messages []string
+		dirs     []direction
+	}{
+		{
+			name:     "bounds",
+			old:      `{"minLength": 1, "maxLength": 10, "minimum": 0}`,
+			cur:      `{"minLength": 2, "maxLength": 20, "maximum": 5}`,
+			messages: []string{"minimum 0 is removed", "minLength is raised from 1 to 2", "maximum 5 is added", "maxLength is raised from 10 to 20"},
+			dirs:     []direction{{widened: true}, {narrowed: true}, {narrowed: true}, {widened: true}},
+		},
+		{
+			name:     "multipleOf",
+			old:      `{"multipleOf": 2}`,
+			cur:      `{"multipleOf": 3}`,
+			messages: []string{"multipleOf is changed from 2 to 3"},
+			dirs:     []direction{{narrowed: true, widened: true}},
+		},
+		{
+			name:     "enum",
+			old:      `{"enum": ["a", "b"]}`,
+			cur:      `{"enum": ["b", "c"]}`,
+			messages: []string{"enum values are removed: a", "enum values are added: c"},
+			dirs:     []direction{{narrowed: true}, {widened: true}},
+		},
+		{
+			name:     "pattern",
+			old:      `{"pattern": "^a$"}`,
+			cur:      `{"pattern": "^b$", "format": "email"}`,
+			messages: []string{"pattern is changed from ^a$ to ^b$", "format email is added"},
+			dirs:     []direction{{narrowed: true, widened: true}, {narrowed: true}},
+		},
+		{
+			name:     "flags",
+			old:      `{"additionalProperties": false}`,
+			cur:      `{"uniqueItems": true}`,
+			messages: []string{"additional properties are allowed", "unique items are required"},
+			dirs:     []direction{{widened: true}, {narrowed: true}},
+		},
+		{
+			name: "unchanged",
+			old:  `{"type": "string", "minLength": 1, "enum": ["a"], "additionalProperties": true}`,
+			cur:  `{"type": "integer", "minLength": 1, "enum": ["a"]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var old, cur map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.old), &old))
+			require.NoError(t, json.Unmarshal([]byte(tc.cur), &cur))
+
+			changes := CompareConstraints(old, cur)
+			require.Len(t, changes, len(tc.messages))
+			for i, change := range changes {
+				require.Equal(t, tc.messages[i], change.Message)
+				require.Equal(t, tc.dirs[i], direction{narrowed: change.Narrowed, widened: change.Widened})
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package jsonschema
+
+// ApplyDefaults returns a copy of the values where missing properties are filled with the "default" values
+// defined by the schema. The schema is expected to be a merged schema of CTI type (see merger.GetMergedCtiSchema).
+//
+// Defaults are applied recursively:
+//   - a missing property is set to its default value, if the property schema defines one;
+//   - a missing required object property without default is created if its schema defines nested defaults;
+//   - present objects and array items are completed according to their schemas;
+//   - for anyOf, defaults of the first branch that is compatible with the value are applied.
+//
+// The input values are not modified.
+func (s JSONSchemaCTI) ApplyDefaults(values map[string]any) map[string]any {
+	if values == nil {
+		values = map[string]any{}
+	}
+	res, _ := applyDefaults(s, s, values).(map[string]any)
+	return res
+}
+
+func applyDefaults(root, schema map[string]any, value any) any {
+	schema = ResolveRef(root, schema)
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, item := range anyOf {
+			branch, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			branch = ResolveRef(root, branch)
+			if compatible(branch, value) {
+				return applyDefaults(root, branch, value)
+			}
+		}
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		res := make(map[string]any, len(v)+len(properties))
+		for key, item := range v {
+			res[key] = item
+		}
+		for key, item := range properties {
+			propSchema, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			propSchema = ResolveRef(root, propSchema)
+			if propValue, ok := res[key]; ok {
+				res[key] = applyDefaults(root, propSchema, propValue)
+				continue
+			}
+			if def, ok := propSchema["default"]; ok {
+				res[key] = DeepCopy(def)
+				continue
+			}
+			if _, ok := RequiredSet(schema)[key]; ok && hasNestedDefaults(root, propSchema) {
+				res[key] = applyDefaults(root, propSchema, map[string]any{})
+			}
+		}
+		return res
+	case []any:
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return v
+		}
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = applyDefaults(root, items, item)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// compatible reports whether the value may conform to the schema judging by its type and, for objects, its keys.
+func compatible(schema map[string]any, value any) bool {
+	typ, _ := schema["type"].(string)
+	switch v := value.(type) {
+	case map[string]any:
+		if typ != "" && typ != "object" {
+			return false
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		if properties == nil {
+			return true
+		}
+		for key := range v {
+			if _, ok := properties[key]; !ok {
+				return false
+			}
+		}
+		return true
+	case []any:
+		return typ == "" || typ == "array"
+	case string:
+		return typ == "" || typ == "string"
+	case bool:
+		return typ == "" || typ == "boolean"
+	case float64, int, int64:
+		return typ == "" || typ == "number" || typ == "integer"
+	case nil:
+		return typ == "" || typ == "null"
+	default:
+		return true
+	}
+}
+
+func hasNestedDefaults(root, schema map[string]any) bool {
+	properties, _ := schema["properties"].(map[string]any)
+	for _, item := range properties {
+		propSchema, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		propSchema = ResolveRef(root, propSchema)
+		if _, ok := propSchema["default"]; ok {
+			return true
+		}
+	}
+	return false
+}
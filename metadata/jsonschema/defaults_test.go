@@ -0,0 +1,62 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApplyDefaults(t *testing.T) {
+	schema, err := FromBytes([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"severity": {"type": "string", "default": "low"},
+			"tags": {"type": "array", "default": ["a"]},
+			"retry": {
+				"type": "object",
+				"properties": {"count": {"type": "integer", "default": 3}, "delay": {"type": "integer"}}
+			},
+			"limits": {
+				"type": "object",
+				"properties": {"max": {"type": "integer", "default": 10}}
+			},
+			"targets": {
+				"type": "array",
+				"items": {"type": "object", "properties": {"url": {"type": "string"}, "port": {"type": "integer", "default": 443}}}
+			},
+			"channel": {
+				"anyOf": [
+					{"type": "object", "properties": {"email": {"type": "string"}, "format": {"type": "string", "default": "html"}}},
+					{"type": "object", "properties": {"phone": {"type": "string"}, "provider": {"type": "string", "default": "sms"}}}
+				]
+			}
+		},
+		"required": ["retry"]
+	}`))
+	require.NoError(t, err)
+
+	values := map[string]any{
+		"name":     "alert",
+		"severity": "high",
+		"targets":  []any{map[string]any{"url": "a"}, map[string]any{"url": "b", "port": float64(80)}},
+		"channel":  map[string]any{"phone": "+1"},
+	}
+	res := schema.ApplyDefaults(values)
+	require.Equal(t, map[string]any{
+		"name":     "alert",
+		"severity": "high",
+		"tags":     []any{"a"},
+		"retry":    map[string]any{"count": float64(3)},
+		"targets":  []any{map[string]any{"url": "a", "port": float64(443)}, map[string]any{"url": "b", "port": float64(80)}},
+		"channel":  map[string]any{"phone": "+1", "provider": "sms"},
+	}, res)
+
+	// Input values are not modified.
+	require.Len(t, values, 4)
+	require.Equal(t, map[string]any{"url": "a"}, values["targets"].([]any)[0])
+
+	// Default values are copied.
+	res["tags"].([]any)[0] = "b"
+	require.Equal(t, []any{"a"}, schema.ApplyDefaults(nil)["tags"])
+}
@@ -0,0 +1,54 @@
+package jsonschema
+
+const domainExtensionPrefix = "x-domainExt-"
+
+// StripCTIExtensions returns a copy of the schema without the "x-custom" keyword that holds CTI annotations
+// and other RAML extensions, so the schema can be passed to validators that reject unknown keywords.
+// Annotations listed in keep (e.g. "cti.id", "cti.reference") are preserved, other extensions are removed.
+// The "x-custom" keyword is removed completely if none of its annotations are kept.
+// Properties and definitions named "x-custom" are schemas rather than extensions, so they are kept.
+// The input schema is not modified.
+func StripCTIExtensions(schema JSONSchemaCTI, keep ...string) JSONSchemaCTI {
+	kept := make(map[string]struct{}, len(keep))
+	for _, name := range keep {
+		kept[domainExtensionPrefix+name] = struct{}{}
+	}
+	res, _ := stripExtensions(map[string]any(schema), kept).(map[string]any)
+	return res
+}
+
+func stripExtensions(v any, kept map[string]struct{}) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res, _ := copySchemaKeywords(v, "", func(item any, _ string) (any, error) {
+			return stripExtensions(item, kept), nil
+		})
+		if _, ok := v[CustomKeyword]; ok {
+			if custom := keptExtensions(v[CustomKeyword], kept); len(custom) != 0 {
+				res[CustomKeyword] = custom
+			} else {
+				delete(res, CustomKeyword)
+			}
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = stripExtensions(item, kept)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+func keptExtensions(v any, kept map[string]struct{}) map[string]any {
+	extensions, _ := v.(map[string]any)
+	res := make(map[string]any)
+	for name, ext := range extensions {
+		if _, ok := kept[name]; ok {
+			res[name] = DeepCopy(ext)
+		}
+	}
+	return res
+}
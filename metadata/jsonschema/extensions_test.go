@@ -0,0 +1,74 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StripCTIExtensions(t *testing.T) {
+	schema, err := FromBytes([]byte(`{
+		"$ref": "#/definitions/Event",
+		"definitions": {
+			"Event": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string", "x-custom": {"x-domainExt-cti.id": true}},
+					"topic": {"type": "string", "x-custom": {"x-domainExt-cti.reference": "cti.a.p.topic.v1.0"}},
+					"data": {"type": "object", "default": {"x-custom": 1}}
+				},
+				"anyOf": [{"type": "object", "x-custom": {"x-domainExt-cti.final": true}}],
+				"x-custom": {"x-domainExt-cti.cti": "cti.a.p.event.v1.0", "x-shapeExt-definitions": {"foo": "string"}}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	res := StripCTIExtensions(schema)
+	require.Equal(t, JSONSchemaCTI{
+		"$ref": "#/definitions/Event",
+		"definitions": map[string]any{
+			"Event": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":    map[string]any{"type": "string"},
+					"topic": map[string]any{"type": "string"},
+					"data":  map[string]any{"type": "object", "default": map[string]any{"x-custom": float64(1)}},
+				},
+				"anyOf": []any{map[string]any{"type": "object"}},
+			},
+		},
+	}, res)
+
+	res = StripCTIExtensions(schema, "cti.id", "cti.reference")
+	properties := res["definitions"].(map[string]any)["Event"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "string", "x-custom": map[string]any{"x-domainExt-cti.id": true}}, properties["id"])
+	require.Equal(t, map[string]any{"type": "string", "x-custom": map[string]any{"x-domainExt-cti.reference": "cti.a.p.topic.v1.0"}}, properties["topic"])
+	require.NotContains(t, res["definitions"].(map[string]any)["Event"], "x-custom")
+
+	// The input schema is not modified.
+	require.Contains(t, schema["definitions"].(map[string]any)["Event"], "x-custom")
+}
+
+func Test_StripCTIExtensions_KeywordNames(t *testing.T) {
+	schema := JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"x-custom": map[string]any{"type": "string", "x-custom": map[string]any{"x-domainExt-cti.id": true}},
+			"enum":     map[string]any{"type": "string", "enum": []any{"a"}, "x-custom": map[string]any{"x-domainExt-cti.l10n": true}},
+		},
+		"patternProperties": map[string]any{
+			"^default": map[string]any{"type": "object", "x-custom": map[string]any{"x-domainExt-cti.final": true}},
+		},
+	}
+	require.Equal(t, JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"x-custom": map[string]any{"type": "string"},
+			"enum":     map[string]any{"type": "string", "enum": []any{"a"}},
+		},
+		"patternProperties": map[string]any{
+			"^default": map[string]any{"type": "object"},
+		},
+	}, StripCTIExtensions(schema))
+}
@@ -0,0 +1,123 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	definitionsPrefix = "#/definitions/"
+	rootPointer       = "#"
+)
+
+// Flatten returns a self-contained copy of the schema where local references to definitions
+// ("#/definitions/<name>") are replaced with the referenced schemas and the "definitions" keyword is removed.
+// This allows passing merged schemas to tools that do not support definitions.
+//
+// Recursive schemas cannot be inlined completely. A reference to the definition that is being inlined
+// is replaced with a reference to the location of its inlined copy, e.g. "#" for the root schema
+// or "#/properties/children/items" for a nested one. Siblings of $ref that are absent in the referenced
+// schema are kept. Values of data keywords (default, const, enum, examples) and vendor extensions (x-*)
+// are copied as is, while properties and definitions with such names are flattened. Other references are kept unchanged. The input schema is not modified.
+func Flatten(schema JSONSchemaCTI) (JSONSchemaCTI, error) {
+	f := &flattener{
+		stack: make(map[string]string),
+	}
+	f.definitions, _ = schema["definitions"].(map[string]any)
+
+	root := make(map[string]any, len(schema))
+	for key, val := range schema {
+		if key != "definitions" {
+			root[key] = val
+		}
+	}
+	res, err := f.flatten(root, rootPointer)
+	if err != nil {
+		return nil, err
+	}
+	return res.(map[string]any), nil
+}
+
+type flattener struct {
+	definitions map[string]any
+	// stack maps names of the definitions that are being inlined to the locations of their copies.
+	stack map[string]string
+}
+
+func (f *flattener) flatten(node any, ptr string) (any, error) {
+	switch v := node.(type) {
+	case map[string]any:
+		return f.flattenObject(v, ptr)
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			flat, err := f.flatten(item, ptr+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = flat
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+func (f *flattener) flattenObject(node map[string]any, ptr string) (any, error) {
+	ref, ok := node["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, definitionsPrefix) {
+		res, err := copySchemaKeywords(node, ptr, f.flatten)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	name := strings.TrimPrefix(ref, definitionsPrefix)
+	if target, ok := f.stack[name]; ok {
+		return map[string]any{"$ref": target}, nil
+	}
+	def, ok := f.definitions[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to find definition of %s at %s", ref, ptr)
+	}
+
+	f.stack[name] = ptr
+	flat, err := f.flattenObject(def, ptr)
+	delete(f.stack, name)
+	if err != nil {
+		return nil, fmt.Errorf("flatten %s: %w", ref, err)
+	}
+
+	res := flat.(map[string]any)
+	siblings := make(map[string]any, len(node))
+	for key, val := range node {
+		if _, ok := res[key]; !ok && key != "$ref" {
+			siblings[key] = val
+		}
+	}
+	flatSiblings, err := f.flattenObject(siblings, ptr)
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range flatSiblings.(map[string]any) {
+		res[key] = val
+	}
+	return res, nil
+}
+
+// isDataKeyword reports whether the keyword value is data rather than a schema. It applies to keywords
+// of schema objects only, not to names of properties or definitions (see isNamedSchemasKeyword).
+func isDataKeyword(key string) bool {
+	switch key {
+	case "default", "const", "enum", "examples":
+		return true
+	}
+	return strings.HasPrefix(key, "x-")
+}
+
+// escapePointer escapes the reference token of JSON pointer according to RFC 6901.
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,112 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Flatten(t *testing.T) {
+	schema, err := FromBytes([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"$ref": "#/definitions/Node",
+		"definitions": {
+			"Node": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string", "default": {"$ref": "#/definitions/Node"}},
+					"children": {"type": "array", "items": {"$ref": "#/definitions/Node"}},
+					"tag": {"$ref": "#/definitions/Tag", "description": "Node tag."},
+					"external": {"$ref": "http://example.com/schema.json"}
+				},
+				"x-custom": {"$ref": "#/definitions/Tag"}
+			},
+			"Tag": {
+				"type": "object",
+				"description": "Tag.",
+				"properties": {
+					"parent": {"$ref": "#/definitions/Tag"},
+					"a/b": {"type": "array", "items": {"$ref": "#/definitions/Tag"}}
+				}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	res, err := Flatten(schema)
+	require.NoError(t, err)
+	require.Equal(t, JSONSchemaCTI{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string", "default": map[string]any{"$ref": "#/definitions/Node"}},
+			"children": map[string]any{"type": "array", "items": map[string]any{"$ref": "#"}},
+			"tag": map[string]any{
+				"type":        "object",
+				"description": "Tag.",
+				"properties": map[string]any{
+					"parent": map[string]any{"$ref": "#/properties/tag"},
+					"a/b":    map[string]any{"type": "array", "items": map[string]any{"$ref": "#/properties/tag"}},
+				},
+			},
+			"external": map[string]any{"$ref": "http://example.com/schema.json"},
+		},
+		"x-custom": map[string]any{"$ref": "#/definitions/Tag"},
+	}, res)
+
+	// The input schema is not modified.
+	require.Contains(t, schema, "definitions")
+	require.Equal(t, "#/definitions/Node", schema["$ref"])
+
+	_, err = Flatten(JSONSchemaCTI{"$ref": "#/definitions/Missing"})
+	require.EqualError(t, err, "failed to find definition of #/definitions/Missing at #")
+}
+
+func Test_Flatten_PointerEscaping(t *testing.T) {
+	schema := JSONSchemaCTI{
+		"properties": map[string]any{
+			"a/b~c": map[string]any{"$ref": "#/definitions/List"},
+		},
+		"definitions": map[string]any{
+			"List": map[string]any{"anyOf": []any{map[string]any{"type": "null"}, map[string]any{"$ref": "#/definitions/List"}}},
+		},
+	}
+	res, err := Flatten(schema)
+	require.NoError(t, err)
+	require.Equal(t, JSONSchemaCTI{
+		"properties": map[string]any{
+			"a/b~c": map[string]any{"anyOf": []any{map[string]any{"type": "null"}, map[string]any{"$ref": "#/properties/a~1b~0c"}}},
+		},
+	}, res)
+}
+
+func Test_Flatten_KeywordNames(t *testing.T) {
+	schema := JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"default":  map[string]any{"$ref": "#/definitions/enum"},
+			"x-id":     map[string]any{"$ref": "#/definitions/enum"},
+			"examples": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/definitions/enum"}},
+		},
+		"patternProperties": map[string]any{
+			"^const": map[string]any{"$ref": "#/definitions/enum"},
+		},
+		"definitions": map[string]any{
+			"enum": map[string]any{"type": "string", "enum": []any{"a", "b"}},
+		},
+	}
+	res, err := Flatten(schema)
+	require.NoError(t, err)
+	enum := map[string]any{"type": "string", "enum": []any{"a", "b"}}
+	require.Equal(t, JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"default":  enum,
+			"x-id":     enum,
+			"examples": map[string]any{"type": "array", "items": enum},
+		},
+		"patternProperties": map[string]any{
+			"^const": enum,
+		},
+	}, res)
+}
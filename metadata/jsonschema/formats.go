@@ -0,0 +1,77 @@
+package jsonschema
+
+import (
+	"regexp"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-cti"
+)
+
+const (
+	// FormatCTI is the format of CTI identifiers, e.g. cti.a.p.event.v1.0.
+	FormatCTI = "cti"
+	// FormatDuration is the format of ISO 8601 durations, e.g. P1DT12H.
+	FormatDuration = "duration"
+)
+
+// rxDuration matches durations defined in Appendix A of RFC 3339.
+var rxDuration = regexp.MustCompile(`^P(?:(?:\d+Y(?:\d+M(?:\d+D)?)?|\d+M(?:\d+D)?|\d+D)(?:T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S))?|T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S)|\d+W)$`)
+
+func init() {
+	RegisterFormat(FormatCTI, FormatCheckerFunc(isCTI))
+	RegisterFormat(FormatDuration, FormatCheckerFunc(isDuration))
+}
+
+// FormatChecker reports whether the value conforms to the format.
+// Values of any JSON type are checked, so checkers of string formats should accept values of other types.
+type FormatChecker interface {
+	IsFormat(input any) bool
+}
+
+// FormatCheckerFunc is an adapter to allow the use of ordinary functions as FormatChecker.
+type FormatCheckerFunc func(input any) bool
+
+// IsFormat implements FormatChecker.
+func (f FormatCheckerFunc) IsFormat(input any) bool {
+	return f(input)
+}
+
+// RegisterFormat registers the checker of the format, so it is applied to values of the schemas with the "format"
+// keyword when they are validated (e.g. by the validator package or the REST server). The checker replaces
+// the checker of the format registered earlier, including the standard ones (e.g. "uuid" or "date-time").
+// Values of the formats without checkers are considered valid.
+//
+// The registry is global and safe for concurrent use. Formats are expected to be registered
+// on initialization of the consumer package before any validation.
+// The package registers FormatCTI and FormatDuration.
+func RegisterFormat(name string, checker FormatChecker) {
+	gojsonschema.FormatCheckers.Add(name, checker)
+}
+
+// UnregisterFormat removes the checker of the format, so values of the format are no longer checked.
+func UnregisterFormat(name string) {
+	gojsonschema.FormatCheckers.Remove(name)
+}
+
+// HasFormat reports whether the checker of the format is registered.
+func HasFormat(name string) bool {
+	return gojsonschema.FormatCheckers.Has(name)
+}
+
+func isCTI(input any) bool {
+	s, ok := input.(string)
+	if !ok {
+		return true
+	}
+	_, err := cti.ParseIdentifier(s)
+	return err == nil
+}
+
+func isDuration(input any) bool {
+	s, ok := input.(string)
+	if !ok {
+		return true
+	}
+	return rxDuration.MatchString(s)
+}
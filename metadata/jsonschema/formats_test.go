@@ -0,0 +1,61 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func validateFormat(t *testing.T, format string, value any) bool {
+	t.Helper()
+
+	schema := gojsonschema.NewGoLoader(map[string]any{"format": format})
+	res, err := gojsonschema.Validate(schema, gojsonschema.NewGoLoader(value))
+	require.NoError(t, err)
+	return res.Valid()
+}
+
+func Test_Formats(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		value  any
+		valid  bool
+	}{
+		{FormatCTI, "cti.a.p.event.v1.0", true},
+		{FormatCTI, "cti.a.p.event.v1.0~a.p.created.v1.0", true},
+		{FormatCTI, "cti.a.p.event", false},
+		{FormatCTI, "event", false},
+		{FormatCTI, 42, true},
+		{FormatDuration, "P1DT12H", true},
+		{FormatDuration, "PT30M", true},
+		{FormatDuration, "P2W", true},
+		{FormatDuration, "P", false},
+		{FormatDuration, "1h", false},
+		{"uuid", "f81d4fae-7dec-11d0-a765-00a0c91e6bf6", true},
+		{"uuid", "f81d4fae", false},
+		{"unknown", "anything", true},
+	} {
+		require.Equal(t, tc.valid, validateFormat(t, tc.format, tc.value), "%s %v", tc.format, tc.value)
+	}
+}
+
+func Test_RegisterFormat(t *testing.T) {
+	const format = "x-upper"
+	require.False(t, HasFormat(format))
+
+	RegisterFormat(format, FormatCheckerFunc(func(input any) bool {
+		s, ok := input.(string)
+		return !ok || s == strings.ToUpper(s)
+	}))
+	t.Cleanup(func() { UnregisterFormat(format) })
+
+	require.True(t, HasFormat(format))
+	require.True(t, validateFormat(t, format, "ABC"))
+	require.False(t, validateFormat(t, format, "abc"))
+
+	UnregisterFormat(format)
+	require.False(t, HasFormat(format))
+	require.True(t, validateFormat(t, format, "abc"))
+}
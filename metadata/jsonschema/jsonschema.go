@@ -0,0 +1,24 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONSchemaCTI is a JSON schema of CTI type converted from RAML.
+// CTI annotations are kept in the "x-custom" keyword of the annotated schemas.
+type JSONSchemaCTI map[string]any
+
+// FromBytes decodes the JSON schema.
+func FromBytes(data []byte) (JSONSchemaCTI, error) {
+	var s JSONSchemaCTI
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("unmarshal json schema: %w", err)
+	}
+	return s, nil
+}
+
+// ToBytes encodes the JSON schema.
+func (s JSONSchemaCTI) ToBytes() ([]byte, error) {
+	return json.Marshal(s)
+}
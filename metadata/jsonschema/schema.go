@@ -0,0 +1,96 @@
+package jsonschema
+
+import "strings"
+
+// CustomKeyword is the vendor extension keyword of schemas converted from RAML that holds CTI annotations
+// and other RAML extensions of the annotated schema.
+const CustomKeyword = "x-custom"
+
+// SchemaType returns the type of the schema. For a list of types, the first type other than "null" is returned,
+// or "null" if there is none. An empty string is returned if the schema does not declare its type.
+func SchemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+		return "null"
+	}
+	return ""
+}
+
+// RequiredSet returns names of the required properties of the object schema.
+func RequiredSet(schema map[string]any) map[string]struct{} {
+	required := make(map[string]struct{})
+	switch items := schema["required"].(type) {
+	case []string:
+		for _, item := range items {
+			required[item] = struct{}{}
+		}
+	case []any:
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				required[s] = struct{}{}
+			}
+		}
+	}
+	return required
+}
+
+// LookupRef returns the definition of the root schema referenced by the local $ref ("#/definitions/<name>").
+func LookupRef(root map[string]any, ref string) (map[string]any, bool) {
+	if !strings.HasPrefix(ref, definitionsPrefix) {
+		return nil, false
+	}
+	definitions, _ := root["definitions"].(map[string]any)
+	def, ok := definitions[strings.TrimPrefix(ref, definitionsPrefix)].(map[string]any)
+	return def, ok
+}
+
+// ResolveRef returns the definition of the root schema referenced by the local $ref of the schema
+// or the schema itself if it has no $ref or the definition is not found.
+func ResolveRef(root, schema map[string]any) map[string]any {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema
+	}
+	if def, ok := LookupRef(root, ref); ok {
+		return def
+	}
+	return schema
+}
+
+// DeepCopy returns a deep copy of the decoded JSON value. Objects and arrays are copied recursively,
+// other values are returned as is.
+func DeepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, item := range v {
+			res[k] = DeepCopy(item)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = DeepCopy(item)
+		}
+		return res
+	case []map[string]any:
+		res := make([]map[string]any, len(v))
+		for i, item := range v {
+			res[i] = DeepCopy(item).(map[string]any)
+		}
+		return res
+	case []string:
+		res := make([]string, len(v))
+		copy(res, v)
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,54 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SchemaType(t *testing.T) {
+	require.Equal(t, "string", SchemaType(map[string]any{"type": "string"}))
+	require.Equal(t, "integer", SchemaType(map[string]any{"type": []any{"null", "integer"}}))
+	require.Equal(t, "null", SchemaType(map[string]any{"type": []any{"null"}}))
+	require.Empty(t, SchemaType(map[string]any{}))
+}
+
+func Test_RequiredSet(t *testing.T) {
+	require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, RequiredSet(map[string]any{"required": []any{"a", "b", 1}}))
+	require.Equal(t, map[string]struct{}{"a": {}}, RequiredSet(map[string]any{"required": []string{"a"}}))
+	require.Empty(t, RequiredSet(map[string]any{}))
+}
+
+func Test_ResolveRef(t *testing.T) {
+	event := map[string]any{"type": "object"}
+	root := map[string]any{
+		"$ref":        "#/definitions/Event",
+		"definitions": map[string]any{"Event": event},
+	}
+	require.Equal(t, event, ResolveRef(root, root))
+
+	def, ok := LookupRef(root, "#/definitions/Event")
+	require.True(t, ok)
+	require.Equal(t, event, def)
+	_, ok = LookupRef(root, "#/definitions/Unknown")
+	require.False(t, ok)
+	_, ok = LookupRef(root, "cti://cti.a.p.event.v1.0")
+	require.False(t, ok)
+
+	unknown := map[string]any{"$ref": "#/definitions/Unknown"}
+	require.Equal(t, unknown, ResolveRef(root, unknown))
+}
+
+func Test_DeepCopy(t *testing.T) {
+	value := map[string]any{
+		"items":    []any{map[string]any{"a": 1.0}},
+		"required": []string{"a"},
+	}
+	res := DeepCopy(value).(map[string]any)
+	require.Equal(t, value, res)
+
+	res["items"].([]any)[0].(map[string]any)["a"] = 2.0
+	res["required"].([]string)[0] = "b"
+	require.Equal(t, 1.0, value["items"].([]any)[0].(map[string]any)["a"])
+	require.Equal(t, "a", value["required"].([]string)[0])
+}
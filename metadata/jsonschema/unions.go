@@ -0,0 +1,98 @@
+package jsonschema
+
+import (
+	"reflect"
+)
+
+// SimplifyUnions returns a copy of the schema where unions (anyOf) are normalized, so validators
+// compiled from merged schemas do not walk deeply nested unions:
+//   - members that are unions without other keywords are replaced with their members;
+//   - identical members are removed, the first one is kept;
+//   - a union of a single member is replaced with the member, if the member can be merged
+//     with the siblings of the union without conflicts.
+//
+// The resulting schema accepts the same values. Values of data keywords and vendor extensions (x-*)
+// are copied as is, while properties and definitions with such names are simplified. The input schema is not modified.
+func SimplifyUnions(schema JSONSchemaCTI) JSONSchemaCTI {
+	res, _ := simplifyUnions(map[string]any(schema)).(map[string]any)
+	return res
+}
+
+func simplifyUnions(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res, _ := copySchemaKeywords(v, "", func(item any, _ string) (any, error) {
+			return simplifyUnions(item), nil
+		})
+		if members, ok := res["anyOf"].([]any); ok {
+			return simplifyUnion(res, members)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = simplifyUnions(item)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// simplifyUnion normalizes the union of the schema. Members are already simplified.
+func simplifyUnion(schema map[string]any, members []any) map[string]any {
+	var flat []any
+	var appendMember func(member any)
+	appendMember = func(member any) {
+		if m, ok := member.(map[string]any); ok && len(m) == 1 {
+			// Empty unions reject any value, so they are kept as is.
+			if nested, ok := m["anyOf"].([]any); ok && len(nested) != 0 {
+				for _, item := range nested {
+					appendMember(item)
+				}
+				return
+			}
+		}
+		for _, existing := range flat {
+			if reflect.DeepEqual(existing, member) {
+				return
+			}
+		}
+		flat = append(flat, member)
+	}
+	for _, member := range members {
+		appendMember(member)
+	}
+
+	if len(flat) == 1 {
+		if member, ok := flat[0].(map[string]any); ok && canMergeMember(schema, member) {
+			delete(schema, "anyOf")
+			for key, val := range member {
+				schema[key] = val
+			}
+			return schema
+		}
+	}
+	schema["anyOf"] = flat
+	return schema
+}
+
+// canMergeMember reports whether the single member of the union may replace the union in the schema.
+// Siblings of $ref are ignored by draft-07 validators, so a member with $ref is merged only into a bare union.
+func canMergeMember(schema map[string]any, member map[string]any) bool {
+	if len(schema) == 1 {
+		return true
+	}
+	if _, ok := member["$ref"]; ok {
+		return false
+	}
+	if _, ok := schema["$ref"]; ok {
+		return false
+	}
+	for key := range member {
+		if _, ok := schema[key]; ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SimplifyUnions(t *testing.T) {
+	schema, err := FromBytes([]byte(`{
+		"type": "object",
+		"properties": {
+			"nested": {"anyOf": [
+				{"type": "string"},
+				{"anyOf": [{"type": "integer"}, {"anyOf": [{"type": "string"}, {"type": "null"}]}]}
+			]},
+			"single": {"anyOf": [{"anyOf": [{"type": "string"}, {"type": "string"}]}], "description": "Single."},
+			"conflict": {"anyOf": [{"type": "string"}], "type": "integer"},
+			"ref": {"anyOf": [{"$ref": "#/definitions/A"}]},
+			"refWithSiblings": {"anyOf": [{"$ref": "#/definitions/A"}], "description": "Ref."},
+			"annotated": {"anyOf": [{"anyOf": [{"type": "string"}, {"type": "null"}], "description": "Kept."}]},
+			"data": {"type": "object", "default": {"anyOf": [{"anyOf": []}]}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	require.Equal(t, JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"nested": map[string]any{"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "integer"},
+				map[string]any{"type": "null"},
+			}},
+			"single":          map[string]any{"type": "string", "description": "Single."},
+			"conflict":        map[string]any{"anyOf": []any{map[string]any{"type": "string"}}, "type": "integer"},
+			"ref":             map[string]any{"$ref": "#/definitions/A"},
+			"refWithSiblings": map[string]any{"anyOf": []any{map[string]any{"$ref": "#/definitions/A"}}, "description": "Ref."},
+			"annotated": map[string]any{
+				"anyOf":       []any{map[string]any{"type": "string"}, map[string]any{"type": "null"}},
+				"description": "Kept.",
+			},
+			"data": map[string]any{"type": "object", "default": map[string]any{"anyOf": []any{map[string]any{"anyOf": []any{}}}}},
+		},
+	}, SimplifyUnions(schema))
+
+	// The input schema is not modified.
+	nested := schema["properties"].(map[string]any)["nested"].(map[string]any)["anyOf"].([]any)
+	require.Len(t, nested, 2)
+}
+
+func Test_SimplifyUnions_KeywordNames(t *testing.T) {
+	schema := JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"enum":   map[string]any{"anyOf": []any{map[string]any{"anyOf": []any{map[string]any{"type": "string"}}}}},
+			"x-tags": map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "string"}}},
+		},
+		"definitions": map[string]any{
+			"default": map[string]any{"anyOf": []any{map[string]any{"type": "integer"}}},
+		},
+	}
+	require.Equal(t, JSONSchemaCTI{
+		"type": "object",
+		"properties": map[string]any{
+			"enum":   map[string]any{"type": "string"},
+			"x-tags": map[string]any{"type": "string"},
+		},
+		"definitions": map[string]any{
+			"default": map[string]any{"type": "integer"},
+		},
+	}, SimplifyUnions(schema))
+}
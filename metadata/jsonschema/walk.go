@@ -0,0 +1,46 @@
+package jsonschema
+
+// isNamedSchemasKeyword reports whether the keyword value maps names (of properties, definitions, etc.)
+// to schemas. Keys of such maps are names rather than keywords, so a property named "default" or "x-id"
+// is a schema like any other.
+func isNamedSchemasKeyword(key string) bool {
+	switch key {
+	case "properties", "patternProperties", "definitions", "$defs", "dependencies":
+		return true
+	}
+	return false
+}
+
+// copySchemaKeywords returns a copy of the schema object where values of data keywords and vendor extensions
+// are copied as is and other values are replaced with the results of fn. For keywords with named schemas,
+// fn is called for every named schema. ptr is JSON pointer of the schema; fn receives pointers of the values.
+// Values that are not schemas (e.g. "type" or "required") are passed to fn as well, so fn must return
+// scalars and arrays of scalars unchanged.
+func copySchemaKeywords(schema map[string]any, ptr string, fn func(v any, ptr string) (any, error)) (map[string]any, error) {
+	res := make(map[string]any, len(schema))
+	for key, val := range schema {
+		keyPtr := ptr + "/" + escapePointer(key)
+		if isDataKeyword(key) {
+			res[key] = DeepCopy(val)
+			continue
+		}
+		if named, ok := val.(map[string]any); ok && isNamedSchemasKeyword(key) {
+			schemas := make(map[string]any, len(named))
+			for name, item := range named {
+				sub, err := fn(item, keyPtr+"/"+escapePointer(name))
+				if err != nil {
+					return nil, err
+				}
+				schemas[name] = sub
+			}
+			res[key] = schemas
+			continue
+		}
+		sub, err := fn(val, keyPtr)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = sub
+	}
+	return res, nil
+}
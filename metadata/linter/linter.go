@@ -0,0 +1,70 @@
+// Package linter checks style of CTI entities, e.g. presence of descriptions and naming of properties.
+// Unlike validation, lint issues do not make entities invalid. Some of them have fixes
+// that mechanically correct the RAML source files of a package.
+package linter
+
+import (
+	"context"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+const (
+	DescriptionRuleName       = "description"
+	DisplayNameCasingRuleName = "display-name-casing"
+	PropertyNamingRuleName    = "property-naming"
+	MissingExamplesRuleName   = "missing-examples"
+)
+
+// DefaultRules returns the style rules of CTI packages. The baseDir is a package directory
+// that is used to locate the fixes in the source files.
+func DefaultRules(baseDir string) []validator.Rule {
+	return []validator.Rule{
+		NewDescriptionRule(),
+		NewDisplayNameCasingRule(baseDir),
+		NewPropertyNamingRule(),
+		NewMissingExamplesRule(),
+	}
+}
+
+// Lint runs the rules for the entities and returns the issues sorted by CTI in the order of rules.
+// The registry is used by the rules to look up related entities, e.g. parents of instances.
+// Issues have warning severity unless the rule sets another one.
+func Lint(ctx context.Context, r *collector.MetadataRegistry, entities []*metadata.Entity, rules ...validator.Rule) []validator.Issue {
+	sorted := make([]*metadata.Entity, len(entities))
+	copy(sorted, entities)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cti < sorted[j].Cti
+	})
+
+	var issues []validator.Issue
+	for _, entity := range sorted {
+		for _, rule := range rules {
+			for _, issue := range rule.Validate(ctx, r, entity) {
+				if issue.Rule == "" {
+					issue.Rule = rule.Name()
+				}
+				if issue.Cti == "" {
+					issue.Cti = entity.Cti
+				}
+				if issue.Severity == "" {
+					issue.Severity = validator.SeverityWarning
+				}
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+// Fixes returns the fixes of the issues.
+func Fixes(issues []validator.Issue) []validator.Fix {
+	var fixes []validator.Fix
+	for _, issue := range issues {
+		fixes = append(fixes, issue.Fixes...)
+	}
+	return fixes
+}
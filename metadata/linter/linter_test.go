@@ -0,0 +1,119 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+func Test_Lint(t *testing.T) {
+	baseDir := t.TempDir()
+	content := `#%RAML 1.0 Library
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    displayName: event # Base event.
+    properties:
+      topic:
+        (cti.display_name): true
+        type: string
+      createdAt: string
+      payload:
+        properties:
+          user-id: string
+    example:
+      topic: login
+      createdAt: "2024-01-01"
+      payload: { user-id: "1" }
+  Documented:
+    (cti.cti): cti.x.y.documented.v1.0
+    displayName: Documented
+    description: A well documented type.
+    properties:
+      user_id: string
+    examples:
+      first:
+        user_id: "1"
+  Topics:
+    (cti.cti): cti.x.y.event.v1.0
+    type: Event[]
+(Topics):
+- topic: "login"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "entities.raml"), []byte(content), 0600))
+
+	yes := true
+	sourceMap := metadata.SourceMap{OriginalPath: "entities.raml"}
+	r := collector.NewMetadataRegistry()
+	entities := []*metadata.Entity{
+		{
+			Cti:         "cti.x.y.event.v1.0",
+			DisplayName: "event",
+			Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {
+				"topic": {"type": "string"}, "createdAt": {"type": "string"},
+				"payload": {"type": "object", "properties": {"user-id": {"type": "string"}}}},
+				"examples": [{"topic": "login"}]}}}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".topic": {DisplayName: &yes}},
+			SourceMap:   sourceMap,
+		},
+		{
+			Cti:         "cti.x.y.documented.v1.0",
+			DisplayName: "Documented",
+			Description: "A well documented type.",
+			Schema: []byte(`{"$ref": "#/definitions/Documented", "definitions": {"Documented": {"type": "object",
+				"properties": {"user_id": {"type": "string"}}, "examples": [{"user_id": "1"}]}}}`),
+			SourceMap: sourceMap,
+		},
+		{Cti: "cti.x.y.event.v1.0~x.y.login.v1.0", DisplayName: "login", Values: []byte(`{"topic": "login"}`), SourceMap: sourceMap},
+		{Cti: "cti.x.y.plain.v1.0", DisplayName: "plain", Schema: []byte(`{"type": "object"}`)},
+	}
+	for _, e := range entities {
+		require.NoError(t, r.Add(e.SourceMap.OriginalPath, e))
+	}
+
+	issues := Lint(context.Background(), r, entities, DefaultRules(baseDir)...)
+	var messages []string
+	for _, issue := range issues {
+		require.Equal(t, validator.SeverityWarning, issue.Severity)
+		messages = append(messages, issue.Rule+": "+issue.Error())
+	}
+	require.Equal(t, []string{
+		`description: cti.x.y.event.v1.0: type has no description`,
+		`display-name-casing: cti.x.y.event.v1.0: display name "event" does not start with an uppercase letter, use "Event"`,
+		`property-naming: cti.x.y.event.v1.0: property .createdAt is not in snake_case, use created_at`,
+		`property-naming: cti.x.y.event.v1.0: property .payload.user-id is not in snake_case, use user_id`,
+		`display-name-casing: cti.x.y.event.v1.0~x.y.login.v1.0: display name "login" does not start with an uppercase letter, use "Login"`,
+		`description: cti.x.y.plain.v1.0: type has no description`,
+		`display-name-casing: cti.x.y.plain.v1.0: display name "plain" does not start with an uppercase letter, use "Plain"`,
+		`missing-examples: cti.x.y.plain.v1.0: type has no examples`,
+	}, messages)
+
+	fixes := Fixes(issues)
+	// Display name of the type without source file is not fixed, while "topic: login" of the example is.
+	require.Len(t, fixes, 3)
+	require.NoError(t, validator.ApplyFixes(baseDir, fixes))
+	data, err := os.ReadFile(filepath.Join(baseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "    displayName: Event # Base event.\n")
+	require.Contains(t, string(data), "- topic: \"Login\"\n")
+}
+
+func Test_toSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"createdAt":  "created_at",
+		"CreatedAt":  "created_at",
+		"userID":     "user_id",
+		"HTTPServer": "http_server",
+		"user-id":    "user_id",
+		"already_ok": "already_ok",
+	} {
+		require.Equal(t, want, toSnakeCase(name), name)
+	}
+}
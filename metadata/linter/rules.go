@@ -0,0 +1,220 @@
+package linter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+var snakeCaseRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// NewDescriptionRule makes a rule that reports types without description.
+func NewDescriptionRule() validator.Rule {
+	return validator.NewRuleFunc(DescriptionRuleName,
+		func(_ context.Context, _ *collector.MetadataRegistry, entity *metadata.Entity) []validator.Issue {
+			if entity.Values != nil || strings.TrimSpace(entity.Description) != "" {
+				return nil
+			}
+			return []validator.Issue{{Message: "type has no description"}}
+		})
+}
+
+// NewDisplayNameCasingRule makes a rule that reports display names of entities that do not start with
+// an uppercase letter and suggests a fix with the capitalized display name.
+// The baseDir is a package directory that is used to locate the display names in the source files.
+func NewDisplayNameCasingRule(baseDir string) validator.Rule {
+	return validator.NewRuleFunc(DisplayNameCasingRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []validator.Issue {
+			return checkDisplayNameCasing(baseDir, r, entity)
+		})
+}
+
+func checkDisplayNameCasing(baseDir string, r *collector.MetadataRegistry, entity *metadata.Entity) []validator.Issue {
+	first, size := utf8.DecodeRuneInString(entity.DisplayName)
+	if size == 0 || !unicode.IsLower(first) {
+		return nil
+	}
+	fixed := string(unicode.ToUpper(first)) + entity.DisplayName[size:]
+	issue := validator.Issue{
+		Message: fmt.Sprintf("display name %q does not start with an uppercase letter, use %q", entity.DisplayName, fixed),
+	}
+	// Display names of types are set with the displayName facet, while display names of instances
+	// are held by the property annotated with cti.display_name. Defaults, e.g. names of types, are not fixed.
+	key := "displayName"
+	if entity.Values != nil {
+		key = displayNameProperty(r, entity)
+	}
+	if key != "" && entity.SourceMap.OriginalPath != "" {
+		// Fixes are best-effort, the issue is reported even if source file is not available.
+		issue.Fixes, _ = findKeyValueFixes(baseDir, entity.SourceMap.OriginalPath, key, entity.DisplayName, fixed)
+	}
+	return []validator.Issue{issue}
+}
+
+// displayNameProperty returns the name of the property of the parent type that holds the display name of the instance.
+func displayNameProperty(r *collector.MetadataRegistry, entity *metadata.Entity) string {
+	parent, ok := r.Index[metadata.GetParentCti(entity.Cti)]
+	if !ok {
+		return ""
+	}
+	for key, annotation := range parent.Annotations {
+		if annotation.DisplayName != nil && *annotation.DisplayName {
+			path := key.String()
+			return path[strings.LastIndexByte(path, '.')+1:]
+		}
+	}
+	return ""
+}
+
+// findKeyValueFixes returns fixes that replace the scalar value of every "key: value" line in the file.
+// The value may be quoted and followed by a comment.
+func findKeyValueFixes(baseDir string, file string, key string, value string, replacement string) ([]validator.Fix, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, file))
+	if err != nil {
+		return nil, fmt.Errorf("read file %s: %w", file, err)
+	}
+	re := regexp.MustCompile(`^\s*(?:-\s+)?` + regexp.QuoteMeta(key) + `:\s+(["']?)(` + regexp.QuoteMeta(value) + `)(["']?)\s*(?:#.*)?$`)
+
+	var fixes []validator.Fix
+	for i, line := range strings.Split(string(data), "\n") {
+		m := re.FindStringSubmatchIndex(line)
+		// Opening and closing quotes must match.
+		if m == nil || line[m[2]:m[3]] != line[m[6]:m[7]] {
+			continue
+		}
+		fixes = append(fixes, validator.Fix{
+			File: file,
+			Range: validator.Range{
+				Start: validator.Position{Line: i, Character: m[4]},
+				End:   validator.Position{Line: i, Character: m[5]},
+			},
+			Replacement: replacement,
+		})
+	}
+	return fixes, nil
+}
+
+// NewPropertyNamingRule makes a rule that reports properties of types that are not named in snake_case.
+// Renaming of properties changes the contract of the type, so the issues have no fixes.
+func NewPropertyNamingRule() validator.Rule {
+	return validator.NewRuleFunc(PropertyNamingRuleName,
+		func(_ context.Context, _ *collector.MetadataRegistry, entity *metadata.Entity) []validator.Issue {
+			if entity.Values != nil || entity.Schema == nil {
+				return nil
+			}
+			var schema map[string]any
+			if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+				return nil
+			}
+			definitions, _ := schema["definitions"].(map[string]any)
+			var issues []validator.Issue
+			walkProperties(rootDefinition(schema), definitions, "", map[string]struct{}{}, func(path, name string) {
+				if !snakeCaseRegexp.MatchString(name) {
+					issues = append(issues, validator.Issue{
+						Message: fmt.Sprintf("property %s.%s is not in snake_case, use %s", path, name, toSnakeCase(name)),
+					})
+				}
+			})
+			return issues
+		})
+}
+
+// walkProperties calls the function for properties of the schema and nested schemas in the order of names.
+func walkProperties(
+	node map[string]any, definitions map[string]any, path string, visited map[string]struct{}, fn func(path, name string),
+) {
+	if ref, ok := node["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := definitions[name].(map[string]any)
+		if !ok {
+			return
+		}
+		if _, ok := visited[name]; ok {
+			return
+		}
+		visited[name] = struct{}{}
+		defer delete(visited, name)
+		node = def
+	}
+
+	properties, _ := node["properties"].(map[string]any)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn(path, name)
+		if prop, ok := properties[name].(map[string]any); ok {
+			walkProperties(prop, definitions, path+"."+name, visited, fn)
+		}
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		walkProperties(items, definitions, path+".#", visited, fn)
+	}
+	if anyOf, ok := node["anyOf"].([]any); ok {
+		for _, member := range anyOf {
+			if m, ok := member.(map[string]any); ok {
+				walkProperties(m, definitions, path, visited, fn)
+			}
+		}
+	}
+}
+
+// toSnakeCase converts camelCase, PascalCase and kebab-case names to snake_case.
+func toSnakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ' || r == '.':
+			sb.WriteByte('_')
+		case unicode.IsUpper(r):
+			// Acronyms are kept together, e.g. userID is converted to user_id.
+			if i > 0 && runes[i-1] != '_' && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// NewMissingExamplesRule makes a rule that reports types without examples.
+func NewMissingExamplesRule() validator.Rule {
+	return validator.NewRuleFunc(MissingExamplesRuleName,
+		func(_ context.Context, _ *collector.MetadataRegistry, entity *metadata.Entity) []validator.Issue {
+			if entity.Values != nil || entity.Schema == nil {
+				return nil
+			}
+			var schema map[string]any
+			if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+				return nil
+			}
+			if examples, ok := rootDefinition(schema)["examples"].([]any); ok && len(examples) != 0 {
+				return nil
+			}
+			return []validator.Issue{{Message: "type has no examples"}}
+		})
+}
+
+func rootDefinition(schema map[string]any) map[string]any {
+	if def, err := merger.ExtractSchemaDefinition(schema); err == nil {
+		return def
+	}
+	return schema
+}
@@ -0,0 +1,580 @@
+// Package lsp provides a Language Server Protocol server for CTI RAML packages.
+// The server reports validation issues of the package as diagnostics, resolves CTI references
+// to the definitions of the entities, shows merged schemas on hover and completes CTI identifiers.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+	"github.com/acronis/go-cti/metadata/merger"
+	"github.com/acronis/go-cti/metadata/ramlx"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+const diagnosticSource = "cti"
+
+// Server is a language server of a CTI package. Positions are counted in bytes,
+// which matches UTF-16 code units of the LSP specification for ASCII sources.
+type Server struct {
+	baseDir string
+	conn    *conn
+
+	// documents are texts of the documents opened by the client, keyed by URI.
+	documents map[string]string
+	// ramlCache keeps parsed RAML of the dependencies, which rarely change between reloads.
+	ramlCache *ctipackage.RamlCache
+	// fragments keep decoded YAML of the fragments, so unchanged files are not decoded again on reloads.
+	fragments *ramlx.FragmentCache
+	pkg       *ctipackage.Package
+	schemas   *merger.SchemaCache
+	// published are URIs of the documents with published diagnostics, so they are cleared once the issues are fixed.
+	published map[string]struct{}
+}
+
+// NewServer makes a language server of the package in the directory.
+// The package is parsed and validated when the client is initialized and every time a document is saved.
+func NewServer(baseDir string) *Server {
+	return &Server{
+		baseDir:   baseDir,
+		documents: make(map[string]string),
+		ramlCache: ctipackage.NewRamlCache(),
+		fragments: ramlx.NewFragmentCache(),
+		published: make(map[string]struct{}),
+	}
+}
+
+// Serve reads requests and notifications of the client from r and writes responses and notifications to w
+// until the exit notification is received or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var respErr *responseError
+			if errors.As(err, &respErr) {
+				slog.Warn("Failed to parse message", slog.Any("error", err))
+				continue
+			}
+			return fmt.Errorf("read message: %w", err)
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) error {
+	if msg.ID == nil {
+		return s.handleNotification(msg)
+	}
+	result, err := s.handleRequest(msg)
+	return s.conn.reply(msg.ID, result, err)
+}
+
+func (s *Server) handleRequest(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"change":    1, // Full
+					"save":      map[string]any{"includeText": false},
+				},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+				"completionProvider": map[string]any{"triggerCharacters": []string{".", "~"}},
+			},
+			"serverInfo": map[string]any{"name": "cti"},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params)
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params)
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params)
+	default:
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+func (s *Server) handleNotification(msg *message) error {
+	switch msg.Method {
+	case "initialized", "textDocument/didSave":
+		return s.reload()
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			slog.Warn("Invalid notification", slog.String("method", msg.Method), slog.Any("error", err))
+			return nil
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			slog.Warn("Invalid notification", slog.String("method", msg.Method), slog.Any("error", err))
+			return nil
+		}
+		// Documents are synchronized in full, so the last change holds the whole text.
+		if n := len(params.ContentChanges); n != 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			slog.Warn("Invalid notification", slog.String("method", msg.Method), slog.Any("error", err))
+			return nil
+		}
+		delete(s.documents, params.TextDocument.URI)
+	}
+	return nil
+}
+
+func unmarshalParams(msg *message, params any) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid params of %s: %v", msg.Method, err)}
+	}
+	return nil
+}
+
+// reload parses and validates the package and publishes diagnostics.
+// If the package cannot be parsed, the previously parsed registry is still used for navigation.
+func (s *Server) reload() error {
+	pkg, err := ctipackage.New(s.baseDir, ctipackage.WithRamlCache(s.ramlCache), ctipackage.WithFragmentCache(s.fragments))
+	if err == nil {
+		err = pkg.Read()
+	}
+	if err == nil {
+		err = pkg.Validate()
+	}
+	if pkg != nil && pkg.GlobalRegistry != nil {
+		s.pkg = pkg
+		s.schemas = merger.NewSchemaCache(pkg.GlobalRegistry)
+	}
+
+	diagnostics := make(map[string][]Diagnostic)
+	if err != nil {
+		s.collectDiagnostics(err, diagnostics)
+	}
+	for uri := range s.published {
+		if _, ok := diagnostics[uri]; !ok {
+			diagnostics[uri] = []Diagnostic{}
+		}
+	}
+	uris := make([]string, 0, len(diagnostics))
+	for uri := range diagnostics {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	s.published = make(map[string]struct{})
+	for _, uri := range uris {
+		if len(diagnostics[uri]) != 0 {
+			s.published[uri] = struct{}{}
+		}
+		params := PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics[uri]}
+		if err := s.conn.notify("textDocument/publishDiagnostics", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceContext is a context of the error collected while walking the stack trace down to the error itself.
+type traceContext struct {
+	messages []string
+	cti      string
+	location string
+	position *stacktrace.Position
+}
+
+func (s *Server) collectDiagnostics(err error, res map[string][]Diagnostic) {
+	var importErr *ctipackage.ImportError
+	if errors.As(err, &importErr) {
+		for _, issue := range importErr.Issues {
+			ctx := traceContext{messages: []string{issue.Error()}, location: issue.File}
+			if issue.Line > 0 {
+				ctx.position = &stacktrace.Position{Line: issue.Line}
+			}
+			s.addDiagnostic(ctx, res)
+		}
+		return
+	}
+	var aggErr *validator.AggregateError
+	if errors.As(err, &aggErr) {
+		s.walkTrace(aggErr.StackTrace(), traceContext{}, res)
+		return
+	}
+	st, ok := stacktrace.Unwrap(err)
+	if !ok {
+		s.addDiagnostic(traceContext{messages: []string{err.Error()}}, res)
+		return
+	}
+	s.walkTrace(st, traceContext{}, res)
+}
+
+func (s *Server) walkTrace(st *stacktrace.StackTrace, ctx traceContext, res map[string][]Diagnostic) {
+	if st.Message != "" {
+		ctx.messages = append(ctx.messages[:len(ctx.messages):len(ctx.messages)], st.Message)
+	}
+	if st.Location != nil {
+		ctx.location, ctx.position = string(*st.Location), st.Position
+	}
+	if st.Info.Has("cti") {
+		ctx.cti = st.Info.StringBy("cti")
+	}
+	switch {
+	case st.Wrapped != nil:
+		s.walkTrace(st.Wrapped, ctx, res)
+	case len(st.List) == 0:
+		s.addDiagnostic(ctx, res)
+	}
+	// Items of the list are independent errors, so the context of the parent is not relevant for them.
+	for _, item := range st.List {
+		s.walkTrace(item, traceContext{}, res)
+	}
+}
+
+func (s *Server) addDiagnostic(ctx traceContext, res map[string][]Diagnostic) {
+	var (
+		path string
+		rng  Range
+	)
+	switch {
+	case ctx.location != "":
+		path = ctx.location
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.baseDir, path)
+		}
+		if ctx.position != nil && ctx.position.Line > 0 {
+			pos := Position{Line: ctx.position.Line - 1}
+			if ctx.position.Column > 0 {
+				pos.Character = ctx.position.Column - 1
+			}
+			rng = Range{Start: pos, End: pos}
+		} else if loc, ok := s.entityLocation(ctx.cti); ok && loc.path == path {
+			rng = loc.rng
+		}
+	case ctx.cti != "":
+		if loc, ok := s.entityLocation(ctx.cti); ok {
+			path, rng = loc.path, loc.rng
+		}
+	}
+	if path == "" {
+		path = filepath.Join(s.baseDir, ctipackage.IndexFileName)
+	}
+	uri := pathToURI(path)
+	res[uri] = append(res[uri], Diagnostic{
+		Range:    rng,
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  strings.Join(ctx.messages, ": "),
+	})
+}
+
+func (s *Server) hover(params TextDocumentPositionParams) (*Hover, error) {
+	entity, rng, err := s.entityAt(params)
+	if err != nil || entity == nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if entity.DisplayName != "" {
+		fmt.Fprintf(&b, "**%s**\n\n", entity.DisplayName)
+	}
+	fmt.Fprintf(&b, "`%s`\n", entity.Cti)
+	if entity.Deprecated {
+		b.WriteString("\n*Deprecated.*")
+		if entity.DeprecationMessage != "" {
+			b.WriteString(" " + entity.DeprecationMessage)
+		}
+		b.WriteString("\n")
+	}
+	if entity.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", entity.Description)
+	}
+
+	var data []byte
+	if entity.Values != nil {
+		if data, err = indentJSON(entity.Values); err != nil {
+			return nil, fmt.Errorf("indent values of %s: %w", entity.Cti, err)
+		}
+	} else {
+		schema, err := s.schemas.GetMergedCtiSchema(entity.Cti)
+		if err != nil {
+			return nil, fmt.Errorf("get merged schema of %s: %w", entity.Cti, err)
+		}
+		if data, err = json.MarshalIndent(jsonschema.StripCTIExtensions(schema), "", "  "); err != nil {
+			return nil, fmt.Errorf("marshal merged schema of %s: %w", entity.Cti, err)
+		}
+	}
+	fmt.Fprintf(&b, "\n```json\n%s\n```\n", data)
+
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: b.String()}, Range: &rng}, nil
+}
+
+func indentJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func (s *Server) definition(params TextDocumentPositionParams) ([]Location, error) {
+	entity, _, err := s.entityAt(params)
+	if err != nil || entity == nil {
+		return nil, err
+	}
+	loc, ok := s.entityLocation(entity.Cti)
+	if !ok {
+		return nil, nil
+	}
+	return []Location{{URI: pathToURI(loc.path), Range: loc.rng}}, nil
+}
+
+func (s *Server) completion(params TextDocumentPositionParams) (*CompletionList, error) {
+	res := &CompletionList{Items: []CompletionItem{}}
+	r := s.registry()
+	if r == nil {
+		return res, nil
+	}
+	text, err := s.text(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	line, ok := lineAt(text, params.Position.Line)
+	if !ok || params.Position.Character > len(line) {
+		return res, nil
+	}
+	start := params.Position.Character
+	for start > 0 && isCtiChar(line[start-1]) {
+		start--
+	}
+	prefix := line[start:params.Position.Character]
+	if !strings.HasPrefix(prefix, "cti.") && !strings.HasPrefix("cti.", prefix) {
+		return res, nil
+	}
+
+	ids := make([]string, 0, len(r.Index))
+	for id := range r.Index {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	rng := Range{
+		Start: Position{Line: params.Position.Line, Character: start},
+		End:   params.Position,
+	}
+	for _, id := range ids {
+		entity := r.Index[id]
+		kind := CompletionItemKindClass
+		if entity.Values != nil {
+			kind = CompletionItemKindConstant
+		}
+		res.Items = append(res.Items, CompletionItem{
+			Label:    id,
+			Kind:     kind,
+			Detail:   entity.DisplayName,
+			TextEdit: &TextEdit{Range: rng, NewText: id},
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) registry() *collector.MetadataRegistry {
+	if s.pkg == nil {
+		return nil
+	}
+	return s.pkg.GlobalRegistry
+}
+
+// entityAt returns the entity referenced by the CTI at the position of the document.
+// References with partial versions are resolved to the latest satisfying versions.
+func (s *Server) entityAt(params TextDocumentPositionParams) (*metadata.Entity, Range, error) {
+	r := s.registry()
+	if r == nil {
+		return nil, Range{}, nil
+	}
+	text, err := s.text(params.TextDocument.URI)
+	if err != nil {
+		return nil, Range{}, err
+	}
+	word, rng, ok := wordAt(text, params.Position)
+	if !ok || !strings.HasPrefix(word, "cti.") {
+		return nil, Range{}, nil
+	}
+	if entity, ok := r.Index[word]; ok {
+		return entity, rng, nil
+	}
+	entity, err := r.LatestVersion(word)
+	if err != nil {
+		return nil, Range{}, nil
+	}
+	return entity, rng, nil
+}
+
+type sourceLocation struct {
+	path string
+	rng  Range
+}
+
+// entityLocation returns the location of the entity definition: the root shape of the type or the values
+// of the instance according to the source maps of the entity. The start of the file is used if the location is unknown.
+func (s *Server) entityLocation(id string) (sourceLocation, bool) {
+	r := s.registry()
+	if r == nil {
+		return sourceLocation{}, false
+	}
+	entity, ok := r.Index[id]
+	if !ok {
+		return sourceLocation{}, false
+	}
+	path, ok := s.entityFile(entity)
+	if !ok {
+		return sourceLocation{}, false
+	}
+	loc, ok := entity.SchemaSourceMap["."]
+	if entity.Values != nil {
+		ok = entity.ValuesSourceLocation != nil
+		if ok {
+			loc = *entity.ValuesSourceLocation
+		}
+	}
+	if !ok || loc.Line == 0 {
+		return sourceLocation{path: path}, true
+	}
+	start := Position{Line: loc.Line - 1}
+	if loc.Column > 0 {
+		start.Character = loc.Column - 1
+	}
+	end := start
+	if loc.EndLine > 0 {
+		end = Position{Line: loc.EndLine - 1, Character: loc.EndColumn - 1}
+	}
+	return sourceLocation{path: path, rng: Range{Start: start, End: end}}, true
+}
+
+// entityFile returns the absolute path to the RAML file of the entity of the package or of one of its dependencies.
+// Paths of the source map are relative to the package that declares the entity, i.e. the package whose ID
+// is the prefix of the last named segment of the CTI. The most specific package ID wins.
+func (s *Server) entityFile(entity *metadata.Entity) (string, bool) {
+	if entity.SourceMap.OriginalPath == "" {
+		return "", false
+	}
+	if _, ok := s.pkg.LocalRegistry.Index[entity.Cti]; ok {
+		return filepath.Join(s.pkg.BaseDir, entity.SourceMap.OriginalPath), true
+	}
+	var depID string
+	for _, dep := range s.pkg.IndexLock.SourceInfo {
+		if len(dep.PackageID) > len(depID) && metadata.IsDeclaredBy(entity.Cti, dep.PackageID) {
+			depID = dep.PackageID
+		}
+	}
+	if depID == "" {
+		return "", false
+	}
+	return filepath.Join(s.pkg.BaseDir, ctipackage.DependencyDirName, depID, entity.SourceMap.OriginalPath), true
+}
+
+// text returns the text of the document opened by the client or the content of the file.
+func (s *Server) text(uri string) (string, error) {
+	if text, ok := s.documents[uri]; ok {
+		return text, nil
+	}
+	path, err := uriToPath(uri)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+func lineAt(text string, line int) (string, bool) {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[line], "\r"), true
+}
+
+// wordAt returns the CTI expression at the position and its range.
+func wordAt(text string, pos Position) (string, Range, bool) {
+	line, ok := lineAt(text, pos.Line)
+	if !ok || pos.Character > len(line) {
+		return "", Range{}, false
+	}
+	start, end := pos.Character, pos.Character
+	for start > 0 && isCtiChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isCtiChar(line[end]) {
+		end++
+	}
+	if start == end {
+		return "", Range{}, false
+	}
+	return line[start:end], Range{
+		Start: Position{Line: pos.Line, Character: start},
+		End:   Position{Line: pos.Line, Character: end},
+	}, true
+}
+
+func isCtiChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '_' || c == '.' || c == '~' || c == '-' || c == '*'
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parse uri %s: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported uri scheme %s", u.Scheme)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
@@ -0,0 +1,207 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/ctitest"
+)
+
+func testPackage(values map[string]any) *ctitest.Package {
+	return &ctitest.Package{
+		ID: "a.p",
+		Types: []ctitest.Type{
+			{
+				Cti:         "cti.a.p.topic.v1.0",
+				Description: "A topic of messages.",
+				Annotations: map[string]any{"cti.final": false},
+				Properties: []ctitest.Property{
+					{Name: "id", Type: "cti.CTI", Annotations: map[string]any{"cti.id": true}},
+					{Name: "name"},
+				},
+			},
+		},
+		Instances: []ctitest.Instance{
+			{Type: "cti.a.p.topic.v1.0", Values: values},
+		},
+	}
+}
+
+// session runs the server with the requests and returns the messages written by the server.
+func session(t *testing.T, dir string, requests ...map[string]any) []message {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, req := range requests {
+		req["jsonrpc"] = "2.0"
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	var out bytes.Buffer
+	require.NoError(t, NewServer(dir).Serve(&in, &out))
+
+	var res []message
+	c := newConn(&out, nil)
+	for {
+		msg, err := c.read()
+		if errors.Is(err, io.EOF) {
+			return res
+		}
+		require.NoError(t, err)
+		res = append(res, *msg)
+	}
+}
+
+func positionOf(t *testing.T, path string, text string) Position {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for i, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, text); idx != -1 {
+			return Position{Line: i, Character: idx}
+		}
+	}
+	require.Failf(t, "text not found", "%s in %s", text, path)
+	return Position{}
+}
+
+func positionParams(path string, pos Position) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": pathToURI(path)}, "position": pos}
+}
+
+func resultOf(t *testing.T, msgs []message, id int, res any) {
+	t.Helper()
+
+	for _, msg := range msgs {
+		if msg.ID != nil && string(*msg.ID) == fmt.Sprint(id) {
+			require.Nil(t, msg.Error)
+			require.NotNil(t, msg.Result)
+			require.NoError(t, json.Unmarshal(*msg.Result, res))
+			return
+		}
+	}
+	require.Failf(t, "response not found", "id %d", id)
+}
+
+func Test_Server(t *testing.T) {
+	dir := testPackage(map[string]any{"id": "cti.a.p.topic.v1.0~a.p.users.v1.0", "name": "Users"}).TempDir(t)
+	path := filepath.Join(dir, ctitest.EntitiesFileName)
+	ref := positionOf(t, path, "cti.a.p.topic.v1.0~a.p.users.v1.0")
+	ref.Character += 4 // inside of the type CTI
+
+	msgs := session(t, dir,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"id": 2, "method": "textDocument/hover", "params": positionParams(path, ref)},
+		map[string]any{"id": 3, "method": "textDocument/definition", "params": positionParams(path, ref)},
+		map[string]any{"id": 4, "method": "textDocument/completion", "params": positionParams(path, Position{Line: ref.Line, Character: ref.Character + 10})},
+		map[string]any{"id": 5, "method": "unknown"},
+		map[string]any{"id": 6, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+
+	var initResult struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	resultOf(t, msgs, 1, &initResult)
+	require.Equal(t, true, initResult.Capabilities["hoverProvider"])
+	require.Equal(t, true, initResult.Capabilities["definitionProvider"])
+
+	for _, msg := range msgs {
+		require.NotEqual(t, "textDocument/publishDiagnostics", msg.Method, "valid package has no diagnostics")
+	}
+
+	var hover Hover
+	resultOf(t, msgs, 2, &hover)
+	require.Equal(t, "markdown", hover.Contents.Kind)
+	require.Contains(t, hover.Contents.Value, "`cti.a.p.topic.v1.0~a.p.users.v1.0`")
+	require.Contains(t, hover.Contents.Value, `"name": "Users"`)
+
+	var locations []Location
+	resultOf(t, msgs, 3, &locations)
+	require.Len(t, locations, 1)
+	require.Equal(t, pathToURI(path), locations[0].URI)
+	// The range of the instance is taken from its source map rather than from the first occurrence of the CTI.
+	require.Greater(t, locations[0].Range.End.Line, locations[0].Range.Start.Line)
+
+	var completion CompletionList
+	resultOf(t, msgs, 4, &completion)
+	labels := make([]string, 0, len(completion.Items))
+	for _, item := range completion.Items {
+		labels = append(labels, item.Label)
+	}
+	require.Equal(t, []string{"cti.a.p.topic.v1.0", "cti.a.p.topic.v1.0~a.p.users.v1.0"}, labels)
+	require.Equal(t, CompletionItemKindClass, completion.Items[0].Kind)
+	require.Equal(t, CompletionItemKindConstant, completion.Items[1].Kind)
+
+	for _, msg := range msgs {
+		if msg.ID != nil && string(*msg.ID) == "5" {
+			require.NotNil(t, msg.Error)
+			require.Equal(t, codeMethodNotFound, msg.Error.Code)
+		}
+	}
+}
+
+func Test_ServerDiagnostics(t *testing.T) {
+	dir := testPackage(map[string]any{"id": "cti.a.p.topic.v1.0~a.p.users.v1.0"}).TempDir(t)
+
+	msgs := session(t, dir,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "exit"},
+	)
+
+	var published []PublishDiagnosticsParams
+	for _, msg := range msgs {
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var params PublishDiagnosticsParams
+			require.NoError(t, json.Unmarshal(msg.Params, &params))
+			published = append(published, params)
+		}
+	}
+	require.Len(t, published, 1)
+	require.Equal(t, pathToURI(filepath.Join(dir, ctitest.EntitiesFileName)), published[0].URI)
+	require.NotEmpty(t, published[0].Diagnostics)
+	require.Equal(t, SeverityError, published[0].Diagnostics[0].Severity)
+	require.Contains(t, published[0].Diagnostics[0].Message, "name")
+}
+
+func Test_ServerEntityFile(t *testing.T) {
+	s := &Server{pkg: &ctipackage.Package{
+		BaseDir:       "/pkg",
+		LocalRegistry: collector.NewMetadataRegistry(),
+		IndexLock: &ctipackage.IndexLock{SourceInfo: map[string]ctipackage.Info{
+			"b.q":     {PackageID: "b.q"},
+			"b.q.ext": {PackageID: "b.q.ext"},
+		}},
+	}}
+	entity := func(id string) *metadata.Entity {
+		return &metadata.Entity{Cti: id, SourceMap: metadata.SourceMap{OriginalPath: "entities.raml"}}
+	}
+
+	path, ok := s.entityFile(entity("cti.b.q.event.v1.0"))
+	require.True(t, ok)
+	require.Equal(t, filepath.Join("/pkg", ctipackage.DependencyDirName, "b.q", "entities.raml"), path)
+
+	// Instances are located in the package that declares them rather than in the package of their type.
+	path, ok = s.entityFile(entity("cti.b.q.event.v1.0~b.q.ext.created.v1.0"))
+	require.True(t, ok)
+	require.Equal(t, filepath.Join("/pkg", ctipackage.DependencyDirName, "b.q.ext", "entities.raml"), path)
+
+	_, ok = s.entityFile(entity("cti.c.r.event.v1.0"))
+	require.False(t, ok)
+}
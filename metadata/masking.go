@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// MaskedValue replaces values of properties annotated with cti.sensitive.
+const MaskedValue = "******"
+
+// MaskSensitive returns the values where values of properties annotated with cti.sensitive are replaced
+// with MaskedValue, so logs and exports do not leak secrets. The annotations are annotations of the type
+// of the instance including its ancestors. Values that are absent or null are kept, and the input values
+// are returned as is if there is nothing to mask. Masking fails rather than leaks values if a path
+// of the annotations cannot be resolved, e.g. if a pattern of a pattern property is not a valid regular expression.
+func MaskSensitive(values []byte, annotations map[GJsonPath]Annotations) ([]byte, error) {
+	sensitive := make(map[GJsonPath]Annotations)
+	for key, annotation := range annotations {
+		if annotation.Sensitive == nil || !*annotation.Sensitive {
+			continue
+		}
+		for _, segment := range key.Segments() {
+			if isPatternSegment(segment) {
+				if _, err := regexp.Compile(segment[1 : len(segment)-1]); err != nil {
+					return nil, fmt.Errorf("resolve sensitive path %s: %w", key, err)
+				}
+			}
+		}
+		sensitive[key] = annotation
+	}
+	resolved := ResolveAnnotatedValues(values, sensitive)
+	if len(resolved) == 0 {
+		return values, nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(values, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal values: %w", err)
+	}
+	var masked []ValuePath
+	for _, v := range resolved {
+		if v.Value.Type == gjson.Null || isNestedInto(v.Path, masked) {
+			continue
+		}
+		var err error
+		if doc, err = maskValue(doc, v.Path); err != nil {
+			return nil, fmt.Errorf("mask %s: %w", v.Path, err)
+		}
+		masked = append(masked, v.Path)
+	}
+	res, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal values: %w", err)
+	}
+	return res, nil
+}
+
+// MaskSensitive returns a copy of the instance with values masked according to the annotations
+// of its type (see MaskSensitive).
+func (e *Entity) MaskSensitive(annotations map[GJsonPath]Annotations) (*Entity, error) {
+	if e.Values == nil {
+		return nil, fmt.Errorf("%s is not an instance", e.Cti)
+	}
+	values, err := MaskSensitive(e.Values, annotations)
+	if err != nil {
+		return nil, fmt.Errorf("mask values of %s: %w", e.Cti, err)
+	}
+	res := *e
+	res.Values = values
+	return &res, nil
+}
+
+// isNestedInto reports whether the path is equal to or nested into any of the paths,
+// e.g. a property of an object that is already masked as a whole.
+func isNestedInto(path ValuePath, paths []ValuePath) bool {
+	for _, p := range paths {
+		if len(p) <= len(path) && compareValuePaths(p, path[:len(p)]) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// maskValue replaces the value at the concrete path (see AnnotatedValue.Path) with MaskedValue.
+func maskValue(doc any, path ValuePath) (any, error) {
+	if len(path) == 0 {
+		return MaskedValue, nil
+	}
+	node := doc
+	for i, segment := range path {
+		last := i == len(path)-1
+		switch n := node.(type) {
+		case map[string]any:
+			if _, ok := n[segment]; !ok {
+				return nil, fmt.Errorf("property %s not found", segment)
+			}
+			if last {
+				n[segment] = MaskedValue
+				return doc, nil
+			}
+			node = n[segment]
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(n) {
+				return nil, fmt.Errorf("item %s not found", segment)
+			}
+			if last {
+				n[idx] = MaskedValue
+				return doc, nil
+			}
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("value at %s is not an object or array", segment)
+		}
+	}
+	return doc, nil
+}
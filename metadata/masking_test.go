@@ -0,0 +1,91 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MaskSensitive(t *testing.T) {
+	yes, no := true, false
+	annotations := map[GJsonPath]Annotations{
+		".id":                 {ID: &yes},
+		".password":           {Sensitive: &yes},
+		".token":              {Sensitive: &yes},
+		".login":              {Sensitive: &no},
+		".keys.#":             {Sensitive: &yes},
+		".accounts.#.secret":  {Sensitive: &yes},
+		".profile":            {Sensitive: &yes},
+		".profile.birth_date": {Sensitive: &yes},
+	}
+
+	masked, err := MaskSensitive([]byte(`{
+		"id": "cti.a.p.credential.v1.0~a.p.backup.v1.0",
+		"login": "admin",
+		"password": "secret",
+		"token": null,
+		"keys": ["k1", "k2"],
+		"accounts": [{"name": "a", "secret": 1}, {"name": "b"}],
+		"profile": {"birth_date": "2000-01-01"}
+	}`), annotations)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"id": "cti.a.p.credential.v1.0~a.p.backup.v1.0",
+		"login": "admin",
+		"password": "******",
+		"token": null,
+		"keys": ["******", "******"],
+		"accounts": [{"name": "a", "secret": "******"}, {"name": "b"}],
+		"profile": "******"
+	}`, string(masked))
+
+	values := []byte(`{"login": "admin"}`)
+	masked, err = MaskSensitive(values, annotations)
+	require.NoError(t, err)
+	require.Equal(t, values, masked)
+
+	masked, err = MaskSensitive([]byte(`"secret"`), map[GJsonPath]Annotations{".": {Sensitive: &yes}})
+	require.NoError(t, err)
+	require.Equal(t, `"******"`, string(masked))
+}
+
+func Test_MaskSensitivePatterns(t *testing.T) {
+	yes := true
+	masked, err := MaskSensitive([]byte(`{"secrets": {"db": "hunter2"}}`),
+		map[GJsonPath]Annotations{".secrets./.*/": {Sensitive: &yes}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"secrets": {"db": "******"}}`, string(masked))
+
+	masked, err = MaskSensitive([]byte(`{
+		"labels": {"x.token": {"value": "t1"}, "x.owner": {"value": null}, "xtoken": {"value": "t2"}},
+		"api.key": "k",
+		"api": {"key": "visible"}
+	}`), map[GJsonPath]Annotations{
+		`.labels./^x\..+$/.value`: {Sensitive: &yes},
+		"./^api\\.key$/":          {Sensitive: &yes},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"labels": {"x.token": {"value": "******"}, "x.owner": {"value": null}, "xtoken": {"value": "t2"}},
+		"api.key": "******",
+		"api": {"key": "visible"}
+	}`, string(masked))
+
+	// Values are never returned unmasked if the sensitive path cannot be resolved.
+	_, err = MaskSensitive([]byte(`{"secrets": {"db": "hunter2"}}`),
+		map[GJsonPath]Annotations{".secrets./[/": {Sensitive: &yes}})
+	require.ErrorContains(t, err, "resolve sensitive path .secrets./[/")
+}
+
+func Test_EntityMaskSensitive(t *testing.T) {
+	yes := true
+	e := &Entity{Cti: "cti.a.p.credential.v1.0~a.p.backup.v1.0", Values: []byte(`{"password": "secret"}`)}
+	masked, err := e.MaskSensitive(map[GJsonPath]Annotations{".password": {Sensitive: &yes}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"password": "******"}`, string(masked.Values))
+	require.Equal(t, e.Cti, masked.Cti)
+	require.JSONEq(t, `{"password": "secret"}`, string(e.Values))
+
+	_, err = (&Entity{Cti: "cti.a.p.credential.v1.0"}).MaskSensitive(nil)
+	require.EqualError(t, err, "cti.a.p.credential.v1.0 is not an instance")
+}
@@ -0,0 +1,135 @@
+package merger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// SchemaCache memoizes merged schemas of CTI types of the registry.
+// A merged schema of a type is computed by merging its own schema onto the cached merged schema of its parent,
+// so the parent chain is merged only once for all its children.
+// The cache is invalidated automatically when an entity is added to the registry and reset when the registry is compacted
+// or restored from a snapshot.
+// Entities that are modified in place must be invalidated explicitly with Invalidate
+// or with NotifyChange of the registry.
+type SchemaCache struct {
+	registry *collector.MetadataRegistry
+
+	mu      sync.Mutex
+	schemas map[string]map[string]any
+}
+
+// schemaCacheKey is the key of the merged schema cache of the registry, see collector.MetadataRegistry.Derived.
+type schemaCacheKey struct{}
+
+// NewSchemaCache returns the merged schema cache of the registry. The cache is made and subscribed to changes
+// and compaction of the registry on the first call and is shared by all later callers, so validators, servers
+// and other users of the same registry neither register hooks of their own nor merge the same schemas again.
+func NewSchemaCache(r *collector.MetadataRegistry) *SchemaCache {
+	return r.Derived(schemaCacheKey{}, func() any {
+		c := &SchemaCache{
+			registry: r,
+			schemas:  make(map[string]map[string]any),
+		}
+		r.AddChangeHook(c.Invalidate)
+		r.AddCompactHook(c.Reset)
+		return c
+	}).(*SchemaCache)
+}
+
+// GetMergedCtiSchema returns the merged schema of the CTI type.
+// The returned schema is a copy and may be modified by the caller.
+func (c *SchemaCache) GetMergedCtiSchema(cti string) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema, err := c.getMergedCtiSchema(cti)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.DeepCopy(schema).(map[string]any), nil
+}
+
+// ApplyDefaults returns a copy of the instance values of the CTI type completed with the defaults
+// of its merged schema. See jsonschema.JSONSchemaCTI.ApplyDefaults for details.
+func (c *SchemaCache) ApplyDefaults(cti string, values map[string]any) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema, err := c.getMergedCtiSchema(cti)
+	if err != nil {
+		return nil, err
+	}
+	// Defaults are copied, so the cached schema is never shared with the result.
+	return jsonschema.JSONSchemaCTI(schema).ApplyDefaults(values), nil
+}
+
+func (c *SchemaCache) getMergedCtiSchema(cti string) (map[string]any, error) {
+	if schema, ok := c.schemas[cti]; ok {
+		return schema, nil
+	}
+
+	entity, ok := c.registry.Index[cti]
+	if !ok {
+		return nil, fmt.Errorf("failed to find cti %s", cti)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+		return nil, err
+	}
+	schema, err := ExtractSchemaDefinition(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	parentCti := metadata.GetParentCti(cti)
+	if parentCti != cti {
+		if _, ok := c.registry.Index[parentCti]; !ok {
+			return nil, fmt.Errorf("failed to find cti parent %s", parentCti)
+		}
+		parentSchema, err := c.getMergedCtiSchema(parentCti)
+		if err != nil {
+			return nil, err
+		}
+		// The cached parent schema is not modified by the merge and shares unchanged nodes with the merged one.
+		schema, err = MergeSchemas(schema, parentSchema)
+		if err != nil {
+			var conflictErr *MergeConflictError
+			if errors.As(err, &conflictErr) {
+				conflictErr.Cti, conflictErr.ParentCti = cti, parentCti
+			}
+			return nil, err
+		}
+	}
+
+	c.schemas[cti] = schema
+	return schema, nil
+}
+
+// Invalidate drops the cached merged schemas of the CTI type and all its descendants.
+func (c *SchemaCache) Invalidate(cti string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := cti + "~"
+	for id := range c.schemas {
+		if id == cti || strings.HasPrefix(id, prefix) {
+			delete(c.schemas, id)
+		}
+	}
+}
+
+// Reset drops all cached merged schemas.
+func (c *SchemaCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.schemas = make(map[string]map[string]any)
+}
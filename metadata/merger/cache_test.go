@@ -0,0 +1,107 @@
+package merger
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_SchemaCache(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	add := func(id string, schema string) {
+		require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: id, Schema: []byte(schema)}))
+	}
+	add("cti.x.y.event.v1.0",
+		`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}}}`)
+	add("cti.x.y.event.v1.0~x.y.created.v1.0",
+		`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {"name": {"type": "string", "maxLength": 8}}, "required": ["name"]}}}`)
+	add("cti.x.y.event.v1.0~x.y.created.v1.0~x.y.user.v1.0",
+		`{"$ref": "#/definitions/User", "definitions": {"User": {"type": "object", "properties": {"name": {"type": "string", "maxLength": 4}}}}}`)
+	add("cti.x.y.event.v1.0~x.y.deleted.v1.0",
+		`{"$ref": "#/definitions/Deleted", "definitions": {"Deleted": {"type": "object", "properties": {"reason": {"type": "string"}}}}}`)
+
+	normalize := func(schema map[string]any) map[string]any {
+		if required, ok := schema["required"].([]string); ok {
+			sort.Strings(required)
+		}
+		return schema
+	}
+
+	c := NewSchemaCache(r)
+	// The cache is shared by all users of the registry.
+	require.Same(t, c, NewSchemaCache(r))
+	require.NotSame(t, c, NewSchemaCache(collector.NewMetadataRegistry()))
+	for _, id := range []string{
+		"cti.x.y.event.v1.0~x.y.created.v1.0~x.y.user.v1.0",
+		"cti.x.y.event.v1.0~x.y.deleted.v1.0",
+		"cti.x.y.event.v1.0~x.y.created.v1.0",
+	} {
+		expected, err := GetMergedCtiSchema(id, r)
+		require.NoError(t, err)
+		actual, err := c.GetMergedCtiSchema(id)
+		require.NoError(t, err)
+		require.Equal(t, normalize(expected), normalize(actual), id)
+	}
+	require.Len(t, c.schemas, 4)
+
+	// Returned schemas are copies.
+	schema, err := c.GetMergedCtiSchema("cti.x.y.event.v1.0")
+	require.NoError(t, err)
+	schema["properties"].(map[string]any)["id"].(map[string]any)["type"] = "integer"
+	schema, err = c.GetMergedCtiSchema("cti.x.y.event.v1.0")
+	require.NoError(t, err)
+	require.Equal(t, "string", schema["properties"].(map[string]any)["id"].(map[string]any)["type"])
+
+	// Modification of the parent invalidates its descendants.
+	r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"].Schema = []byte(
+		`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {"title": {"type": "string"}}}}}`)
+	r.NotifyChange("cti.x.y.event.v1.0~x.y.created.v1.0")
+	require.Len(t, c.schemas, 2)
+	schema, err = c.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.created.v1.0~x.y.user.v1.0")
+	require.NoError(t, err)
+	require.Contains(t, schema["properties"], "title")
+
+	c.Reset()
+	require.Empty(t, c.schemas)
+
+	// Compaction of the registry drops merged schemas that are recomputed on demand.
+	_, err = c.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.deleted.v1.0")
+	require.NoError(t, err)
+	r.Compact()
+	require.Empty(t, c.schemas)
+	_, err = c.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.deleted.v1.0")
+	require.NoError(t, err)
+
+	// Restoring a snapshot drops merged schemas of entities that no longer exist.
+	snapshot := r.Snapshot()
+	add("cti.x.y.event.v1.0~x.y.updated.v1.0",
+		`{"$ref": "#/definitions/Updated", "definitions": {"Updated": {"type": "object"}}}`)
+	_, err = c.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.updated.v1.0")
+	require.NoError(t, err)
+	r.Restore(snapshot)
+	_, err = c.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.updated.v1.0")
+	require.ErrorContains(t, err, "failed to find cti")
+
+	_, err = c.GetMergedCtiSchema("cti.x.y.unknown.v1.0")
+	require.ErrorContains(t, err, "failed to find cti cti.x.y.unknown.v1.0")
+}
+
+func Test_SchemaCacheApplyDefaults(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: "cti.x.y.event.v1.0", Schema: []byte(
+		`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {"id": {"type": "string"}, "source": {"type": "string", "default": "agent"}}}}}`)}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Schema: []byte(
+		`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {"name": {"type": "string", "default": "new"}}}}}`)}))
+
+	c := NewSchemaCache(r)
+	values, err := c.ApplyDefaults("cti.x.y.event.v1.0~x.y.created.v1.0", map[string]any{"id": "1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": "1", "source": "agent", "name": "new"}, values)
+
+	_, err = c.ApplyDefaults("cti.x.y.unknown.v1.0", nil)
+	require.EqualError(t, err, "failed to find cti cti.x.y.unknown.v1.0")
+}
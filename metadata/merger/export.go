@@ -0,0 +1,235 @@
+package merger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+const (
+	// RefURIScheme is the scheme of URIs of CTI types that are referenced by exported schemas.
+	RefURIScheme = "cti://"
+
+	ctiSchemaKey   = "x-domainExt-" + metadata.Schema
+	descriptionKey = "description"
+)
+
+// RefResolver resolves URIs of CTI types referenced by exported schemas (see ExportCtiSchema) to their schemas,
+// so consumers may load the schemas of external types lazily, e.g. from registries of other packages.
+type RefResolver interface {
+	ResolveRef(uri string) (map[string]any, error)
+}
+
+// RefResolverFunc is an adapter to allow the use of ordinary functions as RefResolver.
+type RefResolverFunc func(uri string) (map[string]any, error)
+
+// ResolveRef implements RefResolver.
+func (f RefResolverFunc) ResolveRef(uri string) (map[string]any, error) {
+	return f(uri)
+}
+
+// NewRegistryRefResolver makes a RefResolver that resolves URIs to merged schemas of the types of the registry.
+func NewRegistryRefResolver(r *collector.MetadataRegistry) RefResolver {
+	schemas := NewSchemaCache(r)
+	return RefResolverFunc(func(uri string) (map[string]any, error) {
+		id, err := ParseRefURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := r.Types[id]; !ok {
+			return nil, fmt.Errorf("cti type not found: %s", id)
+		}
+		return schemas.GetMergedCtiSchema(id)
+	})
+}
+
+// RefURI returns a stable URI of the CTI type that is used in $refs of exported schemas.
+// Each node of the CTI is converted into a path of vendor, package, entity name and version,
+// e.g. cti.a.p.event.v1.0~b.q.created.v1.2 is converted into cti://a/p/event/v1.0~b/q/created/v1.2.
+func RefURI(id string) (string, error) {
+	expr, err := cti.ParseIdentifier(id)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", id, err)
+	}
+	var b strings.Builder
+	b.WriteString(RefURIScheme)
+	for n := expr.Head; n != nil; n = n.Child {
+		if n != expr.Head {
+			b.WriteByte('~')
+		}
+		b.WriteString(string(n.Vendor) + "/" + string(n.Package) + "/" + string(n.EntityName) + "/v" + n.Version.String())
+	}
+	return b.String(), nil
+}
+
+// ParseRefURI returns the CTI of the type referenced by the URI (see RefURI).
+func ParseRefURI(uri string) (string, error) {
+	path, ok := strings.CutPrefix(uri, RefURIScheme)
+	if !ok {
+		return "", fmt.Errorf("invalid cti type URI %s: expect %s scheme", uri, RefURIScheme)
+	}
+	nodes := strings.Split(path, "~")
+	for i, node := range nodes {
+		parts := strings.Split(node, "/")
+		if len(parts) != 4 {
+			return "", fmt.Errorf("invalid cti type URI %s: expect vendor, package, entity name and version", uri)
+		}
+		nodes[i] = strings.Join(parts, ".")
+	}
+	id := "cti." + strings.Join(nodes, "~")
+	if _, err := cti.ParseIdentifier(id); err != nil {
+		return "", fmt.Errorf("invalid cti type URI %s: %w", uri, err)
+	}
+	return id, nil
+}
+
+type exportConfig struct {
+	strip bool
+	keep  []string
+}
+
+// ExportOption configures ExportCtiSchema.
+type ExportOption func(*exportConfig)
+
+// WithoutExtensions makes ExportCtiSchema remove vendor extensions (x-custom) from the exported schema,
+// so it can be passed to tools that reject unknown keywords. Annotations listed in keep (e.g. "cti.id")
+// are preserved. See jsonschema.StripCTIExtensions.
+func WithoutExtensions(keep ...string) ExportOption {
+	return func(c *exportConfig) {
+		c.strip = true
+		c.keep = keep
+	}
+}
+
+// ExportCtiSchema returns the merged schema of the CTI type where schemas of types of other packages
+// that are inlined by cti.schema annotations are replaced with $refs to their URIs (see RefURI).
+// The type belongs to the package of the last node of its CTI, e.g. b.q for cti.a.p.event.v1.0~b.q.created.v1.0.
+// Descriptions and annotations of the replaced schemas are kept, several types of the annotation
+// are referenced with anyOf. The $refs may be resolved with RefResolver. Vendor extensions are kept
+// unless WithoutExtensions is passed.
+func ExportCtiSchema(id string, r *collector.MetadataRegistry, opts ...ExportOption) (map[string]any, error) {
+	var cfg exportConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	schema, err := GetMergedCtiSchema(id, r)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := packageOf(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := exportRefs(schema, pkg); err != nil {
+		return nil, fmt.Errorf("export %s: %w", id, err)
+	}
+	if cfg.strip {
+		return jsonschema.StripCTIExtensions(schema, cfg.keep...), nil
+	}
+	return schema, nil
+}
+
+func exportRefs(node map[string]any, pkg string) error {
+	if ids := annotationSchemaCtis(node); len(ids) != 0 {
+		external := false
+		for _, id := range ids {
+			refPkg, err := packageOf(id)
+			if err != nil {
+				return err
+			}
+			external = external || refPkg != pkg
+		}
+		if external {
+			return replaceWithRefs(node, ids)
+		}
+	}
+
+	if properties, ok := node[propertiesKey].(map[string]any); ok {
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if child, ok := properties[name].(map[string]any); ok {
+				if err := exportRefs(child, pkg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if items, ok := node[itemsKey].(map[string]any); ok {
+		if err := exportRefs(items, pkg); err != nil {
+			return err
+		}
+	}
+	if members, ok := node[anyOfKey].([]any); ok {
+		for _, member := range members {
+			if child, ok := member.(map[string]any); ok {
+				if err := exportRefs(child, pkg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func replaceWithRefs(node map[string]any, ids []string) error {
+	refs := make([]any, 0, len(ids))
+	for _, id := range ids {
+		uri, err := RefURI(id)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, map[string]any{refKey: uri})
+	}
+	for key := range node {
+		if key != jsonschema.CustomKeyword && key != descriptionKey {
+			delete(node, key)
+		}
+	}
+	if len(refs) == 1 {
+		node[refKey] = refs[0].(map[string]any)[refKey]
+	} else {
+		node[anyOfKey] = refs
+	}
+	return nil
+}
+
+// annotationSchemaCtis returns CTIs of the cti.schema annotation of the schema node.
+func annotationSchemaCtis(node map[string]any) []string {
+	custom, ok := node[jsonschema.CustomKeyword].(map[string]any)
+	if !ok {
+		return nil
+	}
+	switch val := custom[ctiSchemaKey].(type) {
+	case string:
+		return []string{val}
+	case []any:
+		ids := make([]string, 0, len(val))
+		for _, item := range val {
+			if id, ok := item.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// packageOf returns the package (vendor.package) of the last node of the CTI.
+func packageOf(id string) (string, error) {
+	expr, err := cti.ParseIdentifier(id)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", id, err)
+	}
+	tail := expr.Tail()
+	return string(tail.Vendor) + "." + string(tail.Package), nil
+}
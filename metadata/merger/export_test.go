@@ -0,0 +1,89 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_RefURI(t *testing.T) {
+	uri, err := RefURI("cti.a.p.event.v1.0~b.q.created.v1.2")
+	require.NoError(t, err)
+	require.Equal(t, "cti://a/p/event/v1.0~b/q/created/v1.2", uri)
+
+	id, err := ParseRefURI(uri)
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.event.v1.0~b.q.created.v1.2", id)
+
+	for _, uri := range []string{"http://a/p/event/v1.0", "cti://a/p/event", "cti://a/p/event/1.0"} {
+		_, err := ParseRefURI(uri)
+		require.Error(t, err, uri)
+	}
+}
+
+func Test_ExportCtiSchema(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.b.q.address.v1.0", Schema: []byte(`{"$ref": "#/definitions/Address", "definitions": {"Address": {"type": "object", "properties": {"city": {"type": "string"}}}}}`)},
+		{Cti: "cti.b.q.phone.v1.0", Schema: []byte(`{"$ref": "#/definitions/Phone", "definitions": {"Phone": {"type": "object", "properties": {"number": {"type": "string"}}}}}`)},
+		{Cti: "cti.a.p.tag.v1.0", Schema: []byte(`{"$ref": "#/definitions/Tag", "definitions": {"Tag": {"type": "object", "properties": {"name": {"type": "string"}}}}}`)},
+		{
+			Cti: "cti.a.p.user.v1.0",
+			Schema: []byte(`{"$ref": "#/definitions/User", "definitions": {"User": {"type": "object", "properties": {
+				"address": {
+					"type": "object", "description": "Home address",
+					"properties": {"city": {"type": "string"}},
+					"x-custom": {"x-domainExt-cti.schema": "cti.b.q.address.v1.0"}
+				},
+				"contacts": {"type": "array", "items": {
+					"anyOf": [
+						{"type": "object", "properties": {"city": {"type": "string"}}},
+						{"type": "object", "properties": {"number": {"type": "string"}}}
+					],
+					"x-custom": {"x-domainExt-cti.schema": ["cti.b.q.address.v1.0", "cti.b.q.phone.v1.0"]}
+				}},
+				"tag": {
+					"type": "object", "properties": {"name": {"type": "string"}},
+					"x-custom": {"x-domainExt-cti.schema": "cti.a.p.tag.v1.0"}
+				}
+			}}}}`),
+		},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	schema, err := ExportCtiSchema("cti.a.p.user.v1.0", r)
+	require.NoError(t, err)
+	properties := schema["properties"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"$ref":        "cti://b/q/address/v1.0",
+		"description": "Home address",
+		"x-custom":    map[string]any{"x-domainExt-cti.schema": "cti.b.q.address.v1.0"},
+	}, properties["address"])
+	require.Equal(t, []any{
+		map[string]any{"$ref": "cti://b/q/address/v1.0"},
+		map[string]any{"$ref": "cti://b/q/phone/v1.0"},
+	}, properties["contacts"].(map[string]any)["items"].(map[string]any)["anyOf"])
+	// Types of the same package stay inlined.
+	require.Contains(t, properties["tag"], "properties")
+
+	schema, err = ExportCtiSchema("cti.a.p.user.v1.0", r, WithoutExtensions())
+	require.NoError(t, err)
+	properties = schema["properties"].(map[string]any)
+	require.Equal(t, map[string]any{"$ref": "cti://b/q/address/v1.0", "description": "Home address"}, properties["address"])
+	require.NotContains(t, properties["tag"], "x-custom")
+
+	schema, err = ExportCtiSchema("cti.a.p.user.v1.0", r, WithoutExtensions(metadata.Schema))
+	require.NoError(t, err)
+	require.Contains(t, schema["properties"].(map[string]any)["address"], "x-custom")
+
+	resolver := NewRegistryRefResolver(r)
+	address, err := resolver.ResolveRef("cti://b/q/address/v1.0")
+	require.NoError(t, err)
+	require.Contains(t, address["properties"], "city")
+	_, err = resolver.ResolveRef("cti://b/q/unknown/v1.0")
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/acronis/go-cti/metadata"
@@ -11,18 +12,25 @@ import (
 )
 
 const (
-	anyOfKey       = "anyOf"
-	definitionsKey = "definitions"
-	itemsKey       = "items"
-	propertiesKey  = "properties"
-	refKey         = "$ref"
-	requiredKey    = "required"
-	typeKey        = "type"
+	anyOfKey             = "anyOf"
+	definitionsKey       = "definitions"
+	itemsKey             = "items"
+	patternPropertiesKey = "patternProperties"
+	propertiesKey        = "properties"
+	refKey               = "$ref"
+	requiredKey          = "required"
+	typeKey              = "type"
 )
 
-type merger func(source, target map[string]any) (map[string]any, error)
+type merger func(source, target map[string]any, path string) (map[string]any, error)
 
-var errInvalidSchemaError = errors.New("invalid schema")
+var (
+	// ErrInvalidSchema is returned when the schema does not have the structure expected by the merger,
+	// e.g. the root reference does not point to a definition.
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrSchemaMergeConflict is matched by *MergeConflictError with errors.Is.
+	ErrSchemaMergeConflict = errors.New("schema merge conflict")
+)
 
 var propertiesToMerge = [...]string{
 	"title", "description", "default", "pattern", "format", "enum", "additionalProperties",
@@ -30,9 +38,68 @@ var propertiesToMerge = [...]string{
 	"uniqueItems", "minProperties", "maxProperties",
 }
 
+// documentationKeys are keywords that document the schema and do not constrain values.
+var documentationKeys = [...]string{"title", "description"}
+
+// MergeConflictError is returned when a source schema cannot be merged onto a target one.
+// It holds the JSON pointer of the conflicting node in the merged schema and both conflicting sub-schemas.
+type MergeConflictError struct {
+	// Path is a JSON pointer of the conflicting node, e.g. /properties/payload/properties/size.
+	Path string
+	// Source is the conflicting sub-schema of the child.
+	Source map[string]any
+	// Target is the conflicting sub-schema of the parent.
+	Target map[string]any
+	// Cti is the CTI type whose merged schema is built. Set by GetMergedCtiSchema.
+	Cti string
+	// ParentCti is the ancestor whose schema conflicts with the child. Set by GetMergedCtiSchema.
+	ParentCti string
+	Err       error
+}
+
+func (e *MergeConflictError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("failed to merge schemas")
+	if e.Cti != "" && e.ParentCti != "" {
+		fmt.Fprintf(&sb, " of %s and parent %s", e.Cti, e.ParentCti)
+	}
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	fmt.Fprintf(&sb, " at %s: %v", path, e.Err)
+	source, _ := json.Marshal(e.Source)
+	target, _ := json.Marshal(e.Target)
+	fmt.Fprintf(&sb, " (child: %s, parent: %s)", source, target)
+	return sb.String()
+}
+
+func (e *MergeConflictError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrSchemaMergeConflict.
+func (e *MergeConflictError) Is(target error) bool {
+	return target == ErrSchemaMergeConflict
+}
+
 // MergeSchemas merges a source schema onto a target one, applying various validations,,
-func MergeSchemas(source, target map[string]any) (map[string]any, error) {
-	mergedSchema, err := mergeObjects(source, target)
+// Conflicts are reported as *MergeConflictError.
+// Neither schema is modified: nodes of the target that are changed by the merge are copied, and the merged schema
+// shares unchanged nodes with both schemas, so merging many children onto the same parent does not copy the parent
+// every time. The merged schema must be copied before it is modified.
+func MergeSchemas(source, target map[string]any, opts ...MergeOption) (map[string]any, error) {
+	var cfg mergeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.strict {
+		if widenings := CheckNarrowing(source, target); len(widenings) != 0 {
+			return nil, widenings[0]
+		}
+	}
+
+	mergedSchema, err := mergeObjects(source, target, "")
 	if err != nil {
 		return nil, err
 	}
@@ -40,46 +107,65 @@ func MergeSchemas(source, target map[string]any) (map[string]any, error) {
 	return mergedSchema, nil
 }
 
-func mergeObjects(source, target map[string]any) (map[string]any, error) {
+func mergeObjects(source, target map[string]any, path string) (map[string]any, error) {
+	conflict := func(err error) error {
+		return &MergeConflictError{Path: path, Source: source, Target: target, Err: err}
+	}
+
 	isSourceAnyOf := isAnyOf(source)
 	isTargetAnyOf := isAnyOf(target)
 	if isSourceAnyOf && !isTargetAnyOf {
-		return nil, errors.New("cannot merge union into non-union type")
+		return nil, conflict(errors.New("cannot merge union into non-union type"))
 	}
 	if !isSourceAnyOf && isTargetAnyOf {
 		// Override the same or any type.
-		var err error
-		target, err = overrideUnionType(source, target)
+		member, err := overrideUnionType(source, target)
 		if err != nil {
-			return nil, err
+			return nil, conflict(err)
 		}
-		isTargetAnyOf = isAnyOf(target)
-		if isTargetAnyOf {
-			return nil, errors.New("cannot specialize union of union")
+		if isAnyOf(member) {
+			return nil, conflict(errors.New("cannot specialize union of union"))
 		}
-	}
-
-	for _, key := range propertiesToMerge {
-		if source[key] != nil {
-			target[key] = source[key]
+		// Keep documentation of the union, e.g. the description of the property, on the specialized member.
+		member = cloneMap(member)
+		for _, key := range documentationKeys {
+			if member[key] == nil && target[key] != nil {
+				member[key] = target[key]
+			}
 		}
+		target = member
+		isTargetAnyOf = false
 	}
+	target = cloneMap(target)
 
 	// Insert source type only if target is any type.
 	isTargetAny := target[typeKey] == nil && !isTargetAnyOf
+	if source[typeKey] != target[typeKey] && !(source[typeKey] != nil && isTargetAny) {
+		return nil, conflict(errors.New("attempting to merge incompatible types"))
+	}
 	if source[typeKey] != nil && isTargetAny {
 		target[typeKey] = source[typeKey]
 	}
-	if source[typeKey] != target[typeKey] {
-		return nil, errors.New("attempting to merge incompatible types")
+
+	for _, key := range propertiesToMerge {
+		if source[key] != nil {
+			target[key] = source[key]
+		}
 	}
 
 	if required, err := mergeRequired(source, target); err != nil {
-		return nil, err
+		return nil, conflict(err)
 	} else if len(required) > 0 {
 		target[requiredKey] = required
 	}
 
+	if source[patternPropertiesKey] != nil {
+		var err error
+		if target, err = mergeNamedSchemas(source, target, patternPropertiesKey, path); err != nil {
+			return nil, err
+		}
+	}
+
 	var mergerFn merger
 	switch {
 	case source[itemsKey] != nil:
@@ -93,15 +179,15 @@ func mergeObjects(source, target map[string]any) (map[string]any, error) {
 		return target, nil
 	}
 
-	return mergerFn(source, target)
+	return mergerFn(source, target, path)
 }
 
-// overrideUnionType does what?
+// overrideUnionType finds the member of the target union that the source type specializes.
 func overrideUnionType(source, target map[string]any) (map[string]any, error) {
 	for _, val := range target[anyOfKey].([]any) {
 		object, ok := val.(map[string]any)
 		if !ok {
-			return nil, errInvalidSchemaError
+			return nil, ErrInvalidSchema
 		}
 		if object[typeKey] == source[typeKey] || (object[typeKey] == nil && object[anyOfKey] == nil) {
 			return object, nil
@@ -111,34 +197,55 @@ func overrideUnionType(source, target map[string]any) (map[string]any, error) {
 	return nil, errors.New("failed to find compatible type in union")
 }
 
-// mergeRequired merges two "required" arrays
+// mergeRequired merges two "required" arrays.
+// Properties required by the target go first in their order followed by the ones added by the source,
+// so the merged schema is the same on every run.
 func mergeRequired(source, target map[string]any) ([]string, error) {
-	// Use maps to simulate sets
-	requiredSet := make(map[any]struct{})
-
-	requiredSrc, _ := source[requiredKey].([]any)
-	for _, item := range requiredSrc {
-		requiredSet[item] = struct{}{}
+	var targetRequired []string
+	seen := make(map[string]struct{})
+
+	// Merged schemas hold "required" as []string, while unmarshalled ones hold it as []any.
+	for _, schema := range []map[string]any{target, source} {
+		switch required := schema[requiredKey].(type) {
+		case []any:
+			for _, item := range required {
+				name, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: required property name must be a string", ErrInvalidSchema)
+				}
+				if _, ok := seen[name]; !ok {
+					seen[name] = struct{}{}
+					targetRequired = append(targetRequired, name)
+				}
+			}
+		case []string:
+			for _, name := range required {
+				if _, ok := seen[name]; !ok {
+					seen[name] = struct{}{}
+					targetRequired = append(targetRequired, name)
+				}
+			}
+		}
 	}
 
-	requiredTrg, _ := target[requiredKey].([]any)
-	for _, item := range requiredTrg {
-		requiredSet[item] = struct{}{}
-	}
+	return targetRequired, nil
+}
 
-	targetRequired := make([]string, 0, len(requiredSet))
-	for key := range requiredSet {
-		targetRequired = append(targetRequired, key.(string))
+// cloneMap makes a shallow copy of the schema node, so the node may be changed without affecting schemas that share it.
+func cloneMap(m map[string]any) map[string]any {
+	res := make(map[string]any, len(m))
+	for k, v := range m {
+		res[k] = v
 	}
-
-	return targetRequired, nil
+	return res
 }
 
-func mergeItems(source, target map[string]any) (map[string]any, error) {
+func mergeItems(source, target map[string]any, path string) (map[string]any, error) {
 	if target[itemsKey] == nil {
 		target[itemsKey] = source[itemsKey]
 	} else {
-		mergedItems, err := mergeObjects(source[itemsKey].(map[string]any), target[itemsKey].(map[string]any))
+		mergedItems, err := mergeObjects(source[itemsKey].(map[string]any), target[itemsKey].(map[string]any),
+			path+"/"+itemsKey)
 		if err != nil {
 			return nil, err
 		}
@@ -147,41 +254,58 @@ func mergeItems(source, target map[string]any) (map[string]any, error) {
 	return target, nil
 }
 
-func mergeProperties(source, target map[string]any) (map[string]any, error) {
-	if target[propertiesKey] == nil {
-		target[propertiesKey] = source[propertiesKey]
+func mergeProperties(source, target map[string]any, path string) (map[string]any, error) {
+	return mergeNamedSchemas(source, target, propertiesKey, path)
+}
+
+// mergeNamedSchemas merges maps of sub-schemas stored under the key, i.e. properties or pattern properties.
+func mergeNamedSchemas(source, target map[string]any, schemasKey string, path string) (map[string]any, error) {
+	if target[schemasKey] == nil {
+		target[schemasKey] = source[schemasKey]
 	} else {
-		for key, property := range source[propertiesKey].(map[string]any) {
-			if targetProperty, ok := target[propertiesKey].(map[string]any)[key]; !ok {
+		sourceProperties := source[schemasKey].(map[string]any)
+		targetProperties := cloneMap(target[schemasKey].(map[string]any))
+		// Properties are merged in the order of their names, so the first conflict is reported on every run.
+		keys := make([]string, 0, len(sourceProperties))
+		for key := range sourceProperties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property := sourceProperties[key]
+			if targetProperty, ok := targetProperties[key]; !ok {
 				propertyBytes, _ := json.Marshal(property)
 				var newProperty map[string]any
 				err := json.Unmarshal(propertyBytes, &newProperty)
 				if err != nil {
 					return nil, err
 				}
-				target[propertiesKey].(map[string]any)[key] = newProperty
+				targetProperties[key] = newProperty
 			} else {
 				var err error
-				mergedProperty, err := mergeObjects(property.(map[string]any), targetProperty.(map[string]any))
+				mergedProperty, err := mergeObjects(property.(map[string]any), targetProperty.(map[string]any),
+					path+"/"+schemasKey+"/"+escapePointerToken(key))
 				if err != nil {
 					return nil, err
 				}
-				target[propertiesKey].(map[string]any)[key] = mergedProperty
+				targetProperties[key] = mergedProperty
 			}
 		}
+		target[schemasKey] = targetProperties
 	}
 	return target, nil
 }
 
-func mergeAnyOf(source, target map[string]any) (map[string]any, error) {
+func mergeAnyOf(source, target map[string]any, path string) (map[string]any, error) {
 	if target[anyOfKey] == nil {
 		target[anyOfKey] = source[anyOfKey]
 	} else {
 		anyOfs := make([]map[string]any, 0)
 		for _, schema := range source[anyOfKey].([]interface{}) {
-			for _, item := range target[anyOfKey].([]interface{}) {
+			for i, item := range target[anyOfKey].([]interface{}) {
 				if item.(map[string]any)[typeKey] == schema.(map[string]any)[typeKey] {
-					merged, err := mergeObjects(schema.(map[string]any), item.(map[string]any))
+					merged, err := mergeObjects(schema.(map[string]any), item.(map[string]any),
+						fmt.Sprintf("%s/%s/%d", path, anyOfKey, i))
 					if err != nil {
 						return nil, err
 					}
@@ -195,6 +319,11 @@ func mergeAnyOf(source, target map[string]any) (map[string]any, error) {
 	return target, nil
 }
 
+// escapePointerToken escapes the reference token of JSON pointer.
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
 // getRefType extracts the type from a ref value.
 // E.g.: "MarketingInfo" from "#/definitions/MarketingInfo"
 func getRefType(ref string) (string, error) {
@@ -202,7 +331,7 @@ func getRefType(ref string) (string, error) {
 	if strings.HasPrefix(ref, prefix) {
 		return ref[len(prefix):], nil
 	}
-	return "", errors.New("non-definition references are not implemented")
+	return "", fmt.Errorf("%w: non-definition references are not implemented", ErrInvalidSchema)
 }
 
 // ExtractSchemaDefinition extracts the actual schema definition from the wider structure,
@@ -210,7 +339,7 @@ func getRefType(ref string) (string, error) {
 func ExtractSchemaDefinition(object map[string]any) (map[string]any, error) {
 	ref, ok := object[refKey].(string)
 	if !ok {
-		return nil, errInvalidSchemaError
+		return nil, ErrInvalidSchema
 	}
 
 	refType, err := getRefType(ref)
@@ -220,12 +349,12 @@ func ExtractSchemaDefinition(object map[string]any) (map[string]any, error) {
 
 	definitions, ok := object[definitionsKey].(map[string]any)
 	if !ok {
-		return nil, errInvalidSchemaError
+		return nil, ErrInvalidSchema
 	}
 
 	schema, ok := definitions[refType].(map[string]any)
 	if !ok || schema == nil {
-		return nil, fmt.Errorf("schema does not have $ref:%s", refType)
+		return nil, fmt.Errorf("%w: schema does not have $ref:%s", ErrInvalidSchema, refType)
 	}
 
 	return schema, nil
@@ -283,7 +412,7 @@ func ValidateSchemaProperty(property map[string]any, name string) error {
 	return nil
 }
 
-func GetMergedCtiSchema(cti string, r *collector.MetadataRegistry) (map[string]interface{}, error) {
+func GetMergedCtiSchema(cti string, r *collector.MetadataRegistry, opts ...MergeOption) (map[string]interface{}, error) {
 	root := cti
 
 	entity, ok := r.Index[root]
@@ -321,8 +450,12 @@ func GetMergedCtiSchema(cti string, r *collector.MetadataRegistry) (map[string]i
 		}
 
 		// NOTE: Resulting schema does not have ref.
-		schema, err = MergeSchemas(schema, parentSchema)
+		schema, err = MergeSchemas(schema, parentSchema, opts...)
 		if err != nil {
+			var conflictErr *MergeConflictError
+			if errors.As(err, &conflictErr) {
+				conflictErr.Cti, conflictErr.ParentCti = cti, parentCti
+			}
 			return nil, err
 		}
 	}
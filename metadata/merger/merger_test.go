@@ -0,0 +1,217 @@
+package merger
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_MergeConflict(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		target string
+		path   string
+		err    string
+	}{
+		{
+			name:   "incompatible property types",
+			source: `{"type": "object", "properties": {"payload": {"type": "object", "properties": {"size": {"type": "string"}}}}}`,
+			target: `{"type": "object", "properties": {"payload": {"type": "object", "properties": {"size": {"type": "integer"}}}}}`,
+			path:   "/properties/payload/properties/size",
+			err:    "attempting to merge incompatible types",
+		},
+		{
+			name:   "incompatible array items",
+			source: `{"type": "array", "items": {"type": "string"}}`,
+			target: `{"type": "array", "items": {"type": "number"}}`,
+			path:   "/items",
+			err:    "attempting to merge incompatible types",
+		},
+		{
+			name:   "union into non-union",
+			source: `{"type": "object", "properties": {"a/b": {"anyOf": [{"type": "string"}]}}}`,
+			target: `{"type": "object", "properties": {"a/b": {"type": "string"}}}`,
+			path:   "/properties/a~1b",
+			err:    "cannot merge union into non-union type",
+		},
+		{
+			name:   "no compatible union member",
+			source: `{"type": "boolean"}`,
+			target: `{"anyOf": [{"type": "string"}, {"type": "number"}]}`,
+			path:   "",
+			err:    "failed to find compatible type in union",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var source, target map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.source), &source))
+			require.NoError(t, json.Unmarshal([]byte(tc.target), &target))
+
+			_, err := MergeSchemas(source, target)
+			var conflictErr *MergeConflictError
+			require.True(t, errors.As(err, &conflictErr))
+			require.Equal(t, tc.path, conflictErr.Path)
+			require.ErrorContains(t, conflictErr.Err, tc.err)
+			require.NotNil(t, conflictErr.Source)
+			require.NotNil(t, conflictErr.Target)
+		})
+	}
+}
+
+func Test_GetMergedCtiSchemaConflict(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.event.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {"size": {"type": "integer"}}}}}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.event.v1.0~x.y.created.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {"size": {"type": "string"}}}}}`),
+	}))
+
+	_, err := GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.created.v1.0", r)
+	var conflictErr *MergeConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.ErrorIs(t, err, ErrSchemaMergeConflict)
+	require.Equal(t, "cti.x.y.event.v1.0~x.y.created.v1.0", conflictErr.Cti)
+	require.Equal(t, "cti.x.y.event.v1.0", conflictErr.ParentCti)
+	require.Equal(t, map[string]any{"type": "string"}, conflictErr.Source)
+	require.Equal(t, map[string]any{"type": "integer"}, conflictErr.Target)
+	require.EqualError(t, err, "failed to merge schemas of cti.x.y.event.v1.0~x.y.created.v1.0 and parent cti.x.y.event.v1.0 "+
+		`at /properties/size: attempting to merge incompatible types (child: {"type":"string"}, parent: {"type":"integer"})`)
+}
+
+func Test_GetMergedCtiSchemaInvalid(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.event.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {}}`),
+	}))
+
+	_, err := GetMergedCtiSchema("cti.x.y.event.v1.0", r)
+	require.ErrorIs(t, err, ErrInvalidSchema)
+	require.NotErrorIs(t, err, ErrSchemaMergeConflict)
+}
+
+func Test_GetMergedCtiSchemaDeterministic(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti: "cti.x.y.event.v1.0",
+			Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {
+				"type": "object", "required": ["type", "id", "time"],
+				"properties": {"type": {"type": "string"}, "id": {"type": "string"}, "time": {"type": "string"}}
+			}}}`),
+		},
+		{
+			Cti: "cti.x.y.event.v1.0~x.y.created.v1.0",
+			Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {
+				"type": "object", "required": ["user", "id", "actor"],
+				"properties": {"user": {"type": "string"}, "actor": {"type": "string"}}
+			}}}`),
+		},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	var first []byte
+	for i := 0; i < 20; i++ {
+		schema, err := GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.created.v1.0", r)
+		require.NoError(t, err)
+		require.Equal(t, []string{"type", "id", "time", "user", "actor"}, schema["required"])
+
+		data, err := json.Marshal(schema)
+		require.NoError(t, err)
+		if first == nil {
+			first = data
+		}
+		require.Equal(t, string(first), string(data))
+	}
+}
+
+func Test_MergeSchemasStructuralSharing(t *testing.T) {
+	const parentJSON = `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"payload": {"type": "object", "properties": {"size": {"type": "integer", "maximum": 100}}},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"value": {"anyOf": [{"type": "string"}, {"type": "number"}]}
+		},
+		"required": ["id"]
+	}`
+	var parent map[string]any
+	require.NoError(t, json.Unmarshal([]byte(parentJSON), &parent))
+
+	for _, child := range []string{
+		`{"type": "object", "properties": {"payload": {"type": "object", "properties": {"size": {"type": "integer", "maximum": 10}}}}, "required": ["payload"]}`,
+		`{"type": "object", "properties": {"tags": {"type": "array", "items": {"type": "string", "maxLength": 8}}}}`,
+		`{"type": "object", "properties": {"value": {"type": "number", "minimum": 0}, "name": {"type": "string"}}}`,
+	} {
+		var source map[string]any
+		require.NoError(t, json.Unmarshal([]byte(child), &source))
+		merged, err := MergeSchemas(source, parent)
+		require.NoError(t, err)
+
+		// The parent is never modified by merges of its children.
+		actual, err := json.Marshal(parent)
+		require.NoError(t, err)
+		require.JSONEq(t, parentJSON, string(actual))
+
+		// Nodes that are not changed by the child are shared with the parent.
+		parentProperties := parent["properties"].(map[string]any)
+		mergedProperties := merged["properties"].(map[string]any)
+		require.Equal(t, reflect.ValueOf(parentProperties["id"]).Pointer(), reflect.ValueOf(mergedProperties["id"]).Pointer())
+	}
+}
+
+func Test_MergeSchemasDocumentation(t *testing.T) {
+	const parentJSON = `{
+		"type": "object",
+		"properties": {
+			"value": {"description": "Value.", "anyOf": [{"type": "string"}, {"type": "number", "title": "Number"}]}
+		},
+		"patternProperties": {
+			"^x-": {"type": "object", "description": "Extension.", "properties": {"name": {"type": "string"}}}
+		}
+	}`
+	const childJSON = `{
+		"type": "object",
+		"properties": {"value": {"type": "number", "minimum": 0}},
+		"patternProperties": {
+			"^x-": {"type": "object", "properties": {"size": {"type": "integer", "description": "Size."}}},
+			"^y-": {"type": "string", "description": "Other extension."}
+		}
+	}`
+	var parent, child map[string]any
+	require.NoError(t, json.Unmarshal([]byte(parentJSON), &parent))
+	require.NoError(t, json.Unmarshal([]byte(childJSON), &child))
+
+	merged, err := MergeSchemas(child, parent)
+	require.NoError(t, err)
+	actual, err := json.Marshal(merged)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"value": {"type": "number", "title": "Number", "description": "Value.", "minimum": 0}
+		},
+		"patternProperties": {
+			"^x-": {
+				"type": "object",
+				"description": "Extension.",
+				"properties": {"name": {"type": "string"}, "size": {"type": "integer", "description": "Size."}}
+			},
+			"^y-": {"type": "string", "description": "Other extension."}
+		}
+	}`, string(actual))
+}
@@ -0,0 +1,98 @@
+package merger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// ErrConstraintWidened is wrapped by *MergeConflictError when a child schema widens a constraint of the parent
+// in the strict inheritance mode.
+var ErrConstraintWidened = errors.New("constraint is widened")
+
+type mergeConfig struct {
+	strict bool
+}
+
+type MergeOption func(*mergeConfig)
+
+// WithStrictInheritance makes the merge fail if the source schema widens constraints of the target one,
+// e.g. lowers the minimum, adds enum values or replaces the pattern. See CheckNarrowing.
+func WithStrictInheritance() MergeOption {
+	return func(c *mergeConfig) {
+		c.strict = true
+	}
+}
+
+// CheckNarrowing reports constraints of the source (child) schema that widen constraints of the target (parent) one.
+// A child may only narrow inherited constraints: raise lower bounds, lower upper bounds, pick a subset of enum values,
+// forbid additional properties and so on. The merge silently replaces such constraints with the ones of the child,
+// so instances of the child may be invalid for the parent. Constraints are compared with jsonschema.CompareConstraints.
+// Widenings are reported as *MergeConflictError wrapping ErrConstraintWidened sorted by path.
+func CheckNarrowing(source, target map[string]any) []*MergeConflictError {
+	var res []*MergeConflictError
+	checkNarrowing(source, target, "", &res)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+func checkNarrowing(source, target map[string]any, path string, res *[]*MergeConflictError) {
+	if !isAnyOf(source) && isAnyOf(target) {
+		member, err := overrideUnionType(source, target)
+		if err != nil {
+			// Reported by the merge.
+			return
+		}
+		target = member
+	}
+	for _, change := range jsonschema.CompareConstraints(target, source) {
+		// Constraints that are absent in the child are inherited from the parent.
+		if !change.Widened || change.Old == nil || change.New == nil {
+			continue
+		}
+		*res = append(*res, &MergeConflictError{
+			Path:   path,
+			Source: source,
+			Target: target,
+			Err:    fmt.Errorf("%w: %s", ErrConstraintWidened, change.Message),
+		})
+	}
+
+	if s, ok := source[itemsKey].(map[string]any); ok {
+		if t, ok := target[itemsKey].(map[string]any); ok {
+			checkNarrowing(s, t, path+"/"+itemsKey, res)
+		}
+	}
+	if s, ok := source[propertiesKey].(map[string]any); ok {
+		if t, ok := target[propertiesKey].(map[string]any); ok {
+			for key, property := range s {
+				sp, ok := property.(map[string]any)
+				if !ok {
+					continue
+				}
+				if tp, ok := t[key].(map[string]any); ok {
+					checkNarrowing(sp, tp, path+"/"+propertiesKey+"/"+escapePointerToken(key), res)
+				}
+			}
+		}
+	}
+	if s, ok := source[anyOfKey].([]any); ok {
+		if t, ok := target[anyOfKey].([]any); ok {
+			for _, member := range s {
+				sm, ok := member.(map[string]any)
+				if !ok {
+					continue
+				}
+				for i, item := range t {
+					if tm, ok := item.(map[string]any); ok && tm[typeKey] == sm[typeKey] {
+						checkNarrowing(sm, tm, fmt.Sprintf("%s/%s/%d", path, anyOfKey, i), res)
+					}
+				}
+			}
+		}
+	}
+}
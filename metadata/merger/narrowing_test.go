@@ -0,0 +1,92 @@
+package merger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckNarrowing(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		target string
+		paths  []string
+		errs   []string
+	}{
+		{
+			name:   "narrowed",
+			source: `{"type": "object", "properties": {"name": {"type": "string", "minLength": 2, "maxLength": 8, "enum": ["a", "b"]}}}`,
+			target: `{"type": "object", "properties": {"name": {"type": "string", "minLength": 1, "maxLength": 10, "enum": ["a", "b", "c"]}}}`,
+		},
+		{
+			name:   "widened bounds",
+			source: `{"type": "object", "properties": {"name": {"type": "string", "minLength": 0, "maxLength": 20}}}`,
+			target: `{"type": "object", "properties": {"name": {"type": "string", "minLength": 1, "maxLength": 10}}}`,
+			paths:  []string{"/properties/name", "/properties/name"},
+			errs:   []string{"minLength is lowered from 1 to 0", "maxLength is raised from 10 to 20"},
+		},
+		{
+			name:   "enum superset",
+			source: `{"type": "array", "items": {"type": "string", "enum": ["a", "d"]}}`,
+			target: `{"type": "array", "items": {"type": "string", "enum": ["a", "b"]}}`,
+			paths:  []string{"/items"},
+			errs:   []string{"enum values are added: d"},
+		},
+		{
+			name:   "replaced pattern",
+			source: `{"type": "object", "properties": {"code": {"type": "string", "pattern": ".*"}}}`,
+			target: `{"type": "object", "properties": {"code": {"type": "string", "pattern": "^[A-Z]+$"}}}`,
+			paths:  []string{"/properties/code"},
+			errs:   []string{"pattern is changed from ^[A-Z]+$ to .*"},
+		},
+		{
+			name:   "allowed additional properties",
+			source: `{"type": "object", "additionalProperties": true}`,
+			target: `{"type": "object", "additionalProperties": false}`,
+			paths:  []string{""},
+			errs:   []string{"additional properties are allowed"},
+		},
+		{
+			name:   "union member",
+			source: `{"type": "number", "maximum": 100}`,
+			target: `{"anyOf": [{"type": "string"}, {"type": "number", "maximum": 10}]}`,
+			paths:  []string{""},
+			errs:   []string{"maximum is raised from 10 to 100"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var source, target map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.source), &source))
+			require.NoError(t, json.Unmarshal([]byte(tc.target), &target))
+
+			widenings := CheckNarrowing(source, target)
+			require.Len(t, widenings, len(tc.errs))
+			for i, w := range widenings {
+				require.Equal(t, tc.paths[i], w.Path)
+				require.ErrorIs(t, w, ErrConstraintWidened)
+				require.ErrorContains(t, w, tc.errs[i])
+			}
+		})
+	}
+}
+
+func Test_MergeSchemasStrict(t *testing.T) {
+	source := map[string]any{"type": "string", "maxLength": float64(20)}
+	target := map[string]any{"type": "string", "maxLength": float64(10)}
+
+	merged, err := MergeSchemas(source, target)
+	require.NoError(t, err)
+	require.Equal(t, float64(20), merged["maxLength"])
+
+	target = map[string]any{"type": "string", "maxLength": float64(10)}
+	_, err = MergeSchemas(source, target, WithStrictInheritance())
+	var conflictErr *MergeConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.ErrorIs(t, err, ErrConstraintWidened)
+	require.ErrorIs(t, err, ErrSchemaMergeConflict)
+}
@@ -0,0 +1,125 @@
+package merger
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// ProjectSchema returns a copy of the schema of an object reduced to the requested top-level properties,
+// e.g. for APIs that expose sparse fieldsets of a type and validate only the requested fields.
+// The schema is either a merged schema or a schema with $ref to its definitions. Required properties
+// are reduced to the requested ones, and only the definitions that are referenced transitively
+// by the projection are kept. The input schema is not modified.
+func ProjectSchema(schema map[string]any, fields []string) (map[string]any, error) {
+	res := jsonschema.DeepCopy(schema).(map[string]any)
+	definitions, _ := res[definitionsKey].(map[string]any)
+
+	root := res
+	if ref, ok := res[refKey].(string); ok {
+		name, err := getRefType(ref)
+		if err != nil {
+			return nil, err
+		}
+		if root, ok = definitions[name].(map[string]any); !ok {
+			return nil, fmt.Errorf("%w: schema does not have $ref:%s", ErrInvalidSchema, name)
+		}
+	}
+
+	properties, ok := root["properties"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: schema is not an object with properties", ErrInvalidSchema)
+	}
+	projected := make(map[string]any, len(fields))
+	for _, field := range fields {
+		property, ok := properties[field]
+		if !ok {
+			return nil, fmt.Errorf("property %s not found", field)
+		}
+		projected[field] = property
+	}
+	root["properties"] = projected
+
+	// Merged schemas hold "required" as []string, while unmarshalled ones hold it as []any.
+	var required []string
+	switch names := root[requiredKey].(type) {
+	case []any:
+		for _, name := range names {
+			if s, ok := name.(string); ok && projected[s] != nil {
+				required = append(required, s)
+			}
+		}
+	case []string:
+		for _, name := range names {
+			if projected[name] != nil {
+				required = append(required, name)
+			}
+		}
+	}
+	if len(required) == 0 {
+		delete(root, requiredKey)
+	} else {
+		root[requiredKey] = required
+	}
+
+	if definitions != nil {
+		res[definitionsKey] = referencedDefinitions(res, definitions)
+	}
+	return res, nil
+}
+
+// referencedDefinitions returns the definitions that are referenced transitively by the schema,
+// not counting the definitions themselves.
+func referencedDefinitions(schema map[string]any, definitions map[string]any) map[string]any {
+	res := make(map[string]any)
+	var queue []string
+	var collect func(v any)
+	collect = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v[refKey].(string); ok {
+				if name, err := getRefType(ref); err == nil {
+					if _, ok := res[name]; !ok {
+						if def, ok := definitions[name]; ok {
+							res[name] = def
+							queue = append(queue, name)
+						}
+					}
+				}
+			}
+			for key, item := range v {
+				if key != definitionsKey {
+					collect(item)
+				}
+			}
+		case []any:
+			for _, item := range v {
+				collect(item)
+			}
+		}
+	}
+	collect(schema)
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		collect(definitions[name])
+	}
+	return res
+}
+
+// GetProjectedCtiSchema returns the merged schema of the CTI type reduced to the requested top-level properties.
+// See ProjectSchema for details.
+func (c *SchemaCache) GetProjectedCtiSchema(cti string, fields []string) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema, err := c.getMergedCtiSchema(cti)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ProjectSchema(schema, fields)
+	if err != nil {
+		return nil, fmt.Errorf("project schema of %s: %w", cti, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,89 @@
+package merger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ProjectSchema(t *testing.T) {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema",
+		"$ref": "#/definitions/Alert",
+		"definitions": {
+			"Alert": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"origin": {"$ref": "#/definitions/Origin"},
+					"tags": {"type": "array", "items": {"$ref": "#/definitions/Tag"}}
+				},
+				"required": ["id", "origin", "tags"],
+				"additionalProperties": false
+			},
+			"Origin": {"type": "object", "properties": {"host": {"$ref": "#/definitions/Host"}}},
+			"Host": {"type": "string", "format": "hostname"},
+			"Tag": {"type": "string"}
+		}
+	}`), &schema))
+
+	projected, err := ProjectSchema(schema, []string{"id", "origin"})
+	require.NoError(t, err)
+	data, err := json.Marshal(projected)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema",
+		"$ref": "#/definitions/Alert",
+		"definitions": {
+			"Alert": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"origin": {"$ref": "#/definitions/Origin"}
+				},
+				"required": ["id", "origin"],
+				"additionalProperties": false
+			},
+			"Origin": {"type": "object", "properties": {"host": {"$ref": "#/definitions/Host"}}},
+			"Host": {"type": "string", "format": "hostname"}
+		}
+	}`, string(data))
+	require.Len(t, schema["definitions"], 4, "input schema must not be modified")
+
+	projected, err = ProjectSchema(schema, []string{"tags"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"tags"}, projected["definitions"].(map[string]any)["Alert"].(map[string]any)["required"])
+	require.Contains(t, projected["definitions"], "Tag")
+
+	_, err = ProjectSchema(schema, []string{"name"})
+	require.EqualError(t, err, "property name not found")
+
+	_, err = ProjectSchema(map[string]any{"type": "string"}, []string{"id"})
+	require.ErrorIs(t, err, ErrInvalidSchema)
+}
+
+func Test_SchemaCacheGetProjectedCtiSchema(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: "cti.x.y.event.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}}}`)}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {"name": {"type": "string"}, "size": {"type": "integer"}}, "required": ["name", "size"]}}}`)}))
+
+	c := NewSchemaCache(r)
+	projected, err := c.GetProjectedCtiSchema("cti.x.y.event.v1.0~x.y.created.v1.0", []string{"id", "size"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": map[string]any{"type": "string"}, "size": map[string]any{"type": "integer"}}, projected["properties"])
+	require.ElementsMatch(t, []string{"id", "size"}, projected["required"])
+
+	merged, err := c.GetMergedCtiSchema("cti.x.y.event.v1.0~x.y.created.v1.0")
+	require.NoError(t, err)
+	require.Len(t, merged["properties"], 3, "cached schema must not be modified")
+
+	_, err = c.GetProjectedCtiSchema("cti.x.y.event.v1.0~x.y.created.v1.0", []string{"missing"})
+	require.EqualError(t, err, "project schema of cti.x.y.event.v1.0~x.y.created.v1.0: property missing not found")
+}
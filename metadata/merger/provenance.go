@@ -0,0 +1,88 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// Provenance maps JSON pointers of properties of the merged schema (e.g. /properties/payload/properties/size)
+// to CTIs of the types that define them.
+type Provenance map[string]string
+
+// GetMergedCtiSchemaWithProvenance returns the merged schema of the CTI type (see GetMergedCtiSchema) along with
+// the provenance of its properties. A property is attributed to the nearest to the root ancestor that declares it,
+// so properties inherited from parents are attributed to the parents even if the type overrides their keywords
+// (use TracePath to get all contributions). Properties are walked through nested objects, array items and
+// members of unions, e.g. /properties/tags/items/properties/name or /properties/target/anyOf/1/properties/id.
+func GetMergedCtiSchemaWithProvenance(cti string, r *collector.MetadataRegistry) (map[string]any, Provenance, error) {
+	schema, err := GetMergedCtiSchema(cti, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var chain []string
+	for root := cti; ; root = metadata.GetParentCti(root) {
+		chain = append(chain, root)
+		if metadata.GetParentCti(root) == root {
+			break
+		}
+	}
+
+	res := make(Provenance)
+	// Ancestors are walked from the root, so the first declaration of the property wins.
+	for i := len(chain) - 1; i >= 0; i-- {
+		entity := r.Index[chain[i]]
+		var entitySchema map[string]any
+		if err := json.Unmarshal(entity.Schema, &entitySchema); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal schema of %s: %w", entity.Cti, err)
+		}
+		definitions, _ := entitySchema[definitionsKey].(map[string]any)
+		node, err := ExtractSchemaDefinition(entitySchema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("extract schema of %s: %w", entity.Cti, err)
+		}
+		collectProvenance(node, definitions, "", entity.Cti, res, make(map[string]struct{}))
+	}
+	return schema, res, nil
+}
+
+// collectProvenance attributes properties of the schema node that are not attributed yet to the CTI type.
+// Definitions that are already being walked are skipped to stop on recursive types.
+func collectProvenance(
+	node map[string]any, definitions map[string]any, path string, cti string, res Provenance, visiting map[string]struct{},
+) {
+	if ref, ok := node[refKey].(string); ok {
+		if _, ok := visiting[ref]; ok {
+			return
+		}
+		visiting[ref] = struct{}{}
+		defer delete(visiting, ref)
+		node = resolveDefinition(node, definitions)
+	}
+
+	if properties, ok := node[propertiesKey].(map[string]any); ok {
+		for name, property := range properties {
+			propertyPath := path + "/" + propertiesKey + "/" + escapePointerToken(name)
+			if _, ok := res[propertyPath]; !ok {
+				res[propertyPath] = cti
+			}
+			if child, ok := property.(map[string]any); ok {
+				collectProvenance(child, definitions, propertyPath, cti, res, visiting)
+			}
+		}
+	}
+	if items, ok := node[itemsKey].(map[string]any); ok {
+		collectProvenance(items, definitions, path+"/"+itemsKey, cti, res, visiting)
+	}
+	if members, ok := node[anyOfKey].([]any); ok {
+		for i, member := range members {
+			if child, ok := member.(map[string]any); ok {
+				collectProvenance(child, definitions, path+"/"+anyOfKey+"/"+strconv.Itoa(i), cti, res, visiting)
+			}
+		}
+	}
+}
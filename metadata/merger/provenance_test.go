@@ -0,0 +1,61 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_GetMergedCtiSchemaWithProvenance(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti: "cti.a.p.event.v1.0",
+			Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {
+				"Event": {"type": "object", "properties": {
+					"id": {"type": "string"},
+					"payload": {"type": "object", "properties": {"size": {"type": "integer"}}},
+					"node": {"$ref": "#/definitions/Node"}
+				}},
+				"Node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/Node"}}}}
+			}}`),
+		},
+		{
+			Cti: "cti.a.p.event.v1.0~a.p.created.v1.0",
+			Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {
+				"payload": {"type": "object", "properties": {
+					"size": {"type": "integer", "maximum": 10},
+					"tags": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}}
+				}},
+				"target": {"anyOf": [{"type": "string"}, {"type": "object", "properties": {"id": {"type": "string"}}}]}
+			}}}}`),
+		},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	schema, provenance, err := GetMergedCtiSchemaWithProvenance("cti.a.p.event.v1.0~a.p.created.v1.0", r)
+	require.NoError(t, err)
+	expected, err := GetMergedCtiSchema("cti.a.p.event.v1.0~a.p.created.v1.0", r)
+	require.NoError(t, err)
+	require.Equal(t, expected, schema)
+
+	const parent, child = "cti.a.p.event.v1.0", "cti.a.p.event.v1.0~a.p.created.v1.0"
+	require.Equal(t, Provenance{
+		"/properties/id":                                            parent,
+		"/properties/payload":                                       parent,
+		"/properties/payload/properties/size":                       parent,
+		"/properties/payload/properties/tags":                       child,
+		"/properties/payload/properties/tags/items/properties/name": child,
+		"/properties/node":                                          parent,
+		"/properties/node/properties/children":                      parent,
+		"/properties/target":                                        child,
+		"/properties/target/anyOf/1/properties/id":                  child,
+	}, provenance)
+
+	_, _, err = GetMergedCtiSchemaWithProvenance("cti.a.p.unknown.v1.0", r)
+	require.EqualError(t, err, "failed to find cti cti.a.p.unknown.v1.0")
+}
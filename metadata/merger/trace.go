@@ -0,0 +1,173 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// PathSource is a contribution of a CTI type to a node of the merged schema.
+type PathSource struct {
+	// Cti is the CTI type that defines the node.
+	Cti string
+	// Keywords are JSON schema keywords of the node defined by the type.
+	Keywords []string
+	// Location is the location of the RAML shape of the node.
+	// If the shape location is unknown, only the path to the RAML file of the type is set.
+	Location metadata.SourceLocation
+}
+
+// TracePath returns sources that contributed keywords to the node of the merged schema of the CTI type.
+// The node is addressed by the JSON pointer in the merged schema (e.g. /properties/items/items/properties/name).
+// Sources are ordered from the type itself to the root parent, so the keywords of the first source
+// override the keywords of the following ones. Types that do not define the node are skipped.
+func TracePath(cti string, r *collector.MetadataRegistry, pointer string) ([]PathSource, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []PathSource
+	for root := cti; ; {
+		entity, ok := r.Index[root]
+		if !ok {
+			return nil, fmt.Errorf("failed to find cti %s", root)
+		}
+		source, ok, err := tracePathInEntity(entity, tokens)
+		if err != nil {
+			return nil, fmt.Errorf("trace path in %s: %w", root, err)
+		}
+		if ok {
+			sources = append(sources, source)
+		}
+
+		parentCti := metadata.GetParentCti(root)
+		if parentCti == root {
+			return sources, nil
+		}
+		root = parentCti
+	}
+}
+
+func tracePathInEntity(entity *metadata.Entity, tokens []string) (PathSource, bool, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+		return PathSource{}, false, err
+	}
+	definitions, _ := schema[definitionsKey].(map[string]any)
+	node, err := ExtractSchemaDefinition(schema)
+	if err != nil {
+		return PathSource{}, false, err
+	}
+
+	path := "."
+	for i := 0; i < len(tokens); i++ {
+		node = resolveDefinition(node, definitions)
+		var next any
+		switch token := tokens[i]; token {
+		case propertiesKey, "patternProperties":
+			if i+1 == len(tokens) {
+				next = node[token]
+				break
+			}
+			i++
+			properties, _ := node[token].(map[string]any)
+			next = properties[tokens[i]]
+			path = joinGJsonPath(path, tokens[i])
+		case itemsKey:
+			next = node[token]
+			path = joinGJsonPath(path, "#")
+		case anyOfKey, "oneOf", "allOf":
+			if i+1 == len(tokens) {
+				next = node[token]
+				break
+			}
+			i++
+			idx, err := strconv.Atoi(tokens[i])
+			if err != nil {
+				return PathSource{}, false, fmt.Errorf("invalid index %s of %s", tokens[i], token)
+			}
+			members, _ := node[token].([]any)
+			if idx >= 0 && idx < len(members) {
+				next = members[idx]
+			}
+		default:
+			next = node[token]
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			if next == nil || i+1 != len(tokens) {
+				return PathSource{}, false, nil
+			}
+			// The pointer addresses a keyword value rather than a subschema.
+			return PathSource{
+				Cti:      entity.Cti,
+				Keywords: []string{tokens[i]},
+				Location: sourceLocation(entity, path),
+			}, true, nil
+		}
+		node = child
+	}
+	node = resolveDefinition(node, definitions)
+
+	keywords := make([]string, 0, len(node))
+	for k := range node {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+
+	return PathSource{
+		Cti:      entity.Cti,
+		Keywords: keywords,
+		Location: sourceLocation(entity, path),
+	}, true, nil
+}
+
+func resolveDefinition(node map[string]any, definitions map[string]any) map[string]any {
+	ref, ok := node[refKey].(string)
+	if !ok {
+		return node
+	}
+	refType, err := getRefType(ref)
+	if err != nil {
+		return node
+	}
+	if def, ok := definitions[refType].(map[string]any); ok {
+		return def
+	}
+	return node
+}
+
+func sourceLocation(entity *metadata.Entity, path string) metadata.SourceLocation {
+	if loc, ok := entity.SchemaSourceMap[metadata.GJsonPath(path)]; ok {
+		return loc
+	}
+	return metadata.SourceLocation{Path: entity.SourceMap.OriginalPath}
+}
+
+func joinGJsonPath(path string, key string) string {
+	if path == "." {
+		return path + key
+	}
+	return path + "." + key
+}
+
+// splitPointer splits JSON pointer into unescaped reference tokens.
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" || pointer == "/" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/acronis/go-cti"
 	"github.com/acronis/go-raml"
 	"github.com/tidwall/gjson"
 )
@@ -12,49 +13,152 @@ type Entities []*Entity
 type EntitiesMap map[string]*Entity
 
 type Entity struct {
-	Final             bool                      `json:"final"`
-	Cti               string                    `json:"cti"`
-	DisplayName       string                    `json:"display_name,omitempty"`
-	Description       string                    `json:"description,omitempty"`
-	Dictionaries      map[string]interface{}    `json:"dictionaries,omitempty"` // Deprecated
-	Values            json.RawMessage           `json:"values,omitempty"`
-	Schema            json.RawMessage           `json:"schema,omitempty"`
-	TraitsSchema      json.RawMessage           `json:"traits_schema,omitempty"`
-	TraitsAnnotations map[GJsonPath]Annotations `json:"traits_annotations,omitempty"`
-	Traits            json.RawMessage           `json:"traits,omitempty"`
-	Annotations       map[GJsonPath]Annotations `json:"annotations,omitempty"`
-	SourceMap         SourceMap                 `json:"source_map,omitempty"`
+	Final              bool                      `json:"final"`
+	Access             string                    `json:"access,omitempty"` // Empty means AccessPublic
+	Status             string                    `json:"status,omitempty"` // Empty means StatusReleased
+	Deprecated         bool                      `json:"deprecated,omitempty"`
+	DeprecationMessage string                    `json:"deprecation_message,omitempty"`
+	ReplacedBy         string                    `json:"replaced_by,omitempty"`
+	Cti                string                    `json:"cti"`
+	DisplayName        string                    `json:"display_name,omitempty"`
+	Description        string                    `json:"description,omitempty"`
+	Dictionaries       map[string]interface{}    `json:"dictionaries,omitempty"` // Deprecated
+	Values             json.RawMessage           `json:"values,omitempty"`
+	Schema             json.RawMessage           `json:"schema,omitempty"`
+	TraitsSchema       json.RawMessage           `json:"traits_schema,omitempty"`
+	TraitsAnnotations  map[GJsonPath]Annotations `json:"traits_annotations,omitempty"`
+	Traits             json.RawMessage           `json:"traits,omitempty"`
+	Annotations        map[GJsonPath]Annotations `json:"annotations,omitempty"`
+	Tags               []string                  `json:"tags,omitempty"`
+	Owners             []string                  `json:"owners,omitempty"`
+	SourceMap          SourceMap                 `json:"source_map,omitempty"`
+	// SchemaSourceMap maps a path in the schema to the location of the RAML shape it was converted from.
+	SchemaSourceMap map[GJsonPath]SourceLocation `json:"schema_source_map,omitempty"`
+	// AnnotationsSourceMap maps a path in the schema and a name of the annotation to the location of the annotation.
+	AnnotationsSourceMap map[GJsonPath]map[string]SourceLocation `json:"annotations_source_map,omitempty"`
+	// ValuesSourceLocation is the location of the values of the instance, e.g. of the item of the annotation
+	// that defines the instance. Values nested into it are located with collector.SourceFile.ValueLocation.
+	ValuesSourceLocation *SourceLocation `json:"values_source_location,omitempty"`
+}
+
+// HasTag returns true if the entity is tagged with the specified tag.
+func (e *Entity) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReleased reports whether the entity is released according to its lifecycle status,
+// i.e. it is neither a draft nor retired. Deprecated entities are still released.
+func (e *Entity) IsReleased() bool {
+	switch e.Status {
+	case StatusDraft, StatusRetired:
+		return false
+	default:
+		return true
+	}
+}
+
+// HasOwner returns true if the entity is owned by the specified owner.
+func (e *Entity) HasOwner(owner string) bool {
+	for _, o := range e.Owners {
+		if o == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCompatibleWith reports whether the entity may be used in place of the other one according to CTI versioning rules:
+// both CTIs identify the same entity, major versions are equal and minor versions of the entity are equal to or newer
+// than minor versions of the other one, e.g. cti.a.p.alert.v1.3 is compatible with cti.a.p.alert.v1.1,
+// but not with cti.a.p.alert.v1.4 or cti.a.p.alert.v2.0.
+func (e *Entity) IsCompatibleWith(other *Entity) (bool, error) {
+	p := cti.NewParser()
+	expr, err := p.ParseIdentifier(e.Cti)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", e.Cti, err)
+	}
+	otherExpr, err := p.ParseIdentifier(other.Cti)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", other.Cti, err)
+	}
+	return otherExpr.SatisfiedBy(expr)
+}
+
+// EntityResolver finds entities by CTI, e.g. collector.MetadataRegistry.
+type EntityResolver interface {
+	GetEntity(cti string) (*Entity, bool)
+}
+
+// ResolveMeta returns the entity referenced by the cti.meta annotation of the property at the path
+// (use "." for the entity itself). It returns nil if the property has no cti.meta annotation.
+func (e *Entity) ResolveMeta(r EntityResolver, key GJsonPath) (*Entity, error) {
+	annotation, ok := e.Annotations[key]
+	if !ok || annotation.Meta == "" {
+		return nil, nil
+	}
+	if _, err := cti.NewParser().ParseIdentifier(annotation.Meta); err != nil {
+		return nil, fmt.Errorf("parse cti.meta %s: %w", annotation.Meta, err)
+	}
+	entity, ok := r.GetEntity(annotation.Meta)
+	if !ok {
+		return nil, fmt.Errorf("cti.meta references unknown entity %s", annotation.Meta)
+	}
+	return entity, nil
 }
 
 // TODO: This is a temporary structure until proper model is outlined. Used by tests.
 type EntityStructured struct {
-	Final             bool                      `json:"final"`
-	Cti               string                    `json:"cti"`
-	DisplayName       string                    `json:"display_name,omitempty"`
-	Description       string                    `json:"description,omitempty"`
-	Dictionaries      map[string]interface{}    `json:"dictionaries,omitempty"` // Deprecated
-	Values            map[string]interface{}    `json:"values,omitempty"`
-	Schema            *raml.JSONSchema          `json:"schema,omitempty"`
-	TraitsSchema      *raml.JSONSchema          `json:"traits_schema,omitempty"`
-	TraitsAnnotations map[GJsonPath]Annotations `json:"traits_annotations,omitempty"`
-	Traits            map[string]interface{}    `json:"traits,omitempty"`
-	Annotations       map[GJsonPath]Annotations `json:"annotations,omitempty"`
-	SourceMap         SourceMap                 `json:"source_map,omitempty"`
+	Final              bool                      `json:"final"`
+	Access             string                    `json:"access,omitempty"`
+	Status             string                    `json:"status,omitempty"`
+	Deprecated         bool                      `json:"deprecated,omitempty"`
+	DeprecationMessage string                    `json:"deprecation_message,omitempty"`
+	ReplacedBy         string                    `json:"replaced_by,omitempty"`
+	Cti                string                    `json:"cti"`
+	DisplayName        string                    `json:"display_name,omitempty"`
+	Description        string                    `json:"description,omitempty"`
+	Dictionaries       map[string]interface{}    `json:"dictionaries,omitempty"` // Deprecated
+	Values             map[string]interface{}    `json:"values,omitempty"`
+	Schema             *raml.JSONSchema          `json:"schema,omitempty"`
+	TraitsSchema       *raml.JSONSchema          `json:"traits_schema,omitempty"`
+	TraitsAnnotations  map[GJsonPath]Annotations `json:"traits_annotations,omitempty"`
+	Traits             map[string]interface{}    `json:"traits,omitempty"`
+	Annotations        map[GJsonPath]Annotations `json:"annotations,omitempty"`
+	Tags               []string                  `json:"tags,omitempty"`
+	Owners             []string                  `json:"owners,omitempty"`
+	SourceMap          SourceMap                 `json:"source_map,omitempty"`
+	// SchemaSourceMap maps a path in the schema to the location of the RAML shape it was converted from.
+	SchemaSourceMap map[GJsonPath]SourceLocation `json:"schema_source_map,omitempty"`
+	// AnnotationsSourceMap maps a path in the schema and a name of the annotation to the location of the annotation.
+	AnnotationsSourceMap map[GJsonPath]map[string]SourceLocation `json:"annotations_source_map,omitempty"`
 }
 
 type Annotations struct {
-	Cti           interface{}            `json:"cti.cti,omitempty"` // string or []string
-	ID            *bool                  `json:"cti.id,omitempty"`  // string or []string
-	DisplayName   *bool                  `json:"cti.display_name,omitempty"`
-	Description   *bool                  `json:"cti.description,omitempty"`
-	Reference     interface{}            `json:"cti.reference,omitempty"` // bool or string or []string
-	Overridable   *bool                  `json:"cti.overridable,omitempty"`
-	Final         *bool                  `json:"cti.final,omitempty"`
-	Asset         *bool                  `json:"cti.asset,omitempty"`
-	L10N          *bool                  `json:"cti.l10n,omitempty"`
-	Schema        interface{}            `json:"cti.schema,omitempty"` // string or []string
-	Meta          string                 `json:"cti.meta,omitempty"`
-	PropertyNames map[string]interface{} `json:"cti.propertyNames,omitempty"`
+	Cti                interface{}            `json:"cti.cti,omitempty"` // string or []string
+	ID                 *bool                  `json:"cti.id,omitempty"`  // string or []string
+	DisplayName        *bool                  `json:"cti.display_name,omitempty"`
+	Description        *bool                  `json:"cti.description,omitempty"`
+	Reference          interface{}            `json:"cti.reference,omitempty"` // bool or string or []string
+	Overridable        *bool                  `json:"cti.overridable,omitempty"`
+	Final              *bool                  `json:"cti.final,omitempty"`
+	Deprecated         *bool                  `json:"cti.deprecated,omitempty"`
+	DeprecationMessage string                 `json:"cti.deprecation_message,omitempty"`
+	ReplacedBy         string                 `json:"cti.replaced_by,omitempty"`
+	Asset              *bool                  `json:"cti.asset,omitempty"`
+	Dictionary         *bool                  `json:"cti.dictionary,omitempty"`
+	Sensitive          *bool                  `json:"cti.sensitive,omitempty"`
+	L10N               *bool                  `json:"cti.l10n,omitempty"`
+	Schema             interface{}            `json:"cti.schema,omitempty"` // string or []string
+	Meta               string                 `json:"cti.meta,omitempty"`
+	PropertyNames      map[string]interface{} `json:"cti.propertyNames,omitempty"`
+	// Extra are annotations of registered third-party namespaces keyed by their full names,
+	// e.g. x-acronis.sensitive. See RegisterAnnotationNamespace.
+	Extra map[string]interface{} `json:"extra,omitempty"`
 }
 
 type SourceMap struct {
@@ -77,6 +181,26 @@ func (a *SourceMap) HasOriginalPath() bool {
 	return a.OriginalPath != ""
 }
 
+// SourceLocation is a range of a node in a RAML file. Lines and columns are one-based and columns are counted in characters.
+// The end is the position right after the last character of the node. Offsets are zero-based and counted in bytes.
+type SourceLocation struct {
+	// Path is a relative path to the RAML file.
+	Path      string `json:"path"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+	EndOffset int    `json:"end_offset,omitempty"`
+}
+
+func (l SourceLocation) String() string {
+	if l.Line == 0 {
+		return l.Path
+	}
+	return fmt.Sprintf("%s:%d:%d", l.Path, l.Line, l.Column)
+}
+
 type AnnotationType struct {
 	Name string `json:"name,omitempty"`
 	Type string `json:"type,omitempty"`
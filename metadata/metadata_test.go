@@ -118,3 +118,63 @@ func Test_GJsonPathGetValue(t *testing.T) {
 		})
 	}
 }
+
+func Test_EntityIsCompatibleWith(t *testing.T) {
+	for _, tc := range []struct {
+		entity, other string
+		expected      bool
+	}{
+		{"cti.a.p.alert.v1.3", "cti.a.p.alert.v1.1", true},
+		{"cti.a.p.alert.v1.1", "cti.a.p.alert.v1.1", true},
+		{"cti.a.p.alert.v1.1", "cti.a.p.alert.v1.3", false},
+		{"cti.a.p.alert.v2.0", "cti.a.p.alert.v1.1", false},
+		{"cti.a.p.incident.v1.1", "cti.a.p.alert.v1.1", false},
+		{"cti.a.p.alert.v1.2~a.p.critical.v1.5", "cti.a.p.alert.v1.0~a.p.critical.v1.1", true},
+		{"cti.a.p.alert.v1.2~a.p.critical.v1.0", "cti.a.p.alert.v1.0~a.p.critical.v1.1", false},
+	} {
+		ok, err := (&Entity{Cti: tc.entity}).IsCompatibleWith(&Entity{Cti: tc.other})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, ok, "%s compatible with %s", tc.entity, tc.other)
+	}
+
+	_, err := (&Entity{Cti: "invalid"}).IsCompatibleWith(&Entity{Cti: "cti.a.p.alert.v1.0"})
+	require.Error(t, err)
+}
+
+func Test_EntityResolveTraits(t *testing.T) {
+	typ := &Entity{
+		Cti:    "cti.a.p.alert.v1.0",
+		Traits: []byte(`{"title": "${@display_name}", "summary": "${@display_name} (${@limit})", "limit": "${@limit}", "tags": ["${@owner.name}"], "missing": "${@missing}!", "static": 1}`),
+	}
+	instance := &Entity{
+		Cti:    "cti.a.p.alert.v1.0~a.p.disk_full.v1.0",
+		Values: []byte(`{"display_name": "Disk is full", "limit": 90, "owner": {"name": "storage"}}`),
+	}
+
+	traits, err := typ.ResolveTraits(instance)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"title":   "Disk is full",
+		"summary": "Disk is full (90)",
+		"limit":   float64(90),
+		"tags":    []any{"storage"},
+		"missing": "!",
+		"static":  float64(1),
+	}, traits)
+
+	attrs, err := TraitPlaceholders(typ.Traits)
+	require.NoError(t, err)
+	require.Equal(t, []string{"display_name", "limit", "missing", "owner.name"}, attrs)
+
+	_, err = typ.ResolveTraits(&Entity{Cti: "cti.a.p.event.v1.0~a.p.created.v1.0"})
+	require.EqualError(t, err, "cti.a.p.event.v1.0~a.p.created.v1.0 is not derived from cti.a.p.alert.v1.0")
+}
+
+func Test_IsDeclaredBy(t *testing.T) {
+	require.True(t, IsDeclaredBy("cti.a.p.event.v1.0", "a.p"))
+	require.True(t, IsDeclaredBy("cti.a.p.event.v1.0~b.q.created.v1.0", "b.q"))
+	require.False(t, IsDeclaredBy("cti.a.p.event.v1.0~b.q.created.v1.0", "a.p"))
+	// Anonymous instances are declared by the package of their type.
+	require.True(t, IsDeclaredBy("cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6", "a.p"))
+	require.False(t, IsDeclaredBy("cti.a.px.event.v1.0", "a.p"))
+}
@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CtiNamespace is the namespace of the annotations defined by the CTI specification.
+const CtiNamespace = "cti"
+
+var (
+	namespacesMu sync.RWMutex
+	namespaces   = make(map[string]map[string]json.RawMessage)
+)
+
+// RegisterAnnotationNamespace registers the namespace of third-party annotations, e.g. x-acronis, so annotations
+// of the namespace (e.g. x-acronis.sensitive) are preserved in Annotations.Extra when they are collected from RAML.
+// The namespace is the alias of the RAML library that declares the annotation types.
+// Schemas are JSON schemas of the values of the annotations keyed by names of the annotations without the namespace.
+// Values of annotations that have no schema in the registered namespace are considered invalid by the validator.
+//
+// The registry is global and safe for concurrent use. The namespace replaces the namespace registered earlier.
+// The cti namespace cannot be registered.
+func RegisterAnnotationNamespace(namespace string, schemas map[string]json.RawMessage) error {
+	if namespace == "" || strings.Contains(namespace, ".") {
+		return fmt.Errorf("invalid annotation namespace %q", namespace)
+	}
+	if namespace == CtiNamespace {
+		return fmt.Errorf("annotation namespace %s is reserved", namespace)
+	}
+	cp := make(map[string]json.RawMessage, len(schemas))
+	for name, schema := range schemas {
+		cp[name] = schema
+	}
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	namespaces[namespace] = cp
+	return nil
+}
+
+// UnregisterAnnotationNamespace removes the namespace, so annotations of the namespace are no longer collected.
+func UnregisterAnnotationNamespace(namespace string) {
+	namespacesMu.Lock()
+	defer namespacesMu.Unlock()
+	delete(namespaces, namespace)
+}
+
+// HasAnnotationNamespace reports whether the namespace of the annotation, e.g. x-acronis.sensitive, is registered.
+func HasAnnotationNamespace(annotation string) bool {
+	namespace, _, ok := strings.Cut(annotation, ".")
+	if !ok {
+		return false
+	}
+	namespacesMu.RLock()
+	defer namespacesMu.RUnlock()
+	_, ok = namespaces[namespace]
+	return ok
+}
+
+// AnnotationSchema returns the JSON schema of the values of the annotation of a registered namespace,
+// e.g. x-acronis.sensitive. It returns false if the namespace is not registered or does not define the annotation.
+func AnnotationSchema(annotation string) (json.RawMessage, bool) {
+	namespace, name, ok := strings.Cut(annotation, ".")
+	if !ok {
+		return nil, false
+	}
+	namespacesMu.RLock()
+	defer namespacesMu.RUnlock()
+	schema, ok := namespaces[namespace][name]
+	return schema, ok
+}
@@ -0,0 +1,34 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterAnnotationNamespace(t *testing.T) {
+	require.Error(t, RegisterAnnotationNamespace(CtiNamespace, nil))
+	require.Error(t, RegisterAnnotationNamespace("", nil))
+	require.Error(t, RegisterAnnotationNamespace("x.acme", nil))
+
+	require.NoError(t, RegisterAnnotationNamespace("x-acme", map[string]json.RawMessage{
+		"sensitive": json.RawMessage(`{"type": "boolean"}`),
+	}))
+	t.Cleanup(func() { UnregisterAnnotationNamespace("x-acme") })
+
+	require.True(t, HasAnnotationNamespace("x-acme.sensitive"))
+	require.True(t, HasAnnotationNamespace("x-acme.unknown"))
+	require.False(t, HasAnnotationNamespace("x-other.sensitive"))
+	require.False(t, HasAnnotationNamespace("x-acme"))
+	require.False(t, HasAnnotationNamespace("cti.final"))
+
+	schema, ok := AnnotationSchema("x-acme.sensitive")
+	require.True(t, ok)
+	require.JSONEq(t, `{"type": "boolean"}`, string(schema))
+	_, ok = AnnotationSchema("x-acme.unknown")
+	require.False(t, ok)
+
+	UnregisterAnnotationNamespace("x-acme")
+	require.False(t, HasAnnotationNamespace("x-acme.sensitive"))
+}
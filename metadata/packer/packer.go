@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/acronis/go-cti/metadata"
 	"github.com/acronis/go-cti/metadata/archiver"
 	"github.com/acronis/go-cti/metadata/collector"
 	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/validator"
 )
 
 const (
@@ -20,6 +22,11 @@ type Packer struct {
 	Archiver            archiver.Archiver
 	AnnotationHandlers  []AnnotationHandler
 	FileExcludeFunction func(fsPath string, e os.DirEntry) error
+	ToolVersion         string
+	Revision            string
+	// ValidatorOptions are set if the package must be validated before packing.
+	ValidatorOptions []validator.Option
+	Validate         bool
 }
 
 type Option func(*Packer) error
@@ -55,6 +62,32 @@ func WithFileExcludeFunction(f func(fsPath string, e os.DirEntry) error) Option
 	}
 }
 
+// WithToolVersion sets the tool version that is recorded into the bundle provenance.
+func WithToolVersion(version string) Option {
+	return func(p *Packer) error {
+		p.ToolVersion = version
+		return nil
+	}
+}
+
+// WithRevision sets the VCS revision of the package sources that is recorded into the bundle provenance.
+func WithRevision(revision string) Option {
+	return func(p *Packer) error {
+		p.Revision = revision
+		return nil
+	}
+}
+
+// WithValidation makes the packer validate the package before packing, so the bundle is not produced
+// if the package fails the validation, the rules or the policies from the options.
+func WithValidation(opts ...validator.Option) Option {
+	return func(p *Packer) error {
+		p.Validate = true
+		p.ValidatorOptions = append(p.ValidatorOptions, opts...)
+		return nil
+	}
+}
+
 type AnnotationHandler func(baseDir string, writer archiver.Archiver,
 	key metadata.GJsonPath, entity *metadata.Entity, a metadata.Annotations) error
 
@@ -83,6 +116,17 @@ func (p *Packer) Pack(pkg *ctipackage.Package, destination string) error {
 		return fmt.Errorf("parse package: %w", err)
 	}
 
+	if p.Validate {
+		if err := pkg.Validate(p.ValidatorOptions...); err != nil {
+			return fmt.Errorf("validate package: %w", err)
+		}
+	}
+
+	provenance, err := pkg.ComputeProvenance(p.ToolVersion, p.Revision, time.Now())
+	if err != nil {
+		return fmt.Errorf("compute provenance: %w", err)
+	}
+
 	zipWriter, err := p.Archiver.Init(destination)
 	if err != nil {
 		return fmt.Errorf("create zip writer: %w", err)
@@ -91,12 +135,28 @@ func (p *Packer) Pack(pkg *ctipackage.Package, destination string) error {
 
 	idx := pkg.Index.Clone()
 	idx.PutSerialized(ctipackage.MetadataCacheFile)
+	idx.Provenance = provenance
+
+	// Entities of the dependencies are packed, so the bundle may be validated without them.
+	var dependencies []byte
+	if len(idx.Depends) != 0 {
+		if dependencies, err = pkg.MarshalDependencies(); err != nil {
+			return fmt.Errorf("serialize dependencies: %w", err)
+		}
+		idx.PutSerialized(ctipackage.DependenciesCacheFile)
+	}
 
 	if err := p.Archiver.WriteBytes(ctipackage.IndexFileName, idx.ToBytes()); err != nil {
 		return fmt.Errorf("write index: %w", err)
 	}
 
 	for _, metadata := range idx.Serialized {
+		if metadata == ctipackage.DependenciesCacheFile {
+			if err := p.Archiver.WriteBytes(metadata, dependencies); err != nil {
+				return fmt.Errorf("write dependencies: %w", err)
+			}
+			continue
+		}
 		if err := p.Archiver.WriteFile(pkg.BaseDir, metadata); err != nil {
 			return fmt.Errorf("write metadata %s: %w", metadata, err)
 		}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/acronis/go-cti/metadata/ctipackage"
 	"github.com/acronis/go-cti/metadata/filesys"
@@ -44,6 +45,10 @@ func (pm *packageManager) downloadDependency(source, version string) (CachedDepe
 		return CachedDependencyInfo{}, fmt.Errorf("read index.json: %w", err)
 	}
 
+	// Sources of the same package version share its directory and integrity files.
+	unlock := pm.lockPackage(depIdx.PackageID, version)
+	defer unlock()
+
 	// Check package integrity and register package
 	if err := pm.updateDependencyCache(source, version, info, depDir, depIdx); err != nil {
 		return CachedDependencyInfo{}, fmt.Errorf("update dependency cache: %w", err)
@@ -70,12 +75,26 @@ func (pm *packageManager) downloadDependency(source, version string) (CachedDepe
 	if err != nil {
 		return CachedDependencyInfo{}, fmt.Errorf("compute directory hash: %w", err)
 	}
+	checksum, err := filesys.ComputeDirectorySHA256(targetDir)
+	if err != nil {
+		return CachedDependencyInfo{}, fmt.Errorf("compute directory checksum: %w", err)
+	}
 
 	return CachedDependencyInfo{
 		Path:      targetDir,
 		Source:    source,
 		Version:   version,
 		Integrity: hash,
+		Checksum:  checksum,
 		Index:     *movedIndex,
 	}, nil
 }
+
+// lockPackage locks installation of the package version and returns the function that unlocks it.
+// Dependencies are downloaded in parallel, and different sources may resolve to the same package,
+// which is known only after the download.
+func (pm *packageManager) lockPackage(id, version string) func() {
+	mu, _ := pm.packageLocks.LoadOrStore(id+"@"+version, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
@@ -1,9 +1,12 @@
 package pacman
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/storage"
 )
 
 func Test_Download(t *testing.T) {
@@ -15,3 +18,40 @@ func Test_Download(t *testing.T) {
 
 	require.Len(t, res, 1)
 }
+
+func Test_DownloadContextCanceled(t *testing.T) {
+	pm, err := New(WithStorage(&mockStorage{}), WithPackagesCache("./fixtures/_packages"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = pm.DownloadContext(ctx, map[string]string{"mock@b1": "v1.0.0"})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// aliasStorage resolves different sources to the same package of mockStorage.
+type aliasStorage struct {
+	mockStorage
+	aliases map[string]string
+}
+
+func (s *aliasStorage) Discover(name string, version string) (storage.Origin, error) {
+	return s.mockStorage.Discover(s.aliases[name], version)
+}
+
+func Test_DownloadSamePackage(t *testing.T) {
+	pm, err := New(WithStorage(&aliasStorage{aliases: map[string]string{
+		"mirror@a": "mock@b1",
+		"mirror@b": "mock@b1",
+		"mirror@c": "mock@b1",
+	}}), WithPackagesCache(t.TempDir()))
+	require.NoError(t, err)
+
+	res, err := pm.Download(map[string]string{"mirror@a": "v1.0.0", "mirror@b": "v1.0.0", "mirror@c": "v1.0.0"})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	for _, info := range res {
+		require.Equal(t, res[0].Path, info.Path)
+		require.Equal(t, res[0].Checksum, info.Checksum)
+	}
+}
@@ -1,6 +1,7 @@
 package pacman
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"path/filepath"
@@ -15,26 +16,28 @@ type CachedDependencyInfo struct {
 	Version   string
 	Integrity string
 	Index     ctipackage.Index
+	// Checksum is the SHA-256 hash of the package content, see ctipackage.Info.Checksum.
+	Checksum string
 }
 
-func (pm *packageManager) installDependencies(pkg *ctipackage.Package, depends map[string]string) error {
+func (pm *packageManager) installDependencies(ctx context.Context, pkg *ctipackage.Package, depends map[string]string) error {
 	// Make sure that package is valid i.e. ramlx spec is in place
 	if err := pkg.Sync(); err != nil {
 		return fmt.Errorf("sync package: %w", err)
 	}
 
-	installed, err := pm.Download(depends)
+	installed, err := pm.download(ctx, depends, []CachedDependencyInfo{}, pkg.IndexLock.SourceInfo)
 	if err != nil {
 		return fmt.Errorf("download dependencies: %w", err)
 	}
 
-	if err := pm.installFromCache(pkg, installed); err != nil {
+	if err := pm.installFromCache(ctx, pkg, installed); err != nil {
 		return fmt.Errorf("install from cache: %w", err)
 	}
 	return nil
 }
 
-func (pm *packageManager) installFromCache(target *ctipackage.Package, depends []CachedDependencyInfo) error {
+func (pm *packageManager) installFromCache(ctx context.Context, target *ctipackage.Package, depends []CachedDependencyInfo) error {
 	// put new dependencies from cache and replace links
 	for _, info := range depends {
 		// Validate integrity with installed package
@@ -73,7 +76,7 @@ func (pm *packageManager) installFromCache(target *ctipackage.Package, depends [
 			return fmt.Errorf("read package: %w", err)
 		}
 
-		if err := pkg.Parse(); err != nil {
+		if err := pkg.ParseContext(ctx); err != nil {
 			return fmt.Errorf("parse package: %w", err)
 		}
 
@@ -89,6 +92,8 @@ func (pm *packageManager) installFromCache(target *ctipackage.Package, depends [
 			Integrity: checksum,
 			Source:    info.Source,
 			Depends:   info.Index.Depends,
+			// Checksum of the downloaded package, since the installed one is extended by parsing.
+			Checksum: info.Checksum,
 		}
 	}
 	return nil
@@ -0,0 +1,209 @@
+package pacman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/acronis/go-cti/metadata/ctipackage"
+)
+
+const (
+	// DefaultDownloadConcurrency is the number of dependencies downloaded in parallel by default.
+	DefaultDownloadConcurrency = 4
+	// DefaultDownloadRetries is the number of retries of a failed download by default.
+	DefaultDownloadRetries = 2
+	// DefaultDownloadBackoff is the delay before the first retry by default. The delay doubles with every retry.
+	DefaultDownloadBackoff = time.Second
+)
+
+// ErrChecksumMismatch is returned when the downloaded package does not match the checksum recorded in the index lock.
+// Such downloads are not retried.
+var ErrChecksumMismatch = errors.New("package checksum does not match index lock")
+
+// DownloadStatus is a stage of a dependency download reported by DownloadManager.
+type DownloadStatus string
+
+const (
+	DownloadStarted   DownloadStatus = "started"
+	DownloadRetrying  DownloadStatus = "retrying"
+	DownloadSucceeded DownloadStatus = "succeeded"
+	DownloadFailed    DownloadStatus = "failed"
+)
+
+// DownloadProgress describes a change of the status of a dependency download.
+type DownloadProgress struct {
+	Source  string
+	Version string
+	Status  DownloadStatus
+	// Attempt is the number of the attempt starting from 1.
+	Attempt int
+	// Done is the number of finished downloads, either successful or not, out of Total.
+	Done  int
+	Total int
+	// Err is the error of the failed attempt for DownloadRetrying and DownloadFailed.
+	Err error
+}
+
+// DownloadFunc downloads the version of the dependency from the source into the packages cache.
+type DownloadFunc func(source, version string) (CachedDependencyInfo, error)
+
+// DownloadManager downloads multiple dependencies in parallel, retries failed downloads with exponential backoff
+// and verifies checksums of downloaded packages against the index lock.
+type DownloadManager struct {
+	// Concurrency limits the number of parallel downloads. Zero means DefaultDownloadConcurrency.
+	Concurrency int
+	// Retries is the number of retries of a failed download.
+	Retries int
+	// Backoff is the delay before the first retry. The delay doubles with every retry.
+	Backoff time.Duration
+	// OnProgress is called when the status of a download changes. Calls are serialized,
+	// but may come from different goroutines.
+	OnProgress func(DownloadProgress)
+
+	download DownloadFunc
+	mu       sync.Mutex
+	done     int
+	total    int
+}
+
+// NewDownloadManager makes a manager that downloads dependencies with the function.
+func NewDownloadManager(download DownloadFunc) *DownloadManager {
+	return &DownloadManager{
+		Concurrency: DefaultDownloadConcurrency,
+		Retries:     DefaultDownloadRetries,
+		Backoff:     DefaultDownloadBackoff,
+		download:    download,
+	}
+}
+
+// DownloadAll downloads the dependencies, i.e. versions by sources, and returns them in the order of their sources.
+// Locked are entries of the index lock by sources: if the locked version of the source is downloaded and the lock
+// has its checksum, the checksum of the downloaded package must match it. Pending downloads are canceled when
+// any download fails or the context is done, and errors of all failed downloads are returned.
+func (dm *DownloadManager) DownloadAll(ctx context.Context, depends map[string]string, locked map[string]ctipackage.Info) ([]CachedDependencyInfo, error) {
+	sources := make([]string, 0, len(depends))
+	for source := range depends {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	concurrency := dm.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultDownloadConcurrency
+	}
+
+	dm.mu.Lock()
+	dm.done, dm.total = 0, len(sources)
+	dm.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]CachedDependencyInfo, len(sources))
+	errs := make([]error, len(sources))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("download dependency %s %s: %w", source, depends[source], ctx.Err())
+				return
+			}
+			info, err := dm.downloadOne(ctx, source, depends[source], locked)
+			if err != nil {
+				errs[i] = fmt.Errorf("download dependency %s %s: %w", source, depends[source], err)
+				cancel()
+				return
+			}
+			results[i] = info
+		}(i, source)
+	}
+	wg.Wait()
+
+	// Downloads canceled because of another failure are not reported.
+	var failed, canceled []error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+			canceled = append(canceled, err)
+		default:
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) != 0 {
+		return nil, errors.Join(failed...)
+	}
+	if len(canceled) != 0 {
+		return nil, canceled[0]
+	}
+	return results, nil
+}
+
+func (dm *DownloadManager) downloadOne(ctx context.Context, source, version string, locked map[string]ctipackage.Info) (CachedDependencyInfo, error) {
+	backoff := dm.Backoff
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return CachedDependencyInfo{}, err
+		}
+		dm.report(DownloadProgress{Source: source, Version: version, Status: DownloadStarted, Attempt: attempt})
+		info, err := dm.download(source, version)
+		if err == nil {
+			err = verifyChecksum(info, locked)
+		}
+		if err == nil {
+			dm.report(DownloadProgress{Source: source, Version: version, Status: DownloadSucceeded, Attempt: attempt})
+			return info, nil
+		}
+		if attempt > dm.Retries || errors.Is(err, ErrChecksumMismatch) {
+			dm.report(DownloadProgress{Source: source, Version: version, Status: DownloadFailed, Attempt: attempt, Err: err})
+			return CachedDependencyInfo{}, err
+		}
+		dm.report(DownloadProgress{Source: source, Version: version, Status: DownloadRetrying, Attempt: attempt, Err: err})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			dm.report(DownloadProgress{Source: source, Version: version, Status: DownloadFailed, Attempt: attempt, Err: err})
+			return CachedDependencyInfo{}, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+func (dm *DownloadManager) report(p DownloadProgress) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if p.Status == DownloadSucceeded || p.Status == DownloadFailed {
+		dm.done++
+	}
+	if dm.OnProgress == nil {
+		return
+	}
+	p.Done, p.Total = dm.done, dm.total
+	dm.OnProgress(p)
+}
+
+// verifyChecksum checks the downloaded package against the checksum of the same version in the index lock.
+func verifyChecksum(info CachedDependencyInfo, locked map[string]ctipackage.Info) error {
+	lock, ok := locked[info.Source]
+	if !ok || lock.Version != info.Version || lock.Checksum == "" {
+		return nil
+	}
+	if lock.Checksum != info.Checksum {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, lock.Checksum, info.Checksum)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package pacman
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/ctipackage"
+)
+
+func Test_DownloadManagerConcurrency(t *testing.T) {
+	var running, maxRunning int32
+	dm := NewDownloadManager(func(source, version string) (CachedDependencyInfo, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return CachedDependencyInfo{Source: source, Version: version}, nil
+	})
+	dm.Concurrency = 2
+
+	var mu sync.Mutex
+	var finished []int
+	dm.OnProgress = func(p DownloadProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 5, p.Total)
+		if p.Status == DownloadSucceeded {
+			finished = append(finished, p.Done)
+		}
+	}
+
+	res, err := dm.DownloadAll(context.Background(), map[string]string{
+		"mock@e": "v1.0.0", "mock@d": "v1.0.0", "mock@c": "v1.0.0", "mock@b": "v1.0.0", "mock@a": "v1.0.0",
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+	// Results are ordered by sources.
+	for i, source := range []string{"mock@a", "mock@b", "mock@c", "mock@d", "mock@e"} {
+		require.Equal(t, source, res[i].Source)
+	}
+	require.Equal(t, int32(2), maxRunning)
+	require.Equal(t, []int{1, 2, 3, 4, 5}, finished)
+}
+
+func Test_DownloadManagerRetries(t *testing.T) {
+	attempts := 0
+	dm := NewDownloadManager(func(source, version string) (CachedDependencyInfo, error) {
+		attempts++
+		if attempts < 3 {
+			return CachedDependencyInfo{}, errors.New("connection reset")
+		}
+		return CachedDependencyInfo{Source: source, Version: version}, nil
+	})
+	dm.Backoff = time.Millisecond
+
+	var statuses []DownloadStatus
+	dm.OnProgress = func(p DownloadProgress) {
+		statuses = append(statuses, p.Status)
+	}
+
+	res, err := dm.DownloadAll(context.Background(), map[string]string{"mock@a": "v1.0.0"}, nil)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, []DownloadStatus{
+		DownloadStarted, DownloadRetrying, DownloadStarted, DownloadRetrying, DownloadStarted, DownloadSucceeded,
+	}, statuses)
+
+	attempts = -10
+	_, err = dm.DownloadAll(context.Background(), map[string]string{"mock@a": "v1.0.0"}, nil)
+	require.EqualError(t, err, "download dependency mock@a v1.0.0: connection reset")
+	require.Equal(t, -7, attempts)
+}
+
+func Test_DownloadManagerChecksum(t *testing.T) {
+	attempts := 0
+	dm := NewDownloadManager(func(source, version string) (CachedDependencyInfo, error) {
+		attempts++
+		return CachedDependencyInfo{Source: source, Version: version, Checksum: "h1:actual"}, nil
+	})
+
+	locked := map[string]ctipackage.Info{"mock@a": {Version: "v1.0.0", Checksum: "h1:expected"}}
+	_, err := dm.DownloadAll(context.Background(), map[string]string{"mock@a": "v1.0.0"}, locked)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	require.EqualError(t, err, "download dependency mock@a v1.0.0: package checksum does not match index lock: expected h1:expected, got h1:actual")
+	require.Equal(t, 1, attempts)
+
+	// Other versions are not verified against the lock.
+	_, err = dm.DownloadAll(context.Background(), map[string]string{"mock@a": "v1.1.0"}, locked)
+	require.NoError(t, err)
+}
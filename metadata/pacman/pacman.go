@@ -1,8 +1,11 @@
 package pacman
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/acronis/go-cti/metadata/ctipackage"
 	"github.com/acronis/go-cti/metadata/storage"
@@ -17,6 +20,12 @@ type PackageManager interface {
 	Install(pkg *ctipackage.Package) error
 	// Download dependencies and their sub-dependencies
 	Download(depends map[string]string) ([]CachedDependencyInfo, error)
+
+	// AddContext, InstallContext and DownloadContext are like Add, Install and Download,
+	// but cancel pending downloads and stop with the error of the context when the context is done.
+	AddContext(ctx context.Context, pkg *ctipackage.Package, depends map[string]string) error
+	InstallContext(ctx context.Context, pkg *ctipackage.Package) error
+	DownloadContext(ctx context.Context, depends map[string]string) ([]CachedDependencyInfo, error)
 }
 
 type Option func(*packageManager)
@@ -24,10 +33,22 @@ type Option func(*packageManager)
 type packageManager struct {
 	PackagesDir string
 	Storage     storage.Storage
+	// Concurrency, Retries, Backoff and OnProgress configure downloads, see DownloadManager.
+	Concurrency int
+	Retries     int
+	Backoff     time.Duration
+	OnProgress  func(DownloadProgress)
+
+	// packageLocks serialize installation of package versions by <package id>@<version>, see lockPackage.
+	packageLocks sync.Map
 }
 
 func New(options ...Option) (PackageManager, error) {
-	pm := &packageManager{}
+	pm := &packageManager{
+		Concurrency: DefaultDownloadConcurrency,
+		Retries:     DefaultDownloadRetries,
+		Backoff:     DefaultDownloadBackoff,
+	}
 
 	for _, o := range options {
 		o(pm)
@@ -59,9 +80,36 @@ func WithPackagesCache(cacheDir string) Option {
 	}
 }
 
+// WithConcurrency limits the number of dependencies downloaded in parallel.
+func WithConcurrency(n int) Option {
+	return func(pm *packageManager) {
+		pm.Concurrency = n
+	}
+}
+
+// WithRetries sets the number of retries of a failed download and the delay before the first retry.
+// The delay doubles with every retry.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(pm *packageManager) {
+		pm.Retries = retries
+		pm.Backoff = backoff
+	}
+}
+
+// WithDownloadProgress makes the package manager report progress of downloads, see DownloadManager.OnProgress.
+func WithDownloadProgress(onProgress func(DownloadProgress)) Option {
+	return func(pm *packageManager) {
+		pm.OnProgress = onProgress
+	}
+}
+
 func (pm *packageManager) Add(pkg *ctipackage.Package, depends map[string]string) error {
+	return pm.AddContext(context.Background(), pkg, depends)
+}
+
+func (pm *packageManager) AddContext(ctx context.Context, pkg *ctipackage.Package, depends map[string]string) error {
 	// Validate dependencies
-	if err := pm.installDependencies(pkg, depends); err != nil {
+	if err := pm.installDependencies(ctx, pkg, depends); err != nil {
 		return fmt.Errorf("install dependencies: %w", err)
 	}
 
@@ -86,7 +134,11 @@ func (pm *packageManager) Add(pkg *ctipackage.Package, depends map[string]string
 }
 
 func (pm *packageManager) Install(pkg *ctipackage.Package) error {
-	if err := pm.installDependencies(pkg, pkg.Index.Depends); err != nil {
+	return pm.InstallContext(context.Background(), pkg)
+}
+
+func (pm *packageManager) InstallContext(ctx context.Context, pkg *ctipackage.Package) error {
+	if err := pm.installDependencies(ctx, pkg, pkg.Index.Depends); err != nil {
 		return fmt.Errorf("install index dependencies: %w", err)
 	}
 	if err := pkg.SaveIndexLock(); err != nil {
@@ -95,14 +147,22 @@ func (pm *packageManager) Install(pkg *ctipackage.Package) error {
 	return nil
 }
 
-func (pm *packageManager) download(depends map[string]string, installed []CachedDependencyInfo) ([]CachedDependencyInfo, error) {
-	subDepends := map[string]string{}
-	for source, version := range depends {
-		info, err := pm.downloadDependency(source, version)
-		if err != nil {
-			return nil, fmt.Errorf("download dependency %s %s: %w", source, version, err)
-		}
+// download downloads the dependencies in parallel and then their sub-dependencies.
+// Locked are entries of the index lock by sources whose checksums are verified, see DownloadManager.DownloadAll.
+func (pm *packageManager) download(ctx context.Context, depends map[string]string, installed []CachedDependencyInfo, locked map[string]ctipackage.Info) ([]CachedDependencyInfo, error) {
+	dm := NewDownloadManager(pm.downloadDependency)
+	dm.Concurrency = pm.Concurrency
+	dm.Retries = pm.Retries
+	dm.Backoff = pm.Backoff
+	dm.OnProgress = pm.OnProgress
+	downloaded, err := dm.DownloadAll(ctx, depends, locked)
+	if err != nil {
+		return nil, err
+	}
 
+	subDepends := map[string]string{}
+	for _, info := range downloaded {
+		source := info.Source
 		installed = append(installed, info)
 		// TODO check for cyclic dependencies or duplicates
 		for subSource, subTag := range info.Index.Depends {
@@ -121,11 +181,11 @@ func (pm *packageManager) download(depends map[string]string, installed []Cached
 					slog.String("version", subTag))
 
 				// compare versions
-				installedVers, err := semver.Parse(installedDep.Version)
+				installedVers, err := semver.ParseTolerant(installedDep.Version)
 				if err != nil {
 					return nil, fmt.Errorf("parse installed version %s: %w", installedDep.Version, err)
 				}
-				depVers, err := semver.Parse(subTag)
+				depVers, err := semver.ParseTolerant(subTag)
 				if err != nil {
 					return nil, fmt.Errorf("parse dependency version %s: %w", subTag, err)
 				}
@@ -160,7 +220,7 @@ func (pm *packageManager) download(depends map[string]string, installed []Cached
 	// Recursively download sub-dependencies
 	if len(subDepends) != 0 {
 		slog.Info("Download sub-dependencies")
-		inst, err := pm.download(subDepends, installed)
+		inst, err := pm.download(ctx, subDepends, installed, locked)
 		if err != nil {
 			return nil, fmt.Errorf("download sub-dependencies: %w", err)
 		}
@@ -171,5 +231,9 @@ func (pm *packageManager) download(depends map[string]string, installed []Cached
 }
 
 func (pm *packageManager) Download(depends map[string]string) ([]CachedDependencyInfo, error) {
-	return pm.download(depends, []CachedDependencyInfo{})
+	return pm.DownloadContext(context.Background(), depends)
+}
+
+func (pm *packageManager) DownloadContext(ctx context.Context, depends map[string]string) ([]CachedDependencyInfo, error) {
+	return pm.download(ctx, depends, []CachedDependencyInfo{}, nil)
 }
@@ -54,6 +54,18 @@ func Test_Add(t *testing.T) {
 			require.NoError(t, pkg.Initialize())
 
 			require.NoError(t, pm.Add(pkg, tc.depends))
+
+			// Reinstalled dependencies match the checksums of the index lock.
+			for source, info := range pkg.IndexLock.SourceInfo {
+				require.NotEmpty(t, info.Checksum, source)
+			}
+			require.NoError(t, pm.Add(pkg, tc.depends))
+
+			for source, info := range pkg.IndexLock.SourceInfo {
+				info.Checksum = "h1:tampered"
+				pkg.IndexLock.SourceInfo[source] = info
+			}
+			require.ErrorIs(t, pm.Add(pkg, tc.depends), ErrChecksumMismatch)
 		})
 	}
 }
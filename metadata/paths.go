@@ -0,0 +1,167 @@
+package metadata
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// ValuePath is a concrete path of a value node of the instance, one segment per object property or array index.
+// Unlike GJsonPath, names of properties are kept as is, so they may contain dots.
+type ValuePath []string
+
+// String returns the path in the dotted form, e.g. .items.0.name. Dots in names of properties are not escaped,
+// so the result is for display only.
+func (p ValuePath) String() string {
+	return "." + strings.Join(p, ".")
+}
+
+// GetValue returns the value at the path.
+func (p ValuePath) GetValue(values []byte) gjson.Result {
+	res := gjson.ParseBytes(values)
+	for _, segment := range p {
+		if !res.Exists() {
+			break
+		}
+		res = res.Get(gjson.Escape(segment))
+	}
+	return res
+}
+
+// Segments splits the annotation path into segments, e.g. .items.#./^x\..+$/ into items, # and /^x\..+$/.
+// Segments of pattern properties are kept whole, although their regular expressions may contain dots.
+func (k GJsonPath) Segments() []string {
+	s := strings.TrimPrefix(k.String(), ".")
+	var res []string
+	for s != "" {
+		end := strings.IndexByte(s, '.')
+		if strings.HasPrefix(s, "/") {
+			// The pattern ends with the slash that is followed by a dot or by the end of the path.
+			end = -1
+			for i := 1; i < len(s); i++ {
+				if s[i] == '/' && (i == len(s)-1 || s[i+1] == '.') {
+					end = i + 1
+					break
+				}
+			}
+		}
+		if end < 0 {
+			end = len(s)
+		}
+		res = append(res, s[:end])
+		s = strings.TrimPrefix(s[end:], ".")
+	}
+	return res
+}
+
+// AnnotatedValue is a value node of the instance that an annotation path applies to.
+type AnnotatedValue struct {
+	// Path is a concrete path of the value, where items of arrays are addressed by indices, e.g. [items 0 name].
+	Path ValuePath
+	// Key is a path of the annotations that apply to the value, e.g. .items.#.name.
+	Key         GJsonPath
+	Value       gjson.Result
+	Annotations Annotations
+}
+
+// HasPrefix reports whether the path is equal to the prefix or is nested into it, e.g. .items.#.name
+// is nested into .items and .items.#. Paths are compared by segments, and a trailing dot of the prefix is ignored,
+// so .items. matches everything under .items, but .item does not match .items.
+func (k GJsonPath) HasPrefix(prefix GJsonPath) bool {
+	p := strings.TrimSuffix(prefix.String(), ".")
+	if p == "" {
+		return true
+	}
+	s := k.String()
+	return s == p || strings.HasPrefix(s, p+".")
+}
+
+// FindAnnotations returns annotations of the entity which paths have the prefix (see GJsonPath.HasPrefix).
+func (e *Entity) FindAnnotations(prefix GJsonPath) map[GJsonPath]Annotations {
+	res := make(map[GJsonPath]Annotations)
+	for key, annotation := range e.Annotations {
+		if key.HasPrefix(prefix) {
+			res[key] = annotation
+		}
+	}
+	return res
+}
+
+// ResolveAnnotatedValues returns value nodes of the instance values that the annotations apply to,
+// e.g. annotations of the type of the instance or of its ancestors. Annotations of array items (#)
+// apply to every item of the array and annotations of pattern properties (/regexp/) apply to every
+// matching property. Paths that are absent in the values are skipped.
+// The result is sorted by the concrete paths of the values.
+func ResolveAnnotatedValues(values []byte, annotations map[GJsonPath]Annotations) []AnnotatedValue {
+	root := gjson.ParseBytes(values)
+	var res []AnnotatedValue
+	for key, annotation := range annotations {
+		resolveAnnotatedValues(root, ValuePath{}, key.Segments(), func(path ValuePath, value gjson.Result) {
+			res = append(res, AnnotatedValue{Path: path, Key: key, Value: value, Annotations: annotation})
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if c := compareValuePaths(res[i].Path, res[j].Path); c != 0 {
+			return c < 0
+		}
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
+
+func compareValuePaths(a, b ValuePath) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func resolveAnnotatedValues(node gjson.Result, path ValuePath, segments []string, fn func(path ValuePath, value gjson.Result)) {
+	if !node.Exists() {
+		return
+	}
+	if len(segments) == 0 {
+		fn(path, node)
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+	// Paths of sibling nodes must not share the underlying array.
+	path = path[:len(path):len(path)]
+	switch {
+	case segment == "#":
+		if !node.IsArray() {
+			return
+		}
+		for i, item := range node.Array() {
+			resolveAnnotatedValues(item, append(path, strconv.Itoa(i)), rest, fn)
+		}
+	case isPatternSegment(segment):
+		re, err := regexp.Compile(segment[1 : len(segment)-1])
+		if err != nil || !node.IsObject() {
+			return
+		}
+		node.ForEach(func(name, value gjson.Result) bool {
+			if re.MatchString(name.String()) {
+				resolveAnnotatedValues(value, append(path, name.String()), rest, fn)
+			}
+			return true
+		})
+	default:
+		if node.IsObject() {
+			resolveAnnotatedValues(node.Get(gjson.Escape(segment)), append(path, segment), rest, fn)
+		}
+	}
+}
+
+// isPatternSegment reports whether the segment of the annotation path is a pattern property, e.g. /^x-/.
+func isPatternSegment(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "/") && strings.HasSuffix(segment, "/")
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GJsonPathHasPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		path, prefix GJsonPath
+		expected     bool
+	}{
+		{".items.#.name", ".items", true},
+		{".items.#.name", ".items.", true},
+		{".items.#.name", ".items.#", true},
+		{".items", ".items", true},
+		{".items", ".item", false},
+		{".items", ".items.#", false},
+		{".items", ".", true},
+		{".", ".", true},
+	} {
+		require.Equal(t, tc.expected, tc.path.HasPrefix(tc.prefix), "%s has prefix %s", tc.path, tc.prefix)
+	}
+}
+
+func Test_EntityFindAnnotations(t *testing.T) {
+	yes := true
+	e := &Entity{Annotations: map[GJsonPath]Annotations{
+		".id":           {ID: &yes},
+		".items":        {Final: &yes},
+		".items.#.name": {DisplayName: &yes},
+		".itemsCount":   {Final: &yes},
+	}}
+	require.Equal(t, map[GJsonPath]Annotations{
+		".items":        {Final: &yes},
+		".items.#.name": {DisplayName: &yes},
+	}, e.FindAnnotations(".items."))
+	require.Len(t, e.FindAnnotations("."), 4)
+	require.Empty(t, e.FindAnnotations(".missing"))
+}
+
+func Test_ResolveAnnotatedValues(t *testing.T) {
+	yes := true
+	annotations := map[GJsonPath]Annotations{
+		".":                      {Final: &yes},
+		".id":                    {ID: &yes},
+		".items.#.secret":        {Extra: map[string]any{"x.sensitive": true}},
+		".labels./^x-/":          {Extra: map[string]any{"x.internal": true}},
+		".missing":               {Description: &yes},
+		".matrix.#.#":            {Final: &yes},
+		".items.#.nested.absent": {Final: &yes},
+	}
+	values := []byte(`{
+		"id": "cti.a.p.item.v1.0~a.p.first.v1.0",
+		"items": [{"secret": "a"}, {"name": "b"}, {"secret": "c"}],
+		"labels": {"x-owner": "me", "team": "core"},
+		"matrix": [[1], [2, 3]]
+	}`)
+
+	var paths []string
+	for _, v := range ResolveAnnotatedValues(values, annotations) {
+		paths = append(paths, v.Path.String()+"="+v.Key.String()+"="+v.Value.Raw)
+	}
+	require.Equal(t, []string{
+		`.=.=` + string(values),
+		`.id=.id="cti.a.p.item.v1.0~a.p.first.v1.0"`,
+		`.items.0.secret=.items.#.secret="a"`,
+		`.items.2.secret=.items.#.secret="c"`,
+		`.labels.x-owner=.labels./^x-/="me"`,
+		`.matrix.0.0=.matrix.#.#=1`,
+		`.matrix.1.0=.matrix.#.#=2`,
+		`.matrix.1.1=.matrix.#.#=3`,
+	}, paths)
+
+	resolved := ResolveAnnotatedValues(values, map[GJsonPath]Annotations{".items.#.secret": annotations[".items.#.secret"]})
+	require.Len(t, resolved, 2)
+	require.Equal(t, annotations[".items.#.secret"], resolved[0].Annotations)
+	require.Equal(t, "a", resolved[0].Path.GetValue(values).String())
+}
+
+func Test_GJsonPathSegments(t *testing.T) {
+	for _, tc := range []struct {
+		path     GJsonPath
+		expected []string
+	}{
+		{".", nil},
+		{".items.#.name", []string{"items", "#", "name"}},
+		{".labels./.*/", []string{"labels", "/.*/"}},
+		{`.labels./^x\..+$/.value`, []string{"labels", `/^x\..+$/`, "value"}},
+		{"./a/b/.c", []string{"/a/b/", "c"}},
+	} {
+		require.Equal(t, tc.expected, tc.path.Segments(), "%s", tc.path)
+	}
+}
+
+func Test_ResolveAnnotatedValuesPatterns(t *testing.T) {
+	yes := true
+	annotations := map[GJsonPath]Annotations{
+		".secrets./.*/":           {Final: &yes},
+		`.labels./^x\..+$/.value`: {Final: &yes},
+	}
+	values := []byte(`{
+		"secrets": {"db": "hunter2", "api.key": "k"},
+		"labels": {"x.owner": {"value": "me"}, "xowner": {"value": "you"}}
+	}`)
+
+	var paths []ValuePath
+	for _, v := range ResolveAnnotatedValues(values, annotations) {
+		paths = append(paths, v.Path)
+		require.Equal(t, v.Value.Raw, v.Path.GetValue(values).Raw)
+	}
+	require.Equal(t, []ValuePath{
+		{"labels", "x.owner", "value"},
+		{"secrets", "api.key"},
+		{"secrets", "db"},
+	}, paths)
+}
@@ -0,0 +1,672 @@
+package protobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+const (
+	// DefaultPackage is a name of the protobuf package of the generated messages by default.
+	DefaultPackage = "cti"
+
+	valueType   = "google.protobuf.Value"
+	structType  = "google.protobuf.Struct"
+	listType    = "google.protobuf.ListValue"
+	structProto = "google/protobuf/struct.proto"
+
+	// TraitsOption is a name of the custom message option that holds traits of the type as a JSON string, see WithTraits.
+	TraitsOption = "traits"
+	// TraitsOptionNumber is a field number of the TraitsOption extension from the range reserved for internal use.
+	TraitsOptionNumber = 50000
+
+	descriptorProto = "google/protobuf/descriptor.proto"
+)
+
+type options struct {
+	pkg    string
+	filter *cti.Expression
+
+	withTraits bool
+	traitKeys  []string
+}
+
+type Option func(*options) error
+
+// WithPackage sets a name of the protobuf package of the generated messages, e.g. acme.events.v1.
+func WithPackage(name string) Option {
+	return func(o *options) error {
+		for _, part := range strings.Split(name, ".") {
+			if !isIdent(part) {
+				return fmt.Errorf("invalid package name %s", name)
+			}
+		}
+		o.pkg = name
+		return nil
+	}
+}
+
+// WithFilter keeps only types that match the CTI expression.
+func WithFilter(expr string) Option {
+	return func(o *options) error {
+		if expr == "" {
+			return nil
+		}
+		e, err := cti.Parse(expr)
+		if err != nil {
+			return fmt.Errorf("parse filter: %w", err)
+		}
+		o.filter = &e
+		return nil
+	}
+}
+
+// WithTraits sets the TraitsOption custom option of messages to traits of the type merged with traits
+// of its ancestors as a JSON string, e.g. option (traits) = "{\"severity\":\"high\"}".
+// The option is declared as an extension of google.protobuf.MessageOptions in the generated file.
+// Only the listed top-level traits are included if keys are specified. Messages of types without traits have no option.
+func WithTraits(keys ...string) Option {
+	return func(o *options) error {
+		o.withTraits = true
+		o.traitKeys = append(o.traitKeys, keys...)
+		return nil
+	}
+}
+
+// Generate writes proto3 message definitions of CTI types of the registry.
+// Every CTI type is flattened into a single message using its merged schema, so inherited properties are included.
+// Nested objects, enums and oneof wrappers of anyOf are emitted as separate definitions named after
+// the enclosing message and property. Objects without properties that are described by patternProperties
+// or additionalProperties become maps. Values that cannot be expressed with protobuf types are mapped
+// to well-known types of google/protobuf/struct.proto.
+// Fields are numbered in the order of property names, so adding a property may renumber other fields.
+func Generate(w io.Writer, r *collector.MetadataRegistry, opts ...Option) error {
+	o := options{pkg: DefaultPackage}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return err
+		}
+	}
+
+	// Minor versions are backward compatible, so only the latest minor version of the type is emitted.
+	latest := make(map[string]string)
+	for id := range r.Types {
+		if o.filter != nil {
+			expr, err := cti.Parse(id)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", id, err)
+			}
+			ok, err := o.filter.Match(expr)
+			if err != nil {
+				return fmt.Errorf("match %s: %w", id, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		name, err := MessageName(id)
+		if err != nil {
+			return err
+		}
+		if other, ok := latest[name]; ok {
+			newer, err := isNewerMinor(id, other)
+			if err != nil {
+				return err
+			}
+			if !newer {
+				continue
+			}
+		}
+		latest[name] = id
+	}
+
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g := &generator{
+		options: o,
+		names:   make(map[string]struct{}),
+		imports: make(map[string]struct{}),
+	}
+	for _, name := range names {
+		g.names[name] = struct{}{}
+	}
+	for _, name := range names {
+		id := latest[name]
+		if err := g.addType(r, id, name); err != nil {
+			return fmt.Errorf("generate message of %s: %w", id, err)
+		}
+	}
+
+	return g.write(w)
+}
+
+type fieldKind int
+
+const (
+	kindScalar fieldKind = iota
+	kindMessage
+	kindRepeated
+	kindMap
+)
+
+// field is a type of the message field.
+type field struct {
+	typ  string
+	kind fieldKind
+}
+
+func (f field) String() string {
+	if f.kind == kindRepeated {
+		return "repeated " + f.typ
+	}
+	return f.typ
+}
+
+// element returns the field type that may be used as an item of repeated fields or a value of maps.
+func (g *generator) element(f field) field {
+	switch f.kind {
+	case kindRepeated:
+		return g.wellKnown(listType)
+	case kindMap:
+		return g.wellKnown(structType)
+	default:
+		return f
+	}
+}
+
+type generator struct {
+	options
+
+	defs       []string
+	names      map[string]struct{}
+	imports    map[string]struct{}
+	usedTraits bool
+
+	// definitions are JSON schema definitions of the current CTI type and its parents.
+	definitions map[string]any
+}
+
+func (g *generator) addType(r *collector.MetadataRegistry, id string, name string) error {
+	schema, err := merger.GetMergedCtiSchema(id, r)
+	if err != nil {
+		return fmt.Errorf("get merged schema: %w", err)
+	}
+	g.definitions, err = collectDefinitions(r, id)
+	if err != nil {
+		return err
+	}
+	if r.Types[id].Description != "" {
+		if _, ok := schema["description"]; !ok {
+			schema["description"] = r.Types[id].Description
+		}
+	}
+	option, err := g.traitsOption(r, id)
+	if err != nil {
+		return err
+	}
+	return g.addMessage(name, option, schema)
+}
+
+// traitsOption returns the TraitsOption statement of the message of the type
+// or an empty string if traits are not included or the type has no traits.
+func (g *generator) traitsOption(r *collector.MetadataRegistry, id string) (string, error) {
+	if !g.withTraits {
+		return "", nil
+	}
+	traits, err := r.GetMergedTraits(id)
+	if err != nil {
+		return "", fmt.Errorf("get merged traits: %w", err)
+	}
+	if len(g.traitKeys) != 0 {
+		selected := make(map[string]any, len(g.traitKeys))
+		for _, key := range g.traitKeys {
+			if val, ok := traits[key]; ok {
+				selected[key] = val
+			}
+		}
+		traits = selected
+	}
+	if len(traits) == 0 {
+		return "", nil
+	}
+	// Keys of maps are sorted by encoding/json, so the output is deterministic.
+	values, err := json.Marshal(traits)
+	if err != nil {
+		return "", fmt.Errorf("marshal traits: %w", err)
+	}
+	g.usedTraits = true
+	g.imports[descriptorProto] = struct{}{}
+	return fmt.Sprintf("option (%s) = %s;", TraitsOption, strconv.Quote(string(values))), nil
+}
+
+func (g *generator) addMessage(name string, option string, schema map[string]any) error {
+	properties, _ := schema["properties"].(map[string]any)
+	required := jsonschema.RequiredSet(schema)
+
+	props := make([]string, 0, len(properties))
+	for prop := range properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	// Reserve a slot so the message precedes definitions of its fields.
+	idx := len(g.defs)
+	g.defs = append(g.defs, "")
+
+	var sb strings.Builder
+	writeComment(&sb, "", schema)
+	fmt.Fprintf(&sb, "message %s {\n", name)
+	if option != "" {
+		fmt.Fprintf(&sb, "  %s\n", option)
+	}
+	fieldNames := make(map[string]struct{}, len(props))
+	for i, prop := range props {
+		propSchema, ok := properties[prop].(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid schema of property %s", prop)
+		}
+		f, err := g.fieldType(propSchema, name+pascalCase(prop))
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+		label := ""
+		if _, ok := required[prop]; !ok && f.kind == kindScalar {
+			label = "optional "
+		}
+		writeComment(&sb, "  ", propSchema)
+		fmt.Fprintf(&sb, "  %s%s %s = %d;\n", label, f, uniqueName(fieldName(prop), fieldNames), i+1)
+	}
+	sb.WriteString("}\n")
+	g.defs[idx] = sb.String()
+	return nil
+}
+
+//nolint:gocyclo // dispatch by schema type
+func (g *generator) fieldType(schema map[string]any, hint string) (field, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		defName := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := g.definitions[defName].(map[string]any)
+		if !ok {
+			return g.wellKnown(valueType), nil
+		}
+		return g.fieldType(def, pascalCase(defName))
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) != 0 {
+		return g.enumType(enum, hint, schema), nil
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && len(anyOf) != 0 {
+		return g.oneofType(anyOf, hint)
+	}
+
+	switch typ := jsonschema.SchemaType(schema); typ {
+	case "object":
+		if properties, ok := schema["properties"].(map[string]any); ok && len(properties) != 0 {
+			if _, ok := g.names[hint]; !ok {
+				g.names[hint] = struct{}{}
+				if err := g.addMessage(hint, "", schema); err != nil {
+					return field{}, err
+				}
+			}
+			// Otherwise the named definition is already emitted or is being emitted (recursive reference).
+			return field{typ: hint, kind: kindMessage}, nil
+		}
+		return g.mapType(schema, hint)
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return field{typ: g.wellKnown(valueType).typ, kind: kindRepeated}, nil
+		}
+		item, err := g.fieldType(items, hint+"Item")
+		if err != nil {
+			return field{}, err
+		}
+		return field{typ: g.element(item).typ, kind: kindRepeated}, nil
+	case "string":
+		return field{typ: "string"}, nil
+	case "integer":
+		return field{typ: "int64"}, nil
+	case "number":
+		return field{typ: "double"}, nil
+	case "boolean":
+		return field{typ: "bool"}, nil
+	default:
+		return g.wellKnown(valueType), nil
+	}
+}
+
+// mapType returns the map of the object without properties. Values of the map are described
+// by patternProperties and additionalProperties, which must have the same type.
+func (g *generator) mapType(schema map[string]any, hint string) (field, error) {
+	var valueSchemas []map[string]any
+	if patterns, ok := schema["patternProperties"].(map[string]any); ok {
+		keys := make([]string, 0, len(patterns))
+		for key := range patterns {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if s, ok := patterns[key].(map[string]any); ok {
+				valueSchemas = append(valueSchemas, s)
+			}
+		}
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		valueSchemas = append(valueSchemas, additional)
+	}
+	if len(valueSchemas) == 0 {
+		return g.wellKnown(structType), nil
+	}
+
+	var value field
+	for i, s := range valueSchemas {
+		f, err := g.fieldType(s, hint+"Value")
+		if err != nil {
+			return field{}, err
+		}
+		f = g.element(f)
+		if i != 0 && f.typ != value.typ {
+			return g.wellKnown(structType), nil
+		}
+		value = f
+	}
+	return field{typ: "map<string, " + value.typ + ">", kind: kindMap}, nil
+}
+
+func (g *generator) enumType(enum []any, hint string, schema map[string]any) field {
+	prefix := constantName(hint)
+	values := make([]string, 0, len(enum))
+	seen := map[string]struct{}{prefix + "_UNSPECIFIED": {}}
+	for _, v := range enum {
+		s, ok := v.(string)
+		if !ok || s == "" {
+			// Only string enums are emitted as protobuf enums, fall back to the scalar of the type.
+			return g.scalarOf(schema)
+		}
+		value := prefix + "_" + constantName(s)
+		if _, ok := seen[value]; ok {
+			// Values that differ only in characters not allowed in names cannot be distinguished.
+			return g.scalarOf(schema)
+		}
+		seen[value] = struct{}{}
+		values = append(values, value)
+	}
+	if _, ok := g.names[hint]; ok {
+		return field{typ: hint}
+	}
+	g.names[hint] = struct{}{}
+
+	var sb strings.Builder
+	writeComment(&sb, "", schema)
+	fmt.Fprintf(&sb, "enum %s {\n", hint)
+	fmt.Fprintf(&sb, "  %s_UNSPECIFIED = 0;\n", prefix)
+	for i, v := range values {
+		fmt.Fprintf(&sb, "  %s = %d;\n", v, i+1)
+	}
+	sb.WriteString("}\n")
+	g.defs = append(g.defs, sb.String())
+	return field{typ: hint}
+}
+
+// oneofType returns the message with a oneof of anyOf members. Nullable values with a single member
+// are represented by the member type.
+func (g *generator) oneofType(anyOf []any, hint string) (field, error) {
+	var members []map[string]any
+	for _, item := range anyOf {
+		member, ok := item.(map[string]any)
+		if !ok {
+			return field{}, fmt.Errorf("invalid anyOf member")
+		}
+		if jsonschema.SchemaType(member) == "null" {
+			continue
+		}
+		members = append(members, member)
+	}
+	if len(members) == 1 {
+		return g.fieldType(members[0], hint)
+	}
+	if _, ok := g.names[hint]; ok {
+		return field{typ: hint, kind: kindMessage}, nil
+	}
+	g.names[hint] = struct{}{}
+	idx := len(g.defs)
+	g.defs = append(g.defs, "")
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "message %s {\n", hint)
+	sb.WriteString("  oneof value {\n")
+	for i, member := range members {
+		f, err := g.fieldType(member, hint+"Option"+strconv.Itoa(i+1))
+		if err != nil {
+			return field{}, err
+		}
+		// Fields of oneof cannot be repeated or maps.
+		fmt.Fprintf(&sb, "    %s option_%d = %d;\n", g.element(f).typ, i+1, i+1)
+	}
+	sb.WriteString("  }\n}\n")
+	g.defs[idx] = sb.String()
+	return field{typ: hint, kind: kindMessage}, nil
+}
+
+func (g *generator) scalarOf(schema map[string]any) field {
+	switch jsonschema.SchemaType(schema) {
+	case "string":
+		return field{typ: "string"}
+	case "integer":
+		return field{typ: "int64"}
+	case "number":
+		return field{typ: "double"}
+	case "boolean":
+		return field{typ: "bool"}
+	default:
+		return g.wellKnown(valueType)
+	}
+}
+
+func (g *generator) wellKnown(typ string) field {
+	g.imports[structProto] = struct{}{}
+	return field{typ: typ, kind: kindMessage}
+}
+
+func (g *generator) write(w io.Writer) error {
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+
+	var sb strings.Builder
+	sb.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&sb, "package %s;\n", g.pkg)
+	if len(imports) != 0 {
+		sb.WriteString("\n")
+		for _, imp := range imports {
+			fmt.Fprintf(&sb, "import %q;\n", imp)
+		}
+	}
+	if g.usedTraits {
+		fmt.Fprintf(&sb, "\nextend google.protobuf.MessageOptions {\n  string %s = %d;\n}\n", TraitsOption, TraitsOptionNumber)
+	}
+	for _, def := range g.defs {
+		sb.WriteString("\n")
+		sb.WriteString(def)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// MessageName returns the protobuf message name of the CTI type.
+// The name is made of vendor, package, entity name and major version of every node of the CTI,
+// so minor versions of the same type share the name,
+// for example, cti.a.p.event.v1.0 becomes APEventV1.
+func MessageName(id string) (string, error) {
+	expr, err := cti.ParseIdentifier(id)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", id, err)
+	}
+	var sb strings.Builder
+	for node := expr.Head; node != nil; node = node.Child {
+		sb.WriteString(pascalCase(string(node.Vendor)))
+		sb.WriteString(pascalCase(string(node.Package)))
+		sb.WriteString(pascalCase(string(node.EntityName)))
+		if node.Version.Major.Valid {
+			fmt.Fprintf(&sb, "V%d", node.Version.Major.Value)
+		}
+	}
+	return sb.String(), nil
+}
+
+// isNewerMinor reports whether the CTI a has greater minor versions than the CTI b with the same message name.
+// Minor versions are compared node by node from the head, CTIs are compared if all minor versions are equal.
+func isNewerMinor(a, b string) (bool, error) {
+	exprA, err := cti.ParseIdentifier(a)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", a, err)
+	}
+	exprB, err := cti.ParseIdentifier(b)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", b, err)
+	}
+	for nodeA, nodeB := exprA.Head, exprB.Head; nodeA != nil && nodeB != nil; nodeA, nodeB = nodeA.Child, nodeB.Child {
+		if nodeA.Version.Minor.Value != nodeB.Version.Minor.Value {
+			return nodeA.Version.Minor.Value > nodeB.Version.Minor.Value, nil
+		}
+	}
+	return a > b, nil
+}
+
+func collectDefinitions(r *collector.MetadataRegistry, id string) (map[string]any, error) {
+	definitions := make(map[string]any)
+	for cur := id; ; {
+		entity, ok := r.Index[cur]
+		if !ok {
+			return nil, fmt.Errorf("failed to find cti %s", cur)
+		}
+		var schema map[string]any
+		if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+			return nil, fmt.Errorf("unmarshal schema of %s: %w", cur, err)
+		}
+		if defs, ok := schema["definitions"].(map[string]any); ok {
+			for name, def := range defs {
+				// Definitions of the child take precedence.
+				if _, ok := definitions[name]; !ok {
+					definitions[name] = def
+				}
+			}
+		}
+		parent := metadata.GetParentCti(cur)
+		if parent == cur {
+			return definitions, nil
+		}
+		cur = parent
+	}
+}
+
+func writeComment(sb *strings.Builder, indent string, schema map[string]any) {
+	description, ok := schema["description"].(string)
+	if !ok || description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(sb, "%s// %s\n", indent, line)
+	}
+}
+
+// fieldName replaces characters that are not allowed in protobuf field names with underscores.
+// Names that do not start with a letter are prefixed with "f".
+func fieldName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !isNameChar(c) {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || !isLetter(b[0]) {
+		return "f" + string(b)
+	}
+	return string(b)
+}
+
+func uniqueName(name string, used map[string]struct{}) string {
+	res := name
+	for i := 2; ; i++ {
+		if _, ok := used[res]; !ok {
+			used[res] = struct{}{}
+			return res
+		}
+		res = name + "_" + strconv.Itoa(i)
+	}
+}
+
+// constantName converts the name to the upper snake case used for enum values,
+// e.g. APEventV1Severity becomes APEVENT_V1_SEVERITY and date-time becomes DATE_TIME.
+func constantName(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			sb.WriteByte(c - ('a' - 'A'))
+		case c >= 'A' && c <= 'Z':
+			if i != 0 && ((s[i-1] >= 'a' && s[i-1] <= 'z') || (s[i-1] >= '0' && s[i-1] <= '9')) {
+				sb.WriteByte('_')
+			}
+			sb.WriteByte(c)
+		case c >= '0' && c <= '9':
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+func pascalCase(s string) string {
+	var sb strings.Builder
+	upper := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isNameChar(c) || c == '_' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func isIdent(s string) bool {
+	if s == "" || !isLetter(s[0]) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || isLetter(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,168 @@
+package protobuf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_Generate(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:         "cti.x.y.event.v1.0",
+		Description: "Base event.",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Event",
+			"definitions": {
+				"Event": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "string", "format": "uuid"},
+						"severity": {"type": "string", "enum": ["low", "high"]},
+						"count": {"type": "integer", "description": "Number of occurrences."}
+					},
+					"required": ["id"]
+				}
+			}
+		}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.event.v1.0~x.y.created.v1.0",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Created",
+			"definitions": {
+				"Created": {
+					"type": "object",
+					"properties": {
+						"payload": {
+							"anyOf": [
+								{"type": "object", "properties": {"name": {"type": "string"}}},
+								{"type": "array", "items": {"type": "string"}},
+								{"type": "string"}
+							]
+						},
+						"labels": {"type": "array", "items": {"type": "string"}},
+						"extra": {"type": "object"},
+						"sizes": {"type": "object", "patternProperties": {"^[a-z]+$": {"type": "number"}}},
+						"owner": {"$ref": "#/definitions/Owner"},
+						"parent": {"anyOf": [{"type": "null"}, {"$ref": "#/definitions/Owner"}]},
+						"matrix": {"type": "array", "items": {"type": "array", "items": {"type": "integer"}}}
+					},
+					"required": ["labels"]
+				},
+				"Owner": {
+					"type": "object",
+					"properties": {"name": {"type": "string"}, "manager": {"$ref": "#/definitions/Owner"}},
+					"required": ["name"]
+				}
+			}
+		}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.event.v1.1",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Event",
+			"definitions": {"Event": {"type": "object", "properties": {"id": {"type": "string"}}}}
+		}`),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, r, WithPackage("acme.events.v1"), WithFilter("cti.x.y.event.v1.0~*")))
+	require.Equal(t, `syntax = "proto3";
+
+package acme.events.v1;
+
+import "google/protobuf/struct.proto";
+
+message XYEventV1XYCreatedV1 {
+  // Number of occurrences.
+  optional int64 count = 1;
+  google.protobuf.Struct extra = 2;
+  string id = 3;
+  repeated string labels = 4;
+  repeated google.protobuf.ListValue matrix = 5;
+  Owner owner = 6;
+  Owner parent = 7;
+  XYEventV1XYCreatedV1Payload payload = 8;
+  optional XYEventV1XYCreatedV1Severity severity = 9;
+  map<string, double> sizes = 10;
+}
+
+message Owner {
+  Owner manager = 1;
+  string name = 2;
+}
+
+message XYEventV1XYCreatedV1Payload {
+  oneof value {
+    XYEventV1XYCreatedV1PayloadOption1 option_1 = 1;
+    google.protobuf.ListValue option_2 = 2;
+    string option_3 = 3;
+  }
+}
+
+message XYEventV1XYCreatedV1PayloadOption1 {
+  optional string name = 1;
+}
+
+enum XYEventV1XYCreatedV1Severity {
+  XYEVENT_V1_XYCREATED_V1_SEVERITY_UNSPECIFIED = 0;
+  XYEVENT_V1_XYCREATED_V1_SEVERITY_LOW = 1;
+  XYEVENT_V1_XYCREATED_V1_SEVERITY_HIGH = 2;
+}
+`, buf.String())
+
+	// Only the latest minor version of the type is emitted.
+	buf.Reset()
+	require.NoError(t, Generate(&buf, r))
+	require.Equal(t, 1, strings.Count(buf.String(), "message XYEventV1 {"))
+	require.Contains(t, buf.String(), "package cti;\n")
+	require.Contains(t, buf.String(), "message XYEventV1 {\n  optional string id = 1;\n}\n")
+
+	require.EqualError(t, Generate(&buf, r, WithPackage("acme..v1")), "invalid package name acme..v1")
+}
+
+func Test_GenerateTraits(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:          "cti.x.y.alert.v1.0",
+		Schema:       []byte(`{"$ref": "#/definitions/Alert", "definitions": {"Alert": {"type": "object", "properties": {"id": {"type": "string"}}}}}`),
+		TraitsSchema: []byte(`{"type": "object"}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.alert.v1.0~x.y.disk.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Disk", "definitions": {"Disk": {"type": "object", "properties": {"id": {"type": "string"}}}}}`),
+		Traits: []byte(`{"severity": "high", "category": "storage"}`),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, Generate(&buf, r, WithTraits("severity")))
+	require.Equal(t, `syntax = "proto3";
+
+package cti;
+
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.MessageOptions {
+  string traits = 50000;
+}
+
+message XYAlertV1 {
+  optional string id = 1;
+}
+
+message XYAlertV1XYDiskV1 {
+  option (traits) = "{\"severity\":\"high\"}";
+  optional string id = 1;
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, Generate(&buf, r))
+	require.NotContains(t, buf.String(), "traits")
+}
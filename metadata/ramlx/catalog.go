@@ -0,0 +1,105 @@
+package ramlx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnnotationPrefix is a prefix of the names of annotations declared by the bundled library.
+const AnnotationPrefix = "cti."
+
+const catalogFile = "spec_v1/cti.raml"
+
+// AnnotationType describes an annotation type declared by the bundled cti.raml library.
+type AnnotationType struct {
+	// Name is a full name of the annotation, e.g. cti.reference.
+	Name string `json:"name"`
+	// Types are alternatives of the expected value type, e.g. ["CTIWildcard", "CTIWildcard[]", "boolean"].
+	Types []string `json:"types"`
+	// AllowedTargets are RAML locations where the annotation may be applied. Empty means any location.
+	AllowedTargets []string `json:"allowed_targets,omitempty"`
+	// Default is a default value of the annotation, if any.
+	Default any `json:"default,omitempty"`
+	// Description is a description of the annotation.
+	Description string `json:"description,omitempty"`
+	// Deprecated reports whether the annotation description marks it as deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+var catalog = sync.OnceValues(loadCatalog)
+
+// Annotations returns annotation types declared by the bundled library in the order of declaration.
+// The catalog is read from the embedded RAML files, so it always matches the runtime version.
+func Annotations() ([]AnnotationType, error) {
+	annotations, err := catalog()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]AnnotationType, len(annotations))
+	copy(res, annotations)
+	return res, nil
+}
+
+// LookupAnnotation returns the annotation type by its full name, e.g. cti.reference.
+func LookupAnnotation(name string) (AnnotationType, bool) {
+	annotations, err := catalog()
+	if err != nil {
+		return AnnotationType{}, false
+	}
+	for _, a := range annotations {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return AnnotationType{}, false
+}
+
+func loadCatalog() ([]AnnotationType, error) {
+	data, err := RamlFiles.ReadFile(catalogFile)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", catalogFile, err)
+	}
+	var lib struct {
+		AnnotationTypes yaml.Node `yaml:"annotationTypes"`
+	}
+	if err := yaml.Unmarshal(data, &lib); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", catalogFile, err)
+	}
+
+	nodes := lib.AnnotationTypes.Content
+	annotations := make([]AnnotationType, 0, len(nodes)/2)
+	// Mapping node content is a flat list of key and value nodes.
+	for i := 0; i+1 < len(nodes); i += 2 {
+		var decl struct {
+			Type           string    `yaml:"type"`
+			Description    string    `yaml:"description"`
+			AllowedTargets yaml.Node `yaml:"allowedTargets"`
+			Default        any       `yaml:"default"`
+		}
+		if err := nodes[i+1].Decode(&decl); err != nil {
+			return nil, fmt.Errorf("decode annotation type %s: %w", nodes[i].Value, err)
+		}
+		a := AnnotationType{
+			Name:        AnnotationPrefix + nodes[i].Value,
+			Description: strings.TrimSpace(decl.Description),
+			Default:     decl.Default,
+			Deprecated:  strings.Contains(decl.Description, "Deprecated:"),
+		}
+		for _, t := range strings.Split(decl.Type, "|") {
+			a.Types = append(a.Types, strings.TrimSpace(t))
+		}
+		switch decl.AllowedTargets.Kind {
+		case yaml.ScalarNode:
+			a.AllowedTargets = []string{decl.AllowedTargets.Value}
+		case yaml.SequenceNode:
+			if err := decl.AllowedTargets.Decode(&a.AllowedTargets); err != nil {
+				return nil, fmt.Errorf("decode allowed targets of %s: %w", a.Name, err)
+			}
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, nil
+}
@@ -0,0 +1,80 @@
+package ramlx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Annotations(t *testing.T) {
+	annotations, err := Annotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(annotations) == 0 || annotations[0].Name != "cti.description" {
+		t.Fatalf("annotations are not in the order of declaration: %+v", annotations)
+	}
+
+	// The returned slice is a copy of the catalog.
+	annotations[0].Name = "changed"
+	again, err := Annotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].Name != "cti.description" {
+		t.Fatalf("catalog is changed through the returned slice: %s", again[0].Name)
+	}
+}
+
+func Test_LookupAnnotation(t *testing.T) {
+	testCases := []struct {
+		name string
+		want AnnotationType
+	}{
+		{
+			name: "cti.reference",
+			want: AnnotationType{
+				Name:           "cti.reference",
+				Types:          []string{"CTIWildcard", "CTIWildcard[]", "boolean"},
+				AllowedTargets: []string{"TypeDeclaration"},
+			},
+		},
+		{
+			name: "cti.final",
+			want: AnnotationType{
+				Name:           "cti.final",
+				Types:          []string{"boolean"},
+				AllowedTargets: []string{"TypeDeclaration"},
+				Default:        true,
+			},
+		},
+		{
+			name: "cti.id",
+			want: AnnotationType{
+				Name:           "cti.id",
+				Types:          []string{"boolean"},
+				AllowedTargets: []string{"TypeDeclaration"},
+				Deprecated:     true,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := LookupAnnotation(tc.name)
+			if !ok {
+				t.Fatalf("%s is not found", tc.name)
+			}
+			// Descriptions are long and checked only for presence.
+			if got.Description == "" {
+				t.Fatalf("%s has no description", tc.name)
+			}
+			got.Description = ""
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("unexpected annotation type:\nwant %+v\ngot  %+v", tc.want, got)
+			}
+		})
+	}
+
+	if _, ok := LookupAnnotation("cti.unknown"); ok {
+		t.Fatal("unknown annotation is found")
+	}
+}
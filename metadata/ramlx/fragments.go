@@ -0,0 +1,222 @@
+package ramlx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FragmentCache caches decoded YAML documents of RAML fragments keyed by SHA-256 digests of their contents,
+// so identical fragments, e.g. copies of the same library in the directories of several packages, are decoded once.
+// Documents are kept in memory and, if the cache has a directory (see WithFragmentCacheDir), stored on disk
+// to be reused by other processes. The on-disk store is best-effort: entries that cannot be read or written
+// are decoded from the content again.
+//
+// Cached documents are shared between callers, so they must not be modified. The cache is safe for concurrent use.
+type FragmentCache struct {
+	mu   sync.Mutex
+	docs map[string]*yaml.Node
+	dir  string
+}
+
+type FragmentCacheOption func(*FragmentCache)
+
+// WithFragmentCacheDir makes the cache store decoded documents in the directory.
+// The directory is created on the first write.
+func WithFragmentCacheDir(dir string) FragmentCacheOption {
+	return func(c *FragmentCache) {
+		c.dir = dir
+	}
+}
+
+func NewFragmentCache(opts ...FragmentCacheOption) *FragmentCache {
+	c := &FragmentCache{docs: make(map[string]*yaml.Node)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ReadFile reads the fragment file and returns its content and its decoded YAML document.
+func (c *FragmentCache) ReadFile(path string) ([]byte, *yaml.Node, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	doc, err := c.Decode(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return content, doc, nil
+}
+
+// Decode returns the decoded YAML document of the fragment content.
+// Contents that are not valid YAML are not cached.
+func (c *FragmentCache) Decode(content []byte) (*yaml.Node, error) {
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	doc, ok := c.docs[digest]
+	c.mu.Unlock()
+	if ok {
+		return doc, nil
+	}
+
+	if doc = c.load(digest); doc == nil {
+		doc = &yaml.Node{}
+		if err := yaml.Unmarshal(content, doc); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml: %w", err)
+		}
+		c.store(digest, doc)
+	}
+
+	c.mu.Lock()
+	// Another caller may have decoded the same content meanwhile, its document is kept to be shared.
+	if cached, ok := c.docs[digest]; ok {
+		doc = cached
+	} else {
+		c.docs[digest] = doc
+	}
+	c.mu.Unlock()
+	return doc, nil
+}
+
+// storedNode is a YAML node in the on-disk form. Nodes of a document are stored in a flat list
+// in the document order, so children and aliased anchors are referenced by their indexes.
+type storedNode struct {
+	Kind        yaml.Kind  `json:"kind"`
+	Style       yaml.Style `json:"style,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	Value       string     `json:"value,omitempty"`
+	Anchor      string     `json:"anchor,omitempty"`
+	Alias       *int       `json:"alias,omitempty"`
+	Content     []int      `json:"content,omitempty"`
+	HeadComment string     `json:"head_comment,omitempty"`
+	LineComment string     `json:"line_comment,omitempty"`
+	FootComment string     `json:"foot_comment,omitempty"`
+	Line        int        `json:"line,omitempty"`
+	Column      int        `json:"column,omitempty"`
+}
+
+func (c *FragmentCache) path(digest string) string {
+	return filepath.Join(c.dir, digest+".json")
+}
+
+// load returns the document stored on disk or nil if there is none.
+func (c *FragmentCache) load(digest string) *yaml.Node {
+	if c.dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.path(digest))
+	if err != nil {
+		return nil
+	}
+	var stored []storedNode
+	if err := json.Unmarshal(data, &stored); err != nil || len(stored) == 0 {
+		return nil
+	}
+
+	nodes := make([]yaml.Node, len(stored))
+	for i, s := range stored {
+		n := &nodes[i]
+		*n = yaml.Node{
+			Kind:        s.Kind,
+			Style:       s.Style,
+			Tag:         s.Tag,
+			Value:       s.Value,
+			Anchor:      s.Anchor,
+			HeadComment: s.HeadComment,
+			LineComment: s.LineComment,
+			FootComment: s.FootComment,
+			Line:        s.Line,
+			Column:      s.Column,
+		}
+		if s.Alias != nil {
+			if *s.Alias < 0 || *s.Alias >= len(nodes) {
+				return nil
+			}
+			n.Alias = &nodes[*s.Alias]
+		}
+		for _, child := range s.Content {
+			if child <= i || child >= len(nodes) {
+				return nil
+			}
+			n.Content = append(n.Content, &nodes[child])
+		}
+	}
+	return &nodes[0]
+}
+
+// store writes the document to disk. The file is renamed into place, so concurrent readers never see partial files.
+func (c *FragmentCache) store(digest string, doc *yaml.Node) {
+	if c.dir == "" {
+		return
+	}
+	index := make(map[*yaml.Node]int)
+	var order []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		index[n] = len(order)
+		order = append(order, n)
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	stored := make([]storedNode, len(order))
+	for i, n := range order {
+		s := storedNode{
+			Kind:        n.Kind,
+			Style:       n.Style,
+			Tag:         n.Tag,
+			Value:       n.Value,
+			Anchor:      n.Anchor,
+			HeadComment: n.HeadComment,
+			LineComment: n.LineComment,
+			FootComment: n.FootComment,
+			Line:        n.Line,
+			Column:      n.Column,
+		}
+		if n.Alias != nil {
+			alias, ok := index[n.Alias]
+			if !ok {
+				return
+			}
+			s.Alias = &alias
+		}
+		for _, child := range n.Content {
+			s.Content = append(s.Content, index[child])
+		}
+		stored[i] = s
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(c.dir, digest+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(digest))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,132 @@
+package ramlx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testFragment = `#%RAML 1.0 Library
+# Shared types.
+types:
+  Base: &base
+    type: object # inline
+    properties:
+      name: string
+  Other: *base
+`
+
+func Test_FragmentCacheDecode(t *testing.T) {
+	c := NewFragmentCache()
+	doc, err := c.Decode([]byte(testFragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := c.Decode([]byte(testFragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc != again {
+		t.Fatal("identical contents are decoded twice")
+	}
+
+	var want yaml.Node
+	if err := yaml.Unmarshal([]byte(testFragment), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&want, doc) {
+		t.Fatal("cached document differs from the decoded one")
+	}
+
+	if _, err := c.Decode([]byte("types: [")); err == nil {
+		t.Fatal("invalid YAML is decoded")
+	}
+	if len(c.docs) != 1 {
+		t.Fatalf("invalid YAML is cached: %d entries", len(c.docs))
+	}
+}
+
+func Test_FragmentCacheReadFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/lib.raml", "b/lib.raml"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(testFragment), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewFragmentCache()
+	content, a, err := c.ReadFile(filepath.Join(dir, "a/lib.raml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testFragment {
+		t.Fatalf("unexpected content: %q", content)
+	}
+	_, b, err := c.ReadFile(filepath.Join(dir, "b/lib.raml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Fatal("identical files are decoded twice")
+	}
+
+	if _, _, err := c.ReadFile(filepath.Join(dir, "missing.raml")); err == nil {
+		t.Fatal("missing file is read")
+	}
+}
+
+func Test_FragmentCacheDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fragments")
+	doc, err := NewFragmentCache(WithFragmentCacheDir(dir)).Decode([]byte(testFragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected one stored document, got %v", files)
+	}
+
+	// A new cache loads the document from disk instead of decoding the content.
+	c := NewFragmentCache(WithFragmentCacheDir(dir))
+	stored := c.load(filepath.Base(files[0][:len(files[0])-len(".json")]))
+	if stored == nil {
+		t.Fatal("stored document is not loaded")
+	}
+	if !reflect.DeepEqual(doc, stored) {
+		t.Fatal("stored document differs from the decoded one")
+	}
+	loaded, err := c.Decode([]byte(testFragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doc, loaded) {
+		t.Fatal("loaded document differs from the decoded one")
+	}
+	// Aliases point to the anchored nodes of the same document.
+	types := loaded.Content[0].Content[1]
+	if types.Content[3].Alias != types.Content[1] {
+		t.Fatal("alias does not point to the anchored node")
+	}
+
+	// Corrupted entries are decoded from the content again.
+	if err := os.WriteFile(files[0], []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := NewFragmentCache(WithFragmentCacheDir(dir)).Decode([]byte(testFragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doc, decoded) {
+		t.Fatal("document is not decoded again")
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/acronis/go-cti/metadata/ramlx
 
 go 1.22.6
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,128 @@
+package rewrite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+// diffContext is the number of unchanged lines around changes in hunks of the unified diff.
+const diffContext = 3
+
+// Diff returns the unified diff of the source files with the edits applied, e.g. to preview edits of Plan.
+// Files are not modified. Edits are expected to be single-line as returned by Plan.
+func Diff(baseDir string, fixes []validator.Fix) (string, error) {
+	byFile := make(map[string][]validator.Fix)
+	for _, fix := range fixes {
+		if fix.Range.Start.Line != fix.Range.End.Line {
+			return "", fmt.Errorf("multi-line edit of %s at line %d is not supported", fix.File, fix.Range.Start.Line+1)
+		}
+		byFile[fix.File] = append(byFile[fix.File], fix)
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var sb strings.Builder
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(baseDir, file))
+		if err != nil {
+			return "", fmt.Errorf("read file %s: %w", file, err)
+		}
+		oldLines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		newLines, err := applyLineFixes(oldLines, byFile[file])
+		if err != nil {
+			return "", fmt.Errorf("apply edits to %s: %w", file, err)
+		}
+		writeFileDiff(&sb, file, oldLines, newLines)
+	}
+	return sb.String(), nil
+}
+
+// applyLineFixes returns copies of the lines with the single-line edits applied.
+func applyLineFixes(lines []string, fixes []validator.Fix) ([]string, error) {
+	sorted := make([]validator.Fix, len(fixes))
+	copy(sorted, fixes)
+	// Edits are applied from the end of the line, so offsets of preceding edits remain valid.
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Range.Start, sorted[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character > b.Character
+	})
+
+	res := make([]string, len(lines))
+	copy(res, lines)
+	for _, fix := range sorted {
+		r := fix.Range
+		if r.Start.Line < 0 || r.Start.Line >= len(res) {
+			return nil, fmt.Errorf("line %d is out of range", r.Start.Line+1)
+		}
+		line := res[r.Start.Line]
+		if r.Start.Character < 0 || r.Start.Character > r.End.Character || r.End.Character > len(line) {
+			return nil, fmt.Errorf("characters %d-%d of line %d are out of range",
+				r.Start.Character, r.End.Character, r.Start.Line+1)
+		}
+		res[r.Start.Line] = line[:r.Start.Character] + fix.Replacement + line[r.End.Character:]
+	}
+	return res, nil
+}
+
+// writeFileDiff writes the unified diff of lines of the file. Both versions have the same number of lines.
+func writeFileDiff(sb *strings.Builder, file string, oldLines, newLines []string) {
+	var changed []int
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "--- a/%s\n+++ b/%s\n", file, file)
+
+	for i := 0; i < len(changed); {
+		// Changes separated by no more than two contexts are merged into a single hunk.
+		j := i
+		for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*diffContext {
+			j++
+		}
+		start := changed[i] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := changed[j] + diffContext + 1
+		if end > len(oldLines) {
+			end = len(oldLines)
+		}
+
+		fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		for k := start; k < end; {
+			if oldLines[k] == newLines[k] {
+				sb.WriteString(" " + oldLines[k] + "\n")
+				k++
+				continue
+			}
+			// Consecutive changed lines are written as a block of removals followed by a block of additions.
+			l := k
+			for l < end && oldLines[l] != newLines[l] {
+				l++
+			}
+			for m := k; m < l; m++ {
+				sb.WriteString("-" + oldLines[m] + "\n")
+			}
+			for m := k; m < l; m++ {
+				sb.WriteString("+" + newLines[m] + "\n")
+			}
+			k = l
+		}
+		i = j + 1
+	}
+}
@@ -0,0 +1,67 @@
+package rewrite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Diff(t *testing.T) {
+	baseDir := t.TempDir()
+	content := `#%RAML 1.0 Library
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0
+    type: object
+  Created:
+    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
+    type: Event
+  A:
+    type: object
+  B:
+    type: object
+  C:
+    type: object
+  Topic:
+    (cti.reference): cti.x.y.event.v1.0
+`
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "entities.raml"), []byte(content), 0600))
+
+	fixes, err := Plan(baseDir, []string{"entities.raml"}, Mapping{"cti.x.y.event.v1.0": "cti.x.y.event.v2.0"})
+	require.NoError(t, err)
+	diff, err := Diff(baseDir, fixes)
+	require.NoError(t, err)
+	require.Equal(t, `--- a/entities.raml
++++ b/entities.raml
+@@ -1,10 +1,10 @@
+ #%RAML 1.0 Library
+ types:
+   Event:
+-    (cti.cti): cti.x.y.event.v1.0
++    (cti.cti): cti.x.y.event.v2.0
+     type: object
+   Created:
+-    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
++    (cti.cti): cti.x.y.event.v2.0~x.y.created.v1.0
+     type: Event
+   A:
+     type: object
+@@ -13,4 +13,4 @@
+   C:
+     type: object
+   Topic:
+-    (cti.reference): cti.x.y.event.v1.0
++    (cti.reference): cti.x.y.event.v2.0
+`, diff)
+
+	// The source file is not modified.
+	data, err := os.ReadFile(filepath.Join(baseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Equal(t, content, string(data))
+
+	diff, err = Diff(baseDir, nil)
+	require.NoError(t, err)
+	require.Empty(t, diff)
+}
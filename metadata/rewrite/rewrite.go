@@ -0,0 +1,110 @@
+// Package rewrite replaces CTIs in RAML source files of a package.
+// Occurrences are located with the YAML syntax tree of the source files (see collector.SourceFile) rather than with text search,
+// so only complete scalar values are replaced, while formatting and comments are preserved.
+package rewrite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/validator"
+)
+
+// Mapping maps old CTIs to new CTIs.
+// A mapping of a type also applies to CTIs of its descendants and instances,
+// as well as to attribute selectors and queries of the type,
+// e.g. a mapping of cti.a.p.event.v1.0 applies to cti.a.p.event.v1.0~a.p.created.v1.0.
+type Mapping map[string]string
+
+// SourceFiles returns paths of the source files that define the entities of the registry
+// according to their source maps. Files outside of the package directory are skipped.
+// Paths are relative to the package directory and sorted.
+func SourceFiles(r *collector.MetadataRegistry) []string {
+	seen := make(map[string]struct{})
+	var files []string
+	for _, entity := range r.Index {
+		f := entity.SourceMap.OriginalPath
+		if f == "" || strings.HasPrefix(f, "../") {
+			continue
+		}
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Plan returns edits that replace CTIs of the mapping in the source files.
+// The files are paths relative to the package directory.
+func Plan(baseDir string, files []string, mapping Mapping) ([]validator.Fix, error) {
+	keys := make([]string, 0, len(mapping))
+	for old := range mapping {
+		keys = append(keys, old)
+	}
+	// Longer CTIs are more specific and take precedence.
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+
+	var fixes []validator.Fix
+	for _, file := range files {
+		fileFixes, err := planFile(baseDir, file, keys, mapping)
+		if err != nil {
+			return nil, fmt.Errorf("plan %s: %w", file, err)
+		}
+		fixes = append(fixes, fileFixes...)
+	}
+	return fixes, nil
+}
+
+// Rewrite replaces CTIs of the mapping in the source files and returns the applied edits.
+func Rewrite(baseDir string, files []string, mapping Mapping) ([]validator.Fix, error) {
+	fixes, err := Plan(baseDir, files, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.ApplyFixes(baseDir, fixes); err != nil {
+		return nil, fmt.Errorf("apply edits: %w", err)
+	}
+	return fixes, nil
+}
+
+func planFile(baseDir string, file string, keys []string, mapping Mapping) ([]validator.Fix, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, file))
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	f, err := collector.ParseSourceFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scalars are listed in the document order, so the fixes are sorted.
+	var fixes []validator.Fix
+	for _, scalar := range f.Scalars() {
+		if replacement, ok := rewriteValue(scalar.Value, keys, mapping); ok {
+			fixes = append(fixes, validator.NewFix(file, data, scalar.Location, replacement))
+		}
+	}
+	return fixes, nil
+}
+
+// rewriteValue returns the value with the CTI replaced according to the mapping.
+func rewriteValue(value string, keys []string, mapping Mapping) (string, bool) {
+	for _, old := range keys {
+		if value == old {
+			return mapping[old], true
+		}
+		if strings.HasPrefix(value, old) && strings.ContainsRune("~@[", rune(value[len(old)])) {
+			return mapping[old] + value[len(old):], true
+		}
+	}
+	return "", false
+}
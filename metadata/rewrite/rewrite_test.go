@@ -0,0 +1,77 @@
+package rewrite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_Rewrite(t *testing.T) {
+	baseDir := t.TempDir()
+	content := `#%RAML 1.0 Library
+# Event types: cti.x.y.event.v1.0 is mentioned in a comment.
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v1.0 # Base event.
+    properties:
+      topic:
+        type: cti.CTI
+        (cti.reference): [ "cti.x.y.topic.v1.0", 'cti.x.y.topic.v1.0~x.y.first.v1.0' ]
+  Created:
+    (cti.cti): cti.x.y.event.v1.0~x.y.created.v1.0
+    type: Event
+  Other:
+    (cti.cti): cti.x.y.event.v1.01
+    description: |
+      cti.x.y.event.v1.0
+`
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "entities.raml"), []byte(content), 0600))
+
+	mapping := Mapping{
+		"cti.x.y.event.v1.0": "cti.x.y.event.v2.0",
+		"cti.x.y.topic.v1.0": "cti.x.y.channel.v1.0",
+		// More specific mapping takes precedence.
+		"cti.x.y.topic.v1.0~x.y.first.v1.0": "cti.x.y.channel.v1.0~x.y.main.v1.0",
+	}
+	fixes, err := Rewrite(baseDir, []string{"entities.raml"}, mapping)
+	require.NoError(t, err)
+	require.Len(t, fixes, 4)
+
+	data, err := os.ReadFile(filepath.Join(baseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Equal(t, `#%RAML 1.0 Library
+# Event types: cti.x.y.event.v1.0 is mentioned in a comment.
+types:
+  Event:
+    (cti.cti): cti.x.y.event.v2.0 # Base event.
+    properties:
+      topic:
+        type: cti.CTI
+        (cti.reference): [ "cti.x.y.channel.v1.0", 'cti.x.y.channel.v1.0~x.y.main.v1.0' ]
+  Created:
+    (cti.cti): cti.x.y.event.v2.0~x.y.created.v1.0
+    type: Event
+  Other:
+    (cti.cti): cti.x.y.event.v1.01
+    description: |
+      cti.x.y.event.v1.0
+`, string(data))
+}
+
+func Test_SourceFiles(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.event.v1.0", Schema: []byte(`{}`), SourceMap: metadata.SourceMap{OriginalPath: "types/events.raml"}},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Schema: []byte(`{}`), SourceMap: metadata.SourceMap{OriginalPath: "types/events.raml"}},
+		{Cti: "cti.x.y.topic.v1.0", Schema: []byte(`{}`), SourceMap: metadata.SourceMap{OriginalPath: "entities.raml"}},
+		{Cti: "cti.a.b.base.v1.0", Schema: []byte(`{}`), SourceMap: metadata.SourceMap{OriginalPath: "../a.b/entities.raml"}},
+	} {
+		require.NoError(t, r.Add(e.SourceMap.OriginalPath, e))
+	}
+	require.Equal(t, []string{"entities.raml", "types/events.raml"}, SourceFiles(r))
+}
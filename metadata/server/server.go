@@ -0,0 +1,192 @@
+// Package server provides a read-only REST API over a registry of CTI entities.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+const (
+	KindType     = "type"
+	KindInstance = "instance"
+
+	// maxPayloadSize limits the size of a payload that is validated against a type.
+	maxPayloadSize = 10 << 20 // 10 MB
+)
+
+// EntitySummary is an item of the entity list.
+type EntitySummary struct {
+	Cti  string `json:"cti"`
+	Kind string `json:"kind"`
+	// Value is a value of the attribute selected by the query.
+	Value any `json:"value,omitempty"`
+}
+
+// ValidationResult is a result of validation of a payload against a type.
+type ValidationResult struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves a read-only REST API over the registry:
+//
+//	GET  /entities?query=<query>      lists entities matching the query (see collector.MetadataRegistry.Query) or all entities
+//	GET  /entities/{cti}              returns the entity
+//	GET  /entities/{cti}/schema       returns the merged schema of the type
+//	POST /entities/{cti}/validate     validates the JSON payload against the merged schema of the type
+//	GET  /resolve?ref=<reference>     returns the latest version of the entity satisfying the reference
+//
+// The registry must not be modified while the handler is in use.
+type Handler struct {
+	registry *collector.MetadataRegistry
+	schemas  *merger.SchemaCache
+	mux      *http.ServeMux
+}
+
+// NewHandler makes a handler of the registry API.
+func NewHandler(r *collector.MetadataRegistry) *Handler {
+	h := &Handler{
+		registry: r,
+		schemas:  merger.NewSchemaCache(r),
+		mux:      http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET /entities", h.listEntities)
+	h.mux.HandleFunc("GET /entities/{cti}", h.getEntity)
+	h.mux.HandleFunc("GET /entities/{cti}/schema", h.getSchema)
+	h.mux.HandleFunc("POST /entities/{cti}/validate", h.validate)
+	h.mux.HandleFunc("GET /resolve", h.resolve)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+func (h *Handler) listEntities(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query().Get("query")
+	if query == "" {
+		query = "cti.*"
+	}
+	matches, err := h.registry.Query(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res := make([]EntitySummary, 0, len(matches))
+	for _, match := range matches {
+		res = append(res, EntitySummary{Cti: match.Entity.Cti, Kind: h.kind(match.Entity.Cti), Value: match.Value})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) getEntity(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("cti")
+	entity, ok := h.registry.Index[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("cti entity not found: "+id))
+		return
+	}
+	writeJSON(w, http.StatusOK, entity)
+}
+
+func (h *Handler) getSchema(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("cti")
+	if _, ok := h.registry.Types[id]; !ok {
+		writeError(w, http.StatusNotFound, errors.New("cti type not found: "+id))
+		return
+	}
+	schema, err := h.schemas.GetMergedCtiSchema(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema)
+}
+
+func (h *Handler) validate(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("cti")
+	if _, ok := h.registry.Types[id]; !ok {
+		writeError(w, http.StatusNotFound, errors.New("cti type not found: "+id))
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(payload) > maxPayloadSize {
+		writeError(w, http.StatusRequestEntityTooLarge, errors.New("payload is too large"))
+		return
+	}
+	if !json.Valid(payload) {
+		writeError(w, http.StatusBadRequest, errors.New("payload is not a valid JSON"))
+		return
+	}
+
+	schema, err := h.schemas.GetMergedCtiSchema(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema), gojsonschema.NewBytesLoader(payload))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := ValidationResult{Valid: res.Valid()}
+	for _, resErr := range res.Errors() {
+		result.Errors = append(result.Errors, resErr.String())
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) resolve(w http.ResponseWriter, req *http.Request) {
+	ref := req.URL.Query().Get("ref")
+	if ref == "" {
+		writeError(w, http.StatusBadRequest, errors.New("ref parameter is required"))
+		return
+	}
+	if _, err := cti.ParseReference(ref); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entity, err := h.registry.LatestVersion(ref)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entity)
+}
+
+func (h *Handler) kind(id string) string {
+	if _, ok := h.registry.Instances[id]; ok {
+		return KindInstance
+	}
+	return KindType
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write response", slog.Any("error", err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata/ctipackage"
+	"github.com/acronis/go-cti/metadata/ctitest"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	dir := (&ctitest.Package{
+		ID: "a.p",
+		Types: []ctitest.Type{
+			{
+				Cti:         "cti.a.p.topic.v1.0",
+				Annotations: map[string]any{"cti.final": false},
+				Properties: []ctitest.Property{
+					{Name: "id", Type: "cti.CTI", Annotations: map[string]any{"cti.id": true}},
+					{Name: "name"},
+				},
+			},
+			{
+				Cti:         "cti.a.p.topic.v1.1",
+				Annotations: map[string]any{"cti.final": false},
+				Properties: []ctitest.Property{
+					{Name: "id", Type: "cti.CTI", Annotations: map[string]any{"cti.id": true}},
+					{Name: "name"},
+					{Name: "description", Optional: true},
+				},
+			},
+		},
+		Instances: []ctitest.Instance{
+			{Type: "cti.a.p.topic.v1.0", Values: map[string]any{"id": "cti.a.p.topic.v1.0~a.p.users.v1.0", "name": "Users"}},
+		},
+	}).TempDir(t)
+
+	pkg, err := ctipackage.New(dir)
+	require.NoError(t, err)
+	require.NoError(t, pkg.Read())
+	require.NoError(t, pkg.Parse())
+
+	srv := httptest.NewServer(NewHandler(pkg.GlobalRegistry))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func doRequest(t *testing.T, method, target, body string, res any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, target, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(res))
+	return resp.StatusCode
+}
+
+func Test_Handler(t *testing.T) {
+	srv := newTestServer(t)
+
+	var list []EntitySummary
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, srv.URL+"/entities", "", &list))
+	require.Equal(t, []EntitySummary{
+		{Cti: "cti.a.p.topic.v1.0", Kind: KindType},
+		{Cti: "cti.a.p.topic.v1.0~a.p.users.v1.0", Kind: KindInstance},
+		{Cti: "cti.a.p.topic.v1.1", Kind: KindType},
+	}, list)
+
+	list = nil
+	query := url.QueryEscape(`cti.a.p.topic.v1.0~*@name`)
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, srv.URL+"/entities?query="+query, "", &list))
+	require.Equal(t, []EntitySummary{{Cti: "cti.a.p.topic.v1.0~a.p.users.v1.0", Kind: KindInstance, Value: "Users"}}, list)
+
+	var entity map[string]any
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, srv.URL+"/entities/cti.a.p.topic.v1.0~a.p.users.v1.0", "", &entity))
+	require.Equal(t, "cti.a.p.topic.v1.0~a.p.users.v1.0", entity["cti"])
+
+	var schema map[string]any
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, srv.URL+"/entities/cti.a.p.topic.v1.0/schema", "", &schema))
+	require.Contains(t, schema["properties"], "name")
+
+	var result ValidationResult
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodPost, srv.URL+"/entities/cti.a.p.topic.v1.0/validate",
+		`{"id": "cti.a.p.topic.v1.0~a.p.groups.v1.0", "name": "Groups"}`, &result))
+	require.True(t, result.Valid)
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodPost, srv.URL+"/entities/cti.a.p.topic.v1.0/validate",
+		`{"id": "cti.a.p.topic.v1.0~a.p.groups.v1.0"}`, &result))
+	require.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+
+	entity = nil
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, srv.URL+"/resolve?ref=cti.a.p.topic.v1", "", &entity))
+	require.Equal(t, "cti.a.p.topic.v1.1", entity["cti"])
+}
+
+func Test_Handler_Errors(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/entities?query=invalid", status: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/entities/cti.a.p.unknown.v1.0", status: http.StatusNotFound},
+		{method: http.MethodGet, path: "/entities/cti.a.p.topic.v1.0~a.p.users.v1.0/schema", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/entities/cti.a.p.topic.v1.0/validate", body: "{", status: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/resolve", status: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/resolve?ref=invalid", status: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/resolve?ref=cti.a.p.topic.v2", status: http.StatusNotFound},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			var res errorResponse
+			require.Equal(t, tc.status, doRequest(t, tc.method, srv.URL+tc.path, tc.body, &res))
+			require.NotEmpty(t, res.Error)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Package sqlstorage persists CTI entities of a registry in a SQL database, so long-lived services
+// may reload the registry without reading and parsing packages at startup.
+//
+// The package uses only database/sql; the driver (e.g. Postgres or SQLite) is registered by the caller.
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// DefaultTable is a name of the table with entities by default.
+const DefaultTable = "cti_entities"
+
+const (
+	kindType     = "type"
+	kindInstance = "instance"
+)
+
+// Dialect defines the SQL syntax that differs between databases.
+type Dialect int
+
+const (
+	// DialectPostgres uses numbered placeholders ($1, $2, ...).
+	DialectPostgres Dialect = iota
+	// DialectSQLite uses question mark placeholders.
+	DialectSQLite
+)
+
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+type options struct {
+	table string
+}
+
+type Option func(*options) error
+
+// WithTable sets a name of the table with entities. The name may be qualified by a schema, e.g. cti.entities.
+func WithTable(name string) Option {
+	return func(o *options) error {
+		for _, part := range strings.Split(name, ".") {
+			if !isIdent(part) {
+				return fmt.Errorf("invalid table name %s", name)
+			}
+		}
+		o.table = name
+		return nil
+	}
+}
+
+// Store saves entities of a registry to the table and loads them back.
+// Every entity is stored as a row with its serialized form, so schemas, traits, annotations and source maps are preserved.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+}
+
+// New makes a store of entities in the database.
+func New(db *sql.DB, dialect Dialect, opts ...Option) (*Store, error) {
+	o := options{table: DefaultTable}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{db: db, dialect: dialect, table: o.table}, nil
+}
+
+// Init creates the table of entities if it does not exist.
+func (s *Store) Init(ctx context.Context) error {
+	query := `CREATE TABLE IF NOT EXISTS ` + s.table + ` (
+	cti TEXT PRIMARY KEY,
+	kind TEXT NOT NULL,
+	original_path TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	data TEXT NOT NULL
+)`
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Save replaces entities stored in the table with entities of the registry in a single transaction.
+func (s *Store) Save(ctx context.Context, r *collector.MetadataRegistry) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM `+s.table); err != nil {
+		return fmt.Errorf("delete entities: %w", err)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (cti, kind, original_path, position, data) VALUES (%s, %s, %s, %s, %s)`, s.table,
+		s.dialect.placeholder(1), s.dialect.placeholder(2), s.dialect.placeholder(3), s.dialect.placeholder(4), s.dialect.placeholder(5))
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	paths := make([]string, 0, len(r.FragmentEntities))
+	for path := range r.FragmentEntities {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Entities are stored with the position in the fragment to restore the order of the registry on load.
+	position := 0
+	for _, path := range paths {
+		for _, entity := range r.FragmentEntities[path] {
+			data, err := json.Marshal(entity)
+			if err != nil {
+				return fmt.Errorf("marshal %s: %w", entity.Cti, err)
+			}
+			kind := kindType
+			if _, ok := r.Instances[entity.Cti]; ok {
+				kind = kindInstance
+			}
+			if _, err = stmt.ExecContext(ctx, entity.Cti, kind, path, position, string(data)); err != nil {
+				return fmt.Errorf("insert %s: %w", entity.Cti, err)
+			}
+			position++
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Load reads entities stored in the table into a new registry.
+func (s *Store) Load(ctx context.Context) (*collector.MetadataRegistry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT original_path, data FROM `+s.table+` ORDER BY position`)
+	if err != nil {
+		return nil, fmt.Errorf("select entities: %w", err)
+	}
+	defer rows.Close()
+
+	r := collector.NewMetadataRegistry()
+	for rows.Next() {
+		var path, data string
+		if err := rows.Scan(&path, &data); err != nil {
+			return nil, fmt.Errorf("scan entity: %w", err)
+		}
+		var entity metadata.Entity
+		if err := json.Unmarshal([]byte(data), &entity); err != nil {
+			return nil, fmt.Errorf("unmarshal entity: %w", err)
+		}
+		if err := r.Add(path, &entity); err != nil {
+			return nil, fmt.Errorf("add cti entity: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read entities: %w", err)
+	}
+	return r, nil
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// fakeDriver is a minimal in-memory database that supports only statements issued by Store.
+type fakeDriver struct {
+	mu      sync.Mutex
+	rows    [][]driver.Value
+	queries []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries = append(s.d.queries, s.query)
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+	case strings.HasPrefix(s.query, "DELETE FROM"):
+		s.d.rows = nil
+	case strings.HasPrefix(s.query, "INSERT INTO"):
+		for _, row := range s.d.rows {
+			if row[0] == args[0] {
+				return nil, fmt.Errorf("duplicate key %v", args[0])
+			}
+		}
+		s.d.rows = append(s.d.rows, args)
+	default:
+		return nil, fmt.Errorf("unexpected statement %s", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries = append(s.d.queries, s.query)
+	if !strings.HasPrefix(s.query, "SELECT original_path, data FROM") {
+		return nil, fmt.Errorf("unexpected query %s", s.query)
+	}
+	rows := append([][]driver.Value(nil), s.d.rows...)
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i][3].(int64) < rows[j][3].(int64) })
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	idx  int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"original_path", "data"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.idx == len(r.rows) {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.rows[r.idx][2], r.rows[r.idx][4]
+	r.idx++
+	return nil
+}
+
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDriver) {
+	t.Helper()
+
+	d := &fakeDriver{}
+	name := "fake-" + t.Name()
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, d
+}
+
+func makeTestRegistry(t *testing.T) *collector.MetadataRegistry {
+	t.Helper()
+
+	r := collector.NewMetadataRegistry()
+	deprecated := true
+	for _, e := range []struct {
+		path   string
+		entity *metadata.Entity
+	}{
+		{path: "b.raml", entity: &metadata.Entity{
+			Cti:         "cti.x.y.event.v1.0",
+			Schema:      json.RawMessage(`{"type":"object"}`),
+			Traits:      json.RawMessage(`{"topic":"users"}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".": {Deprecated: &deprecated}},
+			SourceMap:   metadata.SourceMap{OriginalPath: "b.raml"},
+		}},
+		{path: "b.raml", entity: &metadata.Entity{
+			Cti:    "cti.x.y.event.v1.0~x.y.created.v1.0",
+			Values: json.RawMessage(`{"name":"created"}`),
+		}},
+		{path: "a.raml", entity: &metadata.Entity{
+			Cti:    "cti.x.y.topic.v1.0",
+			Schema: json.RawMessage(`{"type":"string"}`),
+		}},
+	} {
+		require.NoError(t, r.Add(e.path, e.entity))
+	}
+	return r
+}
+
+func Test_Store(t *testing.T) {
+	ctx := context.Background()
+	db, d := openFakeDB(t)
+
+	r := makeTestRegistry(t)
+
+	store, err := New(db, DialectPostgres, WithTable("cti.entities"))
+	require.NoError(t, err)
+	require.NoError(t, store.Init(ctx))
+	require.NoError(t, store.Save(ctx, r))
+	// Saving again replaces stored entities.
+	require.NoError(t, store.Save(ctx, r))
+	require.Contains(t, d.queries, "INSERT INTO cti.entities (cti, kind, original_path, position, data) VALUES ($1, $2, $3, $4, $5)")
+	require.Len(t, d.rows, 3)
+	require.Equal(t, []driver.Value{"cti.x.y.topic.v1.0", "type", "a.raml", int64(0)}, d.rows[0][:4])
+	require.Equal(t, "instance", d.rows[2][1])
+
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, r.Index, loaded.Index)
+	require.Equal(t, r.FragmentEntities, loaded.FragmentEntities)
+	require.Len(t, loaded.Types, 2)
+	require.Len(t, loaded.Instances, 1)
+}
+
+func Test_StoreSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cti.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	r := makeTestRegistry(t)
+	store, err := New(db, DialectSQLite)
+	require.NoError(t, err)
+	require.NoError(t, store.Init(ctx))
+	// The table is created only once.
+	require.NoError(t, store.Init(ctx))
+	require.NoError(t, store.Save(ctx, r))
+	require.NoError(t, store.Save(ctx, r))
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+DefaultTable+` WHERE kind = 'instance'`).Scan(&count))
+	require.Equal(t, 1, count)
+
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, r.Index, loaded.Index)
+	require.Equal(t, r.FragmentEntities, loaded.FragmentEntities)
+
+	// Failed saves are rolled back and keep stored entities.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, store.Save(cancelled, collector.NewMetadataRegistry()), context.Canceled)
+	loaded, err = store.Load(ctx)
+	require.NoError(t, err)
+	require.Len(t, loaded.Index, 3)
+}
+
+func Test_New(t *testing.T) {
+	db, d := openFakeDB(t)
+
+	store, err := New(db, DialectSQLite)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(context.Background(), collector.NewMetadataRegistry()))
+	require.Equal(t, []string{"DELETE FROM " + DefaultTable}, d.queries)
+
+	_, err = New(db, DialectSQLite, WithTable("entities; DROP TABLE users"))
+	require.EqualError(t, err, "invalid table name entities; DROP TABLE users")
+}
@@ -0,0 +1,203 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+// Formats of the exported tables.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// CtiColumn is a header of the first column that holds CTIs of instances.
+const CtiColumn = "cti"
+
+type options struct {
+	format string
+}
+
+type Option func(*options) error
+
+// WithFormat sets the format of the table, FormatCSV by default.
+func WithFormat(format string) Option {
+	return func(o *options) error {
+		switch format {
+		case FormatCSV, FormatXLSX:
+			o.format = format
+			return nil
+		default:
+			return fmt.Errorf("unsupported format %s", format)
+		}
+	}
+}
+
+// Table is a table of values of instances of a CTI type.
+type Table struct {
+	// Columns are headers of the columns. The first column holds CTIs of instances.
+	Columns []string
+	// Rows are cells of the instances sorted by CTI.
+	Rows [][]Cell
+}
+
+// Cell is a value of the table cell: a string, a json.Number, a bool or nil for missing values.
+// Objects and arrays are encoded to JSON strings.
+type Cell = any
+
+// MakeTable flattens values of instances of the CTI type into the table. Columns are derived from the merged schema
+// of the type: nested objects are flattened into columns with dotted headers (e.g. owner.name) sorted by name,
+// while arrays, unions, recursive objects and objects without properties are held by single columns as JSON.
+// Only instances derived directly from the type are included.
+func MakeTable(r *collector.MetadataRegistry, typeCti string) (*Table, error) {
+	if _, ok := r.Types[typeCti]; !ok {
+		return nil, fmt.Errorf("cti type %s not found", typeCti)
+	}
+	schema, err := merger.GetMergedCtiSchema(typeCti, r)
+	if err != nil {
+		return nil, fmt.Errorf("get merged schema: %w", err)
+	}
+	var columns [][]string
+	collectColumns(schema, nil, &columns)
+
+	t := &Table{Columns: make([]string, 0, len(columns)+1)}
+	t.Columns = append(t.Columns, CtiColumn)
+	for _, path := range columns {
+		t.Columns = append(t.Columns, strings.Join(path, "."))
+	}
+
+	ids := make([]string, 0)
+	for id := range r.Instances {
+		if metadata.GetParentCti(id) == typeCti {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		var values any
+		// Numbers are kept as is, so large integers do not lose precision.
+		dec := json.NewDecoder(bytes.NewReader(r.Instances[id].Values))
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("unmarshal values of %s: %w", id, err)
+		}
+		row := make([]Cell, 0, len(t.Columns))
+		row = append(row, id)
+		for _, path := range columns {
+			cell, err := makeCell(lookup(values, path))
+			if err != nil {
+				return nil, fmt.Errorf("make cell %s of %s: %w", strings.Join(path, "."), id, err)
+			}
+			row = append(row, cell)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// Export writes values of instances of the CTI type as a table (see MakeTable) in the format of the options.
+func Export(w io.Writer, r *collector.MetadataRegistry, typeCti string, opts ...Option) error {
+	o := options{format: FormatCSV}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return err
+		}
+	}
+	t, err := MakeTable(r, typeCti)
+	if err != nil {
+		return err
+	}
+	if o.format == FormatXLSX {
+		return t.WriteXLSX(w)
+	}
+	return t.WriteCSV(w)
+}
+
+// WriteCSV writes the table as CSV with the header row. Missing values are written as empty strings.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("write row: %w", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// collectColumns appends paths of the columns of the schema to the list.
+func collectColumns(schema map[string]any, path []string, columns *[][]string) {
+	properties, _ := schema["properties"].(map[string]any)
+	_, isRef := schema["$ref"]
+	if len(properties) == 0 || isRef {
+		if path != nil {
+			*columns = append(*columns, path)
+		}
+		return
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := properties[name].(map[string]any)
+		// Paths are copied, since they are shared by columns of sibling properties.
+		propPath := append(append(make([]string, 0, len(path)+1), path...), name)
+		collectColumns(prop, propPath, columns)
+	}
+}
+
+// lookup returns the value at the path or nil if it is missing.
+func lookup(values any, path []string) any {
+	for _, key := range path {
+		m, ok := values.(map[string]any)
+		if !ok {
+			return nil
+		}
+		values = m[key]
+	}
+	return values
+}
+
+func makeCell(val any) (Cell, error) {
+	switch val.(type) {
+	case map[string]any, []any:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	default:
+		return val, nil
+	}
+}
+
+func formatCell(cell Cell) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,83 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func testRegistry(t *testing.T) *collector.MetadataRegistry {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.a.p.event.v1.0", Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {
+			"type": "object", "properties": {"name": {"type": "string"}}}}}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.alert.v1.0", Schema: []byte(`{"$ref": "#/definitions/Alert", "definitions": {"Alert": {
+			"type": "object", "properties": {
+				"severity": {"type": "integer"},
+				"owner": {"type": "object", "properties": {"name": {"type": "string"}, "active": {"type": "boolean"}}},
+				"tags": {"type": "array", "items": {"type": "string"}}}}}}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.alert.v1.0~a.p.disk.v1.0", Values: []byte(
+			`{"name": "Disk, full", "severity": 12345678901234567890, "owner": {"name": "<ops>", "active": true}, "tags": ["a", "b"]}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.alert.v1.0~a.p.cpu.v1.0", Values: []byte(`{"name": "CPU"}`)},
+		{Cti: "cti.a.p.event.v1.0~a.p.other.v1.0", Values: []byte(`{"name": "Other"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	return r
+}
+
+func Test_ExportCSV(t *testing.T) {
+	r := testRegistry(t)
+	var buf bytes.Buffer
+	require.NoError(t, Export(&buf, r, "cti.a.p.event.v1.0~a.p.alert.v1.0"))
+	require.Equal(t, `cti,name,owner.active,owner.name,severity,tags
+cti.a.p.event.v1.0~a.p.alert.v1.0~a.p.cpu.v1.0,CPU,,,,
+cti.a.p.event.v1.0~a.p.alert.v1.0~a.p.disk.v1.0,"Disk, full",true,<ops>,12345678901234567890,"[""a"",""b""]"
+`, buf.String())
+
+	require.ErrorContains(t, Export(&buf, r, "cti.a.p.unknown.v1.0"), "cti type cti.a.p.unknown.v1.0 not found")
+	require.ErrorContains(t, Export(&buf, r, "cti.a.p.event.v1.0", WithFormat("pdf")), "unsupported format pdf")
+}
+
+func Test_ExportXLSX(t *testing.T) {
+	r := testRegistry(t)
+	var buf bytes.Buffer
+	require.NoError(t, Export(&buf, r, "cti.a.p.event.v1.0~a.p.alert.v1.0", WithFormat(FormatXLSX)))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var names []string
+	var sheet []byte
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		sheet, err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+	require.Contains(t, names, "[Content_Types].xml")
+	require.Contains(t, string(sheet), `<c r="F1" t="inlineStr"><is><t xml:space="preserve">tags</t></is></c>`)
+	require.Contains(t, string(sheet), `<c r="C3" t="b"><v>1</v></c>`)
+	require.Contains(t, string(sheet), `<c r="D3" t="inlineStr"><is><t xml:space="preserve">&lt;ops&gt;</t></is></c>`)
+	require.Contains(t, string(sheet), `<c r="E3"><v>12345678901234567890</v></c>`)
+	// Missing values are not written.
+	require.NotContains(t, string(sheet), `r="C2"`)
+}
+
+func Test_ColumnName(t *testing.T) {
+	require.Equal(t, "A", columnName(0))
+	require.Equal(t, "Z", columnName(25))
+	require.Equal(t, "AA", columnName(26))
+	require.Equal(t, "AZ", columnName(51))
+	require.Equal(t, "BA", columnName(52))
+}
@@ -0,0 +1,113 @@
+package tabular
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sheetName is a name of the single worksheet of exported workbooks.
+const sheetName = "Instances"
+
+// xlsxParts are static parts of the workbook. The worksheet is written separately.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + sheetName + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// WriteXLSX writes the table as an Office Open XML workbook with a single worksheet.
+// The first row holds headers. Strings are written as inline strings, numbers and booleans keep their types
+// and missing values are written as empty cells.
+func (t *Table) WriteXLSX(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("write %s: %w", part.name, err)
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return fmt.Errorf("create worksheet: %w", err)
+	}
+	if _, err := io.WriteString(f, t.worksheet()); err != nil {
+		return fmt.Errorf("write worksheet: %w", err)
+	}
+	return zw.Close()
+}
+
+func (t *Table) worksheet() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	header := make([]Cell, len(t.Columns))
+	for i, column := range t.Columns {
+		header[i] = column
+	}
+	writeRow(&sb, 1, header)
+	for i, row := range t.Rows {
+		writeRow(&sb, i+2, row)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+func writeRow(sb *strings.Builder, index int, cells []Cell) {
+	fmt.Fprintf(sb, `<row r="%d">`, index)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(index)
+		switch v := cell.(type) {
+		case nil:
+			continue
+		case json.Number:
+			fmt.Fprintf(sb, `<c r="%s"><v>%s</v></c>`, ref, v)
+		case bool:
+			val := 0
+			if v {
+				val = 1
+			}
+			fmt.Fprintf(sb, `<c r="%s" t="b"><v>%d</v></c>`, ref, val)
+		default:
+			fmt.Fprintf(sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			_ = xml.EscapeText(sb, []byte(formatCell(v)))
+			sb.WriteString(`</t></is></c>`)
+		}
+	}
+	sb.WriteString(`</row>`)
+}
+
+// columnName returns the name of the zero-based column, e.g. A for 0 and AA for 26.
+func columnName(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
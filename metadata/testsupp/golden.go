@@ -0,0 +1,49 @@
+package testsupp
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files instead of comparing with them")
+
+// AssertMergedSchemaGolden compares merged schemas of the types with the golden file, e.g. to catch unintended
+// changes of types inherited from dependencies. The registry is used to resolve parents of the types,
+// so it is usually the global registry of the package, while the types are the types of its local registry:
+//
+//	testsupp.AssertMergedSchemaGolden(t, pkg.GlobalRegistry, pkg.LocalRegistry.Types, "testdata/schemas.golden.json")
+//
+// The golden file is a JSON object that maps CTIs of the types to their merged schemas.
+// Run tests with the -update flag to write the golden file instead of comparing with it.
+func AssertMergedSchemaGolden(t *testing.T, r *collector.MetadataRegistry, types metadata.EntitiesMap, goldenPath string) {
+	t.Helper()
+
+	schemas := make(map[string]any, len(types))
+	for id := range types {
+		schema, err := merger.GetMergedCtiSchema(id, r)
+		require.NoError(t, err, "get merged schema of %s", id)
+		schemas[id] = schema
+	}
+	// Keys of maps are sorted by encoding/json, so the golden file is deterministic.
+	actual, err := json.MarshalIndent(schemas, "", "  ")
+	require.NoError(t, err)
+	actual = append(actual, '\n')
+
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0755))
+		require.NoError(t, os.WriteFile(goldenPath, actual, 0600))
+		return
+	}
+	expected, err := os.ReadFile(goldenPath)
+	require.NoError(t, err, "read golden file, run tests with -update to create it")
+	require.JSONEq(t, string(expected), string(actual), "merged schemas differ from %s, run tests with -update to accept the changes", goldenPath)
+}
@@ -0,0 +1,38 @@
+package testsupp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_AssertMergedSchemaGolden(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.event.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {"id": {"type": "string"}}}}}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.event.v1.0~x.y.created.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {"type": "object", "properties": {"name": {"type": "string"}}}}}`),
+	}))
+	types := metadata.EntitiesMap{"cti.x.y.event.v1.0~x.y.created.v1.0": r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"]}
+	goldenPath := filepath.Join(t.TempDir(), "golden", "schemas.json")
+
+	*updateGolden = true
+	AssertMergedSchemaGolden(t, r, types, goldenPath)
+	*updateGolden = false
+
+	golden, err := os.ReadFile(goldenPath)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"cti.x.y.event.v1.0~x.y.created.v1.0": {
+		"type": "object",
+		"properties": {"id": {"type": "string"}, "name": {"type": "string"}}
+	}}`, string(golden))
+	AssertMergedSchemaGolden(t, r, types, goldenPath)
+}
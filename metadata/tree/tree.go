@@ -0,0 +1,259 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// Node is a CTI type in the inheritance tree.
+type Node struct {
+	Entity    *metadata.Entity
+	Instances int
+	Children  []*Node
+}
+
+type options struct {
+	filter    *cti.Expression
+	tags      []string
+	packageID string
+}
+
+type Option func(*options) error
+
+// WithFilter keeps only types that match the CTI expression and their ancestors.
+func WithFilter(expr string) Option {
+	return func(o *options) error {
+		if expr == "" {
+			return nil
+		}
+		e, err := cti.Parse(expr)
+		if err != nil {
+			return fmt.Errorf("parse filter: %w", err)
+		}
+		o.filter = &e
+		return nil
+	}
+}
+
+// WithTags keeps only types that have any of the specified tags and their ancestors.
+func WithTags(tags ...string) Option {
+	return func(o *options) error {
+		o.tags = append(o.tags, tags...)
+		return nil
+	}
+}
+
+// WithPackage keeps only types that are defined by the package and their ancestors.
+// A type is defined by the package if the last chunk of its CTI starts with the package ID (<vendor>.<package>).
+func WithPackage(id string) Option {
+	return func(o *options) error {
+		o.packageID = id
+		return nil
+	}
+}
+
+// Build makes the inheritance tree of CTI types from the registry.
+// Returned roots and their children are sorted by CTI.
+func Build(r *collector.MetadataRegistry, opts ...Option) ([]*Node, error) {
+	var o options
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	nodes := make(map[string]*Node, len(r.Types))
+	for id, entity := range r.Types {
+		nodes[id] = &Node{Entity: entity}
+	}
+	for id := range r.Instances {
+		if node, ok := nodes[metadata.GetParentCti(id)]; ok {
+			node.Instances++
+		}
+	}
+
+	keep := make(map[string]struct{}, len(nodes))
+	for id, node := range nodes {
+		ok, err := o.match(node.Entity)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		// Keep ancestors to preserve the path from the root.
+		for {
+			keep[id] = struct{}{}
+			parentID := metadata.GetParentCti(id)
+			if parentID == id {
+				break
+			}
+			if _, ok := nodes[parentID]; !ok {
+				break
+			}
+			id = parentID
+		}
+	}
+
+	var roots []*Node
+	for id := range keep {
+		node := nodes[id]
+		parentID := metadata.GetParentCti(id)
+		if parent, ok := nodes[parentID]; ok && parentID != id {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	sortNodes(roots)
+	return roots, nil
+}
+
+func (o *options) match(entity *metadata.Entity) (bool, error) {
+	if o.packageID != "" && !definedInPackage(entity.Cti, o.packageID) {
+		return false, nil
+	}
+	if len(o.tags) != 0 {
+		tagged := false
+		for _, tag := range o.tags {
+			if entity.HasTag(tag) {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false, nil
+		}
+	}
+	if o.filter == nil {
+		return true, nil
+	}
+	expr, err := cti.Parse(entity.Cti)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", entity.Cti, err)
+	}
+	ok, err := o.filter.Match(expr)
+	if err != nil {
+		return false, fmt.Errorf("match %s: %w", entity.Cti, err)
+	}
+	return ok, nil
+}
+
+func definedInPackage(id string, packageID string) bool {
+	chunk := strings.TrimPrefix(id, "cti.")
+	if pos := strings.LastIndexByte(chunk, cti.InheritanceSeparator); pos != -1 {
+		chunk = chunk[pos+1:]
+	}
+	return strings.HasPrefix(chunk, packageID+".")
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Entity.Cti < nodes[j].Entity.Cti
+	})
+	for _, node := range nodes {
+		sortNodes(node.Children)
+	}
+}
+
+// Render writes the tree as indented text.
+// Protected and private types are marked with [protected] and [private], final types are marked with [final],
+// instance counts are shown in parentheses.
+func Render(w io.Writer, roots []*Node) error {
+	for _, root := range roots {
+		if err := renderNode(w, root, "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderNode(w io.Writer, node *Node, prefix, childPrefix string) error {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteString(node.Entity.Cti)
+	for _, marker := range markers(node.Entity) {
+		sb.WriteString(" [" + marker + "]")
+	}
+	switch node.Instances {
+	case 0:
+	case 1:
+		sb.WriteString(" (1 instance)")
+	default:
+		sb.WriteString(fmt.Sprintf(" (%d instances)", node.Instances))
+	}
+	sb.WriteByte('\n')
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write node: %w", err)
+	}
+
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if err := renderNode(w, child, childPrefix+branch, childPrefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markers returns markers of the access modifier and finality of the type. Public access is not marked.
+func markers(entity *metadata.Entity) []string {
+	var res []string
+	switch entity.Access {
+	case metadata.AccessProtected, metadata.AccessPrivate:
+		res = append(res, entity.Access)
+	}
+	if entity.Final {
+		res = append(res, "final")
+	}
+	return res
+}
+
+// RenderDOT writes the tree as a Graphviz digraph with edges from parent to child types.
+// Final types are drawn bold, access and final markers (see Render) and instance counts are added to the node labels.
+func RenderDOT(w io.Writer, roots []*Node) error {
+	var sb strings.Builder
+	sb.WriteString("digraph cti {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=box];\n")
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, node := range nodes {
+			label := node.Entity.Cti
+			if m := markers(node.Entity); len(m) != 0 {
+				label += "\n[" + strings.Join(m, "] [") + "]"
+			}
+			switch node.Instances {
+			case 0:
+			case 1:
+				label += "\n(1 instance)"
+			default:
+				label += fmt.Sprintf("\n(%d instances)", node.Instances)
+			}
+			sb.WriteString(fmt.Sprintf("\t%q [label=%q", node.Entity.Cti, label))
+			if node.Entity.Final {
+				sb.WriteString(", style=bold")
+			}
+			sb.WriteString("];\n")
+			for _, child := range node.Children {
+				sb.WriteString(fmt.Sprintf("\t%q -> %q;\n", node.Entity.Cti, child.Entity.Cti))
+			}
+			walk(node.Children)
+		}
+	}
+	walk(roots)
+	sb.WriteString("}\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write graph: %w", err)
+	}
+	return nil
+}
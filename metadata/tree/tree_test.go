@@ -0,0 +1,119 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func makeTestRegistry(t *testing.T) *collector.MetadataRegistry {
+	t.Helper()
+
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.event.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Final: true, Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.deleted.v1.0", Access: metadata.AccessProtected, Tags: []string{"audit"}, Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.topic.v1.0", Access: metadata.AccessPublic, Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.topic.v1.0~x.y.internal.v1.0", Access: metadata.AccessPrivate, Final: true, Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.topic.v1.0~x.y.first.v1.0", Values: []byte(`{}`)},
+		{Cti: "cti.x.y.topic.v1.0~x.y.second.v1.0", Values: []byte(`{}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.deleted.v1.0~x.y.one.v1.0", Values: []byte(`{}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	return r
+}
+
+func Test_Render(t *testing.T) {
+	r := makeTestRegistry(t)
+
+	testCases := []struct {
+		name   string
+		filter string
+		tags   []string
+		want   string
+	}{
+		{
+			name: "full tree",
+			want: `
+cti.x.y.event.v1.0
+├── cti.x.y.event.v1.0~x.y.created.v1.0 [final]
+└── cti.x.y.event.v1.0~x.y.deleted.v1.0 [protected] (1 instance)
+cti.x.y.topic.v1.0 (2 instances)
+└── cti.x.y.topic.v1.0~x.y.internal.v1.0 [private] [final]
+`,
+		},
+		{
+			name:   "filtered tree keeps ancestors",
+			filter: "cti.x.y.event.v1.0~x.y.deleted.*",
+			want: `
+cti.x.y.event.v1.0
+└── cti.x.y.event.v1.0~x.y.deleted.v1.0 [protected] (1 instance)
+`,
+		},
+		{
+			name: "tagged tree keeps ancestors",
+			tags: []string{"audit", "unknown"},
+			want: `
+cti.x.y.event.v1.0
+└── cti.x.y.event.v1.0~x.y.deleted.v1.0 [protected] (1 instance)
+`,
+		},
+		{
+			name:   "filter and tags must both match",
+			filter: "cti.x.y.topic.*",
+			tags:   []string{"audit"},
+			want:   "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			roots, err := Build(r, WithFilter(tc.filter), WithTags(tc.tags...))
+			require.NoError(t, err)
+
+			var sb strings.Builder
+			require.NoError(t, Render(&sb, roots))
+			require.Equal(t, strings.TrimPrefix(tc.want, "\n"), sb.String())
+		})
+	}
+}
+
+func Test_BuildWithPackage(t *testing.T) {
+	r := makeTestRegistry(t)
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: "cti.x.y.event.v1.0~z.w.extended.v1.0", Schema: []byte(`{}`)}))
+
+	roots, err := Build(r, WithPackage("z.w"))
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, Render(&sb, roots))
+	require.Equal(t, `cti.x.y.event.v1.0
+└── cti.x.y.event.v1.0~z.w.extended.v1.0
+`, sb.String())
+}
+
+func Test_RenderDOT(t *testing.T) {
+	r := makeTestRegistry(t)
+
+	roots, err := Build(r, WithFilter("cti.x.y.event.v1.0"))
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, RenderDOT(&sb, roots))
+	require.Equal(t, `digraph cti {
+	rankdir=LR;
+	node [shape=box];
+	"cti.x.y.event.v1.0" [label="cti.x.y.event.v1.0"];
+	"cti.x.y.event.v1.0" -> "cti.x.y.event.v1.0~x.y.created.v1.0";
+	"cti.x.y.event.v1.0" -> "cti.x.y.event.v1.0~x.y.deleted.v1.0";
+	"cti.x.y.event.v1.0~x.y.created.v1.0" [label="cti.x.y.event.v1.0~x.y.created.v1.0\n[final]", style=bold];
+	"cti.x.y.event.v1.0~x.y.deleted.v1.0" [label="cti.x.y.event.v1.0~x.y.deleted.v1.0\n[protected]\n(1 instance)"];
+}
+`, sb.String())
+}
@@ -15,3 +15,15 @@ func GetBaseCti(cti string) string {
 	}
 	return cti
 }
+
+// IsDeclaredBy reports whether the entity with the CTI is declared by the package, i.e. whether the last named
+// segment of the CTI starts with the package ID. Anonymous instances are declared by the package of their type.
+func IsDeclaredBy(cti string, packageID string) bool {
+	segments := strings.Split(strings.TrimPrefix(cti, "cti."), "~")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if strings.Count(segments[i], ".") >= 2 {
+			return strings.HasPrefix(segments[i], packageID+".")
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+const (
+	CoexistenceRuleName = "major-version-coexistence"
+)
+
+// CoexistencePolicy configures checks of CTI types that exist in several major versions.
+// Checks are applied to types of older major versions against the type of the latest major version.
+type CoexistencePolicy struct {
+	// DistinctSchemas requires older major versions to have a schema different from the latest one.
+	DistinctSchemas bool `json:"distinct_schemas,omitempty"`
+	// RequireDeprecation requires older major versions to be annotated with cti.deprecated.
+	RequireDeprecation bool `json:"require_deprecation,omitempty"`
+	// RetireOlderMajors forbids instances of older major versions.
+	RetireOlderMajors bool `json:"retire_older_majors,omitempty"`
+}
+
+// NewCoexistenceRule makes a rule that checks coexistence of major versions of CTI types according to the policy.
+func NewCoexistenceRule(policy CoexistencePolicy) Rule {
+	return NewRuleFunc(CoexistenceRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			return checkCoexistence(policy, r, entity)
+		})
+}
+
+func checkCoexistence(policy CoexistencePolicy, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	if entity.Schema == nil {
+		return nil
+	}
+	key, major, ok := splitMajorVersion(entity.Cti)
+	if !ok {
+		return nil
+	}
+
+	idx := getMajorVersionIndex(r)
+	idx.mu.Lock()
+	idx.build(r)
+	latestID := idx.latest[key]
+	instances := idx.instances[entity.Cti]
+	idx.mu.Unlock()
+
+	latest, ok := r.Types[latestID]
+	if !ok {
+		return nil
+	}
+	if _, latestMajor, _ := splitMajorVersion(latestID); latestMajor <= major {
+		return nil
+	}
+
+	var issues []Issue
+	if policy.RequireDeprecation && !entity.Deprecated {
+		issues = append(issues, Issue{
+			Message: fmt.Sprintf("must be deprecated since newer major version %s exists", latest.Cti),
+		})
+	}
+	if policy.DistinctSchemas && equalSchemas(entity.Schema, latest.Schema) {
+		issues = append(issues, Issue{
+			Message: fmt.Sprintf("schema is identical to newer major version %s", latest.Cti),
+		})
+	}
+	if policy.RetireOlderMajors {
+		for _, id := range instances {
+			issues = append(issues, Issue{
+				Message: fmt.Sprintf("instance %s of retired major version is not allowed, use %s", id, latest.Cti),
+			})
+		}
+	}
+	return issues
+}
+
+// majorVersionIndex groups types of the registry by their CTI without major version, so every type is compared
+// with the latest major version of its group instead of all types of the registry. The index is built on the first
+// use and dropped when the registry changes.
+type majorVersionIndex struct {
+	mu sync.Mutex
+	// latest maps CTI without version of the last node to the CTI of the type of the latest major version.
+	latest map[string]string
+	// instances maps CTI of a type to sorted CTIs of its direct instances.
+	instances map[string][]string
+}
+
+// majorVersionIndexKey is the key of the index of the registry, see collector.MetadataRegistry.Derived.
+type majorVersionIndexKey struct{}
+
+func getMajorVersionIndex(r *collector.MetadataRegistry) *majorVersionIndex {
+	return r.Derived(majorVersionIndexKey{}, func() any {
+		idx := &majorVersionIndex{}
+		r.AddChangeHook(func(string) { idx.reset() })
+		r.AddCompactHook(idx.reset)
+		return idx
+	}).(*majorVersionIndex)
+}
+
+func (idx *majorVersionIndex) reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.latest, idx.instances = nil, nil
+}
+
+// build groups types and instances of the registry unless the index is built already. idx.mu must be held.
+func (idx *majorVersionIndex) build(r *collector.MetadataRegistry) {
+	if idx.latest != nil {
+		return
+	}
+	idx.latest = make(map[string]string)
+	latestMajors := make(map[string]uint)
+	for id := range r.Types {
+		key, major, ok := splitMajorVersion(id)
+		if !ok {
+			continue
+		}
+		latest, ok := idx.latest[key]
+		if !ok || major > latestMajors[key] || (major == latestMajors[key] && id > latest) {
+			idx.latest[key], latestMajors[key] = id, major
+		}
+	}
+
+	idx.instances = make(map[string][]string)
+	for id := range r.Instances {
+		parent := metadata.GetParentCti(id)
+		idx.instances[parent] = append(idx.instances[parent], id)
+	}
+	for _, ids := range idx.instances {
+		sort.Strings(ids)
+	}
+}
+
+// splitMajorVersion splits CTI into the part without version of the last node and its major version.
+func splitMajorVersion(id string) (string, uint, bool) {
+	expr, err := cti.ParseIdentifier(id)
+	if err != nil {
+		return "", 0, false
+	}
+	tail := expr.Tail()
+	if tail == nil || !tail.Version.Major.Valid {
+		return "", 0, false
+	}
+	idx := strings.LastIndex(id, ".v")
+	if idx == -1 {
+		return "", 0, false
+	}
+	return id[:idx], tail.Version.Major.Value, true
+}
+
+// equalSchemas compares root definitions of the schemas ignoring CTI annotations.
+func equalSchemas(a, b []byte) bool {
+	var av, bv map[string]any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(
+		jsonschema.StripCTIExtensions(jsonschema.ResolveRef(av, av)),
+		jsonschema.StripCTIExtensions(jsonschema.ResolveRef(bv, bv)))
+}
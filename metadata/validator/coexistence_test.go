@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_CoexistenceRule(t *testing.T) {
+	schema := func(name, cti string, props string) []byte {
+		return []byte(`{"$ref": "#/definitions/` + name + `", "definitions": {"` + name + `": {
+			"type": "object", "properties": {` + props + `}, "x-custom": {"x-domainExt-cti.cti": "` + cti + `"}}}}`)
+	}
+
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.event.v1.0", Schema: schema("EventV1", "cti.x.y.event.v1.0", `"a": {"type": "string"}`)},
+		{Cti: "cti.x.y.event.v2.0", Schema: schema("EventV2", "cti.x.y.event.v2.0", `"a": {"type": "string"}`)},
+		{Cti: "cti.x.y.event.v3.1", Schema: schema("EventV3", "cti.x.y.event.v3.1", `"b": {"type": "string"}`)},
+		{Cti: "cti.x.y.event.v2.0~x.y.created.v1.0", Values: []byte(`{}`)},
+		{Cti: "cti.x.y.topic.v1.0", Deprecated: true, Schema: schema("TopicV1", "cti.x.y.topic.v1.0", `"a": {"type": "string"}`)},
+		{Cti: "cti.x.y.topic.v2.0", Schema: schema("TopicV2", "cti.x.y.topic.v2.0", `"a": {"type": "string"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	rule := NewCoexistenceRule(CoexistencePolicy{DistinctSchemas: true, RequireDeprecation: true, RetireOlderMajors: true})
+	ctx := context.Background()
+
+	require.Equal(t, []Issue{
+		{Message: "must be deprecated since newer major version cti.x.y.event.v3.1 exists"},
+		{Message: "instance cti.x.y.event.v2.0~x.y.created.v1.0 of retired major version is not allowed, use cti.x.y.event.v3.1"},
+	}, rule.Validate(ctx, r, r.Index["cti.x.y.event.v2.0"]))
+
+	require.Equal(t, []Issue{
+		{Message: "must be deprecated since newer major version cti.x.y.event.v3.1 exists"},
+	}, rule.Validate(ctx, r, r.Index["cti.x.y.event.v1.0"]))
+
+	require.Empty(t, rule.Validate(ctx, r, r.Index["cti.x.y.event.v3.1"]))
+	// Schemas are compared ignoring names of definitions and CTI annotations.
+	require.Equal(t, []Issue{
+		{Message: "schema is identical to newer major version cti.x.y.topic.v2.0"},
+	}, rule.Validate(ctx, r, r.Index["cti.x.y.topic.v1.0"]))
+	require.Empty(t, rule.Validate(ctx, r, r.Index["cti.x.y.event.v2.0~x.y.created.v1.0"]))
+
+	// Types are grouped by major versions once, and the groups are rebuilt after the registry changes.
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.event.v4.0", Schema: schema("EventV4", "cti.x.y.event.v4.0", `"c": {"type": "string"}`)}))
+	require.Equal(t, []Issue{
+		{Message: "must be deprecated since newer major version cti.x.y.event.v4.0 exists"},
+	}, rule.Validate(ctx, r, r.Index["cti.x.y.event.v3.1"]))
+}
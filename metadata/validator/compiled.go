@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-cti/metadata/jsonschema"
+)
+
+// CompiledSchemaCache holds compiled JSON schemas keyed by hashes of their content, so identical schemas
+// (e.g. merged schemas of near-identical leaf types or traits schemas shared by descendants) are compiled once.
+// The cache is safe for concurrent use. Schemas are never evicted, since they are immutable by content,
+// so a long-running service should share a single cache per registry and drop it with the registry.
+type CompiledSchemaCache struct {
+	mu      sync.Mutex
+	schemas map[[sha256.Size]byte]*compiledSchema
+}
+
+type compiledSchema struct {
+	once   sync.Once
+	schema *gojsonschema.Schema
+	err    error
+}
+
+func NewCompiledSchemaCache() *CompiledSchemaCache {
+	return &CompiledSchemaCache{schemas: make(map[[sha256.Size]byte]*compiledSchema)}
+}
+
+// WithCompiledSchemaCache makes the validator share the cache of compiled schemas, e.g. between validators
+// of the same registry. Each validator has its own cache by default.
+func WithCompiledSchemaCache(c *CompiledSchemaCache) Option {
+	return func(v *MetadataValidator) error {
+		if c == nil {
+			return fmt.Errorf("compiled schema cache is nil")
+		}
+		v.compiled = c
+		return nil
+	}
+}
+
+// Compile returns the compiled schema. The schema is either a decoded JSON (e.g. a merged schema)
+// or JSON bytes. Schemas are compared by their canonical encoding, so formatting and order of keys do not matter.
+func (c *CompiledSchemaCache) Compile(schema any) (*gojsonschema.Schema, error) {
+	switch raw := schema.(type) {
+	case []byte:
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, fmt.Errorf("unmarshal schema: %w", err)
+		}
+	case json.RawMessage:
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, fmt.Errorf("unmarshal schema: %w", err)
+		}
+	}
+	// Keys of maps are sorted by encoding/json, so the encoding is canonical.
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("marshal schema: %w", err)
+	}
+	key := sha256.Sum256(data)
+
+	c.mu.Lock()
+	entry, ok := c.schemas[key]
+	if !ok {
+		entry = &compiledSchema{}
+		c.schemas[key] = entry
+	}
+	c.mu.Unlock()
+
+	// Schemas are compiled outside the lock, so different schemas are compiled concurrently.
+	entry.once.Do(func() {
+		entry.schema, entry.err = gojsonschema.NewSchema(gojsonschema.NewBytesLoader(data))
+	})
+	return entry.schema, entry.err
+}
+
+// Len returns the number of distinct schemas in the cache.
+func (c *CompiledSchemaCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.schemas)
+}
+
+// validateValues validates the document against the schema compiled with the cache.
+func (c *CompiledSchemaCache) validateValues(schema any, document gojsonschema.JSONLoader) (*gojsonschema.Result, error) {
+	compiled, err := c.Compile(schema)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Validate(document)
+}
+
+// compileMerged compiles the merged schema of a type with the cache of the validator.
+func (v *MetadataValidator) compileMerged(schema map[string]any) (*gojsonschema.Schema, error) {
+	if v.simplifyUnions {
+		schema = jsonschema.SimplifyUnions(schema)
+	}
+	return v.compiled.Compile(schema)
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_CompiledSchemaCache(t *testing.T) {
+	c := NewCompiledSchemaCache()
+	a, err := c.Compile([]byte(`{"type": "object", "required": ["id"]}`))
+	require.NoError(t, err)
+	b, err := c.Compile(json.RawMessage(`{"required":["id"],"type":"object"}`))
+	require.NoError(t, err)
+	goSchema, err := c.Compile(map[string]any{"type": "object", "required": []any{"id"}})
+	require.NoError(t, err)
+	require.Same(t, a, b)
+	require.Same(t, a, goSchema)
+	require.Equal(t, 1, c.Len())
+
+	res, err := a.Validate(gojsonschema.NewStringLoader(`{}`))
+	require.NoError(t, err)
+	require.False(t, res.Valid())
+
+	_, err = c.Compile([]byte(`{"type": 1}`))
+	require.Error(t, err)
+	// Compilation errors are cached as well.
+	_, err = c.Compile([]byte(`{"type": 1}`))
+	require.Error(t, err)
+	require.Equal(t, 2, c.Len())
+
+	_, err = c.Compile([]byte(`{`))
+	require.Error(t, err)
+}
+
+func Test_ValidatorSharesCompiledSchemas(t *testing.T) {
+	leaf := func(name string) []byte {
+		return []byte(`{"$ref": "#/definitions/` + name + `", "definitions": {"` + name + `": {
+			"type": "object", "properties": {"id": {"type": "integer"}}}}}`)
+	}
+	annotations := map[metadata.GJsonPath]metadata.Annotations{".id": {}}
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.event.v1.0", Schema: leaf("Event"), Annotations: annotations},
+		{Cti: "cti.x.y.alert.v1.0", Schema: leaf("Event"), Annotations: annotations},
+		{Cti: "cti.x.y.event.v1.0~x.y.a.v1.0", Values: []byte(`{"id": 1}`)},
+		{Cti: "cti.x.y.alert.v1.0~x.y.b.v1.0", Values: []byte(`{"id": 2}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	c := NewCompiledSchemaCache()
+	v, err := MakeMetadataValidator(r, WithCompiledSchemaCache(c))
+	require.NoError(t, err)
+	require.NoError(t, v.ValidateAll())
+	// Merged schemas of both types are identical.
+	require.Equal(t, 1, c.Len())
+
+	_, err = MakeMetadataValidator(r, WithCompiledSchemaCache(nil))
+	require.Error(t, err)
+}
+
+func Test_ValidatorSimplifiedUnions(t *testing.T) {
+	annotations := map[metadata.GJsonPath]metadata.Annotations{".id": {}}
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti: "cti.x.y.event.v1.0",
+			Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {
+				"id": {"anyOf": [{"type": "integer"}, {"anyOf": [{"type": "string"}, {"anyOf": [{"type": "integer"}]}]}]}}}}}`),
+			Annotations: annotations,
+		},
+		{Cti: "cti.x.y.event.v1.0~x.y.a.v1.0", Values: []byte(`{"id": 1}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.b.v1.0", Values: []byte(`{"id": "b"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	v, err := MakeMetadataValidator(r, WithSimplifiedUnions())
+	require.NoError(t, err)
+	require.NoError(t, v.ValidateAll())
+
+	schema, err := v.compileMerged(map[string]any{"anyOf": []any{map[string]any{"anyOf": []any{map[string]any{"type": "integer"}}}}})
+	require.NoError(t, err)
+	res, err := schema.Validate(gojsonschema.NewStringLoader(`true`))
+	require.NoError(t, err)
+	require.False(t, res.Valid())
+	// The simplified schema is compiled instead of the original one.
+	simplified, err := v.compiled.Compile(map[string]any{"type": "integer"})
+	require.NoError(t, err)
+	require.Same(t, simplified, schema)
+}
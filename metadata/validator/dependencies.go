@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	DependencyReferenceRuleName = "dependency-reference"
+)
+
+// NewDependencyReferenceRule makes a rule that resolves references of entities declared by the package
+// to entities of other packages against the dependencies pinned in the lockfile. The dependencies map
+// package IDs to their pinned versions. References are parents of entities, cti.reference and cti.schema
+// annotations of types and values of instances for properties annotated with cti.reference.
+// Queries and wildcard expressions are not resolved.
+//
+// A reference is reported if the target is declared by a package that is not a pinned dependency,
+// if it is missing in the pinned version of the dependency (e.g. it is added in a newer version),
+// or if it is not accessible to the package.
+func NewDependencyReferenceRule(packageID string, dependencies map[string]string) Rule {
+	p := cti.NewParser()
+	return NewRuleFunc(DependencyReferenceRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			if !metadata.IsDeclaredBy(entity.Cti, packageID) {
+				return nil
+			}
+			var issues []Issue
+			for _, ref := range entityReferences(r, entity) {
+				if _, err := p.ParseIdentifier(ref.target); err != nil || metadata.IsDeclaredBy(ref.target, packageID) {
+					continue
+				}
+				if msg := checkDependencyReference(r, entity, ref.target, dependencies); msg != "" {
+					issues = append(issues, Issue{Message: ref.source + ": " + msg})
+				}
+			}
+			return issues
+		})
+}
+
+type entityReference struct {
+	// source describes where the reference is found, e.g. a key of the instance values.
+	source string
+	target string
+}
+
+// entityReferences returns references of the entity to other entities in a deterministic order.
+func entityReferences(r *collector.MetadataRegistry, entity *metadata.Entity) []entityReference {
+	var refs []entityReference
+	if parent := metadata.GetParentCti(entity.Cti); parent != entity.Cti {
+		refs = append(refs, entityReference{source: "parent", target: parent})
+	}
+
+	if entity.Values == nil {
+		keys := make([]string, 0, len(entity.Annotations))
+		for key := range entity.Annotations {
+			keys = append(keys, string(key))
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			annotation := entity.Annotations[metadata.GJsonPath(key)]
+			for _, target := range annotationStrings(annotation.Reference) {
+				refs = append(refs, entityReference{source: key + " " + metadata.Reference, target: target})
+			}
+			for _, target := range annotationStrings(annotation.Schema) {
+				refs = append(refs, entityReference{source: key + " " + metadata.Schema, target: target})
+			}
+		}
+		return refs
+	}
+
+	parent, ok := r.Index[metadata.GetParentCti(entity.Cti)]
+	if !ok {
+		return refs
+	}
+	keys := make([]string, 0, len(parent.Annotations))
+	for key, annotation := range parent.Annotations {
+		if annotation.Reference != nil {
+			keys = append(keys, string(key))
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, val := range metadata.GJsonPath(key).GetValue(entity.Values).Array() {
+			if val.Str != "" {
+				refs = append(refs, entityReference{source: key, target: val.Str})
+			}
+		}
+	}
+	return refs
+}
+
+// annotationStrings returns CTIs of the annotation value, which is either a string or a list of strings.
+func annotationStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// checkDependencyReference returns the description of the problem with the reference or an empty string.
+func checkDependencyReference(
+	r *collector.MetadataRegistry, entity *metadata.Entity, target string, dependencies map[string]string,
+) string {
+	depID, version, ok := pinnedDependency(target, dependencies)
+	if !ok {
+		return fmt.Sprintf("%s is declared by a package that is not a dependency pinned in the lockfile", target)
+	}
+	pinned := fmt.Sprintf("%s@%s pinned in the lockfile", depID, version)
+	referenced, ok := r.Index[target]
+	if !ok {
+		if latest, ok := latestMinorVersionOf(r, target); ok {
+			return fmt.Sprintf("%s is not found in %s, which provides only %s", target, pinned, latest)
+		}
+		return fmt.Sprintf("%s is not found in %s", target, pinned)
+	}
+	if !collector.IsAccessibleFrom(referenced, entity.Cti) {
+		return fmt.Sprintf("%s of %s is %s", target, pinned, referenced.Access)
+	}
+	return ""
+}
+
+// pinnedDependency returns the ID and the version of the pinned dependency that declares the entity.
+func pinnedDependency(target string, dependencies map[string]string) (string, string, bool) {
+	for id, version := range dependencies {
+		if metadata.IsDeclaredBy(target, id) {
+			return id, version, true
+		}
+	}
+	return "", "", false
+}
+
+// latestMinorVersionOf returns the CTI of the type with the same major version as the target
+// and the latest minor version known to the registry, e.g. when the target requires a newer dependency.
+func latestMinorVersionOf(r *collector.MetadataRegistry, target string) (string, bool) {
+	idx := strings.LastIndexByte(target, '.')
+	if idx < 0 {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(target[idx+1:], 10, 32); err != nil {
+		return "", false
+	}
+	minor, ok := latestMinorVersion(r, target[:idx])
+	if !ok {
+		return "", false
+	}
+	return target[:idx] + "." + strconv.FormatUint(uint64(minor), 10), true
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_DependencyReferenceRule(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		// Entities of the dependency a.p pinned in the lockfile.
+		{
+			Cti:         "cti.a.p.event.v1.1",
+			Schema:      []byte(`{}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".topic": {Reference: "cti.a.p.topic.v1.0"}},
+		},
+		{Cti: "cti.a.p.topic.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.topic.v1.0~a.p.internal.v1.0", Values: []byte(`{}`), Access: metadata.AccessPrivate},
+		{Cti: "cti.a.p.topic.v1.0~a.p.public.v1.0", Values: []byte(`{}`)},
+		// Entities of the package x.y.
+		{
+			Cti:    "cti.a.p.event.v1.1~x.y.created.v1.0",
+			Schema: []byte(`{}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".channel": {Reference: []any{"cti.a.p.topic.v1.0", "cti.b.q.channel.v1.0"}},
+				".legacy":  {Schema: "cti.a.p.event.v1.3"},
+			},
+		},
+		{Cti: "cti.a.p.event.v1.1~x.y.created.v1.0~x.y.first.v1.0", Values: []byte(`{}`)},
+		{Cti: "cti.a.p.event.v1.1~x.y.e1.v1.0", Values: []byte(`{"topic": "cti.a.p.topic.v1.0~a.p.public.v1.0"}`)},
+		{Cti: "cti.a.p.event.v1.1~x.y.e2.v1.0", Values: []byte(`{"topic": "cti.a.p.topic.v1.0~a.p.internal.v1.0"}`)},
+		{Cti: "cti.a.p.event.v1.1~x.y.e3.v1.0", Values: []byte(`{"topic": "cti.a.p.topic.v1.0~a.p.added.v1.0"}`)},
+		{Cti: "cti.a.p.event.v1.1~x.y.e4.v1.0", Values: []byte(`{"topic": "cti.a.p.topic.v1.0~a.p.*"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	rule := NewDependencyReferenceRule("x.y", map[string]string{"a.p": "v1.1.0"})
+	messages := func(id string) []string {
+		var res []string
+		for _, issue := range rule.Validate(context.Background(), r, r.Index[id]) {
+			res = append(res, issue.Message)
+		}
+		return res
+	}
+
+	require.Equal(t, []string{
+		".channel cti.reference: cti.b.q.channel.v1.0 is declared by a package that is not a dependency pinned in the lockfile",
+		".legacy cti.schema: cti.a.p.event.v1.3 is not found in a.p@v1.1.0 pinned in the lockfile, which provides only cti.a.p.event.v1.1",
+	}, messages("cti.a.p.event.v1.1~x.y.created.v1.0"))
+	// References to entities of the package are resolved by the core validation.
+	require.Empty(t, messages("cti.a.p.event.v1.1~x.y.created.v1.0~x.y.first.v1.0"))
+	require.Empty(t, messages("cti.a.p.event.v1.1~x.y.e1.v1.0"))
+	require.Equal(t, []string{
+		".topic: cti.a.p.topic.v1.0~a.p.internal.v1.0 of a.p@v1.1.0 pinned in the lockfile is private",
+	}, messages("cti.a.p.event.v1.1~x.y.e2.v1.0"))
+	require.Equal(t, []string{
+		".topic: cti.a.p.topic.v1.0~a.p.added.v1.0 is not found in a.p@v1.1.0 pinned in the lockfile",
+	}, messages("cti.a.p.event.v1.1~x.y.e3.v1.0"))
+	require.Empty(t, messages("cti.a.p.event.v1.1~x.y.e4.v1.0"))
+	// Entities of dependencies are not checked.
+	require.Empty(t, messages("cti.a.p.event.v1.1"))
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	DeprecatedReferenceRuleName = "deprecated-reference"
+)
+
+// NewDeprecatedReferenceRule makes a rule that warns about entities that are not deprecated
+// but depend on deprecated entities (see collector.MetadataRegistry.GetDependencies)
+// or set values of properties annotated with cti.deprecated.
+func NewDeprecatedReferenceRule() Rule {
+	return NewRuleFunc(DeprecatedReferenceRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			return checkDeprecatedReferences(r, entity)
+		})
+}
+
+func checkDeprecatedReferences(r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	if entity.Deprecated {
+		return nil
+	}
+	deps, err := r.GetDependencies(entity.Cti)
+	if err != nil {
+		return []Issue{{Message: err.Error()}}
+	}
+
+	var issues []Issue
+	for _, id := range deps {
+		dep := r.Index[id]
+		if !dep.Deprecated {
+			continue
+		}
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Message:  "references deprecated " + id + deprecationDetails(dep.DeprecationMessage, dep.ReplacedBy),
+		})
+	}
+
+	parent, ok := r.Index[metadata.GetParentCti(entity.Cti)]
+	if !ok || parent.Cti == entity.Cti || entity.Values == nil {
+		return issues
+	}
+	keys := make([]string, 0, len(parent.Annotations))
+	for key, annotation := range parent.Annotations {
+		// Deprecation of the parent type itself is reported as a deprecated reference.
+		if key == "." {
+			continue
+		}
+		if annotation.Deprecated != nil && *annotation.Deprecated && key.GetValue(entity.Values).Exists() {
+			keys = append(keys, key.String())
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		annotation := parent.Annotations[metadata.GJsonPath(key)]
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%s: property is deprecated", key) + deprecationDetails(annotation.DeprecationMessage, annotation.ReplacedBy),
+		})
+	}
+	return issues
+}
+
+func deprecationDetails(message, replacedBy string) string {
+	var res string
+	if message != "" {
+		res += ": " + message
+	}
+	if replacedBy != "" {
+		res += fmt.Sprintf(", use %s instead", replacedBy)
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_DeprecatedReferenceRule(t *testing.T) {
+	deprecated := true
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti:                "cti.x.y.event.v1.0",
+			Deprecated:         true,
+			DeprecationMessage: "use notifications",
+			ReplacedBy:         "cti.x.y.notification.v1.0",
+			Schema:             []byte(`{"type": "object"}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".legacy": {Deprecated: &deprecated, ReplacedBy: "cti.x.y.notification.v1.0"},
+			},
+		},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Values: []byte(`{"legacy": "old"}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.deleted.v1.0", Values: []byte(`{}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.updated.v1.0", Deprecated: true, Schema: []byte(`{"type": "object"}`)},
+		{
+			Cti:    "cti.x.y.notification.v1.0",
+			Schema: []byte(`{"type": "object"}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".event": {Reference: "cti.x.y.event.v1.0"},
+			},
+		},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	rule := NewDeprecatedReferenceRule()
+	ctx := context.Background()
+
+	require.Equal(t, []Issue{
+		{
+			Severity: SeverityWarning,
+			Message:  "references deprecated cti.x.y.event.v1.0: use notifications, use cti.x.y.notification.v1.0 instead",
+		},
+		{Severity: SeverityWarning, Message: ".legacy: property is deprecated, use cti.x.y.notification.v1.0 instead"},
+	}, rule.Validate(ctx, r, r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"]))
+	require.Len(t, rule.Validate(ctx, r, r.Index["cti.x.y.event.v1.0~x.y.deleted.v1.0"]), 1)
+	require.Len(t, rule.Validate(ctx, r, r.Index["cti.x.y.notification.v1.0"]), 1)
+	require.Empty(t, rule.Validate(ctx, r, r.Index["cti.x.y.event.v1.0~x.y.updated.v1.0"]))
+
+	dependents, err := r.GetDependents("cti.x.y.event.v1.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"cti.x.y.event.v1.0~x.y.created.v1.0",
+		"cti.x.y.event.v1.0~x.y.deleted.v1.0",
+		"cti.x.y.event.v1.0~x.y.updated.v1.0",
+		"cti.x.y.notification.v1.0",
+	}, dependents)
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// WithRequiredPropertyDescriptions makes the validator reject public types whose merged schemas have properties
+// without descriptions, including properties of nested objects, array items, union members and pattern properties.
+// Descriptions come from the description facet of RAML properties. If the package is set (see WithPackage),
+// only types declared by the package are checked.
+func WithRequiredPropertyDescriptions() Option {
+	return func(v *MetadataValidator) error {
+		v.requirePropertyDescriptions = true
+		return nil
+	}
+}
+
+// validatePropertyDescriptions checks that all properties of the public type are documented,
+// see WithRequiredPropertyDescriptions.
+func (v *MetadataValidator) validatePropertyDescriptions(current *metadata.Entity) error {
+	if !v.requirePropertyDescriptions || current.Schema == nil || current.Values != nil {
+		return nil
+	}
+	if current.Access != "" && current.Access != metadata.AccessPublic {
+		return nil
+	}
+	if v.packageID != "" && !metadata.IsDeclaredBy(current.Cti, v.packageID) {
+		return nil
+	}
+	schema, err := v.schemas.GetMergedCtiSchema(current.Cti)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	collectUndocumentedProperties(schema, "", &missing)
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w: %s", current.Cti, ErrPropertyDescriptionMissing, strings.Join(missing, ", "))
+}
+
+// collectUndocumentedProperties appends paths of properties of the schema that do not have descriptions.
+// Paths are in the form of annotation keys, e.g. .items.#.name or ./^x-/.
+func collectUndocumentedProperties(schema map[string]any, path string, missing *[]string) {
+	for _, key := range []string{"properties", "patternProperties"} {
+		properties, _ := schema[key].(map[string]any)
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				continue
+			}
+			propertyPath := path + "." + name
+			if key == "patternProperties" {
+				propertyPath = path + "./" + name + "/"
+			}
+			if description, _ := property["description"].(string); strings.TrimSpace(description) == "" {
+				*missing = append(*missing, propertyPath)
+			}
+			collectUndocumentedProperties(property, propertyPath, missing)
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		collectUndocumentedProperties(items, path+".#", missing)
+	}
+	if members, ok := schema["anyOf"].([]any); ok {
+		for _, member := range members {
+			if member, ok := member.(map[string]any); ok {
+				collectUndocumentedProperties(member, path, missing)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_RequiredPropertyDescriptions(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.event.v1.0", Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {
+			"type": "object", "properties": {"id": {"type": "string", "description": "Identifier."}}}}}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {
+			"type": "object",
+			"properties": {
+				"tags": {"type": "array", "description": "Tags.", "items": {"type": "object", "properties": {"name": {"type": "string"}}}},
+				"value": {"description": "Value.", "anyOf": [{"type": "object", "properties": {"size": {"type": "integer"}}}, {"type": "number"}]}
+			},
+			"patternProperties": {"^x-": {"type": "string"}}}}}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.internal.v1.0", Access: metadata.AccessPrivate, Schema: []byte(`{"$ref": "#/definitions/Internal",
+			"definitions": {"Internal": {"type": "object", "properties": {"data": {"type": "string"}}}}}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	require.NoError(t, v.Validate(r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"]))
+
+	v, err = MakeMetadataValidator(r, WithRequiredPropertyDescriptions())
+	require.NoError(t, err)
+	require.NoError(t, v.Validate(r.Index["cti.x.y.event.v1.0"]))
+	// Only public types are checked.
+	require.NoError(t, v.Validate(r.Index["cti.x.y.event.v1.0~x.y.internal.v1.0"]))
+
+	err = v.Validate(r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"])
+	require.ErrorIs(t, err, ErrPropertyDescriptionMissing)
+	require.EqualError(t, err, "cti.x.y.event.v1.0~x.y.created.v1.0: properties do not have descriptions: "+
+		".tags.#.name, .value.size, ./^x-/")
+
+	// Types of other packages are not checked.
+	v, err = MakeMetadataValidator(r, WithRequiredPropertyDescriptions(), WithPackage("a.b", ""))
+	require.NoError(t, err)
+	require.NoError(t, v.Validate(r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"]))
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	DictionaryRuleName = "dictionary-member"
+)
+
+// NewDictionaryRule makes a rule that checks that values of instances declared by the package for properties
+// annotated with cti.dictionary in the types of the inheritance chain are keys of the package dictionary.
+// Instances of other packages, e.g. of dependencies, are checked against their own dictionaries, so they are skipped.
+func NewDictionaryRule(packageID string, keys []string) Rule {
+	dictionary := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		dictionary[key] = struct{}{}
+	}
+	return NewRuleFunc(DictionaryRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			if entity.Values == nil || !metadata.IsDeclaredBy(entity.Cti, packageID) {
+				return nil
+			}
+			return checkDictionaryValues(r, entity, dictionary)
+		})
+}
+
+func checkDictionaryValues(r *collector.MetadataRegistry, entity *metadata.Entity, dictionary map[string]struct{}) []Issue {
+	var issues []Issue
+	for id := metadata.GetParentCti(entity.Cti); ; id = metadata.GetParentCti(id) {
+		if typ, ok := r.Index[id]; ok {
+			keys := make([]string, 0, len(typ.Annotations))
+			for key, annotation := range typ.Annotations {
+				if annotation.Dictionary != nil && *annotation.Dictionary {
+					keys = append(keys, string(key))
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				for _, val := range metadata.GJsonPath(key).GetValue(entity.Values).Array() {
+					if _, ok := dictionary[val.String()]; ok {
+						continue
+					}
+					issues = append(issues, Issue{
+						Message: fmt.Sprintf("%s: value %s is not an entry of the package dictionary", key, val.Raw),
+					})
+				}
+			}
+		}
+		if metadata.GetParentCti(id) == id {
+			break
+		}
+	}
+	return issues
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_DictionaryRule(t *testing.T) {
+	yes := true
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti:         "cti.a.p.project.v1.0",
+			Schema:      []byte(`{}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".status": {Dictionary: &yes}, ".labels": {Dictionary: &yes}},
+		},
+		{Cti: "cti.a.p.project.v1.0~x.y.alpha.v1.0", Values: []byte(`{"status": "active", "labels": ["red", "blue"]}`)},
+		{Cti: "cti.a.p.project.v1.0~a.p.beta.v1.0", Values: []byte(`{"status": "deleted"}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	rule := NewDictionaryRule("x.y", []string{"active", "red"})
+	issues := rule.Validate(context.Background(), r, r.Index["cti.a.p.project.v1.0~x.y.alpha.v1.0"])
+	require.Len(t, issues, 1)
+	require.Equal(t, `.labels: value "blue" is not an entry of the package dictionary`, issues[0].Message)
+
+	// Instances of other packages are checked against their own dictionaries.
+	require.Empty(t, rule.Validate(context.Background(), r, r.Index["cti.a.p.project.v1.0~a.p.beta.v1.0"]))
+}
@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Errors that may be matched with errors.Is in errors returned by the validator.
+var (
+	// ErrSchemaViolation is matched by errors of values (e.g. of instances, traits or examples)
+	// that do not satisfy the schema, see SchemaViolationError and ExampleError.
+	ErrSchemaViolation = errors.New("values do not satisfy the schema")
+	// ErrTraitsSchemaMissing is returned when the entity has traits, but none of its ancestors defines the traits schema.
+	ErrTraitsSchemaMissing = errors.New("type is derived from type that does not define traits")
+	// ErrAnnotationUndefined is returned when the entity has an annotation of a third-party namespace
+	// that is not registered or does not define the annotation, see metadata.RegisterAnnotationNamespace.
+	ErrAnnotationUndefined = errors.New("annotation is not defined by a registered namespace")
+	// ErrReferenceNotFound is returned when the entity referenced by a value of the instance is not found,
+	// see WithDereferencedReferences.
+	ErrReferenceNotFound = errors.New("referenced entity is not found")
+	// ErrReferenceInaccessible is returned when the entity referenced by a value of the instance is not accessible
+	// to the package of the instance, see WithDereferencedReferences.
+	ErrReferenceInaccessible = errors.New("referenced entity is not accessible")
+	// ErrDraftReference is returned when the released type depends on a draft entity, see metadata.Entity.Status.
+	ErrDraftReference = errors.New("released type references draft entity")
+	// ErrReleasedBreakingChange is returned when the type released in the baseline is changed in a breaking way,
+	// see WithReleasedBaseline.
+	ErrReleasedBreakingChange = errors.New("released type is changed in a breaking way")
+	// ErrPropertyDescriptionMissing is returned when properties of the public type do not have descriptions,
+	// see WithRequiredPropertyDescriptions.
+	ErrPropertyDescriptionMissing = errors.New("properties do not have descriptions")
+)
+
+// SchemaViolationError is returned when values do not satisfy the schema.
+type SchemaViolationError struct {
+	// Violations are descriptions of the violated constraints.
+	Violations []string
+}
+
+func (e *SchemaViolationError) Error() string {
+	return strings.Join(e.Violations, "\n-")
+}
+
+// Is reports whether the target is ErrSchemaViolation.
+func (e *SchemaViolationError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+// newSchemaViolationError makes the error from the errors of the validation result.
+// Descriptions omit the field of the error and are used when the field is reported separately.
+func newSchemaViolationError(errs []gojsonschema.ResultError, descriptions bool) *SchemaViolationError {
+	violations := make([]string, len(errs))
+	for i, err := range errs {
+		if descriptions {
+			violations[i] = err.Description()
+		} else {
+			violations[i] = err.String()
+		}
+	}
+	return &SchemaViolationError{Violations: violations}
+}
+
+// AggregateError is returned by ValidateAll with all errors found in the registry, so they are reported in one run.
+// Errors may be matched with errors.Is and errors.As.
+type AggregateError struct {
+	// Errors are errors of entities in the order of their CTIs followed by errors of policies.
+	Errors []error
+	// Truncated is set if the validation is stopped after the maximum number of errors, see WithMaxIssues.
+	Truncated bool
+
+	trace *stacktrace.StackTrace
+}
+
+func (e *AggregateError) Error() string {
+	if e.Truncated {
+		return fmt.Sprintf("%s\nvalidation is stopped after %d errors", e.trace.Error(), len(e.Errors))
+	}
+	return e.trace.Error()
+}
+
+// Unwrap returns the errors.
+func (e *AggregateError) Unwrap() []error {
+	return e.Errors
+}
+
+// StackTrace returns the errors as a stack trace with CTIs, rules and locations of the errors,
+// e.g. for structured logging or editor diagnostics.
+func (e *AggregateError) StackTrace() *stacktrace.StackTrace {
+	return e.trace
+}
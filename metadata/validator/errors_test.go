@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateAllAggregateError(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:         "cti.x.y.event.v1.0",
+		Schema:      []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {"type": "object", "properties": {"id": {"type": "integer"}}}}}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{".": {Cti: "cti.x.y.event.v1.0"}},
+	}))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+			Cti:    fmt.Sprintf("cti.x.y.event.v1.0~x.y.e%d.v1.0", i),
+			Values: []byte(`{"id": "invalid"}`),
+		}))
+	}
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	err = v.ValidateAll()
+	var aggErr *AggregateError
+	require.ErrorAs(t, err, &aggErr)
+	require.Len(t, aggErr.Errors, 5)
+	require.False(t, aggErr.Truncated)
+	require.Len(t, aggErr.StackTrace().List, 5)
+	require.ErrorIs(t, err, ErrSchemaViolation)
+	// Errors are sorted by CTIs of entities.
+	require.ErrorContains(t, aggErr.Errors[0], "cti.x.y.event.v1.0~x.y.e0.v1.0")
+	require.ErrorContains(t, aggErr.Errors[4], "cti.x.y.event.v1.0~x.y.e4.v1.0")
+
+	v, err = MakeMetadataValidator(r, WithMaxIssues(2))
+	require.NoError(t, err)
+	err = v.ValidateAll()
+	require.True(t, errors.As(err, &aggErr))
+	require.Len(t, aggErr.Errors, 2)
+	require.True(t, aggErr.Truncated)
+	require.ErrorContains(t, err, "validation is stopped after 2 errors")
+
+	_, err = MakeMetadataValidator(r, WithMaxIssues(-1))
+	require.Error(t, err)
+}
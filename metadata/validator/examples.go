@@ -0,0 +1,185 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// ExampleError is a violation of the merged schema of the type by an example declared in its schema.
+type ExampleError struct {
+	Cti string
+	// Path is a path to the value described by the schema node that declares the example, e.g. .name or . for the type itself.
+	Path metadata.GJsonPath
+	// Index is a zero-based index of the example in the list of examples of the node.
+	Index int
+	// Location is the location of the RAML shape that declares the example.
+	Location metadata.SourceLocation
+	Errors   []string
+}
+
+func (e *ExampleError) Error() string {
+	return fmt.Sprintf("%s@%s: example #%d does not match the merged schema: %s",
+		e.Cti, e.Path, e.Index+1, strings.Join(e.Errors, "; "))
+}
+
+// Is reports whether the target is ErrSchemaViolation.
+func (e *ExampleError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+// ValidateExamples checks examples declared in the schema of the type (e.g. by example and examples facets of RAML)
+// against the corresponding nodes of its merged schema, so examples also satisfy constraints inherited from parents.
+// Each invalid example is reported separately with the location of the RAML shape that declares it.
+func (v *MetadataValidator) ValidateExamples(entity *metadata.Entity) ([]*ExampleError, error) {
+	if entity.Schema == nil {
+		return nil, nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(entity.Schema, &schema); err != nil {
+		return nil, fmt.Errorf("%s: unmarshal schema: %w", entity.Cti, err)
+	}
+	merged, err := v.schemas.GetMergedCtiSchema(entity.Cti)
+	if err != nil {
+		return nil, fmt.Errorf("%s: get merged schema: %w", entity.Cti, err)
+	}
+
+	w := &examplesWalker{
+		entity:   entity,
+		compiled: v.compiled,
+		visited:  make(map[string]struct{}),
+	}
+	w.definitions, _ = schema["definitions"].(map[string]any)
+	if err := w.walk(schema, merged, "."); err != nil {
+		return nil, fmt.Errorf("%s: %w", entity.Cti, err)
+	}
+	return w.errs, nil
+}
+
+type examplesWalker struct {
+	entity      *metadata.Entity
+	compiled    *CompiledSchemaCache
+	definitions map[string]any
+	// visited are names of definitions that are being walked, to stop on recursive schemas.
+	visited map[string]struct{}
+	errs    []*ExampleError
+}
+
+func (w *examplesWalker) walk(node map[string]any, merged map[string]any, path string) error {
+	if ref, ok := node["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := w.definitions[name].(map[string]any)
+		if !ok {
+			return nil
+		}
+		if _, ok := w.visited[name]; ok {
+			return nil
+		}
+		w.visited[name] = struct{}{}
+		defer delete(w.visited, name)
+		node = def
+	}
+
+	if examples, ok := node["examples"].([]any); ok && merged != nil {
+		if err := w.validate(examples, merged, path); err != nil {
+			return err
+		}
+	}
+
+	properties, _ := node["properties"].(map[string]any)
+	mergedProperties, _ := merged["properties"].(map[string]any)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child, ok := properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		mergedChild, _ := mergedProperties[name].(map[string]any)
+		if err := w.walk(child, mergedChild, joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+
+	if items, ok := node["items"].(map[string]any); ok {
+		mergedItems, _ := merged["items"].(map[string]any)
+		if err := w.walk(items, mergedItems, joinPath(path, "#")); err != nil {
+			return err
+		}
+	}
+
+	if members, ok := node["anyOf"].([]any); ok {
+		mergedMembers, _ := merged["anyOf"].([]any)
+		for i, member := range members {
+			child, ok := member.(map[string]any)
+			if !ok {
+				continue
+			}
+			var mergedChild map[string]any
+			if i < len(mergedMembers) {
+				mergedChild, _ = mergedMembers[i].(map[string]any)
+			}
+			// Members of anyOf do not have own paths in source maps, so the path of the union is used.
+			if err := w.walk(child, mergedChild, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *examplesWalker) validate(examples []any, merged map[string]any, path string) error {
+	// Examples of the node are not a part of the schema used to validate them.
+	schema := make(map[string]any, len(merged))
+	for key, val := range merged {
+		if key != "examples" {
+			schema[key] = val
+		}
+	}
+	compiled, err := w.compiled.Compile(schema)
+	if err != nil {
+		return fmt.Errorf("compile schema of %s: %w", path, err)
+	}
+	for i, example := range examples {
+		res, err := compiled.Validate(gojsonschema.NewGoLoader(example))
+		if err != nil {
+			return fmt.Errorf("validate example #%d of %s: %w", i+1, path, err)
+		}
+		if res.Valid() {
+			continue
+		}
+		exampleErr := &ExampleError{
+			Cti:      w.entity.Cti,
+			Path:     metadata.GJsonPath(path),
+			Index:    i,
+			Location: w.location(path),
+		}
+		for _, resErr := range res.Errors() {
+			exampleErr.Errors = append(exampleErr.Errors, resErr.String())
+		}
+		w.errs = append(w.errs, exampleErr)
+	}
+	return nil
+}
+
+func (w *examplesWalker) location(path string) metadata.SourceLocation {
+	if loc, ok := w.entity.SchemaSourceMap[metadata.GJsonPath(path)]; ok {
+		return loc
+	}
+	return metadata.SourceLocation{Path: w.entity.SourceMap.OriginalPath}
+}
+
+func joinPath(path string, key string) string {
+	if path == "." {
+		return path + key
+	}
+	return path + "." + key
+}
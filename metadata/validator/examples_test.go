@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateExamples(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti: "cti.x.y.event.v1.0",
+			Schema: []byte(`{
+				"$ref": "#/definitions/Event",
+				"definitions": {"Event": {
+					"type": "object",
+					"properties": {"name": {"type": "string", "maxLength": 5}}
+				}}
+			}`),
+		},
+		{
+			Cti: "cti.x.y.event.v1.0~x.y.created.v1.0",
+			Schema: []byte(`{
+				"$ref": "#/definitions/Created",
+				"definitions": {"Created": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string", "examples": ["short", "too long"]},
+						"tags": {"type": "array", "items": {"type": "integer", "examples": [1, "one"]}}
+					},
+					"examples": [{"name": "ok", "tags": [1]}]
+				}}
+			}`),
+			SchemaSourceMap: map[metadata.GJsonPath]metadata.SourceLocation{
+				".name": {Path: "created.raml", Line: 7, Column: 5},
+			},
+			SourceMap: metadata.SourceMap{OriginalPath: "created.raml"},
+		},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	errs, err := v.ValidateExamples(r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"])
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+
+	require.Equal(t, metadata.GJsonPath(".name"), errs[0].Path)
+	require.Equal(t, 1, errs[0].Index)
+	require.Equal(t, metadata.SourceLocation{Path: "created.raml", Line: 7, Column: 5}, errs[0].Location)
+	require.Contains(t, errs[0].Error(), "cti.x.y.event.v1.0~x.y.created.v1.0@.name: example #2 does not match the merged schema")
+	require.ErrorIs(t, errs[0], ErrSchemaViolation)
+
+	require.Equal(t, metadata.GJsonPath(".tags.#"), errs[1].Path)
+	require.Equal(t, 1, errs[1].Index)
+	require.Equal(t, metadata.SourceLocation{Path: "created.raml"}, errs[1].Location)
+
+	errs, err = v.ValidateExamples(r.Index["cti.x.y.event.v1.0"])
+	require.NoError(t, err)
+	require.Empty(t, errs)
+
+	require.ErrorContains(t, v.ValidateAll(), "example #2 does not match the merged schema")
+}
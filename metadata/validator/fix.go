@@ -0,0 +1,129 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// Position is a zero-based position in a source file.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a source file. End position is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Fix is a machine-applicable text edit that resolves an issue.
+type Fix struct {
+	// File is a path to the source file relative to the package directory.
+	File        string `json:"file"`
+	Range       Range  `json:"range"`
+	Replacement string `json:"replacement"`
+}
+
+// NewFix returns a fix that replaces the text at the location in the content of the file.
+// The location must have byte offsets, e.g. located with collector.SourceFile.
+func NewFix(file string, content []byte, loc metadata.SourceLocation, replacement string) Fix {
+	position := func(offset int) Position {
+		lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+		return Position{Line: bytes.Count(content[:lineStart], []byte{'\n'}), Character: offset - lineStart}
+	}
+	return Fix{
+		File:        file,
+		Range:       Range{Start: position(loc.Offset), End: position(loc.EndOffset)},
+		Replacement: replacement,
+	}
+}
+
+// ApplyFixes applies fixes to the files in the package directory.
+// Overlapping and duplicate fixes are applied only once.
+func ApplyFixes(baseDir string, fixes []Fix) error {
+	byFile := make(map[string][]Fix)
+	for _, fix := range fixes {
+		byFile[fix.File] = append(byFile[fix.File], fix)
+	}
+	for file, fileFixes := range byFile {
+		if err := applyFileFixes(filepath.Join(baseDir, file), fileFixes); err != nil {
+			return fmt.Errorf("apply fixes to %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func applyFileFixes(fPath string, fixes []Fix) error {
+	info, err := os.Stat(fPath)
+	if err != nil {
+		return fmt.Errorf("stat file: %w", err)
+	}
+	data, err := os.ReadFile(fPath)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	content := string(data)
+
+	lineOffsets := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	toOffset := func(p Position) (int, error) {
+		if p.Line < 0 || p.Line >= len(lineOffsets) {
+			return 0, fmt.Errorf("line %d is out of range", p.Line)
+		}
+		offset := lineOffsets[p.Line] + p.Character
+		if p.Character < 0 || offset > len(content) {
+			return 0, fmt.Errorf("character %d of line %d is out of range", p.Character, p.Line)
+		}
+		return offset, nil
+	}
+
+	type edit struct {
+		start, end  int
+		replacement string
+	}
+	edits := make([]edit, 0, len(fixes))
+	for _, fix := range fixes {
+		start, err := toOffset(fix.Range.Start)
+		if err != nil {
+			return err
+		}
+		end, err := toOffset(fix.Range.End)
+		if err != nil {
+			return err
+		}
+		if end < start {
+			return fmt.Errorf("invalid range %d:%d-%d:%d", fix.Range.Start.Line, fix.Range.Start.Character,
+				fix.Range.End.Line, fix.Range.End.Character)
+		}
+		edits = append(edits, edit{start: start, end: end, replacement: fix.Replacement})
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			// Skip overlapping edit.
+			continue
+		}
+		sb.WriteString(content[last:e.start])
+		sb.WriteString(e.replacement)
+		last = e.end
+	}
+	sb.WriteString(content[last:])
+
+	return os.WriteFile(fPath, []byte(sb.String()), info.Mode())
+}
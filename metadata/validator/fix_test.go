@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+func Test_ApplyFixes(t *testing.T) {
+	baseDir := t.TempDir()
+	content := "a: cti.x.y.topic.v1\nb: cti.x.y.topic.v1.0\nc: [cti.x.y.topic.v1, cti.x.y.topic.v10]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "entities.raml"), []byte(content), 0600))
+
+	fixes := []Fix{
+		{File: "entities.raml", Range: Range{Start: Position{0, 3}, End: Position{0, 19}}, Replacement: "cti.x.y.topic.v1.2"},
+		{File: "entities.raml", Range: Range{Start: Position{2, 4}, End: Position{2, 20}}, Replacement: "cti.x.y.topic.v1.2"},
+	}
+
+	// Duplicate fixes are applied once.
+	require.NoError(t, ApplyFixes(baseDir, append(fixes, fixes...)))
+
+	data, err := os.ReadFile(filepath.Join(baseDir, "entities.raml"))
+	require.NoError(t, err)
+	require.Equal(t, "a: cti.x.y.topic.v1.2\nb: cti.x.y.topic.v1.0\nc: [cti.x.y.topic.v1.2, cti.x.y.topic.v10]\n", string(data))
+
+	require.ErrorContains(t, ApplyFixes(baseDir, []Fix{{File: "entities.raml", Range: Range{Start: Position{10, 0}}}}),
+		"line 10 is out of range")
+}
+
+func Test_NewFix(t *testing.T) {
+	content := []byte("a: é\nb: [x, cti.x.y.topic.v1]\n")
+	start := len("a: é\nb: [x, ")
+	fix := NewFix("entities.raml", content, metadata.SourceLocation{Offset: start, EndOffset: start + len("cti.x.y.topic.v1")}, "cti.x.y.topic.v1.2")
+	require.Equal(t, Fix{
+		File:        "entities.raml",
+		Range:       Range{Start: Position{1, 7}, End: Position{1, 23}},
+		Replacement: "cti.x.y.topic.v1.2",
+	}, fix)
+}
@@ -0,0 +1,207 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	ReferenceMinorVersionRuleName = "reference-minor-version"
+	AccessModifierRuleName        = "access-modifier"
+	RequiredDeprecationRuleName   = "required-deprecation"
+)
+
+// NewReferenceMinorVersionRule makes a rule that reports instance values of referencing fields
+// that omit the minor version and suggests a fix with the latest minor version known to the registry.
+// The baseDir is a package directory that is used to locate the values in the source files.
+func NewReferenceMinorVersionRule(baseDir string) Rule {
+	return NewRuleFunc(ReferenceMinorVersionRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			return checkReferenceMinorVersion(baseDir, r, entity)
+		})
+}
+
+func checkReferenceMinorVersion(baseDir string, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	if entity.Values == nil {
+		return nil
+	}
+	parent, ok := r.Index[metadata.GetParentCti(entity.Cti)]
+	if !ok || parent.Cti == entity.Cti {
+		return nil
+	}
+
+	references := make(map[metadata.GJsonPath]metadata.Annotations)
+	for key, annotation := range parent.Annotations {
+		if annotation.Reference != nil {
+			references[key] = annotation
+		}
+	}
+
+	// Fixes are best-effort, the issues are reported even if the source file is not available.
+	var source *collector.SourceFile
+	if loc := entity.ValuesSourceLocation; loc != nil {
+		if content, err := os.ReadFile(filepath.Join(baseDir, loc.Path)); err == nil {
+			source, _ = collector.ParseSourceFile(content)
+		}
+	}
+
+	var issues []Issue
+	for _, annotated := range metadata.ResolveAnnotatedValues(entity.Values, references) {
+		// References may be either single values or arrays of them.
+		values := []gjson.Result{annotated.Value}
+		paths := []metadata.ValuePath{annotated.Path}
+		if annotated.Value.IsArray() {
+			values, paths = nil, nil
+			for i, val := range annotated.Value.Array() {
+				values = append(values, val)
+				paths = append(paths, append(annotated.Path[:len(annotated.Path):len(annotated.Path)], strconv.Itoa(i)))
+			}
+		}
+		for i, val := range values {
+			ref := val.Str
+			expr, err := cti.ParseReference(ref)
+			if err != nil {
+				continue
+			}
+			tail := expr.Tail()
+			if tail == nil || tail.HasWildcard() || !tail.Version.Major.Valid || tail.Version.Minor.Valid {
+				continue
+			}
+			issue := Issue{
+				Message: fmt.Sprintf("%s: reference %s is missing minor version", annotated.Key, ref),
+			}
+			if minor, ok := latestMinorVersion(r, ref); ok {
+				fixed := ref + "." + strconv.FormatUint(uint64(minor), 10)
+				issue.Message += fmt.Sprintf(", use %s", fixed)
+				if source != nil {
+					if loc, ok := source.ValueLocation(*entity.ValuesSourceLocation, paths[i]); ok {
+						issue.Fixes = []Fix{NewFix(loc.Path, source.Content(), loc, fixed)}
+					}
+				}
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func latestMinorVersion(r *collector.MetadataRegistry, ref string) (uint, bool) {
+	var latest uint
+	found := false
+	prefix := ref + "."
+	for id := range r.Types {
+		if !strings.HasPrefix(id, prefix) {
+			continue
+		}
+		minor, err := strconv.ParseUint(id[len(prefix):], 10, 32)
+		if err != nil {
+			continue
+		}
+		if !found || uint(minor) > latest {
+			latest = uint(minor)
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// NewAccessModifierRule makes a rule that reports types that are more accessible than their parent types
+// and suggests a fix that narrows cti.access of the type to the access of the parent.
+// The baseDir is a package directory that is used to locate the annotations in the source files.
+func NewAccessModifierRule(baseDir string) Rule {
+	return NewRuleFunc(AccessModifierRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			return checkAccessModifier(baseDir, r, entity)
+		})
+}
+
+func checkAccessModifier(baseDir string, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	if entity.Schema == nil {
+		return nil
+	}
+	parent, ok := r.Index[metadata.GetParentCti(entity.Cti)]
+	if !ok || parent.Cti == entity.Cti || accessLevel(entity.Access) >= accessLevel(parent.Access) {
+		return nil
+	}
+	issue := Issue{
+		Message: fmt.Sprintf("%s access is wider than %s access of parent %s, use %s",
+			accessName(entity.Access), parent.Access, parent.Cti, parent.Access),
+	}
+	if fix, ok := annotationFix(baseDir, entity, metadata.Access, parent.Access); ok {
+		issue.Fixes = []Fix{fix}
+	}
+	return []Issue{issue}
+}
+
+func accessName(access string) string {
+	if access == "" {
+		return metadata.AccessPublic
+	}
+	return access
+}
+
+func accessLevel(access string) int {
+	switch access {
+	case metadata.AccessPrivate:
+		return 2
+	case metadata.AccessProtected:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NewRequiredDeprecationRule makes a rule that reports types of older major versions that are not annotated
+// with cti.deprecated (see CoexistencePolicy.RequireDeprecation) and suggests a fix that adds the annotation.
+// The baseDir is a package directory that is used to locate the annotations in the source files.
+func NewRequiredDeprecationRule(baseDir string) Rule {
+	return NewRuleFunc(RequiredDeprecationRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			issues := checkCoexistence(CoexistencePolicy{RequireDeprecation: true}, r, entity)
+			for i := range issues {
+				if fix, ok := annotationFix(baseDir, entity, metadata.Deprecated, "true"); ok {
+					issues[i].Fixes = []Fix{fix}
+				}
+			}
+			return issues
+		})
+}
+
+// annotationFix returns a fix that sets the value of the annotation of the type.
+// The annotation is replaced if the type has it, otherwise it is inserted before the cti.cti annotation.
+func annotationFix(baseDir string, entity *metadata.Entity, name, value string) (Fix, bool) {
+	locations := entity.AnnotationsSourceMap["."]
+	loc, ok := locations[name]
+	insert := !ok
+	if insert {
+		if loc, ok = locations[metadata.Cti]; !ok {
+			return Fix{}, false
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(baseDir, loc.Path))
+	if err != nil || loc.Offset > loc.EndOffset || loc.EndOffset > len(content) {
+		return Fix{}, false
+	}
+	annotation := fmt.Sprintf("(%s): %s", name, value)
+	if !insert {
+		return NewFix(loc.Path, content, loc, annotation), true
+	}
+	// Annotations of flow mappings are not fixed, since the indentation of the new line is unknown.
+	indent := content[bytes.LastIndexByte(content[:loc.Offset], '\n')+1 : loc.Offset]
+	if len(bytes.TrimLeft(indent, " ")) != 0 {
+		return Fix{}, false
+	}
+	loc.EndOffset = loc.Offset
+	return NewFix(loc.Path, content, loc, annotation+"\n"+string(indent)), true
+}
@@ -0,0 +1,164 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// NewValidatedInstance makes the instance of the CTI type from the values, e.g. a struct or a map,
+// so services that mint instances at runtime get them in the same form as instances collected from packages.
+// Defaults of the merged schema of the type are applied to absent properties, the values are validated
+// the same way as collected instances (see Validate), and the CTI, display name and description of the instance
+// are taken from the values of properties annotated with cti.id, cti.display_name and cti.description
+// in the type or its ancestors.
+func (v *MetadataValidator) NewValidatedInstance(typ *metadata.Entity, values any) (*metadata.Entity, error) {
+	if typ.Schema == nil {
+		return nil, fmt.Errorf("%s is not a type", typ.Cti)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("marshal values: %w", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("values of %s must be an object: %w", typ.Cti, err)
+	}
+
+	schema, err := v.schemas.GetMergedCtiSchema(typ.Cti)
+	if err != nil {
+		return nil, fmt.Errorf("get merged schema of %s: %w", typ.Cti, err)
+	}
+	definitions, _ := schema["definitions"].(map[string]any)
+	applyDefaults(schema, definitions, obj, make(map[string]struct{}))
+	if data, err = json.Marshal(obj); err != nil {
+		return nil, fmt.Errorf("marshal values: %w", err)
+	}
+
+	instance := &metadata.Entity{Final: true, Values: data}
+	for key, annotation := range v.instanceAnnotations(typ.Cti) {
+		value := key.GetValue(data)
+		switch {
+		case annotation.ID != nil && *annotation.ID:
+			instance.Cti = value.String()
+		case annotation.DisplayName != nil && *annotation.DisplayName:
+			instance.DisplayName = value.String()
+		case annotation.Description != nil && *annotation.Description:
+			instance.Description = value.String()
+		}
+	}
+	if instance.Cti == "" {
+		return nil, fmt.Errorf("values of %s have no cti.id", typ.Cti)
+	}
+	if metadata.GetParentCti(instance.Cti) != typ.Cti {
+		return nil, fmt.Errorf("%s is not an instance of %s", instance.Cti, typ.Cti)
+	}
+
+	if err := v.Validate(instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// ValidateValues implements cti.ValuesValidator. It validates the values of the instance with the CTI
+// encoded to JSON the same way as collected instances (see Validate), e.g. after the values are patched
+// with cti.EntityInstance.ApplyPatch. The value of the property annotated with cti.id must match the CTI.
+func (v *MetadataValidator) ValidateValues(id string, values []byte) error {
+	parentCti := metadata.GetParentCti(id)
+	if parentCti == id {
+		return fmt.Errorf("%s is not an instance", id)
+	}
+	if _, ok := v.registry.Index[parentCti]; !ok {
+		return fmt.Errorf("parent type %s of %s not found", parentCti, id)
+	}
+	for key, annotation := range v.instanceAnnotations(parentCti) {
+		if annotation.ID != nil && *annotation.ID {
+			if value := key.GetValue(values).String(); value != id {
+				return fmt.Errorf("cti.id of %s must not be changed to %q", id, value)
+			}
+		}
+	}
+	return v.Validate(&metadata.Entity{Cti: id, Final: true, Values: values})
+}
+
+// instanceAnnotations returns annotations of the type and its ancestors that define the CTI, display name
+// and description of instances. Annotations of the nearest type take precedence.
+func (v *MetadataValidator) instanceAnnotations(id string) map[metadata.GJsonPath]metadata.Annotations {
+	res := make(map[metadata.GJsonPath]metadata.Annotations)
+	seen := make(map[string]struct{})
+	for {
+		entity, ok := v.registry.Index[id]
+		if !ok {
+			break
+		}
+		for key, annotation := range entity.Annotations {
+			var kind string
+			switch {
+			case annotation.ID != nil && *annotation.ID:
+				kind = metadata.ID
+			case annotation.DisplayName != nil && *annotation.DisplayName:
+				kind = metadata.DisplayName
+			case annotation.Description != nil && *annotation.Description:
+				kind = metadata.Description
+			default:
+				continue
+			}
+			if _, ok := seen[kind]; ok {
+				continue
+			}
+			seen[kind] = struct{}{}
+			res[key] = annotation
+		}
+		parentCti := metadata.GetParentCti(id)
+		if parentCti == id {
+			break
+		}
+		id = parentCti
+	}
+	return res
+}
+
+// applyDefaults sets absent properties of the object that have default values in the schema.
+// Defaults of nested objects are applied to the present objects only.
+func applyDefaults(schema map[string]any, definitions map[string]any, obj map[string]any, visiting map[string]struct{}) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if _, ok := visiting[ref]; ok {
+			return
+		}
+		def, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		if !ok {
+			return
+		}
+		visiting[ref] = struct{}{}
+		defer delete(visiting, ref)
+		schema = def
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	for name, property := range properties {
+		propSchema, ok := property.(map[string]any)
+		if !ok {
+			continue
+		}
+		val, ok := obj[name]
+		if !ok {
+			if def, ok := resolveSchema(propSchema, definitions)["default"]; ok {
+				obj[name] = def
+			}
+			continue
+		}
+		if child, ok := val.(map[string]any); ok {
+			applyDefaults(propSchema, definitions, child, visiting)
+		}
+	}
+}
+
+func resolveSchema(schema map[string]any, definitions map[string]any) map[string]any {
+	if ref, ok := schema["$ref"].(string); ok {
+		if def, ok := definitions[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any); ok {
+			return def
+		}
+	}
+	return schema
+}
@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti"
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_NewValidatedInstance(t *testing.T) {
+	yes := true
+	r := collector.NewMetadataRegistry()
+	typ := &metadata.Entity{
+		Cti: "cti.x.y.topic.v1.0",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Topic",
+			"definitions": {"Topic": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"name": {"type": "string"},
+					"summary": {"type": "string", "default": "No summary"},
+					"retention": {"type": "integer", "default": 7}
+				},
+				"required": ["id", "name"]
+			}}
+		}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{
+			".id":      {ID: &yes},
+			".name":    {DisplayName: &yes},
+			".summary": {Description: &yes},
+		},
+	}
+	require.NoError(t, r.Add("entities.raml", typ))
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+
+	instance, err := v.NewValidatedInstance(typ, map[string]any{"id": "cti.x.y.topic.v1.0~x.y.audit.v1.0", "name": "Audit"})
+	require.NoError(t, err)
+	require.Equal(t, "cti.x.y.topic.v1.0~x.y.audit.v1.0", instance.Cti)
+	require.Equal(t, "Audit", instance.DisplayName)
+	require.Equal(t, "No summary", instance.Description)
+	require.True(t, instance.Final)
+	require.JSONEq(t, `{"id": "cti.x.y.topic.v1.0~x.y.audit.v1.0", "name": "Audit", "summary": "No summary", "retention": 7}`,
+		string(instance.Values))
+
+	_, err = v.NewValidatedInstance(typ, map[string]any{"name": "Audit"})
+	require.EqualError(t, err, "values of cti.x.y.topic.v1.0 have no cti.id")
+
+	_, err = v.NewValidatedInstance(typ, map[string]any{"id": "cti.x.y.other.v1.0~x.y.audit.v1.0", "name": "Audit"})
+	require.EqualError(t, err, "cti.x.y.other.v1.0~x.y.audit.v1.0 is not an instance of cti.x.y.topic.v1.0")
+
+	_, err = v.NewValidatedInstance(typ, map[string]any{"id": "cti.x.y.topic.v1.0~x.y.audit.v1.0", "name": "Audit", "retention": "week"})
+	require.Error(t, err)
+
+	_, err = v.NewValidatedInstance(typ, []string{"audit"})
+	require.Error(t, err)
+}
+
+func Test_ValidateValues(t *testing.T) {
+	yes := true
+	r := collector.NewMetadataRegistry()
+	typ := &metadata.Entity{
+		Cti: "cti.x.y.topic.v1.0",
+		Schema: []byte(`{
+			"$ref": "#/definitions/Topic",
+			"definitions": {"Topic": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"retention": {"type": "integer"}
+				},
+				"required": ["id"]
+			}}
+		}`),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{".id": {ID: &yes}},
+	}
+	require.NoError(t, r.Add("entities.raml", typ))
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+
+	instance := &cti.EntityInstance{
+		Cti:    "cti.x.y.topic.v1.0~x.y.audit.v1.0",
+		Values: map[string]any{"id": "cti.x.y.topic.v1.0~x.y.audit.v1.0", "retention": float64(7)},
+	}
+	require.NoError(t, instance.ApplyPatch([]byte(`{"retention": 30}`), cti.MergePatch, v))
+	require.Equal(t, float64(30), instance.Values["retention"])
+	require.JSONEq(t, `{"id": "cti.x.y.topic.v1.0~x.y.audit.v1.0", "retention": 30}`, string(instance.RawValues))
+
+	require.Error(t, instance.ApplyPatch([]byte(`{"retention": "week"}`), cti.MergePatch, v))
+	require.ErrorContains(t, instance.ApplyPatch([]byte(`[{"op": "remove", "path": "/id"}]`), cti.JSONPatch, v),
+		"cti.id of cti.x.y.topic.v1.0~x.y.audit.v1.0 must not be changed")
+	require.Equal(t, float64(30), instance.Values["retention"])
+
+	require.EqualError(t, v.ValidateValues("cti.x.y.topic.v1.0", []byte(`{}`)), "cti.x.y.topic.v1.0 is not an instance")
+	require.EqualError(t, v.ValidateValues("cti.x.y.other.v1.0~x.y.audit.v1.0", []byte(`{}`)),
+		"parent type cti.x.y.other.v1.0 of cti.x.y.other.v1.0~x.y.audit.v1.0 not found")
+}
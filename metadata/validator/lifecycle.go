@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// WithReleasedBaseline makes the validator reject breaking changes of types that are released in the baseline,
+// e.g. in the previously published version of the package (see collector.CheckTypeCompatibility).
+// Unlike collector.CheckCompatibility, final and internal types are checked as well.
+func WithReleasedBaseline(baseline *collector.MetadataRegistry) Option {
+	return func(v *MetadataValidator) error {
+		if baseline == nil {
+			return fmt.Errorf("baseline registry is nil")
+		}
+		v.releasedBaseline = baseline
+		return nil
+	}
+}
+
+// validateLifecycle checks that the released type does not depend on draft entities
+// and does not change in a breaking way since the baseline, see WithReleasedBaseline.
+func (v *MetadataValidator) validateLifecycle(current *metadata.Entity) error {
+	if _, ok := v.registry.Types[current.Cti]; !ok || !current.IsReleased() {
+		return nil
+	}
+	deps, err := v.registry.GetDependencies(current.Cti)
+	if err != nil {
+		return err
+	}
+	for _, id := range deps {
+		if v.registry.Index[id].Status == metadata.StatusDraft {
+			return fmt.Errorf("%s: %w: %s", current.Cti, ErrDraftReference, id)
+		}
+	}
+
+	if v.releasedBaseline == nil {
+		return nil
+	}
+	baseline, ok := v.releasedBaseline.Types[current.Cti]
+	if !ok || !baseline.IsReleased() {
+		return nil
+	}
+	changes, err := collector.CheckTypeCompatibility(baseline, current)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(changes))
+	for i, change := range changes {
+		descriptions[i] = change.String()
+	}
+	return fmt.Errorf("%w:\n-%s", ErrReleasedBreakingChange, strings.Join(descriptions, "\n-"))
+}
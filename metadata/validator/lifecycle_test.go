@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateLifecycle(t *testing.T) {
+	schema := func(name, typ string) []byte {
+		return []byte(`{"$ref": "#/definitions/` + name + `", "definitions": {"` + name + `": {
+			"type": "object", "properties": {"name": {"type": "` + typ + `"}, "topic": {"type": "string"}}}}}`)
+	}
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.topic.v1.0", Schema: schema("Topic", "string"), Status: metadata.StatusDraft},
+		{Cti: "cti.x.y.event.v1.0", Schema: schema("Event", "integer")},
+		{Cti: "cti.x.y.subscription.v1.0", Schema: schema("Subscription", "string"), Status: metadata.StatusDraft,
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".topic": {Reference: "cti.x.y.topic.v1.0"}}},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	// Drafts may reference drafts.
+	require.NoError(t, v.Validate(r.Index["cti.x.y.subscription.v1.0"]))
+
+	released := &metadata.Entity{Cti: "cti.x.y.subscription.v1.0", Schema: schema("Subscription", "string"),
+		Annotations: map[metadata.GJsonPath]metadata.Annotations{".topic": {Reference: "cti.x.y.topic.v1.0"}}}
+	require.NoError(t, r.Replace("entities.raml", released))
+	err = v.Validate(released)
+	require.ErrorIs(t, err, ErrDraftReference)
+	require.EqualError(t, err, "cti.x.y.subscription.v1.0: released type references draft entity: cti.x.y.topic.v1.0")
+
+	baseline := collector.NewMetadataRegistry()
+	require.NoError(t, baseline.Add("entities.raml", &metadata.Entity{Cti: "cti.x.y.event.v1.0", Schema: schema("Event", "string")}))
+	require.NoError(t, baseline.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.topic.v1.0", Schema: schema("Topic", "integer"), Status: metadata.StatusDraft}))
+
+	v, err = MakeMetadataValidator(r, WithReleasedBaseline(baseline))
+	require.NoError(t, err)
+	err = v.Validate(r.Index["cti.x.y.event.v1.0"])
+	require.ErrorIs(t, err, ErrReleasedBreakingChange)
+	require.ErrorContains(t, err, "cti.x.y.event.v1.0: schema")
+	// Types that are drafts in the baseline may change freely.
+	require.NoError(t, v.Validate(r.Index["cti.x.y.topic.v1.0"]))
+
+	_, err = MakeMetadataValidator(r, WithReleasedBaseline(nil))
+	require.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	MetaReferenceRuleName = "meta-reference"
+)
+
+// NewMetaReferenceRule makes a rule that reports cti.meta annotations that do not reference
+// an existing type or instance (see metadata.Entity.ResolveMeta).
+func NewMetaReferenceRule() Rule {
+	return NewRuleFunc(MetaReferenceRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			return checkMetaReferences(r, entity)
+		})
+}
+
+func checkMetaReferences(r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	keys := make([]string, 0, len(entity.Annotations))
+	for key, annotation := range entity.Annotations {
+		if annotation.Meta != "" {
+			keys = append(keys, key.String())
+		}
+	}
+	sort.Strings(keys)
+
+	var issues []Issue
+	for _, key := range keys {
+		if _, err := entity.ResolveMeta(r, metadata.GJsonPath(key)); err != nil {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%s: %s", key, err.Error()),
+			})
+		}
+	}
+	return issues
+}
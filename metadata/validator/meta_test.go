@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_MetaReferenceRule(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.meta.v1.0", Schema: []byte(`{"type": "object"}`)},
+		{
+			Cti:    "cti.x.y.event.v1.0",
+			Schema: []byte(`{"type": "object"}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".":        {Meta: "cti.x.y.meta.v1.0"},
+				".payload": {Meta: "cti.x.y.unknown.v1.0"},
+				".invalid": {Meta: "not a cti"},
+			},
+		},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+
+	entity := r.Index["cti.x.y.event.v1.0"]
+	meta, err := entity.ResolveMeta(r, ".")
+	require.NoError(t, err)
+	require.Equal(t, "cti.x.y.meta.v1.0", meta.Cti)
+
+	meta, err = entity.ResolveMeta(r, ".missing")
+	require.NoError(t, err)
+	require.Nil(t, meta)
+
+	issues := NewMetaReferenceRule().Validate(context.Background(), r, entity)
+	require.Len(t, issues, 2)
+	require.Contains(t, issues[0].Message, ".invalid: parse cti.meta not a cti")
+	require.Equal(t, ".payload: cti.meta references unknown entity cti.x.y.unknown.v1.0", issues[1].Message)
+	require.Empty(t, NewMetaReferenceRule().Validate(context.Background(), r, r.Index["cti.x.y.meta.v1.0"]))
+}
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// validateExtraAnnotations checks values of annotations of third-party namespaces against the schemas
+// of the annotations registered with metadata.RegisterAnnotationNamespace.
+func validateExtraAnnotations(current *metadata.Entity) error {
+	for _, annotations := range []map[metadata.GJsonPath]metadata.Annotations{current.Annotations, current.TraitsAnnotations} {
+		keys := make([]string, 0, len(annotations))
+		for key, annotation := range annotations {
+			if len(annotation.Extra) != 0 {
+				keys = append(keys, key.String())
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			extra := annotations[metadata.GJsonPath(key)].Extra
+			names := make([]string, 0, len(extra))
+			for name := range extra {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				schema, ok := metadata.AnnotationSchema(name)
+				if !ok {
+					return fmt.Errorf("%s@%s: %w: %s", current.Cti, key, ErrAnnotationUndefined, name)
+				}
+				value, err := json.Marshal(extra[name])
+				if err != nil {
+					return fmt.Errorf("%s@%s: marshal %s: %w", current.Cti, key, name, err)
+				}
+				if err := validateBytesJsonValues(schema, value); err != nil {
+					return fmt.Errorf("%s@%s: %s contains invalid value: %w", current.Cti, key, name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
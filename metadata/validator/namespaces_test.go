@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateExtraAnnotations(t *testing.T) {
+	require.NoError(t, metadata.RegisterAnnotationNamespace("acme", map[string]json.RawMessage{
+		"sensitive": json.RawMessage(`{"type": "boolean"}`),
+		"retention": json.RawMessage(`{"type": "string", "format": "duration"}`),
+	}))
+	t.Cleanup(func() { metadata.UnregisterAnnotationNamespace("acme") })
+
+	validate := func(extra map[string]interface{}) error {
+		entity := &metadata.Entity{
+			Cti:    "cti.x.y.event.v1.0",
+			Schema: []byte(`{"type": "object", "properties": {"email": {"type": "string"}}}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".email": {Extra: extra},
+			},
+		}
+		r := collector.NewMetadataRegistry()
+		require.NoError(t, r.Add("entities.raml", entity))
+		v, err := MakeMetadataValidator(r)
+		require.NoError(t, err)
+		return v.Validate(entity)
+	}
+
+	require.NoError(t, validate(map[string]interface{}{"acme.sensitive": true, "acme.retention": "P30D"}))
+
+	err := validate(map[string]interface{}{"acme.sensitive": "yes"})
+	require.ErrorIs(t, err, ErrSchemaViolation)
+	require.ErrorContains(t, err, "cti.x.y.event.v1.0@.email: acme.sensitive contains invalid value")
+
+	err = validate(map[string]interface{}{"acme.owner": "billing"})
+	require.ErrorIs(t, err, ErrAnnotationUndefined)
+	require.ErrorContains(t, err, "acme.owner")
+
+	metadata.UnregisterAnnotationNamespace("acme")
+	require.ErrorIs(t, validate(map[string]interface{}{"acme.sensitive": true}), ErrAnnotationUndefined)
+}
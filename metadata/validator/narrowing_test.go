@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+	"github.com/acronis/go-cti/metadata/merger"
+)
+
+func Test_StrictInheritance(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.event.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Event", "definitions": {"Event": {
+			"type": "object", "properties": {"name": {"type": "string", "maxLength": 10}}}}}`),
+	}))
+	child := &metadata.Entity{
+		Cti: "cti.x.y.event.v1.0~x.y.created.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Created", "definitions": {"Created": {
+			"type": "object", "properties": {"name": {"type": "string", "maxLength": 20}}}}}`),
+	}
+	require.NoError(t, r.Add("entities.raml", child))
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	require.NoError(t, v.Validate(child))
+
+	v, err = MakeMetadataValidator(r, WithStrictInheritance())
+	require.NoError(t, err)
+	err = v.Validate(child)
+	require.ErrorIs(t, err, merger.ErrConstraintWidened)
+	require.ErrorContains(t, err, "of cti.x.y.event.v1.0~x.y.created.v1.0 and parent cti.x.y.event.v1.0 at /properties/name")
+	require.ErrorContains(t, err, "maxLength is raised from 10 to 20")
+}
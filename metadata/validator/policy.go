@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// PolicyInput is the data a policy is evaluated over.
+type PolicyInput struct {
+	// PackageID and BaseDir identify the package. Both are empty if the validator is not bound to a package.
+	PackageID string
+	BaseDir   string
+	// Registry is the parsed registry of the package and its dependencies.
+	Registry *collector.MetadataRegistry
+	// Issues are diagnostics reported by the core validation and the rules.
+	Issues []Issue
+}
+
+// Policy is an organization-specific acceptance check of the whole package.
+// Policies are evaluated after the core validation and the rules, so they can gate on their diagnostics.
+// Policies may be implemented as plain Go functions or as adapters to external policy engines.
+type Policy interface {
+	// Name returns a unique name of the policy.
+	Name() string
+	// Evaluate returns issues that prevent the package from being accepted.
+	// Returned error means that the policy could not be evaluated and is treated as a failure.
+	Evaluate(ctx context.Context, input PolicyInput) ([]Issue, error)
+}
+
+type policyFunc struct {
+	name string
+	fn   func(ctx context.Context, input PolicyInput) ([]Issue, error)
+}
+
+func (f *policyFunc) Name() string {
+	return f.name
+}
+
+func (f *policyFunc) Evaluate(ctx context.Context, input PolicyInput) ([]Issue, error) {
+	return f.fn(ctx, input)
+}
+
+// NewPolicyFunc wraps a plain function into a Policy with the specified name.
+func NewPolicyFunc(name string, fn func(ctx context.Context, input PolicyInput) ([]Issue, error)) Policy {
+	return &policyFunc{name: name, fn: fn}
+}
+
+// WithPolicies makes the validator evaluate the policies after the core validation and the rules.
+func WithPolicies(policies ...Policy) Option {
+	return func(v *MetadataValidator) error {
+		for _, policy := range policies {
+			if policy == nil {
+				return fmt.Errorf("policy is nil")
+			}
+			name := policy.Name()
+			if name == "" {
+				return fmt.Errorf("policy name is empty")
+			}
+			for _, p := range v.policies {
+				if p.Name() == name {
+					return fmt.Errorf("duplicate policy %s", name)
+				}
+			}
+			v.policies = append(v.policies, policy)
+		}
+		return nil
+	}
+}
+
+// WithPackage binds the validator to the package, so policies receive its identifier and directory.
+func WithPackage(packageID string, baseDir string) Option {
+	return func(v *MetadataValidator) error {
+		v.packageID = packageID
+		v.baseDir = baseDir
+		return nil
+	}
+}
+
+// EvaluatePolicies evaluates the policies over the registry and the diagnostics.
+// Issues without severity are reported as errors and are attributed to the policy.
+func (v *MetadataValidator) EvaluatePolicies(ctx context.Context, issues []Issue) ([]Issue, error) {
+	input := PolicyInput{
+		PackageID: v.packageID,
+		BaseDir:   v.baseDir,
+		Registry:  v.registry,
+		Issues:    issues,
+	}
+	var res []Issue
+	for _, policy := range v.policies {
+		policyIssues, err := policy.Evaluate(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate policy %s: %w", policy.Name(), err)
+		}
+		for _, issue := range policyIssues {
+			if issue.Rule == "" {
+				issue.Rule = policy.Name()
+			}
+			if issue.Severity == "" {
+				issue.Severity = SeverityError
+			}
+			res = append(res, issue)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateAllWithPolicies(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.sample_entity.v1.0",
+		Schema: []byte(`{"type": "object"}`),
+	}))
+
+	warning := NewRuleFunc("warning", func(_ context.Context, _ *collector.MetadataRegistry, _ *metadata.Entity) []Issue {
+		return []Issue{{Severity: SeverityWarning, Message: "just a warning"}}
+	})
+	rr := NewRuleRegistry()
+	rr.MustRegister(warning)
+
+	var input PolicyInput
+	noWarnings := NewPolicyFunc("no-warnings", func(_ context.Context, in PolicyInput) ([]Issue, error) {
+		input = in
+		var issues []Issue
+		for _, issue := range in.Issues {
+			if issue.Severity == SeverityWarning {
+				issues = append(issues, Issue{Cti: issue.Cti, Message: "warnings are not allowed: " + issue.Message})
+			}
+		}
+		return issues, nil
+	})
+	ownership := NewPolicyFunc("ownership", func(_ context.Context, in PolicyInput) ([]Issue, error) {
+		if !strings.HasPrefix(in.PackageID, "x.") {
+			return []Issue{{Message: "package is not owned by vendor x"}}, nil
+		}
+		return nil, nil
+	})
+
+	v, err := MakeMetadataValidator(r, WithRules(rr), WithPolicies(noWarnings, ownership), WithPackage("z.y", "/tmp/pkg"))
+	require.NoError(t, err)
+
+	err = v.ValidateAll()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "cti.x.y.sample_entity.v1.0: warnings are not allowed: just a warning")
+	require.ErrorContains(t, err, "package is not owned by vendor x")
+
+	require.Equal(t, "z.y", input.PackageID)
+	require.Equal(t, "/tmp/pkg", input.BaseDir)
+	require.Same(t, r, input.Registry)
+	require.Equal(t, []Issue{
+		{Cti: "cti.x.y.sample_entity.v1.0", Rule: "warning", Severity: SeverityWarning, Message: "just a warning"},
+	}, input.Issues)
+
+	issues, err := v.EvaluatePolicies(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []Issue{
+		{Rule: "ownership", Severity: SeverityError, Message: "package is not owned by vendor x"},
+	}, issues)
+
+	failing := NewPolicyFunc("failing", func(context.Context, PolicyInput) ([]Issue, error) {
+		return nil, errors.New("engine is unavailable")
+	})
+	v, err = MakeMetadataValidator(r, WithPolicies(failing))
+	require.NoError(t, err)
+	require.ErrorContains(t, v.ValidateAll(), "evaluate policy failing: engine is unavailable")
+
+	_, err = MakeMetadataValidator(r, WithPolicies(ownership, ownership))
+	require.ErrorContains(t, err, "duplicate policy ownership")
+}
@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+// WithDereferencedReferences makes the validator resolve instance values referenced by properties annotated
+// with cti.reference: referenced entities must exist in the registry and be accessible to the package
+// of the instance according to their access modifiers (see collector.ViewFor).
+// Without the option, only the syntax of the values and their match with cti.reference are checked.
+func WithDereferencedReferences() Option {
+	return func(v *MetadataValidator) error {
+		v.dereferenceReferences = true
+		return nil
+	}
+}
+
+// dereference checks that the entity referenced by the value of the instance at the key exists and is accessible.
+func (v *MetadataValidator) dereference(current *metadata.Entity, key metadata.GJsonPath, id string) error {
+	entity, ok := v.registry.Index[id]
+	if !ok {
+		return fmt.Errorf("%s@%s: %w: %s", current.Cti, key, ErrReferenceNotFound, id)
+	}
+	if !collector.IsAccessibleFrom(entity, current.Cti) {
+		return fmt.Errorf("%s@%s: %w: %s is %s", current.Cti, key, ErrReferenceInaccessible, id, entity.Access)
+	}
+	return nil
+}
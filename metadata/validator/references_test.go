@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_DereferencedReferences(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.topic.v1.0", Schema: []byte(`{"$ref": "#/definitions/Topic", "definitions": {"Topic": {"type": "object"}}}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".": {Cti: "cti.x.y.topic.v1.0"}}},
+		{Cti: "cti.x.y.topic.v1.0~x.y.public.v1.0", Values: []byte(`{}`)},
+		{Cti: "cti.x.y.topic.v1.0~x.y.private.v1.0", Values: []byte(`{}`), Access: metadata.AccessPrivate},
+		{Cti: "cti.x.y.subscription.v1.0", Schema: []byte(`{"$ref": "#/definitions/Subscription", "definitions": {"Subscription": {
+			"type": "object", "properties": {"topic": {"type": "string"}, "any": {"type": "string"}}}}}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{
+				".topic": {Reference: "cti.x.y.topic.v1.0"},
+				".any":   {Reference: true},
+			}},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	instance := func(values string) *metadata.Entity {
+		return &metadata.Entity{Cti: "cti.x.y.subscription.v1.0~a.b.main.v1.0", Values: []byte(values)}
+	}
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	// Only the syntax and the match with cti.reference are checked by default.
+	require.NoError(t, v.Validate(instance(`{"topic": "cti.x.y.topic.v1.0~x.y.unknown.v1.0"}`)))
+
+	v, err = MakeMetadataValidator(r, WithDereferencedReferences())
+	require.NoError(t, err)
+	require.NoError(t, v.Validate(instance(`{"topic": "cti.x.y.topic.v1.0~x.y.public.v1.0", "any": "cti.x.y.topic.v1.0"}`)))
+
+	err = v.Validate(instance(`{"topic": "cti.x.y.topic.v1.0~x.y.unknown.v1.0"}`))
+	require.ErrorIs(t, err, ErrReferenceNotFound)
+	require.EqualError(t, err, "cti.x.y.subscription.v1.0~a.b.main.v1.0@.topic: referenced entity is not found: cti.x.y.topic.v1.0~x.y.unknown.v1.0")
+
+	err = v.Validate(instance(`{"any": "cti.x.y.unknown.v1.0"}`))
+	require.ErrorIs(t, err, ErrReferenceNotFound)
+
+	err = v.Validate(instance(`{"topic": "cti.x.y.topic.v1.0~x.y.private.v1.0"}`))
+	require.ErrorIs(t, err, ErrReferenceInaccessible)
+	require.ErrorContains(t, err, "cti.x.y.topic.v1.0~x.y.private.v1.0 is private")
+
+	// Private entities are accessible within the same package.
+	require.NoError(t, v.Validate(&metadata.Entity{Cti: "cti.x.y.subscription.v1.0~x.y.main.v1.0",
+		Values: []byte(`{"topic": "cti.x.y.topic.v1.0~x.y.private.v1.0"}`)}))
+}
@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single problem reported by a validation rule.
+type Issue struct {
+	Cti      string
+	Rule     string
+	Severity Severity
+	Message  string
+	// Fixes are optional machine-applicable edits that resolve the issue.
+	Fixes []Fix
+}
+
+func (i Issue) Error() string {
+	if i.Cti == "" {
+		// Package-wide issue, e.g. reported by a policy.
+		return i.Message
+	}
+	return fmt.Sprintf("%s: %s", i.Cti, i.Message)
+}
+
+// Rule is a custom validation rule that is executed for every entity alongside the core validation.
+type Rule interface {
+	// Name returns a unique name of the rule.
+	Name() string
+	// Validate checks the entity and returns a list of found issues.
+	Validate(ctx context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue
+}
+
+type ruleFunc struct {
+	name string
+	fn   func(ctx context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue
+}
+
+func (f *ruleFunc) Name() string {
+	return f.name
+}
+
+func (f *ruleFunc) Validate(ctx context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	return f.fn(ctx, r, entity)
+}
+
+// NewRuleFunc wraps a plain function into a Rule with the specified name.
+func NewRuleFunc(
+	name string, fn func(ctx context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue,
+) Rule {
+	return &ruleFunc{name: name, fn: fn}
+}
+
+// RuleRegistry holds an ordered set of uniquely named validation rules.
+type RuleRegistry struct {
+	rules []Rule
+	names map[string]struct{}
+}
+
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{
+		names: make(map[string]struct{}),
+	}
+}
+
+func (rr *RuleRegistry) Register(rule Rule) error {
+	if rule == nil {
+		return fmt.Errorf("rule is nil")
+	}
+	name := rule.Name()
+	if name == "" {
+		return fmt.Errorf("rule name is empty")
+	}
+	if _, ok := rr.names[name]; ok {
+		return fmt.Errorf("duplicate rule %s", name)
+	}
+	rr.names[name] = struct{}{}
+	rr.rules = append(rr.rules, rule)
+	return nil
+}
+
+func (rr *RuleRegistry) MustRegister(rule Rule) {
+	if err := rr.Register(rule); err != nil {
+		panic(err)
+	}
+}
+
+// Rules returns registered rules in the order of registration.
+func (rr *RuleRegistry) Rules() []Rule {
+	rules := make([]Rule, len(rr.rules))
+	copy(rules, rr.rules)
+	return rules
+}
+
+func (rr *RuleRegistry) run(ctx context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+	var issues []Issue
+	for _, rule := range rr.rules {
+		for _, issue := range rule.Validate(ctx, r, entity) {
+			if issue.Rule == "" {
+				issue.Rule = rule.Name()
+			}
+			if issue.Cti == "" {
+				issue.Cti = entity.Cti
+			}
+			if issue.Severity == "" {
+				issue.Severity = SeverityError
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_RuleRegistry(t *testing.T) {
+	noop := func(context.Context, *collector.MetadataRegistry, *metadata.Entity) []Issue { return nil }
+
+	rr := NewRuleRegistry()
+	require.NoError(t, rr.Register(NewRuleFunc("first", noop)))
+	require.NoError(t, rr.Register(NewRuleFunc("second", noop)))
+	require.ErrorContains(t, rr.Register(NewRuleFunc("first", noop)), "duplicate rule first")
+	require.ErrorContains(t, rr.Register(NewRuleFunc("", noop)), "rule name is empty")
+
+	rules := rr.Rules()
+	require.Len(t, rules, 2)
+	require.Equal(t, "first", rules[0].Name())
+	require.Equal(t, "second", rules[1].Name())
+}
+
+func Test_ValidateAllWithRules(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.forbidden_entity.v1.0",
+		Schema: []byte(`{"type": "object"}`),
+	}))
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti:    "cti.x.y.allowed_entity.v1.0",
+		Schema: []byte(`{"type": "object"}`),
+	}))
+
+	forbidden := NewRuleFunc("forbidden-names", func(_ context.Context, _ *collector.MetadataRegistry, e *metadata.Entity) []Issue {
+		if strings.Contains(e.Cti, "forbidden") {
+			return []Issue{{Message: "forbidden entity name"}}
+		}
+		return nil
+	})
+	warning := NewRuleFunc("warning", func(_ context.Context, _ *collector.MetadataRegistry, e *metadata.Entity) []Issue {
+		return []Issue{{Severity: SeverityWarning, Message: "just a warning"}}
+	})
+
+	rr := NewRuleRegistry()
+	rr.MustRegister(forbidden)
+	rr.MustRegister(warning)
+
+	v, err := MakeMetadataValidator(r, WithRules(rr))
+	require.NoError(t, err)
+
+	issues := v.ValidateRules(context.Background(), r.Index["cti.x.y.forbidden_entity.v1.0"])
+	require.Equal(t, []Issue{
+		{Cti: "cti.x.y.forbidden_entity.v1.0", Rule: "forbidden-names", Severity: SeverityError, Message: "forbidden entity name"},
+		{Cti: "cti.x.y.forbidden_entity.v1.0", Rule: "warning", Severity: SeverityWarning, Message: "just a warning"},
+	}, issues)
+
+	err = v.ValidateAll()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "cti.x.y.forbidden_entity.v1.0: forbidden entity name")
+	require.NotContains(t, err.Error(), "just a warning")
+}
+
+func Test_ValidateAllContext(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, id := range []string{"cti.x.y.first.v1.0", "cti.x.y.second.v1.0"} {
+		require.NoError(t, r.Add("entities.raml", &metadata.Entity{Cti: id, Schema: []byte(`{"type": "object"}`)}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var validated []string
+	rr := NewRuleRegistry()
+	rr.MustRegister(NewRuleFunc("cancel", func(ctx context.Context, _ *collector.MetadataRegistry, e *metadata.Entity) []Issue {
+		require.NoError(t, ctx.Err())
+		validated = append(validated, e.Cti)
+		cancel()
+		return nil
+	}))
+
+	v, err := MakeMetadataValidator(r, WithRules(rr))
+	require.NoError(t, err)
+	require.ErrorIs(t, v.ValidateAllContext(ctx), context.Canceled)
+	require.Equal(t, []string{"cti.x.y.first.v1.0"}, validated)
+}
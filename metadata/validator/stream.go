@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// LineError is a validation error of a single line of NDJSON stream.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateStream validates NDJSON stream of instance values against the merged schema of the type.
+// Lines are read and validated one by one, so the stream is never loaded into memory entirely.
+// Empty lines are skipped. Returned error is not nil only if the stream cannot be validated at all.
+func (v *MetadataValidator) ValidateStream(typeCti string, r io.Reader) ([]LineError, error) {
+	typ, ok := v.registry.Types[typeCti]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", typeCti)
+	}
+	mergedSchema, err := v.schemas.GetMergedCtiSchema(typ.Cti)
+	if err != nil {
+		return nil, fmt.Errorf("get merged schema of %s: %w", typ.Cti, err)
+	}
+	schema, err := v.compileMerged(mergedSchema)
+	if err != nil {
+		return nil, fmt.Errorf("compile schema of %s: %w", typ.Cti, err)
+	}
+
+	var lineErrs []LineError
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, readErr := br.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return lineErrs, fmt.Errorf("read line %d: %w", line, readErr)
+		}
+		if data = bytes.TrimSpace(data); len(data) != 0 {
+			if err := validateLine(schema, data); err != nil {
+				lineErrs = append(lineErrs, LineError{Line: line, Err: err})
+			}
+		}
+		if readErr != nil {
+			return lineErrs, nil
+		}
+	}
+}
+
+func validateLine(schema *gojsonschema.Schema, data []byte) error {
+	res, err := schema.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return err
+	}
+	if !res.Valid() {
+		return newSchemaViolationError(res.Errors(), false)
+	}
+	return nil
+}
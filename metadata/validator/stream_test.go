@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateStream(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.sample_entity.v1.0",
+		Schema: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema",
+			"$ref": "#/definitions/SampleEntity",
+			"definitions": {
+				"SampleEntity": {
+					"type": "object",
+					"properties": {"name": {"type": "string"}},
+					"required": ["name"]
+				}
+			}
+		}`),
+	}))
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+
+	stream := strings.Join([]string{
+		`{"name": "first"}`,
+		`{"name": 1}`,
+		``,
+		`{"name": "fourth"}`,
+		`{}`,
+		`not json`,
+	}, "\n")
+
+	lineErrs, err := v.ValidateStream("cti.x.y.sample_entity.v1.0", strings.NewReader(stream))
+	require.NoError(t, err)
+	require.Len(t, lineErrs, 3)
+	require.Equal(t, 2, lineErrs[0].Line)
+	require.Contains(t, lineErrs[0].Error(), "line 2: name: Invalid type")
+	var violationErr *SchemaViolationError
+	require.ErrorAs(t, lineErrs[0], &violationErr)
+	require.ErrorIs(t, lineErrs[0], ErrSchemaViolation)
+	require.Len(t, violationErr.Violations, 1)
+	require.Equal(t, 5, lineErrs[1].Line)
+	require.Contains(t, lineErrs[1].Error(), "name is required")
+	require.Equal(t, 6, lineErrs[2].Line)
+
+	_, err = v.ValidateStream("cti.x.y.unknown.v1.0", strings.NewReader(stream))
+	require.ErrorContains(t, err, "type cti.x.y.unknown.v1.0 not found")
+}
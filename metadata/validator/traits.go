@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/acronis/go-cti/metadata"
+)
+
+// ValidateTraits validates traits of the entity merged with traits of its ancestors (see GetMergedTraits)
+// against the traits schema of the nearest ancestor that defines it (see FindTraitsSchemaInChain).
+// It is called by Validate for every entity with traits.
+func (v *MetadataValidator) ValidateTraits(entity *metadata.Entity) error {
+	owner, ok := v.registry.FindTraitsSchemaInChain(entity.Cti)
+	if !ok {
+		return fmt.Errorf("%s %w", entity.Cti, ErrTraitsSchemaMissing)
+	}
+	traits, err := v.registry.GetMergedTraits(entity.Cti)
+	if err != nil {
+		return fmt.Errorf("%s get merged traits: %w", entity.Cti, err)
+	}
+	res, err := v.compiled.validateValues(owner.TraitsSchema, gojsonschema.NewGoLoader(traits))
+	if err != nil {
+		return fmt.Errorf("%s validate traits against schema of %s: %w", entity.Cti, owner.Cti, err)
+	}
+	if !res.Valid() {
+		return fmt.Errorf("%s contains invalid traits: %w", entity.Cti, newSchemaViolationError(res.Errors(), true))
+	}
+	return nil
+}
+
+// validateTraitPlaceholders checks that attributes referenced by placeholders of trait values of the type
+// (e.g. ${@display_name}) are properties of its merged schema, so they are resolvable against instances of the type
+// (see metadata.Entity.ResolveTraits).
+func (v *MetadataValidator) validateTraitPlaceholders(current *metadata.Entity) error {
+	attrs, err := metadata.TraitPlaceholders(current.Traits)
+	if err != nil {
+		return fmt.Errorf("%s: %w", current.Cti, err)
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	if current.Schema == nil {
+		return fmt.Errorf("%s: trait placeholders are allowed only in types", current.Cti)
+	}
+	schema, err := v.schemas.GetMergedCtiSchema(current.Cti)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attrs {
+		if !hasSchemaProperty(schema, strings.Split(attr, ".")) {
+			return fmt.Errorf("%s: trait placeholder ${@%s} references unknown attribute", current.Cti, attr)
+		}
+	}
+	return nil
+}
+
+// hasSchemaProperty reports whether the schema has the nested property. Members of unions are looked up in turn.
+func hasSchemaProperty(schema map[string]any, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+	if members, ok := schema["anyOf"].([]any); ok {
+		for _, member := range members {
+			if m, ok := member.(map[string]any); ok && hasSchemaProperty(m, path) {
+				return true
+			}
+		}
+		return false
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	property, ok := properties[path[0]].(map[string]any)
+	if !ok {
+		return false
+	}
+	return hasSchemaProperty(property, path[1:])
+}
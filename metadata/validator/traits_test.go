@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_ValidateTraitReferences(t *testing.T) {
+	makeRegistry := func(severity string) *collector.MetadataRegistry {
+		r := collector.NewMetadataRegistry()
+		for _, e := range []*metadata.Entity{
+			{Cti: "cti.x.y.severity.v1.0", Schema: []byte(`{}`)},
+			{Cti: "cti.x.y.severity.v1.0~x.y.low.v1.0", Values: []byte(`{}`)},
+			{Cti: "cti.x.y.priority.v1.0", Schema: []byte(`{}`)},
+			{Cti: "cti.x.y.priority.v1.0~x.y.low.v1.0", Values: []byte(`{}`)},
+			{Cti: "cti.x.y.alert.v1.0", Schema: []byte(`{}`),
+				TraitsSchema: []byte(`{"type": "object", "properties": {"severity": {"type": "string"}}}`),
+				TraitsAnnotations: map[metadata.GJsonPath]metadata.Annotations{
+					".severity": {Reference: "cti.x.y.severity.v1.0"},
+				}},
+			{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"severity": "` + severity + `"}`)},
+		} {
+			require.NoError(t, r.Add("entities.raml", e))
+		}
+		return r
+	}
+
+	for _, tc := range []struct {
+		severity string
+		wantErr  string
+	}{
+		{severity: "cti.x.y.severity.v1.0~x.y.low.v1.0"},
+		{
+			severity: "cti.x.y.severity.v1.0~x.y.mid.v1.0",
+			wantErr: "cti.x.y.alert.v1.0~x.y.disk.v1.0@.severity: " +
+				"trait value cti.x.y.severity.v1.0~x.y.mid.v1.0 is not a member of cti.x.y.severity.v1.0",
+		},
+		{
+			severity: "cti.x.y.priority.v1.0~x.y.low.v1.0",
+			wantErr: "cti.x.y.alert.v1.0~x.y.disk.v1.0@.severity: " +
+				"trait value cti.x.y.priority.v1.0~x.y.low.v1.0 doesn't match",
+		},
+	} {
+		t.Run(tc.severity, func(t *testing.T) {
+			r := makeRegistry(tc.severity)
+			v, err := MakeMetadataValidator(r)
+			require.NoError(t, err)
+			err = v.Validate(r.Index["cti.x.y.alert.v1.0~x.y.disk.v1.0"])
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_ValidateTraits(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{Cti: "cti.x.y.alert.v1.0", Schema: []byte(`{}`), TraitsSchema: []byte(`{"type": "object", "properties": {"severity": {"type": "string"}}}`)},
+		// The nearest ancestor with traits schema takes precedence over the base type.
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"severity": "low"}`),
+			TraitsSchema: []byte(`{"type": "object", "properties": {"severity": {"type": "string"}, "limit": {"type": "integer"}}, "required": ["severity", "limit"]}`)},
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.full.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"limit": 10}`)},
+		{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.empty.v1.0", Schema: []byte(`{}`), Traits: []byte(`{"limit": "none"}`)},
+		{Cti: "cti.x.y.event.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.x.y.event.v1.0~x.y.created.v1.0", Schema: []byte(`{}`), Traits: []byte(`{}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+
+	// Traits are merged with the traits of the parent to satisfy the required properties.
+	require.NoError(t, v.ValidateTraits(r.Index["cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.full.v1.0"]))
+	require.EqualError(t, v.ValidateTraits(r.Index["cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.empty.v1.0"]),
+		"cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.empty.v1.0 contains invalid traits: Invalid type. Expected: integer, given: string")
+	require.EqualError(t, v.ValidateTraits(r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"]),
+		"cti.x.y.event.v1.0~x.y.created.v1.0 type is derived from type that does not define traits")
+	require.ErrorIs(t, v.ValidateTraits(r.Index["cti.x.y.alert.v1.0~x.y.disk.v1.0~x.y.empty.v1.0"]), ErrSchemaViolation)
+	require.ErrorIs(t, v.ValidateTraits(r.Index["cti.x.y.event.v1.0~x.y.created.v1.0"]), ErrTraitsSchemaMissing)
+}
+
+func Test_ValidateTraitPlaceholders(t *testing.T) {
+	r := collector.NewMetadataRegistry()
+	require.NoError(t, r.Add("entities.raml", &metadata.Entity{
+		Cti: "cti.x.y.alert.v1.0",
+		Schema: []byte(`{"$ref": "#/definitions/Alert", "definitions": {"Alert": {
+			"type": "object", "properties": {"display_name": {"type": "string"},
+			"owner": {"type": "object", "properties": {"name": {"type": "string"}}}}}}}`),
+		TraitsSchema: []byte(`{"type": "object", "properties": {"title": {"type": "string"}}}`),
+	}))
+	valid := &metadata.Entity{Cti: "cti.x.y.alert.v1.0~x.y.disk.v1.0", Schema: []byte(`{"$ref": "#/definitions/Disk", "definitions": {"Disk": {"type": "object"}}}`),
+		Traits: []byte(`{"title": "${@display_name} by ${@owner.name}"}`)}
+	invalid := &metadata.Entity{Cti: "cti.x.y.alert.v1.0~x.y.cpu.v1.0", Schema: []byte(`{"$ref": "#/definitions/Cpu", "definitions": {"Cpu": {"type": "object"}}}`),
+		Traits: []byte(`{"title": "${@owner.email}"}`)}
+	require.NoError(t, r.Add("entities.raml", valid))
+	require.NoError(t, r.Add("entities.raml", invalid))
+
+	v, err := MakeMetadataValidator(r)
+	require.NoError(t, err)
+	require.NoError(t, v.Validate(valid))
+	require.EqualError(t, v.Validate(invalid), "cti.x.y.alert.v1.0~x.y.cpu.v1.0: trait placeholder ${@owner.email} references unknown attribute")
+}
@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+const (
+	UniqueIDRuleName = "unique-id"
+)
+
+// UniquenessScope defines which instances are compared by the unique ID rule.
+type UniquenessScope string
+
+const (
+	// UniquenessScopePackage compares instances declared by the package only.
+	UniquenessScopePackage UniquenessScope = "package"
+	// UniquenessScopeRegistry compares instances of the package with all instances of the registry,
+	// including instances of the dependencies.
+	UniquenessScopeRegistry UniquenessScope = "registry"
+)
+
+// NewUniqueIDRule makes a rule that reports instances declared by the package which values of properties
+// annotated with cti.id duplicate the values of other instances of the same type family, i.e. instances
+// of the type that declares the annotation and of its descendants. Instances without the value are skipped.
+func NewUniqueIDRule(packageID string, scope UniquenessScope) Rule {
+	return NewRuleFunc(UniqueIDRuleName,
+		func(_ context.Context, r *collector.MetadataRegistry, entity *metadata.Entity) []Issue {
+			if entity.Values == nil || !metadata.IsDeclaredBy(entity.Cti, packageID) {
+				return nil
+			}
+			return checkUniqueIDs(r, entity, packageID, scope)
+		})
+}
+
+func checkUniqueIDs(r *collector.MetadataRegistry, entity *metadata.Entity, packageID string, scope UniquenessScope) []Issue {
+	families := idFamilies(r, metadata.GetParentCti(entity.Cti))
+	keys := make([]string, 0, len(families))
+	for key := range families {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+
+	var issues []Issue
+	for _, key := range keys {
+		path := metadata.GJsonPath(key)
+		value := path.GetValue(entity.Values)
+		if !value.Exists() || value.Type == gjson.Null {
+			continue
+		}
+		prefix := families[path] + "~"
+		var duplicates []string
+		for id, instance := range r.Instances {
+			if id == entity.Cti || !strings.HasPrefix(id, prefix) {
+				continue
+			}
+			if scope != UniquenessScopeRegistry && !metadata.IsDeclaredBy(id, packageID) {
+				continue
+			}
+			if other := path.GetValue(instance.Values); other.Exists() && other.Raw == value.Raw {
+				duplicates = append(duplicates, id)
+			}
+		}
+		if len(duplicates) == 0 {
+			continue
+		}
+		sort.Strings(duplicates)
+		issues = append(issues, Issue{
+			Message: fmt.Sprintf("value %s of %s annotated with %s is not unique, it is also used by %s",
+				value.Raw, key, metadata.ID, strings.Join(duplicates, ", ")),
+		})
+	}
+	return issues
+}
+
+// idFamilies returns properties annotated with cti.id in the type and its ancestors mapped to the topmost type
+// that declares the annotation, i.e. the root of the type family where the values must be unique.
+func idFamilies(r *collector.MetadataRegistry, id string) map[metadata.GJsonPath]string {
+	res := make(map[metadata.GJsonPath]string)
+	for {
+		typ, ok := r.Index[id]
+		if !ok {
+			break
+		}
+		for key, annotation := range typ.Annotations {
+			if annotation.ID != nil && *annotation.ID {
+				res[key] = id
+			}
+		}
+		parentCti := metadata.GetParentCti(id)
+		if parentCti == id {
+			break
+		}
+		id = parentCti
+	}
+	return res
+}
@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-cti/metadata"
+	"github.com/acronis/go-cti/metadata/collector"
+)
+
+func Test_UniqueIDRule(t *testing.T) {
+	yes := true
+	r := collector.NewMetadataRegistry()
+	for _, e := range []*metadata.Entity{
+		{
+			Cti:         "cti.a.p.region.v1.0",
+			Schema:      []byte(`{}`),
+			Annotations: map[metadata.GJsonPath]metadata.Annotations{".code": {ID: &yes}},
+		},
+		{Cti: "cti.a.p.region.v1.0~a.p.cloud.v1.0", Schema: []byte(`{}`)},
+		{Cti: "cti.a.p.region.v1.0~a.p.eu.v1.0", Values: []byte(`{"code": "eu"}`)},
+		{Cti: "cti.a.p.region.v1.0~a.p.us.v1.0", Values: []byte(`{"code": "us"}`)},
+		{Cti: "cti.a.p.region.v1.0~x.y.europe.v1.0", Values: []byte(`{"code": "eu"}`)},
+		{Cti: "cti.a.p.region.v1.0~a.p.cloud.v1.0~x.y.eu_cloud.v1.0", Values: []byte(`{"code": "eu"}`)},
+		{Cti: "cti.a.p.region.v1.0~x.y.unknown.v1.0", Values: []byte(`{"code": null}`)},
+		{Cti: "cti.a.p.region.v1.0~x.y.other.v1.0", Values: []byte(`{"code": null}`)},
+	} {
+		require.NoError(t, r.Add("entities.raml", e))
+	}
+	ctx := context.Background()
+
+	rule := NewUniqueIDRule("x.y", UniquenessScopePackage)
+	require.Equal(t, []Issue{{
+		Message: `value "eu" of .code annotated with cti.id is not unique, it is also used by cti.a.p.region.v1.0~x.y.europe.v1.0`,
+	}}, rule.Validate(ctx, r, r.Index["cti.a.p.region.v1.0~a.p.cloud.v1.0~x.y.eu_cloud.v1.0"]))
+	require.Empty(t, rule.Validate(ctx, r, r.Index["cti.a.p.region.v1.0~x.y.unknown.v1.0"]))
+	// Instances of dependencies are not reported.
+	require.Empty(t, rule.Validate(ctx, r, r.Index["cti.a.p.region.v1.0~a.p.eu.v1.0"]))
+
+	rule = NewUniqueIDRule("x.y", UniquenessScopeRegistry)
+	require.Equal(t, []Issue{{
+		Message: `value "eu" of .code annotated with cti.id is not unique, it is also used by ` +
+			`cti.a.p.region.v1.0~a.p.cloud.v1.0~x.y.eu_cloud.v1.0, cti.a.p.region.v1.0~a.p.eu.v1.0`,
+	}}, rule.Validate(ctx, r, r.Index["cti.a.p.region.v1.0~x.y.europe.v1.0"]))
+	require.Empty(t, rule.Validate(ctx, r, r.Index["cti.a.p.region.v1.0~a.p.cloud.v1.0"]))
+}
@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"log/slog"
+	"sort"
 
 	"github.com/xeipuuv/gojsonschema"
 
@@ -16,40 +19,226 @@ import (
 
 const (
 	TrueStr = "true"
+
+	// CoreRuleName is the rule name of issues reported by the core validation to policies.
+	CoreRuleName = "core"
 )
 
 type MetadataValidator struct {
 	registry  *collector.MetadataRegistry
 	ctiParser *cti.Parser
+	rules     *RuleRegistry
+	policies  []Policy
+	packageID string
+	baseDir   string
+	schemas   *merger.SchemaCache
+	// compiled are compiled schemas shared by entities with identical schemas.
+	compiled *CompiledSchemaCache
+	onDone   func(cti string)
+	// strictInheritance makes the validator reject types that widen constraints of their parents.
+	strictInheritance bool
+	// dereferenceReferences makes the validator check that entities referenced by instances exist and are accessible.
+	dereferenceReferences bool
+	// releasedBaseline holds the previous versions of types which breaking changes are rejected if they are released.
+	releasedBaseline *collector.MetadataRegistry
+	// maxIssues limits the number of errors collected by ValidateAll. Zero means no limit.
+	maxIssues int
+	// simplifyUnions makes the validator normalize unions of merged schemas before compiling them.
+	simplifyUnions bool
+	// requirePropertyDescriptions makes the validator reject public types with undocumented properties.
+	requirePropertyDescriptions bool
+}
+
+type Option func(*MetadataValidator) error
+
+// WithRules makes the validator run the rules from the registry alongside the core validation.
+func WithRules(rr *RuleRegistry) Option {
+	return func(v *MetadataValidator) error {
+		if rr == nil {
+			return fmt.Errorf("rule registry is nil")
+		}
+		v.rules = rr
+		return nil
+	}
+}
+
+// WithProgress makes the validator call onDone after each entity is validated by ValidateAll.
+func WithProgress(onDone func(cti string)) Option {
+	return func(v *MetadataValidator) error {
+		v.onDone = onDone
+		return nil
+	}
+}
+
+// WithStrictInheritance makes the validator reject types whose schemas widen constraints inherited from their parents,
+// e.g. a child maxLength larger than the one of the parent. See merger.CheckNarrowing.
+func WithStrictInheritance() Option {
+	return func(v *MetadataValidator) error {
+		v.strictInheritance = true
+		return nil
+	}
+}
+
+// WithMaxIssues makes ValidateAll stop after the number of errors is found. Zero means no limit, which is the default.
+// Warnings are not counted.
+func WithMaxIssues(n int) Option {
+	return func(v *MetadataValidator) error {
+		if n < 0 {
+			return fmt.Errorf("maximum number of issues must not be negative: %d", n)
+		}
+		v.maxIssues = n
+		return nil
+	}
 }
 
-func MakeMetadataValidator(r *collector.MetadataRegistry) *MetadataValidator {
-	return &MetadataValidator{
+// WithSimplifiedUnions makes the validator normalize unions (anyOf) of merged schemas with jsonschema.SimplifyUnions
+// before compiling them, so values of types with deeply nested unions are validated faster.
+func WithSimplifiedUnions() Option {
+	return func(v *MetadataValidator) error {
+		v.simplifyUnions = true
+		return nil
+	}
+}
+
+func MakeMetadataValidator(r *collector.MetadataRegistry, opts ...Option) (*MetadataValidator, error) {
+	v := &MetadataValidator{
 		ctiParser: cti.NewParser(),
 		registry:  r,
+		rules:     NewRuleRegistry(),
+		schemas:   merger.NewSchemaCache(r),
+		compiled:  NewCompiledSchemaCache(),
+	}
+	for _, opt := range opts {
+		if err := opt(v); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
 	}
+	return v, nil
 }
 
+// RegisterRule adds a custom rule to the validator.
+func (v *MetadataValidator) RegisterRule(rule Rule) error {
+	return v.rules.Register(rule)
+}
+
+// ValidateAll validates all entities of the registry in the order of their CTIs and evaluates the policies.
+// All errors are collected up to the limit of WithMaxIssues and returned as *AggregateError.
 func (v *MetadataValidator) ValidateAll() error {
-	st := stacktrace.StackTrace{}
-	for _, entity := range v.registry.Index {
+	return v.ValidateAllContext(context.Background())
+}
+
+// ValidateAllContext is like ValidateAll, but the context is passed to the rules and the policies,
+// and the validation stops with the error of the context when the context is done.
+func (v *MetadataValidator) ValidateAllContext(ctx context.Context) error {
+	agg := &AggregateError{trace: &stacktrace.StackTrace{}}
+	full := func() bool {
+		return v.maxIssues > 0 && len(agg.Errors) >= v.maxIssues
+	}
+	appendError := func(err error, wrapped *stacktrace.StackTrace) {
+		if full() {
+			agg.Truncated = true
+			return
+		}
+		agg.Errors = append(agg.Errors, err)
+		_ = agg.trace.Append(wrapped)
+	}
+	appendIssue := func(issue Issue) {
+		if issue.Severity == SeverityWarning {
+			slog.Warn(issue.Message, slog.String("cti", issue.Cti), slog.String("rule", issue.Rule))
+			return
+		}
+		appendError(issue, stacktrace.NewWrapped("validation failed", issue,
+			stacktrace.WithInfo("cti", issue.Cti), stacktrace.WithInfo("rule", issue.Rule), stacktrace.WithType("validation")))
+	}
+
+	ids := make([]string, 0, len(v.registry.Index))
+	for id := range v.registry.Index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var diagnostics []Issue
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if full() {
+			agg.Truncated = true
+			break
+		}
+		entity := v.registry.Index[id]
 		if err := v.Validate(entity); err != nil {
-			_ = st.Append(stacktrace.NewWrapped("validation failed", err, stacktrace.WithInfo("cti", entity.Cti), stacktrace.WithType("validation")))
+			appendError(err, stacktrace.NewWrapped("validation failed", err, stacktrace.WithInfo("cti", entity.Cti), stacktrace.WithType("validation")))
+			if len(v.policies) != 0 {
+				diagnostics = append(diagnostics, Issue{
+					Cti: entity.Cti, Rule: CoreRuleName, Severity: SeverityError, Message: err.Error(),
+				})
+			}
+		}
+		exampleErrs, err := v.ValidateExamples(entity)
+		if err != nil {
+			appendError(err, stacktrace.NewWrapped("validation failed", err, stacktrace.WithInfo("cti", entity.Cti), stacktrace.WithType("validation")))
+		}
+		for _, exampleErr := range exampleErrs {
+			opts := []stacktrace.Option{
+				stacktrace.WithInfo("cti", entity.Cti), stacktrace.WithLocation(exampleErr.Location.Path), stacktrace.WithType("validation"),
+			}
+			if exampleErr.Location.Line != 0 {
+				opts = append(opts, stacktrace.WithPosition(&stacktrace.Position{Line: exampleErr.Location.Line, Column: exampleErr.Location.Column}))
+			}
+			appendError(exampleErr, stacktrace.NewWrapped("validation failed", exampleErr, opts...))
+			if len(v.policies) != 0 {
+				diagnostics = append(diagnostics, Issue{
+					Cti: entity.Cti, Rule: CoreRuleName, Severity: SeverityError, Message: exampleErr.Error(),
+				})
+			}
+		}
+		for _, issue := range v.ValidateRules(ctx, entity) {
+			appendIssue(issue)
+			diagnostics = append(diagnostics, issue)
+		}
+		if v.onDone != nil {
+			v.onDone(entity.Cti)
 		}
 	}
-	if len(st.List) > 0 {
-		return &st
+
+	if len(v.policies) != 0 && !agg.Truncated {
+		issues, err := v.EvaluatePolicies(ctx, diagnostics)
+		if err != nil {
+			appendError(err, stacktrace.NewWrapped("policy evaluation failed", err, stacktrace.WithType("policy")))
+		}
+		for _, issue := range issues {
+			appendIssue(issue)
+		}
+	}
+
+	if len(agg.Errors) > 0 {
+		return agg
 	}
 
 	return nil
 }
 
+// ValidateRules runs registered custom rules against the entity.
+func (v *MetadataValidator) ValidateRules(ctx context.Context, entity *metadata.Entity) []Issue {
+	return v.rules.run(ctx, v.registry, entity)
+}
+
 func (v *MetadataValidator) Validate(current *metadata.Entity) error {
 	// TODO: Pre-parse all CTIs into expressions
 	currentCtiExpr, err := v.ctiParser.Parse(current.Cti)
 	if err != nil {
 		return fmt.Errorf("%s %s", current.Cti, err.Error())
 	}
+	if err := validateExtraAnnotations(current); err != nil {
+		return err
+	}
+	if err := v.validateLifecycle(current); err != nil {
+		return err
+	}
+	if err := v.validatePropertyDescriptions(current); err != nil {
+		return err
+	}
 
 	parentCti := metadata.GetParentCti(current.Cti)
 	if parentCti == current.Cti {
@@ -80,13 +269,13 @@ func (v *MetadataValidator) Validate(current *metadata.Entity) error {
 		if parent.Schema == nil {
 			return fmt.Errorf("%s instance is derived from non-type CTI", current.Cti)
 		}
-		mergedSchema, err := merger.GetMergedCtiSchema(parent.Cti, v.registry)
+		mergedSchema, err := v.schemas.GetMergedCtiSchema(parent.Cti)
 		if err != nil {
 			return err
 		}
 		values := []byte(current.Values)
-		if err := validateGoJsonValues(mergedSchema, values); err != nil {
-			return fmt.Errorf("%s contains invalid values: %s", current.Cti, err)
+		if err := v.validateValues(mergedSchema, values); err != nil {
+			return fmt.Errorf("%s contains invalid values: %w", current.Cti, err)
 		}
 		if parent.Annotations != nil {
 			// TODO: Ensure correct cti.id field is used
@@ -113,10 +302,21 @@ func (v *MetadataValidator) Validate(current *metadata.Entity) error {
 							if err != nil {
 								return fmt.Errorf("%s@%s: %s in %s", current.Cti, key, err.Error(), val.Str)
 							}
+							if v.dereferenceReferences {
+								if err := v.dereference(current, key, val.Str); err != nil {
+									return err
+								}
+							}
 						}
 					} else {
 						return fmt.Errorf("%s@%s: failed to parse cti.reference. Reason: %s", current.Cti, key, err.Error())
 					}
+				} else if ref == TrueStr && v.dereferenceReferences {
+					for _, val := range key.GetValue(values).Array() {
+						if err := v.dereference(current, key, val.Str); err != nil {
+							return err
+						}
+					}
 				}
 				// if l10n := annotation.L10N; l10n != nil {
 				// 	fmt.Printf("key: [%s][cti.l10n]: %t\n", key, *l10n)
@@ -127,18 +327,14 @@ func (v *MetadataValidator) Validate(current *metadata.Entity) error {
 		}
 	}
 	if current.Traits != nil {
-		id := metadata.GetBaseCti(parentCti)
-		base, ok := v.registry.Index[id]
-		if !ok {
-			return fmt.Errorf("%s failed to find base type", current.Cti)
+		if err := v.ValidateTraits(current); err != nil {
+			return err
 		}
-		// FIXME: Need to obtain traits from the parent
-		if base.TraitsSchema == nil {
-			return fmt.Errorf("%s type is derived from type that does not define traits", current.Cti)
+		if err := v.validateTraitReferences(current); err != nil {
+			return err
 		}
-		schema, values := []byte(base.TraitsSchema), []byte(current.Traits)
-		if err := validateBytesJsonValues(schema, values); err != nil {
-			return fmt.Errorf("%s contains invalid values: %s", current.Cti, err)
+		if err := v.validateTraitPlaceholders(current); err != nil {
+			return err
 		}
 	}
 	if current.Schema != nil {
@@ -146,6 +342,11 @@ func (v *MetadataValidator) Validate(current *metadata.Entity) error {
 		if err := validateBytesJsonSchema(schema); err != nil {
 			return fmt.Errorf("%s contains invalid schema: %s", current.Cti, err)
 		}
+		if v.strictInheritance && parent.Schema != nil {
+			if err := v.validateNarrowing(current, parent); err != nil {
+				return err
+			}
+		}
 	}
 	if current.TraitsSchema != nil {
 		schema := []byte(current.TraitsSchema)
@@ -191,6 +392,57 @@ func (v *MetadataValidator) Validate(current *metadata.Entity) error {
 	return nil
 }
 
+// validateNarrowing checks that the schema of the type only narrows constraints of the merged schema of its parent.
+func (v *MetadataValidator) validateNarrowing(current, parent *metadata.Entity) error {
+	var schema map[string]any
+	if err := json.Unmarshal(current.Schema, &schema); err != nil {
+		return fmt.Errorf("%s: unmarshal schema: %w", current.Cti, err)
+	}
+	schema, err := merger.ExtractSchemaDefinition(schema)
+	if err != nil {
+		return fmt.Errorf("%s: extract schema definition: %w", current.Cti, err)
+	}
+	parentSchema, err := v.schemas.GetMergedCtiSchema(parent.Cti)
+	if err != nil {
+		return err
+	}
+	widenings := merger.CheckNarrowing(schema, parentSchema)
+	if len(widenings) == 0 {
+		return nil
+	}
+	errs := make([]error, len(widenings))
+	for i, w := range widenings {
+		w.Cti, w.ParentCti = current.Cti, parent.Cti
+		errs[i] = w
+	}
+	return errors.Join(errs...)
+}
+
+// validateTraitReferences checks that trait values referencing a specific CTI type (e.g. a dictionary type)
+// are known members of that type.
+func (v *MetadataValidator) validateTraitReferences(current *metadata.Entity) error {
+	refs, err := v.registry.GetTraitReferences(current.Cti)
+	if err != nil {
+		return fmt.Errorf("%s: get trait references: %w", current.Cti, err)
+	}
+	for _, ref := range refs {
+		if ref.Reference == TrueStr {
+			continue
+		}
+		expr, err := v.ctiParser.Parse(ref.Reference)
+		if err != nil {
+			return fmt.Errorf("%s@%s: failed to parse cti.reference. Reason: %s", current.Cti, ref.Path, err.Error())
+		}
+		if err := v.matchCti(&expr, ref.Cti); err != nil {
+			return fmt.Errorf("%s@%s: trait value %s", current.Cti, ref.Path, err.Error())
+		}
+		if ref.Entity == nil {
+			return fmt.Errorf("%s@%s: trait value %s is not a member of %s", current.Cti, ref.Path, ref.Cti, ref.Reference)
+		}
+	}
+	return nil
+}
+
 func (v *MetadataValidator) matchCti(ref *cti.Expression, id string) error {
 	val, err := v.ctiParser.Parse(id)
 	if err != nil {
@@ -242,30 +494,22 @@ func validateBytesJsonValues(schema []byte, document []byte) error {
 		return err
 	}
 	if !res.Valid() {
-		errs := res.Errors()
-		str := make([]string, len(res.Errors()))
-		for i, err := range errs {
-			str[i] = err.Description()
-		}
-		return errors.New(strings.Join(str, "\n-"))
+		return newSchemaViolationError(res.Errors(), true)
 	}
 	return nil
 }
 
-func validateGoJsonValues(schema map[string]interface{}, document []byte) error {
-	sl := gojsonschema.NewGoLoader(schema)
-	dl := gojsonschema.NewBytesLoader(document)
-	res, err := gojsonschema.Validate(sl, dl)
+func (v *MetadataValidator) validateValues(schema map[string]interface{}, document []byte) error {
+	compiled, err := v.compileMerged(schema)
+	if err != nil {
+		return err
+	}
+	res, err := compiled.Validate(gojsonschema.NewBytesLoader(document))
 	if err != nil {
 		return err
 	}
 	if !res.Valid() {
-		errs := res.Errors()
-		str := make([]string, len(res.Errors()))
-		for i, err := range errs {
-			str[i] = err.Description()
-		}
-		return errors.New(strings.Join(str, "\n-"))
+		return newSchemaViolationError(res.Errors(), true)
 	}
 	return nil
 }
@@ -42,6 +42,22 @@ func (e *ParseError) Unwrap() error {
 // ErrNotExpression is returned when an input is not a CTI expression.
 var ErrNotExpression = errors.New("not CTI expression")
 
+// Errors that are returned when the input exceeds limits of the parser (see WithMaxLength, WithMaxChainDepth, WithMaxQueryAttributes).
+var (
+	ErrMaxLengthExceeded          = errors.New("maximum length exceeded")
+	ErrMaxChainDepthExceeded      = errors.New("maximum chain depth exceeded")
+	ErrMaxQueryAttributesExceeded = errors.New("maximum number of query attributes exceeded")
+)
+
+// Errors that are returned when the input is not allowed by the parsing method, e.g. ParseIdentifier does not allow
+// wildcards and requires full versions.
+var (
+	ErrWildcardDisabled         = errors.New("wildcard is disabled")
+	ErrVersionMissing           = errors.New("version is missing")
+	ErrMinorVersionMissing      = errors.New("minor part of version is missing")
+	ErrAttributeSelectorMissing = errors.New("attribute selector is absent in input string")
+)
+
 type versionStrategy uint8
 
 const (
@@ -61,6 +77,11 @@ type parserParams struct {
 type Parser struct {
 	allowAnonymousEntity         bool
 	allowedDynamicParameterNames []string
+	legacyCompat                 bool
+	resolver                     Resolver
+	maxLength                    int
+	maxChainDepth                int
+	maxQueryAttributes           int
 }
 
 // ParserOpts represents a parsing options.
@@ -72,11 +93,19 @@ type ParserOpts struct {
 // Available options:
 // - WithAllowAnonymousEntity(b bool) - allows parsing anonymous entity UUID in CTI expressions.
 // - WithAllowedDynamicParameterNames(names ...string) - allows specifying dynamic parameter names that can be used in CTI expressions.
+// - WithLegacyCompat(b bool) - allows parsing identifiers that were accepted by the legacy regexp-based validation.
+// - WithResolver(r Resolver) - allows resolving anonymous entities of parsed CTI expressions.
+// - WithMaxLength(n int), WithMaxChainDepth(n int), WithMaxQueryAttributes(n int) - limit the input, e.g. received from untrusted sources.
 func NewParser(opts ...ParserOption) *Parser {
 	pOpts := makeParserOptions(opts...)
 	return &Parser{
-		allowAnonymousEntity:         pOpts.allowAnonymousEntity,
+		allowAnonymousEntity:         pOpts.allowAnonymousEntity || pOpts.legacyCompat,
 		allowedDynamicParameterNames: pOpts.allowedDynamicParameterNames,
+		legacyCompat:                 pOpts.legacyCompat,
+		resolver:                     pOpts.resolver,
+		maxLength:                    pOpts.maxLength,
+		maxChainDepth:                pOpts.maxChainDepth,
+		maxQueryAttributes:           pOpts.maxQueryAttributes,
 	}
 }
 
@@ -154,7 +183,7 @@ func (p *Parser) ParseAttributeSelector(input string) (Expression, error) {
 		return emptyExpression, err
 	}
 	if expr.AttributeSelector == "" {
-		return emptyExpression, fmt.Errorf("attribute selector is absent in input string")
+		return emptyExpression, ErrAttributeSelectorMissing
 	}
 	return expr, nil
 }
@@ -181,7 +210,31 @@ func (p *Parser) ParseReference(input string) (Expression, error) {
 	})
 }
 
+// ParseQueryAndSelector parses the query and the attribute selector that follow a CTI expression,
+// e.g. `[severity="critical"]@category`. Both parts are optional, but the query must precede the selector.
+// Unlike Parse, it allows to apply the query and the selector to entities matched by expressions with wildcards.
+func (p *Parser) ParseQueryAndSelector(input string) (QueryAttributeSlice, AttributeName, error) {
+	if err := p.checkLength(input); err != nil {
+		return nil, "", &ParseError{Err: err, RawExpression: input}
+	}
+	queryAttributes, s, err := p.parseQueryAttributesIfPresent(input)
+	if err != nil {
+		return nil, "", &ParseError{Err: fmt.Errorf("parse query attributes: %w", err), RawExpression: input}
+	}
+	attributeSelector, s, err := p.parseAttributeSelectorIfPresent(s)
+	if err != nil {
+		return nil, "", &ParseError{Err: fmt.Errorf("parse attribute selector: %w", err), RawExpression: input}
+	}
+	if s != "" {
+		return nil, "", &ParseError{Err: fmt.Errorf("unexpected %q", s), RawExpression: input}
+	}
+	return queryAttributes, attributeSelector, nil
+}
+
 func (p *Parser) parse(input string, params parserParams) (Expression, error) {
+	if err := p.checkLength(input); err != nil {
+		return emptyExpression, &ParseError{Err: err, RawExpression: input}
+	}
 	expr, err := p.parseExpression(input, params)
 	if err != nil {
 		return emptyExpression, &ParseError{Err: err, RawExpression: input}
@@ -189,6 +242,13 @@ func (p *Parser) parse(input string, params parserParams) (Expression, error) {
 	return expr, nil
 }
 
+func (p *Parser) checkLength(input string) error {
+	if p.maxLength > 0 && len(input) > p.maxLength {
+		return fmt.Errorf("%w: length %d is greater than %d", ErrMaxLengthExceeded, len(input), p.maxLength)
+	}
+	return nil
+}
+
 // MustParse parses input string as a CTI expression and panics on error.
 func (p *Parser) MustParse(input string) Expression {
 	expr, err := p.Parse(input)
@@ -227,6 +287,7 @@ func (p *Parser) parseExpression(s string, params parserParams) (Expression, err
 
 	var head *Node
 	var tail *Node
+	depth := 0
 
 	for s != "" {
 		//nolint:nestif
@@ -262,6 +323,10 @@ func (p *Parser) parseExpression(s string, params parserParams) (Expression, err
 			}
 		}
 
+		if depth++; p.maxChainDepth > 0 && depth > p.maxChainDepth {
+			return emptyExpression, fmt.Errorf("%w: chain has more than %d nodes", ErrMaxChainDepthExceeded, p.maxChainDepth)
+		}
+
 		node := &Node{}
 
 		if s[0] == '$' {
@@ -330,7 +395,7 @@ func (p *Parser) parseChunkToNode(s string, node *Node, params parserParams) (ta
 	node.Vendor = Vendor(val)
 	if node.Vendor.IsWildCard() {
 		if params.wildcardDisabled {
-			return s, fmt.Errorf("parse vendor: wildcard is disabled")
+			return s, fmt.Errorf("parse vendor: %w", ErrWildcardDisabled)
 		}
 		return s, nil
 	}
@@ -342,7 +407,7 @@ func (p *Parser) parseChunkToNode(s string, node *Node, params parserParams) (ta
 	node.Package = Package(val)
 	if node.Package.IsWildCard() {
 		if params.wildcardDisabled {
-			return s, fmt.Errorf("parse package: wildcard is disabled")
+			return s, fmt.Errorf("parse package: %w", ErrWildcardDisabled)
 		}
 		return s, nil
 	}
@@ -353,15 +418,15 @@ func (p *Parser) parseChunkToNode(s string, node *Node, params parserParams) (ta
 	}
 	if node.EntityName.EndsWithWildcard() || node.Version.HasWildcard() {
 		if params.wildcardDisabled {
-			return s, fmt.Errorf("parse entity name and version: wildcard is disabled")
+			return s, fmt.Errorf("parse entity name and version: %w", ErrWildcardDisabled)
 		}
 		return s, nil
 	}
 	if !node.Version.Major.Valid && params.versionStrategy != versionStrategyAllowEmpty {
-		return s, fmt.Errorf("parse entity name and version: version is missing")
+		return s, fmt.Errorf("parse entity name and version: %w", ErrVersionMissing)
 	}
 	if !node.Version.Minor.Valid && params.versionStrategy == versionStrategyRequireFull {
-		return s, fmt.Errorf("parse entity name and version: minor part of version is missing")
+		return s, fmt.Errorf("parse entity name and version: %w", ErrMinorVersionMissing)
 	}
 
 	return s, nil
@@ -419,7 +484,7 @@ loop:
 			if i == 0 {
 				return "", Version{}, s, fmt.Errorf(`entity name can be "%c" or start only with letter or "_"`, Wildcard)
 			}
-			if i > 0 && s[i-1] == '.' {
+			if i > 0 && s[i-1] == '.' && !p.legacyCompat {
 				return "", Version{}, s, fmt.Errorf(`entity name cannot have double dots ("..")`)
 			}
 			if majorIdx != -1 && minorIdx != -1 {
@@ -432,7 +497,7 @@ loop:
 			}
 
 		case s[i] == '_':
-			if i > 0 && s[i-1] == '_' {
+			if i > 0 && s[i-1] == '_' && !p.legacyCompat {
 				return "", Version{}, s, fmt.Errorf(`entity name cannot have double underscores ("__")`)
 			}
 			majorIdx = -1
@@ -494,7 +559,7 @@ loop:
 
 		entityName := EntityName(nameStr)
 		if !entityName.EndsWithWildcard() {
-			return "", Version{}, s, fmt.Errorf("version is missing")
+			return "", Version{}, s, ErrVersionMissing
 		}
 		return entityName, Version{}, newS, nil
 	}
@@ -525,7 +590,7 @@ func (p *Parser) parseVersion(s string, majorIdx int, minorIdx int, pos int) (Ve
 	}
 
 	parseVersionPart := func(s, partName string) (int, error) {
-		if s != "" && s[0] == '0' && s != "0" {
+		if s != "" && s[0] == '0' && s != "0" && !p.legacyCompat {
 			return 0, fmt.Errorf("%s part of version cannot contain leading zero", partName)
 		}
 		ver, err := strconv.Atoi(s)
@@ -559,7 +624,7 @@ func (p *Parser) parseVersion(s string, majorIdx int, minorIdx int, pos int) (Ve
 		return Version{}, err
 	}
 
-	if majorVer == 0 && minorVer == 0 {
+	if majorVer == 0 && minorVer == 0 && !p.legacyCompat {
 		return Version{}, fmt.Errorf("version must be higher than 0.0")
 	}
 
@@ -598,6 +663,9 @@ func (p *Parser) parseQueryAttributesIfPresent(s string) ([]QueryAttribute, stri
 			}
 			ss = trimLeftSpaces(ss[1:])
 		}
+		if p.maxQueryAttributes > 0 && len(res) == p.maxQueryAttributes {
+			return nil, s, fmt.Errorf("%w: query has more than %d attributes", ErrMaxQueryAttributesExceeded, p.maxQueryAttributes)
+		}
 
 		queryAttr, ss, err = p.parseQueryAttribute(ss)
 		if err != nil {
@@ -655,35 +723,85 @@ func (p *Parser) parseQueryAttribute(s string) (QueryAttribute, string, error) {
 
 func (p *Parser) parseAttributeName(s string) (attrName AttributeName, newS string, err error) {
 	var i int
+	partStart := true // the next char starts a new part of the name
+	afterIndex := false
 loop:
-	for ; i < len(s); i++ {
-		switch {
-		case (s[i] >= '0' && s[i] <= '9') || s[i] == '_':
-			if i == 0 || s[i-1] == '.' {
+	for i < len(s) {
+		switch c := s[i]; {
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			if afterIndex {
+				return "", s, fmt.Errorf(`expect "." or "[" after array index in attribute name`)
+			}
+			partStart = false
+		case (c >= '0' && c <= '9') || c == '_' || c == '\\':
+			if afterIndex {
+				return "", s, fmt.Errorf(`expect "." or "[" after array index in attribute name`)
+			}
+			if partStart {
 				return "", s, fmt.Errorf("attribute name and its each part should start with letter")
 			}
-		case s[i] == '.':
+			if c == '\\' {
+				// Escaped dots are parts of keys, e.g. labels.app\.kubernetes\.io selects the "app.kubernetes.io" key.
+				if i+1 == len(s) || (s[i+1] != '.' && s[i+1] != '\\') {
+					return "", s, fmt.Errorf(`attribute name may have only "\." and "\\" escape sequences`)
+				}
+				i++
+			}
+		case c == '.':
 			if i == 0 {
 				return "", s, fmt.Errorf("attribute name should start with letter")
 			}
-			if s[i-1] == '.' {
+			if partStart {
 				return "", s, fmt.Errorf(`attribute name cannot have double dots ("..")`)
 			}
-		case (s[i] >= 'a' && s[i] <= 'z') || (s[i] >= 'A' && s[i] <= 'Z'):
+			partStart, afterIndex = true, false
+		case c == '[':
+			if i == 0 {
+				break loop
+			}
+			if partStart {
+				return "", s, fmt.Errorf("array index in attribute name should follow the name of the array")
+			}
+			n := arrayIndexLen(s[i:])
+			if n == 0 {
+				break loop
+			}
+			i += n - 1
+			afterIndex = true
 		default:
 			break loop
 		}
+		i++
 	}
 	if i == 0 {
 		return "", s, fmt.Errorf(`attribute name cannot be empty and should contain only letters, digits, ".", and "_"`)
 	}
-	if s[i-1] == '.' {
+	if partStart {
 		return "", s, fmt.Errorf(`attribute name cannot end with dot (".")`)
 	}
 
 	return AttributeName(s[:i]), s[i:], nil
 }
 
+// arrayIndexLen returns the length of the array index (e.g. "[0]" or "[*]") at the beginning of s or 0 if there is no one.
+func arrayIndexLen(s string) int {
+	i := 1
+	if i < len(s) && s[i] == '*' {
+		i++
+	} else {
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 1 {
+			return 0
+		}
+	}
+	if i == len(s) || s[i] != ']' {
+		return 0
+	}
+	return i + 1
+}
+
 func (p *Parser) parseQueryAttributeValue(s string) (attrVal string, newS string, err error) {
 	if s == "" {
 		return "", s, fmt.Errorf(`expect attribute value, got end of string`)
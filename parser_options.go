@@ -14,6 +14,11 @@ type ParserOption interface {
 type parserOptions struct {
 	allowAnonymousEntity         bool
 	allowedDynamicParameterNames []string
+	legacyCompat                 bool
+	resolver                     Resolver
+	maxLength                    int
+	maxChainDepth                int
+	maxQueryAttributes           int
 }
 
 type allowAnonymousEntityParserOption bool
@@ -38,6 +43,72 @@ func WithAllowedDynamicParameterNames(names ...string) ParserOption {
 	return allowedDynamicParameterNamesParserOption(names)
 }
 
+type legacyCompatParserOption bool
+
+func (o legacyCompatParserOption) apply(opts *parserOptions) {
+	opts.legacyCompat = bool(o)
+}
+
+// WithLegacyCompat allows specifying whether the parser should accept identifiers
+// that were valid according to the legacy regexp-based validation (see LegacyIdentifierPattern).
+// In this mode the parser allows double dots and double underscores in entity names,
+// leading zeros in version parts, zero version and anonymous entity UUID.
+func WithLegacyCompat(b bool) ParserOption {
+	return legacyCompatParserOption(b)
+}
+
+type resolverParserOption struct {
+	resolver Resolver
+}
+
+func (o resolverParserOption) apply(opts *parserOptions) {
+	opts.resolver = o.resolver
+}
+
+// WithResolver allows specifying the Resolver that is used by parsed expressions to resolve anonymous entities.
+// With the resolver, an expression of CTI type matches its anonymous entities
+// and the query attributes are matched against the values of the resolved instance.
+func WithResolver(r Resolver) ParserOption {
+	return resolverParserOption{resolver: r}
+}
+
+type maxLengthParserOption int
+
+func (o maxLengthParserOption) apply(opts *parserOptions) {
+	opts.maxLength = int(o)
+}
+
+// WithMaxLength limits the length of the input string in bytes. Zero means no limit.
+// The parser returns an error wrapping ErrMaxLengthExceeded for longer input without parsing it.
+func WithMaxLength(n int) ParserOption {
+	return maxLengthParserOption(n)
+}
+
+type maxChainDepthParserOption int
+
+func (o maxChainDepthParserOption) apply(opts *parserOptions) {
+	opts.maxChainDepth = int(o)
+}
+
+// WithMaxChainDepth limits the number of nodes in the inheritance chain of CTI expression
+// (e.g. cti.a.p.event.v1.0~a.p.created.v1.0 has two nodes). Zero means no limit.
+// The parser returns an error wrapping ErrMaxChainDepthExceeded for deeper chains.
+func WithMaxChainDepth(n int) ParserOption {
+	return maxChainDepthParserOption(n)
+}
+
+type maxQueryAttributesParserOption int
+
+func (o maxQueryAttributesParserOption) apply(opts *parserOptions) {
+	opts.maxQueryAttributes = int(o)
+}
+
+// WithMaxQueryAttributes limits the number of attributes in the query of CTI expression. Zero means no limit.
+// The parser returns an error wrapping ErrMaxQueryAttributesExceeded for queries with more attributes.
+func WithMaxQueryAttributes(n int) ParserOption {
+	return maxQueryAttributesParserOption(n)
+}
+
 func makeParserOptions(opts ...ParserOption) parserOptions {
 	var options parserOptions
 	for _, opt := range opts {
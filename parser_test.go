@@ -1130,6 +1130,41 @@ func TestParser_Parse(t *testing.T) {
 				},
 			}, AttributeSelector: "meta.status.name_1"},
 		},
+		{
+			name:  "ok, attribute selector, array index and wildcard",
+			input: `cti.a.p.gr.namespace.v1.0@items[0].tags[*]`,
+			wantExp: Expression{Head: &Node{
+				Vendor:     Vendor("a"),
+				Package:    Package("p"),
+				EntityName: EntityName("gr.namespace"),
+				Version:    NewVersion(1, 0),
+			}, AttributeSelector: "items[0].tags[*]"},
+		},
+		{
+			name:  "ok, attribute selector, escaped dots",
+			input: `cti.a.p.gr.namespace.v1.0@labels.app\.kubernetes\.io`,
+			wantExp: Expression{Head: &Node{
+				Vendor:     Vendor("a"),
+				Package:    Package("p"),
+				EntityName: EntityName("gr.namespace"),
+				Version:    NewVersion(1, 0),
+			}, AttributeSelector: `labels.app\.kubernetes\.io`},
+		},
+		{
+			name:       "error, attribute selector, name after array index",
+			input:      `cti.a.p.gr.namespace.v1.0@items[0]name`,
+			wantErrMsg: `parse attribute selector: expect "." or "[" after array index in attribute name`,
+		},
+		{
+			name:       "error, attribute selector, array index without name",
+			input:      `cti.a.p.gr.namespace.v1.0@items.[0]`,
+			wantErrMsg: `parse attribute selector: array index in attribute name should follow the name of the array`,
+		},
+		{
+			name:       "error, attribute selector, invalid escape sequence",
+			input:      `cti.a.p.gr.namespace.v1.0@items\n`,
+			wantErrMsg: `parse attribute selector: attribute name may have only "\." and "\\" escape sequences`,
+		},
 		{
 			name:       "error, attribute selector, name is empty",
 			input:      `cti.a.p.gr.namespace.v1.*@status`,
@@ -1335,3 +1370,74 @@ func BenchmarkMD5(b *testing.B) {
 		_ = md5.Sum(expBytes[i%len(expBytes)])
 	}
 }
+
+func TestParser_ParseQueryAndSelector(t *testing.T) {
+	p := NewParser()
+
+	attrs, selector, err := p.ParseQueryAndSelector(`[status="active", kind=cti.a.p.kind.v1.0~a.p.*]@name.first`)
+	require.NoError(t, err)
+	require.Equal(t, AttributeName("name.first"), selector)
+	require.Len(t, attrs, 2)
+	require.Equal(t, AttributeName("status"), attrs[0].Name)
+	require.Equal(t, "active", attrs[0].Value.Raw)
+	require.True(t, attrs[1].Value.IsExpression())
+
+	attrs, selector, err = p.ParseQueryAndSelector("")
+	require.NoError(t, err)
+	require.Empty(t, attrs)
+	require.Empty(t, selector)
+
+	_, _, err = p.ParseQueryAndSelector(`@name[status="active"]`)
+	require.EqualError(t, err, `unexpected "[status=\"active\"]"`)
+
+	_, _, err = p.ParseQueryAndSelector(`[status=]`)
+	require.Error(t, err)
+}
+
+func TestParser_Limits(t *testing.T) {
+	p := NewParser(WithMaxLength(64), WithMaxChainDepth(2), WithMaxQueryAttributes(2))
+
+	_, err := p.Parse(`cti.a.p.event.v1.0~a.p.created.v1.0[status="active", kind="user"]`)
+	require.ErrorIs(t, err, ErrMaxLengthExceeded)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+
+	_, err = p.Parse("cti.a.p.a.v1.0~a.p.b.v1.0~a.p.c.v1.0")
+	require.ErrorIs(t, err, ErrMaxChainDepthExceeded)
+
+	_, err = p.Parse(`cti.a.p.event.v1.0[a="1", b="2", c="3"]`)
+	require.ErrorIs(t, err, ErrMaxQueryAttributesExceeded)
+
+	_, _, err = p.ParseQueryAndSelector(`[a="1", b="2", c="3"]`)
+	require.ErrorIs(t, err, ErrMaxQueryAttributesExceeded)
+
+	_, err = p.Parse(`cti.a.p.event.v1.0~a.p.created.v1.0[a="1", b="2"]`)
+	require.NoError(t, err)
+
+	// Zero means no limit.
+	_, err = NewParser(WithMaxLength(0)).Parse("cti.a.p.a.v1.0~a.p.b.v1.0~a.p.c.v1.0")
+	require.NoError(t, err)
+}
+
+func TestParser_Errors(t *testing.T) {
+	p := NewParser()
+	for input, expected := range map[string]error{
+		"cti.a.p.*":        ErrWildcardDisabled,
+		"cti.a.*":          ErrWildcardDisabled,
+		"cti.*":            ErrWildcardDisabled,
+		"cti.a.p.event":    ErrVersionMissing,
+		"cti.a.p.event.v1": ErrMinorVersionMissing,
+	} {
+		_, err := p.ParseIdentifier(input)
+		require.ErrorIs(t, err, expected, input)
+		var parseErr *ParseError
+		require.ErrorAs(t, err, &parseErr, input)
+		require.Equal(t, input, parseErr.RawExpression)
+	}
+
+	_, err := p.ParseReference("cti.a.p.event")
+	require.ErrorIs(t, err, ErrVersionMissing)
+
+	_, err = p.ParseAttributeSelector("cti.a.p.event.v1.0")
+	require.ErrorIs(t, err, ErrAttributeSelectorMissing)
+}
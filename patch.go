@@ -0,0 +1,386 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchFormat is a format of patches of instance values.
+type PatchFormat int
+
+const (
+	// JSONPatch is a JSON Patch document (RFC 6902), i.e. an array of operations.
+	JSONPatch PatchFormat = iota
+	// MergePatch is a JSON Merge Patch document (RFC 7386).
+	MergePatch
+)
+
+// String returns the media type of the patch format.
+func (f PatchFormat) String() string {
+	switch f {
+	case JSONPatch:
+		return "application/json-patch+json"
+	case MergePatch:
+		return "application/merge-patch+json"
+	}
+	return "unknown patch format " + strconv.Itoa(int(f))
+}
+
+// ErrPatchTestFailed is returned when the "test" operation of JSON Patch fails.
+var ErrPatchTestFailed = errors.New("patch test failed")
+
+// ValuesValidator validates values of instances, e.g. against the merged schema of the parent type of the instance.
+type ValuesValidator interface {
+	// ValidateValues validates values of the instance with the CTI encoded to JSON.
+	ValidateValues(cti string, values []byte) error
+}
+
+// ApplyPatch applies the patch in the format to the values of the instance. The patched values must be an object
+// and are validated with the validator, if it is not nil. Values and RawValues of the instance are updated
+// only if the patch is applied and the patched values are valid, so the instance is never left partially patched.
+func (i *EntityInstance) ApplyPatch(patch []byte, format PatchFormat, v ValuesValidator) error {
+	raw := []byte(i.RawValues)
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(i.Values); err != nil {
+			return fmt.Errorf("marshal values of %s: %w", i.Cti, err)
+		}
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("unmarshal values of %s: %w", i.Cti, err)
+	}
+
+	var err error
+	switch format {
+	case JSONPatch:
+		doc, err = applyJSONPatch(doc, patch)
+	case MergePatch:
+		doc, err = applyMergePatch(doc, patch)
+	default:
+		err = fmt.Errorf("unsupported patch format %d", format)
+	}
+	if err != nil {
+		return fmt.Errorf("apply patch to %s: %w", i.Cti, err)
+	}
+
+	values, ok := doc.(map[string]any)
+	if !ok {
+		return fmt.Errorf("apply patch to %s: patched values must be an object", i.Cti)
+	}
+	patched, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("marshal patched values of %s: %w", i.Cti, err)
+	}
+	if v != nil {
+		if err := v.ValidateValues(i.Cti, patched); err != nil {
+			return fmt.Errorf("validate patched values of %s: %w", i.Cti, err)
+		}
+	}
+	i.Values = values
+	i.RawValues = patched
+	return nil
+}
+
+type patchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies operations of RFC 6902 to the document one by one.
+func applyJSONPatch(doc any, patch []byte) (any, error) {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("unmarshal json patch: %w", err)
+	}
+	for n, op := range ops {
+		var err error
+		if doc, err = applyPatchOperation(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", n, op.Op, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOperation(doc any, op patchOperation) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("path is missing")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+	value := func() (any, error) {
+		if op.Value == nil {
+			return nil, fmt.Errorf("value is missing")
+		}
+		var v any
+		if err := json.Unmarshal(*op.Value, &v); err != nil {
+			return nil, fmt.Errorf("unmarshal value: %w", err)
+		}
+		return v, nil
+	}
+	from := func() ([]string, error) {
+		if op.From == nil {
+			return nil, fmt.Errorf("from is missing")
+		}
+		return parsePointer(*op.From)
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "remove":
+		doc, _, err = removeValue(doc, path)
+		return doc, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		if doc, _, err = removeValue(doc, path); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "move":
+		fromPath, err := from()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > len(fromPath) && reflect.DeepEqual(path[:len(fromPath)], fromPath) {
+			return nil, fmt.Errorf("cannot move value into its child")
+		}
+		doc, v, err := removeValue(doc, fromPath)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, v)
+	case "copy":
+		fromPath, err := from()
+		if err != nil {
+			return nil, err
+		}
+		v, err := getValue(doc, fromPath)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, copyJSON(v))
+	case "test":
+		expected, err := value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, fmt.Errorf("%w: value at %s differs", ErrPatchTestFailed, *op.Path)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// parsePointer splits the JSON pointer (RFC 6901) into unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = v
+		case []any:
+			idx, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[idx]
+		default:
+			return nil, fmt.Errorf("cannot get %q of scalar value", token)
+		}
+	}
+	return doc, nil
+}
+
+// addValue adds the value at the path and returns the updated document.
+func addValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+		return doc, nil
+	case []any:
+		idx := len(node)
+		if token != "-" {
+			if idx, err = arrayIndex(token, len(node)); err != nil {
+				return nil, err
+			}
+		}
+		arr := append(node[:idx:idx], append([]any{value}, node[idx:]...)...)
+		return setValue(doc, path[:len(path)-1], arr)
+	}
+	return nil, fmt.Errorf("cannot add %q to scalar value", token)
+}
+
+// removeValue removes the value at the path and returns the updated document and the removed value.
+func removeValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		v, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", token)
+		}
+		delete(node, token)
+		return doc, v, nil
+	case []any:
+		idx, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := node[idx]
+		arr := append(node[:idx:idx], node[idx+1:]...)
+		doc, err = setValue(doc, path[:len(path)-1], arr)
+		return doc, v, err
+	}
+	return nil, nil, fmt.Errorf("cannot remove %q of scalar value", token)
+}
+
+// setValue replaces the existing value at the path, e.g. an array which length is changed.
+func setValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[token] = value
+	case []any:
+		idx, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses the array index token. The index must not exceed max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx > max {
+		return 0, fmt.Errorf("array index %d is out of range", idx)
+	}
+	return idx, nil
+}
+
+// applyMergePatch applies the merge patch of RFC 7386 to the document.
+func applyMergePatch(doc any, patch []byte) (any, error) {
+	var p any
+	dec := json.NewDecoder(bytes.NewReader(patch))
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("unmarshal merge patch: %w", err)
+	}
+	// The patch must be a single JSON document, like the JSON Patch that is unmarshaled as a whole.
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unmarshal merge patch: unexpected data after the patch")
+	}
+	return mergePatch(doc, p), nil
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for key, val := range p {
+		if val == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], val)
+	}
+	return t
+}
+
+func copyJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for key, item := range v {
+			res[key] = copyJSON(item)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = copyJSON(item)
+		}
+		return res
+	default:
+		return v
+	}
+}
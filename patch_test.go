@@ -0,0 +1,164 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type valuesValidatorFunc func(cti string, values []byte) error
+
+func (f valuesValidatorFunc) ValidateValues(cti string, values []byte) error {
+	return f(cti, values)
+}
+
+func newTestInstance() *EntityInstance {
+	return &EntityInstance{
+		Cti: "cti.a.p.am.alert.v1.0~a.p.backup.v1.0",
+		Values: map[string]any{
+			"severity": "critical",
+			"tags":     []any{"a", "b"},
+			"origin":   map[string]any{"host": "srv1", "a/b": "x"},
+		},
+	}
+}
+
+func TestEntityInstance_ApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   PatchFormat
+		patch    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "json patch add and replace",
+			format:   JSONPatch,
+			patch:    `[{"op": "add", "path": "/count", "value": 3}, {"op": "replace", "path": "/severity", "value": "low"}]`,
+			expected: `{"severity": "low", "count": 3, "tags": ["a", "b"], "origin": {"host": "srv1", "a/b": "x"}}`,
+		},
+		{
+			name:     "json patch arrays",
+			format:   JSONPatch,
+			patch:    `[{"op": "add", "path": "/tags/-", "value": "c"}, {"op": "add", "path": "/tags/0", "value": "z"}, {"op": "remove", "path": "/tags/1"}]`,
+			expected: `{"severity": "critical", "tags": ["z", "b", "c"], "origin": {"host": "srv1", "a/b": "x"}}`,
+		},
+		{
+			name:     "json patch move, copy and escaped pointers",
+			format:   JSONPatch,
+			patch:    `[{"op": "move", "from": "/origin/a~1b", "path": "/kind"}, {"op": "copy", "from": "/origin", "path": "/source"}]`,
+			expected: `{"severity": "critical", "kind": "x", "tags": ["a", "b"], "origin": {"host": "srv1"}, "source": {"host": "srv1"}}`,
+		},
+		{
+			name:     "json patch test",
+			format:   JSONPatch,
+			patch:    `[{"op": "test", "path": "/tags", "value": ["a", "b"]}, {"op": "remove", "path": "/tags"}]`,
+			expected: `{"severity": "critical", "origin": {"host": "srv1", "a/b": "x"}}`,
+		},
+		{
+			name:   "json patch failed test",
+			format: JSONPatch,
+			patch:  `[{"op": "remove", "path": "/tags"}, {"op": "test", "path": "/severity", "value": "low"}]`,
+			err:    "patch test failed",
+		},
+		{
+			name:   "json patch missing member",
+			format: JSONPatch,
+			patch:  `[{"op": "replace", "path": "/count", "value": 1}]`,
+			err:    `member "count" not found`,
+		},
+		{
+			name:   "json patch index out of range",
+			format: JSONPatch,
+			patch:  `[{"op": "add", "path": "/tags/5", "value": "c"}]`,
+			err:    "array index 5 is out of range",
+		},
+		{
+			name:   "json patch move into child",
+			format: JSONPatch,
+			patch:  `[{"op": "move", "from": "/origin", "path": "/origin/nested"}]`,
+			err:    "cannot move value into its child",
+		},
+		{
+			name:   "json patch replaces values with non-object",
+			format: JSONPatch,
+			patch:  `[{"op": "replace", "path": "", "value": [1]}]`,
+			err:    "patched values must be an object",
+		},
+		{
+			name:     "merge patch",
+			format:   MergePatch,
+			patch:    `{"severity": "low", "tags": null, "origin": {"host": "srv2", "port": 22}}`,
+			expected: `{"severity": "low", "origin": {"host": "srv2", "a/b": "x", "port": 22}}`,
+		},
+		{
+			name:   "merge patch with trailing data",
+			format: MergePatch,
+			patch:  `{"severity": "low"} {"tags": null}`,
+			err:    "unexpected data after the patch",
+		},
+		{
+			name:   "merge patch with trailing garbage",
+			format: MergePatch,
+			patch:  `{"severity": "low"}}`,
+			err:    "unexpected data after the patch",
+		},
+		{
+			name:   "merge patch replaces values with non-object",
+			format: MergePatch,
+			patch:  `"text"`,
+			err:    "patched values must be an object",
+		},
+		{
+			name:   "unknown format",
+			format: PatchFormat(5),
+			patch:  `{}`,
+			err:    "unsupported patch format 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance()
+			err := instance.ApplyPatch([]byte(tt.patch), tt.format, nil)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				require.Equal(t, newTestInstance(), instance)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(instance.RawValues))
+			values, err := json.Marshal(instance.Values)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(values))
+		})
+	}
+}
+
+func TestEntityInstance_ApplyPatchValidation(t *testing.T) {
+	instance := newTestInstance()
+	validator := valuesValidatorFunc(func(cti string, values []byte) error {
+		require.Equal(t, instance.Cti, cti)
+		if string(values) == `{"origin":{"a/b":"x","host":"srv1"},"severity":"unknown","tags":["a","b"]}` {
+			return errors.New("invalid severity")
+		}
+		return nil
+	})
+
+	err := instance.ApplyPatch([]byte(`{"severity": "unknown"}`), MergePatch, validator)
+	require.EqualError(t, err, "validate patched values of cti.a.p.am.alert.v1.0~a.p.backup.v1.0: invalid severity")
+	require.Equal(t, newTestInstance(), instance)
+
+	require.NoError(t, instance.ApplyPatch([]byte(`{"severity": "low"}`), MergePatch, validator))
+	require.Equal(t, "low", instance.Values["severity"])
+
+	// Subsequent patches are applied to the cached raw values.
+	require.NoError(t, instance.ApplyPatch([]byte(`[{"op": "test", "path": "/severity", "value": "low"}]`), JSONPatch, validator))
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"fmt"
+)
+
+// Truncate returns a new Expression with the first depth nodes of the inheritance chain,
+// e.g. cti.a.p.event.v1.0 is the result of truncation of cti.a.p.event.v1.0~a.p.created.v1.0 to depth 1.
+// Query attributes, attribute selector and anonymous entity UUID are not preserved.
+func (e *Expression) Truncate(depth int) (Expression, error) {
+	if n := e.depth(); depth < 1 || depth > n {
+		return emptyExpression, fmt.Errorf("depth %d is out of range [1, %d]", depth, n)
+	}
+	head, _ := copyNodes(e.Head, depth)
+	return e.validated(Expression{Head: head})
+}
+
+// Append returns a new Expression with the node and its children appended to the end of the inheritance chain,
+// e.g. to derive an identifier of a child type. The node is copied, so it may be reused by the caller.
+// Query attributes and attribute selector are not preserved. Expressions with anonymous entity UUID cannot be extended.
+func (e *Expression) Append(node Node) (Expression, error) {
+	if e.HasAnonymousEntity() {
+		return emptyExpression, fmt.Errorf("expression %s with anonymous entity cannot be extended", e)
+	}
+	head, tail := copyNodes(e.Head, e.depth())
+	appended, _ := copyNodes(&node, -1)
+	if tail == nil {
+		head = appended
+	} else {
+		tail.Child = appended
+	}
+	return e.validated(Expression{Head: head})
+}
+
+// RebaseOnto returns a new Expression with the chain of parent nodes substituted by the parent expression,
+// e.g. rebasing of cti.a.p.event.v1.0~a.p.created.v1.0 onto cti.a.p.event.v1.1 results in cti.a.p.event.v1.1~a.p.created.v1.0.
+// The parent chain of an identified entity consists of all nodes except the last one,
+// and the parent chain of an anonymous entity consists of all nodes, so its UUID is preserved.
+// Query attributes and attribute selector are not preserved.
+func (e *Expression) RebaseOnto(parent Expression) (Expression, error) {
+	if parent.Head == nil || parent.HasAnonymousEntity() || parent.HasQueryAttributes() || parent.AttributeSelector != "" {
+		return emptyExpression, fmt.Errorf("parent %s must consist of nodes only", &parent)
+	}
+	own := 1
+	if e.HasAnonymousEntity() {
+		own = 0
+	}
+	n := e.depth()
+	if n-own < 1 {
+		return emptyExpression, fmt.Errorf("expression %s has no parent", e)
+	}
+
+	head, tail := copyNodes(parent.Head, -1)
+	skipped := e.Head
+	for i := 0; i < n-own; i++ {
+		skipped = skipped.Child
+	}
+	tail.Child, _ = copyNodes(skipped, -1)
+	return e.validated(Expression{Head: head, AnonymousEntityUUID: e.AnonymousEntityUUID})
+}
+
+// depth returns the number of nodes of the inheritance chain.
+func (e *Expression) depth() int {
+	n := 0
+	for node := e.Head; node != nil; node = node.Child {
+		n++
+	}
+	return n
+}
+
+// validated parses the string representation of the expression with the parser of the Expression,
+// so the result satisfies the same restrictions as parsed expressions, e.g. the maximum chain depth.
+func (e *Expression) validated(res Expression) (Expression, error) {
+	p := e.parser
+	if p == nil {
+		p = NewParser()
+	}
+	parsed, err := p.Parse(res.String())
+	if err != nil {
+		return emptyExpression, fmt.Errorf("validate %s: %w", res.String(), err)
+	}
+	return parsed, nil
+}
+
+// copyNodes copies up to limit nodes of the chain starting from the head and returns the head and the tail of the copy.
+// Negative limit means the whole chain.
+func copyNodes(head *Node, limit int) (*Node, *Node) {
+	var cpHead, cpTail *Node
+	for n, i := head, 0; n != nil && (limit < 0 || i < limit); n, i = n.Child, i+1 {
+		cp := *n
+		cp.Child = nil
+		if cpHead == nil {
+			cpHead = &cp
+		} else {
+			cpTail.Child = &cp
+		}
+		cpTail = &cp
+	}
+	return cpHead, cpTail
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2024 Acronis International GmbH.
+
+Released under MIT license.
+*/
+
+package cti
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpression_Truncate(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true))
+	e := p.MustParse("cti.a.p.event.v1.0~a.p.created.v1.0~b.q.user.v2.1")
+
+	res, err := e.Truncate(2)
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.event.v1.0~a.p.created.v1.0", res.String())
+	require.Equal(t, "cti.a.p.event.v1.0~a.p.created.v1.0~b.q.user.v2.1", e.String())
+
+	anonymous := p.MustParse("cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6")
+	res, err = anonymous.Truncate(1)
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.event.v1.0", res.String())
+
+	_, err = e.Truncate(0)
+	require.EqualError(t, err, "depth 0 is out of range [1, 3]")
+	_, err = e.Truncate(4)
+	require.EqualError(t, err, "depth 4 is out of range [1, 3]")
+}
+
+func TestExpression_Append(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true))
+	e := p.MustParse("cti.a.p.event.v1.0")
+	node := Node{Vendor: "b", Package: "q", EntityName: "created", Version: NewVersion(1, 2)}
+
+	res, err := e.Append(node)
+	require.NoError(t, err)
+	require.Equal(t, "cti.a.p.event.v1.0~b.q.created.v1.2", res.String())
+	require.Equal(t, "cti.a.p.event.v1.0", e.String())
+	require.Nil(t, node.Child)
+
+	_, err = e.Append(Node{Vendor: "B", Package: "q", EntityName: "created", Version: NewVersion(1, 0)})
+	require.ErrorContains(t, err, "validate cti.a.p.event.v1.0~B.q.created.v1.0")
+
+	anonymous := p.MustParse("cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6")
+	_, err = anonymous.Append(node)
+	require.ErrorContains(t, err, "cannot be extended")
+
+	deep := NewParser(WithMaxChainDepth(2)).MustParse("cti.a.p.event.v1.0~a.p.created.v1.0")
+	_, err = deep.Append(node)
+	require.ErrorIs(t, err, ErrMaxChainDepthExceeded)
+}
+
+func TestExpression_RebaseOnto(t *testing.T) {
+	p := NewParser(WithAllowAnonymousEntity(true))
+	tests := []struct {
+		name    string
+		input   string
+		parent  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "new parent version",
+			input:  "cti.a.p.event.v1.0~a.p.created.v1.0",
+			parent: "cti.a.p.event.v1.1",
+			want:   "cti.a.p.event.v1.1~a.p.created.v1.0",
+		},
+		{
+			name:   "deeper parent",
+			input:  "cti.a.p.event.v1.0~a.p.created.v1.0",
+			parent: "cti.a.p.event.v1.0~a.p.user_event.v1.0",
+			want:   "cti.a.p.event.v1.0~a.p.user_event.v1.0~a.p.created.v1.0",
+		},
+		{
+			name:   "anonymous entity",
+			input:  "cti.a.p.event.v1.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6",
+			parent: "cti.a.p.alert.v2.0",
+			want:   "cti.a.p.alert.v2.0~ba3c448e-55e3-4f7f-ae54-4e87eb8635f6",
+		},
+		{
+			name:    "no parent",
+			input:   "cti.a.p.event.v1.0",
+			parent:  "cti.a.p.base.v1.0",
+			wantErr: "expression cti.a.p.event.v1.0 has no parent",
+		},
+		{
+			name:    "parent with selector",
+			input:   "cti.a.p.event.v1.0~a.p.created.v1.0",
+			parent:  "cti.a.p.event.v1.0@id",
+			wantErr: "parent cti.a.p.event.v1.0@id must consist of nodes only",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := p.MustParse(tt.input)
+			res, err := e.RebaseOnto(p.MustParse(tt.parent))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, res.String())
+			require.Equal(t, tt.input, e.String())
+		})
+	}
+}